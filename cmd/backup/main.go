@@ -0,0 +1,134 @@
+// Command backup writes a consistent backup of the database and object store
+// to a ZIP archive, or restores one into a fresh instance.
+//
+//	backup create [-o lattice-backup.zip] [-objects=false]
+//	backup restore lattice-backup.zip
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/internal/storage"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	switch os.Args[1] {
+	case "create":
+		create(os.Args[2:])
+	case "restore":
+		restore(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backup create [-o file] [-objects=false]")
+	fmt.Fprintln(os.Stderr, "       backup restore file")
+	os.Exit(2)
+}
+
+// initStores connects to the database and object store
+func initStores() {
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	if err := storage.Init(); err != nil {
+		log.Fatalf("Failed to initialize object storage: %v", err)
+	}
+}
+
+func create(args []string) {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	out := flags.String("o", "lattice-backup-"+time.Now().UTC().Format("20060102-150405")+".zip", "archive to write")
+	objects := flags.Bool("objects", true, "copy stored objects into the archive (otherwise only list them)")
+	flags.Parse(args)
+
+	initStores()
+	defer db.CloseDB()
+
+	// Written beside the destination, and moved into place once complete
+	tmp, err := os.CreateTemp(filepath.Dir(*out), ".backup-*.zip")
+	if err != nil {
+		log.Fatalf("Failed to create backup file: %v", err)
+	}
+
+	manifest, err := services.CreateBackup(context.Background(), tmp, *objects)
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), *out)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		log.Fatalf("Backup failed: %v", err)
+	}
+
+	rows, included := 0, 0
+	for _, t := range manifest.Tables {
+		rows += t.Rows
+	}
+	for _, o := range manifest.Objects {
+		if o.Included {
+			included++
+		}
+	}
+	fmt.Printf("Backed up %d rows from %d tables (%d migrations applied)\n", rows, len(manifest.Tables), len(manifest.Migrations))
+	fmt.Printf("Listed %d stored objects, %d copied into the archive\n", len(manifest.Objects), included)
+	fmt.Printf("Wrote %s\n", *out)
+}
+
+func restore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	initStores()
+	defer db.CloseDB()
+
+	// Create the schema the backup is loaded into
+	migrationsPath := filepath.Join("internal", "db", "migrations")
+	if err := db.RunMigrations(migrationsPath); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	result, err := services.RestoreBackup(context.Background(), flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+
+	rows := 0
+	for _, n := range result.Rows {
+		rows += n
+	}
+	fmt.Printf("Restored %d rows into %d tables\n", rows, len(result.Rows))
+	fmt.Printf("Restored %d objects; %d were already in the store\n", result.ObjectsRestored, result.ObjectsPresent)
+	for _, key := range result.ObjectsMissing {
+		fmt.Printf("  missing: %s\n", key)
+	}
+	for _, ref := range result.Unlinked {
+		fmt.Printf("  unlinked %s.%s: %s\n", ref.Table, ref.Column, ref.Key)
+	}
+}
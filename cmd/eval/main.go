@@ -0,0 +1,86 @@
+// Command eval scores concept extraction against the stored eval cases and
+// saves the run, printing how it compares to the previous run of the same
+// model and prompt version.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+func main() {
+	label := flag.String("label", "", "note stored with the run")
+	model := flag.String("model", "", "Claude model to evaluate (defaults to CLAUDE_MODEL)")
+	instructions := flag.String("instructions", "", "extra concept stage instructions to evaluate")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// Initialize database
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.CloseDB()
+
+	// Run database migrations
+	migrationsPath := filepath.Join("internal", "db", "migrations")
+	if err := db.RunMigrations(migrationsPath); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	if err := services.LoadPrompts(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	evalService, err := services.NewEvalService()
+	if err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+
+	run, err := evalService.Run(context.Background(), services.EvalOptions{
+		Label:        *label,
+		Model:        *model,
+		Instructions: *instructions,
+	})
+	if err != nil {
+		log.Fatalf("Eval failed: %v", err)
+	}
+
+	fmt.Printf("Run %d: %s, prompt %s (judge %s)\n", run.ID, run.Model, run.PromptVersion, run.JudgeModel)
+	for _, r := range run.Results {
+		if r.Error != nil {
+			fmt.Printf("  %-40s failed: %s\n", r.CaseName, *r.Error)
+			continue
+		}
+		fmt.Printf("  %-40s coverage %5.1f%%  precision %5.1f%%\n", r.CaseName, r.Coverage*100, r.Precision*100)
+		for _, missed := range r.Missed {
+			fmt.Printf("      missed: %s\n", missed)
+		}
+	}
+	fmt.Printf("Mean over %d cases (%d failed): coverage %.1f%%, precision %.1f%%\n",
+		run.Cases-run.Failed, run.Failed, run.Coverage*100, run.Precision*100)
+
+	previous, err := db.GetPreviousEvalRun(*run)
+	if err != nil {
+		log.Fatalf("Failed to load previous run: %v", err)
+	}
+	if previous != nil {
+		fmt.Printf("Since run %d: coverage %+.1f points, precision %+.1f points\n",
+			previous.ID, (run.Coverage-previous.Coverage)*100, (run.Precision-previous.Precision)*100)
+	}
+
+	if run.Failed > 0 {
+		db.CloseDB()
+		os.Exit(1)
+	}
+}
@@ -1,23 +1,43 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/mostlyerror/lattice/internal/auth"
+	"github.com/mostlyerror/lattice/internal/config"
 	"github.com/mostlyerror/lattice/internal/db"
 	"github.com/mostlyerror/lattice/internal/handlers"
 	"github.com/mostlyerror/lattice/internal/middleware"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+	"github.com/mostlyerror/lattice/internal/server"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/internal/storage"
+	"github.com/mostlyerror/lattice/internal/tenancy"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
+	// Load environment variables; .env is read again on SIGHUP or POST /api/admin/reload
+	if err := config.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// With TENANTS_FILE, this process routes requests to a server per tenant
+	// instead, each with its own schema and environment
+	if tenantsFile := os.Getenv("TENANTS_FILE"); tenantsFile != "" {
+		if err := tenancy.Run(tenantsFile); err != nil {
+			log.Fatalf("Failed to serve tenants: %v", err)
+		}
+		return
+	}
+
 	// Initialize database
 	if err := db.InitDB(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -30,42 +50,586 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Pipeline runs still marked running were cut short by the last shutdown
+	if interrupted, err := db.MarkInterruptedPipelineRuns(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if interrupted > 0 {
+		log.Printf("Marked %d interrupted pipeline runs incomplete; resubmit or resume them to finish", interrupted)
+	}
+
+	// Jobs still marked running were cut short too; rerunning them resumes their pipeline runs
+	if requeued, err := db.RequeueInterruptedJobs(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if requeued > 0 {
+		log.Printf("Requeued %d interrupted jobs", requeued)
+	}
+
+	// Data requests still marked running were cut short too; each picks up where it stopped
+	if requeued, err := db.RequeueInterruptedDataRequests(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if requeued > 0 {
+		log.Printf("Requeued %d interrupted data requests", requeued)
+	}
+
+	// Initialize object storage
+	if err := storage.Init(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Load the master key for encrypted credentials and text
+	if err := secrets.Init(); err != nil {
+		log.Fatalf("Failed to load secrets master key: %v", err)
+	}
+	if err := secrets.InitTextEncryption(); err != nil {
+		log.Fatalf("Failed to turn on encryption at rest: %v", err)
+	}
+
+	// Configure login providers
+	if err := auth.InitProviders(context.Background()); err != nil {
+		log.Fatalf("Failed to configure login providers: %v", err)
+	}
+
 	// Initialize services
 	if err := handlers.InitSourceContentService(); err != nil {
 		log.Fatalf("Failed to initialize services: %v", err)
 	}
+	if err := handlers.InitConceptService(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := services.InitSettings(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := handlers.InitQuizService(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	handlers.InitAnkiService()
+	handlers.InitNotificationService()
+	handlers.InitPrivacyService()
+	if err := handlers.InitRetentionService(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := handlers.InitModerationService(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := handlers.InitDemo(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := handlers.InitUsageReporter(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := handlers.InitEmbeddingService(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+	if err := services.LoadPrompts(); err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+
+	// Background work runs until shutdown cancels ctx, and shutdown waits for it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Start the job workers that process queued sources (JOB_WORKERS at once)
+	jobWorkers := services.DefaultJobWorkers
+	if workersStr := os.Getenv("JOB_WORKERS"); workersStr != "" {
+		parsed, err := strconv.Atoi(workersStr)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid JOB_WORKERS: %q", workersStr)
+		}
+		jobWorkers = parsed
+	}
+	handlers.StartJobWorkers(ctx, jobWorkers)
+
+	// Start carrying out data export and account deletion requests
+	handlers.StartDataRequests(ctx)
+
+	// Start embedding concepts for semantic search, when EMBEDDING_PROVIDER allows
+	handlers.StartEmbedding(ctx)
+
+	// Start review reminders (REVIEW_REMINDER_INTERVAL=0 disables them)
+	reminderInterval := 4 * time.Hour
+	if intervalStr := os.Getenv("REVIEW_REMINDER_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Invalid REVIEW_REMINDER_INTERVAL: %v", err)
+		}
+		reminderInterval = parsed
+	}
+	if reminderInterval > 0 {
+		handlers.StartReviewReminders(ctx, reminderInterval)
+	}
+
+	// Start the daily review digest at REVIEW_DIGEST_AT local time (HH:MM)
+	if digestAt := os.Getenv("REVIEW_DIGEST_AT"); digestAt != "" {
+		at, err := time.Parse("15:04", digestAt)
+		if err != nil {
+			log.Fatalf("Invalid REVIEW_DIGEST_AT: %v", err)
+		}
+		handlers.StartReviewDigest(ctx, at.Hour(), at.Minute())
+	}
+
+	// Start the daily briefing at BRIEFING_AT local time (HH:MM)
+	if briefingAt := os.Getenv("BRIEFING_AT"); briefingAt != "" {
+		at, err := time.Parse("15:04", briefingAt)
+		if err != nil {
+			log.Fatalf("Invalid BRIEFING_AT: %v", err)
+		}
+		handlers.StartDailyBriefing(ctx, at.Hour(), at.Minute())
+	}
+
+	// Start retention enforcement (RETENTION_INTERVAL=0 disables it)
+	retentionInterval := 24 * time.Hour
+	if intervalStr := os.Getenv("RETENTION_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Invalid RETENTION_INTERVAL: %v", err)
+		}
+		retentionInterval = parsed
+	}
+	if retentionInterval > 0 {
+		handlers.StartRetention(ctx, retentionInterval)
+	}
+
+	// Start reporting usage to the billing sink (BILLING_REPORT_INTERVAL=0 disables it)
+	usageInterval := time.Minute
+	if intervalStr := os.Getenv("BILLING_REPORT_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Invalid BILLING_REPORT_INTERVAL: %v", err)
+		}
+		usageInterval = parsed
+	}
+	if usageInterval > 0 {
+		handlers.StartUsageReporting(ctx, usageInterval)
+	}
 
-	// Set up Gin router
-	router := gin.Default()
+	// Start the FSRS optimizer (FSRS_OPTIMIZE_INTERVAL=0 disables it)
+	optimizeInterval := 168 * time.Hour
+	if intervalStr := os.Getenv("FSRS_OPTIMIZE_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Invalid FSRS_OPTIMIZE_INTERVAL: %v", err)
+		}
+		optimizeInterval = parsed
+	}
+	if optimizeInterval > 0 {
+		handlers.StartFSRSOptimizer(ctx, optimizeInterval)
+	}
+
+	// Start recycling suggestions (RECYCLE_INTERVAL=0 disables them)
+	recycleInterval := 24 * time.Hour
+	if intervalStr := os.Getenv("RECYCLE_INTERVAL"); intervalStr != "" {
+		parsed, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Invalid RECYCLE_INTERVAL: %v", err)
+		}
+		recycleInterval = parsed
+	}
+	if recycleInterval > 0 {
+		handlers.StartRecycling(ctx, recycleInterval)
+	}
+
+	// Reload .env and PROMPTS_DIR on SIGHUP, without dropping in-flight jobs
+	handlers.StartReloadOnHangup(ctx)
+
+	// Set up Gin router. /api/capture and the podcast feed can carry an API
+	// token in their query strings, so they're left out of the request log.
+	router := gin.New()
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/api/capture", "/api/podcast/feed.xml"}}), gin.Recovery())
+
+	// Trust X-Forwarded-For only from TRUSTED_PROXIES (comma-separated), so
+	// client IPs used for demo quotas can't be spoofed
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Apply middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// API routes
 	api := router.Group("/api")
+	api.Use(middleware.Auth(), middleware.Ownership())
 	{
 		// Concept routes
 		concepts := api.Group("/concepts")
 		{
 			concepts.GET("", handlers.GetConcepts)
+			concepts.GET("/duplicates", handlers.GetDuplicateConcepts)
+			concepts.GET("/search", handlers.SearchConcepts)
 			concepts.GET("/:id", handlers.GetConcept)
+			concepts.GET("/:id/full", handlers.GetConceptFull)
+			concepts.GET("/:id/stats", handlers.GetConceptStats)
 			concepts.POST("", handlers.CreateConcept)
 			concepts.PATCH("/:id", handlers.UpdateConcept)
+			concepts.POST("/:id/archive", handlers.ArchiveConcept)
+			concepts.POST("/:id/unarchive", handlers.UnarchiveConcept)
+			concepts.POST("/:id/split", handlers.SplitConcept)
+			concepts.POST("/:id/diagram", handlers.GenerateConceptDiagram)
+			concepts.POST("/:id/split/confirm", handlers.ConfirmSplitConcept)
+			concepts.POST("/:id/merge", handlers.MergeConcept)
+			concepts.POST("/:id/relationships", handlers.CreateConceptRelationship)
+			concepts.POST("/:id/quizzes/generate", handlers.GenerateConceptQuizzes)
+			concepts.POST("/:id/share", handlers.ShareConcept)
+			concepts.DELETE("", handlers.DeleteConcepts)
 			concepts.DELETE("/:id", handlers.DeleteConcept)
 		}
 
 		// Source Content routes
 		sourceContent := api.Group("/source-content")
 		{
-			sourceContent.POST("", handlers.ProcessSourceContent)
+			sourceContent.POST("", handlers.RequireFeature(models.FeatureIngest), handlers.ProcessSourceContent)
 			sourceContent.GET("", handlers.GetSourceContents)
 			sourceContent.GET("/:id", handlers.GetSourceContent)
 			sourceContent.GET("/:id/concepts", handlers.GetSourceContentConcepts)
+			sourceContent.PUT("/:id/concepts/order", handlers.ReorderSourceContentConcepts)
 			sourceContent.GET("/:id/quizzes", handlers.GetSourceContentQuizzes)
+			sourceContent.GET("/:id/glossary", handlers.GetSourceContentGlossary)
+			sourceContent.GET("/:id/action-items", handlers.GetSourceContentActionItems)
+			sourceContent.GET("/:id/mentions", handlers.GetSourceContentMentions)
+			sourceContent.POST("/:id/chat", handlers.DiscussSourceContent)
+			sourceContent.POST("/:id/diagram", handlers.GenerateSourceDiagram)
+			sourceContent.POST("/:id/relationships/suggest", handlers.SuggestConceptRelationships)
+			sourceContent.GET("/:id/chats", handlers.GetSourceContentChats)
+			sourceContent.GET("/:id/chats/:chatId", handlers.GetSourceContentChat)
+			sourceContent.GET("/:id/hook-runs", handlers.GetSourceContentHookRuns)
+			sourceContent.GET("/:id/transcript", handlers.GetSourceContentTranscript)
+			sourceContent.PATCH("/:id/transcript", handlers.CorrectSourceContentTranscript)
+			sourceContent.GET("/:id/worksheet", handlers.GetSourceContentWorksheet)
+			sourceContent.GET("/:id/mindmap", handlers.GetSourceContentMindmap)
+			sourceContent.POST("/:id/audio-summary", handlers.GenerateAudioSummary)
+			sourceContent.GET("/:id/audio-summary", handlers.GetAudioSummary)
+			sourceContent.DELETE("/:id/audio-summary", handlers.DeleteAudioSummary)
 			sourceContent.GET("/:id/content", handlers.GetSourceContentGeneratedContent)
+			sourceContent.POST("/:id/feedback", handlers.CreateArtifactFeedback)
+			sourceContent.POST("/:id/retry", handlers.RetrySourceContentStage)
+			sourceContent.POST("/:id/resume", handlers.ResumeSourceContentPipeline)
+			sourceContent.POST("/:id/archive", handlers.ArchiveSourceContent)
+			sourceContent.POST("/:id/unarchive", handlers.UnarchiveSourceContent)
+			sourceContent.DELETE("", handlers.DeleteSourceContents)
 			sourceContent.DELETE("/:id", handlers.DeleteSourceContent)
 		}
 
+		// Meeting routes
+		meetings := api.Group("/meetings")
+		{
+			meetings.POST("", handlers.RequireFeature(models.FeatureIngest), handlers.ImportMeeting)
+			meetings.POST("/upload", handlers.RequireFeature(models.FeatureIngest), handlers.UploadMeeting)
+		}
+
+		// Podcast routes
+		api.POST("/podcasts", handlers.RequireFeature(models.FeatureIngest), handlers.ImportPodcast)
+
+		// Deck import routes (Anki, Logseq, RemNote)
+		api.POST("/imports", handlers.RequireFeature(models.FeatureIngest), handlers.ImportDeck)
+
+		// Externally transcribed source routes
+		transcriptions := api.Group("/transcriptions")
+		{
+			transcriptions.POST("", handlers.RequireFeature(models.FeatureIngest), handlers.CreateTranscription)
+			transcriptions.GET("", handlers.GetTranscriptions)
+			transcriptions.GET("/:id", handlers.GetTranscription)
+		}
+
+		// Bookmarklet capture
+		api.GET("/capture", handlers.RequireFeature(models.FeatureIngest), handlers.Capture)
+
+		// Private podcast feed of audio summaries
+		api.GET("/podcast/feed.xml", handlers.GetPodcastFeed)
+
+		// Background job routes
+		api.GET("/jobs/:id", handlers.GetJob)
+		api.GET("/jobs/:id/events", handlers.StreamJobEvents)
+
+		// Pipeline definition routes
+		pipelines := api.Group("/pipelines")
+		{
+			pipelines.GET("", handlers.GetPipelines)
+			pipelines.POST("", handlers.CreatePipeline)
+			pipelines.DELETE("/default", handlers.ClearDefaultPipeline)
+			pipelines.GET("/:id", handlers.GetPipeline)
+			pipelines.PUT("/:id", handlers.UpdatePipeline)
+			pipelines.POST("/:id/default", handlers.SetDefaultPipeline)
+			pipelines.DELETE("/:id", handlers.DeletePipeline)
+		}
+
+		// Processing profile routes
+		profiles := api.Group("/profiles")
+		{
+			profiles.GET("", handlers.GetProfiles)
+			profiles.POST("", handlers.CreateProfile)
+			profiles.GET("/:id", handlers.GetProfile)
+			profiles.PUT("/:id", handlers.UpdateProfile)
+			profiles.DELETE("/:id", handlers.DeleteProfile)
+		}
+
+		// Prompt experiment routes
+		experiments := api.Group("/experiments")
+		{
+			experiments.GET("", handlers.GetPromptExperiments)
+			experiments.POST("", handlers.CreatePromptExperiment)
+			experiments.GET("/:id", handlers.GetPromptExperimentReport)
+			experiments.POST("/:id/stop", handlers.StopPromptExperiment)
+			experiments.DELETE("/:id", handlers.DeletePromptExperiment)
+		}
+
+		// Concept extraction eval routes (runs come from cmd/eval)
+		evals := api.Group("/evals")
+		{
+			evals.GET("/cases", handlers.GetEvalCases)
+			evals.POST("/cases", handlers.CreateEvalCase)
+			evals.DELETE("/cases/:id", handlers.DeleteEvalCase)
+			evals.GET("/runs", handlers.GetEvalRuns)
+			evals.GET("/runs/:id", handlers.GetEvalRun)
+		}
+
+		// Pipeline hook routes
+		pipelineHooks := api.Group("/pipeline-hooks")
+		{
+			pipelineHooks.GET("", handlers.GetPipelineHooks)
+			pipelineHooks.POST("", handlers.CreatePipelineHook)
+			pipelineHooks.POST("/:id/enable", handlers.EnablePipelineHook)
+			pipelineHooks.POST("/:id/disable", handlers.DisablePipelineHook)
+			pipelineHooks.DELETE("/:id", handlers.DeletePipelineHook)
+		}
+
+		// Content script routes
+		contentScripts := api.Group("/content-scripts")
+		{
+			contentScripts.GET("", handlers.GetContentScripts)
+			contentScripts.POST("", handlers.CreateContentScript)
+			contentScripts.PUT("/:id", handlers.UpdateContentScript)
+			contentScripts.POST("/:id/preview", handlers.PreviewContentScript)
+			contentScripts.DELETE("/:id", handlers.DeleteContentScript)
+		}
+
+		// Generated content routes
+		content := api.Group("/content")
+		{
+			content.GET("", handlers.GetGeneratedContents)
+			content.GET("/:id/render", handlers.RenderGeneratedContent)
+			content.POST("/:id/share", handlers.ShareGeneratedContent)
+			content.POST("/:id/moderate", handlers.ModerateGeneratedContent)
+			content.PATCH("/:id/status", handlers.UpdateGeneratedContentStatus)
+			content.PUT("/:id/engagement", handlers.RecordContentEngagement)
+			content.POST("/:id/recycle", handlers.RecycleGeneratedContent)
+		}
+
+		// Recycling routes
+		recycling := api.Group("/recycling")
+		{
+			recycling.GET("/suggestions", handlers.GetRecycleSuggestions)
+			recycling.POST("/suggestions/:id/dismiss", handlers.DismissRecycleSuggestion)
+			recycling.POST("/run", handlers.RunRecycling)
+		}
+
+		// Content series routes
+		contentSeries := api.Group("/content-series")
+		{
+			contentSeries.GET("", handlers.GetContentSeriesList)
+			contentSeries.POST("", handlers.PlanContentSeries)
+			contentSeries.GET("/:id", handlers.GetContentSeries)
+		}
+
+		// Content calendar routes
+		api.GET("/calendar", handlers.GetContentCalendar)
+
+		// Share link routes
+		shares := api.Group("/shares")
+		{
+			shares.GET("", handlers.GetShareLinks)
+			shares.DELETE("/:token", handlers.DeleteShareLink)
+		}
+
+		// Output template routes
+		outputTemplates := api.Group("/output-templates")
+		{
+			outputTemplates.GET("", handlers.GetOutputTemplates)
+			outputTemplates.POST("", handlers.CreateOutputTemplate)
+			outputTemplates.PUT("/:id", handlers.UpdateOutputTemplate)
+			outputTemplates.DELETE("/:id", handlers.DeleteOutputTemplate)
+		}
+
+		// Comparison routes
+		compare := api.Group("/compare")
+		{
+			compare.POST("", handlers.CompareSources)
+			compare.GET("", handlers.GetComparisons)
+			compare.GET("/:id", handlers.GetComparison)
+		}
+
+		// Quiz routes
+		quizzes := api.Group("/quizzes")
+		{
+			quizzes.GET("/leeches", handlers.GetLeeches)
+			quizzes.GET("/attempts", handlers.GetQuizAttempts)
+			quizzes.GET("/:id/stats", handlers.GetQuizStats)
+			quizzes.POST("/:id/answer", handlers.AnswerQuizQuestion)
+			quizzes.POST("/:id/explain-more", handlers.ExplainQuizMore)
+			quizzes.POST("/:id/leech/regenerate", handlers.RegenerateLeech)
+			quizzes.POST("/:id/leech/flashcard", handlers.ConvertLeechToFlashcard)
+			quizzes.POST("/:id/unsuspend", handlers.UnsuspendQuizQuestion)
+		}
+
+		// Quiz session routes
+		quizSessions := api.Group("/quiz-sessions")
+		{
+			quizSessions.POST("", handlers.CreateQuizSession)
+			quizSessions.GET("/:id", handlers.GetQuizSession)
+			quizSessions.POST("/:id/answers", handlers.AnswerQuizSessionQuestion)
+		}
+
+		// Review routes
+		review := api.Group("/reviews")
+		{
+			review.GET("/session", handlers.GetReviewSession)
+			review.GET("/due", handlers.GetDueReviews)
+			review.POST("/:concept_id/complete", handlers.CompleteReview)
+			review.POST("/reschedule", handlers.RescheduleReviews)
+			review.POST("/optimize", handlers.OptimizeFSRS)
+			review.GET("/optimizations", handlers.GetFSRSOptimizations)
+		}
+
+		// Anki sync routes (for an AnkiConnect bridge)
+		anki := api.Group("/anki")
+		{
+			anki.GET("/sync", handlers.GetAnkiSyncState)
+			anki.GET("/notes/pending", handlers.GetPendingAnkiNotes)
+			anki.POST("/notes/links", handlers.LinkAnkiNotes)
+			anki.POST("/reviews", handlers.SyncAnkiReviews)
+		}
+
+		// Notification routes
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", handlers.GetNotifications)
+			notifications.PATCH("/:id/read", handlers.MarkNotificationRead)
+			notifications.POST("/read-all", handlers.MarkAllNotificationsRead)
+			notifications.GET("/preferences", handlers.GetNotificationPreferences)
+			notifications.PATCH("/preferences/:event", handlers.UpdateNotificationPreference)
+			notifications.GET("/push-subscriptions", handlers.GetPushSubscriptions)
+			notifications.POST("/push-subscriptions", handlers.CreatePushSubscription)
+			notifications.POST("/push-subscriptions/:id/test", handlers.TestPushSubscription)
+			notifications.DELETE("/push-subscriptions/:id", handlers.DeletePushSubscription)
+		}
+
+		// Daily briefing routes
+		briefings := api.Group("/briefings")
+		{
+			briefings.GET("", handlers.GetBriefings)
+			briefings.POST("", handlers.CreateBriefing)
+			briefings.GET("/:id", handlers.GetBriefing)
+		}
+
+		// Action item routes
+		actionItems := api.Group("/action-items")
+		{
+			actionItems.GET("", handlers.GetActionItems)
+			actionItems.PATCH("/:id", handlers.UpdateActionItem)
+		}
+
+		// Everything a dashboard needs on load
+		api.GET("/bootstrap", handlers.GetBootstrap)
+
+		// Caller identity, permissions, data export, and account deletion
+		api.GET("/me", handlers.GetMe)
+		api.PATCH("/me", handlers.UpdateMe)
+		api.DELETE("/me", handlers.DeleteMe)
+		api.POST("/me/export", handlers.ExportMyData)
+		api.GET("/me/data-requests", handlers.GetMyDataRequests)
+		api.GET("/me/data-requests/:id", handlers.GetMyDataRequest)
+
+		// User routes
+		users := api.Group("/users")
+		{
+			users.GET("", handlers.GetUsers)
+			users.POST("", handlers.InviteUser)
+			users.PATCH("/:id/role", handlers.UpdateUserRole)
+		}
+
+		// API token routes
+		tokens := api.Group("/tokens")
+		{
+			tokens.GET("", handlers.GetAPITokens)
+			tokens.POST("", handlers.CreateAPIToken)
+			tokens.PATCH("/:id", handlers.UpdateAPIToken)
+			tokens.DELETE("/:id", handlers.RevokeAPIToken)
+		}
+
+		// Credential routes
+		credentials := api.Group("/credentials")
+		{
+			credentials.GET("", handlers.GetCredentials)
+			credentials.POST("", handlers.CreateCredential)
+			credentials.POST("/rotate", handlers.RotateCredentials)
+			credentials.PUT("/:id", handlers.UpdateCredential)
+			credentials.DELETE("/:id", handlers.DeleteCredential)
+		}
+
+		// Retention routes
+		retention := api.Group("/retention")
+		{
+			retention.GET("/report", handlers.GetRetentionReport)
+			retention.POST("/run", handlers.RunRetention)
+		}
+
+		// Usage metering routes
+		usage := api.Group("/usage")
+		{
+			usage.GET("", handlers.GetUsage)
+			usage.POST("/report", handlers.ReportUsage)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		{
+			admin.GET("/tenants", handlers.GetTenants)
+			admin.GET("/users", handlers.GetUsers)
+			admin.GET("/jobs", handlers.GetJobQueue)
+			admin.GET("/spend", handlers.GetLLMSpend)
+			admin.GET("/auth-events", handlers.GetAuthEvents)
+			admin.GET("/features", handlers.GetFeatures)
+			admin.PATCH("/features", handlers.UpdateFeatures)
+			admin.POST("/reload", handlers.ReloadConfig)
+			admin.POST("/encryption/rotate", handlers.RotateEncryption)
+			admin.POST("/source-content/:id/reprocess", handlers.ReprocessSourceContent)
+			admin.DELETE("/source-content/:id", handlers.PurgeSourceContent)
+		}
+
+		// Settings routes
+		api.GET("/settings", handlers.GetSettings)
+		api.PATCH("/settings", handlers.UpdateSettings)
+
+		// Integrity routes
+		api.GET("/integrity", handlers.GetIntegrityReport)
+
+		// Glossary routes
+		api.GET("/glossary", handlers.GetGlossary)
+
+		// Mention routes
+		api.GET("/mentions", handlers.GetMentions)
+
+		// Collection routes
+		collections := api.Group("/collections")
+		{
+			collections.GET("", handlers.GetCollections)
+			collections.POST("", handlers.CreateCollection)
+			collections.GET("/:id", handlers.GetCollection)
+			collections.PUT("/:id", handlers.UpdateCollection)
+			collections.DELETE("/:id", handlers.DeleteCollection)
+			collections.GET("/:id/concepts", handlers.GetCollectionConcepts)
+		}
+
+		// Concept graph routes
+		api.GET("/relationships", handlers.GetConceptRelationships)
+		api.DELETE("/relationships/:id", handlers.DeleteConceptRelationship)
+
+		// Search routes
+		api.GET("/autocomplete", handlers.Autocomplete)
+		api.POST("/query", handlers.Query)
+
 		// Health check endpoint
 		api.GET("/health", func(c *gin.Context) {
 			c.JSON(200, gin.H{
@@ -75,15 +639,63 @@ func main() {
 		})
 	}
 
-	// Get port from environment variable or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Login routes
+	authRoutes := router.Group("/auth")
+	{
+		authRoutes.GET("/providers", handlers.GetAuthProviders)
+		authRoutes.GET("/:provider/login", handlers.Login)
+		authRoutes.GET("/:provider/callback", handlers.LoginCallback)
+		authRoutes.POST("/logout", handlers.Logout)
+	}
+
+	// Public share pages
+	router.GET("/share/:token", handlers.RequireFeature(models.FeatureShareLinks), handlers.GetSharePage)
+
+	// Public read API for share links, unauthenticated and limited to
+	// PUBLIC_API_RATE_LIMIT requests per minute per client IP
+	publicRateLimit := 60
+	if limitStr := os.Getenv("PUBLIC_API_RATE_LIMIT"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			log.Fatalf("Invalid PUBLIC_API_RATE_LIMIT: %q", limitStr)
+		}
+		publicRateLimit = parsed
+	}
+	public := router.Group("/public", middleware.RateLimit(publicRateLimit))
+	{
+		public.GET("/openapi.json", handlers.GetPublicAPISpec)
+		public.GET("/shares/:token", handlers.RequireFeature(models.FeatureShareLinks), handlers.GetPublicShare)
+	}
+
+	// Transcription provider callbacks, authenticated by the token in the URL
+	router.POST("/webhooks/transcriptions/:token", handlers.ReceiveTranscription)
+
+	// Signed downloads from local object storage
+	router.GET("/files/*key", handlers.ServeStoredFile)
+
+	// Public demo, unauthenticated and quota-limited (DEMO_MODE=true)
+	if handlers.DemoEnabled() {
+		router.POST("/demo/source-content", handlers.RequireFeature(models.FeatureIngest), handlers.ProcessDemo)
+	}
+
+	// Listen on PORT, UNIX_SOCKET, or a systemd socket, over TLS when
+	// TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS is set
+	serverConfig, err := server.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid server configuration: %v", err)
 	}
 
 	// Start server
-	log.Printf("Starting Lattice API server on port %s...", port)
-	if err := router.Run(":" + port); err != nil {
+	if serverConfig.TLS() {
+		log.Printf("Starting Lattice API server on %s with TLS...", serverConfig.Addr())
+	} else {
+		log.Printf("Starting Lattice API server on %s...", serverConfig.Addr())
+	}
+	stopBackground := func(shutdown context.Context) error {
+		cancel()
+		return handlers.WaitForBackground(shutdown)
+	}
+	if err := server.ListenAndServe(serverConfig, router, stopBackground); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider is an OAuth2 identity provider. Google and generic providers use
+// OIDC and its userinfo endpoint; GitHub uses its REST user API.
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+	github       bool
+}
+
+// Identity is the account a provider signed in
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Providers holds the configured providers by name, set by InitProviders
+var Providers = map[string]*Provider{}
+
+// httpClient is used for every provider call
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// InitProviders configures Google (GOOGLE_CLIENT_ID/SECRET), GitHub
+// (GITHUB_CLIENT_ID/SECRET), and a generic OIDC provider (OIDC_ISSUER,
+// OIDC_CLIENT_ID/SECRET, discovered from the issuer). Unset providers are skipped.
+func InitProviders(ctx context.Context) error {
+	if id := os.Getenv("GOOGLE_CLIENT_ID"); id != "" {
+		Providers["google"] = &Provider{
+			Name:         "google",
+			ClientID:     id,
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+			TokenURL:     "https://oauth2.googleapis.com/token",
+			UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+			Scopes:       []string{"openid", "email", "profile"},
+		}
+	}
+
+	if id := os.Getenv("GITHUB_CLIENT_ID"); id != "" {
+		Providers["github"] = &Provider{
+			Name:         "github",
+			ClientID:     id,
+			ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+			AuthURL:      "https://github.com/login/oauth/authorize",
+			TokenURL:     "https://github.com/login/oauth/access_token",
+			UserInfoURL:  "https://api.github.com/user",
+			Scopes:       []string{"read:user", "user:email"},
+			github:       true,
+		}
+	}
+
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		provider, err := discover(ctx, issuer)
+		if err != nil {
+			return err
+		}
+		provider.ClientID = os.Getenv("OIDC_CLIENT_ID")
+		provider.ClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+		Providers["oidc"] = provider
+	}
+
+	return nil
+}
+
+// discover reads an OIDC issuer's endpoints from its discovery document
+func discover(ctx context.Context, issuer string) (*Provider, error) {
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+
+	discoveryURL := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, discoveryURL, "", &doc); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", issuer, err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("OIDC issuer %s is missing authorization, token, or userinfo endpoints", issuer)
+	}
+
+	return &Provider{
+		Name:        "oidc",
+		AuthURL:     doc.AuthorizationEndpoint,
+		TokenURL:    doc.TokenEndpoint,
+		UserInfoURL: doc.UserinfoEndpoint,
+		Scopes:      []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// AuthCodeURL returns the provider's consent page URL for an authorization code flow
+func (p *Provider) AuthCodeURL(state, redirectURI string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+	return p.AuthURL + "?" + query.Encode()
+}
+
+// Exchange trades an authorization code for the signed-in identity
+func (p *Provider) Exchange(ctx context.Context, code, redirectURI string) (*Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, token.Error)
+	}
+
+	if p.github {
+		return githubIdentity(ctx, p.UserInfoURL, token.AccessToken)
+	}
+	return oidcIdentity(ctx, p.UserInfoURL, token.AccessToken)
+}
+
+// oidcIdentity reads the standard userinfo claims
+func oidcIdentity(ctx context.Context, userInfoURL, accessToken string) (*Identity, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := getJSON(ctx, userInfoURL, accessToken, &info); err != nil {
+		return nil, fmt.Errorf("failed to fetch userinfo: %w", err)
+	}
+	if info.Sub == "" {
+		return nil, fmt.Errorf("userinfo has no subject")
+	}
+
+	return &Identity{Subject: info.Sub, Email: info.Email, EmailVerified: info.EmailVerified, Name: info.Name}, nil
+}
+
+// githubIdentity reads the GitHub user and their primary verified email
+func githubIdentity(ctx context.Context, userURL, accessToken string) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, userURL, accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub user: %w", err)
+	}
+
+	identity := &Identity{Subject: strconv.FormatInt(user.ID, 10), Name: user.Name}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, userURL+"/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub emails: %w", err)
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			identity.Email = e.Email
+			identity.EmailVerified = true
+		}
+	}
+
+	return identity, nil
+}
+
+// getJSON GETs a URL, with a bearer token when given, and decodes the JSON response
+func getJSON(ctx context.Context, rawURL, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
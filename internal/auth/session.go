@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// SessionCookie is the cookie holding the session JWT after a browser login
+const SessionCookie = "lattice_session"
+
+// sessionIssuer is the iss claim of session tokens
+const sessionIssuer = "lattice"
+
+var (
+	// ErrSessionsDisabled is returned when SESSION_SECRET is not set
+	ErrSessionsDisabled = errors.New("SESSION_SECRET environment variable is not set")
+
+	// ErrInvalidSession is returned for session tokens that are malformed, forged, or expired
+	ErrInvalidSession = errors.New("session token is invalid or expired")
+)
+
+// SessionClaims are the claims of a session JWT
+type SessionClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"` // User ID
+	Email     string `json:"email,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// jwtHeader is the fixed header of session tokens
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// sessionSecret returns the HMAC key for session tokens
+func sessionSecret() ([]byte, error) {
+	secret := os.Getenv("SESSION_SECRET")
+	if secret == "" {
+		return nil, ErrSessionsDisabled
+	}
+	return []byte(secret), nil
+}
+
+// SessionTTL is how long sessions last: SESSION_TTL, defaulting to 24h
+func SessionTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("SESSION_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// IssueSession signs an HS256 session JWT for a user
+func IssueSession(userID int, email string) (string, time.Time, error) {
+	secret, err := sessionSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(SessionTTL())
+	claims, err := json.Marshal(SessionClaims{
+		Issuer:    sessionIssuer,
+		Subject:   fmt.Sprint(userID),
+		Email:     email,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode session claims: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + sign(secret, unsigned), expiresAt, nil
+}
+
+// ParseSession verifies a session JWT and returns its claims
+func ParseSession(token string) (*SessionClaims, error) {
+	secret, err := sessionSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidSession
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(secret, parts[0]+"."+parts[1]))) {
+		return nil, ErrInvalidSession
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+
+	var claims SessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidSession
+	}
+	if claims.Issuer != sessionIssuer || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidSession
+	}
+
+	return &claims, nil
+}
+
+// sign returns the base64url HMAC-SHA256 of a JWT's header and payload
+func sign(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
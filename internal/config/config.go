@@ -0,0 +1,107 @@
+// Package config loads the .env file into the environment, and reloads it
+// while the server runs so changed settings can take effect without a
+// restart. Variables the environment sets itself always win over the file.
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// envFile is the file Load and Reload read, relative to the working directory
+const envFile = ".env"
+
+var (
+	mu       sync.Mutex
+	fromFile = map[string]string{} // Variables envFile set, with their values
+)
+
+// Load sets the variables in .env that the environment doesn't already set
+func Load() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := godotenv.Read(envFile)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		os.Setenv(key, value)
+		fromFile[key] = value
+	}
+	return nil
+}
+
+// Reload reads .env again, setting the variables it adds or changes and
+// unsetting the ones removed from it, all but those the environment sets
+// itself. It returns the names of the variables that changed, sorted, and a
+// function that puts their previous values back, for when what they
+// configure fails to load. A missing .env counts as an empty one.
+func Reload() ([]string, func(), error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := godotenv.Read(envFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		values, err = map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previous := map[string]string{} // fromFile's values of the changed variables; missing ones it didn't set
+	changed := []string{}
+	for key, value := range values {
+		old, owned := fromFile[key]
+		if !owned {
+			if _, set := os.LookupEnv(key); set {
+				continue
+			}
+		}
+		if owned && old == value {
+			continue
+		}
+		if owned {
+			previous[key] = old
+		}
+		os.Setenv(key, value)
+		fromFile[key] = value
+		changed = append(changed, key)
+	}
+	for key, old := range fromFile {
+		if _, kept := values[key]; !kept {
+			os.Unsetenv(key)
+			delete(fromFile, key)
+			previous[key] = old
+			changed = append(changed, key)
+		}
+	}
+
+	slices.Sort(changed)
+	return changed, func() { revert(changed, previous) }, nil
+}
+
+// revert puts back the variables a reload changed, as previous had them
+func revert(changed []string, previous map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, key := range changed {
+		if value, owned := previous[key]; owned {
+			os.Setenv(key, value)
+			fromFile[key] = value
+		} else {
+			os.Unsetenv(key)
+			delete(fromFile, key)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// actionItemColumns is the column list scanned by scanActionItem
+const actionItemColumns = "id, source_content_id, concept_id, text, status, position, resolved_at, created_at"
+
+// scanActionItem scans a row selected with actionItemColumns
+func scanActionItem(row rowScanner, a *models.ActionItem) error {
+	return row.Scan(
+		&a.ID,
+		&a.SourceContentID,
+		&a.ConceptID,
+		&a.Text,
+		&a.Status,
+		&a.Position,
+		&a.ResolvedAt,
+		&a.CreatedAt,
+	)
+}
+
+// ReplaceActionItems replaces a source's open action items in a single
+// transaction. Items already done or dismissed are kept.
+func ReplaceActionItems(sourceContentID int, items []models.ActionItem) ([]models.ActionItem, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	_, err = tx.Exec(`
+		DELETE FROM action_items
+		WHERE source_content_id = $1 AND status = 'open'
+	`, sourceContentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear action items: %w", err)
+	}
+
+	query := `
+		INSERT INTO action_items (source_content_id, concept_id, text, position)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + actionItemColumns
+
+	saved := make([]models.ActionItem, 0, len(items))
+	for i, item := range items {
+		var a models.ActionItem
+		if err := scanActionItem(tx.QueryRow(query, sourceContentID, item.ConceptID, item.Text, i), &a); err != nil {
+			return nil, fmt.Errorf("failed to create action item: %w", err)
+		}
+		saved = append(saved, a)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return saved, nil
+}
+
+// GetActionItems retrieves action items of sources owner can see, optionally
+// filtered by source and status, in source order. Items from archived sources
+// are skipped unless a source is given.
+func GetActionItems(sourceContentID *int, status *string, owner *int) ([]models.ActionItem, error) {
+	query := `
+		SELECT a.id, a.source_content_id, a.concept_id, a.text, a.status, a.position, a.resolved_at, a.created_at
+		FROM action_items a
+		INNER JOIN source_contents s ON a.source_content_id = s.id
+		WHERE ($1::int IS NULL OR a.source_content_id = $1)
+			AND ($1::int IS NOT NULL OR s.archived_at IS NULL)
+			AND ($2::text IS NULL OR a.status = $2)
+			AND ` + visibleTo("s.owner_id", 3) + `
+		ORDER BY s.created_at DESC, a.position ASC, a.id ASC
+	`
+
+	rows, err := DB.Query(query, sourceContentID, status, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query action items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ActionItem{}
+	for rows.Next() {
+		var a models.ActionItem
+		if err := scanActionItem(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan action item: %w", err)
+		}
+		items = append(items, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating action items: %w", err)
+	}
+
+	return items, nil
+}
+
+// UpdateActionItemStatus sets an action item's status, stamping resolved_at
+// when it is done or dismissed and clearing it when reopened
+func UpdateActionItemStatus(id int, status string) (*models.ActionItem, error) {
+	query := `
+		UPDATE action_items
+		SET status = $2,
+			resolved_at = CASE
+				WHEN $2 = 'open' THEN NULL
+				WHEN status = $2 THEN resolved_at
+				ELSE NOW()
+			END
+		WHERE id = $1
+		RETURNING ` + actionItemColumns
+
+	var a models.ActionItem
+	err := scanActionItem(DB.QueryRow(query, id, status), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("action item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update action item: %w", err)
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetUnlinkedAnkiQuestions retrieves questions owner can see on active
+// concepts that have no Anki note yet. Suspended leeches are left out.
+func GetUnlinkedAnkiQuestions(owner *int) ([]models.QuizQuestion, error) {
+	query := `
+		SELECT q.id, q.concept_id, q.question, q.option_a, q.option_b, q.option_c, q.option_d,
+			q.correct_answer, q.explanation, q.distractor_rationales, q.lapses, q.suspended_at, q.created_at
+		FROM quiz_questions q
+		INNER JOIN concepts ON q.concept_id = concepts.id
+		WHERE q.suspended_at IS NULL
+			AND ` + visibleTo("q.owner_id", 1) + `
+			AND ` + conceptActiveCondition + `
+			AND NOT EXISTS (SELECT 1 FROM anki_note_links l WHERE l.question_id = q.id)
+		ORDER BY q.created_at ASC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unlinked questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []models.QuizQuestion
+	for rows.Next() {
+		var q models.QuizQuestion
+		if err := scanQuizQuestion(rows, &q); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
+		}
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unlinked questions: %w", err)
+	}
+
+	return questions, nil
+}
+
+// GetUnlinkedAnkiFlashcards retrieves flashcards on active concepts owner can
+// see that have no Anki note yet
+func GetUnlinkedAnkiFlashcards(owner *int) ([]models.Flashcard, error) {
+	query := `
+		SELECT f.id, f.concept_id, f.question_id, f.front, f.back, f.created_at
+		FROM flashcards f
+		INNER JOIN concepts ON f.concept_id = concepts.id
+		WHERE ` + conceptActiveCondition + `
+			AND ` + visibleTo("concepts.owner_id", 1) + `
+			AND NOT EXISTS (SELECT 1 FROM anki_note_links l WHERE l.flashcard_id = f.id)
+		ORDER BY f.created_at ASC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unlinked flashcards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []models.Flashcard
+	for rows.Next() {
+		var f models.Flashcard
+		if err := rows.Scan(&f.ID, &f.ConceptID, &f.QuestionID, &f.Front, &f.Back, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		cards = append(cards, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unlinked flashcards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// CreateAnkiNoteLinks stores resolved note links to items owner can see in a
+// single transaction. Re-linking an item to a new note replaces the old link.
+// A link to an item owner can't see, or that doesn't exist, is an invalid ref.
+func CreateAnkiNoteLinks(links []models.AnkiNoteLink, owner *int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	for _, link := range links {
+		var visible bool
+		err := tx.QueryRow(`
+			SELECT EXISTS (SELECT 1 FROM quiz_questions q WHERE q.id = $1 AND `+visibleTo("q.owner_id", 3)+`)
+				OR EXISTS (
+					SELECT 1 FROM flashcards f JOIN concepts c ON c.id = f.concept_id
+					WHERE f.id = $2 AND `+visibleTo("c.owner_id", 3)+`
+				)
+		`, link.QuestionID, link.FlashcardID, owner).Scan(&visible)
+		if err != nil {
+			return fmt.Errorf("failed to check anki note link: %w", err)
+		}
+		if !visible {
+			return fmt.Errorf("invalid ref %q", link.Ref)
+		}
+
+		_, err = tx.Exec(`
+			DELETE FROM anki_note_links
+			WHERE question_id = $1 OR flashcard_id = $2
+		`, link.QuestionID, link.FlashcardID)
+		if err != nil {
+			return fmt.Errorf("failed to replace anki note link: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO anki_note_links (anki_note_id, question_id, flashcard_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (anki_note_id) DO UPDATE SET
+				question_id = EXCLUDED.question_id,
+				flashcard_id = EXCLUDED.flashcard_id
+		`, link.AnkiNoteID, link.QuestionID, link.FlashcardID)
+		if err != nil {
+			return fmt.Errorf("failed to create anki note link: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetConceptIDForAnkiNote resolves a linked Anki note to its lattice concept,
+// when owner can see it. Returns nil without an error when the note isn't
+// linked to a concept they can.
+func GetConceptIDForAnkiNote(noteID int64, owner *int) (*int, error) {
+	query := `
+		SELECT c.id
+		FROM anki_note_links l
+		LEFT JOIN quiz_questions q ON l.question_id = q.id
+		LEFT JOIN flashcards f ON l.flashcard_id = f.id
+		JOIN concepts c ON c.id = COALESCE(q.concept_id, f.concept_id)
+		WHERE l.anki_note_id = $1 AND ` + visibleTo("c.owner_id", 2) + `
+	`
+
+	var conceptID int
+	err := DB.QueryRow(query, noteID, owner).Scan(&conceptID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, just not linked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anki note link: %w", err)
+	}
+
+	return &conceptID, nil
+}
+
+// CreateAnkiReview records an imported review. Returns false without an error
+// when the review was already imported.
+func CreateAnkiReview(review models.AnkiReview, conceptID int) (bool, error) {
+	query := `
+		INSERT INTO anki_reviews (anki_review_id, anki_note_id, concept_id, ease, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (anki_review_id) DO NOTHING
+	`
+
+	result, err := DB.Exec(query, review.ReviewID, review.NoteID, conceptID, review.Ease, review.DurationMs)
+	if err != nil {
+		return false, fmt.Errorf("failed to create anki review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetAnkiSyncCounts returns the newest imported review id (zero when none) and
+// the number of linked notes, of the concepts owner can see
+func GetAnkiSyncCounts(owner *int) (int64, int, error) {
+	query := `
+		SELECT
+			COALESCE((
+				SELECT MAX(r.anki_review_id) FROM anki_reviews r
+				JOIN concepts c ON c.id = r.concept_id
+				WHERE ` + visibleTo("c.owner_id", 1) + `
+			), 0),
+			(
+				SELECT COUNT(*) FROM anki_note_links l
+				LEFT JOIN quiz_questions q ON l.question_id = q.id
+				LEFT JOIN flashcards f ON l.flashcard_id = f.id
+				JOIN concepts c ON c.id = COALESCE(q.concept_id, f.concept_id)
+				WHERE ` + visibleTo("c.owner_id", 1) + `
+			)
+	`
+
+	var lastReviewID int64
+	var linked int
+	if err := DB.QueryRow(query, owner).Scan(&lastReviewID, &linked); err != nil {
+		return 0, 0, fmt.Errorf("failed to query anki sync state: %w", err)
+	}
+
+	return lastReviewID, linked, nil
+}
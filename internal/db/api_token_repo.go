@@ -0,0 +1,173 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// apiTokenColumns is the column list scanned by scanAPIToken
+const apiTokenColumns = "id, name, prefix, scopes, allowed_ips, expires_at, last_used_at, revoked_at, created_at"
+
+// scanAPIToken scans a row selected with apiTokenColumns
+func scanAPIToken(row rowScanner, t *models.APIToken) error {
+	return row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Prefix,
+		&t.Scopes,
+		&t.AllowedIPs,
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	)
+}
+
+// extraColumns scans columns selected after a scan function's own
+type extraColumns struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+// Scan implements rowScanner
+func (e extraColumns) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// CreateAPIToken stores a new token by its hash
+func CreateAPIToken(name, tokenHash, prefix string, scopes, allowedIPs []string, expiresAt *time.Time) (*models.APIToken, error) {
+	query := `
+		INSERT INTO api_tokens (name, token_hash, prefix, scopes, allowed_ips, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiTokenColumns
+
+	var t models.APIToken
+	err := scanAPIToken(DB.QueryRow(query, name, tokenHash, prefix, models.StringArray(scopes), models.StringArray(allowedIPs), expiresAt), &t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// GetAPITokens retrieves all tokens, including revoked and expired ones, newest first
+func GetAPITokens() ([]models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		ORDER BY created_at DESC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		var t models.APIToken
+		if err := scanAPIToken(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// AuthenticateAPIToken returns the live token with the given hash, and whether
+// its IP allowlist accepts ip, recording its use when it does. Returns nil
+// without an error when no unrevoked, unexpired token matches.
+func AuthenticateAPIToken(tokenHash, ip string) (*models.APIToken, bool, error) {
+	query := `
+		WITH live AS (
+			SELECT id AS token_id,
+				jsonb_array_length(allowed_ips) = 0 OR COALESCE(EXISTS (
+					SELECT 1 FROM jsonb_array_elements_text(allowed_ips) AS allowlist(range_text)
+					WHERE NULLIF($2, '')::inet <<= allowlist.range_text::cidr
+				), false) AS allowed
+			FROM api_tokens
+			WHERE token_hash = $1
+				AND revoked_at IS NULL
+				AND (expires_at IS NULL OR expires_at > NOW())
+		)
+		UPDATE api_tokens
+		SET last_used_at = CASE WHEN live.allowed THEN NOW() ELSE last_used_at END
+		FROM live
+		WHERE id = live.token_id
+		RETURNING ` + apiTokenColumns + `, live.allowed`
+
+	var t models.APIToken
+	var allowed bool
+	err := scanAPIToken(extraColumns{DB.QueryRow(query, tokenHash, ip), []interface{}{&allowed}}, &t)
+
+	if err == sql.ErrNoRows {
+		return nil, false, nil // Not an error, the token is unknown, revoked, or expired
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to authenticate api token: %w", err)
+	}
+
+	return &t, allowed, nil
+}
+
+// AuthEnabled reports whether any API token has been created or any user has
+// signed in, which turns on authentication. Revoked and expired tokens count.
+func AuthEnabled() (bool, error) {
+	var enabled bool
+	query := "SELECT EXISTS (SELECT 1 FROM api_tokens) OR EXISTS (SELECT 1 FROM users)"
+	if err := DB.QueryRow(query).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to check authentication state: %w", err)
+	}
+	return enabled, nil
+}
+
+// RevokeAPIToken revokes a token; it stops authenticating immediately
+func RevokeAPIToken(id int) (*models.APIToken, error) {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING ` + apiTokenColumns
+
+	var t models.APIToken
+	err := scanAPIToken(DB.QueryRow(query, id), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// UpdateAPITokenAllowedIPs replaces the CIDR ranges a token is accepted from
+func UpdateAPITokenAllowedIPs(id int, allowedIPs []string) (*models.APIToken, error) {
+	query := `
+		UPDATE api_tokens
+		SET allowed_ips = $2
+		WHERE id = $1
+		RETURNING ` + apiTokenColumns
+
+	var t models.APIToken
+	err := scanAPIToken(DB.QueryRow(query, id, models.StringArray(allowedIPs)), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update api token: %w", err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// audioSummaryColumns is the column list scanned by scanAudioSummary, from
+// audio_summaries a joined with its source s
+const audioSummaryColumns = "a.source_content_id, s.title, s.url, a.storage_key, a.size_bytes, a.provider, a.voice, a.created_at"
+
+// scanAudioSummary scans a row selected with audioSummaryColumns
+func scanAudioSummary(row rowScanner, a *models.AudioSummary) error {
+	return row.Scan(
+		&a.SourceContentID,
+		&a.Title,
+		&a.SourceURL,
+		&a.StorageKey,
+		&a.SizeBytes,
+		&a.Provider,
+		&a.Voice,
+		&a.CreatedAt,
+	)
+}
+
+// SaveAudioSummary stores a source's audio summary, replacing any earlier one
+func SaveAudioSummary(summary models.AudioSummary) (*models.AudioSummary, error) {
+	query := `
+		WITH a AS (
+			INSERT INTO audio_summaries (source_content_id, storage_key, size_bytes, provider, voice)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (source_content_id) DO UPDATE
+			SET storage_key = EXCLUDED.storage_key, size_bytes = EXCLUDED.size_bytes,
+				provider = EXCLUDED.provider, voice = EXCLUDED.voice, created_at = NOW()
+			RETURNING *
+		)
+		SELECT ` + audioSummaryColumns + `
+		FROM a
+		JOIN source_contents s ON s.id = a.source_content_id
+	`
+
+	var a models.AudioSummary
+	err := scanAudioSummary(DB.QueryRow(query, summary.SourceContentID, summary.StorageKey, summary.SizeBytes, summary.Provider, summary.Voice), &a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save audio summary: %w", err)
+	}
+
+	return &a, nil
+}
+
+// GetAudioSummary retrieves a source's audio summary
+func GetAudioSummary(sourceContentID int) (*models.AudioSummary, error) {
+	query := `
+		SELECT ` + audioSummaryColumns + `
+		FROM audio_summaries a
+		JOIN source_contents s ON s.id = a.source_content_id
+		WHERE a.source_content_id = $1
+	`
+
+	var a models.AudioSummary
+	err := scanAudioSummary(DB.QueryRow(query, sourceContentID), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("audio summary not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audio summary: %w", err)
+	}
+
+	return &a, nil
+}
+
+// GetAudioSummaries retrieves the newest limit audio summaries of unarchived
+// sources, newest first
+func GetAudioSummaries(limit int) ([]models.AudioSummary, error) {
+	query := `
+		SELECT ` + audioSummaryColumns + `
+		FROM audio_summaries a
+		JOIN source_contents s ON s.id = a.source_content_id
+		WHERE s.archived_at IS NULL
+		ORDER BY a.created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := DB.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audio summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.AudioSummary{}
+	for rows.Next() {
+		var a models.AudioSummary
+		if err := scanAudioSummary(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan audio summary: %w", err)
+		}
+		summaries = append(summaries, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audio summaries: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// DeleteAudioSummary deletes a source's audio summary, returning it so its
+// audio can be removed from storage
+func DeleteAudioSummary(sourceContentID int) (*models.AudioSummary, error) {
+	query := `
+		WITH a AS (
+			DELETE FROM audio_summaries
+			WHERE source_content_id = $1
+			RETURNING *
+		)
+		SELECT ` + audioSummaryColumns + `
+		FROM a
+		JOIN source_contents s ON s.id = a.source_content_id
+	`
+
+	var a models.AudioSummary
+	err := scanAudioSummary(DB.QueryRow(query, sourceContentID), &a)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("audio summary not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete audio summary: %w", err)
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// authEventColumns is the column list scanned by scanAuthEvent
+const authEventColumns = "id, event, success, user_id, api_token_id, provider, host(ip), user_agent, route, reason, created_at"
+
+// scanAuthEvent scans a row selected with authEventColumns
+func scanAuthEvent(row rowScanner, e *models.AuthEvent) error {
+	return row.Scan(
+		&e.ID,
+		&e.Event,
+		&e.Success,
+		&e.UserID,
+		&e.APITokenID,
+		&e.Provider,
+		&e.IP,
+		&e.UserAgent,
+		&e.Route,
+		&e.Reason,
+		&e.CreatedAt,
+	)
+}
+
+// RecordAuthEvent stores an authentication event. A token's use is recorded
+// at most once a minute from each address, since every request it
+// authenticates would otherwise add a row.
+func RecordAuthEvent(e models.AuthEvent) error {
+	query := `
+		INSERT INTO auth_events (event, success, user_id, api_token_id, provider, ip, user_agent, route, reason)
+		SELECT $1, $2::boolean, $3::integer, $4::integer, $5, NULLIF($6::text, '')::inet, $7, $8, $9
+		WHERE $1 <> 'token_used' OR NOT EXISTS (
+			SELECT 1 FROM auth_events
+			WHERE event = 'token_used' AND api_token_id = $4::integer
+				AND ip IS NOT DISTINCT FROM NULLIF($6::text, '')::inet
+				AND created_at > NOW() - INTERVAL '1 minute'
+		)
+	`
+
+	_, err := DB.Exec(query, e.Event, e.Success, e.UserID, e.APITokenID, e.Provider, e.IP, e.UserAgent, e.Route, e.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to record auth event: %w", err)
+	}
+	return nil
+}
+
+// GetAuthEvents returns a page of authentication events newest first,
+// filtered by query. An IP filter matches every address in a CIDR range.
+func GetAuthEvents(query models.AuthEventQuery, page models.Page) ([]models.AuthEvent, *models.Cursor, error) {
+	sqlQuery := `
+		SELECT ` + authEventColumns + `
+		FROM auth_events
+		WHERE ($1 = '' OR event = $1)
+			AND ($2::boolean IS NULL OR success = $2)
+			AND ($3::integer IS NULL OR user_id = $3)
+			AND ($4::integer IS NULL OR api_token_id = $4)
+			AND ($5 = '' OR ip <<= $5::inet)
+			AND ($6::timestamptz IS NULL OR (created_at, id) < ($6, $7))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $8
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	rows, err := DB.Query(sqlQuery, query.Event, query.Success, query.UserID, query.APITokenID, query.IP, afterTime, afterID, page.Limit+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query auth events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuthEvent{}
+	for rows.Next() {
+		var e models.AuthEvent
+		if err := scanAuthEvent(rows, &e); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan auth event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating auth events: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(events) > page.Limit {
+		events = events[:page.Limit]
+		last := events[len(events)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return events, next, nil
+}
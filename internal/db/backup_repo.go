@@ -0,0 +1,434 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// restoreBatch is how many rows RestoreDatabase inserts per statement
+const restoreBatch = 500
+
+// BlobColumn is a column naming an object in the object store
+type BlobColumn struct {
+	Table    string
+	Column   string
+	Required bool // Rows whose object is missing are deleted, rather than the column cleared
+}
+
+// BlobColumns lists every column naming a stored object, which a restore
+// re-links to the restored store
+var BlobColumns = []BlobColumn{
+	{Table: "audio_summaries", Column: "storage_key", Required: true},
+	{Table: "briefings", Column: "audio_key"},
+	{Table: "data_requests", Column: "export_key"},
+}
+
+// backupTable is a table holding backed-up rows, with the primary key its
+// rows are dumped in order of
+type backupTable struct {
+	name       string
+	primaryKey string
+}
+
+// getBackupTables returns every table except schema_migrations, referenced
+// tables before the tables referencing them
+func getBackupTables(tx *sql.Tx) ([]backupTable, error) {
+	rows, err := tx.Query(`
+		SELECT c.relname, COALESCE((
+			SELECT string_agg(quote_ident(a.attname), ', ' ORDER BY array_position(i.indkey::int2[], a.attnum))
+			FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+			WHERE i.indrelid = c.oid AND i.indisprimary
+		), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relkind = 'r' AND c.relname <> 'schema_migrations'
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []backupTable{}
+	for rows.Next() {
+		var t backupTable
+		if err := rows.Scan(&t.name, &t.primaryKey); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	refRows, err := tx.Query(`
+		SELECT DISTINCT cl.relname, ref.relname
+		FROM pg_constraint con
+		JOIN pg_class cl ON cl.oid = con.conrelid
+		JOIN pg_class ref ON ref.oid = con.confrelid
+		JOIN pg_namespace n ON n.oid = cl.relnamespace
+		WHERE con.contype = 'f' AND n.nspname = 'public' AND con.conrelid <> con.confrelid
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer refRows.Close()
+
+	references := map[string][]string{}
+	for refRows.Next() {
+		var table, referenced string
+		if err := refRows.Scan(&table, &referenced); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		references[table] = append(references[table], referenced)
+	}
+	if err = refRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return sortByReferences(tables, references), nil
+}
+
+// sortByReferences orders tables so each follows the tables it references,
+// keeping name order otherwise. Tables in a reference cycle go last.
+func sortByReferences(tables []backupTable, references map[string][]string) []backupTable {
+	placed := map[string]bool{}
+	sorted := make([]backupTable, 0, len(tables))
+	for len(sorted) < len(tables) {
+		progressed := false
+		for _, t := range tables {
+			if placed[t.name] {
+				continue
+			}
+			ready := true
+			for _, referenced := range references[t.name] {
+				if !placed[referenced] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[t.name] = true
+				sorted = append(sorted, t)
+				progressed = true
+			}
+		}
+		if !progressed {
+			for _, t := range tables {
+				if !placed[t.name] {
+					placed[t.name] = true
+					sorted = append(sorted, t)
+				}
+			}
+		}
+	}
+	return sorted
+}
+
+// getAppliedMigrations returns the applied migrations in order
+func getAppliedMigrations(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query("SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migrations: %w", err)
+	}
+	defer rows.Close()
+
+	migrations := []string{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		migrations = append(migrations, version)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// DumpDatabase reads every table from a single snapshot, so the rows are
+// consistent with each other while the server keeps writing, passing each
+// row to write as JSON. Text encrypted at rest stays encrypted. Returns the
+// applied migrations and the tables dumped, in restore order.
+func DumpDatabase(write func(table string, row json.RawMessage) error) ([]string, []models.BackupTable, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Read only, never committed
+
+	if _, err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+		return nil, nil, fmt.Errorf("failed to start snapshot: %w", err)
+	}
+
+	migrations, err := getAppliedMigrations(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables, err := getBackupTables(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dumped := make([]models.BackupTable, 0, len(tables))
+	for _, table := range tables {
+		n, err := dumpTable(tx, table, write)
+		if err != nil {
+			return nil, nil, err
+		}
+		dumped = append(dumped, models.BackupTable{Name: table.name, Rows: n})
+	}
+
+	return migrations, dumped, nil
+}
+
+// dumpTable passes a table's rows to write in primary key order, so rows
+// referencing earlier rows of the same table restore after them
+func dumpTable(tx *sql.Tx, table backupTable, write func(table string, row json.RawMessage) error) (int, error) {
+	query := fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", pq.QuoteIdentifier(table.name))
+	if table.primaryKey != "" {
+		query += " ORDER BY " + table.primaryKey
+	}
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dump %s: %w", table.name, err)
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return 0, fmt.Errorf("failed to scan %s: %w", table.name, err)
+		}
+		if err := write(table.name, row); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating %s: %w", table.name, err)
+	}
+
+	return n, nil
+}
+
+// RestoreDatabase loads a backup into a fresh instance in a single
+// transaction. The instance must have no users or sources and be at the
+// backup's migrations; the rows its migrations seeded are replaced. load is
+// called for each table in order and passes its rows to insert. Afterwards
+// sequences continue past the restored IDs, and references to objects stored
+// reports missing are unlinked. Returns the rows restored by table and the
+// references unlinked.
+func RestoreDatabase(migrations []string, tables []string, load func(table string, insert func(row json.RawMessage) error) error, stored func(key string) bool) (map[string]int, []models.BlobReference, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	applied, err := getAppliedMigrations(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.Join(applied, ",") != strings.Join(migrations, ",") {
+		return nil, nil, fmt.Errorf("backup is at migration %s but this instance is at %s; restore with the release that made the backup", lastOf(migrations), lastOf(applied))
+	}
+
+	var hasData bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM source_contents)").Scan(&hasData); err != nil {
+		return nil, nil, fmt.Errorf("failed to check for existing data: %w", err)
+	}
+	if hasData {
+		return nil, nil, fmt.Errorf("instance already has data; restore into a fresh database")
+	}
+
+	current, err := getBackupTables(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	known := map[string]bool{}
+	names := make([]string, 0, len(current))
+	for _, t := range current {
+		known[t.name] = true
+		names = append(names, pq.QuoteIdentifier(t.name))
+	}
+	for _, table := range tables {
+		if !known[table] {
+			return nil, nil, fmt.Errorf("backup table %s does not exist", table)
+		}
+	}
+
+	// Replace the rows seeded by migrations with the backup's
+	if _, err := tx.Exec("TRUNCATE " + strings.Join(names, ", ") + " RESTART IDENTITY CASCADE"); err != nil {
+		return nil, nil, fmt.Errorf("failed to empty tables: %w", err)
+	}
+
+	counts := map[string]int{}
+	for _, table := range tables {
+		n, err := restoreTable(tx, table, load)
+		if err != nil {
+			return nil, nil, err
+		}
+		counts[table] = n
+	}
+
+	if err := resetSequences(tx); err != nil {
+		return nil, nil, err
+	}
+
+	unlinked, err := unlinkMissingBlobs(tx, stored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return counts, unlinked, nil
+}
+
+// restoreTable inserts the rows load passes for a table, in batches
+func restoreTable(tx *sql.Tx, table string, load func(table string, insert func(row json.RawMessage) error) error) (int, error) {
+	quoted := pq.QuoteIdentifier(table)
+	query := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json)", quoted, quoted)
+
+	n := 0
+	batch := make([]json.RawMessage, 0, restoreBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		rows, err := json.Marshal(batch)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s rows: %w", table, err)
+		}
+		if _, err := tx.Exec(query, string(rows)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", table, err)
+		}
+		n += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := load(table, func(row json.RawMessage) error {
+		batch = append(batch, row)
+		if len(batch) < restoreBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// resetSequences moves each serial column's sequence past its largest value
+func resetSequences(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND column_default LIKE 'nextval(%'
+		ORDER BY table_name, column_name
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query sequences: %w", err)
+	}
+
+	type serialColumn struct{ table, column string }
+	columns := []serialColumn{}
+	for rows.Next() {
+		var c serialColumn
+		if err := rows.Scan(&c.table, &c.column); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		columns = append(columns, c)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sequences: %w", err)
+	}
+
+	for _, c := range columns {
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			pq.QuoteIdentifier(c.column), pq.QuoteIdentifier(c.table))
+		if _, err := tx.Exec(query, pq.QuoteIdentifier(c.table), c.column); err != nil {
+			return fmt.Errorf("failed to reset %s.%s sequence: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// unlinkMissingBlobs clears the BlobColumns naming objects stored reports
+// missing, deleting the rows that require their object
+func unlinkMissingBlobs(tx *sql.Tx, stored func(key string) bool) ([]models.BlobReference, error) {
+	unlinked := []models.BlobReference{}
+	for _, col := range BlobColumns {
+		keys, err := getBlobKeys(tx, col)
+		if err != nil {
+			return nil, err
+		}
+
+		query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = $1", col.Table, col.Column, col.Column)
+		if col.Required {
+			query = fmt.Sprintf("DELETE FROM %s WHERE %s = $1", col.Table, col.Column)
+		}
+		for _, key := range keys {
+			if stored(key) {
+				continue
+			}
+			if _, err := tx.Exec(query, key); err != nil {
+				return nil, fmt.Errorf("failed to unlink %s.%s: %w", col.Table, col.Column, err)
+			}
+			unlinked = append(unlinked, models.BlobReference{Table: col.Table, Column: col.Column, Key: key})
+		}
+	}
+	return unlinked, nil
+}
+
+// getBlobKeys returns the distinct object keys in a BlobColumn
+func getBlobKeys(tx *sql.Tx, col BlobColumn) ([]string, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL ORDER BY 1", col.Column, col.Table, col.Column))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s.%s: %w", col.Table, col.Column, err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan %s.%s: %w", col.Table, col.Column, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s.%s: %w", col.Table, col.Column, err)
+	}
+
+	return keys, nil
+}
+
+// lastOf returns the last migration in a list, or "none"
+func lastOf(migrations []string) string {
+	if len(migrations) == 0 {
+		return "none"
+	}
+	return migrations[len(migrations)-1]
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetWorkspaceCounts counts the active sources, concepts, and quiz questions
+// and the drafts owner can see, and unread notifications, in one query
+func GetWorkspaceCounts(owner *int) (*models.WorkspaceCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM source_contents WHERE archived_at IS NULL AND ` + visibleTo("owner_id", 2) + `),
+			(SELECT COUNT(*) FROM concepts WHERE ` + conceptActiveCondition + ` AND ` + visibleTo("concepts.owner_id", 2) + `),
+			(SELECT COUNT(*) FROM quiz_questions q
+				INNER JOIN concepts ON concepts.id = q.concept_id
+				WHERE q.suspended_at IS NULL AND ` + conceptActiveCondition + ` AND ` + visibleTo("q.owner_id", 2) + `),
+			(SELECT COUNT(*) FROM generated_contents WHERE status = $1 AND ` + visibleTo("owner_id", 2) + `),
+			(SELECT COUNT(*) FROM notifications WHERE read_at IS NULL)
+	`
+
+	var counts models.WorkspaceCounts
+	err := DB.QueryRow(query, models.ContentDraft, owner).Scan(
+		&counts.Sources,
+		&counts.Concepts,
+		&counts.QuizQuestions,
+		&counts.Drafts,
+		&counts.UnreadNotifications,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count workspace: %w", err)
+	}
+
+	return &counts, nil
+}
+
+// GetRecentSourceContents retrieves metadata for the newest limit active
+// sources owner can see
+func GetRecentSourceContents(limit int, owner *int) ([]models.SourceContentSummary, error) {
+	query := `
+		SELECT id, type, url, title, processed_at, archived_at, created_at
+		FROM source_contents
+		WHERE archived_at IS NULL AND ` + visibleTo("owner_id", 2) + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := DB.Query(query, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent source contents: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []models.SourceContentSummary{}
+	for rows.Next() {
+		var sc models.SourceContentSummary
+		err := rows.Scan(
+			&sc.ID,
+			&sc.Type,
+			&sc.URL,
+			&sc.Title,
+			&sc.ProcessedAt,
+			&sc.ArchivedAt,
+			&sc.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan source content: %w", err)
+		}
+		sources = append(sources, sc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source contents: %w", err)
+	}
+
+	return sources, nil
+}
+
+// GetRecentDrafts retrieves the limit most recently edited draft contents
+// owner can see
+func GetRecentDrafts(limit int, owner *int) ([]models.GeneratedContent, error) {
+	query := `
+		SELECT ` + generatedContentColumns + `
+		FROM generated_contents
+		WHERE status = $1 AND ` + visibleTo("owner_id", 3) + `
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := DB.Query(query, models.ContentDraft, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []models.GeneratedContent{}
+	for rows.Next() {
+		var gc models.GeneratedContent
+		if err := scanGeneratedContent(rows, &gc); err != nil {
+			return nil, fmt.Errorf("failed to scan generated content: %w", err)
+		}
+		drafts = append(drafts, gc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drafts: %w", err)
+	}
+
+	return drafts, nil
+}
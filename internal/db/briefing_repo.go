@@ -0,0 +1,237 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// briefingColumns is the column list scanned by scanBriefing
+const briefingColumns = "id, to_char(date, 'YYYY-MM-DD'), title, body, due_count, due_concept_ids, new_concept_ids, resurfaced_concept_id, audio_key, created_at"
+
+// briefingFor returns the condition keeping the briefings the owner in
+// argument n can see: their own, or every one for a nil owner. The
+// workspace's briefings name everyone's concepts, so they aren't shared.
+func briefingFor(n int) string {
+	return fmt.Sprintf("($%d::int IS NULL OR owner_id = $%d)", n, n)
+}
+
+// scanBriefing scans a row selected with briefingColumns
+func scanBriefing(row rowScanner, b *models.Briefing) error {
+	return row.Scan(
+		&b.ID,
+		&b.Date,
+		&b.Title,
+		&b.Body,
+		&b.DueCount,
+		&b.DueConceptIDs,
+		&b.NewConceptIDs,
+		&b.ResurfacedConceptID,
+		&b.AudioKey,
+		&b.CreatedAt,
+	)
+}
+
+// SaveBriefing stores a day's briefing, replacing any earlier one for that day
+// and its owner
+func SaveBriefing(briefing models.Briefing) (*models.Briefing, error) {
+	query := `
+		INSERT INTO briefings (date, title, body, due_count, due_concept_ids, new_concept_ids, resurfaced_concept_id, audio_key, owner_id)
+		VALUES ($1::date, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (date, (COALESCE(owner_id, 0))) DO UPDATE
+		SET title = EXCLUDED.title, body = EXCLUDED.body, due_count = EXCLUDED.due_count,
+			due_concept_ids = EXCLUDED.due_concept_ids, new_concept_ids = EXCLUDED.new_concept_ids,
+			resurfaced_concept_id = EXCLUDED.resurfaced_concept_id, audio_key = EXCLUDED.audio_key, created_at = NOW()
+		RETURNING ` + briefingColumns
+
+	var b models.Briefing
+	err := scanBriefing(DB.QueryRow(query,
+		briefing.Date,
+		briefing.Title,
+		briefing.Body,
+		briefing.DueCount,
+		briefing.DueConceptIDs,
+		briefing.NewConceptIDs,
+		briefing.ResurfacedConceptID,
+		briefing.AudioKey,
+		briefing.OwnerID,
+	), &b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save briefing: %w", err)
+	}
+
+	return &b, nil
+}
+
+// GetBriefingByID retrieves a briefing owner can see by ID
+func GetBriefingByID(id int, owner *int) (*models.Briefing, error) {
+	query := `SELECT ` + briefingColumns + ` FROM briefings WHERE id = $1 AND ` + briefingFor(2)
+
+	var b models.Briefing
+	err := scanBriefing(DB.QueryRow(query, id, owner), &b)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("briefing not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query briefing: %w", err)
+	}
+
+	return &b, nil
+}
+
+// GetBriefings retrieves the newest limit briefings owner can see, newest first
+func GetBriefings(limit int, owner *int) ([]models.Briefing, error) {
+	query := `
+		SELECT ` + briefingColumns + `
+		FROM briefings
+		WHERE ` + briefingFor(2) + `
+		ORDER BY date DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := DB.Query(query, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query briefings: %w", err)
+	}
+	defer rows.Close()
+
+	briefings := []models.Briefing{}
+	for rows.Next() {
+		var b models.Briefing
+		if err := scanBriefing(rows, &b); err != nil {
+			return nil, fmt.Errorf("failed to scan briefing: %w", err)
+		}
+		briefings = append(briefings, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating briefings: %w", err)
+	}
+
+	return briefings, nil
+}
+
+// GetPreviousBriefing retrieves owner's newest briefing, or the workspace's
+// for a nil owner, for a day before date (YYYY-MM-DD), or nil when there is none
+func GetPreviousBriefing(date string, owner *int) (*models.Briefing, error) {
+	query := `
+		SELECT ` + briefingColumns + `
+		FROM briefings
+		WHERE date < $1::date AND owner_id IS NOT DISTINCT FROM $2
+		ORDER BY date DESC
+		LIMIT 1
+	`
+
+	var b models.Briefing
+	err := scanBriefing(DB.QueryRow(query, date, owner), &b)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous briefing: %w", err)
+	}
+
+	return &b, nil
+}
+
+// GetDueConcepts retrieves up to limit active concepts owner can see whose
+// next review is due before dueBefore, most overdue first
+func GetDueConcepts(dueBefore time.Time, limit int, owner *int) ([]models.Concept, error) {
+	query := `
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE ` + conceptActiveCondition + `
+			AND ` + visibleTo("concepts.owner_id", 3) + `
+			AND EXISTS (
+				SELECT 1 FROM learning_progress lp
+				WHERE lp.concept_id = concepts.id AND lp.next_review_at < $1
+			)
+		ORDER BY (SELECT lp.next_review_at FROM learning_progress lp WHERE lp.concept_id = concepts.id) ASC, id ASC
+		LIMIT $2
+	`
+
+	return queryBriefingConcepts(query, "due concepts", dueBefore, limit, owner)
+}
+
+// GetConceptsCreatedSince retrieves up to limit active concepts owner can see
+// created after since, oldest first
+func GetConceptsCreatedSince(since time.Time, limit int, owner *int) ([]models.Concept, error) {
+	query := `
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE created_at > $1
+			AND ` + visibleTo("concepts.owner_id", 3) + `
+			AND ` + conceptActiveCondition + `
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2
+	`
+
+	return queryBriefingConcepts(query, "new concepts", since, limit, owner)
+}
+
+// GetResurfaceConcept picks an active concept owner can see created before
+// createdBefore to resurface: the one gone longest without a review, skipping
+// concepts due before dueBefore and those resurfaced by owner's briefings
+// since resurfacedSince. Returns nil when none qualifies.
+func GetResurfaceConcept(createdBefore, dueBefore, resurfacedSince time.Time, owner *int) (*models.Concept, error) {
+	query := `
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE created_at < $1
+			AND ` + visibleTo("concepts.owner_id", 4) + `
+			AND ` + conceptActiveCondition + `
+			AND NOT EXISTS (
+				SELECT 1 FROM learning_progress lp
+				WHERE lp.concept_id = concepts.id AND lp.next_review_at < $2
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM briefings b
+				WHERE b.resurfaced_concept_id = concepts.id AND b.created_at >= $3
+					AND b.owner_id IS NOT DISTINCT FROM $4
+			)
+		ORDER BY
+			COALESCE((SELECT lp.last_reviewed_at FROM learning_progress lp WHERE lp.concept_id = concepts.id), created_at) ASC,
+			id ASC
+		LIMIT 1
+	`
+
+	var c models.Concept
+	err := scanConcept(DB.QueryRow(query, createdBefore, dueBefore, resurfacedSince, owner), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resurface concept: %w", err)
+	}
+
+	return &c, nil
+}
+
+// queryBriefingConcepts runs a concept query for a briefing
+func queryBriefingConcepts(query, what string, args ...interface{}) ([]models.Concept, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", what, err)
+	}
+	defer rows.Close()
+
+	concepts := []models.Concept{}
+	for rows.Next() {
+		var c models.Concept
+		if err := scanConcept(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan concept: %w", err)
+		}
+		concepts = append(concepts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", what, err)
+	}
+
+	return concepts, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// bulkDelete deletes the rows of table matching where in a single transaction.
+// It first counts them, and with each cascade query the rows their delete
+// takes along; cascade queries are keyed by the table they count and select
+// from "matched", the IDs being deleted. A dry run only counts.
+func bulkDelete(table, where string, args []interface{}, cascades map[string]string, dryRun bool) (*models.BulkDeleteResult, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed; always on a dry run
+
+	rows, err := tx.Query(fmt.Sprintf("SELECT id FROM %s WHERE %s ORDER BY id FOR UPDATE", table, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan %s id: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", table, err)
+	}
+
+	result := &models.BulkDeleteResult{
+		DryRun: dryRun,
+		IDs:    ids,
+		Counts: map[string]int{table: len(ids)},
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	matched := fmt.Sprintf("WITH matched AS (SELECT id FROM %s WHERE %s) ", table, where)
+	for name, query := range cascades {
+		var count int
+		if err := tx.QueryRow(matched+query, args...).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", name, err)
+		}
+		result.Counts[name] = count
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", table, where), args...); err != nil {
+		return nil, fmt.Errorf("failed to delete %s: %w", table, err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CreateSourceChat starts a new conversation about a source
+func CreateSourceChat(sourceContentID int) (*models.SourceChat, error) {
+	query := `
+		INSERT INTO source_chats (source_content_id)
+		VALUES ($1)
+		RETURNING id, source_content_id, created_at
+	`
+
+	var chat models.SourceChat
+	err := DB.QueryRow(query, sourceContentID).Scan(&chat.ID, &chat.SourceContentID, &chat.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source chat: %w", err)
+	}
+
+	return &chat, nil
+}
+
+// GetSourceChatByID retrieves a single source chat by ID
+func GetSourceChatByID(id int) (*models.SourceChat, error) {
+	query := `
+		SELECT id, source_content_id, created_at
+		FROM source_chats
+		WHERE id = $1
+	`
+
+	var chat models.SourceChat
+	err := DB.QueryRow(query, id).Scan(&chat.ID, &chat.SourceContentID, &chat.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("chat not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source chat: %w", err)
+	}
+
+	return &chat, nil
+}
+
+// GetSourceChatsBySourceContentID retrieves a source's chats, newest first
+func GetSourceChatsBySourceContentID(sourceContentID int) ([]models.SourceChat, error) {
+	query := `
+		SELECT id, source_content_id, created_at
+		FROM source_chats
+		WHERE source_content_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, sourceContentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source chats: %w", err)
+	}
+	defer rows.Close()
+
+	chats := []models.SourceChat{}
+	for rows.Next() {
+		var chat models.SourceChat
+		if err := rows.Scan(&chat.ID, &chat.SourceContentID, &chat.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan source chat: %w", err)
+		}
+		chats = append(chats, chat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source chats: %w", err)
+	}
+
+	return chats, nil
+}
+
+// GetChatMessages retrieves a chat's messages, oldest first
+func GetChatMessages(chatID int) ([]models.ChatMessage, error) {
+	query := `
+		SELECT id, chat_id, role, content, created_at
+		FROM source_chat_messages
+		WHERE chat_id = $1
+		ORDER BY id ASC
+	`
+
+	rows, err := DB.Query(query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		var m models.ChatMessage
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chat messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// CreateChatExchange stores a user message and the assistant's reply in a single transaction
+func CreateChatExchange(chatID int, userMessage, reply string) (*models.ChatMessage, *models.ChatMessage, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	query := `
+		INSERT INTO source_chat_messages (chat_id, role, content)
+		VALUES ($1, $2, $3)
+		RETURNING id, chat_id, role, content, created_at
+	`
+
+	var saved [2]models.ChatMessage
+	for i, m := range []struct{ role, content string }{
+		{models.ChatRoleUser, userMessage},
+		{models.ChatRoleAssistant, reply},
+	} {
+		err := tx.QueryRow(query, chatID, m.role, m.content).Scan(
+			&saved[i].ID,
+			&saved[i].ChatID,
+			&saved[i].Role,
+			&saved[i].Content,
+			&saved[i].CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create chat message: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &saved[0], &saved[1], nil
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// collectionColumns is the column list scanned by scanCollection
+const collectionColumns = "id, name, description, query, created_at, updated_at"
+
+// scanCollection scans a row selected with collectionColumns
+func scanCollection(row rowScanner, c *models.Collection) error {
+	return row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Description,
+		&c.Query,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+// CreateCollection stores a new collection. Its query isn't checked here.
+func CreateCollection(req models.CollectionRequest) (*models.Collection, error) {
+	query := `
+		INSERT INTO collections (name, description, query)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + collectionColumns
+
+	var c models.Collection
+	err := scanCollection(DB.QueryRow(query, req.Name, req.Description, req.Query), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetCollections retrieves every collection by name
+func GetCollections() ([]models.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections
+		ORDER BY LOWER(name) ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		var c models.Collection
+		if err := scanCollection(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// GetCollectionByID retrieves a single collection by ID
+func GetCollectionByID(id int) (*models.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections
+		WHERE id = $1
+	`
+
+	var c models.Collection
+	err := scanCollection(DB.QueryRow(query, id), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection: %w", err)
+	}
+
+	return &c, nil
+}
+
+// UpdateCollection replaces a collection's name, description, and query
+func UpdateCollection(id int, req models.CollectionRequest) (*models.Collection, error) {
+	if _, err := GetCollectionByID(id); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE collections
+		SET name = $2, description = $3, query = $4, updated_at = NOW()
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM collections o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + collectionColumns
+
+	var c models.Collection
+	err := scanCollection(DB.QueryRow(query, id, req.Name, req.Description, req.Query), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("collection name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return &c, nil
+}
+
+// DeleteCollection deletes a collection. Review sessions and content series
+// drawn from it are kept.
+func DeleteCollection(id int) error {
+	result, err := DB.Exec(`DELETE FROM collections WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("collection not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// sourceComparisonColumns is the column list scanned by scanSourceComparison
+const sourceComparisonColumns = "id, source_content_ids, summary, details, created_at"
+
+// comparisonVisibleTo returns the condition keeping the comparisons the owner
+// in argument n can see: those of no source they can't
+func comparisonVisibleTo(n int) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM source_contents s
+		WHERE s.id IN (SELECT jsonb_array_elements_text(source_comparisons.source_content_ids)::int)
+			AND NOT ` + visibleTo("s.owner_id", n) + `
+	)`
+}
+
+// scanSourceComparison scans a row selected with sourceComparisonColumns
+func scanSourceComparison(row rowScanner, c *models.SourceComparison) error {
+	return row.Scan(
+		&c.ID,
+		&c.SourceContentIDs,
+		&c.Summary,
+		&c.Details,
+		&c.CreatedAt,
+	)
+}
+
+// CreateSourceComparison stores a source comparison
+func CreateSourceComparison(comparison models.SourceComparison) (*models.SourceComparison, error) {
+	query := `
+		INSERT INTO source_comparisons (source_content_ids, summary, details)
+		VALUES ($1, $2, $3)
+		RETURNING ` + sourceComparisonColumns
+
+	var c models.SourceComparison
+	err := scanSourceComparison(DB.QueryRow(query, comparison.SourceContentIDs, comparison.Summary, comparison.Details), &c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source comparison: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetSourceComparisonByID retrieves a source comparison owner can see by ID
+func GetSourceComparisonByID(id int, owner *int) (*models.SourceComparison, error) {
+	query := `
+		SELECT ` + sourceComparisonColumns + `
+		FROM source_comparisons
+		WHERE id = $1 AND ` + comparisonVisibleTo(2) + `
+	`
+
+	var c models.SourceComparison
+	err := scanSourceComparison(DB.QueryRow(query, id, owner), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("comparison not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source comparison: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetSourceComparisons retrieves the source comparisons owner can see,
+// newest first
+func GetSourceComparisons(owner *int) ([]models.SourceComparison, error) {
+	query := `
+		SELECT ` + sourceComparisonColumns + `
+		FROM source_comparisons
+		WHERE ` + comparisonVisibleTo(1) + `
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source comparisons: %w", err)
+	}
+	defer rows.Close()
+
+	comparisons := []models.SourceComparison{}
+	for rows.Next() {
+		var c models.SourceComparison
+		if err := scanSourceComparison(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan source comparison: %w", err)
+		}
+		comparisons = append(comparisons, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source comparisons: %w", err)
+	}
+
+	return comparisons, nil
+}
@@ -3,65 +3,135 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/mostlyerror/lattice/internal/models"
 )
 
-// GetAllConcepts retrieves all concepts from the database
-func GetAllConcepts() ([]models.Concept, error) {
-	query := `
-		SELECT id, title, description, source_content_id, created_at, updated_at
+// conceptColumns is the column list scanned by scanConcept
+const conceptColumns = "id, title, description, source_content_id, tags, position, pinned, archived_at, created_at, updated_at"
+
+// conceptActiveCondition excludes archived concepts and concepts whose source is archived
+const conceptActiveCondition = `concepts.archived_at IS NULL AND NOT EXISTS (
+	SELECT 1 FROM source_contents s
+	WHERE s.id = concepts.source_content_id AND s.archived_at IS NOT NULL
+)`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDests collects the destinations scan functions pass it, so a row
+// joining several tables can be scanned with each table's function
+type scanDests []interface{}
+
+func (d *scanDests) Scan(dest ...interface{}) error {
+	*d = append(*d, dest...)
+	return nil
+}
+
+// qualified prefixes each column of a column list with table
+func qualified(table, columns string) string {
+	return table + "." + strings.ReplaceAll(columns, ", ", ", "+table+".")
+}
+
+// scanConcept scans a row selected with conceptColumns
+func scanConcept(row rowScanner, c *models.Concept) error {
+	return row.Scan(
+		&c.ID,
+		&c.Title,
+		&c.Description,
+		&c.SourceContentID,
+		&c.Tags,
+		&c.Position,
+		&c.Pinned,
+		&c.ArchivedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+// GetConcepts retrieves a page of concepts filtered and sorted by query,
+// skipping archived ones unless query.IncludeArchived is set. Sorting by
+// resonance is left to the caller; those pages come in creation order. The
+// returned cursor is nil on the last page.
+func GetConcepts(query models.ConceptListQuery, page models.Page) ([]models.Concept, *models.Cursor, error) {
+	where := "TRUE"
+	args := []interface{}{}
+
+	if !query.IncludeArchived {
+		where += " AND " + conceptActiveCondition
+	}
+	if query.SourceContentID != nil {
+		args = append(args, *query.SourceContentID)
+		where += fmt.Sprintf(" AND concepts.source_content_id = $%d", len(args))
+	}
+	if query.Tag != "" {
+		args = append(args, query.Tag)
+		where += fmt.Sprintf(" AND concepts.tags @> jsonb_build_array($%d::text)", len(args))
+	}
+	var dates, owned string
+	dates, args = dateRangeClause("concepts.created_at", query.From, query.To, args)
+	where += dates
+	owned, args = ownerClause("concepts.owner_id", query.OwnerID, args)
+	where += owned
+
+	sortColumn := "concepts.created_at"
+	if query.Sort == "updated_at" {
+		sortColumn = "concepts.updated_at"
+	}
+	var paging string
+	paging, args = pageClause(sortColumn, "concepts.id", query.Order == models.OrderAsc, page, args)
+
+	sqlQuery := `
+		SELECT ` + conceptColumns + `
 		FROM concepts
-		ORDER BY created_at DESC
-	`
+		WHERE ` + where + paging
 
-	rows, err := DB.Query(query)
+	rows, err := DB.Query(sqlQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query concepts: %w", err)
+		return nil, nil, fmt.Errorf("failed to query concepts: %w", err)
 	}
 	defer rows.Close()
 
-	var concepts []models.Concept
+	concepts := []models.Concept{}
 	for rows.Next() {
 		var c models.Concept
-		err := rows.Scan(
-			&c.ID,
-			&c.Title,
-			&c.Description,
-			&c.SourceContentID,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan concept: %w", err)
+		if err := scanConcept(rows, &c); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan concept: %w", err)
 		}
 		concepts = append(concepts, c)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating concepts: %w", err)
+		return nil, nil, fmt.Errorf("error iterating concepts: %w", err)
 	}
 
-	return concepts, nil
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(concepts) > page.Limit {
+		concepts = concepts[:page.Limit]
+		last := concepts[len(concepts)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if query.Sort == "updated_at" {
+			next.CreatedAt = last.UpdatedAt
+		}
+	}
+
+	return concepts, next, nil
 }
 
 // GetConceptByID retrieves a single concept by ID
 func GetConceptByID(id int) (*models.Concept, error) {
 	query := `
-		SELECT id, title, description, source_content_id, created_at, updated_at
+		SELECT ` + conceptColumns + `
 		FROM concepts
 		WHERE id = $1
 	`
 
 	var c models.Concept
-	err := DB.QueryRow(query, id).Scan(
-		&c.ID,
-		&c.Title,
-		&c.Description,
-		&c.SourceContentID,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	err := scanConcept(DB.QueryRow(query, id), &c)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("concept not found")
@@ -76,25 +146,19 @@ func GetConceptByID(id int) (*models.Concept, error) {
 // CreateConcept creates a new concept in the database
 func CreateConcept(req models.CreateConceptRequest) (*models.Concept, error) {
 	query := `
-		INSERT INTO concepts (title, description, source_content_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, title, description, source_content_id, created_at, updated_at
-	`
+		INSERT INTO concepts (title, description, source_content_id, tags, owner_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + conceptColumns
 
 	var c models.Concept
-	err := DB.QueryRow(
+	err := scanConcept(DB.QueryRow(
 		query,
 		req.Title,
 		req.Description,
 		req.SourceContentID,
-	).Scan(
-		&c.ID,
-		&c.Title,
-		&c.Description,
-		&c.SourceContentID,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+		models.StringArray(req.Tags),
+		req.OwnerID,
+	), &c)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create concept: %w", err)
@@ -122,21 +186,52 @@ func UpdateConcept(id int, req models.UpdateConceptRequest) (*models.Concept, er
 		argCount++
 	}
 
+	if req.Tags != nil {
+		query += fmt.Sprintf("tags = $%d, ", argCount)
+		args = append(args, models.StringArray(*req.Tags))
+		argCount++
+	}
+
+	if req.Pinned != nil {
+		query += fmt.Sprintf("pinned = $%d, ", argCount)
+		args = append(args, *req.Pinned)
+		argCount++
+	}
+
+	// Hand edits to the generated text feed prompt experiment metrics
+	if req.Title != nil || req.Description != nil || req.Tags != nil {
+		query += "edited_at = NOW(), "
+	}
+
 	// Remove trailing comma and space
 	query = query[:len(query)-2]
 
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, title, description, source_content_id, created_at, updated_at", argCount)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING %s", argCount, conceptColumns)
 	args = append(args, id)
 
 	var c models.Concept
-	err := DB.QueryRow(query, args...).Scan(
-		&c.ID,
-		&c.Title,
-		&c.Description,
-		&c.SourceContentID,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	err := scanConcept(DB.QueryRow(query, args...), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("concept not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update concept: %w", err)
+	}
+
+	return &c, nil
+}
+
+// SetConceptArchived archives or unarchives a concept
+func SetConceptArchived(id int, archived bool) (*models.Concept, error) {
+	query := `
+		UPDATE concepts
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) ELSE NULL END
+		WHERE id = $1
+		RETURNING ` + conceptColumns
+
+	var c models.Concept
+	err := scanConcept(DB.QueryRow(query, id, archived), &c)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("concept not found")
@@ -148,6 +243,28 @@ func UpdateConcept(id int, req models.UpdateConceptRequest) (*models.Concept, er
 	return &c, nil
 }
 
+// ArchiveConceptsBySourceContentID archives all of a source's active concepts,
+// returning how many were archived
+func ArchiveConceptsBySourceContentID(sourceContentID int) (int, error) {
+	query := `
+		UPDATE concepts
+		SET archived_at = NOW()
+		WHERE source_content_id = $1 AND archived_at IS NULL
+	`
+
+	result, err := DB.Exec(query, sourceContentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive concepts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
 // DeleteConcept deletes a concept by ID
 func DeleteConcept(id int) error {
 	query := "DELETE FROM concepts WHERE id = $1"
@@ -169,14 +286,83 @@ func DeleteConcept(id int) error {
 	return nil
 }
 
-// GetConceptsBySourceContentID retrieves all concepts for a source content
-func GetConceptsBySourceContentID(sourceContentID int) ([]models.Concept, error) {
+// DeleteConcepts deletes the concepts req selects, with their quizzes by
+// cascade. On a dry run it only counts them.
+func DeleteConcepts(req models.BulkDeleteConceptsRequest, dryRun bool) (*models.BulkDeleteResult, error) {
+	where := "TRUE"
+	args := []interface{}{}
+
+	if req.SourceContentID != nil {
+		args = append(args, *req.SourceContentID)
+		where += fmt.Sprintf(" AND source_content_id = $%d", len(args))
+	}
+	if req.Tag != "" {
+		args = append(args, req.Tag)
+		where += fmt.Sprintf(" AND tags @> jsonb_build_array($%d::text)", len(args))
+	}
+	if req.Before != nil {
+		args = append(args, *req.Before)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if req.Archived {
+		where += " AND archived_at IS NOT NULL"
+	}
+	if req.Detached {
+		where += " AND source_content_id IS NULL"
+	}
+	var owned string
+	owned, args = ownerClause("owner_id", req.OwnerID, args)
+	where += owned
+
+	return bulkDelete("concepts", where, args, map[string]string{
+		"quiz_questions": "SELECT COUNT(*) FROM quiz_questions WHERE concept_id IN (SELECT id FROM matched)",
+	}, dryRun)
+}
+
+// GetConceptsByTag retrieves the active concepts with a tag, oldest first
+func GetConceptsByTag(tag string, owner *int) ([]models.Concept, error) {
 	query := `
-		SELECT id, title, description, source_content_id, created_at, updated_at
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE tags @> jsonb_build_array($1::text) AND ` + conceptActiveCondition + `
+			AND ` + visibleTo("concepts.owner_id", 2) + `
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := DB.Query(query, tag, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query concepts: %w", err)
+	}
+	defer rows.Close()
+
+	concepts := []models.Concept{}
+	for rows.Next() {
+		var c models.Concept
+		if err := scanConcept(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan concept: %w", err)
+		}
+		concepts = append(concepts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating concepts: %w", err)
+	}
+
+	return concepts, nil
+}
+
+// GetConceptsBySourceContentID retrieves all concepts for a source content in teaching order
+// (pinned first, then by position, then newest), skipping archived ones unless includeArchived is set
+func GetConceptsBySourceContentID(sourceContentID int, includeArchived bool) ([]models.Concept, error) {
+	query := `
+		SELECT ` + conceptColumns + `
 		FROM concepts
 		WHERE source_content_id = $1
-		ORDER BY created_at DESC
 	`
+	if !includeArchived {
+		query += " AND archived_at IS NULL"
+	}
+	query += " ORDER BY pinned DESC, position ASC NULLS LAST, created_at DESC"
 
 	rows, err := DB.Query(query, sourceContentID)
 	if err != nil {
@@ -187,14 +373,7 @@ func GetConceptsBySourceContentID(sourceContentID int) ([]models.Concept, error)
 	var concepts []models.Concept
 	for rows.Next() {
 		var c models.Concept
-		err := rows.Scan(
-			&c.ID,
-			&c.Title,
-			&c.Description,
-			&c.SourceContentID,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
+		err := scanConcept(rows, &c)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan concept: %w", err)
 		}
@@ -222,28 +401,21 @@ func CreateConceptsBatch(concepts []models.Concept) ([]models.Concept, error) {
 	defer tx.Rollback() // Rollback if not committed
 
 	query := `
-		INSERT INTO concepts (title, description, source_content_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, title, description, source_content_id, created_at, updated_at
-	`
+		INSERT INTO concepts (title, description, source_content_id, tags)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + conceptColumns
 
 	createdConcepts := make([]models.Concept, 0, len(concepts))
 
 	for _, concept := range concepts {
 		var c models.Concept
-		err := tx.QueryRow(
+		err := scanConcept(tx.QueryRow(
 			query,
 			concept.Title,
 			concept.Description,
 			concept.SourceContentID,
-		).Scan(
-			&c.ID,
-			&c.Title,
-			&c.Description,
-			&c.SourceContentID,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
+			concept.Tags,
+		), &c)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to create concept: %w", err)
@@ -259,3 +431,350 @@ func CreateConceptsBatch(concepts []models.Concept) ([]models.Concept, error) {
 
 	return createdConcepts, nil
 }
+
+// GetRelatedConcepts retrieves concepts linked to a concept in either direction
+func GetRelatedConcepts(conceptID int) ([]models.RelatedConcept, error) {
+	query := `
+		SELECT c.id, c.title, c.description, c.source_content_id, c.tags, c.position, c.pinned, c.archived_at, c.created_at, c.updated_at,
+			COALESCE(r.relationship_type, 'related'), 'outgoing'
+		FROM concept_relationships r
+		INNER JOIN concepts c ON c.id = r.to_concept_id
+		WHERE r.from_concept_id = $1
+		UNION ALL
+		SELECT c.id, c.title, c.description, c.source_content_id, c.tags, c.position, c.pinned, c.archived_at, c.created_at, c.updated_at,
+			COALESCE(r.relationship_type, 'related'), 'incoming'
+		FROM concept_relationships r
+		INNER JOIN concepts c ON c.id = r.from_concept_id
+		WHERE r.to_concept_id = $1
+	`
+
+	rows, err := DB.Query(query, conceptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related concepts: %w", err)
+	}
+	defer rows.Close()
+
+	related := []models.RelatedConcept{}
+	for rows.Next() {
+		var rc models.RelatedConcept
+		err := rows.Scan(
+			&rc.ID,
+			&rc.Title,
+			&rc.Description,
+			&rc.SourceContentID,
+			&rc.Tags,
+			&rc.Position,
+			&rc.Pinned,
+			&rc.ArchivedAt,
+			&rc.CreatedAt,
+			&rc.UpdatedAt,
+			&rc.RelationshipType,
+			&rc.Direction,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan related concept: %w", err)
+		}
+		related = append(related, rc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating related concepts: %w", err)
+	}
+
+	return related, nil
+}
+
+// SplitConcept replaces a concept with child concepts in a single transaction.
+// Children inherit the original's source; questionIDs[i] lists existing quiz
+// questions moved to children[i]. The original is archived so its attempt
+// history is kept.
+func SplitConcept(originalID int, children []models.Concept, questionIDs [][]int) (*models.Concept, []models.Concept, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	var original models.Concept
+	err = scanConcept(tx.QueryRow(`
+		UPDATE concepts SET archived_at = COALESCE(archived_at, NOW())
+		WHERE id = $1
+		RETURNING `+conceptColumns, originalID), &original)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("concept not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to archive concept: %w", err)
+	}
+
+	// Children keep the original's owner, which a detached concept has no
+	// source to inherit from
+	insertQuery := `
+		INSERT INTO concepts (title, description, source_content_id, tags, owner_id)
+		VALUES ($1, $2, $3, $4, (SELECT owner_id FROM concepts WHERE id = $5))
+		RETURNING ` + conceptColumns
+
+	created := make([]models.Concept, 0, len(children))
+	for i, child := range children {
+		var c models.Concept
+		err := scanConcept(tx.QueryRow(
+			insertQuery,
+			child.Title,
+			child.Description,
+			original.SourceContentID,
+			child.Tags,
+			originalID,
+		), &c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create child concept: %w", err)
+		}
+
+		if i < len(questionIDs) {
+			for _, questionID := range questionIDs[i] {
+				_, err := tx.Exec(
+					"UPDATE quiz_questions SET concept_id = $1 WHERE id = $2 AND concept_id = $3",
+					c.ID, questionID, originalID,
+				)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to move quiz question %d: %w", questionID, err)
+				}
+			}
+		}
+
+		created = append(created, c)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &original, created, nil
+}
+
+// ReorderConcepts sets the teaching order of a source's concepts. Listed concepts
+// get positions 0..n-1 in the given order; the source's other concepts lose
+// their position and sort after them.
+func ReorderConcepts(sourceContentID int, conceptIDs []int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	_, err = tx.Exec("UPDATE concepts SET position = NULL WHERE source_content_id = $1", sourceContentID)
+	if err != nil {
+		return fmt.Errorf("failed to clear concept positions: %w", err)
+	}
+
+	for position, conceptID := range conceptIDs {
+		result, err := tx.Exec(
+			"UPDATE concepts SET position = $1 WHERE id = $2 AND source_content_id = $3",
+			position, conceptID, sourceContentID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set concept position: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("concept %d does not belong to source content %d", conceptID, sourceContentID)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// duplicateConceptPairs is a CTE of pairs of active concepts the owner in $2
+// can see whose titles have a trigram similarity of at least $1. The %
+// operator narrows the pairs with idx_concepts_title_trgm at pg_trgm's
+// default 0.3 threshold.
+const duplicateConceptPairs = `
+	WITH active AS (
+		SELECT id, title FROM concepts WHERE ` + conceptActiveCondition + `
+			AND ($2::int IS NULL OR concepts.owner_id IS NULL OR concepts.owner_id = $2)
+	), pairs AS (
+		SELECT a.id AS concept_id, b.id AS other_id, similarity(a.title, b.title) AS score
+		FROM active a
+		JOIN active b ON a.id < b.id AND a.title % b.title
+		WHERE similarity(a.title, b.title) >= $1
+	)
+`
+
+// GetDuplicateConceptCandidates returns the pairs of active concepts owner
+// can see whose titles are at least threshold similar, and each concept in
+// them by ID
+func GetDuplicateConceptCandidates(threshold float64, owner *int) ([]models.ConceptPair, map[int]models.DuplicateConcept, error) {
+	rows, err := DB.Query(duplicateConceptPairs+`
+		SELECT concept_id, other_id, score FROM pairs
+		ORDER BY score DESC, concept_id ASC, other_id ASC
+	`, threshold, owner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query similar concepts: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := []models.ConceptPair{}
+	for rows.Next() {
+		var p models.ConceptPair
+		if err := rows.Scan(&p.ConceptID, &p.OtherID, &p.Similarity); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan similar concepts: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating similar concepts: %w", err)
+	}
+
+	concepts := map[int]models.DuplicateConcept{}
+	if len(pairs) == 0 {
+		return pairs, concepts, nil
+	}
+
+	conceptRows, err := DB.Query(duplicateConceptPairs+`
+		SELECT `+conceptColumns+`, source_title, quiz_count
+		FROM (
+			SELECT concepts.*, s.title AS source_title,
+				(SELECT COUNT(*) FROM quiz_questions q WHERE q.concept_id = concepts.id) AS quiz_count
+			FROM concepts
+			LEFT JOIN source_contents s ON s.id = concepts.source_content_id
+		) c
+		WHERE id IN (SELECT concept_id FROM pairs UNION SELECT other_id FROM pairs)
+	`, threshold, owner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query duplicate concepts: %w", err)
+	}
+	defer conceptRows.Close()
+
+	for conceptRows.Next() {
+		var d models.DuplicateConcept
+		err := conceptRows.Scan(
+			&d.ID,
+			&d.Title,
+			&d.Description,
+			&d.SourceContentID,
+			&d.Tags,
+			&d.Position,
+			&d.Pinned,
+			&d.ArchivedAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+			&d.SourceTitle,
+			&d.QuizCount,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan duplicate concept: %w", err)
+		}
+		concepts[d.ID] = d
+	}
+
+	if err = conceptRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating duplicate concepts: %w", err)
+	}
+
+	return pairs, concepts, nil
+}
+
+// relinkConceptQuery replaces concept $2 with concept $1 in a table's
+// concept_ids, keeping the first position of each ID and dropping repeats
+const relinkConceptQuery = `
+	UPDATE %s SET concept_ids = (
+		SELECT jsonb_agg(id ORDER BY pos) FROM (
+			SELECT CASE WHEN e = to_jsonb($2::int) THEN to_jsonb($1::int) ELSE e END AS id, MIN(n) AS pos
+			FROM jsonb_array_elements(concept_ids) WITH ORDINALITY AS t(e, n)
+			GROUP BY 1
+		) ids
+	)
+	WHERE concept_ids @> to_jsonb(ARRAY[$2::int])
+`
+
+// MergeConcepts merges concepts into the target in a single transaction. Their
+// quiz questions and action items move to the target, their tags are added to
+// the target's, generated content and glossary terms linking them link the
+// target instead, and they are archived. Returns the updated target and the
+// archived concepts.
+func MergeConcepts(targetID int, conceptIDs []int) (*models.Concept, []models.Concept, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	var target models.Concept
+	err = scanConcept(tx.QueryRow("SELECT "+conceptColumns+" FROM concepts WHERE id = $1 FOR UPDATE", targetID), &target)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("concept not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get concept: %w", err)
+	}
+
+	tags := append(models.StringArray{}, target.Tags...)
+	hasTag := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		hasTag[tag] = true
+	}
+
+	merged := make([]models.Concept, 0, len(conceptIDs))
+	for _, id := range conceptIDs {
+		var c models.Concept
+		err := scanConcept(tx.QueryRow(`
+			UPDATE concepts SET archived_at = COALESCE(archived_at, NOW())
+			WHERE id = $1
+			RETURNING `+conceptColumns, id), &c)
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("concept not found")
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to archive concept %d: %w", id, err)
+		}
+
+		for _, tag := range c.Tags {
+			if !hasTag[tag] {
+				hasTag[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+
+		if _, err := tx.Exec("UPDATE quiz_questions SET concept_id = $1 WHERE concept_id = $2", targetID, id); err != nil {
+			return nil, nil, fmt.Errorf("failed to move quiz questions of concept %d: %w", id, err)
+		}
+		if _, err := tx.Exec("UPDATE action_items SET concept_id = $1 WHERE concept_id = $2", targetID, id); err != nil {
+			return nil, nil, fmt.Errorf("failed to move action items of concept %d: %w", id, err)
+		}
+		for _, table := range []string{"generated_contents", "glossary_terms"} {
+			if _, err := tx.Exec(fmt.Sprintf(relinkConceptQuery, table), targetID, id); err != nil {
+				return nil, nil, fmt.Errorf("failed to relink %s of concept %d: %w", table, id, err)
+			}
+		}
+
+		merged = append(merged, c)
+	}
+
+	if len(tags) > len(target.Tags) {
+		err := scanConcept(tx.QueryRow(
+			"UPDATE concepts SET tags = $1 WHERE id = $2 RETURNING "+conceptColumns,
+			tags, targetID,
+		), &target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update concept tags: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &target, merged, nil
+}
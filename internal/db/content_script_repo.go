@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// contentScriptColumns is the column list scanned by scanContentScript
+const contentScriptColumns = "id, name, platform, steps, wasm, enabled, created_at, updated_at"
+
+// scanContentScript scans a row selected with contentScriptColumns
+func scanContentScript(row rowScanner, s *models.ContentScript) error {
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Platform,
+		&s.Steps,
+		&s.Wasm,
+		&s.Enabled,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	s.WasmSize = len(s.Wasm)
+	return err
+}
+
+// CreateContentScript stores a new content script, enabled unless the request says otherwise
+func CreateContentScript(req models.ContentScriptRequest) (*models.ContentScript, error) {
+	query := `
+		INSERT INTO content_scripts (name, platform, steps, wasm, enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + contentScriptColumns
+
+	enabled := req.Enabled == nil || *req.Enabled
+
+	var s models.ContentScript
+	err := scanContentScript(DB.QueryRow(query, req.Name, req.Platform, models.ContentScriptSteps(req.Steps), req.Wasm, enabled), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("script name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content script: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetContentScripts retrieves content scripts in the order they run. With
+// platform set, only enabled scripts for that platform (or every platform) are returned.
+func GetContentScripts(platform *string) ([]models.ContentScript, error) {
+	query := `
+		SELECT ` + contentScriptColumns + `
+		FROM content_scripts
+		WHERE $1::text IS NULL OR (enabled AND (platform IS NULL OR platform = $1))
+		ORDER BY id ASC
+	`
+
+	rows, err := DB.Query(query, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content scripts: %w", err)
+	}
+	defer rows.Close()
+
+	scripts := []models.ContentScript{}
+	for rows.Next() {
+		var s models.ContentScript
+		if err := scanContentScript(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan content script: %w", err)
+		}
+		scripts = append(scripts, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content scripts: %w", err)
+	}
+
+	return scripts, nil
+}
+
+// GetContentScriptByID retrieves a single content script by ID
+func GetContentScriptByID(id int) (*models.ContentScript, error) {
+	query := `
+		SELECT ` + contentScriptColumns + `
+		FROM content_scripts
+		WHERE id = $1
+	`
+
+	var s models.ContentScript
+	err := scanContentScript(DB.QueryRow(query, id), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("script not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content script: %w", err)
+	}
+
+	return &s, nil
+}
+
+// UpdateContentScript replaces a content script. Enabled is left unchanged when the request omits it.
+func UpdateContentScript(id int, req models.ContentScriptRequest) (*models.ContentScript, error) {
+	if _, err := GetContentScriptByID(id); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE content_scripts
+		SET name = $2, platform = $3, steps = $4, wasm = $5, enabled = COALESCE($6, enabled), updated_at = NOW()
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM content_scripts o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + contentScriptColumns
+
+	var s models.ContentScript
+	err := scanContentScript(DB.QueryRow(query, id, req.Name, req.Platform, models.ContentScriptSteps(req.Steps), req.Wasm, req.Enabled), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("script name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update content script: %w", err)
+	}
+
+	return &s, nil
+}
+
+// DeleteContentScript deletes a content script by ID
+func DeleteContentScript(id int) error {
+	query := "DELETE FROM content_scripts WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete content script: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("script not found")
+	}
+
+	return nil
+}
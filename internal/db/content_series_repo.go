@@ -0,0 +1,189 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// contentSeriesColumns is the column list scanned by scanContentSeries
+const contentSeriesColumns = "id, tag, collection_id, title, summary, weeks, created_at"
+
+// seriesVisibleTo returns the condition keeping the content series the owner
+// in argument n can see: those with no post they can't
+func seriesVisibleTo(n int) string {
+	return "NOT EXISTS (SELECT 1 FROM generated_contents g WHERE g.series_id = content_series.id AND NOT " + visibleTo("g.owner_id", n) + ")"
+}
+
+// scanContentSeries scans a row selected with contentSeriesColumns
+func scanContentSeries(row rowScanner, s *models.ContentSeries) error {
+	return row.Scan(
+		&s.ID,
+		&s.Tag,
+		&s.CollectionID,
+		&s.Title,
+		&s.Summary,
+		&s.Weeks,
+		&s.CreatedAt,
+	)
+}
+
+// CreateContentSeries stores a content series and its posts in one
+// transaction. Each post keeps its ScheduledFor; posts are numbered in order.
+func CreateContentSeries(series models.ContentSeries, posts []models.GeneratedContent) (*models.ContentSeries, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO content_series (tag, collection_id, title, summary, weeks)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + contentSeriesColumns
+
+	var s models.ContentSeries
+	if err := scanContentSeries(tx.QueryRow(query, series.Tag, series.CollectionID, series.Title, series.Summary, series.Weeks), &s); err != nil {
+		return nil, fmt.Errorf("failed to create content series: %w", err)
+	}
+
+	ids, err := nextIDs(tx, "generated_contents", len(posts))
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO generated_contents (id, platform, title, body, concept_ids, citations, status, readability, series_id, series_position, scheduled_for)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + generatedContentColumns
+
+	s.Posts = make([]models.GeneratedContent, 0, len(posts))
+	for i, post := range posts {
+		var gc models.GeneratedContent
+		err := scanGeneratedContent(tx.QueryRow(
+			query,
+			ids[i],
+			post.Platform,
+			post.Title,
+			sealed(post.Body, "generated_contents", "body", ids[i]),
+			post.ConceptIDs,
+			post.Citations,
+			post.Status,
+			post.Readability,
+			s.ID,
+			i+1,
+			post.ScheduledFor,
+		), &gc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create series post: %w", err)
+		}
+		s.Posts = append(s.Posts, gc)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetContentSeriesByID retrieves a content series owner can see with its
+// posts in order
+func GetContentSeriesByID(id int, owner *int) (*models.ContentSeries, error) {
+	query := `
+		SELECT ` + contentSeriesColumns + `
+		FROM content_series
+		WHERE id = $1 AND ` + seriesVisibleTo(2) + `
+	`
+
+	var s models.ContentSeries
+	err := scanContentSeries(DB.QueryRow(query, id, owner), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("content series not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content series: %w", err)
+	}
+
+	s.Posts, err = queryGeneratedContents(`
+		SELECT `+generatedContentColumns+`
+		FROM generated_contents
+		WHERE series_id = $1
+		ORDER BY series_position ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// GetContentSeries retrieves the content series owner can see without their
+// posts, newest first
+func GetContentSeries(owner *int) ([]models.ContentSeries, error) {
+	query := `
+		SELECT ` + contentSeriesColumns + `
+		FROM content_series
+		WHERE ` + seriesVisibleTo(1) + `
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content series: %w", err)
+	}
+	defer rows.Close()
+
+	series := []models.ContentSeries{}
+	for rows.Next() {
+		var s models.ContentSeries
+		if err := scanContentSeries(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan content series: %w", err)
+		}
+		series = append(series, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content series: %w", err)
+	}
+
+	return series, nil
+}
+
+// GetScheduledContent retrieves the generated content owner can see
+// scheduled in [from, to), soonest first
+func GetScheduledContent(from, to time.Time, owner *int) ([]models.GeneratedContent, error) {
+	return queryGeneratedContents(`
+		SELECT `+generatedContentColumns+`
+		FROM generated_contents
+		WHERE scheduled_for >= $1 AND scheduled_for < $2 AND `+visibleTo("owner_id", 3)+`
+		ORDER BY scheduled_for ASC, id ASC
+	`, from, to, owner)
+}
+
+// queryGeneratedContents runs a query selecting generatedContentColumns
+func queryGeneratedContents(query string, args ...interface{}) ([]models.GeneratedContent, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generated contents: %w", err)
+	}
+	defer rows.Close()
+
+	contents := []models.GeneratedContent{}
+	for rows.Next() {
+		var gc models.GeneratedContent
+		if err := scanGeneratedContent(rows, &gc); err != nil {
+			return nil, fmt.Errorf("failed to scan generated content: %w", err)
+		}
+		contents = append(contents, gc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating generated contents: %w", err)
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// credentialColumns is the column list scanned by scanCredential
+const credentialColumns = "id, provider, name, ciphertext, key_id, created_at, updated_at, rotated_at"
+
+// scanCredential scans a row selected with credentialColumns
+func scanCredential(row rowScanner, c *models.Credential) error {
+	return row.Scan(
+		&c.ID,
+		&c.Provider,
+		&c.Name,
+		&c.Ciphertext,
+		&c.KeyID,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.RotatedAt,
+	)
+}
+
+// CreateCredential stores an encrypted credential
+func CreateCredential(provider, name string, ciphertext []byte, keyID string) (*models.Credential, error) {
+	query := `
+		INSERT INTO credentials (provider, name, ciphertext, key_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, name) DO NOTHING
+		RETURNING ` + credentialColumns
+
+	var c models.Credential
+	err := scanCredential(DB.QueryRow(query, provider, name, ciphertext, keyID), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("credential already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetCredentials retrieves all credentials by provider and name
+func GetCredentials() ([]models.Credential, error) {
+	query := `
+		SELECT ` + credentialColumns + `
+		FROM credentials
+		ORDER BY provider ASC, name ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer rows.Close()
+
+	credentials := []models.Credential{}
+	for rows.Next() {
+		var c models.Credential
+		if err := scanCredential(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// GetCredentialByID retrieves a single credential by ID
+func GetCredentialByID(id int) (*models.Credential, error) {
+	query := `
+		SELECT ` + credentialColumns + `
+		FROM credentials
+		WHERE id = $1
+	`
+
+	var c models.Credential
+	err := scanCredential(DB.QueryRow(query, id), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credential: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetCredentialByProvider retrieves a provider's credential by name
+func GetCredentialByProvider(provider, name string) (*models.Credential, error) {
+	query := `
+		SELECT ` + credentialColumns + `
+		FROM credentials
+		WHERE provider = $1 AND name = $2
+	`
+
+	var c models.Credential
+	err := scanCredential(DB.QueryRow(query, provider, name), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credential: %w", err)
+	}
+
+	return &c, nil
+}
+
+// UpdateCredentialSecret stores a credential's new ciphertext. With rotated
+// set, the secret is unchanged and only re-encrypted under a new key.
+func UpdateCredentialSecret(id int, ciphertext []byte, keyID string, rotated bool) (*models.Credential, error) {
+	query := `
+		UPDATE credentials
+		SET ciphertext = $2, key_id = $3, updated_at = CASE WHEN $4 THEN updated_at ELSE NOW() END,
+			rotated_at = CASE WHEN $4 THEN NOW() ELSE rotated_at END
+		WHERE id = $1
+		RETURNING ` + credentialColumns
+
+	var c models.Credential
+	err := scanCredential(DB.QueryRow(query, id, ciphertext, keyID, rotated), &c)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update credential: %w", err)
+	}
+
+	return &c, nil
+}
+
+// DeleteCredential deletes a credential by ID
+func DeleteCredential(id int) error {
+	query := "DELETE FROM credentials WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("credential not found")
+	}
+
+	return nil
+}
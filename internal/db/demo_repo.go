@@ -0,0 +1,67 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ClaimDemoRun records a demo run for ip if it has had fewer than perDay in
+// the last 24 hours, returning the run's ID. Returns nil without an error when
+// the quota is used up. Claims for one ip are serialized by an advisory lock,
+// so concurrent requests can't both see room for one more run.
+func ClaimDemoRun(ip string, perDay int) (*int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	// Released when the transaction ends
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", "demo_runs:"+ip); err != nil {
+		return nil, fmt.Errorf("failed to lock demo runs: %w", err)
+	}
+
+	query := `
+		INSERT INTO demo_runs (ip)
+		SELECT $1
+		WHERE (
+			SELECT COUNT(*) FROM demo_runs
+			WHERE ip = $1 AND created_at > NOW() - INTERVAL '24 hours'
+		) < $2
+		RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(query, ip, perDay).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim demo run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &id, nil
+}
+
+// FinishDemoRun records a demo run's outcome
+func FinishDemoRun(id int, sourceContentID *int, tokensUsed int, runErr error) error {
+	var errText *string
+	if runErr != nil {
+		msg := runErr.Error()
+		errText = &msg
+	}
+
+	_, err := DB.Exec(
+		"UPDATE demo_runs SET source_content_id = $2, tokens_used = $3, error = $4 WHERE id = $1",
+		id, sourceContentID, tokensUsed, errText,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record demo run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// conceptEmbeddingText is the text a concept is embedded from
+const conceptEmbeddingText = "concepts.title || E'\\n\\n' || concepts.description"
+
+// maxEmbeddingChars bounds the text embedded for a concept, within what
+// embedding models take
+const maxEmbeddingChars = 8000
+
+// vectorLiteral formats a vector as pgvector's text input, [1,2,3]
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// GetUnembeddedConcepts returns up to limit active concepts with no embedding
+// from model, or whose text changed since theirs, in ID order
+func GetUnembeddedConcepts(model string, limit int) ([]models.ConceptText, error) {
+	query := `
+		SELECT concepts.id, left(` + conceptEmbeddingText + `, $2), md5(` + conceptEmbeddingText + `)
+		FROM concepts
+		LEFT JOIN concept_embeddings e ON e.concept_id = concepts.id
+		WHERE (e.concept_id IS NULL OR e.model <> $1 OR e.content_hash <> md5(` + conceptEmbeddingText + `))
+			AND ` + conceptActiveCondition + `
+		ORDER BY concepts.id
+		LIMIT $3
+	`
+
+	rows, err := DB.Query(query, model, maxEmbeddingChars, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unembedded concepts: %w", err)
+	}
+	defer rows.Close()
+
+	texts := []models.ConceptText{}
+	for rows.Next() {
+		var t models.ConceptText
+		if err := rows.Scan(&t.ConceptID, &t.Text, &t.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan concept text: %w", err)
+		}
+		texts = append(texts, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating concept texts: %w", err)
+	}
+
+	return texts, nil
+}
+
+// SaveConceptEmbeddings stores the embedding of each text, vectors[i] being
+// texts[i]'s, in a single transaction, replacing earlier embeddings
+func SaveConceptEmbeddings(model string, texts []models.ConceptText, vectors [][]float32) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	query := `
+		INSERT INTO concept_embeddings (concept_id, model, content_hash, embedding)
+		SELECT $1::int, $2::text, $3::text, $4::vector
+		WHERE EXISTS (SELECT 1 FROM concepts WHERE id = $1) -- It may have been deleted since it was read
+		ON CONFLICT (concept_id) DO UPDATE
+		SET model = EXCLUDED.model,
+			content_hash = EXCLUDED.content_hash,
+			embedding = EXCLUDED.embedding,
+			created_at = NOW()
+	`
+	for i, t := range texts {
+		if _, err := tx.Exec(query, t.ConceptID, model, t.Hash, vectorLiteral(vectors[i])); err != nil {
+			return fmt.Errorf("failed to save embedding of concept %d: %w", t.ConceptID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SearchConceptsByEmbedding returns up to limit active concepts owner can see
+// embedded by model, most similar to embedding first
+func SearchConceptsByEmbedding(embedding []float32, model string, limit int, owner *int) ([]models.ConceptMatch, error) {
+	query := `
+		SELECT ` + conceptColumns + `, 1 - e.distance
+		FROM concepts
+		JOIN (
+			SELECT concept_id, embedding <=> $1::vector AS distance
+			FROM concept_embeddings
+			WHERE model = $2
+		) e ON e.concept_id = concepts.id
+		WHERE ` + conceptActiveCondition + `
+			AND ` + visibleTo("concepts.owner_id", 4) + `
+		ORDER BY e.distance
+		LIMIT $3
+	`
+
+	rows, err := DB.Query(query, vectorLiteral(embedding), model, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search concepts: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.ConceptMatch{}
+	for rows.Next() {
+		var m models.ConceptMatch
+		err := rows.Scan(
+			&m.ID,
+			&m.Title,
+			&m.Description,
+			&m.SourceContentID,
+			&m.Tags,
+			&m.Position,
+			&m.Pinned,
+			&m.ArchivedAt,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+			&m.Similarity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan concept match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating concept matches: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,366 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/secrets"
+)
+
+// SealedColumn is a text or JSON column encrypted at rest. Values are bound
+// to their table, column, and row, so one can't be copied elsewhere.
+type SealedColumn struct {
+	Table  string
+	Column string
+	JSON   bool // JSONB, holding a sealed value as a JSON string
+}
+
+// SealedColumns lists every column encrypted at rest
+var SealedColumns = []SealedColumn{
+	{Table: "source_contents", Column: "transcript"},
+	{Table: "source_contents", Column: "original_transcript"},
+	{Table: "source_contents", Column: "transcript_words", JSON: true},
+	{Table: "source_contents", Column: "chapters", JSON: true},
+	{Table: "generated_contents", Column: "body"},
+	{Table: "transcript_segments", Column: "text"},
+	{Table: "eval_cases", Column: "transcript"},
+}
+
+// binding is where a value of the column in row id is stored
+func (col SealedColumn) binding(id int) secrets.TextBinding {
+	return secrets.TextBinding{Table: col.Table, Column: col.Column, Row: id}
+}
+
+// sealedColumn returns the sealed column of table
+func sealedColumn(table, column string) SealedColumn {
+	for _, col := range SealedColumns {
+		if col.Table == table && col.Column == column {
+			return col
+		}
+	}
+	panic(fmt.Sprintf("%s.%s is not a sealed column", table, column))
+}
+
+// sealedValue is a query argument encrypted at rest when it's turned on
+type sealedValue struct {
+	text string
+	at   secrets.TextBinding
+}
+
+// Value implements driver.Valuer
+func (v sealedValue) Value() (driver.Value, error) {
+	return secrets.SealText(v.text, v.at)
+}
+
+// sealed wraps a text argument for a sealed column of table's row id
+func sealed(text, table, column string, id int) sealedValue {
+	return sealedValue{text: text, at: sealedColumn(table, column).binding(id)}
+}
+
+// sealedJSONValue is a JSON query argument encrypted at rest when it's turned on
+type sealedJSONValue struct {
+	value driver.Valuer
+	at    secrets.TextBinding
+}
+
+// Value implements driver.Valuer
+func (v sealedJSONValue) Value() (driver.Value, error) {
+	value, err := v.value.Value()
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	var text string
+	switch j := value.(type) {
+	case string:
+		text = j
+	case []byte:
+		text = string(j)
+	default:
+		return nil, fmt.Errorf("cannot seal %T as JSON", value)
+	}
+
+	stored, err := secrets.SealText(text, v.at)
+	if err != nil || stored == text {
+		return value, err
+	}
+	return json.Marshal(stored)
+}
+
+// sealedJSON wraps a JSON argument for a sealed JSON column of table's row id
+func sealedJSON(value driver.Valuer, table, column string, id int) sealedJSONValue {
+	return sealedJSONValue{value: value, at: sealedColumn(table, column).binding(id)}
+}
+
+// openedValue scans a sealed column, decrypting it into dest
+type openedValue struct {
+	dest   *string
+	null   **string
+	column SealedColumn
+	id     *int // The row's ID, scanned before the column
+}
+
+// Scan implements sql.Scanner
+func (v openedValue) Scan(src interface{}) error {
+	var stored string
+	switch s := src.(type) {
+	case nil:
+		if v.null == nil {
+			return fmt.Errorf("sealed %s.%s column is NULL", v.column.Table, v.column.Column)
+		}
+		*v.null = nil
+		return nil
+	case string:
+		stored = s
+	case []byte:
+		stored = string(s)
+	default:
+		return fmt.Errorf("cannot scan %T into a sealed %s.%s column", src, v.column.Table, v.column.Column)
+	}
+
+	text, err := secrets.OpenText(stored, v.column.binding(*v.id))
+	if err != nil {
+		return fmt.Errorf("failed to open sealed %s.%s column: %w", v.column.Table, v.column.Column, err)
+	}
+	if v.null != nil {
+		*v.null = &text
+	} else {
+		*v.dest = text
+	}
+	return nil
+}
+
+// opened scans a sealed column of table into dest. id points at where the
+// row's ID is scanned, which must come first in the row.
+func opened(dest *string, table, column string, id *int) openedValue {
+	return openedValue{dest: dest, column: sealedColumn(table, column), id: id}
+}
+
+// openedNull scans a nullable sealed column of table into dest, as opened does
+func openedNull(dest **string, table, column string, id *int) openedValue {
+	return openedValue{null: dest, column: sealedColumn(table, column), id: id}
+}
+
+// openedJSONValue scans a sealed JSON column, decrypting it into dest
+type openedJSONValue struct {
+	dest   sql.Scanner
+	column SealedColumn
+	id     *int // The row's ID, scanned before the column
+}
+
+// Scan implements sql.Scanner
+func (v openedJSONValue) Scan(src interface{}) error {
+	var raw []byte
+	switch s := src.(type) {
+	case nil:
+		return v.dest.Scan(nil)
+	case string:
+		raw = []byte(s)
+	case []byte:
+		raw = s
+	default:
+		return fmt.Errorf("cannot scan %T into a sealed %s.%s column", src, v.column.Table, v.column.Column)
+	}
+
+	text, err := openSealedJSON(raw, v.column.binding(*v.id))
+	if err != nil {
+		return fmt.Errorf("failed to open sealed %s.%s column: %w", v.column.Table, v.column.Column, err)
+	}
+	return v.dest.Scan(text)
+}
+
+// openedJSON scans a sealed JSON column of table into dest, as opened does
+func openedJSON(dest sql.Scanner, table, column string, id *int) openedJSONValue {
+	return openedJSONValue{dest: dest, column: sealedColumn(table, column), id: id}
+}
+
+// openSealedJSON decrypts a stored JSON value that's a sealed JSON string;
+// other JSON is returned unchanged
+func openSealedJSON(raw []byte, at secrets.TextBinding) ([]byte, error) {
+	var stored string
+	if len(raw) == 0 || raw[0] != '"' || json.Unmarshal(raw, &stored) != nil || !secrets.IsSealedText(stored) {
+		return raw, nil
+	}
+
+	text, err := secrets.OpenText(stored, at)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(text), nil
+}
+
+// nextIDs reserves n IDs from the sequence of table's id column, so rows
+// whose sealed values are bound to their IDs can be inserted with them
+func nextIDs(q querier, table string, n int) ([]int, error) {
+	rows, err := q.Query(`SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)`, table, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve %s IDs: %w", table, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, n)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan %s ID: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s IDs: %w", table, err)
+	}
+
+	return ids, nil
+}
+
+// nextID reserves one ID from the sequence of table's id column, as nextIDs does
+func nextID(q querier, table string) (int, error) {
+	ids, err := nextIDs(q, table, 1)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// querier runs queries on the database or in a transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// SealedText is a value of a sealed column as stored
+type SealedText struct {
+	SealedColumn
+	ID     int
+	Stored string
+}
+
+// Binding is where the value is stored, which it's sealed to
+func (v SealedText) Binding() secrets.TextBinding {
+	return v.binding(v.ID)
+}
+
+// storedText is the SQL for a sealed column's value as text: a JSON column's
+// value is a sealed JSON string's contents, or plaintext JSON
+func (col SealedColumn) storedText() string {
+	if col.JSON {
+		return col.Column + " #>> '{}'"
+	}
+	return col.Column
+}
+
+// GetSealedTextToRotate returns up to limit values of a sealed column after
+// afterID, in ID order, that aren't stored the way they would be written
+// now: under keyID in the current format, or as plaintext when keyID is empty
+func GetSealedTextToRotate(col SealedColumn, afterID int, keyID string, limit int) ([]SealedText, error) {
+	query := fmt.Sprintf(`
+		SELECT id, value
+		FROM (SELECT id, %s AS value FROM %s) t
+		WHERE id > $1 AND value <> ''
+			AND CASE WHEN $2::text = '' THEN value LIKE 'enc:v_:%%' ELSE value NOT LIKE 'enc:v2:' || $2::text || ':%%' END
+		ORDER BY id
+		LIMIT $3
+	`, col.storedText(), col.Table)
+
+	rows, err := DB.Query(query, afterID, keyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s.%s: %w", col.Table, col.Column, err)
+	}
+	defer rows.Close()
+
+	values := []SealedText{}
+	for rows.Next() {
+		v := SealedText{SealedColumn: col}
+		if err := rows.Scan(&v.ID, &v.Stored); err != nil {
+			return nil, fmt.Errorf("failed to scan %s.%s: %w", col.Table, col.Column, err)
+		}
+		values = append(values, v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s.%s: %w", col.Table, col.Column, err)
+	}
+
+	return values, nil
+}
+
+// ReplaceSealedText rewrites a stored value unless it changed since it was
+// read. Returns whether it was replaced.
+func ReplaceSealedText(v SealedText, stored string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET %s = $3 WHERE id = $1 AND %s = $2", v.Table, v.Column, v.storedText())
+
+	var value interface{} = stored
+	if v.JSON {
+		query = fmt.Sprintf("UPDATE %s SET %s = $3::jsonb WHERE id = $1 AND %s = $2", v.Table, v.Column, v.storedText())
+		if secrets.IsSealedText(stored) {
+			encoded, err := json.Marshal(stored)
+			if err != nil {
+				return false, err
+			}
+			value = string(encoded)
+		}
+	}
+
+	result, err := DB.Exec(query, v.ID, v.Stored, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to rewrite %s.%s: %w", v.Table, v.Column, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// openSealedRows decrypts the sealed columns in a JSON array of table's rows
+func openSealedRows(table string, rows []byte) ([]byte, error) {
+	var columns []SealedColumn
+	for _, col := range SealedColumns {
+		if col.Table == table {
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) == 0 {
+		return rows, nil
+	}
+
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(rows, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", table, err)
+	}
+	for _, record := range records {
+		var id int
+		if err := json.Unmarshal(record["id"], &id); err != nil {
+			return nil, fmt.Errorf("failed to decode %s id: %w", table, err)
+		}
+
+		for _, col := range columns {
+			if col.JSON {
+				opened, err := openSealedJSON(record[col.Column], col.binding(id))
+				if err != nil {
+					return nil, fmt.Errorf("failed to open sealed %s.%s: %w", table, col.Column, err)
+				}
+				record[col.Column] = opened
+				continue
+			}
+
+			var stored *string
+			if err := json.Unmarshal(record[col.Column], &stored); err != nil || stored == nil {
+				continue
+			}
+			text, err := secrets.OpenText(*stored, col.binding(id))
+			if err != nil {
+				return nil, fmt.Errorf("failed to open sealed %s.%s: %w", table, col.Column, err)
+			}
+			if record[col.Column], err = json.Marshal(text); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(records)
+}
@@ -0,0 +1,282 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// evalCaseColumns is the column list scanned by scanEvalCase
+const evalCaseColumns = "id, name, transcript, expected_concepts, source_content_id, created_at"
+
+// scanEvalCase scans a row selected with evalCaseColumns
+func scanEvalCase(row rowScanner, e *models.EvalCase) error {
+	return row.Scan(
+		&e.ID,
+		&e.Name,
+		opened(&e.Transcript, "eval_cases", "transcript", &e.ID),
+		&e.ExpectedConcepts,
+		&e.SourceContentID,
+		&e.CreatedAt,
+	)
+}
+
+// evalRunColumns is the column list scanned by scanEvalRun
+const evalRunColumns = "id, label, model, prompt_version, instructions, judge_model, cases, failed, coverage, precision, created_at"
+
+// scanEvalRun scans a row selected with evalRunColumns
+func scanEvalRun(row rowScanner, r *models.EvalRun) error {
+	return row.Scan(
+		&r.ID,
+		&r.Label,
+		&r.Model,
+		&r.PromptVersion,
+		&r.Instructions,
+		&r.JudgeModel,
+		&r.Cases,
+		&r.Failed,
+		&r.Coverage,
+		&r.Precision,
+		&r.CreatedAt,
+	)
+}
+
+// CreateEvalCase stores an eval case
+func CreateEvalCase(name, transcript string, expected []string, sourceContentID *int) (*models.EvalCase, error) {
+	id, err := nextID(DB, "eval_cases")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO eval_cases (id, name, transcript, expected_concepts, source_content_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + evalCaseColumns
+
+	var e models.EvalCase
+	err = scanEvalCase(DB.QueryRow(query, id, name, sealed(transcript, "eval_cases", "transcript", id), models.StringArray(expected), sourceContentID), &e)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("eval case name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create eval case: %w", err)
+	}
+
+	return &e, nil
+}
+
+// GetEvalCases retrieves all eval cases in name order
+func GetEvalCases() ([]models.EvalCase, error) {
+	query := `
+		SELECT ` + evalCaseColumns + `
+		FROM eval_cases
+		ORDER BY name ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query eval cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []models.EvalCase{}
+	for rows.Next() {
+		var e models.EvalCase
+		if err := scanEvalCase(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan eval case: %w", err)
+		}
+		cases = append(cases, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating eval cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// DeleteEvalCase deletes an eval case, and its results in past runs
+func DeleteEvalCase(id int) error {
+	query := "DELETE FROM eval_cases WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete eval case: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("eval case not found")
+	}
+
+	return nil
+}
+
+// CreateEvalRun stores a finished eval run with its per-case results
+func CreateEvalRun(run models.EvalRun) (*models.EvalRun, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	query := `
+		INSERT INTO eval_runs (label, model, prompt_version, instructions, judge_model, cases, failed, coverage, precision)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + evalRunColumns
+
+	var saved models.EvalRun
+	err = scanEvalRun(tx.QueryRow(
+		query,
+		run.Label,
+		run.Model,
+		run.PromptVersion,
+		run.Instructions,
+		run.JudgeModel,
+		run.Cases,
+		run.Failed,
+		run.Coverage,
+		run.Precision,
+	), &saved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create eval run: %w", err)
+	}
+
+	resultQuery := `
+		INSERT INTO eval_results (run_id, case_id, case_name, coverage, precision, extracted, missed, irrelevant, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for _, r := range run.Results {
+		_, err := tx.Exec(resultQuery, saved.ID, r.CaseID, r.CaseName, r.Coverage, r.Precision, r.Extracted, r.Missed, r.Irrelevant, r.Error)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create eval result: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	saved.Results = run.Results
+	return &saved, nil
+}
+
+// GetEvalRuns retrieves eval runs newest first, optionally only those of one
+// model and prompt version
+func GetEvalRuns(model, promptVersion *string) ([]models.EvalRun, error) {
+	query := `
+		SELECT ` + evalRunColumns + `
+		FROM eval_runs
+		WHERE ($1::text IS NULL OR model = $1)
+			AND ($2::text IS NULL OR prompt_version = $2)
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, model, promptVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query eval runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.EvalRun{}
+	for rows.Next() {
+		var r models.EvalRun
+		if err := scanEvalRun(rows, &r); err != nil {
+			return nil, fmt.Errorf("failed to scan eval run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating eval runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetEvalRunByID retrieves an eval run with its per-case results
+func GetEvalRunByID(id int) (*models.EvalRun, error) {
+	query := `
+		SELECT ` + evalRunColumns + `
+		FROM eval_runs
+		WHERE id = $1
+	`
+
+	var r models.EvalRun
+	err := scanEvalRun(DB.QueryRow(query, id), &r)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("eval run not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query eval run: %w", err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT case_id, case_name, coverage, precision, extracted, missed, irrelevant, error
+		FROM eval_results
+		WHERE run_id = $1
+		ORDER BY case_name ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query eval results: %w", err)
+	}
+	defer rows.Close()
+
+	r.Results = []models.EvalResult{}
+	for rows.Next() {
+		var res models.EvalResult
+		err := rows.Scan(
+			&res.CaseID,
+			&res.CaseName,
+			&res.Coverage,
+			&res.Precision,
+			&res.Extracted,
+			&res.Missed,
+			&res.Irrelevant,
+			&res.Error,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan eval result: %w", err)
+		}
+		r.Results = append(r.Results, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating eval results: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetPreviousEvalRun retrieves the latest run before run of the same model and
+// prompt version. Returns nil without an error for the first such run.
+func GetPreviousEvalRun(run models.EvalRun) (*models.EvalRun, error) {
+	query := `
+		SELECT ` + evalRunColumns + `
+		FROM eval_runs
+		WHERE model = $1 AND prompt_version = $2 AND id < $3
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	var r models.EvalRun
+	err := scanEvalRun(DB.QueryRow(query, run.Model, run.PromptVersion, run.ID), &r)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous eval run: %w", err)
+	}
+
+	return &r, nil
+}
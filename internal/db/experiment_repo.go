@@ -0,0 +1,281 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// promptExperimentColumns is the column list scanned by scanPromptExperiment
+const promptExperimentColumns = "id, name, stage, variant_a, variant_b, sample_rate, active, winner, created_at, ended_at"
+
+// scanPromptExperiment scans a row selected with promptExperimentColumns
+func scanPromptExperiment(row rowScanner, e *models.PromptExperiment) error {
+	return row.Scan(
+		&e.ID,
+		&e.Name,
+		&e.Stage,
+		&e.VariantA,
+		&e.VariantB,
+		&e.SampleRate,
+		&e.Active,
+		&e.Winner,
+		&e.CreatedAt,
+		&e.EndedAt,
+	)
+}
+
+// CreatePromptExperiment starts a prompt experiment, unless one is already
+// running for the stage
+func CreatePromptExperiment(req models.CreatePromptExperimentRequest) (*models.PromptExperiment, error) {
+	sampleRate := 1.0
+	if req.SampleRate != nil {
+		sampleRate = *req.SampleRate
+	}
+
+	query := `
+		INSERT INTO prompt_experiments (name, stage, variant_a, variant_b, sample_rate)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (SELECT 1 FROM prompt_experiments WHERE stage = $2 AND active)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + promptExperimentColumns
+
+	var e models.PromptExperiment
+	err := scanPromptExperiment(DB.QueryRow(query, req.Name, req.Stage, req.VariantA, req.VariantB, sampleRate), &e)
+
+	if err == sql.ErrNoRows {
+		var nameTaken bool
+		if err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM prompt_experiments WHERE name = $1)", req.Name).Scan(&nameTaken); err != nil {
+			return nil, fmt.Errorf("failed to create prompt experiment: %w", err)
+		}
+		if nameTaken {
+			return nil, fmt.Errorf("experiment name already exists")
+		}
+		return nil, fmt.Errorf("an experiment is already running for this stage")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt experiment: %w", err)
+	}
+
+	return &e, nil
+}
+
+// GetPromptExperiments retrieves all prompt experiments, running ones first, newest first
+func GetPromptExperiments() ([]models.PromptExperiment, error) {
+	query := `
+		SELECT ` + promptExperimentColumns + `
+		FROM prompt_experiments
+		ORDER BY active DESC, created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt experiments: %w", err)
+	}
+	defer rows.Close()
+
+	experiments := []models.PromptExperiment{}
+	for rows.Next() {
+		var e models.PromptExperiment
+		if err := scanPromptExperiment(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt experiment: %w", err)
+		}
+		experiments = append(experiments, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prompt experiments: %w", err)
+	}
+
+	return experiments, nil
+}
+
+// GetPromptExperimentByID retrieves a single prompt experiment by ID
+func GetPromptExperimentByID(id int) (*models.PromptExperiment, error) {
+	query := `
+		SELECT ` + promptExperimentColumns + `
+		FROM prompt_experiments
+		WHERE id = $1
+	`
+
+	var e models.PromptExperiment
+	err := scanPromptExperiment(DB.QueryRow(query, id), &e)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("experiment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt experiment: %w", err)
+	}
+
+	return &e, nil
+}
+
+// GetActivePromptExperiment retrieves the experiment running for a stage
+func GetActivePromptExperiment(stage string) (*models.PromptExperiment, error) {
+	query := `
+		SELECT ` + promptExperimentColumns + `
+		FROM prompt_experiments
+		WHERE stage = $1 AND active
+	`
+
+	var e models.PromptExperiment
+	err := scanPromptExperiment(DB.QueryRow(query, stage), &e)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, the stage runs as configured
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active prompt experiment: %w", err)
+	}
+
+	return &e, nil
+}
+
+// StopPromptExperiment stops a running experiment, recording the winner if given.
+// Stopping an experiment that already ended only updates the winner.
+func StopPromptExperiment(id int, winner *string) (*models.PromptExperiment, error) {
+	query := `
+		UPDATE prompt_experiments
+		SET active = FALSE, ended_at = COALESCE(ended_at, NOW()), winner = COALESCE($2, winner)
+		WHERE id = $1
+		RETURNING ` + promptExperimentColumns
+
+	var e models.PromptExperiment
+	err := scanPromptExperiment(DB.QueryRow(query, id, winner), &e)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("experiment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop prompt experiment: %w", err)
+	}
+
+	return &e, nil
+}
+
+// DeletePromptExperiment deletes an experiment and its assignments
+func DeletePromptExperiment(id int) error {
+	query := "DELETE FROM prompt_experiments WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt experiment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("experiment not found")
+	}
+
+	return nil
+}
+
+// AssignPromptVariant records which variant a source got in an experiment
+func AssignPromptVariant(experimentID, sourceContentID int, variant string) error {
+	query := `
+		INSERT INTO prompt_experiment_assignments (experiment_id, source_content_id, variant)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (experiment_id, source_content_id) DO NOTHING
+	`
+
+	if _, err := DB.Exec(query, experimentID, sourceContentID, variant); err != nil {
+		return fmt.Errorf("failed to record prompt variant: %w", err)
+	}
+	return nil
+}
+
+// CreateArtifactFeedback records a thumbs up or down on a stage's output for a source
+func CreateArtifactFeedback(sourceContentID int, stage string, positive bool) error {
+	_, err := DB.Exec(
+		"INSERT INTO artifact_feedback (source_content_id, stage, positive) VALUES ($1, $2, $3)",
+		sourceContentID, stage, positive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	return nil
+}
+
+// GetPromptVariantMetrics sums each variant's downstream signals over the
+// sources assigned to it. Variants without sources are omitted.
+func GetPromptVariantMetrics(experiment models.PromptExperiment) ([]models.PromptVariantMetrics, error) {
+	query := `
+		SELECT a.variant, COUNT(*),
+			COALESCE(SUM(f.up), 0), COALESCE(SUM(f.down), 0),
+			COALESCE(SUM(c.total), 0), COALESCE(SUM(c.edited), 0),
+			COALESCE(SUM(g.total), 0), COALESCE(SUM(g.edited), 0),
+			COALESCE(SUM(q.total), 0), COALESCE(SUM(q.correct), 0)
+		FROM prompt_experiment_assignments a
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE positive) AS up, COUNT(*) FILTER (WHERE NOT positive) AS down
+			FROM artifact_feedback
+			WHERE source_content_id = a.source_content_id AND stage = $2
+		) f
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(edited_at) AS edited
+			FROM concepts
+			WHERE source_content_id = a.source_content_id
+		) c
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(gc.edited_at) AS edited
+			FROM generated_contents gc
+			WHERE EXISTS (
+				SELECT 1 FROM concepts sc
+				WHERE sc.source_content_id = a.source_content_id AND gc.concept_ids @> to_jsonb(sc.id)
+			)
+		) g
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE qa.correct) AS correct
+			FROM quiz_attempts qa
+			INNER JOIN quiz_questions qq ON qq.id = qa.question_id
+			INNER JOIN concepts qc ON qc.id = qq.concept_id
+			WHERE qc.source_content_id = a.source_content_id
+		) q
+		WHERE a.experiment_id = $1
+		GROUP BY a.variant
+		ORDER BY a.variant
+	`
+
+	rows, err := DB.Query(query, experiment.ID, experiment.Stage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt variant metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := []models.PromptVariantMetrics{}
+	for rows.Next() {
+		var m models.PromptVariantMetrics
+		err := rows.Scan(
+			&m.Variant,
+			&m.Sources,
+			&m.ThumbsUp,
+			&m.ThumbsDown,
+			&m.Concepts,
+			&m.ConceptsEdited,
+			&m.Content,
+			&m.ContentEdited,
+			&m.QuizAttempts,
+			&m.QuizCorrect,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prompt variant metrics: %w", err)
+		}
+		if m.QuizAttempts > 0 {
+			accuracy := float64(m.QuizCorrect) / float64(m.QuizAttempts)
+			m.QuizAccuracy = &accuracy
+		}
+		metrics = append(metrics, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prompt variant metrics: %w", err)
+	}
+
+	return metrics, nil
+}
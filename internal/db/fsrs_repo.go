@@ -0,0 +1,123 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// fsrsOptimizationColumns is the column list scanned by scanFSRSOptimization
+const fsrsOptimizationColumns = "id, reviews, concepts, log_loss_before, log_loss_after, weights, applied, created_at"
+
+// scanFSRSOptimization scans a row selected with fsrsOptimizationColumns
+func scanFSRSOptimization(row rowScanner, o *models.FSRSOptimization) error {
+	return row.Scan(
+		&o.ID,
+		&o.Reviews,
+		&o.Concepts,
+		&o.LogLossBefore,
+		&o.LogLossAfter,
+		&o.Weights,
+		&o.Applied,
+		&o.CreatedAt,
+	)
+}
+
+// GetReviewHistory retrieves every review of a concept, quiz attempts and Anki
+// reviews alike, grouped by concept in review order. A wrong answer counts as
+// again and a correct one without a rating as good.
+func GetReviewHistory() ([]models.ReviewLog, error) {
+	query := `
+		SELECT concept_id, rating, reviewed_at
+		FROM (
+			SELECT q.concept_id,
+				CASE WHEN NOT a.correct THEN 'again' ELSE COALESCE(a.rating, 'good') END AS rating,
+				a.attempted_at::timestamptz AS reviewed_at
+			FROM quiz_attempts a
+			INNER JOIN quiz_questions q ON q.id = a.question_id
+			UNION ALL
+			SELECT concept_id,
+				(ARRAY['again', 'hard', 'good', 'easy'])[ease] AS rating,
+				to_timestamp(anki_review_id / 1000.0) AS reviewed_at
+			FROM anki_reviews
+			WHERE concept_id IS NOT NULL
+		) reviews
+		ORDER BY concept_id ASC, reviewed_at ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review history: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []models.ReviewLog{}
+	for rows.Next() {
+		var l models.ReviewLog
+		if err := rows.Scan(&l.ConceptID, &l.Rating, &l.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		logs = append(logs, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review history: %w", err)
+	}
+
+	return logs, nil
+}
+
+// CreateFSRSOptimization records an optimization run
+func CreateFSRSOptimization(o models.FSRSOptimization) (*models.FSRSOptimization, error) {
+	query := `
+		INSERT INTO fsrs_optimizations (reviews, concepts, log_loss_before, log_loss_after, weights, applied)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + fsrsOptimizationColumns
+
+	var saved models.FSRSOptimization
+	err := scanFSRSOptimization(DB.QueryRow(query,
+		o.Reviews,
+		o.Concepts,
+		o.LogLossBefore,
+		o.LogLossAfter,
+		o.Weights,
+		o.Applied,
+	), &saved)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FSRS optimization: %w", err)
+	}
+
+	return &saved, nil
+}
+
+// GetFSRSOptimizations retrieves the most recent optimization runs, newest first
+func GetFSRSOptimizations(limit int) ([]models.FSRSOptimization, error) {
+	query := `
+		SELECT ` + fsrsOptimizationColumns + `
+		FROM fsrs_optimizations
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := DB.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query FSRS optimizations: %w", err)
+	}
+	defer rows.Close()
+
+	optimizations := []models.FSRSOptimization{}
+	for rows.Next() {
+		var o models.FSRSOptimization
+		if err := scanFSRSOptimization(rows, &o); err != nil {
+			return nil, fmt.Errorf("failed to scan FSRS optimization: %w", err)
+		}
+		optimizations = append(optimizations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating FSRS optimizations: %w", err)
+	}
+
+	return optimizations, nil
+}
@@ -3,37 +3,64 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 
 	"github.com/mostlyerror/lattice/internal/models"
 )
 
+// generatedContentColumns is the column list scanned by scanGeneratedContent
+const generatedContentColumns = "id, platform, title, body, concept_ids, citations, status, readability, moderation, fact_check, series_id, series_position, scheduled_for, engagement, recycled_from_id, published_at, created_at, updated_at"
+
+// scanGeneratedContent scans a row selected with generatedContentColumns
+func scanGeneratedContent(row rowScanner, gc *models.GeneratedContent) error {
+	return row.Scan(
+		&gc.ID,
+		&gc.Platform,
+		&gc.Title,
+		opened(&gc.Body, "generated_contents", "body", &gc.ID),
+		&gc.ConceptIDs,
+		&gc.Citations,
+		&gc.Status,
+		&gc.Readability,
+		&gc.Moderation,
+		&gc.FactCheck,
+		&gc.SeriesID,
+		&gc.SeriesPosition,
+		&gc.ScheduledFor,
+		&gc.Engagement,
+		&gc.RecycledFromID,
+		&gc.PublishedAt,
+		&gc.CreatedAt,
+		&gc.UpdatedAt,
+	)
+}
+
 // CreateGeneratedContent creates a new generated content record
 func CreateGeneratedContent(content *models.GeneratedContent) (*models.GeneratedContent, error) {
+	id, err := nextID(DB, "generated_contents")
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO generated_contents (platform, title, body, concept_ids, status)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, platform, title, body, concept_ids, status, published_at, created_at, updated_at
+		INSERT INTO generated_contents (id, platform, title, body, concept_ids, citations, status, readability, recycled_from_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + generatedContentColumns + `
 	`
 
 	var gc models.GeneratedContent
-	err := DB.QueryRow(
+	err = scanGeneratedContent(DB.QueryRow(
 		query,
+		id,
 		content.Platform,
 		content.Title,
-		content.Body,
+		sealed(content.Body, "generated_contents", "body", id),
 		content.ConceptIDs,
+		content.Citations,
 		content.Status,
-	).Scan(
-		&gc.ID,
-		&gc.Platform,
-		&gc.Title,
-		&gc.Body,
-		&gc.ConceptIDs,
-		&gc.Status,
-		&gc.PublishedAt,
-		&gc.CreatedAt,
-		&gc.UpdatedAt,
-	)
+		content.Readability,
+		content.RecycledFromID,
+	), &gc)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create generated content: %w", err)
@@ -55,34 +82,32 @@ func CreateGeneratedContentBatch(contents []models.GeneratedContent) ([]models.G
 	}
 	defer tx.Rollback()
 
+	ids, err := nextIDs(tx, "generated_contents", len(contents))
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO generated_contents (platform, title, body, concept_ids, status)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, platform, title, body, concept_ids, status, published_at, created_at, updated_at
+		INSERT INTO generated_contents (id, platform, title, body, concept_ids, citations, status, readability)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + generatedContentColumns + `
 	`
 
 	createdContents := make([]models.GeneratedContent, 0, len(contents))
 
-	for _, content := range contents {
+	for i, content := range contents {
 		var gc models.GeneratedContent
-		err := tx.QueryRow(
+		err := scanGeneratedContent(tx.QueryRow(
 			query,
+			ids[i],
 			content.Platform,
 			content.Title,
-			content.Body,
+			sealed(content.Body, "generated_contents", "body", ids[i]),
 			content.ConceptIDs,
+			content.Citations,
 			content.Status,
-		).Scan(
-			&gc.ID,
-			&gc.Platform,
-			&gc.Title,
-			&gc.Body,
-			&gc.ConceptIDs,
-			&gc.Status,
-			&gc.PublishedAt,
-			&gc.CreatedAt,
-			&gc.UpdatedAt,
-		)
+			content.Readability,
+		), &gc)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to create generated content: %w", err)
@@ -102,23 +127,13 @@ func CreateGeneratedContentBatch(contents []models.GeneratedContent) ([]models.G
 // GetGeneratedContentByID retrieves a single generated content by ID
 func GetGeneratedContentByID(id int) (*models.GeneratedContent, error) {
 	query := `
-		SELECT id, platform, title, body, concept_ids, status, published_at, created_at, updated_at
+		SELECT ` + generatedContentColumns + `
 		FROM generated_contents
 		WHERE id = $1
 	`
 
 	var gc models.GeneratedContent
-	err := DB.QueryRow(query, id).Scan(
-		&gc.ID,
-		&gc.Platform,
-		&gc.Title,
-		&gc.Body,
-		&gc.ConceptIDs,
-		&gc.Status,
-		&gc.PublishedAt,
-		&gc.CreatedAt,
-		&gc.UpdatedAt,
-	)
+	err := scanGeneratedContent(DB.QueryRow(query, id), &gc)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("generated content not found")
@@ -135,7 +150,7 @@ func GetGeneratedContentByConceptIDs(conceptIDs []int) ([]models.GeneratedConten
 	// This is a simplified version - in production, you'd want to use PostgreSQL array operators
 	// For now, we'll get all and filter in memory
 	query := `
-		SELECT id, platform, title, body, concept_ids, status, published_at, created_at, updated_at
+		SELECT ` + generatedContentColumns + `
 		FROM generated_contents
 		ORDER BY created_at DESC
 	`
@@ -149,29 +164,14 @@ func GetGeneratedContentByConceptIDs(conceptIDs []int) ([]models.GeneratedConten
 	var contents []models.GeneratedContent
 	for rows.Next() {
 		var gc models.GeneratedContent
-		err := rows.Scan(
-			&gc.ID,
-			&gc.Platform,
-			&gc.Title,
-			&gc.Body,
-			&gc.ConceptIDs,
-			&gc.Status,
-			&gc.PublishedAt,
-			&gc.CreatedAt,
-			&gc.UpdatedAt,
-		)
+		err := scanGeneratedContent(rows, &gc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan generated content: %w", err)
 		}
 
 		// Filter by concept IDs (check if any match)
-		for _, targetID := range conceptIDs {
-			for _, contentID := range gc.ConceptIDs {
-				if contentID == targetID {
-					contents = append(contents, gc)
-					break
-				}
-			}
+		if slices.ContainsFunc(gc.ConceptIDs, func(id int) bool { return slices.Contains(conceptIDs, id) }) {
+			contents = append(contents, gc)
 		}
 	}
 
@@ -182,45 +182,84 @@ func GetGeneratedContentByConceptIDs(conceptIDs []int) ([]models.GeneratedConten
 	return contents, nil
 }
 
-// GetAllGeneratedContents retrieves all generated contents
-func GetAllGeneratedContents() ([]models.GeneratedContent, error) {
-	query := `
-		SELECT id, platform, title, body, concept_ids, status, published_at, created_at, updated_at
+// GetGeneratedContents retrieves a page of generated contents filtered and
+// sorted by query. The returned cursor is nil on the last page.
+func GetGeneratedContents(query models.GeneratedContentListQuery, page models.Page) ([]models.GeneratedContent, *models.Cursor, error) {
+	where := "TRUE"
+	args := []interface{}{}
+
+	if query.Platform != "" {
+		args = append(args, query.Platform)
+		where += fmt.Sprintf(" AND platform = $%d", len(args))
+	}
+	if query.Status != "" {
+		args = append(args, query.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if query.SourceContentID != nil {
+		args = append(args, *query.SourceContentID)
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM concepts c
+			WHERE c.source_content_id = $%d AND generated_contents.concept_ids @> to_jsonb(ARRAY[c.id])
+		)`, len(args))
+	}
+	if query.ConceptID != nil {
+		args = append(args, *query.ConceptID)
+		where += fmt.Sprintf(" AND concept_ids @> to_jsonb(ARRAY[$%d::int])", len(args))
+	}
+	if query.SeriesID != nil {
+		args = append(args, *query.SeriesID)
+		where += fmt.Sprintf(" AND series_id = $%d", len(args))
+	}
+	var dates, owned string
+	dates, args = dateRangeClause("created_at", query.From, query.To, args)
+	where += dates
+	owned, args = ownerClause("owner_id", query.OwnerID, args)
+	where += owned
+
+	sortColumn := "created_at"
+	if query.Sort == "updated_at" {
+		sortColumn = "updated_at"
+	}
+	var paging string
+	paging, args = pageClause(sortColumn, "id", query.Order == models.OrderAsc, page, args)
+
+	sqlQuery := `
+		SELECT ` + generatedContentColumns + `
 		FROM generated_contents
-		ORDER BY created_at DESC
-	`
+		WHERE ` + where + paging
 
-	rows, err := DB.Query(query)
+	rows, err := DB.Query(sqlQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query generated contents: %w", err)
+		return nil, nil, fmt.Errorf("failed to query generated contents: %w", err)
 	}
 	defer rows.Close()
 
-	var contents []models.GeneratedContent
+	contents := []models.GeneratedContent{}
 	for rows.Next() {
 		var gc models.GeneratedContent
-		err := rows.Scan(
-			&gc.ID,
-			&gc.Platform,
-			&gc.Title,
-			&gc.Body,
-			&gc.ConceptIDs,
-			&gc.Status,
-			&gc.PublishedAt,
-			&gc.CreatedAt,
-			&gc.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan generated content: %w", err)
+		if err := scanGeneratedContent(rows, &gc); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan generated content: %w", err)
 		}
 		contents = append(contents, gc)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating generated contents: %w", err)
+		return nil, nil, fmt.Errorf("error iterating generated contents: %w", err)
 	}
 
-	return contents, nil
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(contents) > page.Limit {
+		contents = contents[:page.Limit]
+		last := contents[len(contents)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if query.Sort == "updated_at" {
+			next.CreatedAt = last.UpdatedAt
+		}
+	}
+
+	return contents, next, nil
 }
 
 // UpdateGeneratedContent updates an existing generated content
@@ -238,7 +277,7 @@ func UpdateGeneratedContent(id int, req models.UpdateGeneratedContentRequest) (*
 
 	if req.Body != nil {
 		query += fmt.Sprintf("body = $%d, ", argCount)
-		args = append(args, *req.Body)
+		args = append(args, sealed(*req.Body, "generated_contents", "body", id))
 		argCount++
 	}
 
@@ -248,25 +287,20 @@ func UpdateGeneratedContent(id int, req models.UpdateGeneratedContentRequest) (*
 		argCount++
 	}
 
+	// Hand edits to the generated text feed prompt experiment metrics
+	if req.Title != nil || req.Body != nil {
+		query += "edited_at = NOW(), "
+	}
+
 	// Always update updated_at
 	query += fmt.Sprintf("updated_at = NOW() WHERE id = $%d ", argCount)
 	args = append(args, id)
 	argCount++
 
-	query += "RETURNING id, platform, title, body, concept_ids, status, published_at, created_at, updated_at"
+	query += "RETURNING " + generatedContentColumns
 
 	var gc models.GeneratedContent
-	err := DB.QueryRow(query, args...).Scan(
-		&gc.ID,
-		&gc.Platform,
-		&gc.Title,
-		&gc.Body,
-		&gc.ConceptIDs,
-		&gc.Status,
-		&gc.PublishedAt,
-		&gc.CreatedAt,
-		&gc.UpdatedAt,
-	)
+	err := scanGeneratedContent(DB.QueryRow(query, args...), &gc)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("generated content not found")
@@ -278,6 +312,103 @@ func UpdateGeneratedContent(id int, req models.UpdateGeneratedContentRequest) (*
 	return &gc, nil
 }
 
+// SaveContentModeration stores the result of a moderation check on generated content
+func SaveContentModeration(id int, moderation models.ContentModeration) (*models.GeneratedContent, error) {
+	query := `
+		UPDATE generated_contents
+		SET moderation = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + generatedContentColumns + `
+	`
+
+	var gc models.GeneratedContent
+	err := scanGeneratedContent(DB.QueryRow(query, moderation, id), &gc)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("generated content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save content moderation: %w", err)
+	}
+
+	return &gc, nil
+}
+
+// SaveContentFactCheck stores the result of fact-checking generated content
+func SaveContentFactCheck(id int, factCheck models.FactCheck) (*models.GeneratedContent, error) {
+	query := `
+		UPDATE generated_contents
+		SET fact_check = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + generatedContentColumns + `
+	`
+
+	var gc models.GeneratedContent
+	err := scanGeneratedContent(DB.QueryRow(query, factCheck, id), &gc)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("generated content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save content fact check: %w", err)
+	}
+
+	return &gc, nil
+}
+
+// SaveContentEngagement stores the latest engagement imported for generated content
+func SaveContentEngagement(id int, engagement models.ContentEngagement) (*models.GeneratedContent, error) {
+	query := `
+		UPDATE generated_contents
+		SET engagement = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + generatedContentColumns + `
+	`
+
+	var gc models.GeneratedContent
+	err := scanGeneratedContent(DB.QueryRow(query, engagement, id), &gc)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("generated content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save content engagement: %w", err)
+	}
+
+	return &gc, nil
+}
+
+// SetGeneratedContentStatus moves generated content to status, storing its
+// moderation with it. published_at is set when it's first published and
+// cleared when it's moved back to draft.
+func SetGeneratedContentStatus(id int, status string, moderation *models.ContentModeration) (*models.GeneratedContent, error) {
+	query := `
+		UPDATE generated_contents
+		SET status = $1,
+			moderation = $2,
+			published_at = CASE
+				WHEN $1 = 'published' THEN COALESCE(published_at, NOW())
+				WHEN $1 = 'draft' THEN NULL
+				ELSE published_at
+			END,
+			updated_at = NOW()
+		WHERE id = $3
+		RETURNING ` + generatedContentColumns + `
+	`
+
+	var gc models.GeneratedContent
+	err := scanGeneratedContent(DB.QueryRow(query, status, moderation, id), &gc)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("generated content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update generated content status: %w", err)
+	}
+
+	return &gc, nil
+}
+
 // DeleteGeneratedContent deletes a generated content by ID
 func DeleteGeneratedContent(id int) error {
 	query := "DELETE FROM generated_contents WHERE id = $1"
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// glossaryTermColumns is the column list scanned by scanGlossaryTerm
+const glossaryTermColumns = "id, source_content_id, term, definition, concept_ids, created_at"
+
+// scanGlossaryTerm scans a row selected with glossaryTermColumns
+func scanGlossaryTerm(row rowScanner, t *models.GlossaryTerm) error {
+	return row.Scan(
+		&t.ID,
+		&t.SourceContentID,
+		&t.Term,
+		&t.Definition,
+		&t.ConceptIDs,
+		&t.CreatedAt,
+	)
+}
+
+// ReplaceGlossaryTerms replaces a source's glossary in a single transaction.
+// Terms repeated within the batch (ignoring case) keep their first definition.
+func ReplaceGlossaryTerms(sourceContentID int, terms []models.GlossaryTerm) ([]models.GlossaryTerm, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	if _, err := tx.Exec("DELETE FROM glossary_terms WHERE source_content_id = $1", sourceContentID); err != nil {
+		return nil, fmt.Errorf("failed to clear glossary terms: %w", err)
+	}
+
+	query := `
+		INSERT INTO glossary_terms (source_content_id, term, definition, concept_ids)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + glossaryTermColumns
+
+	saved := make([]models.GlossaryTerm, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		key := strings.ToLower(term.Term)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		conceptIDs := term.ConceptIDs
+		if conceptIDs == nil {
+			conceptIDs = models.IntArray{}
+		}
+
+		var t models.GlossaryTerm
+		err := scanGlossaryTerm(tx.QueryRow(query, sourceContentID, term.Term, term.Definition, conceptIDs), &t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create glossary term: %w", err)
+		}
+		saved = append(saved, t)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return saved, nil
+}
+
+// GetGlossaryTermsBySourceContentID retrieves a source's glossary, alphabetically
+func GetGlossaryTermsBySourceContentID(sourceContentID int) ([]models.GlossaryTerm, error) {
+	query := `
+		SELECT ` + glossaryTermColumns + `
+		FROM glossary_terms
+		WHERE source_content_id = $1
+		ORDER BY LOWER(term) ASC
+	`
+
+	return queryGlossaryTerms(query, sourceContentID)
+}
+
+// GetGlossaryTerms retrieves the glossary terms of all unarchived sources
+// owner can see, alphabetically and then oldest source first
+func GetGlossaryTerms(owner *int) ([]models.GlossaryTerm, error) {
+	query := `
+		SELECT g.id, g.source_content_id, g.term, g.definition, g.concept_ids, g.created_at
+		FROM glossary_terms g
+		INNER JOIN source_contents s ON g.source_content_id = s.id
+		WHERE s.archived_at IS NULL AND ` + visibleTo("s.owner_id", 1) + `
+		ORDER BY LOWER(g.term) ASC, s.created_at ASC
+	`
+
+	return queryGlossaryTerms(query, owner)
+}
+
+// queryGlossaryTerms runs a query selecting glossaryTermColumns
+func queryGlossaryTerms(query string, args ...interface{}) ([]models.GlossaryTerm, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query glossary terms: %w", err)
+	}
+	defer rows.Close()
+
+	terms := []models.GlossaryTerm{}
+	for rows.Next() {
+		var t models.GlossaryTerm
+		if err := scanGlossaryTerm(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan glossary term: %w", err)
+		}
+		terms = append(terms, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating glossary terms: %w", err)
+	}
+
+	return terms, nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// pipelineHookColumns is the column list scanned by scanPipelineHook
+const pipelineHookColumns = "id, name, url, secret, stages, enabled, created_at"
+
+// scanPipelineHook scans a row selected with pipelineHookColumns
+func scanPipelineHook(row rowScanner, h *models.PipelineHook) error {
+	return row.Scan(
+		&h.ID,
+		&h.Name,
+		&h.URL,
+		&h.Secret,
+		&h.Stages,
+		&h.Enabled,
+		&h.CreatedAt,
+	)
+}
+
+// CreatePipelineHook registers a webhook hook
+func CreatePipelineHook(req models.CreatePipelineHookRequest) (*models.PipelineHook, error) {
+	query := `
+		INSERT INTO pipeline_hooks (name, url, secret, stages)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + pipelineHookColumns
+
+	var h models.PipelineHook
+	err := scanPipelineHook(DB.QueryRow(query, req.Name, req.URL, req.Secret, models.StringArray(req.Stages)), &h)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("hook name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipeline hook: %w", err)
+	}
+
+	return &h, nil
+}
+
+// GetPipelineHooks retrieves webhook hooks, optionally only enabled ones, oldest first
+func GetPipelineHooks(enabledOnly bool) ([]models.PipelineHook, error) {
+	query := `
+		SELECT ` + pipelineHookColumns + `
+		FROM pipeline_hooks
+		WHERE enabled OR NOT $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := DB.Query(query, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pipeline hooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.PipelineHook{}
+	for rows.Next() {
+		var h models.PipelineHook
+		if err := scanPipelineHook(rows, &h); err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline hook: %w", err)
+		}
+		hooks = append(hooks, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline hooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// SetPipelineHookEnabled enables or disables a webhook hook
+func SetPipelineHookEnabled(id int, enabled bool) (*models.PipelineHook, error) {
+	query := `
+		UPDATE pipeline_hooks
+		SET enabled = $2
+		WHERE id = $1
+		RETURNING ` + pipelineHookColumns
+
+	var h models.PipelineHook
+	err := scanPipelineHook(DB.QueryRow(query, id, enabled), &h)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("hook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pipeline hook: %w", err)
+	}
+
+	return &h, nil
+}
+
+// DeletePipelineHook deletes a webhook hook by ID
+func DeletePipelineHook(id int) error {
+	query := "DELETE FROM pipeline_hooks WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pipeline hook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("hook not found")
+	}
+
+	return nil
+}
+
+// CreatePipelineHookRun records a hook call in the run log
+func CreatePipelineHookRun(run models.PipelineHookRun) error {
+	query := `
+		INSERT INTO pipeline_hook_runs (hook_name, stage, source_content_id, status, error, concepts_updated, annotations)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := DB.Exec(query, run.HookName, run.Stage, run.SourceContentID, run.Status, run.Error, run.ConceptsUpdated, run.Annotations)
+	if err != nil {
+		return fmt.Errorf("failed to create pipeline hook run: %w", err)
+	}
+
+	return nil
+}
+
+// GetPipelineHookRuns retrieves a source's hook runs, oldest first
+func GetPipelineHookRuns(sourceContentID int) ([]models.PipelineHookRun, error) {
+	query := `
+		SELECT id, hook_name, stage, source_content_id, status, error, concepts_updated, annotations, created_at
+		FROM pipeline_hook_runs
+		WHERE source_content_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := DB.Query(query, sourceContentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pipeline hook runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.PipelineHookRun{}
+	for rows.Next() {
+		var r models.PipelineHookRun
+		if err := rows.Scan(&r.ID, &r.HookName, &r.Stage, &r.SourceContentID, &r.Status, &r.Error, &r.ConceptsUpdated, &r.Annotations, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline hook run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline hook runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CreateImportedDeck saves an imported deck in a single transaction: the
+// source, and for each card a concept in deck order with its flashcard and
+// any multiple-choice question. Flashcards from Anki are linked to their
+// notes, so the Anki bridge applies the notes' reviews rather than adding
+// them again; a note making several cloze cards links only the first.
+func CreateImportedDeck(source models.CreateSourceContentRequest, cards []models.ImportedCard) (*models.ImportResult, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	sourceID, err := nextID(tx, "source_contents")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceQuery := `
+		INSERT INTO source_contents (id, type, url, title, transcript, owner_id, processed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING ` + sourceContentColumns
+
+	var sc models.SourceContent
+	err = scanSourceContent(tx.QueryRow(sourceQuery, sourceID, source.Type, source.URL, source.Title, sealed(source.Transcript, "source_contents", "transcript", sourceID), source.OwnerID), &sc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source content: %w", err)
+	}
+
+	conceptQuery := `
+		INSERT INTO concepts (title, description, source_content_id, tags, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	flashcardQuery := `
+		INSERT INTO flashcards (concept_id, front, back)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	ankiLinkQuery := `
+		INSERT INTO anki_note_links (anki_note_id, flashcard_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	questionQuery := `
+		INSERT INTO quiz_questions (
+			concept_id, question, option_a, option_b, option_c, option_d,
+			correct_answer, explanation, distractor_rationales
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	result := &models.ImportResult{SourceContent: &sc}
+	for i, card := range cards {
+		var conceptID int
+		err := tx.QueryRow(conceptQuery, card.Title, card.Description, sc.ID, models.StringArray(card.Tags), i+1).Scan(&conceptID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create concept: %w", err)
+		}
+		result.Concepts++
+
+		var flashcardID int
+		if err := tx.QueryRow(flashcardQuery, conceptID, card.Front, card.Back).Scan(&flashcardID); err != nil {
+			return nil, fmt.Errorf("failed to create flashcard: %w", err)
+		}
+		result.Flashcards++
+
+		if card.AnkiNoteID != 0 {
+			if _, err := tx.Exec(ankiLinkQuery, card.AnkiNoteID, flashcardID); err != nil {
+				return nil, fmt.Errorf("failed to link Anki note: %w", err)
+			}
+		}
+
+		if q := card.Question; q != nil {
+			_, err := tx.Exec(questionQuery, conceptID, q.Question, q.OptionA, q.OptionB, q.OptionC, q.OptionD,
+				q.CorrectAnswer, q.Explanation, q.DistractorRationales)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create quiz question: %w", err)
+			}
+			result.QuizQuestions++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
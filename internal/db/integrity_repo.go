@@ -0,0 +1,155 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// integritySampleSize caps the row IDs returned per integrity check
+const integritySampleSize = 100
+
+// integrityCheck pairs a check with the query selecting the IDs it flags
+type integrityCheck struct {
+	models.IntegrityCheck
+	query string
+}
+
+// integrityChecks cover the links foreign keys can't: IDs held in JSONB arrays
+var integrityChecks = []integrityCheck{
+	{
+		IntegrityCheck: models.IntegrityCheck{
+			Name:        "generated_content_concepts",
+			Description: "Generated content linking concepts that no longer exist",
+		},
+		query: `
+			SELECT g.id FROM generated_contents g
+			WHERE EXISTS (
+				SELECT 1 FROM jsonb_array_elements(g.concept_ids) e
+				WHERE NOT EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+			)
+			ORDER BY g.id
+		`,
+	},
+	{
+		IntegrityCheck: models.IntegrityCheck{
+			Name:        "glossary_term_concepts",
+			Description: "Glossary terms linking concepts that no longer exist",
+		},
+		query: `
+			SELECT g.id FROM glossary_terms g
+			WHERE EXISTS (
+				SELECT 1 FROM jsonb_array_elements(g.concept_ids) e
+				WHERE NOT EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+			)
+			ORDER BY g.id
+		`,
+	},
+	{
+		IntegrityCheck: models.IntegrityCheck{
+			Name:        "comparison_sources",
+			Description: "Source comparisons of sources that no longer exist",
+		},
+		query: `
+			SELECT sc.id FROM source_comparisons sc
+			WHERE EXISTS (
+				SELECT 1 FROM jsonb_array_elements(sc.source_content_ids) e
+				WHERE NOT EXISTS (SELECT 1 FROM source_contents s WHERE to_jsonb(s.id) = e)
+			)
+			ORDER BY sc.id
+		`,
+	},
+	{
+		IntegrityCheck: models.IntegrityCheck{
+			Name:        "unlinked_generated_content",
+			Description: "Generated content whose concepts have all been deleted",
+			Informative: true,
+		},
+		query: `
+			SELECT id FROM generated_contents
+			WHERE concept_ids = '[]'::jsonb
+			ORDER BY id
+		`,
+	},
+	{
+		IntegrityCheck: models.IntegrityCheck{
+			Name:        "detached_concepts",
+			Description: "Concepts without a source: created directly, or left by source deletes before they cascaded",
+			Informative: true,
+		},
+		query: `
+			SELECT id FROM concepts
+			WHERE source_content_id IS NULL
+			ORDER BY id
+		`,
+	},
+}
+
+// RunIntegrityChecks runs every integrity check, collecting the flagged IDs
+func RunIntegrityChecks() ([]models.IntegrityCheck, error) {
+	checks := make([]models.IntegrityCheck, 0, len(integrityChecks))
+	for _, ic := range integrityChecks {
+		check := ic.IntegrityCheck
+		check.IDs = []int{}
+
+		rows, err := DB.Query(ic.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run integrity check %s: %w", check.Name, err)
+		}
+
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan integrity check %s: %w", check.Name, err)
+			}
+			if check.Count < integritySampleSize {
+				check.IDs = append(check.IDs, id)
+			}
+			check.Count++
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating integrity check %s: %w", check.Name, err)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// GetUnguardedForeignKeys lists foreign keys without an ON DELETE rule, as
+// table.constraint
+func GetUnguardedForeignKeys() ([]string, error) {
+	query := `
+		SELECT conrelid::regclass::text || '.' || conname
+		FROM pg_constraint
+		WHERE contype = 'f' AND confdeltype = 'a'
+			AND connamespace = current_schema()::regnamespace
+		ORDER BY 1
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return keys, nil
+}
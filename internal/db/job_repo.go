@@ -0,0 +1,209 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// jobColumns is the column list scanned by scanJob
+const jobColumns = `id, status, request, spec, source_content_id, warnings, redactions, error, created_at, started_at, finished_at, owner_id`
+
+// scanJob scans a row selected with jobColumns
+func scanJob(row rowScanner, job *models.Job) error {
+	return row.Scan(
+		&job.ID,
+		&job.Status,
+		&job.Request,
+		&job.Spec,
+		&job.SourceContentID,
+		&job.Warnings,
+		&job.Redactions,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.OwnerID,
+	)
+}
+
+// CreateJob queues a source for processing with spec, to be owned by
+// req.OwnerID
+func CreateJob(req models.CreateSourceContentRequest, spec models.PipelineSpec) (*models.Job, error) {
+	query := `
+		INSERT INTO jobs (request, spec, owner_id)
+		VALUES ($1, $2, $3)
+		RETURNING ` + jobColumns
+
+	var job models.Job
+	if err := scanJob(DB.QueryRow(query, req, spec, req.OwnerID), &job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// GetJobByID retrieves a job by ID
+func GetJobByID(id int) (*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+
+	var job models.Job
+	err := scanJob(DB.QueryRow(query, id), &job)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ClaimNextJob marks the oldest queued job running and returns it. Returns
+// nil without an error when nothing is queued. Concurrent workers never claim
+// the same job.
+func ClaimNextJob() (*models.Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'running', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'queued'
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	var job models.Job
+	err := scanJob(DB.QueryRow(query), &job)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// SetJobSource records the source a running job saved, so its stages'
+// progress can be followed through the source's pipeline run
+func SetJobSource(id, sourceContentID int) error {
+	if _, err := DB.Exec(`UPDATE jobs SET source_content_id = $2 WHERE id = $1`, id, sourceContentID); err != nil {
+		return fmt.Errorf("failed to set job source: %w", err)
+	}
+	return nil
+}
+
+// FinishJob records the outcome of a job: the source it processed with the
+// run's warnings and redaction counts, or why it failed
+func FinishJob(id int, sourceContentID *int, warnings models.StageWarnings, redactions models.JSONObject, failure *string) error {
+	query := `
+		UPDATE jobs
+		SET status = CASE WHEN $5::text IS NULL THEN 'completed' ELSE 'failed' END,
+			source_content_id = COALESCE($2, source_content_id), warnings = $3, redactions = $4, error = $5, finished_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := DB.Exec(query, id, sourceContentID, warnings, redactions, failure); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// RequeueInterruptedJobs queues jobs still marked running again. Call it at
+// startup, when no job can still be in progress.
+func RequeueInterruptedJobs() (int, error) {
+	result, err := DB.Exec(`
+		UPDATE jobs
+		SET status = 'queued', started_at = NULL
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue interrupted jobs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// GetJobs retrieves a page of jobs newest first, only those with status
+// unless it's empty
+func GetJobs(status string, page models.Page) ([]models.Job, *models.Cursor, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE ($1 = '' OR status = $1)
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	rows, err := DB.Query(query, status, afterTime, afterID, page.Limit+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		var job models.Job
+		if err := scanJob(rows, &job); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(jobs) > page.Limit {
+		jobs = jobs[:page.Limit]
+		last := jobs[len(jobs)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return jobs, next, nil
+}
+
+// CountJobsByStatus counts jobs per status, every status included
+func CountJobsByStatus() (map[string]int, error) {
+	rows, err := DB.Query(`SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{
+		models.JobQueued:    0,
+		models.JobRunning:   0,
+		models.JobCompleted: 0,
+		models.JobFailed:    0,
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job counts: %w", err)
+	}
+
+	return counts, nil
+}
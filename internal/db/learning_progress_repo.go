@@ -0,0 +1,151 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// learningProgressColumns is the column list scanned by scanLearningProgress
+const learningProgressColumns = "id, concept_id, mastery_level, consecutive_correct, ease_factor, interval_days, stability, difficulty, last_reviewed_at, next_review_at, created_at, updated_at"
+
+// scanLearningProgress scans a row selected with learningProgressColumns
+func scanLearningProgress(row rowScanner, lp *models.LearningProgress) error {
+	return row.Scan(
+		&lp.ID,
+		&lp.ConceptID,
+		&lp.MasteryLevel,
+		&lp.ConsecutiveCorrect,
+		&lp.EaseFactor,
+		&lp.IntervalDays,
+		&lp.Stability,
+		&lp.Difficulty,
+		&lp.LastReviewedAt,
+		&lp.NextReviewAt,
+		&lp.CreatedAt,
+		&lp.UpdatedAt,
+	)
+}
+
+// GetLearningProgressByConceptID retrieves spaced repetition progress for a concept.
+// Returns nil without an error when the concept has never been reviewed.
+func GetLearningProgressByConceptID(conceptID int) (*models.LearningProgress, error) {
+	query := `
+		SELECT ` + learningProgressColumns + `
+		FROM learning_progress
+		WHERE concept_id = $1
+	`
+
+	var lp models.LearningProgress
+	err := scanLearningProgress(DB.QueryRow(query, conceptID), &lp)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, just never reviewed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query learning progress: %w", err)
+	}
+
+	return &lp, nil
+}
+
+// UpsertLearningProgress creates or replaces the scheduling state for a concept
+func UpsertLearningProgress(lp models.LearningProgress) (*models.LearningProgress, error) {
+	query := `
+		INSERT INTO learning_progress (
+			concept_id, mastery_level, consecutive_correct, ease_factor, interval_days,
+			stability, difficulty, last_reviewed_at, next_review_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (concept_id) DO UPDATE SET
+			mastery_level = EXCLUDED.mastery_level,
+			consecutive_correct = EXCLUDED.consecutive_correct,
+			ease_factor = EXCLUDED.ease_factor,
+			interval_days = EXCLUDED.interval_days,
+			stability = EXCLUDED.stability,
+			difficulty = EXCLUDED.difficulty,
+			last_reviewed_at = EXCLUDED.last_reviewed_at,
+			next_review_at = EXCLUDED.next_review_at
+		RETURNING ` + learningProgressColumns
+
+	var saved models.LearningProgress
+	err := scanLearningProgress(DB.QueryRow(
+		query,
+		lp.ConceptID,
+		lp.MasteryLevel,
+		lp.ConsecutiveCorrect,
+		lp.EaseFactor,
+		lp.IntervalDays,
+		lp.Stability,
+		lp.Difficulty,
+		lp.LastReviewedAt,
+		lp.NextReviewAt,
+	), &saved)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to save learning progress: %w", err)
+	}
+
+	return &saved, nil
+}
+
+// GetReviewedLearningProgress retrieves the progress of every concept that has
+// been reviewed
+func GetReviewedLearningProgress() ([]models.LearningProgress, error) {
+	query := `
+		SELECT ` + learningProgressColumns + `
+		FROM learning_progress
+		WHERE last_reviewed_at IS NOT NULL
+		ORDER BY id ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query learning progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := []models.LearningProgress{}
+	for rows.Next() {
+		var lp models.LearningProgress
+		if err := scanLearningProgress(rows, &lp); err != nil {
+			return nil, fmt.Errorf("failed to scan learning progress: %w", err)
+		}
+		progress = append(progress, lp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating learning progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+// UpdateReviewSchedules saves the scheduling state of many concepts in a
+// single transaction, leaving their mastery untouched
+func UpdateReviewSchedules(progress []models.LearningProgress) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	query := `
+		UPDATE learning_progress
+		SET interval_days = $1, stability = $2, difficulty = $3, next_review_at = $4
+		WHERE id = $5
+	`
+	for _, lp := range progress {
+		if _, err := tx.Exec(query, lp.IntervalDays, lp.Stability, lp.Difficulty, lp.NextReviewAt, lp.ID); err != nil {
+			return fmt.Errorf("failed to update review schedule of concept %d: %w", lp.ConceptID, err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// mentionColumns is the column list scanned by scanMention
+const mentionColumns = "id, source_content_id, kind, name, context, url, recommended, created_at"
+
+// scanMention scans a row selected with mentionColumns
+func scanMention(row rowScanner, m *models.Mention) error {
+	return row.Scan(
+		&m.ID,
+		&m.SourceContentID,
+		&m.Kind,
+		&m.Name,
+		&m.Context,
+		&m.URL,
+		&m.Recommended,
+		&m.CreatedAt,
+	)
+}
+
+// ReplaceMentions replaces a source's mentions in a single transaction.
+// Entities repeated within the batch (same kind, ignoring case) keep the first.
+func ReplaceMentions(sourceContentID int, mentions []models.Mention) ([]models.Mention, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	if _, err := tx.Exec("DELETE FROM mentions WHERE source_content_id = $1", sourceContentID); err != nil {
+		return nil, fmt.Errorf("failed to clear mentions: %w", err)
+	}
+
+	query := `
+		INSERT INTO mentions (source_content_id, kind, name, context, url, recommended)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + mentionColumns
+
+	saved := make([]models.Mention, 0, len(mentions))
+	seen := make(map[string]bool, len(mentions))
+	for _, mention := range mentions {
+		key := mention.Kind + ":" + strings.ToLower(mention.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var m models.Mention
+		err := scanMention(tx.QueryRow(query,
+			sourceContentID,
+			mention.Kind,
+			mention.Name,
+			mention.Context,
+			mention.URL,
+			mention.Recommended,
+		), &m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mention: %w", err)
+		}
+		saved = append(saved, m)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return saved, nil
+}
+
+// GetMentionsBySourceContentID retrieves a source's mentions by kind, then name
+func GetMentionsBySourceContentID(sourceContentID int) ([]models.Mention, error) {
+	query := `
+		SELECT ` + mentionColumns + `
+		FROM mentions
+		WHERE source_content_id = $1
+		ORDER BY kind ASC, LOWER(name) ASC
+	`
+
+	rows, err := DB.Query(query, sourceContentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mentions: %w", err)
+	}
+	defer rows.Close()
+
+	mentions := []models.Mention{}
+	for rows.Next() {
+		var m models.Mention
+		if err := scanMention(rows, &m); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		mentions = append(mentions, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+
+	return mentions, nil
+}
+
+// GetMentionEntries retrieves mentions across unarchived sources owner can
+// see, optionally filtered by kind and to recommendations. Mentions of the same kind and name
+// (ignoring case) are merged into one entry, listing the oldest source first.
+func GetMentionEntries(kind *string, recommendedOnly bool, owner *int) ([]models.MentionEntry, error) {
+	query := `
+		SELECT m.id, m.source_content_id, s.title, m.kind, m.name, m.context, m.url, m.recommended
+		FROM mentions m
+		INNER JOIN source_contents s ON m.source_content_id = s.id
+		WHERE s.archived_at IS NULL
+			AND ($1::text IS NULL OR m.kind = $1)
+			AND (NOT $2 OR m.recommended)
+			AND ` + visibleTo("s.owner_id", 3) + `
+		ORDER BY m.kind ASC, LOWER(m.name) ASC, s.created_at ASC
+	`
+
+	rows, err := DB.Query(query, kind, recommendedOnly, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mentions: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.MentionEntry{}
+	var key string
+	for rows.Next() {
+		var src models.MentionSource
+		var mentionKind, name string
+		var url *string
+		if err := rows.Scan(&src.MentionID, &src.SourceContentID, &src.SourceTitle, &mentionKind, &name, &src.Context, &url, &src.Recommended); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+
+		// Rows arrive grouped, so a new key starts a new entry
+		if k := mentionKind + ":" + strings.ToLower(name); len(entries) == 0 || k != key {
+			key = k
+			entries = append(entries, models.MentionEntry{
+				Kind:    mentionKind,
+				Name:    name,
+				Sources: []models.MentionSource{},
+			})
+		}
+
+		entry := &entries[len(entries)-1]
+		if entry.URL == nil {
+			entry.URL = url
+		}
+		entry.Recommended = entry.Recommended || src.Recommended
+		entry.Sources = append(entry.Sources, src)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+
+	return entries, nil
+}
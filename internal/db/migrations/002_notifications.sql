@@ -0,0 +1,36 @@
+-- Notifications
+-- Adds the in-app notification store and per-event delivery preferences
+
+-- Notifications table (in-app notification feed)
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed')),
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB, -- Event payload (source_content_id, content_id, etc.)
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Notification Preferences table (which channels each event is delivered to)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    event_type VARCHAR(50) PRIMARY KEY CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed')),
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT FALSE,
+    webhook BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default preferences: everything in-app, nothing external
+INSERT INTO notification_preferences (event_type) VALUES
+    ('pipeline_complete'),
+    ('review_due'),
+    ('publish_succeeded'),
+    ('publish_failed')
+ON CONFLICT (event_type) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(read_at) WHERE read_at IS NULL;
+
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,11 @@
+-- Concept tags and autocomplete search
+-- Adds topic tags to concepts and trigram indexes for search-as-you-type
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'; -- Array of lowercase tags
+
+-- Trigram indexes back the ILIKE lookups used by autocomplete
+CREATE INDEX IF NOT EXISTS idx_concepts_title_trgm ON concepts USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_concepts_tags_trgm ON concepts USING gin ((tags::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_source_contents_title_trgm ON source_contents USING gin (title gin_trgm_ops);
@@ -0,0 +1,8 @@
+-- Archived state
+-- Archiving hides stale content from lists, search, review, and generation without deleting it
+
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_concepts_active ON concepts(created_at) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_source_contents_active ON source_contents(created_at) WHERE archived_at IS NULL;
@@ -0,0 +1,7 @@
+-- Concept ordering and pinning
+-- Lets users arrange a source's concepts in teaching order and pin key ones to the top
+
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS position INTEGER; -- Teaching order within the source, NULL = unordered
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_concepts_source_position ON concepts(source_content_id, pinned, position);
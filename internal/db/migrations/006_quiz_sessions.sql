@@ -0,0 +1,26 @@
+-- Quiz sessions
+-- Questions are served with shuffled options per session; the mapping is stored
+-- so answers given in displayed letters are graded against the canonical answer
+
+CREATE TABLE IF NOT EXISTS quiz_sessions (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE CASCADE,
+    concept_id INTEGER REFERENCES concepts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- option_order maps displayed letters to canonical ones: 'CADB' means displayed A
+-- shows canonical option C, displayed B shows canonical A, and so on
+CREATE TABLE IF NOT EXISTS quiz_session_questions (
+    session_id INTEGER NOT NULL REFERENCES quiz_sessions(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    option_order CHAR(4) NOT NULL,
+    PRIMARY KEY (session_id, question_id)
+);
+
+-- Attempts keep the canonical selected_answer; the displayed letter is kept for auditing
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS session_id INTEGER REFERENCES quiz_sessions(id) ON DELETE SET NULL;
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS displayed_answer CHAR(1) CHECK (displayed_answer IN ('A', 'B', 'C', 'D'));
+
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_session ON quiz_attempts(session_id);
@@ -0,0 +1,14 @@
+-- Quiz explanations
+-- Caches Claude's deeper "explain more" explanation per question so repeat requests are free
+
+CREATE TABLE IF NOT EXISTS quiz_explanations (
+    question_id INTEGER PRIMARY KEY REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    explanation TEXT NOT NULL,
+    analogy TEXT NOT NULL,
+    worked_example TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_quiz_explanations_updated_at BEFORE UPDATE ON quiz_explanations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Distractor rationales
+-- One-line "why this is wrong" per incorrect option, keyed by canonical option letter
+
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS distractor_rationales JSONB NOT NULL DEFAULT '{}';
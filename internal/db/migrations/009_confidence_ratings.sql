@@ -0,0 +1,8 @@
+-- Confidence-rated answers
+-- Learners self-rate recall (again/hard/good/easy); the rating drives the
+-- spaced-repetition interval, so progress keeps an ease factor and interval
+
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS rating VARCHAR(10) CHECK (rating IN ('again', 'hard', 'good', 'easy'));
+
+ALTER TABLE learning_progress ADD COLUMN IF NOT EXISTS ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5;
+ALTER TABLE learning_progress ADD COLUMN IF NOT EXISTS interval_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Review sessions
+-- Themed review sessions draw due-or-weak concepts for a tag or source
+
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'quiz' CHECK (kind IN ('quiz', 'review'));
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS tag TEXT;
@@ -0,0 +1,20 @@
+-- Leech handling
+-- Questions failed repeatedly are suspended from sessions until the learner
+-- regenerates a simpler variant, converts them to a flashcard, or unsuspends them
+
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS lapses INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_suspended ON quiz_questions(suspended_at) WHERE suspended_at IS NOT NULL;
+
+-- Flashcards are plain front/back cards, typically converted from leeches
+CREATE TABLE IF NOT EXISTS flashcards (
+    id SERIAL PRIMARY KEY,
+    concept_id INTEGER NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+    question_id INTEGER REFERENCES quiz_questions(id) ON DELETE SET NULL,
+    front TEXT NOT NULL,
+    back TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_concept ON flashcards(concept_id);
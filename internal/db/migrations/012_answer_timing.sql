@@ -0,0 +1,4 @@
+-- Answer timing
+-- Client-reported time from question shown to answer submitted, for question analytics
+
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS time_to_answer_ms INTEGER CHECK (time_to_answer_ms >= 0);
@@ -0,0 +1,8 @@
+-- Timed quiz mode
+-- Sessions may set a per-question time limit; attempts record server-measured
+-- latency since the question became current and whether the limit was exceeded
+
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS time_limit_seconds INTEGER CHECK (time_limit_seconds > 0);
+
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS latency_ms INTEGER CHECK (latency_ms >= 0);
+ALTER TABLE quiz_attempts ADD COLUMN IF NOT EXISTS timed_out BOOLEAN NOT NULL DEFAULT FALSE;
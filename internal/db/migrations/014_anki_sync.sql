@@ -0,0 +1,23 @@
+-- Anki sync
+-- Links lattice questions and flashcards to the Anki notes an AnkiConnect bridge
+-- created for them, and records imported Anki reviews so each is applied once
+
+CREATE TABLE IF NOT EXISTS anki_note_links (
+    anki_note_id BIGINT PRIMARY KEY,
+    question_id INTEGER UNIQUE REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    flashcard_id INTEGER UNIQUE REFERENCES flashcards(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((question_id IS NULL) <> (flashcard_id IS NULL))
+);
+
+-- anki_review_id is Anki's revlog id (milliseconds since epoch of the review)
+CREATE TABLE IF NOT EXISTS anki_reviews (
+    anki_review_id BIGINT PRIMARY KEY,
+    anki_note_id BIGINT NOT NULL,
+    concept_id INTEGER REFERENCES concepts(id) ON DELETE CASCADE,
+    ease SMALLINT NOT NULL CHECK (ease BETWEEN 1 AND 4),
+    duration_ms INTEGER,
+    synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_anki_reviews_concept ON anki_reviews(concept_id);
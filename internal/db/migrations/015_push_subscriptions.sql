@@ -0,0 +1,17 @@
+-- Push notifications
+-- ntfy and Gotify subscriptions for phone/browser push, plus a push delivery
+-- channel on notification preferences (on by default for due reviews)
+
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('ntfy', 'gotify')),
+    endpoint TEXT NOT NULL, -- ntfy topic URL, or Gotify server URL
+    token TEXT,             -- ntfy access token or Gotify application token
+    name TEXT,
+    last_delivered_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS push BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE notification_preferences SET push = TRUE WHERE event_type = 'review_due';
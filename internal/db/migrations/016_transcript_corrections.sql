@@ -0,0 +1,6 @@
+-- Transcript corrections
+-- Keeps the transcript as first fetched when it is corrected by hand, so
+-- fixes can be reviewed or reverted
+
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS original_transcript TEXT;
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS transcript_corrected_at TIMESTAMP;
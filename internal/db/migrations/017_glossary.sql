@@ -0,0 +1,15 @@
+-- Glossary
+-- Domain terms and their definitions extracted from each source's transcript,
+-- linked to the concepts they support
+
+CREATE TABLE IF NOT EXISTS glossary_terms (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    term VARCHAR(255) NOT NULL,
+    definition TEXT NOT NULL,
+    concept_ids JSONB NOT NULL DEFAULT '[]', -- Array of concept IDs
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_glossary_terms_source_term ON glossary_terms(source_content_id, LOWER(term));
+CREATE INDEX IF NOT EXISTS idx_glossary_terms_term ON glossary_terms(LOWER(term));
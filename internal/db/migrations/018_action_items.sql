@@ -0,0 +1,16 @@
+-- Action items
+-- Explicit "do this" advice extracted from each source, tracked as a checklist
+
+CREATE TABLE IF NOT EXISTS action_items (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    concept_id INTEGER REFERENCES concepts(id) ON DELETE SET NULL,
+    text TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done', 'dismissed')),
+    position INTEGER NOT NULL DEFAULT 0,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_action_items_source_content ON action_items(source_content_id, position);
+CREATE INDEX IF NOT EXISTS idx_action_items_status ON action_items(status);
@@ -0,0 +1,16 @@
+-- Mentions
+-- Books, tools, people, and frameworks named in each source
+
+CREATE TABLE IF NOT EXISTS mentions (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('book', 'tool', 'person', 'framework')),
+    name VARCHAR(255) NOT NULL,
+    context TEXT NOT NULL DEFAULT '', -- Why the source mentions it
+    url TEXT,
+    recommended BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mentions_source_name ON mentions(source_content_id, kind, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_mentions_kind_name ON mentions(kind, LOWER(name));
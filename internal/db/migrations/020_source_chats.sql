@@ -0,0 +1,19 @@
+-- Source chats
+-- "Discuss this video" conversations scoped to one source's transcript and concepts
+
+CREATE TABLE IF NOT EXISTS source_chats (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS source_chat_messages (
+    id SERIAL PRIMARY KEY,
+    chat_id INTEGER NOT NULL REFERENCES source_chats(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('user', 'assistant')),
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_chats_source_content ON source_chats(source_content_id);
+CREATE INDEX IF NOT EXISTS idx_source_chat_messages_chat ON source_chat_messages(chat_id, id);
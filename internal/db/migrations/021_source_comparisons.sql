@@ -0,0 +1,13 @@
+-- Source comparisons
+-- Structured comparisons of two or more sources: what they share, where they
+-- disagree, and what each adds
+
+CREATE TABLE IF NOT EXISTS source_comparisons (
+    id SERIAL PRIMARY KEY,
+    source_content_ids JSONB NOT NULL, -- Array of source content IDs, in request order
+    summary TEXT NOT NULL,
+    details JSONB NOT NULL, -- Shared concepts, disagreements, and unique points
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_comparisons_created ON source_comparisons(created_at);
@@ -0,0 +1,14 @@
+-- Pipeline definitions
+-- Stored pipeline specs (which stages run, in what order, with which prompts,
+-- models, and platforms). At most one is the default.
+
+CREATE TABLE IF NOT EXISTS pipeline_definitions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    spec JSONB NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pipeline_definitions_default ON pipeline_definitions(is_default) WHERE is_default;
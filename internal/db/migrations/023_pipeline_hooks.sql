@@ -0,0 +1,27 @@
+-- Pipeline hooks
+-- Webhooks called after pipeline stages, and a log of every hook run
+-- (Go hooks registered in code are logged too)
+
+CREATE TABLE IF NOT EXISTS pipeline_hooks (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    secret TEXT, -- Signs payloads with HMAC-SHA256 when set
+    stages JSONB NOT NULL DEFAULT '[]', -- Stage names to run after; empty for every stage
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pipeline_hook_runs (
+    id SERIAL PRIMARY KEY,
+    hook_name VARCHAR(255) NOT NULL,
+    stage VARCHAR(50) NOT NULL,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    error TEXT,
+    concepts_updated INTEGER NOT NULL DEFAULT 0,
+    annotations JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipeline_hook_runs_source_content ON pipeline_hook_runs(source_content_id, created_at);
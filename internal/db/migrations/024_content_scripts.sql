@@ -0,0 +1,13 @@
+-- Content scripts
+-- Ordered transform steps applied to generated content before it is saved,
+-- optionally limited to one platform
+
+CREATE TABLE IF NOT EXISTS content_scripts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    platform VARCHAR(50), -- NULL applies to every platform
+    steps JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Output templates
+-- User Go templates that render generated content into text formats such as
+-- Markdown notes or static-site posts
+
+CREATE TABLE IF NOT EXISTS output_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE, -- Passed as ?template=; overrides a built-in of the same name
+    body TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT 'text/plain',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
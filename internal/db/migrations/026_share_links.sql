@@ -0,0 +1,15 @@
+-- Share links
+-- Unguessable tokens that publish a concept or generated content as a public
+-- page with OpenGraph metadata
+
+CREATE TABLE IF NOT EXISTS share_links (
+    token VARCHAR(64) PRIMARY KEY,
+    concept_id INTEGER REFERENCES concepts(id) ON DELETE CASCADE,
+    generated_content_id INTEGER REFERENCES generated_contents(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((concept_id IS NULL) <> (generated_content_id IS NULL))
+);
+
+-- One link per item, so sharing twice returns the same URL
+CREATE UNIQUE INDEX IF NOT EXISTS idx_share_links_concept ON share_links(concept_id) WHERE concept_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_share_links_generated_content ON share_links(generated_content_id) WHERE generated_content_id IS NOT NULL;
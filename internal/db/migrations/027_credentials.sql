@@ -0,0 +1,15 @@
+-- Credentials
+-- Integration tokens (LinkedIn, X, Notion, SendGrid) encrypted with AES-256-GCM
+-- under a master key from the environment; key_id records which key sealed each
+
+CREATE TABLE IF NOT EXISTS credentials (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT 'default',
+    ciphertext BYTEA NOT NULL, -- Nonce followed by the sealed secret
+    key_id VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    rotated_at TIMESTAMP, -- Last re-encrypted under a new master key
+    UNIQUE (provider, name)
+);
@@ -0,0 +1,15 @@
+-- API tokens
+-- Bearer tokens with scopes and optional expiry. Only a SHA-256 hash of each
+-- token is stored. Once any token exists, /api requests must present one.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL, -- Start of the token, to recognize it in lists
+    scopes JSONB NOT NULL DEFAULT '[]',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
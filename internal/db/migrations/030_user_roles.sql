@@ -0,0 +1,9 @@
+-- User roles
+-- Each user's role grants a set of permissions; the first user is the admin
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'editor'
+    CHECK (role IN ('viewer', 'contributor', 'editor', 'admin'));
+
+UPDATE users SET role = 'admin'
+WHERE id = (SELECT MIN(id) FROM users)
+    AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin');
@@ -0,0 +1,7 @@
+-- Keyset pagination indexes
+-- Cursor-paginated lists order by (created_at, id) descending
+
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_keyset ON quiz_attempts(attempted_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_keyset ON notifications(created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_notifications_created;
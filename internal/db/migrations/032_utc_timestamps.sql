@@ -0,0 +1,21 @@
+-- UTC timestamps and user time zones
+-- Every TIMESTAMP column becomes TIMESTAMPTZ so instants are stored in UTC
+-- regardless of the server's zone. Existing values are read in the database's
+-- TimeZone setting, which is the zone CURRENT_TIMESTAMP defaults wrote them in.
+
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+            AND data_type = 'timestamp without time zone'
+    LOOP
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ', col.table_name, col.column_name);
+    END LOOP;
+END $$;
+
+-- IANA zone name, e.g. America/New_York; NULL uses the server's TIMEZONE
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
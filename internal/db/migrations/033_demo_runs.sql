@@ -0,0 +1,13 @@
+-- Public demo runs
+-- One row per unauthenticated demo request, for per-IP daily quotas
+
+CREATE TABLE IF NOT EXISTS demo_runs (
+    id SERIAL PRIMARY KEY,
+    ip VARCHAR(45) NOT NULL,
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE SET NULL,
+    tokens_used INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_demo_runs_ip_created ON demo_runs(ip, created_at);
@@ -0,0 +1,41 @@
+-- Prompt experiments
+-- A/B tests of stage prompts, which variant each source got, thumbs feedback
+-- on stage output, and when users hand-edited generated artifacts
+
+CREATE TABLE IF NOT EXISTS prompt_experiments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    stage VARCHAR(50) NOT NULL,
+    variant_a JSONB NOT NULL DEFAULT '{}',
+    variant_b JSONB NOT NULL DEFAULT '{}',
+    sample_rate DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (sample_rate > 0 AND sample_rate <= 1),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    winner CHAR(1) CHECK (winner IN ('a', 'b')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMPTZ
+);
+
+-- At most one running experiment per stage
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_experiments_active_stage ON prompt_experiments(stage) WHERE active;
+
+CREATE TABLE IF NOT EXISTS prompt_experiment_assignments (
+    experiment_id INTEGER NOT NULL REFERENCES prompt_experiments(id) ON DELETE CASCADE,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    variant CHAR(1) NOT NULL CHECK (variant IN ('a', 'b')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (experiment_id, source_content_id)
+);
+
+CREATE TABLE IF NOT EXISTS artifact_feedback (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    stage VARCHAR(50) NOT NULL,
+    positive BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_feedback_source ON artifact_feedback(source_content_id, stage);
+
+-- Set when a user edits generated text (not by reordering, archiving, or hooks)
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
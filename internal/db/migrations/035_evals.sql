@@ -0,0 +1,41 @@
+-- Concept extraction evals
+-- Stored transcripts with expected concepts, and judged runs over them per
+-- model and prompt version for regression tracking
+
+CREATE TABLE IF NOT EXISTS eval_cases (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    transcript TEXT NOT NULL,
+    expected_concepts JSONB NOT NULL DEFAULT '[]',
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS eval_runs (
+    id SERIAL PRIMARY KEY,
+    label VARCHAR(255),
+    model VARCHAR(100) NOT NULL,
+    prompt_version VARCHAR(100) NOT NULL,
+    instructions TEXT,
+    judge_model VARCHAR(100) NOT NULL,
+    cases INTEGER NOT NULL,
+    failed INTEGER NOT NULL DEFAULT 0,
+    coverage DOUBLE PRECISION NOT NULL,
+    precision DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_runs_version ON eval_runs(model, prompt_version, created_at);
+
+CREATE TABLE IF NOT EXISTS eval_results (
+    run_id INTEGER NOT NULL REFERENCES eval_runs(id) ON DELETE CASCADE,
+    case_id INTEGER NOT NULL REFERENCES eval_cases(id) ON DELETE CASCADE,
+    case_name VARCHAR(255) NOT NULL,
+    coverage DOUBLE PRECISION NOT NULL,
+    precision DOUBLE PRECISION NOT NULL,
+    extracted JSONB NOT NULL DEFAULT '[]',
+    missed JSONB NOT NULL DEFAULT '[]',
+    irrelevant JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    PRIMARY KEY (run_id, case_id)
+);
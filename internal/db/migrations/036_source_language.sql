@@ -0,0 +1,4 @@
+-- Source language
+-- The language of the transcript, detected when fetched (BCP 47, e.g. "es", "pt-BR")
+
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS language VARCHAR(20);
@@ -0,0 +1,15 @@
+-- Pipeline checkpoints
+-- Each new source's run records the stages it has finished, so a run cut short
+-- by a crash or a failed concept extraction is visible and can be resumed
+
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    source_content_id INTEGER PRIMARY KEY REFERENCES source_contents(id) ON DELETE CASCADE,
+    spec JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'incomplete')),
+    completed_stages JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_status ON pipeline_runs(status) WHERE status <> 'completed';
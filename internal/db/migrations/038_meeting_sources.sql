@@ -0,0 +1,6 @@
+-- Meeting sources
+-- Imported and uploaded meeting transcripts are stored with type 'meeting'
+
+ALTER TABLE source_contents DROP CONSTRAINT IF EXISTS source_contents_type_check;
+ALTER TABLE source_contents ADD CONSTRAINT source_contents_type_check
+    CHECK (type IN ('youtube', 'pdf', 'article', 'meeting'));
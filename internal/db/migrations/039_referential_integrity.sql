@@ -0,0 +1,52 @@
+-- Referential integrity
+-- Deleting a source deletes its concepts, and with them their quizzes, flashcards,
+-- and progress. Concept ID arrays in generated content and glossary terms can't
+-- carry foreign keys, so a trigger removes deleted concepts from them.
+
+ALTER TABLE concepts DROP CONSTRAINT IF EXISTS concepts_source_content_id_fkey;
+ALTER TABLE concepts ADD CONSTRAINT concepts_source_content_id_fkey
+    FOREIGN KEY (source_content_id) REFERENCES source_contents(id) ON DELETE CASCADE;
+
+CREATE OR REPLACE FUNCTION unlink_deleted_concept()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE generated_contents
+    SET concept_ids = COALESCE((
+        SELECT jsonb_agg(e) FROM jsonb_array_elements(concept_ids) e WHERE e <> to_jsonb(OLD.id)
+    ), '[]'::jsonb)
+    WHERE concept_ids @> to_jsonb(ARRAY[OLD.id]);
+
+    UPDATE glossary_terms
+    SET concept_ids = COALESCE((
+        SELECT jsonb_agg(e) FROM jsonb_array_elements(concept_ids) e WHERE e <> to_jsonb(OLD.id)
+    ), '[]'::jsonb)
+    WHERE concept_ids @> to_jsonb(ARRAY[OLD.id]);
+
+    RETURN OLD;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS unlink_deleted_concept ON concepts;
+CREATE TRIGGER unlink_deleted_concept AFTER DELETE ON concepts
+    FOR EACH ROW EXECUTE FUNCTION unlink_deleted_concept();
+
+-- Remove links to concepts deleted before the trigger existed
+UPDATE generated_contents g
+SET concept_ids = COALESCE((
+    SELECT jsonb_agg(e) FROM jsonb_array_elements(g.concept_ids) e
+    WHERE EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+), '[]'::jsonb)
+WHERE EXISTS (
+    SELECT 1 FROM jsonb_array_elements(g.concept_ids) e
+    WHERE NOT EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+);
+
+UPDATE glossary_terms g
+SET concept_ids = COALESCE((
+    SELECT jsonb_agg(e) FROM jsonb_array_elements(g.concept_ids) e
+    WHERE EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+), '[]'::jsonb)
+WHERE EXISTS (
+    SELECT 1 FROM jsonb_array_elements(g.concept_ids) e
+    WHERE NOT EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e)
+);
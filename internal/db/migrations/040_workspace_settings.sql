@@ -0,0 +1,9 @@
+-- Workspace settings
+-- Overrides of the environment's configuration, changed through the API. A
+-- single row; settings missing from overrides use the environment's values.
+
+CREATE TABLE IF NOT EXISTS workspace_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    overrides JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Review algorithms
+-- Memory state kept by the FSRS scheduler; NULL until a concept is scheduled by it
+
+ALTER TABLE learning_progress ADD COLUMN IF NOT EXISTS stability DOUBLE PRECISION;
+ALTER TABLE learning_progress ADD COLUMN IF NOT EXISTS difficulty DOUBLE PRECISION;
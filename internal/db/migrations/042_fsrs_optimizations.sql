@@ -0,0 +1,14 @@
+-- FSRS optimizations
+-- Weights fitted to the workspace's review history, kept so each run can be
+-- compared with the last and the job can tell when enough new reviews exist
+
+CREATE TABLE IF NOT EXISTS fsrs_optimizations (
+    id SERIAL PRIMARY KEY,
+    reviews INTEGER NOT NULL,
+    concepts INTEGER NOT NULL,
+    log_loss_before DOUBLE PRECISION NOT NULL,
+    log_loss_after DOUBLE PRECISION NOT NULL,
+    weights JSONB NOT NULL,
+    applied BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
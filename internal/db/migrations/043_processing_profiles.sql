@@ -0,0 +1,17 @@
+-- Processing profiles
+-- Named presets for a content genre that adjust the pipeline a source runs,
+-- and the profile each source was submitted with
+
+CREATE TABLE IF NOT EXISTS processing_profiles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    pipeline_id INTEGER REFERENCES pipeline_definitions(id) ON DELETE SET NULL,
+    concepts JSONB,
+    platforms JSONB NOT NULL DEFAULT '[]',
+    instructions JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS profile VARCHAR(100);
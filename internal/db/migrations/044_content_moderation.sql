@@ -0,0 +1,9 @@
+-- Content moderation
+-- Generated content is approved before it's published, and keeps the findings
+-- of its latest moderation check
+
+ALTER TABLE generated_contents DROP CONSTRAINT IF EXISTS generated_contents_status_check;
+ALTER TABLE generated_contents ADD CONSTRAINT generated_contents_status_check
+    CHECK (status IN ('draft', 'approved', 'published'));
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS moderation JSONB;
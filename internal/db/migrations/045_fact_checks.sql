@@ -0,0 +1,5 @@
+-- Fact checks
+-- Generated content keeps the result of checking its claims against the
+-- source transcript
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS fact_check JSONB;
@@ -0,0 +1,5 @@
+-- Content citations
+-- Generated content records which concept, and where in its source, each
+-- sentence draws on
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS citations JSONB NOT NULL DEFAULT '[]';
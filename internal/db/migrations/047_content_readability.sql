@@ -0,0 +1,4 @@
+-- Content readability
+-- Reading time, grade level, and passive voice scores of generated content
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS readability JSONB;
@@ -0,0 +1,19 @@
+-- Content series
+-- Multi-week series of posts planned from a tag's concepts, and the date each
+-- generated post is scheduled for on the content calendar
+
+CREATE TABLE IF NOT EXISTS content_series (
+    id SERIAL PRIMARY KEY,
+    tag VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    weeks INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES content_series(id) ON DELETE SET NULL;
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS series_position INTEGER;
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_generated_contents_series ON generated_contents(series_id);
+CREATE INDEX IF NOT EXISTS idx_generated_contents_scheduled ON generated_contents(scheduled_for) WHERE scheduled_for IS NOT NULL;
@@ -0,0 +1,29 @@
+-- Content recycling
+-- Imported engagement metrics on generated content, suggestions to recycle
+-- high-performing or evergreen published posts, and the post each refreshed
+-- variant was recycled from
+
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS engagement JSONB;
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS recycled_from_id INTEGER REFERENCES generated_contents(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_generated_contents_recycled_from ON generated_contents(recycled_from_id);
+
+CREATE TABLE IF NOT EXISTS recycle_suggestions (
+    id SERIAL PRIMARY KEY,
+    generated_content_id INTEGER NOT NULL UNIQUE REFERENCES generated_contents(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('high_performing', 'evergreen')),
+    engagement_rate DOUBLE PRECISION, -- Interactions per impression, for high_performing
+    dismissed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_event_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested'));
+
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS notification_preferences_event_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested'));
+
+INSERT INTO notification_preferences (event_type) VALUES ('recycle_suggested')
+ON CONFLICT (event_type) DO NOTHING;
@@ -0,0 +1,23 @@
+-- Pending transcriptions
+-- Sources transcribed out-of-band by AssemblyAI, Deepgram, or Rev, which are
+-- processed when the provider's callback arrives, stored with type 'recording'
+
+CREATE TABLE IF NOT EXISTS pending_transcriptions (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE, -- Secret in the callback URL
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('assemblyai', 'deepgram', 'rev')),
+    title TEXT NOT NULL,
+    credential VARCHAR(100) NOT NULL DEFAULT '',
+    spec JSONB NOT NULL,
+    scrub BOOLEAN,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    job_id VARCHAR(255),
+    error TEXT,
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ
+);
+
+ALTER TABLE source_contents DROP CONSTRAINT IF EXISTS source_contents_type_check;
+ALTER TABLE source_contents ADD CONSTRAINT source_contents_type_check
+    CHECK (type IN ('youtube', 'pdf', 'article', 'meeting', 'recording'));
@@ -0,0 +1,6 @@
+-- Transcript timing
+-- Word timestamps and chapters returned by AssemblyAI and Deepgram, for
+-- timing transcript segments and citations
+
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS transcript_words JSONB;
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS chapters JSONB;
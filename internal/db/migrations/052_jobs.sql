@@ -0,0 +1,19 @@
+-- Background jobs
+-- POST /api/source-content queues its pipeline here and returns at once; a
+-- worker pool claims queued jobs, and GET /api/jobs/:id reports their progress
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+    request JSONB NOT NULL,
+    spec JSONB NOT NULL,
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE SET NULL,
+    warnings JSONB NOT NULL DEFAULT '[]',
+    redactions JSONB,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queued ON jobs(id) WHERE status = 'queued';
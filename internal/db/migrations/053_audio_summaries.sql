@@ -0,0 +1,14 @@
+-- Audio summaries
+-- A source's summary and concepts read aloud by a text-to-speech provider, one
+-- per source; the MP3 is in object storage and listed in the private podcast feed
+
+CREATE TABLE IF NOT EXISTS audio_summaries (
+    source_content_id INTEGER PRIMARY KEY REFERENCES source_contents(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    voice VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audio_summaries_created_at ON audio_summaries(created_at DESC);
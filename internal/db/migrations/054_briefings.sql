@@ -0,0 +1,28 @@
+-- Daily briefings
+-- One per day: the concepts due for review, concepts extracted since the last
+-- briefing, and one older concept resurfaced, delivered as a daily_briefing
+-- notification with optional text-to-speech audio
+
+CREATE TABLE IF NOT EXISTS briefings (
+    id SERIAL PRIMARY KEY,
+    date DATE NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    due_count INTEGER NOT NULL DEFAULT 0,
+    due_concept_ids JSONB NOT NULL DEFAULT '[]',
+    new_concept_ids JSONB NOT NULL DEFAULT '[]',
+    resurfaced_concept_id INTEGER REFERENCES concepts(id) ON DELETE SET NULL,
+    audio_key TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_event_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested', 'daily_briefing'));
+
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS notification_preferences_event_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested', 'daily_briefing'));
+
+INSERT INTO notification_preferences (event_type) VALUES ('daily_briefing')
+ON CONFLICT (event_type) DO NOTHING;
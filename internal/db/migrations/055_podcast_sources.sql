@@ -0,0 +1,7 @@
+-- Podcast sources
+-- Episodes imported from podcast feeds or audio URLs and transcribed, stored
+-- with type 'podcast' and their audio URL
+
+ALTER TABLE source_contents DROP CONSTRAINT IF EXISTS source_contents_type_check;
+ALTER TABLE source_contents ADD CONSTRAINT source_contents_type_check
+    CHECK (type IN ('youtube', 'pdf', 'article', 'meeting', 'recording', 'podcast'));
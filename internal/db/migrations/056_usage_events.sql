@@ -0,0 +1,17 @@
+-- Usage events
+-- Metered usage (sources processed, Claude tokens, posts published) per
+-- tenant, kept for aggregation and reported to the billing sink in the
+-- background
+
+CREATE TABLE IF NOT EXISTS usage_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL,
+    metric VARCHAR(30) NOT NULL CHECK (metric IN ('sources_processed', 'claude_tokens', 'posts_published')),
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    source_content_id INTEGER REFERENCES source_contents(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reported_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_events_tenant_created ON usage_events(tenant, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_events_unreported ON usage_events(id) WHERE reported_at IS NULL;
@@ -0,0 +1,12 @@
+-- LLM usage
+-- Claude requests and tokens per UTC day and model, so the admin API can
+-- estimate spend at each model's price
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+    day DATE NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, model)
+);
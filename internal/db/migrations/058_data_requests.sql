@@ -0,0 +1,30 @@
+-- Data requests
+-- A user's requests to export all their data or delete their account, carried
+-- out in the background and reported with a data_request_complete notification
+
+CREATE TABLE IF NOT EXISTS data_requests (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL, -- Cleared once a deletion removes the user
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('export', 'delete')),
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+    export_key TEXT, -- Object store key of a finished export's archive
+    result JSONB, -- Rows and files exported or deleted, by table
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_requests_user_id ON data_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_data_requests_queued ON data_requests(id) WHERE status = 'queued';
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_event_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested', 'daily_briefing', 'data_request_complete'));
+
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS notification_preferences_event_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_event_type_check
+    CHECK (event_type IN ('pipeline_complete', 'review_due', 'publish_succeeded', 'publish_failed', 'recycle_suggested', 'daily_briefing', 'data_request_complete'));
+
+INSERT INTO notification_preferences (event_type) VALUES ('data_request_complete')
+ON CONFLICT (event_type) DO NOTHING;
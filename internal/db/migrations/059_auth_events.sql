@@ -0,0 +1,23 @@
+-- Authentication events
+-- IP allowlists for API tokens, and a log of sign-ins, token use, and rejected
+-- credentials for admins to audit
+
+ALTER TABLE api_tokens ADD COLUMN IF NOT EXISTS allowed_ips JSONB NOT NULL DEFAULT '[]'; -- CIDR ranges; any address when empty
+
+CREATE TABLE IF NOT EXISTS auth_events (
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(20) NOT NULL CHECK (event IN ('login', 'login_failed', 'logout', 'token_used', 'auth_failed')),
+    success BOOLEAN NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    api_token_id INTEGER REFERENCES api_tokens(id) ON DELETE SET NULL,
+    provider VARCHAR(50), -- Login provider, for logins
+    ip INET,
+    user_agent TEXT,
+    route TEXT, -- Method and route pattern the credentials were presented to
+    reason TEXT, -- Why a login or credential was rejected
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_events_created ON auth_events(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_auth_events_user_id ON auth_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_auth_events_api_token_id ON auth_events(api_token_id, created_at);
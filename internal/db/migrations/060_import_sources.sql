@@ -0,0 +1,7 @@
+-- Imported decks
+-- Flashcards imported from Anki, Logseq, or RemNote are stored as a source
+-- with type 'import', its URL identifying the imported file
+
+ALTER TABLE source_contents DROP CONSTRAINT IF EXISTS source_contents_type_check;
+ALTER TABLE source_contents ADD CONSTRAINT source_contents_type_check
+    CHECK (type IN ('youtube', 'pdf', 'article', 'meeting', 'recording', 'podcast', 'import'));
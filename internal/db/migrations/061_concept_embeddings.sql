@@ -0,0 +1,15 @@
+-- Concept embeddings
+-- Vector embeddings of each concept's title and description, for semantic
+-- search with pgvector
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS concept_embeddings (
+    concept_id INTEGER PRIMARY KEY REFERENCES concepts(id) ON DELETE CASCADE,
+    model VARCHAR(100) NOT NULL, -- Embeddings from other models aren't comparable
+    content_hash CHAR(32) NOT NULL, -- md5 of the embedded text, so edited concepts are embedded again
+    embedding vector(768) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_concept_embeddings_embedding ON concept_embeddings USING hnsw (embedding vector_cosine_ops);
@@ -0,0 +1,28 @@
+-- Concept relationship types
+-- Edges of the concept graph are prerequisite (from must be learned before
+-- to), related, or contradicts. Two concepts share at most one edge, in
+-- either direction.
+
+UPDATE concept_relationships SET relationship_type = 'related'
+WHERE relationship_type IS NULL OR relationship_type NOT IN ('prerequisite', 'related', 'contradicts');
+
+DELETE FROM concept_relationships WHERE from_concept_id = to_concept_id;
+
+DELETE FROM concept_relationships a
+USING concept_relationships b
+WHERE a.from_concept_id = b.to_concept_id AND a.to_concept_id = b.from_concept_id AND a.id > b.id;
+
+ALTER TABLE concept_relationships ALTER COLUMN relationship_type SET NOT NULL;
+
+ALTER TABLE concept_relationships DROP CONSTRAINT IF EXISTS concept_relationships_type_check;
+ALTER TABLE concept_relationships ADD CONSTRAINT concept_relationships_type_check
+    CHECK (relationship_type IN ('prerequisite', 'related', 'contradicts'));
+
+ALTER TABLE concept_relationships DROP CONSTRAINT IF EXISTS concept_relationships_distinct_check;
+ALTER TABLE concept_relationships ADD CONSTRAINT concept_relationships_distinct_check
+    CHECK (from_concept_id <> to_concept_id);
+
+ALTER TABLE concept_relationships ADD COLUMN IF NOT EXISTS rationale TEXT; -- Why the concepts are related, as suggested or entered
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_concept_relationships_pair
+    ON concept_relationships (LEAST(from_concept_id, to_concept_id), GREATEST(from_concept_id, to_concept_id));
@@ -0,0 +1,19 @@
+-- Smart collections
+-- Named concept queries, kept as their query text so a collection always
+-- holds the concepts matching it now. Review sessions and content series can
+-- draw from a collection.
+
+CREATE TABLE IF NOT EXISTS collections (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    query TEXT NOT NULL, -- In the POST /api/query language
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS collection_id INTEGER REFERENCES collections(id) ON DELETE SET NULL;
+
+-- A series is planned from a tag or a collection
+ALTER TABLE content_series ALTER COLUMN tag DROP NOT NULL;
+ALTER TABLE content_series ADD COLUMN IF NOT EXISTS collection_id INTEGER REFERENCES collections(id) ON DELETE SET NULL;
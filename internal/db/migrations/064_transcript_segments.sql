@@ -0,0 +1,14 @@
+-- Transcript segments
+-- The timed cues of the caption track a YouTube transcript was built from,
+-- for timing transcript segments and citations of sources without word
+-- timestamps
+
+CREATE TABLE IF NOT EXISTS transcript_segments (
+    id SERIAL PRIMARY KEY,
+    source_content_id INTEGER NOT NULL REFERENCES source_contents(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL, -- Order in the caption track, from 0
+    start_seconds DOUBLE PRECISION NOT NULL,
+    end_seconds DOUBLE PRECISION NOT NULL,
+    text TEXT NOT NULL, -- Sealed like transcripts when encryption at rest is on
+    UNIQUE (source_content_id, position)
+);
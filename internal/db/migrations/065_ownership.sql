@@ -0,0 +1,67 @@
+-- Content ownership
+-- Sources, concepts, quizzes, and generated content belong to the user who
+-- created them, and only they and admins see them. Rows without an owner,
+-- those from before accounts and those created with API tokens, stay shared
+-- with the whole workspace. Deleting a user deletes what they own.
+
+ALTER TABLE source_contents ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE concepts ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE quiz_questions ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE generated_contents ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+-- Queued work remembers who the source it saves will belong to
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE pending_transcriptions ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_source_contents_owner_id ON source_contents(owner_id);
+CREATE INDEX IF NOT EXISTS idx_concepts_owner_id ON concepts(owner_id);
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_owner_id ON quiz_questions(owner_id);
+CREATE INDEX IF NOT EXISTS idx_generated_contents_owner_id ON generated_contents(owner_id);
+
+-- Concepts belong to their source's owner, quizzes to their concept's, and
+-- generated content to its first concept's, or to what it was recycled from,
+-- unless the insert names an owner
+CREATE OR REPLACE FUNCTION inherit_concept_owner()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.owner_id IS NULL AND NEW.source_content_id IS NOT NULL THEN
+        NEW.owner_id := (SELECT owner_id FROM source_contents WHERE id = NEW.source_content_id);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS inherit_concept_owner ON concepts;
+CREATE TRIGGER inherit_concept_owner BEFORE INSERT ON concepts
+    FOR EACH ROW EXECUTE FUNCTION inherit_concept_owner();
+
+CREATE OR REPLACE FUNCTION inherit_quiz_owner()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.owner_id IS NULL THEN
+        NEW.owner_id := (SELECT owner_id FROM concepts WHERE id = NEW.concept_id);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS inherit_quiz_owner ON quiz_questions;
+CREATE TRIGGER inherit_quiz_owner BEFORE INSERT ON quiz_questions
+    FOR EACH ROW EXECUTE FUNCTION inherit_quiz_owner();
+
+CREATE OR REPLACE FUNCTION inherit_generated_content_owner()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.owner_id IS NULL AND NEW.recycled_from_id IS NOT NULL THEN
+        NEW.owner_id := (SELECT owner_id FROM generated_contents WHERE id = NEW.recycled_from_id);
+    END IF;
+    IF NEW.owner_id IS NULL AND jsonb_typeof(NEW.concept_ids) = 'array' AND jsonb_array_length(NEW.concept_ids) > 0 THEN
+        NEW.owner_id := (SELECT owner_id FROM concepts WHERE id = (NEW.concept_ids->>0)::int);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS inherit_generated_content_owner ON generated_contents;
+CREATE TRIGGER inherit_generated_content_owner BEFORE INSERT ON generated_contents
+    FOR EACH ROW EXECUTE FUNCTION inherit_generated_content_owner();
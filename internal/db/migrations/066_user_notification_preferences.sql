@@ -0,0 +1,29 @@
+-- Per-user notification preferences
+-- Preferences were the workspace's, one row per event. They're now kept per
+-- user and event; the workspace's become the defaults for events a user
+-- hasn't set, and decide notifications meant for no one account. Each
+-- notification may be addressed to a user, and only they see it.
+
+ALTER TABLE notification_preferences RENAME TO workspace_notification_preferences;
+ALTER INDEX notification_preferences_pkey RENAME TO workspace_notification_preferences_pkey;
+ALTER TABLE workspace_notification_preferences RENAME CONSTRAINT notification_preferences_event_type_check TO workspace_notification_preferences_event_type_check;
+ALTER TRIGGER update_notification_preferences_updated_at ON workspace_notification_preferences RENAME TO update_workspace_notification_preferences_updated_at;
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL REFERENCES workspace_notification_preferences(event_type) ON DELETE CASCADE,
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    webhook BOOLEAN NOT NULL,
+    push BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, event_type)
+);
+
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- NULL for the workspace's notifications, which everyone sees
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
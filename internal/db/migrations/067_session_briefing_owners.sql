@@ -0,0 +1,23 @@
+-- Quiz session and briefing ownership
+-- A quiz session belongs to the user who started it, and a briefing to the
+-- user it was written for; only they and admins see it. Sessions without an
+-- owner are shared, as other content is. Briefings without one are the
+-- workspace's, written from every concept, so only admins see them.
+
+ALTER TABLE quiz_sessions ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+-- Sessions started before belong to their concept's or source's owner
+UPDATE quiz_sessions qs
+SET owner_id = COALESCE(
+    (SELECT owner_id FROM concepts WHERE id = qs.concept_id),
+    (SELECT owner_id FROM source_contents WHERE id = qs.source_content_id)
+)
+WHERE owner_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_sessions_owner_id ON quiz_sessions(owner_id);
+
+ALTER TABLE briefings ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+-- One briefing a day for the workspace, and one for each user
+ALTER TABLE briefings DROP CONSTRAINT IF EXISTS briefings_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_briefings_date_owner ON briefings(date, COALESCE(owner_id, 0));
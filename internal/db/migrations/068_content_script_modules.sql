@@ -0,0 +1,5 @@
+-- WebAssembly content scripts
+-- A content script may be a WASI module instead of steps, run sandboxed with
+-- memory and time limits
+
+ALTER TABLE content_scripts ADD COLUMN IF NOT EXISTS wasm BYTEA; -- NULL for a script of steps
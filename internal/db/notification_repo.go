@@ -0,0 +1,430 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CreateNotification creates a new in-app notification
+func CreateNotification(n *models.Notification) (*models.Notification, error) {
+	query := `
+		INSERT INTO notifications (user_id, event_type, title, body, data)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, event_type, title, body, data, read_at, created_at
+	`
+
+	var created models.Notification
+	err := DB.QueryRow(
+		query,
+		n.UserID,
+		n.EventType,
+		n.Title,
+		n.Body,
+		n.Data,
+	).Scan(
+		&created.ID,
+		&created.UserID,
+		&created.EventType,
+		&created.Title,
+		&created.Body,
+		&created.Data,
+		&created.ReadAt,
+		&created.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetNotifications retrieves a page of the notifications user sees, newest
+// first, optionally only unread ones: their own and the workspace's, or every
+// notification when user is nil. The returned cursor is nil on the last page.
+func GetNotifications(user *int, unreadOnly bool, page models.Page) ([]models.Notification, *models.Cursor, error) {
+	query := `
+		SELECT id, user_id, event_type, title, body, data, read_at, created_at
+		FROM notifications
+		WHERE (NOT $1 OR read_at IS NULL)
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+			AND ` + visibleTo("user_id", 5) + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	rows, err := DB.Query(query, unreadOnly, afterTime, afterID, page.Limit+1, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.EventType,
+			&n.Title,
+			&n.Body,
+			&n.Data,
+			&n.ReadAt,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(notifications) > page.Limit {
+		notifications = notifications[:page.Limit]
+		last := notifications[len(notifications)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return notifications, next, nil
+}
+
+// CountUnreadNotifications returns the number of unread notifications user
+// sees, as GetNotifications lists them
+func CountUnreadNotifications(user *int) (int, error) {
+	var count int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE read_at IS NULL AND `+visibleTo("user_id", 1), user).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks a single notification user sees as read
+func MarkNotificationRead(id int, user *int) (*models.Notification, error) {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND ` + visibleTo("user_id", 2) + `
+		RETURNING id, user_id, event_type, title, body, data, read_at, created_at
+	`
+
+	var n models.Notification
+	err := DB.QueryRow(query, id, user).Scan(
+		&n.ID,
+		&n.UserID,
+		&n.EventType,
+		&n.Title,
+		&n.Body,
+		&n.Data,
+		&n.ReadAt,
+		&n.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	return &n, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification user sees as read
+// and returns how many changed
+func MarkAllNotificationsRead(user *int) (int64, error) {
+	result, err := DB.Exec(`UPDATE notifications SET read_at = NOW() WHERE read_at IS NULL AND `+visibleTo("user_id", 1), user)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// notificationPreferenceColumns selects, from the workspace's preferences w
+// and a user's p, the user's where they're set and the workspace's otherwise
+const notificationPreferenceColumns = `w.event_type, COALESCE(p.in_app, w.in_app), COALESCE(p.email, w.email),
+	COALESCE(p.webhook, w.webhook), COALESCE(p.push, w.push), COALESCE(p.updated_at, w.updated_at)`
+
+// scanNotificationPreference scans a row selected with notificationPreferenceColumns
+func scanNotificationPreference(row rowScanner, p *models.NotificationPreference) error {
+	return row.Scan(&p.EventType, &p.InApp, &p.Email, &p.Webhook, &p.Push, &p.UpdatedAt)
+}
+
+// GetNotificationPreferences retrieves a user's delivery preferences for
+// every event type, or the workspace's when user is nil
+func GetNotificationPreferences(user *int) ([]models.NotificationPreference, error) {
+	query := `
+		SELECT ` + notificationPreferenceColumns + `
+		FROM workspace_notification_preferences w
+		LEFT JOIN notification_preferences p ON p.event_type = w.event_type AND p.user_id = $1
+		ORDER BY w.event_type ASC
+	`
+
+	rows, err := DB.Query(query, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []models.NotificationPreference
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := scanNotificationPreference(rows, &p); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs = append(prefs, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// GetNotificationPreference retrieves a user's delivery preferences for a
+// single event type, or the workspace's when user is nil
+func GetNotificationPreference(eventType string, user *int) (*models.NotificationPreference, error) {
+	query := `
+		SELECT ` + notificationPreferenceColumns + `
+		FROM workspace_notification_preferences w
+		LEFT JOIN notification_preferences p ON p.event_type = w.event_type AND p.user_id = $2
+		WHERE w.event_type = $1
+	`
+
+	var p models.NotificationPreference
+	err := scanNotificationPreference(DB.QueryRow(query, eventType, user), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification preference not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preference: %w", err)
+	}
+
+	return &p, nil
+}
+
+// UpdateNotificationPreference updates a user's delivery preferences for an
+// event type, starting from the workspace's, or the workspace's when user is
+// nil
+func UpdateNotificationPreference(eventType string, user *int, req models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	if user == nil {
+		return updateWorkspaceNotificationPreference(eventType, req)
+	}
+
+	query := `
+		INSERT INTO notification_preferences (user_id, event_type, in_app, email, webhook, push)
+		SELECT $1, w.event_type, COALESCE($3, w.in_app), COALESCE($4, w.email), COALESCE($5, w.webhook), COALESCE($6, w.push)
+		FROM workspace_notification_preferences w
+		WHERE w.event_type = $2
+		ON CONFLICT (user_id, event_type) DO UPDATE SET
+			in_app = COALESCE($3, notification_preferences.in_app),
+			email = COALESCE($4, notification_preferences.email),
+			webhook = COALESCE($5, notification_preferences.webhook),
+			push = COALESCE($6, notification_preferences.push),
+			updated_at = NOW()
+		RETURNING event_type, in_app, email, webhook, push, updated_at
+	`
+
+	var p models.NotificationPreference
+	err := DB.QueryRow(query, *user, eventType, req.InApp, req.Email, req.Webhook, req.Push).Scan(&p.EventType, &p.InApp, &p.Email, &p.Webhook, &p.Push, &p.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification preference not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preference: %w", err)
+	}
+
+	return &p, nil
+}
+
+// updateWorkspaceNotificationPreference updates the workspace's delivery
+// preferences for an event type
+func updateWorkspaceNotificationPreference(eventType string, req models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	// Build dynamic update query
+	query := "UPDATE workspace_notification_preferences SET "
+	args := []interface{}{}
+	argCount := 1
+
+	if req.InApp != nil {
+		query += fmt.Sprintf("in_app = $%d, ", argCount)
+		args = append(args, *req.InApp)
+		argCount++
+	}
+
+	if req.Email != nil {
+		query += fmt.Sprintf("email = $%d, ", argCount)
+		args = append(args, *req.Email)
+		argCount++
+	}
+
+	if req.Webhook != nil {
+		query += fmt.Sprintf("webhook = $%d, ", argCount)
+		args = append(args, *req.Webhook)
+		argCount++
+	}
+
+	if req.Push != nil {
+		query += fmt.Sprintf("push = $%d, ", argCount)
+		args = append(args, *req.Push)
+		argCount++
+	}
+
+	// Always update updated_at
+	query += fmt.Sprintf("updated_at = NOW() WHERE event_type = $%d ", argCount)
+	args = append(args, eventType)
+
+	query += "RETURNING event_type, in_app, email, webhook, push, updated_at"
+
+	var p models.NotificationPreference
+	err := DB.QueryRow(query, args...).Scan(&p.EventType, &p.InApp, &p.Email, &p.Webhook, &p.Push, &p.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification preference not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preference: %w", err)
+	}
+
+	return &p, nil
+}
+
+// pushSubscriptionColumns is the column list scanned by scanPushSubscription
+const pushSubscriptionColumns = "id, provider, endpoint, token, name, last_delivered_at, last_error, created_at"
+
+// scanPushSubscription scans a row selected with pushSubscriptionColumns
+func scanPushSubscription(row rowScanner, p *models.PushSubscription) error {
+	return row.Scan(
+		&p.ID,
+		&p.Provider,
+		&p.Endpoint,
+		&p.Token,
+		&p.Name,
+		&p.LastDeliveredAt,
+		&p.LastError,
+		&p.CreatedAt,
+	)
+}
+
+// CreatePushSubscription creates a new push subscription
+func CreatePushSubscription(req models.CreatePushSubscriptionRequest) (*models.PushSubscription, error) {
+	query := `
+		INSERT INTO push_subscriptions (provider, endpoint, token, name)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + pushSubscriptionColumns
+
+	var p models.PushSubscription
+	err := scanPushSubscription(DB.QueryRow(query, req.Provider, req.Endpoint, req.Token, req.Name), &p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push subscription: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetPushSubscriptions retrieves all push subscriptions, oldest first
+func GetPushSubscriptions() ([]models.PushSubscription, error) {
+	query := `
+		SELECT ` + pushSubscriptionColumns + `
+		FROM push_subscriptions
+		ORDER BY created_at ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.PushSubscription{}
+	for rows.Next() {
+		var p models.PushSubscription
+		if err := scanPushSubscription(rows, &p); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// GetPushSubscriptionByID retrieves a single push subscription by ID
+func GetPushSubscriptionByID(id int) (*models.PushSubscription, error) {
+	query := `
+		SELECT ` + pushSubscriptionColumns + `
+		FROM push_subscriptions
+		WHERE id = $1
+	`
+
+	var p models.PushSubscription
+	err := scanPushSubscription(DB.QueryRow(query, id), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("push subscription not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push subscription: %w", err)
+	}
+
+	return &p, nil
+}
+
+// RecordPushDelivery stores the outcome of the latest delivery to a subscription.
+// A nil deliveryErr marks a success and clears any previous error.
+func RecordPushDelivery(id int, deliveryErr error) error {
+	var query string
+	var args []interface{}
+	if deliveryErr == nil {
+		query = "UPDATE push_subscriptions SET last_delivered_at = NOW(), last_error = NULL WHERE id = $1"
+		args = []interface{}{id}
+	} else {
+		query = "UPDATE push_subscriptions SET last_error = $2 WHERE id = $1"
+		args = []interface{}{id, deliveryErr.Error()}
+	}
+
+	if _, err := DB.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to record push delivery: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePushSubscription deletes a push subscription by ID
+func DeletePushSubscription(id int) error {
+	query := "DELETE FROM push_subscriptions WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("push subscription not found")
+	}
+
+	return nil
+}
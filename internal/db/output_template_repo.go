@@ -0,0 +1,145 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// outputTemplateColumns is the column list scanned by scanOutputTemplate
+const outputTemplateColumns = "id, name, body, content_type, created_at, updated_at"
+
+// scanOutputTemplate scans a row selected with outputTemplateColumns
+func scanOutputTemplate(row rowScanner, t *models.OutputTemplate) error {
+	return row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Body,
+		&t.ContentType,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+}
+
+// CreateOutputTemplate stores a new output template
+func CreateOutputTemplate(req models.OutputTemplateRequest) (*models.OutputTemplate, error) {
+	query := `
+		INSERT INTO output_templates (name, body, content_type)
+		VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'text/plain'))
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + outputTemplateColumns
+
+	var t models.OutputTemplate
+	err := scanOutputTemplate(DB.QueryRow(query, req.Name, req.Body, req.ContentType), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output template: %w", err)
+	}
+
+	return &t, nil
+}
+
+// GetOutputTemplates retrieves all output templates by name
+func GetOutputTemplates() ([]models.OutputTemplate, error) {
+	query := `
+		SELECT ` + outputTemplateColumns + `
+		FROM output_templates
+		ORDER BY name ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query output templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.OutputTemplate{}
+	for rows.Next() {
+		var t models.OutputTemplate
+		if err := scanOutputTemplate(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan output template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating output templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetOutputTemplateByName retrieves an output template by name
+func GetOutputTemplateByName(name string) (*models.OutputTemplate, error) {
+	query := `
+		SELECT ` + outputTemplateColumns + `
+		FROM output_templates
+		WHERE name = $1
+	`
+
+	var t models.OutputTemplate
+	err := scanOutputTemplate(DB.QueryRow(query, name), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, a built-in template may apply
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query output template: %w", err)
+	}
+
+	return &t, nil
+}
+
+// UpdateOutputTemplate replaces an output template's name, body, and content type
+func UpdateOutputTemplate(id int, req models.OutputTemplateRequest) (*models.OutputTemplate, error) {
+	query := `
+		UPDATE output_templates
+		SET name = $2, body = $3, content_type = COALESCE(NULLIF($4, ''), 'text/plain'), updated_at = NOW()
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM output_templates o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + outputTemplateColumns
+
+	var t models.OutputTemplate
+	err := scanOutputTemplate(DB.QueryRow(query, id, req.Name, req.Body, req.ContentType), &t)
+
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM output_templates WHERE id = $1)", id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to query output template: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("template not found")
+		}
+		return nil, fmt.Errorf("template name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update output template: %w", err)
+	}
+
+	return &t, nil
+}
+
+// DeleteOutputTemplate deletes an output template by ID
+func DeleteOutputTemplate(id int) error {
+	query := "DELETE FROM output_templates WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete output template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("template not found")
+	}
+
+	return nil
+}
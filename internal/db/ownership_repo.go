@@ -0,0 +1,89 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ownedTables are the tables whose rows have an owner_id
+var ownedTables = map[string]bool{
+	"source_contents":    true,
+	"concepts":           true,
+	"quiz_questions":     true,
+	"generated_contents": true,
+	"jobs":               true,
+	"quiz_sessions":      true,
+}
+
+// derivedOwners select, for tables without an owner_id, the owner of the row
+// with ID $1: an action item's is its source's, and a relationship's is that
+// of whichever of its concepts has one
+var derivedOwners = map[string]string{
+	"action_items": `
+		SELECT s.owner_id FROM action_items a
+		JOIN source_contents s ON s.id = a.source_content_id
+		WHERE a.id = $1`,
+	"concept_relationships": `
+		SELECT COALESCE(f.owner_id, t.owner_id) FROM concept_relationships r
+		JOIN concepts f ON f.id = r.from_concept_id
+		JOIN concepts t ON t.id = r.to_concept_id
+		WHERE r.id = $1`,
+}
+
+// ownerClause returns the condition keeping the rows owner can see, those
+// whose column is owner or NULL, appending its argument to args. A nil owner
+// sees every row.
+func ownerClause(column string, owner *int, args []interface{}) (string, []interface{}) {
+	if owner == nil {
+		return "", args
+	}
+	args = append(args, *owner)
+	return fmt.Sprintf(" AND (%s IS NULL OR %s = $%d)", column, column, len(args)), args
+}
+
+// visibleTo returns the condition, for queries with fixed arguments, keeping
+// the rows the owner in argument n can see; a NULL owner sees every row
+func visibleTo(column string, n int) string {
+	return fmt.Sprintf("($%d::int IS NULL OR %s IS NULL OR %s = $%d)", n, column, column, n)
+}
+
+// GetOwner retrieves the owner of the row of table with id, nil when the row
+// is shared with the workspace. found is false when there's no such row.
+func GetOwner(table string, id int) (owner *int, found bool, err error) {
+	query, derived := derivedOwners[table]
+	if !derived {
+		if !ownedTables[table] {
+			return nil, false, fmt.Errorf("%s rows have no owner", table)
+		}
+		query = fmt.Sprintf("SELECT owner_id FROM %s WHERE id = $1", table)
+	}
+
+	err = DB.QueryRow(query, id).Scan(&owner)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query %s owner: %w", table, err)
+	}
+
+	return owner, true, nil
+}
+
+// CountHidden counts the rows of table with one of ids that owner can't see.
+// Missing rows aren't counted; nil owner sees every row.
+func CountHidden(table string, ids []int, owner *int) (int, error) {
+	if !ownedTables[table] {
+		return 0, fmt.Errorf("%s rows have no owner", table)
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id = ANY($1) AND NOT %s", table, visibleTo("owner_id", 2))
+
+	var hidden int
+	if err := DB.QueryRow(query, pq.Array(ids), owner).Scan(&hidden); err != nil {
+		return 0, fmt.Errorf("failed to check %s owners: %w", table, err)
+	}
+
+	return hidden, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// cursorArgs returns the (created_at, id) query arguments for a page, both nil
+// on the first page. Queries compare with
+// ($n::timestamptz IS NULL OR (created_at, id) < ($n, $n+1)).
+func cursorArgs(page models.Page) (interface{}, interface{}) {
+	if page.After == nil {
+		return nil, nil
+	}
+	return page.After.CreatedAt, page.After.ID
+}
+
+// pageClause returns the keyset condition, ordering, and limit that select
+// page from rows sorted by column, a non-null timestamp, then by idColumn,
+// appending their arguments to args. Rows are newest first unless ascending.
+// One row past the page is fetched, to tell whether another page follows.
+func pageClause(column, idColumn string, ascending bool, page models.Page, args []interface{}) (string, []interface{}) {
+	cmp, dir := "<", "DESC"
+	if ascending {
+		cmp, dir = ">", "ASC"
+	}
+
+	afterTime, afterID := cursorArgs(page)
+	args = append(args, afterTime, afterID, page.Limit+1)
+	n := len(args)
+
+	clause := fmt.Sprintf(" AND ($%d::timestamptz IS NULL OR (%s, %s) %s ($%d, $%d))", n-2, column, idColumn, cmp, n-2, n-1)
+	clause += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT $%d", column, dir, idColumn, dir, n)
+	return clause, args
+}
+
+// dateRangeClause returns the condition keeping rows whose column falls from
+// the start of from through the end of to, dates in UTC, appending its
+// arguments to args. Either bound may be nil.
+func dateRangeClause(column string, from, to *time.Time, args []interface{}) (string, []interface{}) {
+	clause := ""
+	if from != nil {
+		args = append(args, *from)
+		clause += fmt.Sprintf(" AND %s >= $%d", column, len(args))
+	}
+	if to != nil {
+		args = append(args, to.AddDate(0, 0, 1))
+		clause += fmt.Sprintf(" AND %s < $%d", column, len(args))
+	}
+	return clause, args
+}
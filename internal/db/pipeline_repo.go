@@ -0,0 +1,200 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// pipelineDefinitionColumns is the column list scanned by scanPipelineDefinition
+const pipelineDefinitionColumns = "id, name, spec, is_default, created_at, updated_at"
+
+// scanPipelineDefinition scans a row selected with pipelineDefinitionColumns
+func scanPipelineDefinition(row rowScanner, p *models.PipelineDefinition) error {
+	return row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Spec,
+		&p.IsDefault,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+}
+
+// CreatePipelineDefinition stores a new pipeline definition
+func CreatePipelineDefinition(req models.PipelineDefinitionRequest) (*models.PipelineDefinition, error) {
+	query := `
+		INSERT INTO pipeline_definitions (name, spec)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + pipelineDefinitionColumns
+
+	var p models.PipelineDefinition
+	err := scanPipelineDefinition(DB.QueryRow(query, req.Name, req.Spec), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pipeline name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipeline definition: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetPipelineDefinitions retrieves all pipeline definitions, default first
+func GetPipelineDefinitions() ([]models.PipelineDefinition, error) {
+	query := `
+		SELECT ` + pipelineDefinitionColumns + `
+		FROM pipeline_definitions
+		ORDER BY is_default DESC, name ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pipeline definitions: %w", err)
+	}
+	defer rows.Close()
+
+	pipelines := []models.PipelineDefinition{}
+	for rows.Next() {
+		var p models.PipelineDefinition
+		if err := scanPipelineDefinition(rows, &p); err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline definition: %w", err)
+		}
+		pipelines = append(pipelines, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline definitions: %w", err)
+	}
+
+	return pipelines, nil
+}
+
+// GetPipelineDefinitionByID retrieves a single pipeline definition by ID
+func GetPipelineDefinitionByID(id int) (*models.PipelineDefinition, error) {
+	query := `
+		SELECT ` + pipelineDefinitionColumns + `
+		FROM pipeline_definitions
+		WHERE id = $1
+	`
+
+	var p models.PipelineDefinition
+	err := scanPipelineDefinition(DB.QueryRow(query, id), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pipeline not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pipeline definition: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetDefaultPipelineDefinition retrieves the default pipeline definition
+func GetDefaultPipelineDefinition() (*models.PipelineDefinition, error) {
+	query := `
+		SELECT ` + pipelineDefinitionColumns + `
+		FROM pipeline_definitions
+		WHERE is_default
+	`
+
+	var p models.PipelineDefinition
+	err := scanPipelineDefinition(DB.QueryRow(query), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, the built-in pipeline applies
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query default pipeline definition: %w", err)
+	}
+
+	return &p, nil
+}
+
+// UpdatePipelineDefinition replaces a pipeline definition's name and spec
+func UpdatePipelineDefinition(id int, req models.PipelineDefinitionRequest) (*models.PipelineDefinition, error) {
+	if _, err := GetPipelineDefinitionByID(id); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE pipeline_definitions
+		SET name = $2, spec = $3, updated_at = NOW()
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM pipeline_definitions o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + pipelineDefinitionColumns
+
+	var p models.PipelineDefinition
+	err := scanPipelineDefinition(DB.QueryRow(query, id, req.Name, req.Spec), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pipeline name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pipeline definition: %w", err)
+	}
+
+	return &p, nil
+}
+
+// SetDefaultPipelineDefinition makes a pipeline the default, or clears the
+// default (restoring the built-in pipeline) when id is nil
+func SetDefaultPipelineDefinition(id *int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	if _, err := tx.Exec("UPDATE pipeline_definitions SET is_default = FALSE WHERE is_default"); err != nil {
+		return fmt.Errorf("failed to clear default pipeline: %w", err)
+	}
+
+	if id != nil {
+		result, err := tx.Exec("UPDATE pipeline_definitions SET is_default = TRUE WHERE id = $1", *id)
+		if err != nil {
+			return fmt.Errorf("failed to set default pipeline: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("pipeline not found")
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePipelineDefinition deletes a pipeline definition by ID
+func DeletePipelineDefinition(id int) error {
+	query := "DELETE FROM pipeline_definitions WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pipeline definition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pipeline not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+const pipelineRunColumns = `source_content_id, spec, status, completed_stages, started_at, updated_at, finished_at`
+
+// scanPipelineRun scans a row selected with pipelineRunColumns
+func scanPipelineRun(row rowScanner, run *models.PipelineRun) error {
+	return row.Scan(
+		&run.SourceContentID,
+		&run.Spec,
+		&run.Status,
+		&run.CompletedStages,
+		&run.StartedAt,
+		&run.UpdatedAt,
+		&run.FinishedAt,
+	)
+}
+
+// StartPipelineRun marks a source's run as running spec. Resuming an earlier
+// run keeps the stages it completed.
+func StartPipelineRun(sourceContentID int, spec models.PipelineSpec) error {
+	query := `
+		INSERT INTO pipeline_runs (source_content_id, spec)
+		VALUES ($1, $2)
+		ON CONFLICT (source_content_id) DO UPDATE
+		SET spec = EXCLUDED.spec, status = 'running', updated_at = NOW(), finished_at = NULL
+	`
+
+	if _, err := DB.Exec(query, sourceContentID, spec); err != nil {
+		return fmt.Errorf("failed to start pipeline run: %w", err)
+	}
+	return nil
+}
+
+// RestartPipelineRun starts a source's run of spec over, forgetting the
+// stages it had checkpointed
+func RestartPipelineRun(sourceContentID int, spec models.PipelineSpec) error {
+	query := `
+		INSERT INTO pipeline_runs (source_content_id, spec)
+		VALUES ($1, $2)
+		ON CONFLICT (source_content_id) DO UPDATE
+		SET spec = EXCLUDED.spec, status = 'running', completed_stages = '[]', updated_at = NOW(), finished_at = NULL
+	`
+
+	if _, err := DB.Exec(query, sourceContentID, spec); err != nil {
+		return fmt.Errorf("failed to restart pipeline run: %w", err)
+	}
+	return nil
+}
+
+// CompletePipelineStage checkpoints a finished stage of a source's run
+func CompletePipelineStage(sourceContentID int, stage string) error {
+	query := `
+		UPDATE pipeline_runs
+		SET completed_stages = CASE
+				WHEN completed_stages @> jsonb_build_array($2::text) THEN completed_stages
+				ELSE completed_stages || jsonb_build_array($2::text)
+			END,
+			updated_at = NOW()
+		WHERE source_content_id = $1
+	`
+
+	if _, err := DB.Exec(query, sourceContentID, stage); err != nil {
+		return fmt.Errorf("failed to checkpoint pipeline stage: %w", err)
+	}
+	return nil
+}
+
+// FinishPipelineRun records that a source's run ended, completed or not
+func FinishPipelineRun(sourceContentID int, status string) error {
+	query := `
+		UPDATE pipeline_runs
+		SET status = $2, updated_at = NOW(), finished_at = NOW()
+		WHERE source_content_id = $1
+	`
+
+	if _, err := DB.Exec(query, sourceContentID, status); err != nil {
+		return fmt.Errorf("failed to finish pipeline run: %w", err)
+	}
+	return nil
+}
+
+// GetPipelineRun retrieves a source's run. Returns nil without an error for
+// sources processed before runs were recorded.
+func GetPipelineRun(sourceContentID int) (*models.PipelineRun, error) {
+	query := `SELECT ` + pipelineRunColumns + ` FROM pipeline_runs WHERE source_content_id = $1`
+
+	var run models.PipelineRun
+	err := scanPipelineRun(DB.QueryRow(query, sourceContentID), &run)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// MarkInterruptedPipelineRuns marks runs still running as incomplete. Call it
+// at startup, when no run can still be in progress.
+func MarkInterruptedPipelineRuns() (int, error) {
+	result, err := DB.Exec(`
+		UPDATE pipeline_runs
+		SET status = 'incomplete', updated_at = NOW()
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted pipeline runs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
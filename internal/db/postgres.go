@@ -4,18 +4,29 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // DB holds the database connection
 var DB *sql.DB
 
-// InitDB initializes the database connection
+// schemaName is what DB_SCHEMA may be: a lowercase, unquoted identifier
+var schemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// sharedExtensions are the extensions migrations use. They're database-wide,
+// so with DB_SCHEMA they're created in public, where every schema finds them.
+var sharedExtensions = []string{"pg_trgm", "vector"}
+
+// InitDB initializes the database connection. With DB_SCHEMA, as for a
+// tenant's server (see internal/tenancy), tables are created and queried in
+// that schema, which is created if need be.
 func InitDB() error {
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
@@ -23,6 +34,16 @@ func InitDB() error {
 	}
 
 	var err error
+	schema := os.Getenv("DB_SCHEMA")
+	if schema != "" {
+		if !schemaName.MatchString(schema) || strings.HasPrefix(schema, "pg_") {
+			return fmt.Errorf("invalid DB_SCHEMA %q: want a lowercase identifier such as tenant_acme", schema)
+		}
+		if databaseURL, err = withSearchPath(databaseURL, schema+",public"); err != nil {
+			return err
+		}
+	}
+
 	DB, err = sql.Open("postgres", databaseURL)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
@@ -33,6 +54,12 @@ func InitDB() error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if schema != "" {
+		if err := createSchema(schema); err != nil {
+			return err
+		}
+	}
+
 	// Set connection pool settings
 	DB.SetMaxOpenConns(25)
 	DB.SetMaxIdleConns(5)
@@ -41,6 +68,37 @@ func InitDB() error {
 	return nil
 }
 
+// withSearchPath adds a search_path to a connection URL or key=value
+// connection string, which lib/pq sets on every connection it opens
+func withSearchPath(databaseURL, searchPath string) (string, error) {
+	if !strings.HasPrefix(databaseURL, "postgres://") && !strings.HasPrefix(databaseURL, "postgresql://") {
+		return databaseURL + " search_path='" + searchPath + "'", nil
+	}
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("search_path", searchPath)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// createSchema creates schema, and the extensions migrations use in public,
+// if they don't exist yet
+func createSchema(schema string) error {
+	if _, err := DB.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(schema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+	for _, extension := range sharedExtensions {
+		if _, err := DB.Exec("CREATE EXTENSION IF NOT EXISTS " + pq.QuoteIdentifier(extension) + " SCHEMA public"); err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", extension, err)
+		}
+	}
+	return nil
+}
+
 // CloseDB closes the database connection
 func CloseDB() error {
 	if DB != nil {
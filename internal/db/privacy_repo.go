@@ -0,0 +1,452 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// dataRequestColumns is the column list scanned by scanDataRequest
+const dataRequestColumns = "id, user_id, kind, status, export_key, result, error, created_at, started_at, finished_at"
+
+// scanDataRequest scans a row selected with dataRequestColumns
+func scanDataRequest(row rowScanner, r *models.DataRequest) error {
+	return row.Scan(
+		&r.ID,
+		&r.UserID,
+		&r.Kind,
+		&r.Status,
+		&r.ExportKey,
+		&r.Result,
+		&r.Error,
+		&r.CreatedAt,
+		&r.StartedAt,
+		&r.FinishedAt,
+	)
+}
+
+// dataTable is a table holding workspace data, with the columns left out of
+// exports because they hold secrets
+type dataTable struct {
+	name   string
+	omit   []string
+	config bool   // Workspace configuration: exported, but kept when the last account is deleted
+	owned  string // Condition on t keeping the rows of the user in $1: those they own, and those of what they own; empty for workspace-only tables
+}
+
+// Rows that belong to what the user in $1 owns
+const (
+	ownedSources   = "(SELECT id FROM source_contents WHERE owner_id = $1)"
+	ownedConcepts  = "(SELECT id FROM concepts WHERE owner_id = $1)"
+	ownedQuestions = "(SELECT id FROM quiz_questions WHERE owner_id = $1)"
+	ownedContents  = "(SELECT id FROM generated_contents WHERE owner_id = $1)"
+)
+
+// dataTables lists every table of workspace data. Users, their identities,
+// and data requests are handled separately; settings, the workspace's
+// notification preferences, credentials, API tokens, pipeline hooks, the
+// auth event audit log, and the usage metered for billing are neither
+// exported nor deleted.
+var dataTables = []dataTable{
+	{name: "source_contents", owned: "t.owner_id = $1"},
+	{name: "transcript_segments", owned: "t.source_content_id IN " + ownedSources},
+	{name: "concepts", owned: "t.owner_id = $1"},
+	{name: "concept_relationships", owned: "t.from_concept_id IN " + ownedConcepts + " OR t.to_concept_id IN " + ownedConcepts},
+	{name: "collections"},
+	{name: "quiz_questions", owned: "t.owner_id = $1"},
+	{name: "quiz_attempts", owned: "t.question_id IN " + ownedQuestions},
+	{name: "quiz_explanations", owned: "t.question_id IN " + ownedQuestions},
+	{name: "quiz_sessions", owned: "t.owner_id = $1 OR t.source_content_id IN " + ownedSources + " OR t.concept_id IN " + ownedConcepts},
+	{name: "quiz_session_questions", owned: "t.question_id IN " + ownedQuestions},
+	{name: "learning_progress", owned: "t.concept_id IN " + ownedConcepts},
+	{name: "flashcards", owned: "t.concept_id IN " + ownedConcepts},
+	{name: "anki_note_links", owned: "t.question_id IN " + ownedQuestions + " OR t.flashcard_id IN (SELECT f.id FROM flashcards f JOIN concepts c ON c.id = f.concept_id WHERE c.owner_id = $1)"},
+	{name: "anki_reviews", owned: "t.concept_id IN " + ownedConcepts},
+	{name: "fsrs_optimizations"},
+	{name: "generated_contents", owned: "t.owner_id = $1"},
+	{name: "content_series"},
+	{name: "publishing_events", owned: "t.content_id IN " + ownedContents},
+	{name: "recycle_suggestions", owned: "t.generated_content_id IN " + ownedContents},
+	{name: "share_links", owned: "t.concept_id IN " + ownedConcepts + " OR t.generated_content_id IN " + ownedContents},
+	{name: "artifact_feedback", owned: "t.source_content_id IN " + ownedSources},
+	{name: "glossary_terms", owned: "t.source_content_id IN " + ownedSources},
+	{name: "action_items", owned: "t.source_content_id IN " + ownedSources},
+	{name: "mentions", owned: "t.source_content_id IN " + ownedSources},
+	{name: "source_chats", owned: "t.source_content_id IN " + ownedSources},
+	{name: "source_chat_messages", owned: "t.chat_id IN (SELECT id FROM source_chats WHERE source_content_id IN " + ownedSources + ")"},
+	{name: "source_comparisons"},
+	{name: "audio_summaries", owned: "t.source_content_id IN " + ownedSources},
+	{name: "briefings", owned: "t.owner_id = $1"},
+	{name: "notifications", owned: "t.user_id = $1"},
+	{name: "notification_preferences", owned: "t.user_id = $1"},
+	{name: "push_subscriptions", omit: []string{"token"}},
+	{name: "pending_transcriptions", omit: []string{"token"}, owned: "t.owner_id = $1"},
+	{name: "jobs", owned: "t.owner_id = $1"},
+	{name: "pipeline_runs", owned: "t.source_content_id IN " + ownedSources},
+	{name: "pipeline_hook_runs", owned: "t.source_content_id IN " + ownedSources},
+	{name: "prompt_experiment_assignments", owned: "t.source_content_id IN " + ownedSources},
+	{name: "eval_cases"},
+	{name: "eval_runs"},
+	{name: "eval_results"},
+	{name: "pipeline_definitions", config: true},
+	{name: "processing_profiles", config: true},
+	{name: "content_scripts", config: true},
+	{name: "output_templates", config: true},
+	{name: "prompt_experiments", config: true},
+}
+
+// CreateDataRequest queues a data request for a user
+func CreateDataRequest(userID int, kind string) (*models.DataRequest, error) {
+	query := `
+		INSERT INTO data_requests (user_id, kind)
+		VALUES ($1, $2)
+		RETURNING ` + dataRequestColumns
+
+	var r models.DataRequest
+	if err := scanDataRequest(DB.QueryRow(query, userID, kind), &r); err != nil {
+		return nil, fmt.Errorf("failed to create data request: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetPendingDataRequest returns a user's queued or running request of a kind.
+// Returns nil without an error when there's none.
+func GetPendingDataRequest(userID int, kind string) (*models.DataRequest, error) {
+	query := `
+		SELECT ` + dataRequestColumns + `
+		FROM data_requests
+		WHERE user_id = $1 AND kind = $2 AND status IN ('queued', 'running')
+		ORDER BY id
+		LIMIT 1
+	`
+
+	var r models.DataRequest
+	err := scanDataRequest(DB.QueryRow(query, userID, kind), &r)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, none pending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data request: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetDataRequestByID retrieves a data request by ID
+func GetDataRequestByID(id int) (*models.DataRequest, error) {
+	query := `
+		SELECT ` + dataRequestColumns + `
+		FROM data_requests
+		WHERE id = $1
+	`
+
+	var r models.DataRequest
+	err := scanDataRequest(DB.QueryRow(query, id), &r)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data request: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetDataRequestsByUser retrieves a user's data requests, newest first
+func GetDataRequestsByUser(userID int) ([]models.DataRequest, error) {
+	query := `
+		SELECT ` + dataRequestColumns + `
+		FROM data_requests
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.DataRequest{}
+	for rows.Next() {
+		var r models.DataRequest
+		if err := scanDataRequest(rows, &r); err != nil {
+			return nil, fmt.Errorf("failed to scan data request: %w", err)
+		}
+		requests = append(requests, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ClaimNextDataRequest marks the oldest queued data request running and
+// returns it. Returns nil without an error when none are queued.
+func ClaimNextDataRequest() (*models.DataRequest, error) {
+	query := `
+		UPDATE data_requests
+		SET status = 'running', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM data_requests
+			WHERE status = 'queued'
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + dataRequestColumns
+
+	var r models.DataRequest
+	err := scanDataRequest(DB.QueryRow(query), &r)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data request: %w", err)
+	}
+
+	return &r, nil
+}
+
+// FinishDataRequest records the outcome of a data request: its export's
+// archive and what it exported or deleted, or why it failed
+func FinishDataRequest(id int, exportKey *string, result models.JSONObject, failure *string) error {
+	query := `
+		UPDATE data_requests
+		SET status = CASE WHEN $4::text IS NULL THEN 'completed' ELSE 'failed' END,
+			export_key = $2, result = COALESCE($3, result), error = $4, finished_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := DB.Exec(query, id, exportKey, result, failure); err != nil {
+		return fmt.Errorf("failed to finish data request: %w", err)
+	}
+	return nil
+}
+
+// RequeueInterruptedDataRequests queues data requests still marked running
+// again. Call it at startup, when none can still be in progress.
+func RequeueInterruptedDataRequests() (int, error) {
+	result, err := DB.Exec(`
+		UPDATE data_requests
+		SET status = 'queued', started_at = NULL
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue interrupted data requests: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ExportWorkspaceData passes each data table's rows to write as a JSON array,
+// without the columns that hold secrets and with encrypted text decrypted
+func ExportWorkspaceData(write func(table string, rows json.RawMessage) error) error {
+	return exportData(nil, write)
+}
+
+// ExportUserData is ExportWorkspaceData for the rows a user owns, and the
+// rows of what they own. Tables of workspace data alone are left out.
+func ExportUserData(userID int, write func(table string, rows json.RawMessage) error) error {
+	return exportData(&userID, write)
+}
+
+// exportData exports every data table, or with a user, their rows of the
+// tables that have an owned condition
+func exportData(userID *int, write func(table string, rows json.RawMessage) error) error {
+	for _, table := range dataTables {
+		query := fmt.Sprintf("SELECT COALESCE(jsonb_agg(to_jsonb(t) - COALESCE($1::text[], '{}')), '[]') FROM %s t", table.name)
+		args := []interface{}{pq.Array(table.omit)}
+		if userID != nil {
+			if table.owned == "" {
+				continue
+			}
+			query = fmt.Sprintf("SELECT COALESCE(jsonb_agg(to_jsonb(t) - COALESCE($2::text[], '{}')), '[]') FROM %s t WHERE %s", table.name, table.owned)
+			args = []interface{}{*userID, pq.Array(table.omit)}
+		}
+
+		var rows []byte
+		if err := DB.QueryRow(query, args...).Scan(&rows); err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.name, err)
+		}
+		rows, err := openSealedRows(table.name, rows)
+		if err != nil {
+			return err
+		}
+		if err := write(table.name, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserObjectKeys returns the keys of the stored objects the rows a user
+// owns name, as ExportUserData selects them
+func GetUserObjectKeys(userID int) ([]string, error) {
+	keys := []string{}
+	for _, col := range BlobColumns {
+		for _, table := range dataTables {
+			if table.name != col.Table || table.owned == "" {
+				continue
+			}
+
+			query := fmt.Sprintf("SELECT DISTINCT t.%s FROM %s t WHERE t.%s IS NOT NULL AND (%s) ORDER BY 1", col.Column, col.Table, col.Column, table.owned)
+			rows, err := DB.Query(query, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query %s.%s: %w", col.Table, col.Column, err)
+			}
+			for rows.Next() {
+				var key string
+				if err := rows.Scan(&key); err != nil {
+					rows.Close()
+					return nil, fmt.Errorf("failed to scan %s.%s: %w", col.Table, col.Column, err)
+				}
+				keys = append(keys, key)
+			}
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error iterating %s.%s: %w", col.Table, col.Column, err)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// CheckUserDeletable returns an error unless the user exists and deleting
+// them would leave an admin while other users remain, and leave
+// authentication on: the last user goes only while an API token exists
+func CheckUserDeletable(id int) error {
+	return checkUserDeletable(DB, id)
+}
+
+// queryRower runs single-row queries on the database or in a transaction
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkUserDeletable is CheckUserDeletable run through q
+func checkUserDeletable(q queryRower, id int) error {
+	var lastAdmin, reopens bool
+	err := q.QueryRow(`
+		SELECT role = 'admin'
+			AND EXISTS (SELECT 1 FROM users o WHERE o.id <> $1)
+			AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> $1 AND o.role = 'admin'),
+			NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> $1)
+			AND NOT EXISTS (SELECT 1 FROM api_tokens)
+		FROM users
+		WHERE id = $1
+	`, id).Scan(&lastAdmin, &reopens)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query user: %w", err)
+	}
+	if lastAdmin {
+		return fmt.Errorf("cannot delete the last admin")
+	}
+	if reopens {
+		// Without users or tokens the API is open to anyone, as an admin
+		return fmt.Errorf("cannot delete the last account")
+	}
+
+	return nil
+}
+
+// DeleteUserAccount deletes a user and their identities in a single
+// transaction, recording the deletion on the data request carrying it out.
+// What they own goes with them. When they're the last user, every data table
+// except the configuration ones is emptied too. Returns the rows deleted by
+// table, and whether the workspace's data went with them.
+func DeleteUserAccount(requestID, userID int) (map[string]int, bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	// Concurrent deletions could each see the other user as the one remaining
+	if _, err := tx.Exec("LOCK TABLE users IN EXCLUSIVE MODE"); err != nil {
+		return nil, false, fmt.Errorf("failed to lock users: %w", err)
+	}
+	if err := checkUserDeletable(tx, userID); err != nil {
+		return nil, false, err
+	}
+
+	var lastUser bool
+	if err := tx.QueryRow("SELECT NOT EXISTS (SELECT 1 FROM users WHERE id <> $1)", userID).Scan(&lastUser); err != nil {
+		return nil, false, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var identities int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM user_identities WHERE user_id = $1", userID).Scan(&identities); err != nil {
+		return nil, false, fmt.Errorf("failed to count user identities: %w", err)
+	}
+	counts := map[string]int{"users": 1, "user_identities": identities}
+
+	// Counted first, since deleting the user cascades into what they own and
+	// deleting one table cascades into the next: with the last user every
+	// data table's rows, otherwise the rows of what the user owns
+	for _, table := range dataTables {
+		var n int
+		var err error
+		switch {
+		case lastUser && !table.config:
+			err = tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table.name)).Scan(&n)
+		case !lastUser && table.owned != "":
+			err = tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s t WHERE %s", table.name, table.owned), userID).Scan(&n)
+			if n == 0 {
+				continue
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to count %s: %w", table.name, err)
+		}
+		counts[table.name] = n
+	}
+
+	if _, err := tx.Exec("DELETE FROM users WHERE id = $1", userID); err != nil {
+		return nil, false, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if lastUser {
+		for _, table := range dataTables {
+			if table.config {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table.name)); err != nil {
+				return nil, false, fmt.Errorf("failed to delete %s: %w", table.name, err)
+			}
+		}
+	}
+
+	deleted := models.JSONObject{"rows": counts, "workspace_deleted": lastUser}
+	if _, err := tx.Exec("UPDATE data_requests SET result = $2 WHERE id = $1", requestID, deleted); err != nil {
+		return nil, false, fmt.Errorf("failed to record deletion: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return counts, lastUser, nil
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// processingProfileColumns is the column list scanned by scanProcessingProfile
+const processingProfileColumns = "id, name, description, pipeline_id, concepts, platforms, instructions, created_at, updated_at"
+
+// scanProcessingProfile scans a row selected with processingProfileColumns
+func scanProcessingProfile(row rowScanner, p *models.ProcessingProfile) error {
+	return row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Description,
+		&p.PipelineID,
+		&p.Concepts,
+		&p.Platforms,
+		&p.Instructions,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+}
+
+// CreateProcessingProfile stores a new processing profile
+func CreateProcessingProfile(req models.ProcessingProfileRequest) (*models.ProcessingProfile, error) {
+	query := `
+		INSERT INTO processing_profiles (name, description, pipeline_id, concepts, platforms, instructions)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + processingProfileColumns
+
+	var p models.ProcessingProfile
+	err := scanProcessingProfile(DB.QueryRow(query,
+		req.Name,
+		req.Description,
+		req.PipelineID,
+		req.Concepts,
+		models.StringArray(req.Platforms),
+		req.Instructions,
+	), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("profile name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create processing profile: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetProcessingProfiles retrieves all processing profiles in name order
+func GetProcessingProfiles() ([]models.ProcessingProfile, error) {
+	query := `
+		SELECT ` + processingProfileColumns + `
+		FROM processing_profiles
+		ORDER BY name ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processing profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []models.ProcessingProfile{}
+	for rows.Next() {
+		var p models.ProcessingProfile
+		if err := scanProcessingProfile(rows, &p); err != nil {
+			return nil, fmt.Errorf("failed to scan processing profile: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating processing profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// GetProcessingProfileByID retrieves a single processing profile by ID
+func GetProcessingProfileByID(id int) (*models.ProcessingProfile, error) {
+	query := `
+		SELECT ` + processingProfileColumns + `
+		FROM processing_profiles
+		WHERE id = $1
+	`
+
+	var p models.ProcessingProfile
+	err := scanProcessingProfile(DB.QueryRow(query, id), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processing profile: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetProcessingProfileByName retrieves a single processing profile by name
+func GetProcessingProfileByName(name string) (*models.ProcessingProfile, error) {
+	query := `
+		SELECT ` + processingProfileColumns + `
+		FROM processing_profiles
+		WHERE name = $1
+	`
+
+	var p models.ProcessingProfile
+	err := scanProcessingProfile(DB.QueryRow(query, name), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processing profile: %w", err)
+	}
+
+	return &p, nil
+}
+
+// UpdateProcessingProfile replaces a processing profile. Sources already
+// submitted with it keep its old name.
+func UpdateProcessingProfile(id int, req models.ProcessingProfileRequest) (*models.ProcessingProfile, error) {
+	if _, err := GetProcessingProfileByID(id); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE processing_profiles
+		SET name = $2, description = $3, pipeline_id = $4, concepts = $5, platforms = $6, instructions = $7, updated_at = NOW()
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM processing_profiles o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + processingProfileColumns
+
+	var p models.ProcessingProfile
+	err := scanProcessingProfile(DB.QueryRow(query,
+		id,
+		req.Name,
+		req.Description,
+		req.PipelineID,
+		req.Concepts,
+		models.StringArray(req.Platforms),
+		req.Instructions,
+	), &p)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("profile name already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update processing profile: %w", err)
+	}
+
+	return &p, nil
+}
+
+// DeleteProcessingProfile deletes a processing profile by ID
+func DeleteProcessingProfile(id int) error {
+	query := "DELETE FROM processing_profiles WHERE id = $1"
+
+	result, err := DB.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete processing profile: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("profile not found")
+	}
+
+	return nil
+}
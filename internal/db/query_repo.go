@@ -0,0 +1,391 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// queryFieldKind is how a query field compares with values
+type queryFieldKind int
+
+const (
+	queryText   queryFieldKind = iota // ":" contains, "=" equals, case-insensitively
+	queryTag                          // ":" and "=" have the tag, case-insensitively
+	queryNumber                       // Any comparison
+	queryDate                         // Any comparison, with a date or RFC 3339 time
+	queryBool                         // true or false
+)
+
+// queryField is a field of the query language and the SQL expression it reads.
+// For tags the expression selects the lowercased tags.
+type queryField struct {
+	expr string
+	kind queryFieldKind
+}
+
+// conceptProgress selects a column of a concept's learning progress
+func conceptProgress(column string) string {
+	return "(SELECT lp." + column + " FROM learning_progress lp WHERE lp.concept_id = concepts.id)"
+}
+
+// conceptQueryFields are the fields concept queries can filter on
+var conceptQueryFields = map[string]queryField{
+	"id":          {"concepts.id", queryNumber},
+	"title":       {"concepts.title", queryText},
+	"description": {"concepts.description", queryText},
+	"tag":         {"SELECT lower(tag) FROM jsonb_array_elements_text(concepts.tags) AS tag", queryTag},
+	"source":      {"concepts.source_content_id", queryNumber},
+	"source_type": {"(SELECT s.type FROM source_contents s WHERE s.id = concepts.source_content_id)", queryText},
+	"pinned":      {"concepts.pinned", queryBool},
+	"mastery":     {"COALESCE(" + conceptProgress("mastery_level") + ", 0)", queryNumber},
+	"due":         {conceptProgress("next_review_at"), queryDate},
+	"reviewed":    {conceptProgress("last_reviewed_at"), queryDate},
+	"attempts": {`(SELECT COUNT(*) FROM quiz_attempts a JOIN quiz_questions q ON q.id = a.question_id
+		WHERE q.concept_id = concepts.id)`, queryNumber},
+	"accuracy": {`(SELECT AVG(a.correct::int) FROM quiz_attempts a JOIN quiz_questions q ON q.id = a.question_id
+		WHERE q.concept_id = concepts.id)`, queryNumber},
+	"created": {"concepts.created_at", queryDate},
+	"updated": {"concepts.updated_at", queryDate},
+}
+
+// sourceQueryFields are the fields source queries can filter on
+var sourceQueryFields = map[string]queryField{
+	"id":       {"source_contents.id", queryNumber},
+	"type":     {"source_contents.type", queryText},
+	"title":    {"source_contents.title", queryText},
+	"url":      {"source_contents.url", queryText},
+	"language": {"source_contents.language", queryText},
+	"profile":  {"source_contents.profile", queryText},
+	"tag": {`SELECT lower(tag) FROM concepts c, jsonb_array_elements_text(c.tags) AS tag
+		WHERE c.source_content_id = source_contents.id`, queryTag},
+	"concepts":  {"(SELECT COUNT(*) FROM concepts c WHERE c.source_content_id = source_contents.id)", queryNumber},
+	"created":   {"source_contents.created_at", queryDate},
+	"processed": {"source_contents.processed_at", queryDate},
+}
+
+// queryCompiler turns a parsed query into a SQL condition, collecting its
+// arguments
+type queryCompiler struct {
+	fields map[string]queryField
+	text   []string // Columns bare words are matched against
+	loc    *time.Location
+	now    time.Time
+	args   []interface{}
+}
+
+// arg adds a query argument, returning its placeholder
+func (qc *queryCompiler) arg(v interface{}) string {
+	qc.args = append(qc.args, v)
+	return "$" + strconv.Itoa(len(qc.args))
+}
+
+// timeArg adds a time argument, cast so its zone is kept
+func (qc *queryCompiler) timeArg(t time.Time) string {
+	return qc.arg(t) + "::timestamptz"
+}
+
+// compile returns the SQL condition for a node. Comparisons with a missing
+// value, like the due date of a concept never reviewed, are false, so NOT
+// still matches them.
+func (qc *queryCompiler) compile(n query.Node) (string, error) {
+	switch n := n.(type) {
+	case query.And:
+		return qc.compileAll(n, " AND ")
+	case query.Or:
+		return qc.compileAll(n, " OR ")
+	case query.Not:
+		cond, err := qc.compile(n.Node)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + cond, nil
+	case query.Term:
+		cond, err := qc.compileTerm(n)
+		if err != nil {
+			return "", err
+		}
+		return "COALESCE(" + cond + ", false)", nil
+	}
+	return "", fmt.Errorf("%w: unsupported node %T", query.ErrInvalidQuery, n)
+}
+
+// compileAll joins the conditions of nodes with sep
+func (qc *queryCompiler) compileAll(nodes []query.Node, sep string) (string, error) {
+	conds := make([]string, len(nodes))
+	for i, n := range nodes {
+		cond, err := qc.compile(n)
+		if err != nil {
+			return "", err
+		}
+		conds[i] = cond
+	}
+	return "(" + strings.Join(conds, sep) + ")", nil
+}
+
+// compileTerm returns the SQL condition for a term, checking its field,
+// operator, and value
+func (qc *queryCompiler) compileTerm(t query.Term) (string, error) {
+	if t.Field == "" {
+		pattern := qc.arg("%" + escapeLike(t.Value) + "%")
+		conds := make([]string, len(qc.text))
+		for i, column := range qc.text {
+			conds[i] = column + " ILIKE " + pattern
+		}
+		return "(" + strings.Join(conds, " OR ") + ")", nil
+	}
+
+	field, ok := qc.fields[t.Field]
+	if !ok {
+		names := make([]string, 0, len(qc.fields))
+		for name := range qc.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("%w: unknown field %q (fields: %s)", query.ErrInvalidQuery, t.Field, strings.Join(names, ", "))
+	}
+	op := t.Op
+	invalidOp := fmt.Errorf("%w: %s can't be compared with %s", query.ErrInvalidQuery, t.Field, op)
+
+	switch field.kind {
+	case queryText:
+		switch op {
+		case query.OpMatch:
+			return field.expr + " ILIKE " + qc.arg("%"+escapeLike(t.Value)+"%"), nil
+		case query.OpEqual:
+			return "lower(" + field.expr + ") = lower(" + qc.arg(t.Value) + ")", nil
+		case query.OpNotEqual:
+			return "lower(" + field.expr + ") <> lower(" + qc.arg(t.Value) + ")", nil
+		}
+		return "", invalidOp
+
+	case queryTag:
+		cond := "lower(" + qc.arg(t.Value) + ") IN (" + field.expr + ")"
+		switch op {
+		case query.OpMatch, query.OpEqual:
+			return cond, nil
+		case query.OpNotEqual:
+			return "NOT " + cond, nil
+		}
+		return "", invalidOp
+
+	case queryBool:
+		value, err := strconv.ParseBool(t.Value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s must be true or false", query.ErrInvalidQuery, t.Field)
+		}
+		switch op {
+		case query.OpMatch, query.OpEqual:
+			return field.expr + " = " + qc.arg(value), nil
+		case query.OpNotEqual:
+			return field.expr + " <> " + qc.arg(value), nil
+		}
+		return "", invalidOp
+
+	case queryNumber:
+		value, err := strconv.ParseFloat(t.Value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s must be a number", query.ErrInvalidQuery, t.Field)
+		}
+		return field.expr + " " + sqlOperator(op) + " " + qc.arg(value) + "::float8", nil
+
+	case queryDate:
+		return qc.compileDate(field.expr, t)
+	}
+	return "", invalidOp
+}
+
+// compileDate compares a timestamp with a time, or with a day in the
+// compiler's zone: created>2024-01-01 is after that day, created=2024-01-01
+// during it. today, yesterday, and tomorrow name days too.
+func (qc *queryCompiler) compileDate(expr string, t query.Term) (string, error) {
+	if at, err := time.Parse(time.RFC3339, t.Value); err == nil {
+		return expr + " " + sqlOperator(t.Op) + " " + qc.timeArg(at), nil
+	}
+
+	var day time.Time
+	today := time.Date(qc.now.Year(), qc.now.Month(), qc.now.Day(), 0, 0, 0, 0, qc.loc)
+	switch strings.ToLower(t.Value) {
+	case "today":
+		day = today
+	case "yesterday":
+		day = today.AddDate(0, 0, -1)
+	case "tomorrow":
+		day = today.AddDate(0, 0, 1)
+	default:
+		parsed, err := time.ParseInLocation("2006-01-02", t.Value, qc.loc)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s must be a date (2024-01-31), an RFC 3339 time, today, yesterday, or tomorrow",
+				query.ErrInvalidQuery, t.Field)
+		}
+		day = parsed
+	}
+	next := day.AddDate(0, 0, 1)
+
+	switch t.Op {
+	case query.OpLess:
+		return expr + " < " + qc.timeArg(day), nil
+	case query.OpLessEqual:
+		return expr + " < " + qc.timeArg(next), nil
+	case query.OpGreater:
+		return expr + " >= " + qc.timeArg(next), nil
+	case query.OpGreaterEqual:
+		return expr + " >= " + qc.timeArg(day), nil
+	case query.OpNotEqual:
+		return "NOT (" + expr + " >= " + qc.timeArg(day) + " AND " + expr + " < " + qc.timeArg(next) + ")", nil
+	}
+	return "(" + expr + " >= " + qc.timeArg(day) + " AND " + expr + " < " + qc.timeArg(next) + ")", nil
+}
+
+// sqlOperator returns the SQL operator for a query comparison, treating ":" as "="
+func sqlOperator(op string) string {
+	switch op {
+	case query.OpMatch:
+		return "="
+	case query.OpNotEqual:
+		return "<>"
+	}
+	return op
+}
+
+// newConceptQueryCompiler returns a compiler for concept queries, with days
+// in dates taken in loc
+func newConceptQueryCompiler(loc *time.Location) *queryCompiler {
+	return &queryCompiler{
+		fields: conceptQueryFields,
+		text:   []string{"concepts.title", "concepts.description"},
+		loc:    loc,
+		now:    time.Now().In(loc),
+	}
+}
+
+// CheckConceptQuery returns an error wrapping query.ErrInvalidQuery when a
+// parsed query names fields or values concept queries don't support
+func CheckConceptQuery(n query.Node) error {
+	_, err := newConceptQueryCompiler(time.UTC).compile(n)
+	return err
+}
+
+// QueryConcepts retrieves a page of concepts matching a parsed query, newest
+// first, with days in dates taken in loc. Archived concepts are skipped
+// unless includeArchived is set. The returned cursor is nil on the last page.
+func QueryConcepts(n query.Node, loc *time.Location, includeArchived bool, owner *int, page models.Page) ([]models.Concept, *models.Cursor, error) {
+	qc := newConceptQueryCompiler(loc)
+	cond, err := qc.compile(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !includeArchived {
+		cond += " AND " + conceptActiveCondition
+	}
+	var owned string
+	owned, qc.args = ownerClause("concepts.owner_id", owner, qc.args)
+	cond += owned
+
+	afterTime, afterID := cursorArgs(page)
+	afterTimeArg := qc.arg(afterTime)
+	q := `
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE ` + cond + `
+			AND (` + afterTimeArg + `::timestamptz IS NULL OR (concepts.created_at, concepts.id) < (` + afterTimeArg + `, ` + qc.arg(afterID) + `))
+		ORDER BY concepts.created_at DESC, concepts.id DESC
+		LIMIT ` + qc.arg(page.Limit+1)
+
+	rows, err := DB.Query(q, qc.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query concepts: %w", err)
+	}
+	defer rows.Close()
+
+	concepts := []models.Concept{}
+	for rows.Next() {
+		var c models.Concept
+		if err := scanConcept(rows, &c); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan concept: %w", err)
+		}
+		concepts = append(concepts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating concepts: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(concepts) > page.Limit {
+		concepts = concepts[:page.Limit]
+		last := concepts[len(concepts)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return concepts, next, nil
+}
+
+// QuerySources retrieves a page of sources matching a parsed query, newest
+// first and without transcripts, with days in dates taken in loc. Archived
+// sources are skipped unless includeArchived is set. The returned cursor is
+// nil on the last page.
+func QuerySources(n query.Node, loc *time.Location, includeArchived bool, owner *int, page models.Page) ([]models.SourceContentSummary, *models.Cursor, error) {
+	qc := &queryCompiler{
+		fields: sourceQueryFields,
+		text:   []string{"source_contents.title"},
+		loc:    loc,
+		now:    time.Now().In(loc),
+	}
+	cond, err := qc.compile(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !includeArchived {
+		cond += " AND source_contents.archived_at IS NULL"
+	}
+	var owned string
+	owned, qc.args = ownerClause("source_contents.owner_id", owner, qc.args)
+	cond += owned
+
+	afterTime, afterID := cursorArgs(page)
+	afterTimeArg := qc.arg(afterTime)
+	q := `
+		SELECT id, type, url, title, processed_at, archived_at, created_at
+		FROM source_contents
+		WHERE ` + cond + `
+			AND (` + afterTimeArg + `::timestamptz IS NULL OR (source_contents.created_at, source_contents.id) < (` + afterTimeArg + `, ` + qc.arg(afterID) + `))
+		ORDER BY source_contents.created_at DESC, source_contents.id DESC
+		LIMIT ` + qc.arg(page.Limit+1)
+
+	rows, err := DB.Query(q, qc.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query source contents: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []models.SourceContentSummary{}
+	for rows.Next() {
+		var sc models.SourceContentSummary
+		err := rows.Scan(&sc.ID, &sc.Type, &sc.URL, &sc.Title, &sc.ProcessedAt, &sc.ArchivedAt, &sc.CreatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan source content: %w", err)
+		}
+		sources = append(sources, sc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating source contents: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(sources) > page.Limit {
+		sources = sources[:page.Limit]
+		last := sources[len(sources)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return sources, next, nil
+}
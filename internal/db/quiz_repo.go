@@ -7,6 +7,48 @@ import (
 	"github.com/mostlyerror/lattice/internal/models"
 )
 
+// quizQuestionColumns is the column list scanned by scanQuizQuestion
+const quizQuestionColumns = "id, concept_id, question, option_a, option_b, option_c, option_d, correct_answer, explanation, distractor_rationales, lapses, suspended_at, created_at"
+
+// scanQuizQuestion scans a row selected with quizQuestionColumns
+func scanQuizQuestion(row rowScanner, q *models.QuizQuestion) error {
+	return row.Scan(
+		&q.ID,
+		&q.ConceptID,
+		&q.Question,
+		&q.OptionA,
+		&q.OptionB,
+		&q.OptionC,
+		&q.OptionD,
+		&q.CorrectAnswer,
+		&q.Explanation,
+		&q.DistractorRationales,
+		&q.Lapses,
+		&q.SuspendedAt,
+		&q.CreatedAt,
+	)
+}
+
+// quizAttemptColumns is the column list scanned by scanQuizAttempt
+const quizAttemptColumns = "id, question_id, selected_answer, correct, session_id, displayed_answer, rating, time_to_answer_ms, latency_ms, timed_out, attempted_at"
+
+// scanQuizAttempt scans a row selected with quizAttemptColumns
+func scanQuizAttempt(row rowScanner, a *models.QuizAttempt) error {
+	return row.Scan(
+		&a.ID,
+		&a.QuestionID,
+		&a.SelectedAnswer,
+		&a.Correct,
+		&a.SessionID,
+		&a.DisplayedAnswer,
+		&a.Rating,
+		&a.TimeToAnswerMs,
+		&a.LatencyMs,
+		&a.TimedOut,
+		&a.AttemptedAt,
+	)
+}
+
 // CreateQuizBatch creates multiple quiz questions in a single transaction
 func CreateQuizBatch(questions []models.QuizQuestion) ([]models.QuizQuestion, error) {
 	if len(questions) == 0 {
@@ -23,18 +65,16 @@ func CreateQuizBatch(questions []models.QuizQuestion) ([]models.QuizQuestion, er
 	query := `
 		INSERT INTO quiz_questions (
 			concept_id, question, option_a, option_b, option_c, option_d,
-			correct_answer, explanation
+			correct_answer, explanation, distractor_rationales
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, concept_id, question, option_a, option_b, option_c, option_d,
-			correct_answer, explanation, created_at
-	`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + quizQuestionColumns
 
 	createdQuestions := make([]models.QuizQuestion, 0, len(questions))
 
 	for _, q := range questions {
 		var created models.QuizQuestion
-		err := tx.QueryRow(
+		err := scanQuizQuestion(tx.QueryRow(
 			query,
 			q.ConceptID,
 			q.Question,
@@ -44,18 +84,8 @@ func CreateQuizBatch(questions []models.QuizQuestion) ([]models.QuizQuestion, er
 			q.OptionD,
 			q.CorrectAnswer,
 			q.Explanation,
-		).Scan(
-			&created.ID,
-			&created.ConceptID,
-			&created.Question,
-			&created.OptionA,
-			&created.OptionB,
-			&created.OptionC,
-			&created.OptionD,
-			&created.CorrectAnswer,
-			&created.Explanation,
-			&created.CreatedAt,
-		)
+			q.DistractorRationales,
+		), &created)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to create quiz question: %w", err)
@@ -72,15 +102,47 @@ func CreateQuizBatch(questions []models.QuizQuestion) ([]models.QuizQuestion, er
 	return createdQuestions, nil
 }
 
-// GetQuizzesByConceptID retrieves all quizzes for a concept
-func GetQuizzesByConceptID(conceptID int) ([]models.QuizQuestion, error) {
+// nextReviewQuestionOrder orders a concept's questions q for review: the one
+// answered longest ago first, never answered ones before any
+const nextReviewQuestionOrder = `(SELECT MAX(a.attempted_at) FROM quiz_attempts a WHERE a.question_id = q.id) ASC NULLS FIRST, q.created_at ASC`
+
+// GetNextReviewQuestion returns a concept's unsuspended question answered
+// longest ago, preferring ones never answered. Returns nil without an error
+// when the concept has none.
+func GetNextReviewQuestion(conceptID int) (*models.QuizQuestion, error) {
 	query := `
-		SELECT id, concept_id, question, option_a, option_b, option_c, option_d,
-			correct_answer, explanation, created_at
+		SELECT ` + quizQuestionColumns + `
+		FROM quiz_questions q
+		WHERE concept_id = $1 AND suspended_at IS NULL
+		ORDER BY ` + nextReviewQuestionOrder + `
+		LIMIT 1
+	`
+
+	var q models.QuizQuestion
+	err := scanQuizQuestion(DB.QueryRow(query, conceptID), &q)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, the concept has no questions
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz question: %w", err)
+	}
+
+	return &q, nil
+}
+
+// GetQuizzesByConceptID retrieves all quizzes for a concept, skipping suspended
+// leeches unless includeSuspended is set
+func GetQuizzesByConceptID(conceptID int, includeSuspended bool) ([]models.QuizQuestion, error) {
+	query := `
+		SELECT ` + quizQuestionColumns + `
 		FROM quiz_questions
 		WHERE concept_id = $1
-		ORDER BY created_at ASC
 	`
+	if !includeSuspended {
+		query += " AND suspended_at IS NULL"
+	}
+	query += " ORDER BY created_at ASC"
 
 	rows, err := DB.Query(query, conceptID)
 	if err != nil {
@@ -91,18 +153,7 @@ func GetQuizzesByConceptID(conceptID int) ([]models.QuizQuestion, error) {
 	var questions []models.QuizQuestion
 	for rows.Next() {
 		var q models.QuizQuestion
-		err := rows.Scan(
-			&q.ID,
-			&q.ConceptID,
-			&q.Question,
-			&q.OptionA,
-			&q.OptionB,
-			&q.OptionC,
-			&q.OptionD,
-			&q.CorrectAnswer,
-			&q.Explanation,
-			&q.CreatedAt,
-		)
+		err := scanQuizQuestion(rows, &q)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
 		}
@@ -120,7 +171,7 @@ func GetQuizzesByConceptID(conceptID int) ([]models.QuizQuestion, error) {
 func GetQuizzesBySourceContentID(sourceContentID int) ([]models.QuizQuestion, error) {
 	query := `
 		SELECT q.id, q.concept_id, q.question, q.option_a, q.option_b, q.option_c, q.option_d,
-			q.correct_answer, q.explanation, q.created_at
+			q.correct_answer, q.explanation, q.distractor_rationales, q.lapses, q.suspended_at, q.created_at
 		FROM quiz_questions q
 		INNER JOIN concepts c ON q.concept_id = c.id
 		WHERE c.source_content_id = $1
@@ -136,18 +187,7 @@ func GetQuizzesBySourceContentID(sourceContentID int) ([]models.QuizQuestion, er
 	var questions []models.QuizQuestion
 	for rows.Next() {
 		var q models.QuizQuestion
-		err := rows.Scan(
-			&q.ID,
-			&q.ConceptID,
-			&q.Question,
-			&q.OptionA,
-			&q.OptionB,
-			&q.OptionC,
-			&q.OptionD,
-			&q.CorrectAnswer,
-			&q.Explanation,
-			&q.CreatedAt,
-		)
+		err := scanQuizQuestion(rows, &q)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
 		}
@@ -164,25 +204,13 @@ func GetQuizzesBySourceContentID(sourceContentID int) ([]models.QuizQuestion, er
 // GetQuizQuestionByID retrieves a single quiz question by ID
 func GetQuizQuestionByID(id int) (*models.QuizQuestion, error) {
 	query := `
-		SELECT id, concept_id, question, option_a, option_b, option_c, option_d,
-			correct_answer, explanation, created_at
+		SELECT ` + quizQuestionColumns + `
 		FROM quiz_questions
 		WHERE id = $1
 	`
 
 	var q models.QuizQuestion
-	err := DB.QueryRow(query, id).Scan(
-		&q.ID,
-		&q.ConceptID,
-		&q.Question,
-		&q.OptionA,
-		&q.OptionB,
-		&q.OptionC,
-		&q.OptionD,
-		&q.CorrectAnswer,
-		&q.Explanation,
-		&q.CreatedAt,
-	)
+	err := scanQuizQuestion(DB.QueryRow(query, id), &q)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("quiz question not found")
@@ -214,3 +242,278 @@ func DeleteQuizQuestion(id int) error {
 
 	return nil
 }
+
+// GetAttemptSummaryByConceptID aggregates all quiz attempts across a concept's questions
+func GetAttemptSummaryByConceptID(conceptID int) (*models.AttemptSummary, error) {
+	query := `
+		SELECT COUNT(a.id),
+			COUNT(a.id) FILTER (WHERE a.correct),
+			MAX(a.attempted_at)
+		FROM quiz_attempts a
+		INNER JOIN quiz_questions q ON a.question_id = q.id
+		WHERE q.concept_id = $1
+	`
+
+	var summary models.AttemptSummary
+	err := DB.QueryRow(query, conceptID).Scan(
+		&summary.TotalAttempts,
+		&summary.CorrectAttempts,
+		&summary.LastAttemptedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attempt summary: %w", err)
+	}
+
+	if summary.TotalAttempts > 0 {
+		summary.Accuracy = float64(summary.CorrectAttempts) / float64(summary.TotalAttempts)
+	}
+
+	return &summary, nil
+}
+
+// CreateQuizAttempt records an answer to a quiz question
+func CreateQuizAttempt(attempt models.QuizAttempt) (*models.QuizAttempt, error) {
+	query := `
+		INSERT INTO quiz_attempts (
+			question_id, selected_answer, correct, session_id, displayed_answer,
+			rating, time_to_answer_ms, latency_ms, timed_out
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + quizAttemptColumns
+
+	var a models.QuizAttempt
+	err := scanQuizAttempt(DB.QueryRow(
+		query,
+		attempt.QuestionID,
+		attempt.SelectedAnswer,
+		attempt.Correct,
+		attempt.SessionID,
+		attempt.DisplayedAnswer,
+		attempt.Rating,
+		attempt.TimeToAnswerMs,
+		attempt.LatencyMs,
+		attempt.TimedOut,
+	), &a)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quiz attempt: %w", err)
+	}
+
+	return &a, nil
+}
+
+// GetQuizAttempts retrieves a page of attempts at questions owner can see,
+// newest first, optionally filtered by question, session, or concept. The
+// returned cursor is nil on the last page.
+func GetQuizAttempts(filter models.QuizAttemptFilter, page models.Page, owner *int) ([]models.QuizAttempt, *models.Cursor, error) {
+	query := `
+		SELECT ` + quizAttemptColumns + `
+		FROM quiz_attempts
+		WHERE ($1::int IS NULL OR question_id = $1)
+			AND ($2::int IS NULL OR session_id = $2)
+			AND ($3::int IS NULL OR question_id IN (SELECT id FROM quiz_questions WHERE concept_id = $3))
+			AND ($4::timestamptz IS NULL OR (attempted_at, id) < ($4, $5))
+			AND ($7::int IS NULL OR question_id IN (SELECT id FROM quiz_questions q WHERE ` + visibleTo("q.owner_id", 7) + `))
+		ORDER BY attempted_at DESC, id DESC
+		LIMIT $6
+	`
+
+	afterTime, afterID := cursorArgs(page)
+	rows, err := DB.Query(query, filter.QuestionID, filter.SessionID, filter.ConceptID, afterTime, afterID, page.Limit+1, owner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query quiz attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []models.QuizAttempt{}
+	for rows.Next() {
+		var a models.QuizAttempt
+		if err := scanQuizAttempt(rows, &a); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan quiz attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating quiz attempts: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(attempts) > page.Limit {
+		attempts = attempts[:page.Limit]
+		last := attempts[len(attempts)-1]
+		next = &models.Cursor{CreatedAt: last.AttemptedAt, ID: last.ID}
+	}
+
+	return attempts, next, nil
+}
+
+// GetQuizExplanation retrieves the cached extended explanation for a question.
+// Returns nil without an error when none has been generated yet.
+func GetQuizExplanation(questionID int) (*models.QuizExplanation, error) {
+	query := `
+		SELECT question_id, explanation, analogy, worked_example, created_at, updated_at
+		FROM quiz_explanations
+		WHERE question_id = $1
+	`
+
+	var e models.QuizExplanation
+	err := DB.QueryRow(query, questionID).Scan(
+		&e.QuestionID,
+		&e.Explanation,
+		&e.Analogy,
+		&e.WorkedExample,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, just not generated yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz explanation: %w", err)
+	}
+
+	return &e, nil
+}
+
+// UpsertQuizExplanation stores (or replaces) the extended explanation for a question
+func UpsertQuizExplanation(e models.QuizExplanation) (*models.QuizExplanation, error) {
+	query := `
+		INSERT INTO quiz_explanations (question_id, explanation, analogy, worked_example)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (question_id) DO UPDATE SET
+			explanation = EXCLUDED.explanation,
+			analogy = EXCLUDED.analogy,
+			worked_example = EXCLUDED.worked_example
+		RETURNING question_id, explanation, analogy, worked_example, created_at, updated_at
+	`
+
+	var saved models.QuizExplanation
+	err := DB.QueryRow(query, e.QuestionID, e.Explanation, e.Analogy, e.WorkedExample).Scan(
+		&saved.QuestionID,
+		&saved.Explanation,
+		&saved.Analogy,
+		&saved.WorkedExample,
+		&saved.CreatedAt,
+		&saved.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to save quiz explanation: %w", err)
+	}
+
+	return &saved, nil
+}
+
+// RecordQuestionLapse counts a failed answer against a question and suspends it
+// once it reaches threshold lapses. Returns true when this lapse made it a leech.
+func RecordQuestionLapse(questionID, threshold int) (bool, error) {
+	query := `
+		UPDATE quiz_questions
+		SET lapses = lapses + 1,
+			suspended_at = CASE
+				WHEN suspended_at IS NULL AND lapses + 1 >= $2 THEN NOW()
+				ELSE suspended_at
+			END
+		WHERE id = $1
+		RETURNING lapses >= $2 AND lapses - 1 < $2
+	`
+
+	var becameLeech bool
+	err := DB.QueryRow(query, questionID, threshold).Scan(&becameLeech)
+
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("quiz question not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record lapse: %w", err)
+	}
+
+	return becameLeech, nil
+}
+
+// GetLeechQuestions retrieves suspended leech questions owner can see on
+// active concepts, most lapsed first
+func GetLeechQuestions(owner *int) ([]models.QuizQuestion, error) {
+	query := `
+		SELECT q.id, q.concept_id, q.question, q.option_a, q.option_b, q.option_c, q.option_d,
+			q.correct_answer, q.explanation, q.distractor_rationales, q.lapses, q.suspended_at, q.created_at
+		FROM quiz_questions q
+		INNER JOIN concepts ON q.concept_id = concepts.id
+		WHERE q.suspended_at IS NOT NULL
+			AND ` + visibleTo("q.owner_id", 1) + `
+			AND ` + conceptActiveCondition + `
+		ORDER BY q.lapses DESC, q.suspended_at DESC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leech questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []models.QuizQuestion
+	for rows.Next() {
+		var q models.QuizQuestion
+		if err := scanQuizQuestion(rows, &q); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
+		}
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leech questions: %w", err)
+	}
+
+	return questions, nil
+}
+
+// SetQuizQuestionSuspended suspends or unsuspends a question. Unsuspending also
+// resets its lapse count so it gets a fresh start.
+func SetQuizQuestionSuspended(id int, suspended bool) (*models.QuizQuestion, error) {
+	query := `
+		UPDATE quiz_questions
+		SET suspended_at = CASE WHEN $2 THEN COALESCE(suspended_at, NOW()) ELSE NULL END,
+			lapses = CASE WHEN $2 THEN lapses ELSE 0 END
+		WHERE id = $1
+		RETURNING ` + quizQuestionColumns
+
+	var q models.QuizQuestion
+	err := scanQuizQuestion(DB.QueryRow(query, id, suspended), &q)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("quiz question not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update quiz question: %w", err)
+	}
+
+	return &q, nil
+}
+
+// CreateFlashcard creates a new flashcard
+func CreateFlashcard(card models.Flashcard) (*models.Flashcard, error) {
+	query := `
+		INSERT INTO flashcards (concept_id, question_id, front, back)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, concept_id, question_id, front, back, created_at
+	`
+
+	var created models.Flashcard
+	err := DB.QueryRow(query, card.ConceptID, card.QuestionID, card.Front, card.Back).Scan(
+		&created.ID,
+		&created.ConceptID,
+		&created.QuestionID,
+		&created.Front,
+		&created.Back,
+		&created.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create flashcard: %w", err)
+	}
+
+	return &created, nil
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CreateQuizSession creates a session and its shuffled question mappings in a single transaction
+func CreateQuizSession(session models.QuizSession, questions []models.QuizSessionQuestion) (*models.QuizSession, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	var created models.QuizSession
+	err = tx.QueryRow(`
+		INSERT INTO quiz_sessions (source_content_id, concept_id, kind, tag, collection_id, time_limit_seconds, owner_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, source_content_id, concept_id, kind, tag, collection_id, time_limit_seconds, owner_id, created_at
+	`, session.SourceContentID, session.ConceptID, session.Kind, session.Tag, session.CollectionID, session.TimeLimitSeconds, session.OwnerID).Scan(
+		&created.ID,
+		&created.SourceContentID,
+		&created.ConceptID,
+		&created.Kind,
+		&created.Tag,
+		&created.CollectionID,
+		&created.TimeLimitSeconds,
+		&created.OwnerID,
+		&created.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quiz session: %w", err)
+	}
+
+	for _, q := range questions {
+		_, err := tx.Exec(`
+			INSERT INTO quiz_session_questions (session_id, question_id, position, option_order)
+			VALUES ($1, $2, $3, $4)
+		`, created.ID, q.QuestionID, q.Position, q.OptionOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create quiz session question: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetQuizSessionByID retrieves a single quiz session by ID
+func GetQuizSessionByID(id int) (*models.QuizSession, error) {
+	query := `
+		SELECT id, source_content_id, concept_id, kind, tag, collection_id, time_limit_seconds, owner_id, created_at
+		FROM quiz_sessions
+		WHERE id = $1
+	`
+
+	var s models.QuizSession
+	err := DB.QueryRow(query, id).Scan(
+		&s.ID,
+		&s.SourceContentID,
+		&s.ConceptID,
+		&s.Kind,
+		&s.Tag,
+		&s.CollectionID,
+		&s.TimeLimitSeconds,
+		&s.OwnerID,
+		&s.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("quiz session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz session: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetQuizSessionQuestions retrieves a session's question mappings in serving order
+func GetQuizSessionQuestions(sessionID int) ([]models.QuizSessionQuestion, error) {
+	query := `
+		SELECT session_id, question_id, position, option_order
+		FROM quiz_session_questions
+		WHERE session_id = $1
+		ORDER BY position ASC
+	`
+
+	rows, err := DB.Query(query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz session questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []models.QuizSessionQuestion
+	for rows.Next() {
+		var q models.QuizSessionQuestion
+		if err := rows.Scan(&q.SessionID, &q.QuestionID, &q.Position, &q.OptionOrder); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz session question: %w", err)
+		}
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quiz session questions: %w", err)
+	}
+
+	return questions, nil
+}
+
+// GetQuizSessionQuestion retrieves the option mapping for one question in a session
+func GetQuizSessionQuestion(sessionID, questionID int) (*models.QuizSessionQuestion, error) {
+	query := `
+		SELECT session_id, question_id, position, option_order
+		FROM quiz_session_questions
+		WHERE session_id = $1 AND question_id = $2
+	`
+
+	var q models.QuizSessionQuestion
+	err := DB.QueryRow(query, sessionID, questionID).Scan(&q.SessionID, &q.QuestionID, &q.Position, &q.OptionOrder)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("question is not part of this quiz session")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz session question: %w", err)
+	}
+
+	return &q, nil
+}
+
+// GetQuizSessionAttempts retrieves all attempts made in a session, oldest first
+func GetQuizSessionAttempts(sessionID int) ([]models.QuizAttempt, error) {
+	query := `
+		SELECT ` + quizAttemptColumns + `
+		FROM quiz_attempts
+		WHERE session_id = $1
+		ORDER BY attempted_at ASC, id ASC
+	`
+
+	rows, err := DB.Query(query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiz session attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []models.QuizAttempt
+	for rows.Next() {
+		var a models.QuizAttempt
+		if err := scanQuizAttempt(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan quiz attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quiz session attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// GetQuizSessionElapsedMs returns the milliseconds since the session's most
+// recent answer, or since the session started if nothing has been answered yet.
+// Computed in the database so it matches the stored timestamps' clock.
+func GetQuizSessionElapsedMs(sessionID int) (int, error) {
+	query := `
+		SELECT (EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - COALESCE(
+			(SELECT MAX(attempted_at) FROM quiz_attempts WHERE session_id = $1),
+			created_at
+		))) * 1000)::int
+		FROM quiz_sessions
+		WHERE id = $1
+	`
+
+	var elapsed int
+	err := DB.QueryRow(query, sessionID).Scan(&elapsed)
+
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("quiz session not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query quiz session activity: %w", err)
+	}
+
+	return max(0, elapsed), nil
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// recycleSuggestionColumns is the column list scanned by scanRecycleSuggestion
+const recycleSuggestionColumns = "id, generated_content_id, reason, engagement_rate, dismissed_at, created_at"
+
+// scanRecycleSuggestion scans a row selected with recycleSuggestionColumns
+func scanRecycleSuggestion(row rowScanner, s *models.RecycleSuggestion) error {
+	return row.Scan(
+		&s.ID,
+		&s.GeneratedContentID,
+		&s.Reason,
+		&s.EngagementRate,
+		&s.DismissedAt,
+		&s.CreatedAt,
+	)
+}
+
+// GetRecyclingCandidates retrieves the published content that has no recycle
+// suggestion yet and hasn't been recycled, oldest published first
+func GetRecyclingCandidates() ([]models.GeneratedContent, error) {
+	return queryGeneratedContents(`
+		SELECT ` + generatedContentColumns + `
+		FROM generated_contents gc
+		WHERE status = 'published' AND published_at IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM recycle_suggestions rs WHERE rs.generated_content_id = gc.id)
+			AND NOT EXISTS (SELECT 1 FROM generated_contents v WHERE v.recycled_from_id = gc.id)
+		ORDER BY published_at ASC, id ASC
+	`)
+}
+
+// GetPublishedEngagement retrieves the engagement of every published post that
+// has some, with the post's platform
+func GetPublishedEngagement() (map[string][]models.ContentEngagement, error) {
+	query := `
+		SELECT platform, engagement
+		FROM generated_contents
+		WHERE status = 'published' AND engagement IS NOT NULL
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content engagement: %w", err)
+	}
+	defer rows.Close()
+
+	engagement := map[string][]models.ContentEngagement{}
+	for rows.Next() {
+		var platform string
+		var e models.ContentEngagement
+		if err := rows.Scan(&platform, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan content engagement: %w", err)
+		}
+		engagement[platform] = append(engagement[platform], e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content engagement: %w", err)
+	}
+
+	return engagement, nil
+}
+
+// GetConceptResonance retrieves the resonance of every concept with published
+// posts that have at least minImpressions, keyed by concept ID
+func GetConceptResonance(minImpressions int) (map[int]models.ConceptResonance, error) {
+	query := `
+		WITH posts AS (
+			SELECT
+				concept_ids,
+				(engagement->>'impressions')::int AS impressions,
+				((engagement->>'reactions')::int + (engagement->>'comments')::int +
+					(engagement->>'shares')::int + (engagement->>'clicks')::int)::float8 /
+					(engagement->>'impressions')::int AS rate
+			FROM generated_contents
+			WHERE status = 'published' AND engagement IS NOT NULL
+				AND (engagement->>'impressions')::int >= GREATEST($1, 1)
+		),
+		overall AS (SELECT AVG(rate) AS rate FROM posts)
+		SELECT concept.id::int, COALESCE(AVG(posts.rate) / NULLIF(overall.rate, 0), 0), COUNT(*), SUM(posts.impressions)
+		FROM posts
+		CROSS JOIN overall
+		CROSS JOIN LATERAL jsonb_array_elements_text(posts.concept_ids) AS concept(id)
+		GROUP BY concept.id, overall.rate
+	`
+
+	rows, err := DB.Query(query, minImpressions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query concept resonance: %w", err)
+	}
+	defer rows.Close()
+
+	resonance := map[int]models.ConceptResonance{}
+	for rows.Next() {
+		var id int
+		var r models.ConceptResonance
+		if err := rows.Scan(&id, &r.Score, &r.Posts, &r.Impressions); err != nil {
+			return nil, fmt.Errorf("failed to scan concept resonance: %w", err)
+		}
+		resonance[id] = r
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating concept resonance: %w", err)
+	}
+
+	return resonance, nil
+}
+
+// CreateRecycleSuggestion stores a recycle suggestion
+func CreateRecycleSuggestion(suggestion models.RecycleSuggestion) (*models.RecycleSuggestion, error) {
+	query := `
+		INSERT INTO recycle_suggestions (generated_content_id, reason, engagement_rate)
+		VALUES ($1, $2, $3)
+		RETURNING ` + recycleSuggestionColumns
+
+	var s models.RecycleSuggestion
+	err := scanRecycleSuggestion(DB.QueryRow(query, suggestion.GeneratedContentID, suggestion.Reason, suggestion.EngagementRate), &s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recycle suggestion: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetRecycleSuggestions retrieves the recycle suggestions for posts not
+// recycled since, newest first, skipping dismissed ones unless includeDismissed is set
+func GetRecycleSuggestions(includeDismissed bool) ([]models.RecycleSuggestion, error) {
+	query := `
+		SELECT ` + recycleSuggestionColumns + `
+		FROM recycle_suggestions rs
+		WHERE NOT EXISTS (SELECT 1 FROM generated_contents v WHERE v.recycled_from_id = rs.generated_content_id)
+	`
+	if !includeDismissed {
+		query += " AND dismissed_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recycle suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []models.RecycleSuggestion{}
+	for rows.Next() {
+		var s models.RecycleSuggestion
+		if err := scanRecycleSuggestion(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan recycle suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recycle suggestions: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// DismissRecycleSuggestion marks a recycle suggestion dismissed
+func DismissRecycleSuggestion(id int) (*models.RecycleSuggestion, error) {
+	query := `
+		UPDATE recycle_suggestions
+		SET dismissed_at = COALESCE(dismissed_at, NOW())
+		WHERE id = $1
+		RETURNING ` + recycleSuggestionColumns
+
+	var s models.RecycleSuggestion
+	err := scanRecycleSuggestion(DB.QueryRow(query, id), &s)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recycle suggestion not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dismiss recycle suggestion: %w", err)
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// relationshipColumns selects a relationship r with the titles of its
+// concepts f and t
+const relationshipColumns = "r.id, r.from_concept_id, f.title, r.to_concept_id, t.title, r.relationship_type, r.rationale, r.created_at"
+
+// relationshipJoins joins a relationship r to its concepts f and t
+const relationshipJoins = `
+	JOIN concepts f ON f.id = r.from_concept_id
+	JOIN concepts t ON t.id = r.to_concept_id
+`
+
+// scanConceptRelationship scans a row selected with relationshipColumns
+func scanConceptRelationship(row rowScanner, r *models.ConceptRelationship) error {
+	return row.Scan(
+		&r.ID,
+		&r.FromConceptID,
+		&r.FromTitle,
+		&r.ToConceptID,
+		&r.ToTitle,
+		&r.RelationshipType,
+		&r.Rationale,
+		&r.CreatedAt,
+	)
+}
+
+// CreateConceptRelationship links concept fromID to req's concept, both of
+// which owner must be able to see. Two concepts share at most one
+// relationship, and prerequisites can't form a cycle. Symmetric relationships
+// are stored from the lower ID.
+func CreateConceptRelationship(fromID int, req models.CreateConceptRelationshipRequest, owner *int) (*models.ConceptRelationship, error) {
+	toID := req.ToConceptID
+	if req.RelationshipType != models.RelationshipPrerequisite && fromID > toID {
+		fromID, toID = toID, fromID
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	var found int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM concepts WHERE id IN ($1, $2) AND `+visibleTo("owner_id", 3), fromID, toID, owner).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to check concepts: %w", err)
+	}
+	if found != 2 {
+		return nil, fmt.Errorf("concept not found")
+	}
+
+	if req.RelationshipType == models.RelationshipPrerequisite {
+		// from -> to closes a cycle when from is already reachable from to
+		cycleQuery := `
+			WITH RECURSIVE reachable(id) AS (
+				SELECT $1::int
+				UNION
+				SELECT r.to_concept_id
+				FROM concept_relationships r
+				JOIN reachable ON r.from_concept_id = reachable.id
+				WHERE r.relationship_type = 'prerequisite'
+			)
+			SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2)
+		`
+		var cycle bool
+		if err := tx.QueryRow(cycleQuery, toID, fromID).Scan(&cycle); err != nil {
+			return nil, fmt.Errorf("failed to check prerequisites: %w", err)
+		}
+		if cycle {
+			return nil, fmt.Errorf("relationship would create a prerequisite cycle")
+		}
+	}
+
+	query := `
+		WITH r AS (
+			INSERT INTO concept_relationships (from_concept_id, to_concept_id, relationship_type, rationale)
+			VALUES ($1, $2, $3, NULLIF($4, ''))
+			ON CONFLICT DO NOTHING
+			RETURNING *
+		)
+		SELECT ` + relationshipColumns + `
+		FROM r` + relationshipJoins
+
+	var r models.ConceptRelationship
+	err = scanConceptRelationship(tx.QueryRow(query, fromID, toID, req.RelationshipType, req.Rationale), &r)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("relationship already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relationship: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetConceptRelationships retrieves the relationships q selects between
+// concepts owner can see, oldest first
+func GetConceptRelationships(q models.ConceptRelationshipQuery, owner *int) ([]models.ConceptRelationship, error) {
+	where := "TRUE"
+	args := []interface{}{}
+
+	var clause string
+	clause, args = ownerClause("f.owner_id", owner, args)
+	where += clause
+	clause, args = ownerClause("t.owner_id", owner, args)
+	where += clause
+
+	if q.ConceptID != 0 {
+		args = append(args, q.ConceptID)
+		where += fmt.Sprintf(" AND (r.from_concept_id = $%d OR r.to_concept_id = $%d)", len(args), len(args))
+	}
+	if q.SourceContentID != 0 {
+		args = append(args, q.SourceContentID)
+		where += fmt.Sprintf(" AND f.source_content_id = $%d AND t.source_content_id = $%d", len(args), len(args))
+	}
+	if q.RelationshipType != "" {
+		args = append(args, q.RelationshipType)
+		where += fmt.Sprintf(" AND r.relationship_type = $%d", len(args))
+	}
+
+	query := `
+		SELECT ` + relationshipColumns + `
+		FROM concept_relationships r` + relationshipJoins + `
+		WHERE ` + where + `
+		ORDER BY r.created_at ASC, r.id ASC
+	`
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	relationships := []models.ConceptRelationship{}
+	for rows.Next() {
+		var r models.ConceptRelationship
+		if err := scanConceptRelationship(rows, &r); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship: %w", err)
+		}
+		relationships = append(relationships, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating relationships: %w", err)
+	}
+
+	return relationships, nil
+}
+
+// DeleteConceptRelationship deletes a relationship
+func DeleteConceptRelationship(id int) error {
+	result, err := DB.Exec(`DELETE FROM concept_relationships WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete relationship: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("relationship not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetExpiredArchivedItems lists the sources archived before cutoff, then the
+// concepts archived before cutoff or belonging to those sources
+func GetExpiredArchivedItems(cutoff time.Time) ([]models.RetentionItem, error) {
+	query := `
+		SELECT 'source_content', id, title, archived_at
+		FROM source_contents
+		WHERE archived_at < $1
+		UNION ALL
+		SELECT 'concept', c.id, c.title, COALESCE(c.archived_at, s.archived_at)
+		FROM concepts c
+		LEFT JOIN source_contents s ON c.source_content_id = s.id
+		WHERE c.archived_at < $1 OR s.archived_at < $1
+		ORDER BY 1 DESC, 2 ASC
+	`
+
+	rows, err := DB.Query(query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired archived items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.RetentionItem
+	for rows.Next() {
+		item := models.RetentionItem{Policy: models.RetentionArchived}
+		var id int
+		if err := rows.Scan(&item.Kind, &id, &item.Title, &item.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan expired archived item: %w", err)
+		}
+		item.ID = &id
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expired archived items: %w", err)
+	}
+
+	return items, nil
+}
+
+// PurgeArchivedItems deletes the given concepts and sources in a single
+// transaction. Deleting a source cascades to its concepts as well.
+func PurgeArchivedItems(conceptIDs, sourceContentIDs []int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	for _, id := range conceptIDs {
+		if _, err := tx.Exec("DELETE FROM concepts WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to purge concept %d: %w", id, err)
+		}
+	}
+
+	for _, id := range sourceContentIDs {
+		if _, err := tx.Exec("DELETE FROM source_contents WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to purge source content %d: %w", id, err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
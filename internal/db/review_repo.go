@@ -0,0 +1,157 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetReviewConcepts retrieves active concepts that are due before dueBefore, have
+// never been reviewed, or sit at or below weakMastery, optionally narrowed to a
+// tag, a source, and/or conceptIDs (when not nil), among those owner can see.
+// Overdue and weakest concepts come first.
+func GetReviewConcepts(tag *string, sourceContentID *int, conceptIDs []int, weakMastery int, dueBefore time.Time, owner *int) ([]models.Concept, error) {
+	query := `
+		SELECT ` + conceptColumns + `
+		FROM concepts
+		WHERE ` + conceptActiveCondition + `
+			AND ($1::text IS NULL OR concepts.tags ? $1)
+			AND ($2::int IS NULL OR concepts.source_content_id = $2)
+			AND ($5::int[] IS NULL OR concepts.id = ANY($5))
+			AND ` + visibleTo("concepts.owner_id", 6) + `
+			AND NOT EXISTS (
+				SELECT 1 FROM learning_progress lp
+				WHERE lp.concept_id = concepts.id
+					AND lp.next_review_at >= $4
+					AND lp.mastery_level > $3
+			)
+		ORDER BY
+			(SELECT lp.next_review_at FROM learning_progress lp WHERE lp.concept_id = concepts.id) ASC NULLS FIRST,
+			(SELECT lp.mastery_level FROM learning_progress lp WHERE lp.concept_id = concepts.id) ASC NULLS FIRST,
+			created_at ASC
+	`
+
+	rows, err := DB.Query(query, tag, sourceContentID, weakMastery, dueBefore, pq.Array(conceptIDs), owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review concepts: %w", err)
+	}
+	defer rows.Close()
+
+	var concepts []models.Concept
+	for rows.Next() {
+		var c models.Concept
+		if err := scanConcept(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan concept: %w", err)
+		}
+		concepts = append(concepts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review concepts: %w", err)
+	}
+
+	return concepts, nil
+}
+
+// dueQuestionColumns selects whether a due concept has a question q to review
+// it with, then q's quizQuestionColumns, zero values when it has none
+const dueQuestionColumns = `q.id IS NOT NULL, COALESCE(q.id, 0), COALESCE(q.concept_id, 0), COALESCE(q.question, ''),
+	COALESCE(q.option_a, ''), COALESCE(q.option_b, ''), COALESCE(q.option_c, ''), COALESCE(q.option_d, ''),
+	COALESCE(q.correct_answer, ''), COALESCE(q.explanation, ''), COALESCE(q.distractor_rationales, '{}'),
+	COALESCE(q.lapses, 0), q.suspended_at, COALESCE(q.created_at, NOW())`
+
+// GetDueReviews retrieves up to limit active concepts owner can see whose
+// next review is due before dueBefore, most overdue first, each with its
+// progress and the question GetNextReviewQuestion would pick, in one query
+func GetDueReviews(dueBefore time.Time, limit int, owner *int) ([]models.DueConcept, error) {
+	query := `
+		SELECT ` + qualified("concepts", conceptColumns) + `, ` + qualified("lp", learningProgressColumns) + `, ` + dueQuestionColumns + `
+		FROM concepts
+		INNER JOIN learning_progress lp ON lp.concept_id = concepts.id
+		LEFT JOIN LATERAL (
+			SELECT q.*
+			FROM quiz_questions q
+			WHERE q.concept_id = concepts.id AND q.suspended_at IS NULL
+			ORDER BY ` + nextReviewQuestionOrder + `
+			LIMIT 1
+		) q ON TRUE
+		WHERE lp.next_review_at < $1
+			AND ` + visibleTo("concepts.owner_id", 3) + `
+			AND ` + conceptActiveCondition + `
+		ORDER BY lp.next_review_at ASC, concepts.id ASC
+		LIMIT $2
+	`
+
+	rows, err := DB.Query(query, dueBefore, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due reviews: %w", err)
+	}
+	defer rows.Close()
+
+	due := []models.DueConcept{}
+	for rows.Next() {
+		var d models.DueConcept
+		var hasQuestion bool
+		var q models.QuizQuestion
+
+		var dests scanDests
+		scanConcept(&dests, &d.Concept)
+		scanLearningProgress(&dests, &d.Progress)
+		dests = append(dests, &hasQuestion)
+		scanQuizQuestion(&dests, &q)
+		if err := rows.Scan(dests...); err != nil {
+			return nil, fmt.Errorf("failed to scan due review: %w", err)
+		}
+
+		if hasQuestion {
+			d.Question = &q
+		}
+		due = append(due, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due reviews: %w", err)
+	}
+
+	return due, nil
+}
+
+// CountDueConcepts counts the active concepts owner can see whose next review
+// is due before dueBefore
+func CountDueConcepts(dueBefore time.Time, owner *int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM concepts
+		INNER JOIN learning_progress lp ON lp.concept_id = concepts.id
+		WHERE lp.next_review_at < $1
+			AND ` + visibleTo("concepts.owner_id", 2) + `
+			AND ` + conceptActiveCondition
+
+	var count int
+	if err := DB.QueryRow(query, dueBefore, owner).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count due concepts: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountConceptsReviewedSince counts the active concepts owner can see last
+// reviewed at or after since
+func CountConceptsReviewedSince(since time.Time, owner *int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM concepts
+		INNER JOIN learning_progress lp ON lp.concept_id = concepts.id
+		WHERE lp.last_reviewed_at >= $1
+			AND ` + visibleTo("concepts.owner_id", 2) + `
+			AND ` + conceptActiveCondition
+
+	var count int
+	if err := DB.QueryRow(query, since, owner).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count reviewed concepts: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Autocomplete returns up to limit suggestions per kind (concept titles, tags,
+// source titles) matching q. Prefix matches rank ahead of substring matches.
+// Archived concepts and sources, and those owner can't see, are never
+// suggested.
+func Autocomplete(q string, limit int, owner *int) ([]models.AutocompleteSuggestion, error) {
+	pattern := escapeLike(strings.ToLower(q))
+
+	suggestions := []models.AutocompleteSuggestion{}
+
+	// Concept titles (idx_concepts_title_trgm)
+	conceptQuery := `
+		SELECT id, title
+		FROM concepts
+		WHERE title ILIKE '%' || $1 || '%'
+			AND ` + conceptActiveCondition + `
+			AND ` + visibleTo("owner_id", 4) + `
+		ORDER BY (lower(title) LIKE $1 || '%') DESC, similarity(title, $2) DESC, title ASC
+		LIMIT $3
+	`
+	if err := appendSuggestions(&suggestions, "concept", conceptQuery, pattern, q, limit, owner); err != nil {
+		return nil, err
+	}
+
+	// Tags (idx_concepts_tags_trgm narrows to concepts whose tag list contains
+	// an element starting with the query before unnesting)
+	tagQuery := `
+		SELECT DISTINCT tag
+		FROM concepts, jsonb_array_elements_text(tags) AS tag
+		WHERE tags::text ILIKE '%"' || $1 || '%'
+			AND lower(tag) LIKE $1 || '%'
+			AND ` + conceptActiveCondition + `
+			AND ` + visibleTo("owner_id", 3) + `
+		ORDER BY tag ASC
+		LIMIT $2
+	`
+	rows, err := DB.Query(tagQuery, pattern, limit, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag suggestion: %w", err)
+		}
+		suggestions = append(suggestions, models.AutocompleteSuggestion{Type: "tag", Text: tag})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag suggestions: %w", err)
+	}
+
+	// Source titles (idx_source_contents_title_trgm)
+	sourceQuery := `
+		SELECT id, title
+		FROM source_contents
+		WHERE title ILIKE '%' || $1 || '%'
+			AND archived_at IS NULL
+			AND ` + visibleTo("owner_id", 4) + `
+		ORDER BY (lower(title) LIKE $1 || '%') DESC, similarity(title, $2) DESC, title ASC
+		LIMIT $3
+	`
+	if err := appendSuggestions(&suggestions, "source", sourceQuery, pattern, q, limit, owner); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// appendSuggestions runs an (id, text) suggestion query and appends the results
+func appendSuggestions(suggestions *[]models.AutocompleteSuggestion, kind, query, pattern, q string, limit int, owner *int) error {
+	rows, err := DB.Query(query, pattern, q, limit, owner)
+	if err != nil {
+		return fmt.Errorf("failed to query %s suggestions: %w", kind, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return fmt.Errorf("failed to scan %s suggestion: %w", kind, err)
+		}
+		*suggestions = append(*suggestions, models.AutocompleteSuggestion{Type: kind, ID: &id, Text: text})
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating %s suggestions: %w", kind, err)
+	}
+
+	return nil
+}
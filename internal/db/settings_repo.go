@@ -0,0 +1,43 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetSettingsOverrides retrieves the stored workspace setting overrides and when
+// they last changed. Returns empty overrides and a nil time if none were saved.
+func GetSettingsOverrides() (models.SettingsOverrides, *time.Time, error) {
+	var overrides models.SettingsOverrides
+	var updatedAt time.Time
+	err := DB.QueryRow("SELECT overrides, updated_at FROM workspace_settings").Scan(&overrides, &updatedAt)
+	if err == sql.ErrNoRows {
+		return models.SettingsOverrides{}, nil, nil
+	}
+	if err != nil {
+		return models.SettingsOverrides{}, nil, fmt.Errorf("failed to get workspace settings: %w", err)
+	}
+
+	return overrides, &updatedAt, nil
+}
+
+// SaveSettingsOverrides replaces the stored workspace setting overrides
+func SaveSettingsOverrides(overrides models.SettingsOverrides) (*time.Time, error) {
+	query := `
+		INSERT INTO workspace_settings (id, overrides)
+		VALUES (TRUE, $1)
+		ON CONFLICT (id) DO UPDATE
+		SET overrides = EXCLUDED.overrides, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	if err := DB.QueryRow(query, overrides).Scan(&updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save workspace settings: %w", err)
+	}
+
+	return &updatedAt, nil
+}
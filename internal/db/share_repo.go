@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// shareLinkColumns is the column list scanned by scanShareLink
+const shareLinkColumns = "token, concept_id, generated_content_id, created_at"
+
+// shareLinkOwner is the owner of a share link's item
+const shareLinkOwner = `COALESCE(
+	(SELECT owner_id FROM concepts WHERE id = share_links.concept_id),
+	(SELECT owner_id FROM generated_contents WHERE id = share_links.generated_content_id)
+)`
+
+// scanShareLink scans a row selected with shareLinkColumns
+func scanShareLink(row rowScanner, l *models.ShareLink) error {
+	return row.Scan(
+		&l.Token,
+		&l.ConceptID,
+		&l.GeneratedContentID,
+		&l.CreatedAt,
+	)
+}
+
+// CreateShareLink stores a share link, or returns the item's existing link
+// when it's already shared
+func CreateShareLink(link models.ShareLink) (*models.ShareLink, error) {
+	query := `
+		INSERT INTO share_links (token, concept_id, generated_content_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING ` + shareLinkColumns
+
+	var l models.ShareLink
+	err := scanShareLink(DB.QueryRow(query, link.Token, link.ConceptID, link.GeneratedContentID), &l)
+
+	if err == sql.ErrNoRows {
+		query = `
+			SELECT ` + shareLinkColumns + `
+			FROM share_links
+			WHERE concept_id = $1 OR generated_content_id = $2
+		`
+		err = scanShareLink(DB.QueryRow(query, link.ConceptID, link.GeneratedContentID), &l)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return &l, nil
+}
+
+// GetShareLinkByToken retrieves a share link by its token
+func GetShareLinkByToken(token string) (*models.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE token = $1
+	`
+
+	var l models.ShareLink
+	err := scanShareLink(DB.QueryRow(query, token), &l)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("share link not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share link: %w", err)
+	}
+
+	return &l, nil
+}
+
+// GetShareLinks retrieves the links to items owner can see, newest first
+func GetShareLinks(owner *int) ([]models.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE ` + visibleTo(shareLinkOwner, 1) + `
+		ORDER BY created_at DESC
+	`
+
+	rows, err := DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		var l models.ShareLink
+		if err := scanShareLink(rows, &l); err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share links: %w", err)
+	}
+
+	return links, nil
+}
+
+// DeleteShareLink revokes a share link of an item owner can see
+func DeleteShareLink(token string, owner *int) error {
+	query := "DELETE FROM share_links WHERE token = $1 AND " + visibleTo(shareLinkOwner, 2)
+
+	result, err := DB.Exec(query, token, owner)
+	if err != nil {
+		return fmt.Errorf("failed to delete share link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("share link not found")
+	}
+
+	return nil
+}
@@ -7,56 +7,101 @@ import (
 	"github.com/mostlyerror/lattice/internal/models"
 )
 
-// CreateSourceContent creates a new source content record
-func CreateSourceContent(req models.CreateSourceContentRequest) (*models.SourceContent, error) {
-	query := `
-		INSERT INTO source_contents (type, url, title, transcript, processed_at)
-		VALUES ($1, $2, $3, $4, NOW())
-		RETURNING id, type, url, title, transcript, processed_at, created_at
-	`
+// sourceContentColumns is the column list scanned by scanSourceContent
+const sourceContentColumns = "id, type, url, title, transcript, language, original_transcript, transcript_corrected_at, profile, chapters, processed_at, archived_at, created_at"
 
-	var sc models.SourceContent
-	err := DB.QueryRow(
-		query,
-		req.Type,
-		req.URL,
-		req.Title,
-		req.Transcript,
-	).Scan(
+// scanSourceContent scans a row selected with sourceContentColumns
+func scanSourceContent(row rowScanner, sc *models.SourceContent) error {
+	return row.Scan(
 		&sc.ID,
 		&sc.Type,
 		&sc.URL,
 		&sc.Title,
-		&sc.Transcript,
+		opened(&sc.Transcript, "source_contents", "transcript", &sc.ID),
+		&sc.Language,
+		openedNull(&sc.OriginalTranscript, "source_contents", "original_transcript", &sc.ID),
+		&sc.TranscriptCorrectedAt,
+		&sc.Profile,
+		openedJSON(&sc.Chapters, "source_contents", "chapters", &sc.ID),
 		&sc.ProcessedAt,
+		&sc.ArchivedAt,
 		&sc.CreatedAt,
 	)
+}
+
+// CreateSourceContent creates a new source content record, with the timed
+// segments of its caption track, owned by req.OwnerID
+func CreateSourceContent(req models.CreateSourceContentRequest) (*models.SourceContent, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	id, err := nextID(tx, "source_contents")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO source_contents (id, type, url, title, transcript, language, profile, transcript_words, chapters, owner_id, processed_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, NOW())
+		RETURNING ` + sourceContentColumns
+
+	var sc models.SourceContent
+	err = scanSourceContent(tx.QueryRow(
+		query,
+		id,
+		req.Type,
+		req.URL,
+		req.Title,
+		sealed(req.Transcript, "source_contents", "transcript", id),
+		req.Language,
+		req.Profile,
+		sealedJSON(req.Words, "source_contents", "transcript_words", id),
+		sealedJSON(req.Chapters, "source_contents", "chapters", id),
+		req.OwnerID,
+	), &sc)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create source content: %w", err)
 	}
 
+	segmentIDs, err := nextIDs(tx, "transcript_segments", len(req.Segments))
+	if err != nil {
+		return nil, err
+	}
+	for i, seg := range req.Segments {
+		_, err := tx.Exec(`
+			INSERT INTO transcript_segments (id, source_content_id, position, start_seconds, end_seconds, text)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, segmentIDs[i], sc.ID, i, seg.Start, seg.End, sealed(seg.Text, "transcript_segments", "text", segmentIDs[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transcript segment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &sc, nil
 }
 
-// GetSourceContentByURL retrieves source content by URL (for duplicate detection)
-func GetSourceContentByURL(url string) (*models.SourceContent, error) {
+// GetSourceContentByURL retrieves source content by URL (for duplicate
+// detection) among owner's sources and the shared ones; only shared ones when
+// owner is nil
+func GetSourceContentByURL(url string, owner *int) (*models.SourceContent, error) {
 	query := `
-		SELECT id, type, url, title, transcript, processed_at, created_at
+		SELECT ` + sourceContentColumns + `
 		FROM source_contents
-		WHERE url = $1
+		WHERE url = $1 AND (owner_id IS NULL OR owner_id = $2)
+		ORDER BY owner_id NULLS LAST, id
+		LIMIT 1
 	`
 
 	var sc models.SourceContent
-	err := DB.QueryRow(query, url).Scan(
-		&sc.ID,
-		&sc.Type,
-		&sc.URL,
-		&sc.Title,
-		&sc.Transcript,
-		&sc.ProcessedAt,
-		&sc.CreatedAt,
-	)
+	err := scanSourceContent(DB.QueryRow(query, url, owner), &sc)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // Not an error, just not found
@@ -68,70 +113,214 @@ func GetSourceContentByURL(url string) (*models.SourceContent, error) {
 	return &sc, nil
 }
 
-// GetAllSourceContents retrieves all source contents
-func GetAllSourceContents() ([]models.SourceContent, error) {
-	query := `
-		SELECT id, type, url, title, transcript, processed_at, created_at
+// GetSourceContents retrieves a page of source contents filtered and sorted
+// by query, skipping archived ones unless query.IncludeArchived is set. The
+// returned cursor is nil on the last page.
+func GetSourceContents(query models.SourceContentListQuery, page models.Page) ([]models.SourceContent, *models.Cursor, error) {
+	where := "TRUE"
+	args := []interface{}{}
+
+	if !query.IncludeArchived {
+		where += " AND archived_at IS NULL"
+	}
+	if query.Type != "" {
+		args = append(args, query.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	var dates, owned string
+	dates, args = dateRangeClause("created_at", query.From, query.To, args)
+	where += dates
+	owned, args = ownerClause("owner_id", query.OwnerID, args)
+	where += owned
+
+	sortColumn := "created_at"
+	if query.Sort == "processed_at" {
+		sortColumn = "processed_at"
+	}
+	var paging string
+	paging, args = pageClause(sortColumn, "id", query.Order == models.OrderAsc, page, args)
+
+	sqlQuery := `
+		SELECT ` + sourceContentColumns + `
 		FROM source_contents
-		ORDER BY created_at DESC
-	`
+		WHERE ` + where + paging
 
-	rows, err := DB.Query(query)
+	rows, err := DB.Query(sqlQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query source contents: %w", err)
+		return nil, nil, fmt.Errorf("failed to query source contents: %w", err)
 	}
 	defer rows.Close()
 
-	var contents []models.SourceContent
+	contents := []models.SourceContent{}
 	for rows.Next() {
 		var sc models.SourceContent
-		err := rows.Scan(
-			&sc.ID,
-			&sc.Type,
-			&sc.URL,
-			&sc.Title,
-			&sc.Transcript,
-			&sc.ProcessedAt,
-			&sc.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan source content: %w", err)
+		if err := scanSourceContent(rows, &sc); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan source content: %w", err)
 		}
 		contents = append(contents, sc)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating source contents: %w", err)
+		return nil, nil, fmt.Errorf("error iterating source contents: %w", err)
+	}
+
+	// The extra row only signals another page
+	var next *models.Cursor
+	if len(contents) > page.Limit {
+		contents = contents[:page.Limit]
+		last := contents[len(contents)-1]
+		next = &models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if query.Sort == "processed_at" {
+			next.CreatedAt = last.ProcessedAt
+		}
 	}
 
-	return contents, nil
+	return contents, next, nil
 }
 
 // GetSourceContentByID retrieves a single source content by ID
 func GetSourceContentByID(id int) (*models.SourceContent, error) {
 	query := `
-		SELECT id, type, url, title, transcript, processed_at, created_at
+		SELECT ` + sourceContentColumns + `
 		FROM source_contents
 		WHERE id = $1
 	`
 
 	var sc models.SourceContent
-	err := DB.QueryRow(query, id).Scan(
-		&sc.ID,
-		&sc.Type,
-		&sc.URL,
-		&sc.Title,
-		&sc.Transcript,
-		&sc.ProcessedAt,
-		&sc.CreatedAt,
-	)
+	err := scanSourceContent(DB.QueryRow(query, id), &sc)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("source content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source content: %w", err)
+	}
+
+	return &sc, nil
+}
+
+// GetTranscriptWords retrieves the word timestamps of a source's transcript,
+// nil when its transcription provider didn't return them
+func GetTranscriptWords(id int) (models.TranscriptWords, error) {
+	query := `
+		SELECT transcript_words
+		FROM source_contents
+		WHERE id = $1
+	`
+
+	var words models.TranscriptWords
+	err := DB.QueryRow(query, id).Scan(openedJSON(&words, "source_contents", "transcript_words", &id))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("source content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript words: %w", err)
+	}
+
+	return words, nil
+}
+
+// GetTranscriptSegments retrieves the timed caption segments of a source's
+// transcript in order, empty when it wasn't built from a caption track
+func GetTranscriptSegments(id int) ([]models.TimedSegment, error) {
+	query := `
+		SELECT id, start_seconds, end_seconds, text
+		FROM transcript_segments
+		WHERE source_content_id = $1
+		ORDER BY position ASC
+	`
+
+	rows, err := DB.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript segments: %w", err)
+	}
+	defer rows.Close()
+
+	segments := []models.TimedSegment{}
+	for rows.Next() {
+		var seg models.TimedSegment
+		var segmentID int
+		if err := rows.Scan(&segmentID, &seg.Start, &seg.End, opened(&seg.Text, "transcript_segments", "text", &segmentID)); err != nil {
+			return nil, fmt.Errorf("failed to scan transcript segment: %w", err)
+		}
+		segments = append(segments, seg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transcript segments: %w", err)
+	}
+
+	return segments, nil
+}
+
+// SetSourceContentArchived archives or unarchives a source content
+func SetSourceContentArchived(id int, archived bool) (*models.SourceContent, error) {
+	query := `
+		UPDATE source_contents
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) ELSE NULL END
+		WHERE id = $1
+		RETURNING ` + sourceContentColumns
+
+	var sc models.SourceContent
+	err := scanSourceContent(DB.QueryRow(query, id, archived), &sc)
 
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("source content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update source content: %w", err)
+	}
+
+	return &sc, nil
+}
+
+// UpdateSourceContentTranscript replaces a source's transcript with a corrected
+// one, keeping the transcript as first fetched in original_transcript
+func UpdateSourceContentTranscript(id int, transcript string) (*models.SourceContent, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	// Sealed values are bound to their column, so the first transcript is
+	// opened and sealed again as the original rather than copied in SQL
+	var current models.SourceContent
+	err = scanSourceContent(tx.QueryRow(`SELECT `+sourceContentColumns+` FROM source_contents WHERE id = $1 FOR UPDATE`, id), &current)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("source content not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query source content: %w", err)
 	}
+	original := current.OriginalTranscript
+	if original == nil {
+		original = &current.Transcript
+	}
+
+	query := `
+		UPDATE source_contents
+		SET original_transcript = $2,
+			transcript = $3,
+			transcript_corrected_at = NOW()
+		WHERE id = $1
+		RETURNING ` + sourceContentColumns
+
+	var sc models.SourceContent
+	err = scanSourceContent(tx.QueryRow(
+		query,
+		id,
+		sealed(*original, "source_contents", "original_transcript", id),
+		sealed(transcript, "source_contents", "transcript", id),
+	), &sc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transcript: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return &sc, nil
 }
@@ -156,3 +345,110 @@ func DeleteSourceContent(id int) error {
 
 	return nil
 }
+
+// PurgeSourceContent deletes a source content like DeleteSourceContent, along
+// with the generated content drawn only from its concepts, which deleting the
+// source alone keeps. Returns how many pieces of content were deleted.
+func PurgeSourceContent(id int) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	result, err := tx.Exec(`
+		DELETE FROM generated_contents g
+		WHERE jsonb_array_length(g.concept_ids) > 0
+			AND NOT EXISTS (
+				SELECT 1 FROM jsonb_array_elements(g.concept_ids) e
+				WHERE NOT EXISTS (SELECT 1 FROM concepts c WHERE to_jsonb(c.id) = e AND c.source_content_id = $1)
+			)
+	`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete generated content: %w", err)
+	}
+	deletedContent, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	result, err = tx.Exec("DELETE FROM source_contents WHERE id = $1", id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete source content: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("source content not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(deletedContent), nil
+}
+
+// CountSourceContents counts every source content, archived ones included
+func CountSourceContents() (int, error) {
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM source_contents").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count source contents: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteSourceContents deletes the sources req selects, with their concepts and
+// quizzes by cascade. On a dry run it only counts them.
+func DeleteSourceContents(req models.BulkDeleteSourceContentsRequest, dryRun bool) (*models.BulkDeleteResult, error) {
+	where := "created_at < $1"
+	args := []interface{}{req.Before}
+
+	if req.Type != "" {
+		args = append(args, req.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if req.Archived {
+		where += " AND archived_at IS NOT NULL"
+	}
+	var owned string
+	owned, args = ownerClause("owner_id", req.OwnerID, args)
+	where += owned
+
+	return bulkDelete("source_contents", where, args, map[string]string{
+		"concepts": "SELECT COUNT(*) FROM concepts WHERE source_content_id IN (SELECT id FROM matched)",
+		"quiz_questions": `SELECT COUNT(*) FROM quiz_questions q JOIN concepts c ON q.concept_id = c.id
+			WHERE c.source_content_id IN (SELECT id FROM matched)`,
+	}, dryRun)
+}
+
+// GetSourceContentSummaryByID retrieves source content metadata without the transcript
+func GetSourceContentSummaryByID(id int) (*models.SourceContentSummary, error) {
+	query := `
+		SELECT id, type, url, title, processed_at, archived_at, created_at
+		FROM source_contents
+		WHERE id = $1
+	`
+
+	var sc models.SourceContentSummary
+	err := DB.QueryRow(query, id).Scan(
+		&sc.ID,
+		&sc.Type,
+		&sc.URL,
+		&sc.Title,
+		&sc.ProcessedAt,
+		&sc.ArchivedAt,
+		&sc.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("source content not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source content: %w", err)
+	}
+
+	return &sc, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetQuestionAttemptStats retrieves attempt counts, average answer time, and
+// option selection counts for a question
+func GetQuestionAttemptStats(questionID int) (*models.QuestionStats, error) {
+	query := `
+		SELECT COUNT(id),
+			COUNT(id) FILTER (WHERE correct),
+			MAX(attempted_at),
+			AVG(time_to_answer_ms)::float8
+		FROM quiz_attempts
+		WHERE question_id = $1
+	`
+
+	stats := models.QuestionStats{
+		QuestionID:   questionID,
+		OptionCounts: map[string]int{"A": 0, "B": 0, "C": 0, "D": 0},
+	}
+	err := DB.QueryRow(query, questionID).Scan(
+		&stats.Attempts.TotalAttempts,
+		&stats.Attempts.CorrectAttempts,
+		&stats.Attempts.LastAttemptedAt,
+		&stats.AvgTimeToAnswerMs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query question stats: %w", err)
+	}
+
+	if stats.Attempts.TotalAttempts > 0 {
+		stats.Attempts.Accuracy = float64(stats.Attempts.CorrectAttempts) / float64(stats.Attempts.TotalAttempts)
+	}
+
+	rows, err := DB.Query(`
+		SELECT selected_answer, COUNT(*)
+		FROM quiz_attempts
+		WHERE question_id = $1
+		GROUP BY selected_answer
+	`, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query option counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var letter string
+		var count int
+		if err := rows.Scan(&letter, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan option count: %w", err)
+		}
+		stats.OptionCounts[letter] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating option counts: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetDiscriminationSamples retrieves, for every session attempt at a question,
+// whether it was correct and the accuracy on the session's other questions
+func GetDiscriminationSamples(questionID int) ([]models.DiscriminationSample, error) {
+	query := `
+		SELECT a.correct, AVG(o.correct::int)::float8
+		FROM quiz_attempts a
+		INNER JOIN quiz_attempts o ON o.session_id = a.session_id AND o.question_id <> a.question_id
+		WHERE a.question_id = $1 AND a.session_id IS NOT NULL
+		GROUP BY a.id, a.correct
+	`
+
+	rows, err := DB.Query(query, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discrimination samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []models.DiscriminationSample
+	for rows.Next() {
+		var s models.DiscriminationSample
+		if err := rows.Scan(&s.Correct, &s.RestScore); err != nil {
+			return nil, fmt.Errorf("failed to scan discrimination sample: %w", err)
+		}
+		samples = append(samples, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating discrimination samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// GetConceptAverageTimeToAnswer retrieves the mean answer time across a concept's
+// questions. Returns nil when no attempt reported a time.
+func GetConceptAverageTimeToAnswer(conceptID int) (*float64, error) {
+	query := `
+		SELECT AVG(a.time_to_answer_ms)::float8
+		FROM quiz_attempts a
+		INNER JOIN quiz_questions q ON a.question_id = q.id
+		WHERE q.concept_id = $1
+	`
+
+	var avg *float64
+	if err := DB.QueryRow(query, conceptID).Scan(&avg); err != nil {
+		return nil, fmt.Errorf("failed to query average answer time: %w", err)
+	}
+
+	return avg, nil
+}
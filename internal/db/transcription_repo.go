@@ -0,0 +1,156 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// pendingTranscriptionColumns is the column list scanned by scanPendingTranscription
+const pendingTranscriptionColumns = `id, token, provider, title, credential, spec, scrub, status, job_id, error,
+	source_content_id, owner_id, created_at, completed_at`
+
+// scanPendingTranscription scans a row selected with pendingTranscriptionColumns
+func scanPendingTranscription(row rowScanner, t *models.PendingTranscription) error {
+	return row.Scan(
+		&t.ID,
+		&t.Token,
+		&t.Provider,
+		&t.Title,
+		&t.Credential,
+		&t.Spec,
+		&t.Scrub,
+		&t.Status,
+		&t.JobID,
+		&t.Error,
+		&t.SourceContentID,
+		&t.OwnerID,
+		&t.CreatedAt,
+		&t.CompletedAt,
+	)
+}
+
+// CreatePendingTranscription stores a source waiting on an external transcription
+func CreatePendingTranscription(t models.PendingTranscription) (*models.PendingTranscription, error) {
+	query := `
+		INSERT INTO pending_transcriptions (token, provider, title, credential, spec, scrub, owner_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + pendingTranscriptionColumns
+
+	var created models.PendingTranscription
+	err := scanPendingTranscription(DB.QueryRow(query, t.Token, t.Provider, t.Title, t.Credential, t.Spec, t.Scrub, t.OwnerID), &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pending transcription: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetPendingTranscriptionByID retrieves a pending transcription by ID
+func GetPendingTranscriptionByID(id int) (*models.PendingTranscription, error) {
+	return getPendingTranscription("id = $1", id)
+}
+
+// GetPendingTranscriptionByToken retrieves a pending transcription by its callback token
+func GetPendingTranscriptionByToken(token string) (*models.PendingTranscription, error) {
+	return getPendingTranscription("token = $1", token)
+}
+
+// getPendingTranscription retrieves the pending transcription matching where
+func getPendingTranscription(where string, arg any) (*models.PendingTranscription, error) {
+	query := `
+		SELECT ` + pendingTranscriptionColumns + `
+		FROM pending_transcriptions
+		WHERE ` + where
+
+	var t models.PendingTranscription
+	err := scanPendingTranscription(DB.QueryRow(query, arg), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pending transcription not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending transcription: %w", err)
+	}
+
+	return &t, nil
+}
+
+// GetPendingTranscriptions retrieves pending transcriptions, newest first,
+// only those with status when it's set
+func GetPendingTranscriptions(status string) ([]models.PendingTranscription, error) {
+	query := `
+		SELECT ` + pendingTranscriptionColumns + `
+		FROM pending_transcriptions
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := DB.Query(query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending transcriptions: %w", err)
+	}
+	defer rows.Close()
+
+	transcriptions := []models.PendingTranscription{}
+	for rows.Next() {
+		var t models.PendingTranscription
+		if err := scanPendingTranscription(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan pending transcription: %w", err)
+		}
+		transcriptions = append(transcriptions, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending transcriptions: %w", err)
+	}
+
+	return transcriptions, nil
+}
+
+// ClaimPendingTranscription moves a pending transcription to processing and
+// records the provider's job, returning nil when it isn't pending, so a
+// repeated callback is only processed once
+func ClaimPendingTranscription(id int, jobID string) (*models.PendingTranscription, error) {
+	query := `
+		UPDATE pending_transcriptions
+		SET status = 'processing', job_id = NULLIF($2, '')
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + pendingTranscriptionColumns
+
+	var t models.PendingTranscription
+	err := scanPendingTranscription(DB.QueryRow(query, id, jobID), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending transcription: %w", err)
+	}
+
+	return &t, nil
+}
+
+// FinishPendingTranscription records the outcome of a pending transcription:
+// the source it became, or why it failed
+func FinishPendingTranscription(id int, sourceContentID *int, failure *string) (*models.PendingTranscription, error) {
+	query := `
+		UPDATE pending_transcriptions
+		SET status = CASE WHEN $3::text IS NULL THEN 'completed' ELSE 'failed' END,
+			source_content_id = $2, error = $3, completed_at = NOW()
+		WHERE id = $1
+		RETURNING ` + pendingTranscriptionColumns
+
+	var t models.PendingTranscription
+	err := scanPendingTranscription(DB.QueryRow(query, id, sourceContentID, failure), &t)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pending transcription not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish pending transcription: %w", err)
+	}
+
+	return &t, nil
+}
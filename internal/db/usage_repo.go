@@ -0,0 +1,234 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// usageEventColumns is the column list scanned by scanUsageEvent
+const usageEventColumns = `id, tenant, metric, quantity, source_content_id, created_at, reported_at`
+
+// scanUsageEvent scans a row selected with usageEventColumns
+func scanUsageEvent(row rowScanner, event *models.UsageEvent) error {
+	return row.Scan(
+		&event.ID,
+		&event.Tenant,
+		&event.Metric,
+		&event.Quantity,
+		&event.SourceContentID,
+		&event.CreatedAt,
+		&event.ReportedAt,
+	)
+}
+
+// RecordUsageEvent records quantity of a metric used by tenant
+func RecordUsageEvent(tenant, metric string, quantity int64, sourceContentID *int) error {
+	query := `
+		INSERT INTO usage_events (tenant, metric, quantity, source_content_id)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := DB.Exec(query, tenant, metric, quantity, sourceContentID); err != nil {
+		return fmt.Errorf("failed to record usage event: %w", err)
+	}
+	return nil
+}
+
+// GetUnreportedUsageEvents retrieves up to limit of the oldest events the
+// billing sink hasn't taken
+func GetUnreportedUsageEvents(limit int) ([]models.UsageEvent, error) {
+	query := `
+		SELECT ` + usageEventColumns + `
+		FROM usage_events
+		WHERE reported_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := DB.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.UsageEvent{}
+	for rows.Next() {
+		var event models.UsageEvent
+		if err := scanUsageEvent(rows, &event); err != nil {
+			return nil, fmt.Errorf("failed to scan usage event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage events: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountUnreportedUsageEvents counts the events the billing sink hasn't taken
+func CountUnreportedUsageEvents() (int, error) {
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM usage_events WHERE reported_at IS NULL`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unreported usage events: %w", err)
+	}
+	return count, nil
+}
+
+// SumUsageSince totals a tenant's usage of metric since the given time
+func SumUsageSince(tenant, metric string, since time.Time) (int64, error) {
+	var total int64
+	err := DB.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0)::bigint
+		FROM usage_events
+		WHERE tenant = $1 AND metric = $2 AND created_at >= $3
+	`, tenant, metric, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum %s usage: %w", metric, err)
+	}
+	return total, nil
+}
+
+// MarkUsageEventsReported records that the billing sink took the events
+func MarkUsageEventsReported(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := DB.Exec(`UPDATE usage_events SET reported_at = NOW() WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to mark usage events reported: %w", err)
+	}
+	return nil
+}
+
+// GetUsageTotals sums usage from from (inclusive) to to (exclusive) per
+// tenant, metric, and UTC day, or month when monthly is set. An empty tenant
+// includes every tenant.
+func GetUsageTotals(from, to time.Time, monthly bool, tenant string) ([]models.UsageTotal, error) {
+	format := "YYYY-MM-DD"
+	if monthly {
+		format = "YYYY-MM"
+	}
+
+	query := `
+		SELECT tenant, metric, to_char(created_at AT TIME ZONE 'UTC', $3) AS period, SUM(quantity)::bigint
+		FROM usage_events
+		WHERE created_at >= $1 AND created_at < $2
+			AND ($4 = '' OR tenant = $4)
+		GROUP BY tenant, metric, period
+		ORDER BY tenant, period, metric
+	`
+
+	rows, err := DB.Query(query, from, to, format, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []models.UsageTotal{}
+	for rows.Next() {
+		var total models.UsageTotal
+		if err := rows.Scan(&total.Tenant, &total.Metric, &total.Period, &total.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan usage total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// RecordLLMUsage adds one Claude response's tokens to today's totals for model
+func RecordLLMUsage(model string, inputTokens, outputTokens int) error {
+	query := `
+		INSERT INTO llm_usage (day, model, requests, input_tokens, output_tokens)
+		VALUES ((NOW() AT TIME ZONE 'UTC')::date, $1, 1, $2, $3)
+		ON CONFLICT (day, model) DO UPDATE
+		SET requests = llm_usage.requests + 1,
+			input_tokens = llm_usage.input_tokens + EXCLUDED.input_tokens,
+			output_tokens = llm_usage.output_tokens + EXCLUDED.output_tokens
+	`
+
+	if _, err := DB.Exec(query, model, inputTokens, outputTokens); err != nil {
+		return fmt.Errorf("failed to record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// GetLLMUsage sums Claude usage from from (inclusive) to to (exclusive) per
+// model and UTC day, or month when monthly is set
+func GetLLMUsage(from, to time.Time, monthly bool) ([]models.LLMSpend, error) {
+	format := "YYYY-MM-DD"
+	if monthly {
+		format = "YYYY-MM"
+	}
+
+	query := `
+		SELECT to_char(day, $3) AS period, model,
+			SUM(requests)::bigint, SUM(input_tokens)::bigint, SUM(output_tokens)::bigint
+		FROM llm_usage
+		WHERE day >= $1::date AND day < $2::date
+		GROUP BY period, model
+		ORDER BY period, model
+	`
+
+	rows, err := DB.Query(query, from, to, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM usage: %w", err)
+	}
+	defer rows.Close()
+
+	spend := []models.LLMSpend{}
+	for rows.Next() {
+		var s models.LLMSpend
+		if err := rows.Scan(&s.Period, &s.Model, &s.Requests, &s.InputTokens, &s.OutputTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM usage: %w", err)
+		}
+		spend = append(spend, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating LLM usage: %w", err)
+	}
+
+	return spend, nil
+}
+
+// GetUsageTenants retrieves every tenant with metered usage and when it was
+// first and last metered, ordered by name
+func GetUsageTenants() ([]models.Tenant, error) {
+	query := `
+		SELECT tenant, MIN(created_at), MAX(created_at)
+		FROM usage_events
+		GROUP BY tenant
+		ORDER BY tenant
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []models.Tenant{}
+	for rows.Next() {
+		var tenant models.Tenant
+		if err := rows.Scan(&tenant.Name, &tenant.FirstUsageAt, &tenant.LastUsageAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
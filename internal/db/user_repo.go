@@ -0,0 +1,281 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// userColumns is the column list scanned by scanUser
+const userColumns = "id, email, name, role, timezone, created_at, last_login_at"
+
+// scanUser scans a row selected with userColumns
+func scanUser(row rowScanner, u *models.User) error {
+	return row.Scan(
+		&u.ID,
+		&u.Email,
+		&u.Name,
+		&u.Role,
+		&u.Timezone,
+		&u.CreatedAt,
+		&u.LastLoginAt,
+	)
+}
+
+// GetUserByID retrieves a user by ID with their linked identities
+func GetUserByID(id int) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1
+	`
+
+	var u models.User
+	err := scanUser(DB.QueryRow(query, id), &u)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user identities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user identity: %w", err)
+		}
+		u.Identities = append(u.Identities, identity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user identities: %w", err)
+	}
+
+	return &u, nil
+}
+
+// GetUserIDByIdentity returns the user linked to a provider account.
+// Returns nil without an error when the identity isn't linked.
+func GetUserIDByIdentity(provider, subject string) (*int, error) {
+	var userID int
+	err := DB.QueryRow(
+		"SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2",
+		provider, subject,
+	).Scan(&userID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, just not linked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user identity: %w", err)
+	}
+
+	return &userID, nil
+}
+
+// GetUserIDByEmail returns the user with an email, compared case-insensitively.
+// Returns nil without an error when there's none.
+func GetUserIDByEmail(email string) (*int, error) {
+	var userID int
+	err := DB.QueryRow("SELECT id FROM users WHERE LOWER(email) = LOWER($1)", email).Scan(&userID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not an error, no such user
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return &userID, nil
+}
+
+// CreateUser creates a user signing in for the first time. While no user or
+// API token exists, the user becomes an admin. After that, users are only
+// created when signup is set, as viewers.
+func CreateUser(email *string, name string, signup bool) (int, error) {
+	query := `
+		WITH workspace AS (
+			SELECT EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM api_tokens) AS claimed
+		)
+		INSERT INTO users (email, name, role)
+		SELECT $1, $2, CASE WHEN claimed THEN 'viewer' ELSE 'admin' END
+		FROM workspace
+		WHERE $3 OR NOT claimed
+		RETURNING id
+	`
+
+	var id int
+	err := DB.QueryRow(query, email, name, signup).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("sign-up is closed")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return id, nil
+}
+
+// InviteUser creates a user who hasn't signed in yet. Signing in with a
+// verified email matching theirs links the identity to them.
+func InviteUser(req models.InviteUserRequest) (*models.User, error) {
+	query := `
+		INSERT INTO users (email, name, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT ((LOWER(email))) WHERE email IS NOT NULL DO NOTHING
+		RETURNING ` + userColumns
+
+	var u models.User
+	err := scanUser(DB.QueryRow(query, req.Email, req.Name, req.Role), &u)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user email already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to invite user: %w", err)
+	}
+
+	return &u, nil
+}
+
+// LinkUserIdentity links a provider account to a user
+func LinkUserIdentity(userID int, provider, subject string, email *string) error {
+	_, err := DB.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, $4)
+	`, userID, provider, subject, email)
+	if err != nil {
+		return fmt.Errorf("failed to link user identity: %w", err)
+	}
+
+	return nil
+}
+
+// RecordUserLogin sets a user's last login time
+func RecordUserLogin(userID int) error {
+	if _, err := DB.Exec("UPDATE users SET last_login_at = NOW() WHERE id = $1", userID); err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+	return nil
+}
+
+// GetUsers retrieves all users, oldest first
+func GetUsers() ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		ORDER BY id ASC
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserRole returns a user's role
+func GetUserRole(id int) (string, error) {
+	var role string
+	err := DB.QueryRow("SELECT role FROM users WHERE id = $1", id).Scan(&role)
+
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query user role: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateUserRole changes a user's role. The last admin can't be demoted.
+func UpdateUserRole(id int, role string) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET role = $2
+		WHERE id = $1
+			AND ($2 = 'admin' OR EXISTS (SELECT 1 FROM users o WHERE o.role = 'admin' AND o.id <> $1))
+		RETURNING ` + userColumns
+
+	var u models.User
+	err := scanUser(DB.QueryRow(query, id, role), &u)
+
+	if err == sql.ErrNoRows {
+		if _, err := GetUserRole(id); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("cannot demote the last admin")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	return &u, nil
+}
+
+// GetUserTimezone returns a user's timezone setting.
+// Returns nil without an error when they haven't set one.
+func GetUserTimezone(id int) (*string, error) {
+	var timezone *string
+	err := DB.QueryRow("SELECT timezone FROM users WHERE id = $1", id).Scan(&timezone)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user timezone: %w", err)
+	}
+
+	return timezone, nil
+}
+
+// UpdateUserSettings changes a user's own settings
+func UpdateUserSettings(id int, req models.UpdateUserSettingsRequest) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET timezone = COALESCE($2, timezone)
+		WHERE id = $1
+		RETURNING ` + userColumns
+
+	var u models.User
+	err := scanUser(DB.QueryRow(query, id, req.Timezone), &u)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user settings: %w", err)
+	}
+
+	return &u, nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetActionItems handles GET /api/action-items?status=&source_content_id=
+// Returns action items across the sources the caller can see, optionally
+// filtered
+func GetActionItems(c *gin.Context) {
+	var sourceID *int
+	if sourceStr := c.Query("source_content_id"); sourceStr != "" {
+		parsed, err := strconv.Atoi(sourceStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid source_content_id",
+				"details": "source_content_id must be a number",
+			})
+			return
+		}
+		sourceID = &parsed
+	}
+
+	var status *string
+	if statusStr := c.Query("status"); statusStr != "" {
+		if statusStr != models.ActionItemOpen && statusStr != models.ActionItemDone && statusStr != models.ActionItemDismissed {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid status",
+				"details": "status must be one of: open, done, dismissed",
+			})
+			return
+		}
+		status = &statusStr
+	}
+
+	items, err := db.GetActionItems(sourceID, status, callerScope(c))
+	if err != nil {
+		log.Printf("Error getting action items: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve action items",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"action_items": items,
+		"count":        len(items),
+	})
+}
+
+// UpdateActionItem handles PATCH /api/action-items/:id
+// Checks off, dismisses, or reopens an action item
+func UpdateActionItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.UpdateActionItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := db.UpdateActionItemStatus(id, req.Status)
+	if err != nil {
+		if err.Error() == "action item not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Action item not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error updating action item %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update action item",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
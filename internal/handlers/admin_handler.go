@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// RequireFeature rejects requests with 503 while feature is turned off
+func RequireFeature(feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !services.FeatureEnabled(feature) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Feature disabled",
+				"details": fmt.Sprintf("the %s feature is turned off by an administrator", feature),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetTenants handles GET /api/admin/tenants
+// Lists this deployment's tenant, with its users and sources, and any other
+// tenant usage was metered for under an earlier BILLING_TENANT
+func GetTenants(c *gin.Context) {
+	tenants, err := services.GetTenants()
+	if err != nil {
+		log.Printf("Error listing tenants: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve tenants",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tenants": tenants,
+		"count":   len(tenants),
+	})
+}
+
+// GetJobQueue handles GET /api/admin/jobs
+// Returns a page of background jobs newest first, optionally only those of
+// ?status=, with how many there are of each status. Pass next_cursor back as
+// ?cursor= for the following page.
+func GetJobQueue(c *gin.Context) {
+	var query models.JobQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	jobs, next, counts, err := services.GetJobQueue(query, page)
+	if err != nil {
+		log.Printf("Error listing jobs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":        jobs,
+		"count":       len(jobs),
+		"counts":      counts,
+		"processing":  services.FeatureEnabled(models.FeatureJobProcessing),
+		"next_cursor": encodeCursor(next),
+	})
+}
+
+// GetLLMSpend handles GET /api/admin/spend
+// Returns Claude requests, tokens, and their estimated cost at list prices
+// per model and day or month
+func GetLLMSpend(c *gin.Context) {
+	var query models.LLMSpendQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	report, err := services.LLMSpendReport(query)
+	if errors.Is(err, services.ErrInvalidUsageRange) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error totalling LLM spend: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to total LLM spend",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAuthEvents handles GET /api/admin/auth-events
+// Returns a page of sign-ins, token uses, and rejected credentials newest
+// first, filtered by ?event=, ?success=, ?user_id=, ?api_token_id=, and ?ip=
+func GetAuthEvents(c *gin.Context) {
+	var query models.AuthEventQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	events, next, err := db.GetAuthEvents(query, page)
+	if err != nil {
+		log.Printf("Error listing auth events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve auth events",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":      events,
+		"count":       len(events),
+		"next_cursor": encodeCursor(next),
+	})
+}
+
+// GetFeatures handles GET /api/admin/features
+// Returns the feature flags in effect and those set through the API
+func GetFeatures(c *gin.Context) {
+	features, err := services.GetFeatures()
+	if err != nil {
+		log.Printf("Error getting features: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve features",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, features)
+}
+
+// UpdateFeatures handles PATCH /api/admin/features
+// Turns the given feature flags on or off and resets those listed in "reset"
+// to the environment's values. Changes apply immediately; idle job workers
+// notice job_processing within their poll interval.
+func UpdateFeatures(c *gin.Context) {
+	var req models.UpdateFeaturesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	features, err := services.UpdateFeatures(req)
+	if err != nil {
+		log.Printf("Error updating features: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update features",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, features)
+}
+
+// RotateEncryption handles POST /api/admin/encryption/rotate
+// Re-encrypts transcripts and generated content under the current master key,
+// encrypting any stored as plaintext, or decrypts them all when encryption at
+// rest is off
+func RotateEncryption(c *gin.Context) {
+	result, err := services.RotateEncryption()
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Encryption is not configured",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error rotating encryption: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to rotate encryption",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReprocessSourceContent handles POST /api/admin/source-content/:id/reprocess
+// Runs a source's whole pipeline again from its transcript, archiving its
+// concepts, however its last run ended
+func ReprocessSourceContent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	result, err := sourceContentService.ReprocessSource(c.Request.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error reprocessing source content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to reprocess source content",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PurgeSourceContent handles DELETE /api/admin/source-content/:id
+// Deletes a source like DELETE /api/source-content/:id, and also the
+// generated content drawn only from it
+func PurgeSourceContent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	deletedContent, err := services.PurgeSourceContent(c.Request.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error purging source content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete source content",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Source content deleted successfully",
+		"id":              id,
+		"deleted_content": deletedContent,
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var ankiService *services.AnkiService
+
+// InitAnkiService initializes the Anki sync service
+func InitAnkiService() {
+	ankiService = services.NewAnkiService()
+}
+
+// GetAnkiSyncState handles GET /api/anki/sync
+// Returns the last imported review id and pending/linked note counts
+func GetAnkiSyncState(c *gin.Context) {
+	state, err := ankiService.GetSyncState(c.Request.Context(), callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve sync state",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetPendingAnkiNotes handles GET /api/anki/notes/pending
+// Returns cards not yet in Anki, ready for AnkiConnect addNotes
+func GetPendingAnkiNotes(c *gin.Context) {
+	notes, err := ankiService.PendingNotes(c.Request.Context(), callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve pending notes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notes": notes,
+		"count": len(notes),
+	})
+}
+
+// LinkAnkiNotes handles POST /api/anki/notes/links
+// Records the Anki note IDs returned by addNotes so the cards aren't sent again
+func LinkAnkiNotes(c *gin.Context) {
+	var req models.LinkAnkiNotesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := ankiService.LinkNotes(c.Request.Context(), req.Links, callerScope(c)); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ref") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to link notes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"linked": len(req.Links),
+	})
+}
+
+// SyncAnkiReviews handles POST /api/anki/reviews
+// Imports reviews done in Anki and updates mastery for the linked concepts
+func SyncAnkiReviews(c *gin.Context) {
+	var req models.SyncAnkiReviewsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := ankiService.ApplyReviews(c.Request.Context(), req.Reviews, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to sync reviews",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/middleware"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetAPITokens handles GET /api/tokens
+func GetAPITokens(c *gin.Context) {
+	tokens, err := db.GetAPITokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve API tokens",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+		"count":  len(tokens),
+	})
+}
+
+// CreateAPIToken handles POST /api/tokens
+// Returns the token value once; only its hash is stored
+func CreateAPIToken(c *gin.Context) {
+	var req models.CreateAPITokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create API token",
+			"details": err.Error(),
+		})
+		return
+	}
+	raw := middleware.APITokenPrefix + hex.EncodeToString(secret)
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		t := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, err := db.CreateAPIToken(req.Name, middleware.HashAPIToken(raw), raw[:len(middleware.APITokenPrefix)+8], req.Scopes, allowedCIDRs(req.AllowedIPs), expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create API token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreatedAPIToken{APIToken: *token, Token: raw})
+}
+
+// UpdateAPIToken handles PATCH /api/tokens/:id
+// Replaces the addresses a token is accepted from; an empty allowed_ips
+// accepts any address
+func UpdateAPIToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.UpdateAPITokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	token, err := db.UpdateAPITokenAllowedIPs(id, allowedCIDRs(req.AllowedIPs))
+	if err != nil {
+		if err.Error() == "api token not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "API token not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update API token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// allowedCIDRs normalizes validated addresses and CIDR ranges to CIDR ranges,
+// a single address becoming a /32 or /128
+func allowedCIDRs(entries []string) []string {
+	cidrs := []string{}
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			cidrs = append(cidrs, network.String())
+			continue
+		}
+		ip := net.ParseIP(entry)
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		cidrs = append(cidrs, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+	}
+	return cidrs
+}
+
+// RevokeAPIToken handles DELETE /api/tokens/:id
+func RevokeAPIToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	token, err := db.RevokeAPIToken(id)
+	if err != nil {
+		if err.Error() == "api token not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "API token not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke API token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
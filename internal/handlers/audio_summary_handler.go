@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/tts"
+)
+
+// GenerateAudioSummary handles POST /api/source-content/:id/audio-summary
+// Reads the source's summary and concepts aloud with the configured
+// text-to-speech provider, replacing any earlier audio summary
+func GenerateAudioSummary(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := services.GenerateAudioSummary(c.Request.Context(), id)
+	if err != nil {
+		respondAudioSummaryError(c, "Failed to generate audio summary", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, summary)
+}
+
+// GetAudioSummary handles GET /api/source-content/:id/audio-summary
+// Returns the source's audio summary with a signed download URL
+func GetAudioSummary(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := services.GetAudioSummary(c.Request.Context(), id)
+	if err != nil {
+		respondAudioSummaryError(c, "Failed to retrieve audio summary", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// DeleteAudioSummary handles DELETE /api/source-content/:id/audio-summary
+// Deletes the source's audio summary, removing it from the podcast feed
+func DeleteAudioSummary(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteAudioSummary(c.Request.Context(), id); err != nil {
+		respondAudioSummaryError(c, "Failed to delete audio summary", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Audio summary deleted successfully",
+	})
+}
+
+// GetPodcastFeed handles GET /api/podcast/feed.xml
+// Returns the newest audio summaries as a podcast RSS feed. Podcast apps
+// can't send headers, so it also accepts a read-only API token in ?token=.
+func GetPodcastFeed(c *gin.Context) {
+	feed, err := services.RenderPodcastFeed(c.Request.Context(), publicBaseURL(c))
+	if err != nil {
+		log.Printf("Error rendering podcast feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to render podcast feed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", feed)
+}
+
+// respondAudioSummaryError maps audio summary errors to responses
+func respondAudioSummaryError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, tts.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Text-to-speech is not configured",
+			"details": err.Error(),
+		})
+	case errors.Is(err, tts.ErrUnauthorized):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Provider rejected the API key",
+			"details": err.Error(),
+		})
+	case err.Error() == "source content not found", err.Error() == "audio summary not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "source has no concepts":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Source has no concepts",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
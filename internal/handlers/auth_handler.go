@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/auth"
+	"github.com/mostlyerror/lattice/internal/middleware"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// oauthStateCookie carries the login's state value to the callback
+const oauthStateCookie = "lattice_oauth_state"
+
+// GetAuthProviders handles GET /auth/providers
+// Lists the configured login providers
+func GetAuthProviders(c *gin.Context) {
+	names := make([]string, 0, len(auth.Providers))
+	for name := range auth.Providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	c.JSON(http.StatusOK, gin.H{
+		"providers": names,
+		"count":     len(names),
+	})
+}
+
+// Login handles GET /auth/:provider/login
+// Redirects to the provider's consent page
+func Login(c *gin.Context) {
+	provider, ok := auth.Providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Login provider not found",
+			"details": "provider " + strconv.Quote(c.Param("provider")) + " is not configured",
+		})
+		return
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start login",
+			"details": err.Error(),
+		})
+		return
+	}
+	state := hex.EncodeToString(stateBytes)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, 600, "/auth", "", isSecureRequest(c), true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, callbackURL(c, provider.Name)))
+}
+
+// LoginCallback handles GET /auth/:provider/callback
+// Completes the login, linking the identity to the signed-in user if there is
+// one, and issues a session. Redirects to AUTH_SUCCESS_REDIRECT when set;
+// otherwise responds with the session.
+func LoginCallback(c *gin.Context) {
+	provider, ok := auth.Providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Login provider not found",
+			"details": "provider " + strconv.Quote(c.Param("provider")) + " is not configured",
+		})
+		return
+	}
+
+	if errParam := c.Query("error"); errParam != "" {
+		recordLoginFailure(c, provider.Name, errParam)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Login was not completed",
+			"details": errParam,
+		})
+		return
+	}
+
+	state, err := c.Cookie(oauthStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		recordLoginFailure(c, provider.Name, "state does not match")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid login state",
+			"details": "state does not match; start the login again",
+		})
+		return
+	}
+	c.SetCookie(oauthStateCookie, "", -1, "/auth", "", isSecureRequest(c), true)
+
+	identity, err := provider.Exchange(c.Request.Context(), c.Query("code"), callbackURL(c, provider.Name))
+	if err != nil {
+		log.Printf("Error completing %s login: %v", provider.Name, err)
+		recordLoginFailure(c, provider.Name, err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to complete login",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var currentUserID *int
+	if session, err := c.Cookie(auth.SessionCookie); err == nil {
+		if claims, err := auth.ParseSession(session); err == nil {
+			if id, err := strconv.Atoi(claims.Subject); err == nil {
+				currentUserID = &id
+			}
+		}
+	}
+
+	result, err := services.SignIn(provider.Name, *identity, currentUserID)
+	if err != nil {
+		recordLoginFailure(c, provider.Name, err.Error())
+		switch {
+		case errors.Is(err, auth.ErrSessionsDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Login is not configured",
+				"details": err.Error(),
+			})
+		case err.Error() == "sign-up is closed":
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Sign-up is closed",
+				"details": "ask an admin to invite your email address",
+			})
+		case err.Error() == "identity is linked to another user":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Identity is linked to another user",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error signing in with %s: %v", provider.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to sign in",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	middleware.RecordAuthEvent(c, models.AuthEvent{Event: models.AuthEventLogin, UserID: &result.User.ID, Provider: &provider.Name})
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.SessionCookie, result.Token, int(auth.SessionTTL().Seconds()), "/", "", isSecureRequest(c), true)
+
+	if redirect := os.Getenv("AUTH_SUCCESS_REDIRECT"); redirect != "" {
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Logout handles POST /auth/logout
+// Clears the session cookie. Session JWTs stay valid until they expire.
+func Logout(c *gin.Context) {
+	event := models.AuthEvent{Event: models.AuthEventLogout}
+	if session, err := c.Cookie(auth.SessionCookie); err == nil {
+		if claims, err := auth.ParseSession(session); err == nil {
+			if id, err := strconv.Atoi(claims.Subject); err == nil {
+				event.UserID = &id
+			}
+		}
+	}
+	middleware.RecordAuthEvent(c, event)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.SessionCookie, "", -1, "/", "", isSecureRequest(c), true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+// recordLoginFailure records a provider login that didn't sign anyone in
+func recordLoginFailure(c *gin.Context, provider, reason string) {
+	middleware.RecordAuthEvent(c, models.AuthEvent{Event: models.AuthEventLoginFailed, Provider: &provider, Reason: &reason})
+}
+
+// callbackURL returns the provider's redirect URI, which must be registered with it
+func callbackURL(c *gin.Context, provider string) string {
+	return publicBaseURL(c) + "/auth/" + provider + "/callback"
+}
+
+// isSecureRequest reports whether the request arrived over HTTPS
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" ||
+		strings.HasPrefix(os.Getenv("PUBLIC_BASE_URL"), "https://")
+}
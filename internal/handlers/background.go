@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+)
+
+// background tracks the goroutines the Start functions run, so the server
+// can wait for them to stop once their context is cancelled
+var background sync.WaitGroup
+
+// WaitForBackground waits for the background work the Start functions run to
+// stop after their context is cancelled, or until ctx is done
+func WaitForBackground(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetBootstrap handles GET /api/bootstrap
+// Returns the caller, workspace counts, the review queue's size, and recent
+// sources and drafts, so a dashboard loads in one request
+func GetBootstrap(c *gin.Context) {
+	bootstrap, err := services.GetBootstrap(callerLocation(c), callerScope(c))
+	if err != nil {
+		log.Printf("Error loading bootstrap: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load dashboard",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	me, err := callerMe(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve user",
+			"details": err.Error(),
+		})
+		return
+	}
+	bootstrap.Me = me
+
+	c.JSON(http.StatusOK, bootstrap)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// briefingListLimit is how many briefings GET /api/briefings returns
+const briefingListLimit = 30
+
+// StartDailyBriefing starts the daily briefing at hour:minute local time
+func StartDailyBriefing(ctx context.Context, hour, minute int) {
+	background.Go(func() { notificationService.StartDailyBriefing(ctx, hour, minute) })
+}
+
+// CreateBriefing handles POST /api/briefings
+// Generates today's briefing now, replacing any earlier one, and delivers it.
+// Non-admin users get their own, from the concepts they can see; admins the
+// workspace's.
+func CreateBriefing(c *gin.Context) {
+	briefing, err := notificationService.DeliverBriefing(c.Request.Context(), callerScope(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNothingToBrief) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Nothing to brief",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error generating briefing: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate briefing",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, briefing)
+}
+
+// GetBriefings handles GET /api/briefings
+// Returns the newest briefings the caller can see, newest first
+func GetBriefings(c *gin.Context) {
+	briefings, err := db.GetBriefings(briefingListLimit, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve briefings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for i := range briefings {
+		if err := services.SignBriefingAudio(c.Request.Context(), &briefings[i]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to retrieve briefings",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"briefings": briefings,
+		"count":     len(briefings),
+	})
+}
+
+// GetBriefing handles GET /api/briefings/:id
+func GetBriefing(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	briefing, err := db.GetBriefingByID(id, callerScope(c))
+	if err == nil {
+		err = services.SignBriefingAudio(c.Request.Context(), briefing)
+	}
+	if err != nil {
+		if err.Error() == "briefing not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Briefing not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve briefing",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, briefing)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/youtube"
+)
+
+// Capture handles GET /api/capture
+// Queues the YouTube video at ?url= and returns a small HTML confirmation,
+// so a bookmarklet can capture the page it's clicked on. The API token may
+// ride in ?token=; see middleware.Auth.
+func Capture(c *gin.Context) {
+	var query models.CaptureQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		// A page rather than bindQuery's JSON, with the same explanation
+		details, _ := bindingDetails(err)
+		respondCapturePage(c, http.StatusBadRequest, "Nothing to capture", "The capture link is invalid: "+details+".", "")
+		return
+	}
+
+	existing, err := sourceContentService.CaptureURL(query.URL, callerUserID(c))
+	switch {
+	case errors.Is(err, youtube.ErrInvalidURL):
+		respondCapturePage(c, http.StatusBadRequest, "Can't capture this page", "Only YouTube videos can be captured.", query.URL)
+	case err != nil:
+		log.Printf("Error capturing URL: %v", err)
+		respondCapturePage(c, http.StatusInternalServerError, "Capture failed", "Something went wrong. Try again in a moment.", query.URL)
+	case existing != nil:
+		title := existing.Title
+		if title == "" {
+			title = query.URL
+		}
+		respondCapturePage(c, http.StatusOK, "Already in Lattice", title+" was captured before.", query.URL)
+	default:
+		respondCapturePage(c, http.StatusAccepted, "Saved to Lattice", "It's processing now and will be in your library shortly. You can close this tab.", query.URL)
+	}
+}
+
+// respondCapturePage writes a capture confirmation page
+func respondCapturePage(c *gin.Context, status int, heading, message, url string) {
+	html, err := services.RenderCapturePage(heading, message, url)
+	if err != nil {
+		log.Printf("Error rendering capture page: %v", err)
+		c.Data(http.StatusInternalServerError, "text/html; charset=utf-8", []byte("<!DOCTYPE html><title>Error</title><p>Something went wrong.</p>"))
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", html)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// GetCollections handles GET /api/collections
+func GetCollections(c *gin.Context) {
+	collections, err := db.GetCollections()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve collections",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collections": collections,
+		"count":       len(collections),
+	})
+}
+
+// CreateCollection handles POST /api/collections
+// Saves a named concept query as a smart collection
+func CreateCollection(c *gin.Context) {
+	var req models.CollectionRequest
+	if !bindCollectionRequest(c, &req) {
+		return
+	}
+
+	collection, err := db.CreateCollection(req)
+	if err != nil {
+		respondCollectionError(c, "Failed to create collection", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollection handles GET /api/collections/:id
+func GetCollection(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	collection, err := db.GetCollectionByID(id)
+	if err != nil {
+		respondCollectionError(c, "Failed to retrieve collection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection handles PUT /api/collections/:id
+func UpdateCollection(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CollectionRequest
+	if !bindCollectionRequest(c, &req) {
+		return
+	}
+
+	collection, err := db.UpdateCollection(id, req)
+	if err != nil {
+		respondCollectionError(c, "Failed to update collection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection handles DELETE /api/collections/:id
+func DeleteCollection(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteCollection(id); err != nil {
+		respondCollectionError(c, "Failed to delete collection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collection deleted successfully"})
+}
+
+// GetCollectionConcepts handles GET /api/collections/:id/concepts
+// Returns a page of the active concepts matching the collection now, newest
+// first. Pass next_cursor back as ?cursor= for the following page.
+func GetCollectionConcepts(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	collection, concepts, next, err := services.CollectionConcepts(id, callerLocation(c), callerScope(c), page)
+	if err != nil {
+		respondCollectionError(c, "Failed to retrieve collection concepts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection":  collection,
+		"concepts":    concepts,
+		"count":       len(concepts),
+		"next_cursor": encodeCursor(next),
+	})
+}
+
+// bindCollectionRequest binds and validates a collection request body,
+// responding 400 and returning false when it's invalid
+func bindCollectionRequest(c *gin.Context, req *models.CollectionRequest) bool {
+	if !bindJSON(c, req) {
+		return false
+	}
+
+	if err := services.ValidateCollection(*req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// respondCollectionError maps collection errors to a response
+func respondCollectionError(c *gin.Context, message string, err error) {
+	switch {
+	case err.Error() == "collection not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Collection not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "collection name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Collection name already exists",
+			"details": err.Error(),
+		})
+	case errors.Is(err, query.ErrInvalidQuery):
+		// A stored query can stop compiling when fields change
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Collection query is no longer valid",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
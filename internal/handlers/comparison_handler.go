@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CompareSources handles POST /api/compare
+// Compares two or more sources and stores the comparison
+func CompareSources(c *gin.Context) {
+	var req models.CompareSourcesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerScope(c)
+
+	comparison, err := sourceContentService.CompareSources(c.Request.Context(), req)
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error comparing sources %v: %v", req.SourceContentIDs, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to compare sources",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comparison)
+}
+
+// GetComparisons handles GET /api/compare
+// Returns the stored comparisons of sources the caller can see, newest first
+func GetComparisons(c *gin.Context) {
+	comparisons, err := db.GetSourceComparisons(callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve comparisons",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comparisons": comparisons,
+		"count":       len(comparisons),
+	})
+}
+
+// GetComparison handles GET /api/compare/:id
+func GetComparison(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	comparison, err := db.GetSourceComparisonByID(id, callerScope(c))
+	if err != nil {
+		if err.Error() == "comparison not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Comparison not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve comparison",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
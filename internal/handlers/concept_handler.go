@@ -1,23 +1,77 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/mostlyerror/lattice/internal/db"
 	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
+var conceptService *services.ConceptService
+
+// InitConceptService initializes the concept service
+func InitConceptService() error {
+	var err error
+	conceptService, err = services.NewConceptService()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetConcepts handles GET /api/concepts
+// Returns a page of concepts, newest first, filtered by source, tag, and
+// creation date. Archived concepts are only included with
+// ?include_archived=true, and ?sort=resonance puts the concepts the audience
+// engaged with most first. That ranks the newest
+// services.MaxResonanceSortConcepts concepts, paged by ?offset= and
+// next_offset rather than a cursor.
 func GetConcepts(c *gin.Context) {
-	concepts, err := db.GetAllConcepts()
+	var query models.ConceptListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	query.OwnerID = callerScope(c)
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+	if query.Sort == "resonance" && page.After != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid cursor",
+			"details": "sort=resonance pages with offset, not cursor",
+		})
+		return
+	}
+	if query.Sort != "resonance" && query.Offset > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": "offset only pages sort=resonance; other sorts page with cursor",
+		})
+		return
+	}
+
+	concepts, next, nextOffset, err := services.ListConcepts(query, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, concepts)
+	response := gin.H{
+		"concepts":    concepts,
+		"count":       len(concepts),
+		"next_cursor": encodeCursor(next),
+	}
+	if query.Sort == "resonance" {
+		response["next_offset"] = nextOffset
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetConcept handles GET /api/concepts/:id
@@ -41,19 +95,81 @@ func GetConcept(c *gin.Context) {
 	c.JSON(http.StatusOK, concept)
 }
 
+// GetConceptFull handles GET /api/concepts/:id/full
+// Returns the concept with quizzes, attempts, mastery, relations, source, and content
+func GetConceptFull(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	detail, err := conceptService.GetConceptDetail(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// GetConceptStats handles GET /api/concepts/:id/stats
+// Returns concept-wide attempt stats plus per-question stats for curating quiz sets
+func GetConceptStats(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	stats, err := quizService.GetConceptStats(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // CreateConcept handles POST /api/concepts
 func CreateConcept(c *gin.Context) {
 	var req models.CreateConceptRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
+	// Concepts of a source belong to its owner; others to the caller
+	if req.SourceContentID != nil {
+		owner, _, err := db.GetOwner("source_contents", *req.SourceContentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if scope := callerScope(c); owner != nil && scope != nil && *owner != *scope {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": "source content not found",
+			})
+			return
+		}
+	} else {
+		req.OwnerID = callerUserID(c)
+	}
+
 	concept, err := db.CreateConcept(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	services.WakeEmbedder()
 
 	c.JSON(http.StatusCreated, concept)
 }
@@ -67,8 +183,7 @@ func UpdateConcept(c *gin.Context) {
 	}
 
 	var req models.UpdateConceptRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -81,10 +196,197 @@ func UpdateConcept(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Title != nil || req.Description != nil {
+		services.WakeEmbedder()
+	}
 
 	c.JSON(http.StatusOK, concept)
 }
 
+// ArchiveConcept handles POST /api/concepts/:id/archive
+func ArchiveConcept(c *gin.Context) {
+	setConceptArchived(c, true)
+}
+
+// UnarchiveConcept handles POST /api/concepts/:id/unarchive
+func UnarchiveConcept(c *gin.Context) {
+	setConceptArchived(c, false)
+}
+
+// setConceptArchived applies the archive flag for the archive/unarchive handlers
+func setConceptArchived(c *gin.Context, archived bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	concept, err := db.SetConceptArchived(id, archived)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, concept)
+}
+
+// SplitConcept handles POST /api/concepts/:id/split
+// Returns Claude's proposal for splitting the concept; nothing is saved
+func SplitConcept(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	proposal, err := conceptService.ProposeSplit(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal)
+}
+
+// ConfirmSplitConcept handles POST /api/concepts/:id/split/confirm
+// Applies a split proposal (as returned by SplitConcept, optionally edited)
+func ConfirmSplitConcept(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	var req models.ConfirmConceptSplitRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := conceptService.ConfirmSplit(c.Request.Context(), id, req)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetDuplicateConcepts handles GET /api/concepts/duplicates
+// Lists clusters of likely-duplicate concepts across all sources, each with the
+// merge request that folds it into one concept. ?threshold= sets the title
+// similarity that counts as a duplicate.
+func GetDuplicateConcepts(c *gin.Context) {
+	threshold := services.DefaultConceptDuplicateThreshold
+	if str := c.Query("threshold"); str != "" {
+		parsed, err := strconv.ParseFloat(str, 64)
+		if err != nil || parsed < 0.3 || parsed > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid threshold", "details": "threshold must be between 0.3 and 1"})
+			return
+		}
+		threshold = parsed
+	}
+
+	clusters, err := services.FindDuplicateConcepts(threshold, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"clusters":  clusters,
+		"count":     len(clusters),
+		"threshold": threshold,
+	})
+}
+
+// MergeConcept handles POST /api/concepts/:id/merge
+// Merges the listed concepts into this one and archives them
+func MergeConcept(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	var req models.MergeConceptsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if slices.Contains(req.ConceptIDs, id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": fmt.Sprintf("concept %d can't be merged into itself", id)})
+		return
+	}
+
+	// Only concepts the caller can see may be merged, so their quizzes and
+	// progress don't move to someone else
+	hidden, err := db.CountHidden("concepts", req.ConceptIDs, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hidden > 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Concept not found",
+			"details": "concept not found",
+		})
+		return
+	}
+
+	result, err := services.MergeConcepts(id, req)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GenerateConceptQuizzes handles POST /api/concepts/:id/quizzes/generate
+// Generates additional questions for a concept that needs more drilling
+func GenerateConceptQuizzes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid concept id"})
+		return
+	}
+
+	var req models.GenerateQuizzesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	quizzes, err := conceptService.GenerateMoreQuizzes(c.Request.Context(), id, req.Count)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"quizzes": quizzes,
+		"count":   len(quizzes),
+	})
+}
+
 // DeleteConcept handles DELETE /api/concepts/:id
 func DeleteConcept(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -105,3 +407,27 @@ func DeleteConcept(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "concept deleted successfully"})
 }
+
+// DeleteConcepts handles DELETE /api/concepts
+// Bulk-deletes concepts matching ?source_content_id=, ?tag=, ?before=YYYY-MM-DD,
+// ?archived=true, and ?detached=true, at least one of which is required. Runs
+// dry by default, returning what would be removed; pass ?dry_run=false to delete.
+func DeleteConcepts(c *gin.Context) {
+	var req models.BulkDeleteConceptsRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if !req.HasFilter() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": "at least one filter is required"})
+		return
+	}
+	req.OwnerID = callerScope(c)
+
+	result, err := db.DeleteConcepts(req, req.DryRun == nil || *req.DryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
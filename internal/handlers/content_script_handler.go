@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetContentScripts handles GET /api/content-scripts
+func GetContentScripts(c *gin.Context) {
+	scripts, err := db.GetContentScripts(nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve content scripts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scripts": scripts,
+		"count":   len(scripts),
+	})
+}
+
+// CreateContentScript handles POST /api/content-scripts
+func CreateContentScript(c *gin.Context) {
+	var req models.ContentScriptRequest
+	if !bindContentScriptRequest(c, &req) {
+		return
+	}
+
+	script, err := db.CreateContentScript(req)
+	if err != nil {
+		respondContentScriptError(c, "Failed to create content script", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, script)
+}
+
+// UpdateContentScript handles PUT /api/content-scripts/:id
+func UpdateContentScript(c *gin.Context) {
+	id, ok := parseContentScriptID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ContentScriptRequest
+	if !bindContentScriptRequest(c, &req) {
+		return
+	}
+
+	script, err := db.UpdateContentScript(id, req)
+	if err != nil {
+		respondContentScriptError(c, "Failed to update content script", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, script)
+}
+
+// PreviewContentScript handles POST /api/content-scripts/:id/preview
+// Runs a script over sample content without saving anything
+func PreviewContentScript(c *gin.Context) {
+	id, ok := parseContentScriptID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PreviewContentScriptRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	script, err := db.GetContentScriptByID(id)
+	if err != nil {
+		respondContentScriptError(c, "Failed to retrieve content script", err)
+		return
+	}
+
+	title, body, err := services.RunContentScript(c.Request.Context(), *script, req.Title, req.Body)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Content script failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"title": title,
+		"body":  body,
+	})
+}
+
+// DeleteContentScript handles DELETE /api/content-scripts/:id
+func DeleteContentScript(c *gin.Context) {
+	id, ok := parseContentScriptID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteContentScript(id); err != nil {
+		respondContentScriptError(c, "Failed to delete content script", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Content script deleted successfully",
+	})
+}
+
+// parseContentScriptID parses the :id URL param, responding 400 when it isn't a number
+func parseContentScriptID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// bindContentScriptRequest binds and validates a content script, responding 400 when invalid
+func bindContentScriptRequest(c *gin.Context, req *models.ContentScriptRequest) bool {
+	if !bindJSON(c, req) {
+		return false
+	}
+
+	if err := services.ValidateContentScript(c.Request.Context(), *req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid content script",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// respondContentScriptError maps content script repo errors to a response
+func respondContentScriptError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "script not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Content script not found",
+			"details": err.Error(),
+		})
+	case "script name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Content script name already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
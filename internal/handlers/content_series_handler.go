@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// PlanContentSeries handles POST /api/content-series
+// Plans a multi-week series of posts from the concepts with a tag or in a
+// collection, and stores a scheduled draft of each
+func PlanContentSeries(c *gin.Context) {
+	var req models.PlanContentSeriesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerScope(c)
+	if (req.Tag == "") == (req.CollectionID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "exactly one of tag or collection_id is required",
+		})
+		return
+	}
+
+	series, err := sourceContentService.PlanContentSeries(c.Request.Context(), req, callerLocation(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNoTaggedConcepts) || errors.Is(err, services.ErrEmptyCollection) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "No concepts to plan from",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "collection not found" || errors.Is(err, query.ErrInvalidQuery) {
+			respondCollectionError(c, "Failed to plan content series", err)
+			return
+		}
+		log.Printf("Error planning content series: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to plan content series",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, series)
+}
+
+// GetContentSeriesList handles GET /api/content-series
+// Returns the content series the caller can see, without their posts, newest
+// first
+func GetContentSeriesList(c *gin.Context) {
+	series, err := db.GetContentSeries(callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve content series",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"series": series,
+		"count":  len(series),
+	})
+}
+
+// GetContentSeries handles GET /api/content-series/:id
+// Returns a content series with its posts in order
+func GetContentSeries(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	series, err := db.GetContentSeriesByID(id, callerScope(c))
+	if err != nil {
+		if err.Error() == "content series not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Content series not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve content series",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetContentCalendar handles GET /api/calendar?from=&to=
+// Returns the content the caller can see scheduled from the start of from up
+// to the end of to, dates in the caller's timezone, soonest first
+func GetContentCalendar(c *gin.Context) {
+	var req models.ContentCalendarQuery
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	loc := callerLocation(c)
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if req.From != "" {
+		from, _ = time.ParseInLocation(time.DateOnly, req.From, loc) // Validated by binding
+	}
+	to := from.AddDate(0, 0, 28)
+	if req.To != "" {
+		to, _ = time.ParseInLocation(time.DateOnly, req.To, loc)
+		to = to.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "to must not be before from",
+		})
+		return
+	}
+
+	content, err := db.GetScheduledContent(from, to, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve content calendar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"content": content,
+		"count":   len(content),
+	})
+}
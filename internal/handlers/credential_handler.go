@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetCredentials handles GET /api/credentials
+// Lists stored credentials without their secrets
+func GetCredentials(c *gin.Context) {
+	credentials, err := db.GetCredentials()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve credentials",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"credentials": credentials,
+		"count":       len(credentials),
+	})
+}
+
+// CreateCredential handles POST /api/credentials
+func CreateCredential(c *gin.Context) {
+	var req models.CreateCredentialRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	credential, err := services.CreateCredential(req)
+	if err != nil {
+		respondCredentialError(c, "Failed to store credential", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, credential)
+}
+
+// UpdateCredential handles PUT /api/credentials/:id
+// Replaces a credential's secret
+func UpdateCredential(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.UpdateCredentialRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	credential, err := services.ReplaceCredentialSecret(id, req.Secret)
+	if err != nil {
+		respondCredentialError(c, "Failed to update credential", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credential)
+}
+
+// DeleteCredential handles DELETE /api/credentials/:id
+func DeleteCredential(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	if err := db.DeleteCredential(id); err != nil {
+		respondCredentialError(c, "Failed to delete credential", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Credential deleted successfully",
+	})
+}
+
+// RotateCredentials handles POST /api/credentials/rotate
+// Re-encrypts every credential under the current master key
+func RotateCredentials(c *gin.Context) {
+	result, err := services.RotateCredentials()
+	if err != nil {
+		respondCredentialError(c, "Failed to rotate credentials", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// respondCredentialError maps credential errors to a response
+func respondCredentialError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, secrets.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Credential storage is not configured",
+			"details": err.Error(),
+		})
+	case err.Error() == "credential not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Credential not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "credential already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Credential already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
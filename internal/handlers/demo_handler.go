@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var demoConfig *services.DemoConfig
+
+// InitDemo loads the public demo settings. The demo stays off unless DEMO_MODE=true.
+func InitDemo() error {
+	var err error
+	demoConfig, err = services.LoadDemoConfig()
+	return err
+}
+
+// DemoEnabled reports whether the public demo route should be served
+func DemoEnabled() bool {
+	return demoConfig != nil
+}
+
+// ProcessDemoRequest represents the request body for a demo run
+type ProcessDemoRequest struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+// ProcessDemo handles POST /demo/source-content
+// Runs the reduced demo pipeline for a short YouTube video without
+// authentication, limited to DEMO_RUNS_PER_DAY runs per client IP
+func ProcessDemo(c *gin.Context) {
+	var req ProcessDemoRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ip := c.ClientIP()
+	runID, err := db.ClaimDemoRun(ip, demoConfig.RunsPerDay)
+	if err != nil {
+		log.Printf("Error claiming demo run: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start demo",
+			"details": err.Error(),
+		})
+		return
+	}
+	if runID == nil {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "Demo quota used",
+			"details": "the demo is limited per day; try again tomorrow",
+		})
+		return
+	}
+
+	log.Printf("Processing demo request from %s: url=%s", ip, req.URL)
+
+	result, tokensUsed, err := sourceContentService.ProcessDemoURL(c.Request.Context(), req.URL, *demoConfig)
+
+	var sourceID *int
+	if result != nil {
+		sourceID = &result.SourceContent.ID
+	}
+	if err := db.FinishDemoRun(*runID, sourceID, tokensUsed, err); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	if err != nil {
+		if errors.Is(err, services.ErrVideoTooLong) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Video too long for the demo",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error processing demo: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to process demo",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Only the demo stages' artifacts are returned, even for a video already
+	// processed through the full pipeline
+	c.JSON(http.StatusCreated, gin.H{
+		"source_content": result.SourceContent,
+		"concepts":       result.Concepts,
+		"quizzes":        result.Quizzes,
+		"glossary":       result.Glossary,
+		"warnings":       result.Warnings,
+		"tokens_used":    tokensUsed,
+		"limits": gin.H{
+			"runs_per_day":         demoConfig.RunsPerDay,
+			"max_duration_seconds": int(demoConfig.MaxDuration.Seconds()),
+			"max_tokens":           demoConfig.MaxTokens,
+		},
+	})
+}
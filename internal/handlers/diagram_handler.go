@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GenerateConceptDiagram handles POST /api/concepts/:id/diagram
+// Returns Mermaid or Graphviz source illustrating the concept, rendered to SVG
+// with "render": true. Nothing is saved.
+func GenerateConceptDiagram(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.GenerateDiagramRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	diagram, err := conceptService.GenerateConceptDiagram(c.Request.Context(), id, req)
+	if err != nil {
+		respondDiagramError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diagram)
+}
+
+// GenerateSourceDiagram handles POST /api/source-content/:id/diagram
+// Returns a concept map of how the source's concepts relate, as Mermaid or
+// Graphviz source, rendered to SVG with "render": true. Nothing is saved.
+func GenerateSourceDiagram(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.GenerateDiagramRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	diagram, err := conceptService.GenerateSourceDiagram(c.Request.Context(), id, req)
+	if err != nil {
+		respondDiagramError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diagram)
+}
+
+// respondDiagramError maps diagram errors to responses
+func respondDiagramError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRendererNotFound):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Diagram rendering is not available",
+			"details": err.Error(),
+		})
+	case err.Error() == "concept not found", err.Error() == "source content not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "source has no concepts":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Source has no concepts",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("Error generating diagram: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate diagram",
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetEvalCases handles GET /api/evals/cases
+func GetEvalCases(c *gin.Context) {
+	cases, err := db.GetEvalCases()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve eval cases",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cases": cases,
+		"count": len(cases),
+	})
+}
+
+// CreateEvalCase handles POST /api/evals/cases
+// Stores a transcript, given or copied from a source, with its expected concepts
+func CreateEvalCase(c *gin.Context) {
+	var req models.CreateEvalCaseRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	transcript := req.Transcript
+	if req.SourceContentID != nil {
+		source, err := db.GetSourceContentByID(*req.SourceContentID)
+		if err != nil {
+			respondEvalError(c, "Failed to retrieve source content", err)
+			return
+		}
+		transcript = source.Transcript
+	}
+	if transcript == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "transcript or source_content_id is required",
+		})
+		return
+	}
+
+	evalCase, err := db.CreateEvalCase(req.Name, transcript, req.ExpectedConcepts, req.SourceContentID)
+	if err != nil {
+		respondEvalError(c, "Failed to create eval case", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, evalCase)
+}
+
+// DeleteEvalCase handles DELETE /api/evals/cases/:id
+func DeleteEvalCase(c *gin.Context) {
+	id, ok := parseEvalID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteEvalCase(id); err != nil {
+		respondEvalError(c, "Failed to delete eval case", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Eval case deleted successfully",
+	})
+}
+
+// GetEvalRuns handles GET /api/evals/runs
+// Returns runs newest first, filtered by ?model= and ?prompt_version= when given
+func GetEvalRuns(c *gin.Context) {
+	var model, promptVersion *string
+	if m := c.Query("model"); m != "" {
+		model = &m
+	}
+	if v := c.Query("prompt_version"); v != "" {
+		promptVersion = &v
+	}
+
+	runs, err := db.GetEvalRuns(model, promptVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve eval runs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetEvalRun handles GET /api/evals/runs/:id
+// Returns a run with its per-case results
+func GetEvalRun(c *gin.Context) {
+	id, ok := parseEvalID(c)
+	if !ok {
+		return
+	}
+
+	run, err := db.GetEvalRunByID(id)
+	if err != nil {
+		respondEvalError(c, "Failed to retrieve eval run", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// parseEvalID parses the :id URL param, responding 400 when it isn't a number
+func parseEvalID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondEvalError maps eval repo errors to a response
+func respondEvalError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "eval case not found", "eval run not found", "source content not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"details": err.Error(),
+		})
+	case "eval case name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Eval case name already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetPromptExperiments handles GET /api/experiments
+func GetPromptExperiments(c *gin.Context) {
+	experiments, err := db.GetPromptExperiments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve experiments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiments": experiments,
+		"count":       len(experiments),
+	})
+}
+
+// CreatePromptExperiment handles POST /api/experiments
+// Starts running two prompt variants of a stage on a sample of new sources
+func CreatePromptExperiment(c *gin.Context) {
+	var req models.CreatePromptExperimentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	experiment, err := db.CreatePromptExperiment(req)
+	if err != nil {
+		respondExperimentError(c, "Failed to create experiment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// GetPromptExperimentReport handles GET /api/experiments/:id
+// Returns the experiment with each variant's downstream metrics
+func GetPromptExperimentReport(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+
+	experiment, err := db.GetPromptExperimentByID(id)
+	if err != nil {
+		respondExperimentError(c, "Failed to retrieve experiment", err)
+		return
+	}
+
+	variants, err := db.GetPromptVariantMetrics(*experiment)
+	if err != nil {
+		respondExperimentError(c, "Failed to retrieve experiment metrics", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromptExperimentReport{
+		Experiment: *experiment,
+		Variants:   variants,
+	})
+}
+
+// StopPromptExperiment handles POST /api/experiments/:id/stop
+// Stops assigning variants, optionally recording which one won
+func StopPromptExperiment(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req models.StopPromptExperimentRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	experiment, err := db.StopPromptExperiment(id, req.Winner)
+	if err != nil {
+		respondExperimentError(c, "Failed to stop experiment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// DeletePromptExperiment handles DELETE /api/experiments/:id
+func DeletePromptExperiment(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeletePromptExperiment(id); err != nil {
+		respondExperimentError(c, "Failed to delete experiment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Experiment deleted successfully",
+	})
+}
+
+// CreateArtifactFeedback handles POST /api/source-content/:id/feedback
+// Records a thumbs up or down on one stage's output for a source
+func CreateArtifactFeedback(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ArtifactFeedbackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if _, err := db.GetSourceContentByID(id); err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve source content",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := db.CreateArtifactFeedback(id, req.Stage, req.Thumbs == "up"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save feedback",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Feedback recorded",
+	})
+}
+
+// parseExperimentID parses the :id URL param, responding 400 when it isn't a number
+func parseExperimentID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondExperimentError maps prompt experiment repo errors to a response
+func respondExperimentError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "experiment not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Experiment not found",
+			"details": err.Error(),
+		})
+	case "experiment name already exists", "an experiment is already running for this stage":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Experiment conflicts with an existing one",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
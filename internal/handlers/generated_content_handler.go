@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetGeneratedContents handles GET /api/content
+// Returns a page of generated content, newest first, filtered by platform,
+// status, source, concept, series, and creation date
+func GetGeneratedContents(c *gin.Context) {
+	var query models.GeneratedContentListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	query.OwnerID = callerScope(c)
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	contents, next, err := db.GetGeneratedContents(query, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve generated content",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"content":     contents,
+		"count":       len(contents),
+		"next_cursor": encodeCursor(next),
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetGlossary handles GET /api/glossary
+// Returns the glossary merged across all sources the caller can see
+func GetGlossary(c *gin.Context) {
+	entries, err := services.GetMergedGlossary(callerScope(c))
+	if err != nil {
+		log.Printf("Error getting glossary: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve glossary",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"terms": entries,
+		"count": len(entries),
+	})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetPipelineHooks handles GET /api/pipeline-hooks
+func GetPipelineHooks(c *gin.Context) {
+	hooks, err := db.GetPipelineHooks(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve pipeline hooks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hooks": hooks,
+		"count": len(hooks),
+	})
+}
+
+// CreatePipelineHook handles POST /api/pipeline-hooks
+// Registers a webhook called after pipeline stages
+func CreatePipelineHook(c *gin.Context) {
+	var req models.CreatePipelineHookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	for _, stage := range req.Stages {
+		if !slices.Contains(models.PipelineStages, stage) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid stage",
+				"details": "unknown stage " + strconv.Quote(stage),
+			})
+			return
+		}
+	}
+
+	hook, err := db.CreatePipelineHook(req)
+	if err != nil {
+		if err.Error() == "hook name already exists" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Hook name already exists",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create pipeline hook",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// EnablePipelineHook handles POST /api/pipeline-hooks/:id/enable
+func EnablePipelineHook(c *gin.Context) {
+	setPipelineHookEnabled(c, true)
+}
+
+// DisablePipelineHook handles POST /api/pipeline-hooks/:id/disable
+func DisablePipelineHook(c *gin.Context) {
+	setPipelineHookEnabled(c, false)
+}
+
+// setPipelineHookEnabled is the shared implementation of enable and disable
+func setPipelineHookEnabled(c *gin.Context, enabled bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	hook, err := db.SetPipelineHookEnabled(id, enabled)
+	if err != nil {
+		if err.Error() == "hook not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Hook not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update pipeline hook",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeletePipelineHook handles DELETE /api/pipeline-hooks/:id
+func DeletePipelineHook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	if err := db.DeletePipelineHook(id); err != nil {
+		if err.Error() == "hook not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Hook not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete pipeline hook",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline hook deleted successfully",
+	})
+}
+
+// GetSourceContentHookRuns handles GET /api/source-content/:id/hook-runs
+// Returns the log of hooks run while processing a source
+func GetSourceContentHookRuns(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	runs, err := db.GetPipelineHookRuns(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve hook runs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/decks"
+)
+
+// ImportDeck handles POST /api/imports
+// Imports the flashcards of an Anki, Logseq, or RemNote export uploaded as
+// the multipart "file" field
+func ImportDeck(c *gin.Context) {
+	var req models.ImportDeckRequest
+	if !bindForm(c, &req) {
+		return
+	}
+	req.OwnerID = callerUserID(c)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "file is required",
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so oversized files are rejected, not truncated
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxImportUpload+1))
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	result, err := services.ImportDeck(c.Request.Context(), header.Filename, data, req)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	if result.Duplicate {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	log.Printf("Imported %d concepts as source content ID: %d", result.Concepts, result.SourceContent.ID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// respondImportError maps deck import errors to responses
+func respondImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrImportFormatRequired),
+		errors.Is(err, decks.ErrInvalidExport),
+		errors.Is(err, decks.ErrUnsupportedAnkiVersion):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrImportTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "Import too large",
+			"details": err.Error(),
+		})
+	case errors.Is(err, decks.ErrNoCards):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "No flashcards found",
+			"details": err.Error(),
+		})
+	case errors.Is(err, decks.ErrSQLiteNotFound):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Anki import is not available",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to import deck",
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetIntegrityReport handles GET /api/integrity
+// Audits the links foreign keys don't enforce, reporting orphaned rows, along
+// with any foreign key that lacks an ON DELETE rule. Nothing is changed.
+func GetIntegrityReport(c *gin.Context) {
+	checks, err := db.RunIntegrityChecks()
+	if err != nil {
+		log.Printf("Error running integrity checks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to run integrity checks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	keys, err := db.GetUnguardedForeignKeys()
+	if err != nil {
+		log.Printf("Error listing foreign keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to run integrity checks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	report := models.IntegrityReport{
+		CheckedAt:            time.Now(),
+		Healthy:              len(keys) == 0,
+		Checks:               checks,
+		UnguardedForeignKeys: keys,
+	}
+	for _, check := range checks {
+		if check.Count > 0 && !check.Informative {
+			report.Healthy = false
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
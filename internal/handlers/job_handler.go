@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// StartJobWorkers starts workers that process queued sources in the background
+func StartJobWorkers(ctx context.Context, workers int) {
+	background.Go(func() { sourceContentService.StartJobWorkers(ctx, workers) })
+}
+
+// GetJob handles GET /api/jobs/:id
+// Returns a job's status and each pipeline step's progress, with its
+// source_content_id once the source is saved
+func GetJob(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	job, err := services.GetJob(id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Job not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// jobEventsHeartbeat is how often an idle event stream is kept alive, and the
+// job rechecked in case another server is running it
+const jobEventsHeartbeat = 15 * time.Second
+
+// StreamJobEvents handles GET /api/jobs/:id/events
+// Streams a job's progress as Server-Sent Events: "job" with the job as it
+// stands, "progress" as its steps advance, and "done" with the finished job
+func StreamJobEvents(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	// Subscribe first, so nothing is missed between the snapshot and the stream
+	events, unsubscribe := services.SubscribeJobProgress(id)
+	defer unsubscribe()
+
+	job, err := services.GetJob(id)
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Job not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve job",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Stop nginx buffering the stream
+	c.SSEvent("job", job)
+	if jobFinished(job) {
+		c.SSEvent("done", job)
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(jobEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, open := <-events:
+			if open {
+				c.SSEvent("progress", event)
+				return true
+			}
+		case <-heartbeat.C:
+		}
+
+		job, err := services.GetJob(id)
+		if err != nil {
+			c.SSEvent("error", gin.H{
+				"error":   "Failed to retrieve job",
+				"details": err.Error(),
+			})
+			return false
+		}
+		if !jobFinished(job) {
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		}
+		c.SSEvent("done", job)
+		return false
+	})
+}
+
+// jobFinished reports whether a job has completed or failed
+func jobFinished(job *models.Job) bool {
+	return job.Status == models.JobCompleted || job.Status == models.JobFailed
+}
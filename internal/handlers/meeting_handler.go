@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/meeting"
+)
+
+// ImportMeeting handles POST /api/meetings
+// Imports a Zoom or Google Meet meeting's transcript and processes it
+func ImportMeeting(c *gin.Context) {
+	var req models.ImportMeetingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerUserID(c)
+
+	result, err := sourceContentService.ProcessMeeting(c.Request.Context(), req)
+	if err != nil {
+		respondMeetingError(c, err)
+		return
+	}
+
+	log.Printf("Successfully processed meeting as source content ID: %d", result.SourceContent.ID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// UploadMeeting handles POST /api/meetings/upload
+// Processes a meeting transcript uploaded as the multipart "file" field
+func UploadMeeting(c *gin.Context) {
+	var req models.UploadMeetingRequest
+	if !bindForm(c, &req) {
+		return
+	}
+	req.OwnerID = callerUserID(c)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "file is required",
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondMeetingError(c, err)
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so oversized files are rejected, not truncated
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxTranscriptUpload+1))
+	if err != nil {
+		respondMeetingError(c, err)
+		return
+	}
+
+	result, err := sourceContentService.ProcessMeetingUpload(c.Request.Context(), header.Filename, data, req)
+	if err != nil {
+		respondMeetingError(c, err)
+		return
+	}
+
+	log.Printf("Successfully processed uploaded meeting as source content ID: %d", result.SourceContent.ID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// respondMeetingError maps meeting import errors to responses
+func respondMeetingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedTranscript),
+		errors.Is(err, meeting.ErrInvalidCredential):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrTranscriptTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "Transcript too large",
+			"details": err.Error(),
+		})
+	case errors.Is(err, meeting.ErrNoTranscript):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "No transcript available",
+			"details": err.Error(),
+		})
+	case errors.Is(err, meeting.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Meeting not found",
+			"details": err.Error(),
+		})
+	case errors.Is(err, meeting.ErrUnauthorized):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Provider rejected the credential",
+			"details": err.Error(),
+		})
+	case errors.Is(err, secrets.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Credential storage is not configured",
+			"details": err.Error(),
+		})
+	case err.Error() == "credential not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Credential not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "pipeline not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Pipeline not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "profile not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Profile not found",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("Error processing meeting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to process meeting",
+			"details": err.Error(),
+		})
+	}
+}
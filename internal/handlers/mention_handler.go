@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetMentions handles GET /api/mentions?kind=&recommended=
+// Returns books, tools, people, and frameworks merged across all sources the
+// caller can see
+func GetMentions(c *gin.Context) {
+	var kind *string
+	if kindStr := c.Query("kind"); kindStr != "" {
+		if !slices.Contains(models.MentionKinds, kindStr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid kind",
+				"details": "kind must be one of: book, tool, person, framework",
+			})
+			return
+		}
+		kind = &kindStr
+	}
+
+	recommendedOnly := false
+	if recStr := c.Query("recommended"); recStr != "" {
+		parsed, err := strconv.ParseBool(recStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid recommended",
+				"details": "recommended must be true or false",
+			})
+			return
+		}
+		recommendedOnly = parsed
+	}
+
+	entries, err := db.GetMentionEntries(kind, recommendedOnly, callerScope(c))
+	if err != nil {
+		log.Printf("Error getting mentions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve mentions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mentions": entries,
+		"count":    len(entries),
+	})
+}
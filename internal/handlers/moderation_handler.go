@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// moderationService is swapped whole when the configuration is reloaded
+var moderationService atomic.Pointer[services.ModerationService]
+
+// InitModerationService initializes the moderation service
+func InitModerationService() error {
+	s, err := services.NewModerationService()
+	if err != nil {
+		return err
+	}
+	moderationService.Store(s)
+	return nil
+}
+
+// ModerateGeneratedContent handles POST /api/content/:id/moderate
+// Runs the moderation checks now and attaches the findings to the content
+func ModerateGeneratedContent(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	content, err := moderationService.Load().ModerateContent(c.Request.Context(), id)
+	if err != nil {
+		respondModerationError(c, err, "Failed to moderate content")
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// UpdateGeneratedContentStatus handles PATCH /api/content/:id/status
+// Moves content between draft, approved, and published. With moderation on,
+// findings block approving or publishing unless acknowledge_findings is set.
+func UpdateGeneratedContentStatus(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateContentStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	content, err := moderationService.Load().SetContentStatus(c.Request.Context(), id, req)
+	if errors.Is(err, services.ErrContentFlagged) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Content has moderation findings",
+			"details":    "Review the findings, then retry with acknowledge_findings to " + req.Status + " anyway",
+			"moderation": content.Moderation,
+		})
+		return
+	}
+	if err != nil {
+		respondModerationError(c, err, "Failed to update content status")
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// parseContentID parses the :id URL param, responding 400 when it isn't a number
+func parseContentID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondModerationError maps moderation service errors to responses
+func respondModerationError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "generated content not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Generated content not found",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrModerationOff):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Moderation is off",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
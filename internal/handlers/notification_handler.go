@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var notificationService *services.NotificationService
+
+// InitNotificationService initializes the notification service
+func InitNotificationService() {
+	notificationService = services.NewNotificationService()
+}
+
+// StartReviewReminders starts sending review_due notifications in the background
+func StartReviewReminders(ctx context.Context, interval time.Duration) {
+	background.Go(func() { notificationService.StartReviewReminders(ctx, interval) })
+}
+
+// StartReviewDigest starts the daily review digest at hour:minute local time
+func StartReviewDigest(ctx context.Context, hour, minute int) {
+	background.Go(func() { notificationService.StartReviewDigest(ctx, hour, minute) })
+}
+
+// GetNotifications handles GET /api/notifications
+// Returns a page of notifications newest first; ?unread=true limits to unread
+// ones. Pass next_cursor back as ?cursor= for the following page. Signed-in
+// users see their own notifications and the workspace's.
+func GetNotifications(c *gin.Context) {
+	unreadOnly := c.Query("unread") == "true"
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	notifications, next, err := db.GetNotifications(callerUserID(c), unreadOnly, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve notifications",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	unread, err := db.CountUnreadNotifications(callerUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve notifications",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"count":         len(notifications),
+		"unread_count":  unread,
+		"next_cursor":   encodeCursor(next),
+	})
+}
+
+// MarkNotificationRead handles PATCH /api/notifications/:id/read
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	notification, err := db.MarkNotificationRead(id, callerUserID(c))
+	if err != nil {
+		if err.Error() == "notification not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to mark notification read",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllNotificationsRead handles POST /api/notifications/read-all
+func MarkAllNotificationsRead(c *gin.Context) {
+	updated, err := db.MarkAllNotificationsRead(callerUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to mark notifications read",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// GetNotificationPreferences handles GET /api/notifications/preferences
+// Returns the signed-in user's preferences, or the workspace's for API tokens
+// and open setups
+func GetNotificationPreferences(c *gin.Context) {
+	prefs, err := db.GetNotificationPreferences(callerUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve notification preferences",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preferences": prefs,
+	})
+}
+
+// UpdateNotificationPreference handles PATCH /api/notifications/preferences/:event
+// Updates the signed-in user's preferences, or the workspace's for API tokens
+// and open setups
+func UpdateNotificationPreference(c *gin.Context) {
+	eventType := c.Param("event")
+
+	valid := false
+	for _, e := range models.NotificationEventTypes {
+		if e == eventType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid event type",
+			"details": "event must be one of: " + strings.Join(models.NotificationEventTypes, ", "),
+		})
+		return
+	}
+
+	var req models.UpdateNotificationPreferenceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pref, err := db.UpdateNotificationPreference(eventType, callerUserID(c), req)
+	if err != nil {
+		if err.Error() == "notification preference not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification preference not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update notification preference",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// GetPushSubscriptions handles GET /api/notifications/push-subscriptions
+func GetPushSubscriptions(c *gin.Context) {
+	subscriptions, err := db.GetPushSubscriptions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve push subscriptions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subscriptions,
+		"count":         len(subscriptions),
+	})
+}
+
+// CreatePushSubscription handles POST /api/notifications/push-subscriptions
+// Adds an ntfy topic or Gotify server to deliver push notifications to
+func CreatePushSubscription(c *gin.Context) {
+	var req models.CreatePushSubscriptionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if req.Provider == models.PushProviderGotify && (req.Token == nil || *req.Token == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "token is required for gotify",
+		})
+		return
+	}
+
+	subscription, err := db.CreatePushSubscription(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create push subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// TestPushSubscription handles POST /api/notifications/push-subscriptions/:id/test
+// Sends a test message and reports whether it was delivered
+func TestPushSubscription(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	if err := notificationService.SendTestPush(c.Request.Context(), id); err != nil {
+		if err.Error() == "push subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "push subscription not found"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to deliver test push",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Test push delivered",
+	})
+}
+
+// DeletePushSubscription handles DELETE /api/notifications/push-subscriptions/:id
+func DeletePushSubscription(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	if err := db.DeletePushSubscription(id); err != nil {
+		if err.Error() == "push subscription not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "push subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete push subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Push subscription deleted successfully",
+	})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// RenderGeneratedContent handles GET /api/content/:id/render
+// Renders generated content with a stored or built-in template (?template=, default markdown)
+func RenderGeneratedContent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	templateName := c.DefaultQuery("template", services.DefaultOutputTemplate)
+
+	output, contentType, err := services.RenderContent(id, templateName)
+	if err != nil {
+		switch err.Error() {
+		case "generated content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Generated content not found",
+				"details": err.Error(),
+			})
+		case "template not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Template not found",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error rendering generated content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to render content",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	// Kept from being sniffed as, or run as, a page on the API's origin
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Data(http.StatusOK, contentType, output)
+}
+
+// GetOutputTemplates handles GET /api/output-templates
+// Returns the stored templates and the names of the built-in ones
+func GetOutputTemplates(c *gin.Context) {
+	templates, err := db.GetOutputTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve output templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+		"builtin":   services.BuiltinOutputTemplates(),
+	})
+}
+
+// CreateOutputTemplate handles POST /api/output-templates
+func CreateOutputTemplate(c *gin.Context) {
+	var req models.OutputTemplateRequest
+	if !bindOutputTemplateRequest(c, &req) {
+		return
+	}
+
+	outputTemplate, err := db.CreateOutputTemplate(req)
+	if err != nil {
+		respondOutputTemplateError(c, "Failed to create output template", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, outputTemplate)
+}
+
+// UpdateOutputTemplate handles PUT /api/output-templates/:id
+func UpdateOutputTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.OutputTemplateRequest
+	if !bindOutputTemplateRequest(c, &req) {
+		return
+	}
+
+	outputTemplate, err := db.UpdateOutputTemplate(id, req)
+	if err != nil {
+		respondOutputTemplateError(c, "Failed to update output template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, outputTemplate)
+}
+
+// DeleteOutputTemplate handles DELETE /api/output-templates/:id
+func DeleteOutputTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	if err := db.DeleteOutputTemplate(id); err != nil {
+		respondOutputTemplateError(c, "Failed to delete output template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Output template deleted successfully",
+	})
+}
+
+// bindOutputTemplateRequest binds a template and checks that it parses, responding 400 when invalid
+func bindOutputTemplateRequest(c *gin.Context, req *models.OutputTemplateRequest) bool {
+	if !bindJSON(c, req) {
+		return false
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "name must not be blank",
+		})
+		return false
+	}
+
+	if req.ContentType != "" {
+		contentType, err := services.OutputContentType(req.ContentType)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid content type",
+				"details": err.Error(),
+			})
+			return false
+		}
+		req.ContentType = contentType
+	}
+
+	if _, err := services.ParseOutputTemplate(req.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid template",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// respondOutputTemplateError maps output template repo errors to a response
+func respondOutputTemplateError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "template not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Template not found",
+			"details": err.Error(),
+		})
+	case "template name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Template name already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
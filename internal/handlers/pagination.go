@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// maxPageLimit caps ?limit on cursor-paginated lists
+const maxPageLimit = 200
+
+// parsePage reads ?cursor= and ?limit= for a cursor-paginated list. On invalid
+// values it responds 400 and returns false.
+func parsePage(c *gin.Context, defaultLimit int) (models.Page, bool) {
+	page := models.Page{Limit: defaultLimit}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit",
+				"details": fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+			})
+			return page, false
+		}
+		page.Limit = parsed
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := models.DecodeCursor(token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid cursor",
+				"details": "cursor must be a next_cursor value from a previous page",
+			})
+			return page, false
+		}
+		page.After = cursor
+	}
+
+	return page, true
+}
+
+// encodeCursor returns the next_cursor response value, nil on the last page
+func encodeCursor(next *models.Cursor) *string {
+	if next == nil {
+		return nil
+	}
+	token := next.Encode()
+	return &token
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetPipelines handles GET /api/pipelines
+// Returns the stored pipeline definitions and the built-in spec used when none is the default
+func GetPipelines(c *gin.Context) {
+	pipelines, err := db.GetPipelineDefinitions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve pipelines",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pipelines": pipelines,
+		"count":     len(pipelines),
+		"builtin":   services.BuiltinPipelineSpec(),
+	})
+}
+
+// GetPipeline handles GET /api/pipelines/:id
+func GetPipeline(c *gin.Context) {
+	id, ok := parsePipelineID(c)
+	if !ok {
+		return
+	}
+
+	pipeline, err := db.GetPipelineDefinitionByID(id)
+	if err != nil {
+		respondPipelineError(c, "Failed to retrieve pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// CreatePipeline handles POST /api/pipelines
+func CreatePipeline(c *gin.Context) {
+	var req models.PipelineDefinitionRequest
+	if !bindPipelineRequest(c, &req) {
+		return
+	}
+
+	pipeline, err := db.CreatePipelineDefinition(req)
+	if err != nil {
+		respondPipelineError(c, "Failed to create pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pipeline)
+}
+
+// UpdatePipeline handles PUT /api/pipelines/:id
+// Replaces a pipeline's name and spec
+func UpdatePipeline(c *gin.Context) {
+	id, ok := parsePipelineID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PipelineDefinitionRequest
+	if !bindPipelineRequest(c, &req) {
+		return
+	}
+
+	pipeline, err := db.UpdatePipelineDefinition(id, req)
+	if err != nil {
+		respondPipelineError(c, "Failed to update pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// SetDefaultPipeline handles POST /api/pipelines/:id/default
+// Makes a pipeline the one new sources are processed with
+func SetDefaultPipeline(c *gin.Context) {
+	id, ok := parsePipelineID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.SetDefaultPipelineDefinition(&id); err != nil {
+		respondPipelineError(c, "Failed to set default pipeline", err)
+		return
+	}
+
+	pipeline, err := db.GetPipelineDefinitionByID(id)
+	if err != nil {
+		respondPipelineError(c, "Failed to retrieve pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pipeline)
+}
+
+// ClearDefaultPipeline handles DELETE /api/pipelines/default
+// Restores the built-in pipeline as the default
+func ClearDefaultPipeline(c *gin.Context) {
+	if err := db.SetDefaultPipelineDefinition(nil); err != nil {
+		respondPipelineError(c, "Failed to clear default pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default pipeline cleared; the built-in pipeline applies",
+	})
+}
+
+// DeletePipeline handles DELETE /api/pipelines/:id
+func DeletePipeline(c *gin.Context) {
+	id, ok := parsePipelineID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeletePipelineDefinition(id); err != nil {
+		respondPipelineError(c, "Failed to delete pipeline", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline deleted successfully",
+	})
+}
+
+// parsePipelineID parses the :id URL param, responding 400 when it isn't a number
+func parsePipelineID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// bindPipelineRequest binds and validates a pipeline definition, responding 400 when invalid
+func bindPipelineRequest(c *gin.Context, req *models.PipelineDefinitionRequest) bool {
+	if !bindJSON(c, req) {
+		return false
+	}
+
+	if err := services.ValidatePipelineSpec(req.Spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid pipeline spec",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// respondPipelineError maps pipeline repo errors to a response
+func respondPipelineError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "pipeline not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Pipeline not found",
+			"details": err.Error(),
+		})
+	case "pipeline name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Pipeline name already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
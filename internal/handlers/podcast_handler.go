@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/podcast"
+)
+
+// ImportPodcast handles POST /api/podcasts
+// Queues the newest episodes of a podcast RSS feed, or a single episode's
+// audio URL, to be transcribed and processed; poll each episode's job at
+// GET /api/jobs/:id for progress
+func ImportPodcast(c *gin.Context) {
+	var req models.ImportPodcastRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerUserID(c)
+
+	log.Printf("Queueing podcast import: url=%s, episodes=%d", req.URL, req.Episodes)
+
+	imported, err := sourceContentService.EnqueuePodcast(c.Request.Context(), req)
+	if err != nil {
+		respondPodcastError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, imported)
+}
+
+// respondPodcastError maps podcast import errors to responses
+func respondPodcastError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, podcast.ErrInvalidURL),
+		errors.Is(err, podcast.ErrNotPodcast),
+		errors.Is(err, podcast.ErrNoEpisodes):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid podcast",
+			"details": err.Error(),
+		})
+	case errors.Is(err, podcast.ErrFetchFailed):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to fetch podcast",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrNoSpeechToText):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Speech-to-text is not configured",
+			"details": err.Error(),
+		})
+	case err.Error() == "pipeline not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Pipeline not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "profile not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Profile not found",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("Error queueing podcast: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to queue podcast",
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/middleware"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var privacyService *services.PrivacyService
+
+// InitPrivacyService initializes the privacy service
+func InitPrivacyService() {
+	privacyService = services.NewPrivacyService()
+}
+
+// StartDataRequests starts carrying out data export and deletion requests in the background
+func StartDataRequests(ctx context.Context) {
+	background.Go(func() { privacyService.StartDataRequests(ctx) })
+}
+
+// signedInUser returns the signed-in user's ID, or responds 400 for API
+// tokens and anonymous callers, who have no account
+func signedInUser(c *gin.Context) (int, bool) {
+	userID, ok := c.Get(middleware.UserIDKey)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "data requests belong to a signed-in user; API tokens and anonymous callers have no account",
+		})
+		return 0, false
+	}
+	return userID.(int), true
+}
+
+// ExportMyData handles POST /api/me/export
+// Queues an export of the caller's account with the data and files they own,
+// or for admins all the workspace's, as a ZIP archive, returning 202 with the request. A data_request_complete
+// notification is sent when it's ready to download.
+func ExportMyData(c *gin.Context) {
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	req, err := privacyService.RequestExport(userID)
+	if err != nil {
+		log.Printf("Error requesting data export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to request data export",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, req)
+}
+
+// DeleteMe handles DELETE /api/me
+// Queues the deletion of the caller's account and what they own, returning 202
+// with the request. Deleting the last account also deletes the workspace's
+// data and files, and needs an API token to keep the API closed. The
+// body must be {"confirm": true}.
+func DeleteMe(c *gin.Context) {
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	var body models.DeleteAccountRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	req, err := privacyService.RequestDeletion(userID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "User not found",
+				"details": err.Error(),
+			})
+		case "cannot delete the last admin":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Cannot delete the last admin",
+				"details": "promote another user to admin first",
+			})
+		case "cannot delete the last account":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Cannot delete the last account",
+				"details": "create an API token first, or the API would be open to anyone",
+			})
+		default:
+			log.Printf("Error requesting account deletion: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to request account deletion",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, req)
+}
+
+// GetMyDataRequests handles GET /api/me/data-requests
+// Lists the caller's export and deletion requests, newest first
+func GetMyDataRequests(c *gin.Context) {
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	requests, err := services.GetDataRequests(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve data requests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data_requests": requests,
+		"count":         len(requests),
+	})
+}
+
+// GetMyDataRequest handles GET /api/me/data-requests/:id
+// Returns one of the caller's data requests, with a download_url once an
+// export is ready
+func GetMyDataRequest(c *gin.Context) {
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	req, err := services.GetDataRequest(c.Request.Context(), userID, id)
+	if err != nil {
+		if err.Error() == "data request not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Data request not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve data request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
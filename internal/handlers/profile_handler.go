@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetProfiles handles GET /api/profiles
+func GetProfiles(c *gin.Context) {
+	profiles, err := db.GetProcessingProfiles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve profiles",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profiles": profiles,
+		"count":    len(profiles),
+	})
+}
+
+// GetProfile handles GET /api/profiles/:id
+func GetProfile(c *gin.Context) {
+	id, ok := parseProfileID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := db.GetProcessingProfileByID(id)
+	if err != nil {
+		respondProfileError(c, "Failed to retrieve profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// CreateProfile handles POST /api/profiles
+func CreateProfile(c *gin.Context) {
+	var req models.ProcessingProfileRequest
+	if !bindProfileRequest(c, &req) {
+		return
+	}
+
+	profile, err := db.CreateProcessingProfile(req)
+	if err != nil {
+		respondProfileError(c, "Failed to create profile", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// UpdateProfile handles PUT /api/profiles/:id
+// Replaces a profile; sources already processed with it are unchanged
+func UpdateProfile(c *gin.Context) {
+	id, ok := parseProfileID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ProcessingProfileRequest
+	if !bindProfileRequest(c, &req) {
+		return
+	}
+
+	profile, err := db.UpdateProcessingProfile(id, req)
+	if err != nil {
+		respondProfileError(c, "Failed to update profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile handles DELETE /api/profiles/:id
+func DeleteProfile(c *gin.Context) {
+	id, ok := parseProfileID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteProcessingProfile(id); err != nil {
+		respondProfileError(c, "Failed to delete profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile deleted successfully",
+	})
+}
+
+// parseProfileID parses the :id URL param, responding 400 when it isn't a number
+func parseProfileID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// bindProfileRequest binds and validates a profile, responding 400 when invalid
+func bindProfileRequest(c *gin.Context, req *models.ProcessingProfileRequest) bool {
+	if !bindJSON(c, req) {
+		return false
+	}
+
+	if err := services.ValidateProfile(*req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid profile",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// respondProfileError maps profile repo errors to a response
+func respondProfileError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "profile not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Profile not found",
+			"details": err.Error(),
+		})
+	case "profile name already exists":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Profile name already exists",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetPublicShare handles GET /public/shares/:token
+// Returns a share link's concept, with its quiz set, or content piece as JSON
+// for embedding. Unauthenticated and rate-limited per client IP.
+func GetPublicShare(c *gin.Context) {
+	share, err := services.GetPublicShare(c.Param("token"))
+	if err != nil {
+		switch err.Error() {
+		case "share link not found", "concept not found", "generated content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not found",
+				"details": "share link not found",
+			})
+		default:
+			log.Printf("Error retrieving public share: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to retrieve share",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	share.URL = sharePageURL(c, share.Token)
+	c.JSON(http.StatusOK, share)
+}
+
+// GetPublicAPISpec handles GET /public/openapi.json
+// Describes the public read API, and only it, as an OpenAPI 3.0 document
+func GetPublicAPISpec(c *gin.Context) {
+	c.JSON(http.StatusOK, publicAPISpec(publicBaseURL(c)))
+}
+
+// publicAPISpec builds the OpenAPI document for the public read API served at baseURL
+func publicAPISpec(baseURL string) gin.H {
+	str := gin.H{"type": "string"}
+	errorResponse := func(description string) gin.H {
+		return gin.H{
+			"description": description,
+			"content": gin.H{
+				"application/json": gin.H{"schema": gin.H{"$ref": "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	return gin.H{
+		"openapi": "3.0.3",
+		"info": gin.H{
+			"title":       "Lattice Public API",
+			"version":     "1.0.0",
+			"description": "Unauthenticated, read-only access to shared concepts and content. Requests are rate-limited per client IP.",
+		},
+		"servers": []gin.H{{"url": baseURL}},
+		"paths": gin.H{
+			"/public/shares/{token}": gin.H{
+				"get": gin.H{
+					"operationId": "getShare",
+					"summary":     "Get a shared concept or content piece",
+					"parameters": []gin.H{{
+						"name":        "token",
+						"in":          "path",
+						"required":    true,
+						"description": "The share link token",
+						"schema":      str,
+					}},
+					"responses": gin.H{
+						"200": gin.H{
+							"description": "The shared item",
+							"content": gin.H{
+								"application/json": gin.H{"schema": gin.H{"$ref": "#/components/schemas/Share"}},
+							},
+						},
+						"404": errorResponse("The link was revoked or never existed"),
+						"429": gin.H{
+							"description": "Rate limit exceeded",
+							"headers": gin.H{
+								"Retry-After": gin.H{
+									"description": "Seconds until the limit resets",
+									"schema":      gin.H{"type": "integer"},
+								},
+							},
+							"content": gin.H{
+								"application/json": gin.H{"schema": gin.H{"$ref": "#/components/schemas/Error"}},
+							},
+						},
+					},
+				},
+			},
+			"/public/openapi.json": gin.H{
+				"get": gin.H{
+					"operationId": "getOpenAPI",
+					"summary":     "Get this document",
+					"responses": gin.H{
+						"200": gin.H{"description": "The OpenAPI document"},
+					},
+				},
+			},
+		},
+		"components": gin.H{
+			"schemas": gin.H{
+				"Share": gin.H{
+					"type":     "object",
+					"required": []string{"token", "type", "url"},
+					"properties": gin.H{
+						"token":   str,
+						"type":    gin.H{"type": "string", "enum": []string{"concept", "content"}},
+						"url":     gin.H{"type": "string", "format": "uri", "description": "The public HTML page"},
+						"concept": gin.H{"$ref": "#/components/schemas/Concept"},
+						"content": gin.H{"$ref": "#/components/schemas/Content"},
+					},
+				},
+				"Concept": gin.H{
+					"type":     "object",
+					"required": []string{"title", "description", "tags", "quiz"},
+					"properties": gin.H{
+						"title":        str,
+						"description":  str,
+						"tags":         gin.H{"type": "array", "items": str},
+						"source_title": str,
+						"source_url":   gin.H{"type": "string", "format": "uri"},
+						"quiz":         gin.H{"type": "array", "items": gin.H{"$ref": "#/components/schemas/QuizQuestion"}},
+					},
+				},
+				"QuizQuestion": gin.H{
+					"type":     "object",
+					"required": []string{"question", "option_a", "option_b", "option_c", "option_d", "correct_answer", "explanation"},
+					"properties": gin.H{
+						"question":       str,
+						"option_a":       str,
+						"option_b":       str,
+						"option_c":       str,
+						"option_d":       str,
+						"correct_answer": gin.H{"type": "string", "enum": []string{"A", "B", "C", "D"}},
+						"explanation":    str,
+					},
+				},
+				"Content": gin.H{
+					"type":     "object",
+					"required": []string{"title", "body", "platform"},
+					"properties": gin.H{
+						"title":        str,
+						"body":         str,
+						"platform":     str,
+						"published_at": gin.H{"type": "string", "format": "date-time"},
+					},
+				},
+				"Error": gin.H{
+					"type":     "object",
+					"required": []string{"error"},
+					"properties": gin.H{
+						"error":   str,
+						"details": str,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ExplainQuizMore handles POST /api/quizzes/:id/explain-more
+// Returns a deeper explanation with an analogy and worked example. The result is
+// cached on the question; pass ?refresh=true to regenerate it.
+func ExplainQuizMore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	explanation, cached, err := quizService.ExplainMore(c.Request.Context(), id, c.Query("refresh") == "true")
+	if err != nil {
+		if err.Error() == "quiz question not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Quiz question not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error explaining quiz question %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate explanation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"explanation": explanation,
+		"cached":      cached,
+	})
+}
+
+// AnswerQuizQuestion handles POST /api/quizzes/:id/answer
+// Records an answer given outside a quiz session and reschedules the concept.
+// question_id may be left out of the body; if given it must match :id.
+func AnswerQuizQuestion(c *gin.Context) {
+	id, ok := parseQuizID(c)
+	if !ok {
+		return
+	}
+
+	req := models.AnswerQuizRequest{QuestionID: id}
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.QuestionID != id {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "question_id doesn't match the question in the URL",
+		})
+		return
+	}
+
+	resp, err := quizService.Answer(c.Request.Context(), req)
+	if err != nil {
+		if err.Error() == "quiz question not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Quiz question not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error answering quiz question %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to record answer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetLeeches handles GET /api/quizzes/leeches
+// Lists questions suspended after being failed repeatedly
+func GetLeeches(c *gin.Context) {
+	leeches, err := quizService.GetLeeches(c.Request.Context(), callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve leeches",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if leeches == nil {
+		leeches = []models.QuizQuestion{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leeches": leeches,
+		"count":   len(leeches),
+	})
+}
+
+// RegenerateLeech handles POST /api/quizzes/:id/leech/regenerate
+// Asks Claude for a simpler variant of a leech question
+func RegenerateLeech(c *gin.Context) {
+	id, ok := parseQuizID(c)
+	if !ok {
+		return
+	}
+
+	question, err := quizService.RegenerateLeech(c.Request.Context(), id)
+	if err != nil {
+		respondLeechError(c, "Failed to regenerate question", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// ConvertLeechToFlashcard handles POST /api/quizzes/:id/leech/flashcard
+// Converts a leech question into a front/back flashcard
+func ConvertLeechToFlashcard(c *gin.Context) {
+	id, ok := parseQuizID(c)
+	if !ok {
+		return
+	}
+
+	card, err := quizService.ConvertLeechToFlashcard(c.Request.Context(), id)
+	if err != nil {
+		respondLeechError(c, "Failed to create flashcard", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// UnsuspendQuizQuestion handles POST /api/quizzes/:id/unsuspend
+// Returns a question to sessions and resets its lapse count
+func UnsuspendQuizQuestion(c *gin.Context) {
+	id, ok := parseQuizID(c)
+	if !ok {
+		return
+	}
+
+	question, err := quizService.UnsuspendQuestion(c.Request.Context(), id)
+	if err != nil {
+		respondLeechError(c, "Failed to unsuspend question", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// parseQuizID parses the :id param, writing a 400 response when it is invalid
+func parseQuizID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondLeechError maps leech handling errors to HTTP responses
+func respondLeechError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "quiz question not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Quiz question not found",
+			"details": err.Error(),
+		})
+	case "quiz question is not a leech":
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// GetQuizAttempts handles GET /api/quizzes/attempts?question_id=&session_id=&concept_id=
+// Returns a page of quiz attempts, newest first. Pass next_cursor back as
+// ?cursor= for the following page.
+func GetQuizAttempts(c *gin.Context) {
+	var filter models.QuizAttemptFilter
+	for param, dest := range map[string]**int{
+		"question_id": &filter.QuestionID,
+		"session_id":  &filter.SessionID,
+		"concept_id":  &filter.ConceptID,
+	} {
+		if str := c.Query(param); str != "" {
+			parsed, err := strconv.Atoi(str)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid " + param,
+					"details": param + " must be a number",
+				})
+				return
+			}
+			*dest = &parsed
+		}
+	}
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	attempts, next, err := db.GetQuizAttempts(filter, page, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve quiz attempts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attempts":    attempts,
+		"count":       len(attempts),
+		"next_cursor": encodeCursor(next),
+	})
+}
+
+// GetQuizStats handles GET /api/quizzes/:id/stats
+// Returns attempt counts, accuracy, answer time, option counts, and discrimination
+func GetQuizStats(c *gin.Context) {
+	id, ok := parseQuizID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := quizService.GetQuestionStats(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "quiz question not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Quiz question not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve quiz stats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var quizService *services.QuizService
+
+// InitQuizService initializes the quiz service
+func InitQuizService() error {
+	var err error
+	quizService, err = services.NewQuizService()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateQuizSession handles POST /api/quiz-sessions
+// Starts a session over a concept's or source's questions with shuffled options
+func CreateQuizSession(c *gin.Context) {
+	var req models.CreateQuizSessionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerScope(c)
+	req.UserID = callerUserID(c)
+
+	detail, err := quizService.StartSession(c.Request.Context(), req)
+	if err != nil {
+		switch err.Error() {
+		case "concept not found", "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not found",
+				"details": err.Error(),
+			})
+		case "exactly one of source_content_id or concept_id is required",
+			"no quiz questions available",
+			"concept is archived":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to start quiz session",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, detail)
+}
+
+// GetQuizSession handles GET /api/quiz-sessions/:id
+// Returns the session's questions exactly as they were served
+func GetQuizSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	detail, err := quizService.GetSession(c.Request.Context(), id)
+	if err != nil {
+		if err.Error() == "quiz session not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "quiz session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve quiz session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// AnswerQuizSessionQuestion handles POST /api/quiz-sessions/:id/answers
+// selected_answer is the letter as displayed in this session
+func AnswerQuizSessionQuestion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.AnswerQuizRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	resp, err := quizService.AnswerInSession(c.Request.Context(), id, req)
+	if err != nil {
+		if err.Error() == "question is not part of this quiz session" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "quiz question not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "quiz question not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to record answer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// StartRecycling suggests published content to recycle in the background every interval
+func StartRecycling(ctx context.Context, interval time.Duration) {
+	background.Go(func() { sourceContentService.StartRecycling(ctx, interval) })
+}
+
+// RecordContentEngagement handles PUT /api/content/:id/engagement
+// Imports a published post's engagement, replacing what was imported before
+func RecordContentEngagement(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecordEngagementRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	content, err := sourceContentService.RecordEngagement(id, req)
+	if err != nil {
+		respondRecyclingError(c, err, "Failed to record engagement")
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// RecycleGeneratedContent handles POST /api/content/:id/recycle
+// Generates a refreshed draft of a published post that notes what changed since
+func RecycleGeneratedContent(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecycleContentRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	content, err := sourceContentService.RecycleContent(c.Request.Context(), id, req)
+	if err != nil {
+		respondRecyclingError(c, err, "Failed to recycle content")
+		return
+	}
+
+	c.JSON(http.StatusCreated, content)
+}
+
+// GetRecycleSuggestions handles GET /api/recycling/suggestions?include_dismissed=
+// Returns the suggestions for posts not recycled since, newest first
+func GetRecycleSuggestions(c *gin.Context) {
+	var req models.RecycleSuggestionsQuery
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	suggestions, err := sourceContentService.GetRecycleSuggestions(req.IncludeDismissed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve recycle suggestions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// RunRecycling handles POST /api/recycling/run
+// Looks for content to recycle now rather than waiting for the schedule, and
+// returns the new suggestions
+func RunRecycling(c *gin.Context) {
+	suggestions, err := sourceContentService.SuggestRecycling(c.Request.Context())
+	if err != nil {
+		log.Printf("Error suggesting content to recycle: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to suggest content to recycle",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// DismissRecycleSuggestion handles POST /api/recycling/suggestions/:id/dismiss
+func DismissRecycleSuggestion(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	suggestion, err := db.DismissRecycleSuggestion(id)
+	if err != nil {
+		if err.Error() == "recycle suggestion not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Recycle suggestion not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to dismiss recycle suggestion",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestion)
+}
+
+// respondRecyclingError maps recycling service errors to responses
+func respondRecyclingError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "generated content not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Generated content not found",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrNotPublished), errors.Is(err, services.ErrNoConceptsLeft):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// CreateConceptRelationship handles POST /api/concepts/:id/relationships
+// Links the concept to another as a prerequisite of it, related, or
+// contradicting it
+func CreateConceptRelationship(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateConceptRelationshipRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.ToConceptID == id {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid relationship",
+			"details": "a concept can't be related to itself",
+		})
+		return
+	}
+
+	relationship, err := db.CreateConceptRelationship(id, req, callerScope(c))
+	if err != nil {
+		respondRelationshipError(c, "Failed to create relationship", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, relationship)
+}
+
+// GetConceptRelationships handles GET /api/relationships
+// Lists edges of the concept graph between concepts the caller can see,
+// optionally only those touching
+// ?concept_id=, within ?source_content_id=, or of ?type=
+func GetConceptRelationships(c *gin.Context) {
+	var query models.ConceptRelationshipQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	relationships, err := db.GetConceptRelationships(query, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve relationships",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"relationships": relationships,
+		"count":         len(relationships),
+	})
+}
+
+// DeleteConceptRelationship handles DELETE /api/relationships/:id
+func DeleteConceptRelationship(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteConceptRelationship(id); err != nil {
+		respondRelationshipError(c, "Failed to delete relationship", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "relationship deleted successfully"})
+}
+
+// SuggestConceptRelationships handles POST /api/source-content/:id/relationships/suggest
+// Returns Claude's suggested relationships among the source's concepts, in
+// the shape POST /api/concepts/:id/relationships takes. Nothing is saved.
+func SuggestConceptRelationships(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := conceptService.SuggestRelationships(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("Error suggesting relationships for source %d: %v", id, err)
+		respondRelationshipError(c, "Failed to suggest relationships", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// respondRelationshipError maps concept relationship errors to responses
+func respondRelationshipError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "concept not found", "source content not found", "relationship not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"details": err.Error(),
+		})
+	case "relationship already exists", "relationship would create a prerequisite cycle", "source has fewer than two concepts":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/config"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// reloadableConfig are the variables a reload puts into effect: the
+// workspace settings' environment layer, prompts, and moderation, plus those
+// read each time they're used. Everything else is read once at startup.
+var reloadableConfig = []string{
+	"AUTH_SUCCESS_REDIRECT",
+	"CLAUDE_MONTHLY_TOKEN_LIMIT",
+	"CLAUDE_MODEL",
+	"CONCEPTS_MAX",
+	"CONCEPTS_MIN",
+	"DISABLED_FEATURES",
+	"GEMINI_MODEL",
+	"MODERATION_BANNED_TERMS",
+	"MODERATION_CHECKS",
+	"MODERATION_GUIDELINES",
+	"OLLAMA_MODEL",
+	"OPENAI_MODEL",
+	"PROMPTS_DIR",
+	"PUBLIC_BASE_URL",
+	"QUIZ_DUPLICATE_THRESHOLD",
+	"SESSION_TTL",
+	"SIGNUP_EMAIL_DOMAINS",
+	"TIMEZONE",
+}
+
+// reloadMu keeps a SIGHUP and an API reload from interleaving
+var reloadMu sync.Mutex
+
+// ReloadConfiguration reads .env and PROMPTS_DIR again and puts the settings,
+// prompts, and moderation checks they configure into effect. Requests and
+// job stages that start afterwards use them; those in flight finish as they
+// began. Every part is loaded before any takes effect, so when one fails to
+// load the previous configuration stays in effect, .env's variables
+// included, and the error names the part.
+func ReloadConfiguration() (*models.ConfigReload, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	changed, revert, err := config.Reload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	moderation, err := services.NewModerationService()
+	if err != nil {
+		revert()
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	activateSettings, err := services.PrepareSettings()
+	if err != nil {
+		revert()
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+	activatePrompts, err := services.PreparePrompts()
+	if err != nil {
+		revert()
+		return nil, fmt.Errorf("prompts: %w", err)
+	}
+
+	moderationService.Store(moderation)
+	activateSettings()
+	activatePrompts()
+
+	reload := &models.ConfigReload{
+		Changed:         changed,
+		RestartRequired: []string{},
+		Prompts:         services.PromptOverrides(),
+		ReloadedAt:      time.Now(),
+	}
+	for _, key := range changed {
+		if !slices.Contains(reloadableConfig, key) {
+			reload.RestartRequired = append(reload.RestartRequired, key)
+		}
+	}
+
+	return reload, nil
+}
+
+// StartReloadOnHangup reloads the configuration each time the server gets
+// SIGHUP, such as from systemctl reload, until ctx is done
+func StartReloadOnHangup(ctx context.Context) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	background.Go(func() {
+		defer signal.Stop(hangups)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangups:
+			}
+
+			reload, err := ReloadConfiguration()
+			if err != nil {
+				log.Printf("Error reloading configuration: %v", err)
+				continue
+			}
+			log.Printf("Reloaded configuration: changed %v, restart required for %v", reload.Changed, reload.RestartRequired)
+		}
+	})
+}
+
+// ReloadConfig handles POST /api/admin/reload
+// Reloads .env and PROMPTS_DIR without restarting the server, as SIGHUP does,
+// and lists the changed variables that still need a restart
+func ReloadConfig(c *gin.Context) {
+	reload, err := ReloadConfiguration()
+	if err != nil {
+		log.Printf("Error reloading configuration: %v", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Failed to reload configuration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, reload)
+}
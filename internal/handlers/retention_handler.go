@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+var retentionService *services.RetentionService
+
+// InitRetentionService initializes the retention service
+func InitRetentionService() error {
+	var err error
+	retentionService, err = services.NewRetentionService()
+	return err
+}
+
+// StartRetention starts enforcing retention policies in the background
+func StartRetention(ctx context.Context, interval time.Duration) {
+	background.Go(func() { retentionService.StartRetention(ctx, interval) })
+}
+
+// GetRetentionReport handles GET /api/retention/report
+// Dry run: lists what the retention policies would remove now, removing nothing
+func GetRetentionReport(c *gin.Context) {
+	c.JSON(http.StatusOK, retentionService.Run(c.Request.Context(), true))
+}
+
+// RunRetention handles POST /api/retention/run
+// Applies the retention policies now rather than waiting for the schedule.
+// Policies that failed are listed in the report's errors.
+func RunRetention(c *gin.Context) {
+	c.JSON(http.StatusOK, retentionService.Run(c.Request.Context(), false))
+}
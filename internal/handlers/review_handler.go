@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// StartFSRSOptimizer refits the FSRS weights in the background every interval
+func StartFSRSOptimizer(ctx context.Context, interval time.Duration) {
+	background.Go(func() { services.StartFSRSOptimizer(ctx, interval) })
+}
+
+// GetDueReviews handles GET /api/reviews/due
+// Returns the daily review queue: concepts whose next review has passed, most
+// overdue first, each with a question, up to what's left of the review
+// daily_limit after the concepts reviewed today in the caller's timezone
+func GetDueReviews(c *gin.Context) {
+	queue, err := services.GetDueReviews(callerLocation(c), callerScope(c))
+	if err != nil {
+		log.Printf("Error listing due reviews: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve due reviews",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// CompleteReview handles POST /api/reviews/:concept_id/complete
+// Advances a concept's schedule from the learner's own rating of their recall
+func CompleteReview(c *gin.Context) {
+	conceptID, err := strconv.Atoi(c.Param("concept_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid concept ID",
+			"details": "concept_id must be a number",
+		})
+		return
+	}
+
+	var req models.CompleteReviewRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	progress, err := services.CompleteReview(conceptID, req.Rating)
+	if err != nil {
+		if err.Error() == "concept not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "concept not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to complete review",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetReviewSession handles GET /api/reviews/session?tag=&source_content_id=&collection_id=
+// Starts a themed review session of due-or-weak questions for a tag, source,
+// and/or collection
+func GetReviewSession(c *gin.Context) {
+	req := models.ReviewSessionRequest{Limit: 20}
+
+	if tag := c.Query("tag"); tag != "" {
+		req.Tag = &tag
+	}
+
+	if sourceStr := c.Query("source_content_id"); sourceStr != "" {
+		sourceID, err := strconv.Atoi(sourceStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid source_content_id",
+				"details": "source_content_id must be a number",
+			})
+			return
+		}
+		req.SourceContentID = &sourceID
+	}
+
+	if collectionStr := c.Query("collection_id"); collectionStr != "" {
+		collectionID, err := strconv.Atoi(collectionStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid collection_id",
+				"details": "collection_id must be a number",
+			})
+			return
+		}
+		req.CollectionID = &collectionID
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit",
+				"details": "limit must be between 1 and 100",
+			})
+			return
+		}
+		req.Limit = parsed
+	}
+
+	req.Location = callerLocation(c)
+	req.OwnerID = callerScope(c)
+	req.UserID = callerUserID(c)
+
+	detail, err := quizService.StartReviewSession(c.Request.Context(), req)
+	if err != nil {
+		if err.Error() == "tag, source_content_id, or collection_id is required" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "collection not found" || errors.Is(err, query.ErrInvalidQuery) {
+			respondCollectionError(c, "Failed to start review session", err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start review session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if detail == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"session":   nil,
+			"questions": []interface{}{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, detail)
+}
+
+// OptimizeFSRS handles POST /api/reviews/optimize?dry_run=
+// Fits the FSRS weights to the review history and, unless dry_run, applies them
+// when they predict recall better than the weights in effect
+func OptimizeFSRS(c *gin.Context) {
+	var req models.OptimizeFSRSRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	optimization, err := services.OptimizeFSRS(c.Request.Context(), req.DryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrNotEnoughReviews) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "Not enough review history",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error optimizing FSRS weights: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to optimize FSRS weights",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, optimization)
+}
+
+// GetFSRSOptimizations handles GET /api/reviews/optimizations
+// Returns the 20 most recent optimization runs, newest first
+func GetFSRSOptimizations(c *gin.Context) {
+	optimizations, err := services.GetFSRSOptimizations(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve FSRS optimizations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"optimizations": optimizations})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/llm"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// embeddingService is nil when no embedding provider is configured
+var embeddingService *services.EmbeddingService
+
+// InitEmbeddingService initializes the embedding service. Without an
+// embedding provider, semantic search is turned off rather than failing.
+func InitEmbeddingService() error {
+	service, err := services.NewEmbeddingService()
+	if errors.Is(err, llm.ErrNoEmbedder) {
+		log.Printf("Semantic search is off: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	embeddingService = service
+	return nil
+}
+
+// StartEmbedding embeds concepts in the background, when semantic search is on
+func StartEmbedding(ctx context.Context) {
+	if embeddingService != nil {
+		background.Go(func() { embeddingService.StartEmbedding(ctx) })
+	}
+}
+
+// Autocomplete handles GET /api/autocomplete?q=
+// Returns lightweight concept, tag, and source suggestions for a search box
+func Autocomplete(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusOK, gin.H{
+			"suggestions": []interface{}{},
+			"count":       0,
+		})
+		return
+	}
+
+	limit := 5
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit",
+				"details": "limit must be between 1 and 20",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := db.Autocomplete(q, limit, callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve suggestions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// Query handles POST /api/query
+// Returns a page of the concepts or sources matching a filter query such as
+// tag:golang AND mastery<3 AND created>2024-01-01, newest first. Pass
+// next_cursor back as "cursor" for the following page.
+func Query(c *gin.Context) {
+	var req models.QueryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	node, err := query.Parse(req.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	page := models.Page{Limit: 50}
+	if req.Limit != 0 {
+		page.Limit = req.Limit
+	}
+	if req.Cursor != "" {
+		cursor, err := models.DecodeCursor(req.Cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid cursor",
+				"details": "cursor must be a next_cursor value from a previous page",
+			})
+			return
+		}
+		page.After = cursor
+	}
+
+	from := req.From
+	if from == "" {
+		from = models.QueryFromConcepts
+	}
+
+	var results interface{}
+	var count int
+	var next *models.Cursor
+	if from == models.QueryFromSources {
+		sources, cursor, queryErr := db.QuerySources(node, callerLocation(c), req.IncludeArchived, callerScope(c), page)
+		results, count, next, err = sources, len(sources), cursor, queryErr
+	} else {
+		concepts, cursor, queryErr := db.QueryConcepts(node, callerLocation(c), req.IncludeArchived, callerScope(c), page)
+		results, count, next, err = concepts, len(concepts), cursor, queryErr
+	}
+	if errors.Is(err, query.ErrInvalidQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to run query",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        from,
+		"results":     results,
+		"count":       count,
+		"next_cursor": encodeCursor(next),
+	})
+}
+
+// SearchConcepts handles GET /api/concepts/search?q=
+// Returns the concepts closest in meaning to q, most similar first, across
+// every source
+func SearchConcepts(c *gin.Context) {
+	var query models.ConceptSearchQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if embeddingService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Semantic search is not configured",
+			"details": llm.ErrNoEmbedder.Error(),
+		})
+		return
+	}
+
+	matches, err := embeddingService.SearchConcepts(c.Request.Context(), query.Q, query.Limit, callerScope(c))
+	if err != nil {
+		log.Printf("Error searching concepts: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, llm.ErrAPIError) || errors.Is(err, llm.ErrRateLimitExceeded) || errors.Is(err, llm.ErrTimeout) {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to search concepts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"concepts": matches,
+		"count":    len(matches),
+	})
+}
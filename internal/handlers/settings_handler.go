@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetSettings handles GET /api/settings
+// Returns the workspace settings in effect and the overrides set through the API
+func GetSettings(c *gin.Context) {
+	settings, err := services.GetSettings()
+	if err != nil {
+		log.Printf("Error getting settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles PATCH /api/settings
+// Overrides the given settings and resets those listed in "reset" to the
+// environment's values. Changes apply immediately, without a restart.
+func UpdateSettings(c *gin.Context) {
+	var req models.UpdateSettingsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := services.UpdateSettings(req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid settings",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error updating settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// RescheduleReviews handles POST /api/reviews/reschedule
+// Moves every reviewed concept onto the current review algorithm's schedule.
+// Changing the algorithm does this already; this retries it if that failed.
+func RescheduleReviews(c *gin.Context) {
+	rescheduled, err := services.RescheduleReviews()
+	if err != nil {
+		log.Printf("Error rescheduling reviews: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reschedule reviews",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"algorithm":   services.CurrentSettings().Review.Algorithm,
+		"rescheduled": rescheduled,
+	})
+}
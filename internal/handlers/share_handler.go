@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// ShareConcept handles POST /api/concepts/:id/share
+// Returns the concept's public page link, creating it on first share
+func ShareConcept(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	link, err := services.ShareConcept(id)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	link.URL = sharePageURL(c, link.Token)
+	c.JSON(http.StatusOK, link)
+}
+
+// ShareGeneratedContent handles POST /api/content/:id/share
+// Returns the content's public page link, creating it on first share
+func ShareGeneratedContent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	link, err := services.ShareGeneratedContent(id)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	link.URL = sharePageURL(c, link.Token)
+	c.JSON(http.StatusOK, link)
+}
+
+// GetShareLinks handles GET /api/shares
+// Returns the links to items the caller can see, newest first
+func GetShareLinks(c *gin.Context) {
+	links, err := db.GetShareLinks(callerScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve share links",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for i := range links {
+		links[i].URL = sharePageURL(c, links[i].Token)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": links,
+		"count":  len(links),
+	})
+}
+
+// DeleteShareLink handles DELETE /api/shares/:token
+// Revokes a share link; its page stops resolving
+func DeleteShareLink(c *gin.Context) {
+	if err := db.DeleteShareLink(c.Param("token"), callerScope(c)); err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Share link revoked successfully",
+	})
+}
+
+// GetSharePage handles GET /share/:token
+// Serves the public HTML page, with OpenGraph and Twitter card metadata, for a share link
+func GetSharePage(c *gin.Context) {
+	token := c.Param("token")
+
+	html, err := services.RenderSharePage(token, sharePageURL(c, token))
+	if err != nil {
+		switch err.Error() {
+		case "share link not found", "concept not found", "generated content not found":
+			c.Data(http.StatusNotFound, "text/html; charset=utf-8", []byte("<!DOCTYPE html><title>Not found</title><p>This link has expired or never existed.</p>"))
+		default:
+			log.Printf("Error rendering share page: %v", err)
+			c.Data(http.StatusInternalServerError, "text/html; charset=utf-8", []byte("<!DOCTYPE html><title>Error</title><p>Something went wrong.</p>"))
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
+
+// sharePageURL returns the absolute URL of a share page
+func sharePageURL(c *gin.Context, token string) string {
+	return publicBaseURL(c) + "/share/" + token
+}
+
+// publicBaseURL returns the API's public origin: PUBLIC_BASE_URL when set,
+// otherwise the request's scheme and host
+func publicBaseURL(c *gin.Context) string {
+	if base := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/"); base != "" {
+		return base
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// respondShareError maps share errors to a response
+func respondShareError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "concept not found", "generated content not found", "share link not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Not found",
+			"details": err.Error(),
+		})
+	case "concept is archived":
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Concept is archived",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to share",
+			"details": err.Error(),
+		})
+	}
+}
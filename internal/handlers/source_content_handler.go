@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/mostlyerror/lattice/internal/db"
 	"github.com/mostlyerror/lattice/internal/models"
 	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/youtube"
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,16 +28,13 @@ func InitSourceContentService() error {
 }
 
 // ProcessSourceContent handles POST /api/source-content
-// Processes a new YouTube URL through the full pipeline
+// Queues a new YouTube URL for the full pipeline and returns its job at once;
+// poll GET /api/jobs/:id for progress
 func ProcessSourceContent(c *gin.Context) {
 	var req models.CreateSourceContentRequest
 
 	// Bind and validate request
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -46,29 +47,60 @@ func ProcessSourceContent(c *gin.Context) {
 		return
 	}
 
-	// Process the YouTube URL
-	log.Printf("Processing source content request: type=%s, url=%s", req.Type, req.URL)
+	req.OwnerID = callerUserID(c)
+	log.Printf("Queueing source content request: type=%s, url=%s", req.Type, req.URL)
 
-	result, err := sourceContentService.ProcessYouTubeURL(c.Request.Context(), req.URL)
+	job, err := sourceContentService.EnqueueSourceContent(req)
 	if err != nil {
-		log.Printf("Error processing source content: %v", err)
+		if errors.Is(err, youtube.ErrInvalidURL) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid URL",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "pipeline not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Pipeline not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "profile not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Profile not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error queueing source content: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to process source content",
+			"error":   "Failed to queue source content",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	log.Printf("Successfully processed source content ID: %d", result.SourceContent.ID)
-
-	// Return the full result
-	c.JSON(http.StatusCreated, result)
+	log.Printf("Queued source content as job %d", job.ID)
+	c.JSON(http.StatusAccepted, job)
 }
 
 // GetSourceContents handles GET /api/source-content
-// Returns all source contents
+// Returns a page of source contents, newest first, filtered by type and
+// creation date (archived ones only with ?include_archived=true)
 func GetSourceContents(c *gin.Context) {
-	contents, err := db.GetAllSourceContents()
+	var query models.SourceContentListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	query.OwnerID = callerScope(c)
+
+	page, ok := parsePage(c, 50)
+	if !ok {
+		return
+	}
+
+	contents, next, err := db.GetSourceContents(query, page)
 	if err != nil {
 		log.Printf("Error getting source contents: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -81,6 +113,7 @@ func GetSourceContents(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"source_contents": contents,
 		"count":           len(contents),
+		"next_cursor":     encodeCursor(next),
 	})
 }
 
@@ -113,7 +146,7 @@ func GetSourceContent(c *gin.Context) {
 }
 
 // GetSourceContentConcepts handles GET /api/source-content/:id/concepts
-// Returns all concepts for a source content
+// Returns all concepts for a source content (archived ones only with ?include_archived=true)
 func GetSourceContentConcepts(c *gin.Context) {
 	// Parse ID from URL
 	idStr := c.Param("id")
@@ -127,7 +160,7 @@ func GetSourceContentConcepts(c *gin.Context) {
 	}
 
 	// Get concepts
-	concepts, err := db.GetConceptsBySourceContentID(id)
+	concepts, err := db.GetConceptsBySourceContentID(id, c.Query("include_archived") == "true")
 	if err != nil {
 		log.Printf("Error getting concepts for source content %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -136,6 +169,62 @@ func GetSourceContentConcepts(c *gin.Context) {
 		})
 		return
 	}
+	services.AttachResonance(concepts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"concepts": concepts,
+		"count":    len(concepts),
+	})
+}
+
+// ReorderSourceContentConcepts handles PUT /api/source-content/:id/concepts/order
+// Sets the teaching order of a source's concepts
+func ReorderSourceContentConcepts(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.ReorderConceptsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	seen := make(map[int]bool, len(req.ConceptIDs))
+	for _, conceptID := range req.ConceptIDs {
+		if seen[conceptID] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": fmt.Sprintf("concept %d is listed more than once", conceptID),
+			})
+			return
+		}
+		seen[conceptID] = true
+	}
+
+	if err := db.ReorderConcepts(id, req.ConceptIDs); err != nil {
+		log.Printf("Error reordering concepts for source content %d: %v", id, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to reorder concepts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(id, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve concepts",
+			"details": err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"concepts": concepts,
@@ -143,6 +232,241 @@ func GetSourceContentConcepts(c *gin.Context) {
 	})
 }
 
+// GetSourceContentTranscript handles GET /api/source-content/:id/transcript
+// Returns the transcript in segments with markers where each concept's
+// supporting text occurs
+func GetSourceContentTranscript(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	view, err := services.BuildTranscriptView(id)
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error building transcript view for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve transcript",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// RetrySourceContentStage handles POST /api/source-content/:id/retry
+// Re-runs one stage of the pipeline, such as the parts a run's warnings name
+func RetrySourceContentStage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.RetryStageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if (len(req.ConceptIDs) > 0 && req.Stage != models.StageQuizzes) || ((len(req.Platforms) > 0 || req.TargetGrade != nil) && req.Stage != models.StageContent) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "concept_ids only apply to the quizzes stage, and platforms and target_grade to the content stage",
+		})
+		return
+	}
+
+	result, err := sourceContentService.RetryStage(c.Request.Context(), id, req)
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error retrying %s for source content %d: %v", req.Stage, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retry stage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ResumeSourceContentPipeline handles POST /api/source-content/:id/resume
+// Finishes a pipeline run left incomplete by a restart or a failed concept extraction
+func ResumeSourceContentPipeline(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	result, err := sourceContentService.ResumePipeline(c.Request.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+		case "pipeline run is not incomplete":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Nothing to resume",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error resuming pipeline for source content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to resume pipeline",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CorrectSourceContentTranscript handles PATCH /api/source-content/:id/transcript
+// Applies a manual transcript correction, optionally re-running extraction
+func CorrectSourceContentTranscript(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.CorrectTranscriptRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if req.Transcript == nil && len(req.Replacements) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "transcript or replacements is required",
+		})
+		return
+	}
+
+	result, err := sourceContentService.CorrectTranscript(c.Request.Context(), id, req)
+	if err != nil {
+		switch err.Error() {
+		case "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+		case "corrected transcript is empty":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error correcting transcript for source content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to correct transcript",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSourceContentWorksheet handles GET /api/source-content/:id/worksheet
+// Returns a printable HTML worksheet of the source's concepts and questions,
+// with the answer key on a separate page unless ?answers=false
+func GetSourceContentWorksheet(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	html, err := services.RenderWorksheet(id, c.Query("answers") != "false")
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error rendering worksheet for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to render worksheet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
+
+// GetSourceContentMindmap handles GET /api/source-content/:id/mindmap
+// Returns a Mermaid mind map of the source's concepts and their sub-points, as
+// plain text ready to paste into docs or render
+func GetSourceContentMindmap(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	mindmap, err := services.RenderMindmap(id)
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error rendering mind map for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to render mind map",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(mindmap))
+}
+
 // GetSourceContentQuizzes handles GET /api/source-content/:id/quizzes
 // Returns all quizzes for a source content
 func GetSourceContentQuizzes(c *gin.Context) {
@@ -174,6 +498,220 @@ func GetSourceContentQuizzes(c *gin.Context) {
 	})
 }
 
+// GetSourceContentGlossary handles GET /api/source-content/:id/glossary
+// Returns the glossary extracted from a source content
+func GetSourceContentGlossary(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	terms, err := db.GetGlossaryTermsBySourceContentID(id)
+	if err != nil {
+		log.Printf("Error getting glossary for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve glossary",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"terms": terms,
+		"count": len(terms),
+	})
+}
+
+// GetSourceContentActionItems handles GET /api/source-content/:id/action-items
+// Returns the action items extracted from a source content
+func GetSourceContentActionItems(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	items, err := db.GetActionItems(&id, nil, nil)
+	if err != nil {
+		log.Printf("Error getting action items for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve action items",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"action_items": items,
+		"count":        len(items),
+	})
+}
+
+// GetSourceContentMentions handles GET /api/source-content/:id/mentions
+// Returns the books, tools, people, and frameworks named in a source content
+func GetSourceContentMentions(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	mentions, err := db.GetMentionsBySourceContentID(id)
+	if err != nil {
+		log.Printf("Error getting mentions for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve mentions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mentions": mentions,
+		"count":    len(mentions),
+	})
+}
+
+// DiscussSourceContent handles POST /api/source-content/:id/chat
+// Answers a question about one source from its transcript and concepts
+func DiscussSourceContent(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.SourceChatRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if strings.TrimSpace(req.Message) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "message cannot be empty",
+		})
+		return
+	}
+
+	resp, err := sourceContentService.Discuss(c.Request.Context(), id, req)
+	if err != nil {
+		switch err.Error() {
+		case "source content not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+		case "chat not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Chat not found",
+				"details": err.Error(),
+			})
+		default:
+			log.Printf("Error discussing source content %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to answer message",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSourceContentChats handles GET /api/source-content/:id/chats
+// Returns a source's chats, newest first
+func GetSourceContentChats(c *gin.Context) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	chats, err := db.GetSourceChatsBySourceContentID(id)
+	if err != nil {
+		log.Printf("Error getting chats for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve chats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chats": chats,
+		"count": len(chats),
+	})
+}
+
+// GetSourceContentChat handles GET /api/source-content/:id/chats/:chatId
+// Returns a source chat with its messages
+func GetSourceContentChat(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	chatID, err := strconv.Atoi(c.Param("chatId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid chat ID",
+			"details": "chat ID must be a number",
+		})
+		return
+	}
+
+	detail, err := sourceContentService.GetChat(c.Request.Context(), id, chatID)
+	if err != nil {
+		if err.Error() == "chat not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Chat not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error getting chat %d: %v", chatID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve chat",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
 // GetSourceContentGeneratedContent handles GET /api/source-content/:id/content
 // Returns all generated content for a source content
 func GetSourceContentGeneratedContent(c *gin.Context) {
@@ -189,7 +727,7 @@ func GetSourceContentGeneratedContent(c *gin.Context) {
 	}
 
 	// Get concepts first (to get concept IDs)
-	concepts, err := db.GetConceptsBySourceContentID(id)
+	concepts, err := db.GetConceptsBySourceContentID(id, true)
 	if err != nil {
 		log.Printf("Error getting concepts for source content %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -226,6 +764,50 @@ func GetSourceContentGeneratedContent(c *gin.Context) {
 	})
 }
 
+// ArchiveSourceContent handles POST /api/source-content/:id/archive
+// Hides a source and its concepts from lists, search, review, and generation
+func ArchiveSourceContent(c *gin.Context) {
+	setSourceContentArchived(c, true)
+}
+
+// UnarchiveSourceContent handles POST /api/source-content/:id/unarchive
+func UnarchiveSourceContent(c *gin.Context) {
+	setSourceContentArchived(c, false)
+}
+
+// setSourceContentArchived applies the archive flag for the archive/unarchive handlers
+func setSourceContentArchived(c *gin.Context, archived bool) {
+	// Parse ID from URL
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	content, err := db.SetSourceContentArchived(id, archived)
+	if err != nil {
+		if err.Error() == "source content not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Source content not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error updating archive state for source content %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update source content",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
 // DeleteSourceContent handles DELETE /api/source-content/:id
 // Deletes a source content and all related data
 func DeleteSourceContent(c *gin.Context) {
@@ -240,8 +822,8 @@ func DeleteSourceContent(c *gin.Context) {
 		return
 	}
 
-	// Delete source content
-	// Note: This should cascade delete related records if foreign keys are set up properly
+	// Delete source content. Its concepts, quizzes, glossary, and other
+	// per-source records cascade; generated content is kept, unlinked from them.
 	err = db.DeleteSourceContent(id)
 	if err != nil {
 		log.Printf("Error deleting source content %d: %v", id, err)
@@ -251,9 +833,39 @@ func DeleteSourceContent(c *gin.Context) {
 		})
 		return
 	}
+	services.RemoveAudioSummaryFiles(c.Request.Context(), id)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Source content deleted successfully",
 		"id":      id,
 	})
 }
+
+// DeleteSourceContents handles DELETE /api/source-content?before=YYYY-MM-DD
+// Bulk-deletes sources created before a date, optionally only of ?type= or
+// only ?archived=true ones. Runs dry by default, returning what would be
+// removed; pass ?dry_run=false to delete.
+func DeleteSourceContents(c *gin.Context) {
+	var req models.BulkDeleteSourceContentsRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	req.OwnerID = callerScope(c)
+
+	dryRun := req.DryRun == nil || *req.DryRun
+	result, err := db.DeleteSourceContents(req, dryRun)
+	if err != nil {
+		log.Printf("Error bulk deleting source content: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete source content",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !dryRun {
+		services.RemoveAudioSummaryFiles(c.Request.Context(), result.IDs...)
+		log.Printf("Bulk deleted %d source contents created before %s", len(result.IDs), req.Before.Format("2006-01-02"))
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/storage"
+)
+
+// ServeStoredFile handles GET /files/*key
+// Serves a locally stored object to holders of a valid signed URL. Remote
+// stores sign their own URLs, so this route only serves local disk storage.
+func ServeStoredFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	local, ok := storage.Store.(*storage.LocalStorage)
+	if !ok || !local.VerifySignedURL(key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Invalid or expired link",
+			"details": "signed URL is invalid or has expired",
+		})
+		return
+	}
+
+	file, err := local.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "File not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		log.Printf("Error opening stored file %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to open file",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Printf("Error serving stored file %s: %v", key, err)
+	}
+}
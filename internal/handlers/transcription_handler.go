@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/transcription"
+)
+
+// CreateTranscription handles POST /api/transcriptions
+// Registers a source that AssemblyAI, Deepgram, or Rev will transcribe, and
+// returns the callback URL to submit the job with
+func CreateTranscription(c *gin.Context) {
+	var req models.CreateTranscriptionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.OwnerID = callerUserID(c)
+
+	pending, err := services.CreatePendingTranscription(req)
+	if err != nil {
+		respondTranscriptionError(c, err)
+		return
+	}
+
+	pending.CallbackURL = transcriptionCallbackURL(c, pending.Token)
+	c.JSON(http.StatusCreated, pending)
+}
+
+// GetTranscriptions handles GET /api/transcriptions
+// Returns pending transcriptions, newest first, optionally filtered by status
+func GetTranscriptions(c *gin.Context) {
+	var query models.TranscriptionsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	transcriptions, err := db.GetPendingTranscriptions(query.Status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve transcriptions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for i := range transcriptions {
+		transcriptions[i].CallbackURL = transcriptionCallbackURL(c, transcriptions[i].Token)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transcriptions": transcriptions,
+		"count":          len(transcriptions),
+	})
+}
+
+// GetTranscription handles GET /api/transcriptions/:id
+// Returns a pending transcription, with the source it became once processed
+func GetTranscription(c *gin.Context) {
+	id, ok := parseContentID(c)
+	if !ok {
+		return
+	}
+
+	pending, err := db.GetPendingTranscriptionByID(id)
+	if err != nil {
+		respondTranscriptionError(c, err)
+		return
+	}
+
+	pending.CallbackURL = transcriptionCallbackURL(c, pending.Token)
+	c.JSON(http.StatusOK, pending)
+}
+
+// ReceiveTranscription handles POST /webhooks/transcriptions/:token
+// Accepts a provider's callback for a pending transcription, authenticated
+// by the token in its URL. Responds 202 while the pipeline runs.
+func ReceiveTranscription(c *gin.Context) {
+	// Read one byte past the limit so oversized bodies are rejected, not truncated
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, services.MaxTranscriptUpload+1))
+	if err != nil {
+		respondTranscriptionError(c, err)
+		return
+	}
+	if len(body) > services.MaxTranscriptUpload {
+		respondTranscriptionError(c, services.ErrTranscriptTooLarge)
+		return
+	}
+
+	pending, err := sourceContentService.ReceiveTranscription(c.Request.Context(), c.Param("token"), body)
+	if err != nil {
+		respondTranscriptionError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if pending.Status == models.TranscriptionProcessing {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, gin.H{
+		"id":     pending.ID,
+		"status": pending.Status,
+	})
+}
+
+// transcriptionCallbackURL returns the absolute URL a provider calls back
+func transcriptionCallbackURL(c *gin.Context, token string) string {
+	return publicBaseURL(c) + "/webhooks/transcriptions/" + token
+}
+
+// respondTranscriptionError maps pending transcription errors to responses
+func respondTranscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, transcription.ErrInvalidCallback):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrTranscriptTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "Transcript too large",
+			"details": err.Error(),
+		})
+	case errors.Is(err, transcription.ErrUnauthorized):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Provider rejected the credential",
+			"details": err.Error(),
+		})
+	case errors.Is(err, secrets.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Credential storage is not configured",
+			"details": err.Error(),
+		})
+	case err.Error() == "pending transcription not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Transcription not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "credential not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Credential not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "pipeline not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Pipeline not found",
+			"details": err.Error(),
+		})
+	case err.Error() == "profile not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Profile not found",
+			"details": err.Error(),
+		})
+	default:
+		log.Printf("Error handling transcription: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to handle transcription",
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+	"github.com/mostlyerror/lattice/pkg/billing"
+)
+
+var usageReporter *services.UsageReporter
+
+// InitUsageReporter initializes the usage reporter
+func InitUsageReporter() error {
+	var err error
+	usageReporter, err = services.NewUsageReporter()
+	return err
+}
+
+// StartUsageReporting starts reporting metered usage to the billing sink in the background
+func StartUsageReporting(ctx context.Context, interval time.Duration) {
+	background.Go(func() { usageReporter.StartUsageReporting(ctx, interval) })
+}
+
+// GetUsage handles GET /api/usage
+// Returns metered usage totals per tenant, metric, and day or month
+func GetUsage(c *gin.Context) {
+	var query models.UsageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	report, err := usageReporter.UsageReport(query)
+	if errors.Is(err, services.ErrInvalidUsageRange) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error totalling usage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to total usage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReportUsage handles POST /api/usage/report
+// Sends unreported usage to the billing sink now rather than waiting for the
+// schedule
+func ReportUsage(c *gin.Context) {
+	reported, err := usageReporter.Report(c.Request.Context())
+	switch {
+	case errors.Is(err, billing.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Billing sink is not configured",
+			"details": err.Error(),
+		})
+	case errors.Is(err, billing.ErrSinkError):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":    "Billing sink rejected usage",
+			"details":  err.Error(),
+			"reported": reported,
+		})
+	case err != nil:
+		log.Printf("Error reporting usage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to report usage",
+			"details": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{"reported": reported})
+	}
+}
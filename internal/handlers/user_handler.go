@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/middleware"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/services"
+)
+
+// GetMe handles GET /api/me
+// Returns the caller and their effective permissions, for gating UI
+func GetMe(c *gin.Context) {
+	me, err := callerMe(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, me)
+}
+
+// callerMe describes the authenticated caller
+func callerMe(c *gin.Context) (models.Me, error) {
+	me := models.Me{Kind: "anonymous"}
+
+	if token, ok := c.Get(middleware.APITokenKey); ok {
+		me.Kind = "token"
+		me.Token = token.(*models.APIToken)
+	}
+	if userID, ok := c.Get(middleware.UserIDKey); ok {
+		user, err := db.GetUserByID(userID.(int))
+		if err != nil {
+			return me, err
+		}
+		me.Kind = "user"
+		me.User = user
+	}
+
+	me.Permissions = models.ExpandPermissions(c.GetStringSlice(middleware.PermissionsKey))
+	me.Timezone = callerLocation(c).String()
+
+	return me, nil
+}
+
+// UpdateMe handles PATCH /api/me
+// Changes the signed-in user's own settings, such as their timezone
+func UpdateMe(c *gin.Context) {
+	userID, ok := c.Get(middleware.UserIDKey)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": "settings belong to a signed-in user; API tokens and anonymous callers have none",
+		})
+		return
+	}
+
+	var req models.UpdateUserSettingsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Timezone != nil {
+		if err := services.ValidateTimezone(*req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid timezone",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	user, err := db.UpdateUserSettings(userID.(int), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// callerLocation returns the signed-in user's timezone, or the server default
+// for API tokens and anonymous callers
+func callerLocation(c *gin.Context) *time.Location {
+	if userID, ok := c.Get(middleware.UserIDKey); ok {
+		id := userID.(int)
+		return services.UserLocation(&id)
+	}
+	return services.UserLocation(nil)
+}
+
+// callerUserID returns the signed-in user's ID, who owns what they create, or
+// nil for API tokens and anonymous callers, whose content is shared
+func callerUserID(c *gin.Context) *int {
+	if userID, ok := c.Get(middleware.UserIDKey); ok {
+		id := userID.(int)
+		return &id
+	}
+	return nil
+}
+
+// callerScope returns the user whose content, with the shared content, lists
+// show the caller, or nil when they show everything (see middleware.OwnerScope)
+func callerScope(c *gin.Context) *int {
+	return middleware.OwnerScope(c)
+}
+
+// GetUsers handles GET /api/users
+func GetUsers(c *gin.Context) {
+	users, err := db.GetUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve users",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"count": len(users),
+	})
+}
+
+// InviteUser handles POST /api/users
+// Creates a user who joins by signing in with a verified email matching theirs
+func InviteUser(c *gin.Context) {
+	var req models.InviteUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := db.InviteUser(req)
+	if err != nil {
+		if err.Error() == "user email already exists" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "User email already exists",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to invite user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUserRole handles PATCH /api/users/:id/role
+func UpdateUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid ID",
+			"details": "ID must be a number",
+		})
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := db.UpdateUserRole(id, req.Role)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "User not found",
+				"details": err.Error(),
+			})
+		case "cannot demote the last admin":
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Cannot demote the last admin",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update user role",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one invalid field in a request body
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path, e.g. "steps[0].op"
+	Rule    string `json:"rule"`    // The failed rule, e.g. "required" or "type"
+	Message string `json:"message"` // Human-readable explanation
+}
+
+func init() {
+	// Report fields by their JSON names, or the query and form names of
+	// fields without one, rather than Go struct field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name, _, _ = strings.Cut(f.Tag.Get("form"), ",")
+			}
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	}
+}
+
+// bindJSON binds and validates the request body into req. On failure it
+// responds 400 with the field-level errors and returns false.
+func bindJSON(c *gin.Context, req any) bool {
+	return bindWith(c, req, binding.JSON)
+}
+
+// bindQuery binds and validates the query string into req, responding as
+// bindJSON does on failure
+func bindQuery(c *gin.Context, req any) bool {
+	return bindWith(c, req, binding.Query)
+}
+
+// bindForm binds and validates the form fields, URL-encoded or multipart,
+// into req, responding as bindJSON does on failure. Files are left for
+// c.FormFile.
+func bindForm(c *gin.Context, req any) bool {
+	return bindWith(c, req, binding.Form)
+}
+
+// bindWith binds and validates the request into req with b, responding 400
+// with the field-level errors and returning false on failure
+func bindWith(c *gin.Context, req any, b binding.Binding) bool {
+	err := c.ShouldBindWith(req, b)
+	if err == nil {
+		return true
+	}
+
+	details, fields := bindingDetails(err)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid request",
+		"details": details,
+		"fields":  fields,
+	})
+	return false
+}
+
+// bindingDetails explains a binding error, with its field errors
+func bindingDetails(err error) (string, []FieldError) {
+	fields := fieldErrors(err)
+	details := err.Error()
+	var syntaxErr *json.SyntaxError
+	if errors.Is(err, io.EOF) {
+		details = "request body is required"
+	} else if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		details = "request body is not valid JSON: " + err.Error()
+	} else if len(fields) > 0 {
+		messages := make([]string, len(fields))
+		for i, f := range fields {
+			messages[i] = f.Message
+		}
+		details = strings.Join(messages, "; ")
+	}
+	return details, fields
+}
+
+// fieldErrors converts binding errors into field errors. Malformed JSON has
+// no field and yields none.
+func fieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Message: validationMessage(fe),
+			}
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%s must be %s, not %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value),
+		}}
+	}
+
+	return []FieldError{}
+}
+
+// fieldPath drops the struct name from a validator namespace
+// ("CreateConceptRequest.tags[0]" becomes "tags[0]")
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// validationMessage explains a failed validator rule
+func validationMessage(fe validator.FieldError) string {
+	field := fieldPath(fe.Namespace())
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be %s %s characters", field, bound, fe.Param())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("%s must have %s %s items", field, bound, fe.Param())
+		default:
+			return fmt.Sprintf("%s must be %s %s", field, bound, fe.Param())
+		}
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "url":
+		return field + " must be a valid URL"
+	case "gtefield":
+		return fmt.Sprintf("%s must be at least %s", field, strings.ToLower(fe.Param()))
+	case "unique":
+		return field + " must not contain duplicates"
+	case "datetime":
+		return fmt.Sprintf("%s must be a date formatted as %s", field, fe.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
+
+// jsonTypeName names a Go type as its JSON equivalent
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// RecordAuthEvent logs an authentication event with the caller's address,
+// user agent, and route. Failing to store it doesn't fail the request.
+func RecordAuthEvent(c *gin.Context, e models.AuthEvent) {
+	e.Success = e.Event == models.AuthEventLogin || e.Event == models.AuthEventLogout || e.Event == models.AuthEventTokenUsed
+	if ip := c.ClientIP(); ip != "" {
+		e.IP = &ip
+	}
+	if ua := c.Request.UserAgent(); ua != "" {
+		e.UserAgent = &ua
+	}
+	route := c.Request.Method + " " + c.FullPath()
+	e.Route = &route
+
+	if err := db.RecordAuthEvent(e); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// rejectCredentials records rejected credentials and aborts with status
+func rejectCredentials(c *gin.Context, status int, tokenID *int, reason string) {
+	RecordAuthEvent(c, models.AuthEvent{Event: models.AuthEventAuthFailed, APITokenID: tokenID, Reason: &reason})
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":   http.StatusText(status),
+		"details": reason,
+	})
+}
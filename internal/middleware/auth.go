@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/auth"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// Context keys set by Auth
+const (
+	APITokenKey    = "api_token"   // The authenticated *models.APIToken
+	UserIDKey      = "user_id"     // The signed-in user's ID, for session logins
+	PermissionsKey = "permissions" // The caller's granted permissions
+	OwnerScopeKey  = "owner_scope" // The user whose content a non-admin session sees (see Ownership)
+)
+
+// APITokenPrefix starts every API token, so they're recognizable in config and
+// secret scanners; other bearer values are session JWTs
+const APITokenPrefix = "lat_"
+
+// Auth authenticates the caller with a bearer API token or a login session,
+// then checks the route's permission (see permissionRules) against the
+// token's scopes or the user's role. Authentication is required once any API
+// token or user exists; until then the API stays open, as it was for
+// single-user local setups. Sessions are accepted from the bearer header or
+// the session cookie, and on queryTokenRoutes, API tokens from ?token=.
+// Tokens are only accepted from their allowlisted addresses. Token use and
+// rejected credentials are recorded as auth events.
+func Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if publicRoutes[route] || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		enabled, err := db.AuthEnabled()
+		if err != nil {
+			log.Printf("Error checking authentication state: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to authenticate",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !enabled {
+			c.Set(PermissionsKey, []string{models.ScopeAdmin})
+			c.Next()
+			return
+		}
+
+		raw, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if raw == "" {
+			raw, _ = c.Cookie(auth.SessionCookie)
+		}
+		fromQuery := false
+		if raw == "" && queryTokenRoutes[route] {
+			raw = c.Query("token")
+			fromQuery = raw != ""
+		}
+		if fromQuery && !strings.HasPrefix(raw, APITokenPrefix) {
+			rejectCredentials(c, http.StatusUnauthorized, nil, "only API tokens are accepted in ?token=")
+			return
+		}
+		if raw == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"details": "an API token or session is required (Authorization: Bearer <token>)",
+			})
+			return
+		}
+
+		var granted []string
+		if strings.HasPrefix(raw, APITokenPrefix) {
+			token, allowed, err := db.AuthenticateAPIToken(HashAPIToken(raw), c.ClientIP())
+			if err != nil {
+				log.Printf("Error authenticating api token: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error":   "Failed to authenticate",
+					"details": err.Error(),
+				})
+				return
+			}
+			if token == nil {
+				rejectCredentials(c, http.StatusUnauthorized, nil, "API token is invalid, expired, or revoked")
+				return
+			}
+			if !allowed {
+				rejectCredentials(c, http.StatusForbidden, &token.ID, "API token is not allowed from "+c.ClientIP())
+				return
+			}
+			RecordAuthEvent(c, models.AuthEvent{Event: models.AuthEventTokenUsed, APITokenID: &token.ID})
+			c.Set(APITokenKey, token)
+			granted = token.Scopes
+		} else {
+			userID, role, err := authenticateSession(raw)
+			if err != nil {
+				rejectCredentials(c, http.StatusUnauthorized, nil, err.Error())
+				return
+			}
+			c.Set(UserIDKey, userID)
+			if role != models.RoleAdmin {
+				c.Set(OwnerScopeKey, userID)
+			}
+			granted = models.RolePermissions[role]
+		}
+		c.Set(PermissionsKey, granted)
+
+		permission := RequiredPermission(c.Request.Method, c.FullPath())
+		if fromQuery && !strictlyScoped(granted, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"details": "tokens passed in ?token= must have only the " + permission + " scope",
+			})
+			return
+		}
+		if !hasPermission(granted, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"details": "this route needs the " + permission + " permission",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateSession verifies a session JWT and loads the user's current
+// role, so role changes apply without signing in again
+func authenticateSession(raw string) (int, string, error) {
+	claims, err := auth.ParseSession(raw)
+	if err != nil {
+		return 0, "", err
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, "", auth.ErrInvalidSession
+	}
+
+	role, err := db.GetUserRole(userID)
+	if err != nil {
+		return 0, "", auth.ErrInvalidSession
+	}
+
+	return userID, role, nil
+}
+
+// HashAPIToken returns the stored form of a token
+func HashAPIToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,11 +2,13 @@ package middleware
 
 import (
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORSMiddleware sets up CORS headers
+// CORSMiddleware sets up CORS headers. The public read API under /public/
+// is open to any origin, without credentials.
 func CORSMiddleware() gin.HandlerFunc {
 	corsOrigin := os.Getenv("CORS_ORIGIN")
 	if corsOrigin == "" {
@@ -14,8 +16,12 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", corsOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		if strings.HasPrefix(c.Request.URL.Path, "/public/") {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", corsOrigin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
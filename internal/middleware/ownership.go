@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mostlyerror/lattice/internal/db"
+)
+
+// ownedRoute names the table whose row a route's Param identifies. Path
+// matches the route and any route beneath it.
+type ownedRoute struct {
+	Path  string
+	Param string
+	Table string
+	Noun  string // For the not found error, as the handlers word it
+}
+
+// ownedRoutes are the routes that act on one owned row
+var ownedRoutes = []ownedRoute{
+	{"/api/source-content/:id", "id", "source_contents", "Source content"},
+	{"/api/concepts/:id", "id", "concepts", "Concept"},
+	{"/api/quizzes/:id", "id", "quiz_questions", "Quiz question"},
+	{"/api/content/:id", "id", "generated_contents", "Generated content"},
+	{"/api/jobs/:id", "id", "jobs", "Job"},
+	{"/api/reviews/:concept_id", "concept_id", "concepts", "Concept"},
+	{"/api/action-items/:id", "id", "action_items", "Action item"},
+	{"/api/relationships/:id", "id", "concept_relationships", "Relationship"},
+	{"/api/quiz-sessions/:id", "id", "quiz_sessions", "Quiz session"},
+}
+
+// OwnerScope returns the user whose content the caller sees, with the content
+// shared with the workspace, or nil when the caller sees everything: admins,
+// API tokens, and open setups
+func OwnerScope(c *gin.Context) *int {
+	if userID, ok := c.Get(OwnerScopeKey); ok {
+		id := userID.(int)
+		return &id
+	}
+	return nil
+}
+
+// Ownership runs after Auth and answers 404 on ownedRoutes when the row
+// belongs to a user other than a non-admin session's, as if it didn't
+// exist. Shared rows, and missing ones, are left to the handler.
+func Ownership() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := OwnerScope(c)
+		if scope == nil {
+			c.Next()
+			return
+		}
+
+		for _, route := range ownedRoutes {
+			fullPath := c.FullPath()
+			if fullPath != route.Path && !strings.HasPrefix(fullPath, route.Path+"/") {
+				continue
+			}
+
+			id, err := strconv.Atoi(c.Param(route.Param))
+			if err != nil {
+				break // The handler rejects it
+			}
+
+			owner, found, err := db.GetOwner(route.Table, id)
+			if err != nil {
+				log.Printf("Error checking ownership: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error":   "Failed to check ownership",
+					"details": err.Error(),
+				})
+				return
+			}
+			if found && owner != nil && *owner != *scope {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+					"error":   route.Noun + " not found",
+					"details": strings.ToLower(route.Noun) + " not found",
+				})
+				return
+			}
+			break
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// permissionRule grants access to routes matching Method and Path. Method "*"
+// matches any method; a Path ending in "*" matches by prefix.
+type permissionRule struct {
+	Method     string
+	Path       string
+	Permission string // Empty allows any authenticated caller
+}
+
+// permissionRules maps routes to the permission they need. The first matching
+// rule applies, so specific rules come before the read/write defaults.
+var permissionRules = []permissionRule{
+	{"GET", "/api/me", ""},
+	{"PATCH", "/api/me", ""},
+	{"DELETE", "/api/me", ""},
+	{"*", "/api/me/*", ""},
+	{"*", "/api/tokens*", models.ScopeAdmin},
+	{"*", "/api/credentials*", models.ScopeAdmin},
+	{"*", "/api/users*", models.ScopeAdmin},
+	{"*", "/api/integrity*", models.ScopeAdmin},
+	{"*", "/api/usage*", models.ScopeAdmin},
+	{"*", "/api/admin*", models.ScopeAdmin},
+	{"PATCH", "/api/settings", models.ScopeAdmin},
+	{"POST", "/api/reviews/reschedule", models.ScopeAdmin},
+	{"POST", "/api/reviews/optimize", models.ScopeAdmin},
+	{"POST", "/api/source-content", models.ScopeIngest},
+	{"POST", "/api/meetings*", models.ScopeIngest},
+	{"POST", "/api/podcasts", models.ScopeIngest},
+	{"POST", "/api/imports", models.ScopeIngest},
+	{"*", "/api/transcriptions*", models.ScopeIngest}, // Listings include callback URLs, which can ingest
+	{"GET", "/api/capture", models.ScopeIngest},
+	{"POST", "/api/concepts/:id/share", models.ScopePublish},
+	{"POST", "/api/content/:id/share", models.ScopePublish},
+	{"PATCH", "/api/content/:id/status", models.ScopePublish},
+	{"POST", "/api/query", models.ScopeRead}, // Reads, with the query in the body
+	{"GET", "*", models.ScopeRead},
+	{"HEAD", "*", models.ScopeRead},
+	{"*", "*", models.ScopeWrite},
+}
+
+// publicRoutes are reachable without authenticating
+var publicRoutes = map[string]bool{
+	"GET /api/health": true,
+}
+
+// queryTokenRoutes also accept an API token in ?token=, for bookmarklets and
+// podcast apps that can't set headers. URLs end up in browser history and
+// app settings, so those tokens must be scoped to exactly the route's
+// permission (see strictlyScoped).
+var queryTokenRoutes = map[string]bool{
+	"GET /api/capture":          true,
+	"GET /api/podcast/feed.xml": true,
+}
+
+// RequiredPermission returns the permission a route needs, or "" when any
+// authenticated caller may use it
+func RequiredPermission(method, fullPath string) string {
+	for _, rule := range permissionRules {
+		if rule.Method != "*" && rule.Method != method {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(rule.Path, "*"); ok {
+			if strings.HasPrefix(fullPath, prefix) {
+				return rule.Permission
+			}
+		} else if rule.Path == fullPath {
+			return rule.Permission
+		}
+	}
+	return models.ScopeAdmin
+}
+
+// hasPermission reports whether any granted permission covers required
+func hasPermission(granted []string, required string) bool {
+	if required == "" {
+		return true
+	}
+	for _, g := range granted {
+		if g == required || g == models.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// strictlyScoped reports whether a token grants required and nothing else
+func strictlyScoped(scopes []string, required string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, s := range scopes {
+		if s != required {
+			return false
+		}
+	}
+	return true
+}
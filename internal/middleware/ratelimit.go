@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow is the fixed window RateLimit counts requests in
+const rateLimitWindow = time.Minute
+
+// rateWindow counts one client's requests in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows each client IP perMinute requests per minute, answering
+// 429 with Retry-After beyond that. Counts are kept in memory, so each server
+// instance limits separately. perMinute <= 0 disables the limit.
+func RateLimit(perMinute int) gin.HandlerFunc {
+	if perMinute <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	var (
+		mu        sync.Mutex
+		windows   = map[string]*rateWindow{}
+		lastSweep = time.Now()
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Drop expired windows now and then so idle clients don't accumulate
+		if now.Sub(lastSweep) > rateLimitWindow {
+			for k, w := range windows {
+				if now.Sub(w.start) >= rateLimitWindow {
+					delete(windows, k)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := windows[ip]
+		if !ok || now.Sub(w.start) >= rateLimitWindow {
+			w = &rateWindow{start: now}
+			windows[ip] = w
+		}
+		w.count++
+		count, retryAfter := w.count, w.start.Add(rateLimitWindow).Sub(now)
+		mu.Unlock()
+
+		if count > perMinute {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Rate limit exceeded",
+				"details": "limited to " + strconv.Itoa(perMinute) + " requests per minute; retry after the Retry-After delay",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
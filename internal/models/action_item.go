@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Action item statuses
+const (
+	ActionItemOpen      = "open"
+	ActionItemDone      = "done"
+	ActionItemDismissed = "dismissed"
+)
+
+// ActionItem is a piece of explicit "do this" advice from a source
+type ActionItem struct {
+	ID              int        `json:"id" db:"id"`
+	SourceContentID int        `json:"source_content_id" db:"source_content_id"`
+	ConceptID       *int       `json:"concept_id,omitempty" db:"concept_id"`
+	Text            string     `json:"text" db:"text"`
+	Status          string     `json:"status" db:"status"` // open, done, dismissed
+	Position        int        `json:"position" db:"position"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" db:"resolved_at"` // When marked done or dismissed
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// UpdateActionItemRequest represents the request body for checking off an action item
+type UpdateActionItemRequest struct {
+	Status string `json:"status" binding:"required,oneof=open done dismissed"`
+}
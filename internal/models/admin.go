@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Feature flags, all on unless DISABLED_FEATURES or an administrator turns
+// them off
+const (
+	FeatureIngest        = "ingest"         // New sources are accepted: videos, podcasts, meetings, transcriptions, captures
+	FeatureJobProcessing = "job_processing" // Workers claim queued jobs; off leaves them queued
+	FeatureShareLinks    = "share_links"    // Public share pages are served
+)
+
+// Features are the feature flags that can be toggled
+var Features = []string{FeatureIngest, FeatureJobProcessing, FeatureShareLinks}
+
+// UpdateFeaturesRequest represents the request body for toggling feature
+// flags. Flags named in Reset go back to the environment's values.
+type UpdateFeaturesRequest struct {
+	Features map[string]bool `json:"features" binding:"dive,keys,oneof=ingest job_processing share_links,endkeys"`
+	Reset    []string        `json:"reset" binding:"dive,oneof=ingest job_processing share_links"`
+}
+
+// FeaturesResponse is the feature flags in effect and which are overridden
+type FeaturesResponse struct {
+	Features  map[string]bool `json:"features"`
+	Overrides map[string]bool `json:"overrides"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// Tenant is an organization Lattice meters usage for. Each deployment serves
+// one; the others are tenants it metered under an earlier BILLING_TENANT.
+type Tenant struct {
+	Name         string     `json:"name"`
+	Current      bool       `json:"current"`           // This deployment's tenant
+	Users        *int       `json:"users,omitempty"`   // Current tenant only
+	Sources      *int       `json:"sources,omitempty"` // Current tenant only
+	FirstUsageAt *time.Time `json:"first_usage_at,omitempty"`
+	LastUsageAt  *time.Time `json:"last_usage_at,omitempty"`
+}
+
+// JobQuery selects the jobs GET /api/admin/jobs lists
+type JobQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=queued running completed failed"`
+}
+
+// LLMSpendQuery selects the Claude usage GET /api/admin/spend totals
+type LLMSpendQuery struct {
+	From     *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`   // From this date (UTC); 30 days before To when omitted
+	To       *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`     // Through this date (UTC); today when omitted
+	Interval string     `form:"interval" binding:"omitempty,oneof=day month"` // Period totals are grouped by; day when omitted
+}
+
+// LLMSpend is the Claude usage of one model in one period
+type LLMSpend struct {
+	Period       string   `json:"period" db:"period"` // YYYY-MM-DD, or YYYY-MM by month
+	Model        string   `json:"model" db:"model"`
+	Requests     int64    `json:"requests" db:"requests"`
+	InputTokens  int64    `json:"input_tokens" db:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens" db:"output_tokens"`
+	CostUSD      *float64 `json:"cost_usd,omitempty" db:"-"` // At list price; unset for models without a known price
+}
+
+// LLMSpendReport totals Claude usage and its estimated cost per model and period
+type LLMSpendReport struct {
+	From           string     `json:"from"`
+	To             string     `json:"to"`
+	Interval       string     `json:"interval"`
+	Spend          []LLMSpend `json:"spend"`
+	TotalCostUSD   float64    `json:"total_cost_usd"`
+	UnpricedModels []string   `json:"unpriced_models,omitempty"` // Left out of the total
+}
+
+// ConfigReload is what reloading the configuration changed
+type ConfigReload struct {
+	Changed         []string  `json:"changed"`          // .env variables added, changed, or removed
+	RestartRequired []string  `json:"restart_required"` // Changed variables that only take effect on a restart
+	Prompts         []string  `json:"prompts"`          // System prompts PROMPTS_DIR now replaces
+	ReloadedAt      time.Time `json:"reloaded_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// AnkiNote is a lattice card in AnkiConnect addNotes format. Ref identifies the
+// lattice item ("question:12" or "flashcard:3") and is echoed back when linking.
+type AnkiNote struct {
+	Ref       string            `json:"ref"`
+	DeckName  string            `json:"deckName"`
+	ModelName string            `json:"modelName"`
+	Fields    map[string]string `json:"fields"`
+	Tags      []string          `json:"tags"`
+}
+
+// AnkiNoteLink records the Anki note created for a lattice item. QuestionID
+// and FlashcardID are resolved from Ref; exactly one is set.
+type AnkiNoteLink struct {
+	Ref         string `json:"ref" binding:"required"`
+	AnkiNoteID  int64  `json:"anki_note_id" binding:"required"`
+	QuestionID  *int   `json:"-"`
+	FlashcardID *int   `json:"-"`
+}
+
+// LinkAnkiNotesRequest represents the request body for linking created Anki notes
+type LinkAnkiNotesRequest struct {
+	Links []AnkiNoteLink `json:"links" binding:"required,dive"`
+}
+
+// AnkiReview is one review from Anki's revlog, as read through AnkiConnect
+// cardReviews and mapped to its note
+type AnkiReview struct {
+	ReviewID   int64 `json:"review_id" binding:"required"` // Revlog id, milliseconds since epoch
+	NoteID     int64 `json:"note_id" binding:"required"`
+	Ease       int   `json:"ease" binding:"required,min=1,max=4"` // 1 again, 2 hard, 3 good, 4 easy
+	DurationMs int   `json:"duration_ms,omitempty"`
+}
+
+// SyncAnkiReviewsRequest represents the request body for importing Anki reviews
+type SyncAnkiReviewsRequest struct {
+	Reviews []AnkiReview `json:"reviews" binding:"required,dive"`
+}
+
+// AnkiReviewSyncResult reports what happened to imported reviews
+type AnkiReviewSyncResult struct {
+	Applied   int `json:"applied"`
+	Duplicate int `json:"duplicate"` // Already imported
+	Unlinked  int `json:"unlinked"`  // Note isn't linked to a lattice item
+}
+
+// AnkiSyncState tells a bridge where to resume
+type AnkiSyncState struct {
+	LastReviewID int64 `json:"last_review_id"` // Pass as startID to AnkiConnect cardReviews
+	PendingNotes int   `json:"pending_notes"`
+	LinkedNotes  int   `json:"linked_notes"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Permissions, granted to API tokens as scopes and to users through their
+// role. Admin grants every permission.
+const (
+	ScopeRead    = "read"    // GET requests
+	ScopeIngest  = "ingest"  // Submitting new sources
+	ScopeWrite   = "write"   // Other changes: editing concepts, answering quizzes, etc.
+	ScopePublish = "publish" // Publishing content outside Lattice
+	ScopeAdmin   = "admin"   // Managing tokens, credentials, and users
+)
+
+// Permissions lists every permission
+var Permissions = []string{ScopeRead, ScopeIngest, ScopeWrite, ScopePublish, ScopeAdmin}
+
+// ExpandPermissions returns the permissions a grant amounts to, in the order
+// of Permissions. Admin expands to every permission.
+func ExpandPermissions(granted []string) []string {
+	effective := []string{}
+	for _, p := range Permissions {
+		for _, g := range granted {
+			if g == p || g == ScopeAdmin {
+				effective = append(effective, p)
+				break
+			}
+		}
+	}
+	return effective
+}
+
+// APIToken is a bearer token for the API. The token itself is only returned
+// when it's created.
+type APIToken struct {
+	ID         int         `json:"id" db:"id"`
+	Name       string      `json:"name" db:"name"`
+	Prefix     string      `json:"prefix" db:"prefix"`
+	Scopes     StringArray `json:"scopes" db:"scopes"`
+	AllowedIPs StringArray `json:"allowed_ips" db:"allowed_ips"` // CIDR ranges the token is accepted from; any address when empty
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CreateAPITokenRequest represents the request body for creating an API token
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=255"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,unique,dive,oneof=read ingest write publish admin"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1"`    // Never expires when omitted
+	AllowedIPs    []string `json:"allowed_ips" binding:"omitempty,dive,cidr|ip"` // Addresses or CIDR ranges; any address when omitted
+}
+
+// UpdateAPITokenRequest represents the request body for changing a token's
+// IP allowlist. An empty list accepts any address.
+type UpdateAPITokenRequest struct {
+	AllowedIPs []string `json:"allowed_ips" binding:"required,dive,cidr|ip"`
+}
+
+// CreatedAPIToken is a new token, returned once with its secret value
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AudioSummary is a source's summary and concepts rendered to speech, for
+// listening in a podcast app. Regenerating replaces it.
+type AudioSummary struct {
+	SourceContentID int       `json:"source_content_id" db:"source_content_id"`
+	Title           string    `json:"title" db:"title"` // The source's title
+	SourceURL       string    `json:"source_url" db:"url"`
+	StorageKey      string    `json:"-" db:"storage_key"`
+	SizeBytes       int64     `json:"size_bytes" db:"size_bytes"`
+	Provider        string    `json:"provider" db:"provider"`
+	Voice           string    `json:"voice" db:"voice"`
+	URL             string    `json:"url" db:"-"` // Signed download URL, filled in when read
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Authentication events
+const (
+	AuthEventLogin       = "login"        // A user signed in with a login provider
+	AuthEventLoginFailed = "login_failed" // A provider login was refused or couldn't be completed
+	AuthEventLogout      = "logout"       // A session cookie was cleared
+	AuthEventTokenUsed   = "token_used"   // An API token authenticated a request
+	AuthEventAuthFailed  = "auth_failed"  // A request's token or session was rejected
+)
+
+// AuthEvent records a sign-in, the use of an API token, or rejected
+// credentials, for auditing
+type AuthEvent struct {
+	ID         int       `json:"id" db:"id"`
+	Event      string    `json:"event" db:"event"`
+	Success    bool      `json:"success" db:"success"`
+	UserID     *int      `json:"user_id,omitempty" db:"user_id"`
+	APITokenID *int      `json:"api_token_id,omitempty" db:"api_token_id"`
+	Provider   *string   `json:"provider,omitempty" db:"provider"`
+	IP         *string   `json:"ip,omitempty" db:"ip"`
+	UserAgent  *string   `json:"user_agent,omitempty" db:"user_agent"`
+	Route      *string   `json:"route,omitempty" db:"route"`   // Method and route pattern the credentials were presented to
+	Reason     *string   `json:"reason,omitempty" db:"reason"` // Why a login or credential was rejected
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// AuthEventQuery filters the events GET /api/admin/auth-events returns
+type AuthEventQuery struct {
+	Event      string `form:"event" binding:"omitempty,oneof=login login_failed logout token_used auth_failed"`
+	Success    *bool  `form:"success"`
+	UserID     *int   `form:"user_id"`
+	APITokenID *int   `form:"api_token_id"`
+	IP         string `form:"ip" binding:"omitempty,cidr|ip"` // An address, or every address in a CIDR range
+}
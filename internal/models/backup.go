@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// BackupFormat is the version of the backup archive layout
+const BackupFormat = 1
+
+// BackupManifest describes a backup archive: the migrations its schema was
+// at, the rows dumped from each table, and the object store's contents
+type BackupManifest struct {
+	Format     int            `json:"format"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Migrations []string       `json:"migrations"`
+	Tables     []BackupTable  `json:"tables"` // In restore order, referenced tables first
+	Objects    []BackupObject `json:"objects"`
+}
+
+// BackupTable is a table dumped into a backup
+type BackupTable struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// BackupObject is a stored object listed in a backup. Objects left out of the
+// archive must be copied to the restored instance's store separately.
+type BackupObject struct {
+	Key      string `json:"key"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256,omitempty"` // Set when Included
+	Included bool   `json:"included"`
+}
+
+// BlobReference is a row column naming a stored object
+type BlobReference struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Key    string `json:"key"`
+}
+
+// RestoreResult reports what a restore loaded: rows by table, objects copied
+// into the store, and references to objects the store doesn't have, which
+// were cleared (or their rows deleted, where the object is required)
+type RestoreResult struct {
+	Rows            map[string]int  `json:"rows"`
+	ObjectsRestored int             `json:"objects_restored"`
+	ObjectsPresent  int             `json:"objects_present"` // Left out of the archive, but already in the store
+	ObjectsMissing  []string        `json:"objects_missing"`
+	Unlinked        []BlobReference `json:"unlinked"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Bootstrap is everything a dashboard needs on load, in one response
+type Bootstrap struct {
+	Me            Me                     `json:"me"`
+	Counts        WorkspaceCounts        `json:"counts"`
+	Reviews       ReviewSummary          `json:"reviews"`
+	RecentSources []SourceContentSummary `json:"recent_sources"` // Newest active sources
+	RecentDrafts  []GeneratedContent     `json:"recent_drafts"`  // Most recently edited drafts
+}
+
+// WorkspaceCounts counts what the workspace holds. Archived sources and
+// concepts, and suspended questions, aren't counted.
+type WorkspaceCounts struct {
+	Sources             int `json:"sources"`
+	Concepts            int `json:"concepts"`
+	QuizQuestions       int `json:"quiz_questions"`
+	Drafts              int `json:"drafts"`
+	UnreadNotifications int `json:"unread_notifications"`
+}
+
+// ReviewSummary is the daily review queue's size, without its reviews
+type ReviewSummary struct {
+	Due           int `json:"due"`            // Concepts due now, including those past the limit
+	ReviewedToday int `json:"reviewed_today"` // Concepts reviewed since local midnight
+	DailyLimit    int `json:"daily_limit"`
+	Remaining     int `json:"remaining"` // Reviews GET /api/reviews/due would serve now
+}
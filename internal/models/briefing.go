@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Briefing is a day's short digest: the concepts due for review, the concepts
+// extracted since the previous briefing, and one older concept resurfaced.
+// Regenerating a day's briefing replaces it.
+type Briefing struct {
+	ID                  int       `json:"id" db:"id"`
+	Date                string    `json:"date" db:"date"` // The local day it's for, YYYY-MM-DD
+	Title               string    `json:"title" db:"title"`
+	Body                string    `json:"body" db:"body"` // Plain text, as delivered
+	DueCount            int       `json:"due_count" db:"due_count"`
+	DueConceptIDs       IntArray  `json:"due_concept_ids" db:"due_concept_ids"` // The first few due, as listed in the body
+	NewConceptIDs       IntArray  `json:"new_concept_ids" db:"new_concept_ids"`
+	ResurfacedConceptID *int      `json:"resurfaced_concept_id,omitempty" db:"resurfaced_concept_id"`
+	AudioKey            *string   `json:"-" db:"audio_key"`
+	AudioURL            string    `json:"audio_url,omitempty" db:"-"` // Signed, when the briefing was read aloud
+	OwnerID             *int      `json:"-" db:"owner_id"`            // The user it was written for; nil for the workspace's
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// BulkDeleteSourceContentsRequest selects the sources DELETE /api/source-content removes
+type BulkDeleteSourceContentsRequest struct {
+	Before   time.Time `form:"before" binding:"required" time_format:"2006-01-02" time_utc:"1"` // Created before this date (UTC)
+	Type     string    `form:"type" binding:"omitempty,oneof=youtube pdf article meeting podcast"`
+	Archived bool      `form:"archived"` // Only archived sources
+	DryRun   *bool     `form:"dry_run"`  // Defaults to true; pass false to delete
+	OwnerID  *int      `form:"-"`        // Limits the delete to this user's and shared sources, when set
+}
+
+// BulkDeleteConceptsRequest selects the concepts DELETE /api/concepts removes.
+// At least one filter is required.
+type BulkDeleteConceptsRequest struct {
+	SourceContentID *int       `form:"source_content_id"`
+	Tag             string     `form:"tag"`
+	Before          *time.Time `form:"before" time_format:"2006-01-02" time_utc:"1"` // Created before this date (UTC)
+	Archived        bool       `form:"archived"`                                     // Only archived concepts
+	Detached        bool       `form:"detached"`                                     // Only concepts without a source
+	DryRun          *bool      `form:"dry_run"`                                      // Defaults to true; pass false to delete
+	OwnerID         *int       `form:"-"`                                            // Limits the delete to this user's and shared concepts, when set
+}
+
+// HasFilter reports whether any filter is set
+func (r BulkDeleteConceptsRequest) HasFilter() bool {
+	return r.SourceContentID != nil || r.Tag != "" || r.Before != nil || r.Archived || r.Detached
+}
+
+// BulkDeleteResult reports what a bulk delete removed, or would remove on a dry run
+type BulkDeleteResult struct {
+	DryRun bool           `json:"dry_run"`
+	IDs    []int          `json:"ids"`    // Matched sources or concepts
+	Counts map[string]int `json:"counts"` // Rows per table, including those removed by cascade
+}
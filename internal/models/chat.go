@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Chat message roles
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// SourceChat is a conversation scoped to one source
+type SourceChat struct {
+	ID              int       `json:"id" db:"id"`
+	SourceContentID int       `json:"source_content_id" db:"source_content_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChatMessage is one turn in a source chat
+type ChatMessage struct {
+	ID        int       `json:"id" db:"id"`
+	ChatID    int       `json:"chat_id" db:"chat_id"`
+	Role      string    `json:"role" db:"role"` // user or assistant
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SourceChatDetail is a source chat with its messages, oldest first
+type SourceChatDetail struct {
+	Chat     SourceChat    `json:"chat"`
+	Messages []ChatMessage `json:"messages"`
+}
+
+// SourceChatRequest represents the request body for asking about a source.
+// Omit ChatID to start a new conversation.
+type SourceChatRequest struct {
+	ChatID  *int   `json:"chat_id"`
+	Message string `json:"message" binding:"required"`
+}
+
+// SourceChatResponse is the reply to a source chat message
+type SourceChatResponse struct {
+	ChatID  int         `json:"chat_id"`
+	Message ChatMessage `json:"message"` // The user's message as stored
+	Reply   ChatMessage `json:"reply"`
+}
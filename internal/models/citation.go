@@ -0,0 +1,43 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Citation ties a sentence of generated content to a concept it draws on, and
+// to where the concept's source discusses it
+type Citation struct {
+	Sentence        string   `json:"sentence"` // Quoted from the body as generated
+	ConceptID       int      `json:"concept_id"`
+	SourceContentID *int     `json:"source_content_id,omitempty"`
+	Segment         *int     `json:"segment,omitempty"`       // Index of the transcript segment that best supports the concept
+	StartSeconds    *float64 `json:"start_seconds,omitempty"` // Where that segment starts, for timed transcripts
+}
+
+// Citations is a custom type for handling PostgreSQL JSONB citation arrays
+type Citations []Citation
+
+// Scan implements the sql.Scanner interface
+func (c *Citations) Scan(value interface{}) error {
+	if value == nil {
+		*c = Citations{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Citations")
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements the driver.Valuer interface
+func (c Citations) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
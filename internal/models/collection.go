@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Collection is a smart collection: a named concept query, in the language of
+// POST /api/query, whose concepts are whichever match it now
+type Collection struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Query       string    `json:"query" db:"query"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CollectionRequest represents the request body for creating or replacing a collection
+type CollectionRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	Query       string `json:"query" binding:"required,max=1000"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// SharedConcept is an idea covered by more than one compared source
+type SharedConcept struct {
+	Topic            string `json:"topic"`
+	SourceContentIDs []int  `json:"source_content_ids"`
+	Notes            string `json:"notes"` // How the sources' treatments differ in emphasis
+}
+
+// ComparisonPosition is one source's stance in a disagreement
+type ComparisonPosition struct {
+	SourceContentID int    `json:"source_content_id"`
+	Position        string `json:"position"`
+}
+
+// Disagreement is a topic on which compared sources take different positions
+type Disagreement struct {
+	Topic     string               `json:"topic"`
+	Positions []ComparisonPosition `json:"positions"`
+}
+
+// UniquePoint is an idea only one compared source makes
+type UniquePoint struct {
+	SourceContentID int    `json:"source_content_id"`
+	Point           string `json:"point"`
+}
+
+// ComparisonDetails is the structured body of a source comparison
+type ComparisonDetails struct {
+	SharedConcepts []SharedConcept `json:"shared_concepts"`
+	Disagreements  []Disagreement  `json:"disagreements"`
+	UniquePoints   []UniquePoint   `json:"unique_points"`
+}
+
+// Scan implements the sql.Scanner interface
+func (d *ComparisonDetails) Scan(value interface{}) error {
+	if value == nil {
+		*d = ComparisonDetails{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ComparisonDetails")
+	}
+
+	return json.Unmarshal(bytes, d)
+}
+
+// Value implements the driver.Valuer interface
+func (d ComparisonDetails) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// SourceComparison is a stored comparison of two or more sources
+type SourceComparison struct {
+	ID               int               `json:"id" db:"id"`
+	SourceContentIDs IntArray          `json:"source_content_ids" db:"source_content_ids"`
+	Summary          string            `json:"summary" db:"summary"`
+	Details          ComparisonDetails `json:"details" db:"details"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+}
+
+// CompareSourcesRequest represents the request body for comparing sources
+type CompareSourcesRequest struct {
+	SourceContentIDs []int `json:"source_content_ids" binding:"required,min=2,max=5,unique"`
+	OwnerID          *int  `json:"-"` // Limits the comparison to this user's and shared sources, when set
+}
@@ -4,23 +4,170 @@ import "time"
 
 // Concept represents a single learnable unit extracted from content
 type Concept struct {
-	ID              int       `json:"id" db:"id"`
-	Title           string    `json:"title" db:"title"`
-	Description     string    `json:"description" db:"description"`
-	SourceContentID *int      `json:"source_content_id,omitempty" db:"source_content_id"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	ID              int         `json:"id" db:"id"`
+	Title           string      `json:"title" db:"title"`
+	Description     string      `json:"description" db:"description"`
+	SourceContentID *int        `json:"source_content_id,omitempty" db:"source_content_id"`
+	Tags            StringArray `json:"tags" db:"tags"`
+	Position        *int        `json:"position,omitempty" db:"position"` // Teaching order within the source
+	Pinned          bool        `json:"pinned" db:"pinned"`
+	ArchivedAt      *time.Time  `json:"archived_at,omitempty" db:"archived_at"`
+	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at" db:"updated_at"`
+
+	Resonance *ConceptResonance `json:"resonance,omitempty" db:"-"` // Set in listings once posts on the concept have engagement
+}
+
+// ConceptResonance is how well a concept's published posts engaged the audience
+type ConceptResonance struct {
+	Score       float64 `json:"score"` // Mean engagement rate of its posts relative to all posts', 1 being average
+	Posts       int     `json:"posts"`
+	Impressions int     `json:"impressions"`
 }
 
 // CreateConceptRequest represents the request body for creating a concept
 type CreateConceptRequest struct {
-	Title           string `json:"title" binding:"required"`
-	Description     string `json:"description" binding:"required"`
-	SourceContentID *int   `json:"source_content_id,omitempty"`
+	Title           string   `json:"title" binding:"required"`
+	Description     string   `json:"description" binding:"required"`
+	SourceContentID *int     `json:"source_content_id,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	OwnerID         *int     `json:"-"` // The signed-in user; the source's owner when nil
 }
 
 // UpdateConceptRequest represents the request body for updating a concept
 type UpdateConceptRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Pinned      *bool     `json:"pinned,omitempty"`
+}
+
+// ReorderConceptsRequest represents the request body for setting a source's teaching order
+type ReorderConceptsRequest struct {
+	ConceptIDs []int `json:"concept_ids" binding:"required,min=1"` // In teaching order
+}
+
+// RelatedConcept is a concept connected to another through concept_relationships
+type RelatedConcept struct {
+	Concept
+	RelationshipType string `json:"relationship_type"`
+	Direction        string `json:"direction"` // outgoing (this concept -> related) or incoming
+}
+
+// ConceptDetail bundles a concept with everything needed to render its page
+type ConceptDetail struct {
+	Concept          Concept               `json:"concept"`
+	Quizzes          []QuizQuestion        `json:"quizzes"`
+	Attempts         AttemptSummary        `json:"attempts"`
+	Progress         *LearningProgress     `json:"progress"`
+	RelatedConcepts  []RelatedConcept      `json:"related_concepts"`
+	Source           *SourceContentSummary `json:"source"`
+	GeneratedContent []GeneratedContent    `json:"generated_content"`
+}
+
+// ConceptMatch is a semantic search result
+type ConceptMatch struct {
+	Concept
+	Similarity float64 `json:"similarity"` // Cosine similarity of the embeddings, 1 being identical
+}
+
+// ConceptSearchQuery holds the query of a semantic concept search
+type ConceptSearchQuery struct {
+	Q     string `form:"q" binding:"required,max=1000"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"` // Defaults to 10
+}
+
+// ConceptListQuery filters and sorts GET /api/concepts
+type ConceptListQuery struct {
+	SourceContentID *int       `form:"source_content_id"`
+	Tag             string     `form:"tag"`
+	From            *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"` // Created from this date (UTC)
+	To              *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`   // Created through this date (UTC)
+	IncludeArchived bool       `form:"include_archived"`
+	Sort            string     `form:"sort" binding:"omitempty,oneof=created_at updated_at resonance"` // created_at when omitted
+	Order           string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Offset          int        `form:"offset" binding:"omitempty,min=0"` // Concepts to skip; sort=resonance pages by offset
+	OwnerID         *int       `form:"-"`                                // Limits the list to this user's and shared concepts, when set
+}
+
+// ConceptText is the text of a concept to embed
+type ConceptText struct {
+	ConceptID int
+	Text      string
+	Hash      string // md5 of Text, stored with the embedding
+}
+
+// AutocompleteSuggestion is a lightweight search-as-you-type result
+type AutocompleteSuggestion struct {
+	Type string `json:"type"`         // concept, tag, source
+	ID   *int   `json:"id,omitempty"` // Not set for tags
+	Text string `json:"text"`
+}
+
+// ConceptSplitChild is one proposed (or confirmed) child of a split concept
+type ConceptSplitChild struct {
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Tags        []string `json:"tags,omitempty"`
+	QuestionIDs []int    `json:"question_ids,omitempty"` // Existing quiz questions to move to this child
+}
+
+// ConceptSplitProposal is Claude's suggestion for splitting a concept
+type ConceptSplitProposal struct {
+	Concept  Concept             `json:"concept"`
+	Quizzes  []QuizQuestion      `json:"quizzes"`
+	Children []ConceptSplitChild `json:"children"`
+}
+
+// ConfirmConceptSplitRequest represents the request body for applying a split
+type ConfirmConceptSplitRequest struct {
+	Children          []ConceptSplitChild `json:"children" binding:"required,min=2,dive"`
+	RegenerateQuizzes bool                `json:"regenerate_quizzes"` // Generate quizzes for children that received no existing questions
+}
+
+// ConceptSplitResult is the outcome of applying a split
+type ConceptSplitResult struct {
+	Original Concept        `json:"original"` // Archived after the split
+	Children []Concept      `json:"children"`
+	Quizzes  []QuizQuestion `json:"quizzes"` // Quizzes now attached to the children
+}
+
+// MergeConceptsRequest represents the request body for merging concepts into another
+type MergeConceptsRequest struct {
+	ConceptIDs []int `json:"concept_ids" binding:"required,min=1,max=50,unique,dive,min=1"` // Concepts to merge in and archive
+}
+
+// ConceptMergeResult is the outcome of a merge
+type ConceptMergeResult struct {
+	Concept Concept        `json:"concept"` // The merge target, with the union of tags
+	Merged  []Concept      `json:"merged"`  // Archived after the merge
+	Quizzes []QuizQuestion `json:"quizzes"` // Quizzes now attached to the target
+}
+
+// ConceptPair is two concepts with similar titles
+type ConceptPair struct {
+	ConceptID  int     `json:"concept_id"`
+	OtherID    int     `json:"other_id"`
+	Similarity float64 `json:"similarity"` // Trigram similarity of the titles, 0-1
+}
+
+// DuplicateConcept is a member of a duplicate cluster
+type DuplicateConcept struct {
+	Concept
+	SourceTitle *string `json:"source_title,omitempty"`
+	QuizCount   int     `json:"quiz_count"`
+}
+
+// ConceptMergePayload is the request that merges a duplicate cluster into its first concept
+type ConceptMergePayload struct {
+	Method string               `json:"method"`
+	Path   string               `json:"path"`
+	Body   MergeConceptsRequest `json:"body"`
+}
+
+// DuplicateCluster is a group of concepts likely to duplicate one another
+type DuplicateCluster struct {
+	Concepts   []DuplicateConcept  `json:"concepts"`   // The suggested merge target first
+	Similarity float64             `json:"similarity"` // Highest similarity between two members
+	Merge      ConceptMergePayload `json:"merge"`
 }
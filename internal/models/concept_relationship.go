@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Concept relationship types
+const (
+	RelationshipPrerequisite = "prerequisite" // The from concept should be learned before the to concept
+	RelationshipRelated      = "related"
+	RelationshipContradicts  = "contradicts"
+)
+
+// RelationshipTypes lists every type of edge in the concept graph
+var RelationshipTypes = []string{RelationshipPrerequisite, RelationshipRelated, RelationshipContradicts}
+
+// ConceptRelationship is an edge of the concept graph. Related and
+// contradicts are symmetric; a prerequisite points from the concept to learn
+// first.
+type ConceptRelationship struct {
+	ID               int       `json:"id" db:"id"`
+	FromConceptID    int       `json:"from_concept_id" db:"from_concept_id"`
+	FromTitle        string    `json:"from_title" db:"-"`
+	ToConceptID      int       `json:"to_concept_id" db:"to_concept_id"`
+	ToTitle          string    `json:"to_title" db:"-"`
+	RelationshipType string    `json:"relationship_type" db:"relationship_type"`
+	Rationale        *string   `json:"rationale,omitempty" db:"rationale"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateConceptRelationshipRequest represents the request body for linking a
+// concept to another
+type CreateConceptRelationshipRequest struct {
+	ToConceptID      int    `json:"to_concept_id" binding:"required"`
+	RelationshipType string `json:"relationship_type" binding:"required,oneof=prerequisite related contradicts"`
+	Rationale        string `json:"rationale,omitempty"`
+}
+
+// ConceptRelationshipQuery filters a listing of concept relationships
+type ConceptRelationshipQuery struct {
+	ConceptID        int    `form:"concept_id"`        // Edges touching the concept, at either end
+	SourceContentID  int    `form:"source_content_id"` // Edges with both ends in the source
+	RelationshipType string `form:"type" binding:"omitempty,oneof=prerequisite related contradicts"`
+}
+
+// RelationshipSuggestion is a relationship Claude proposes between two of a
+// source's concepts. Nothing is saved; accepted suggestions are created
+// like any other relationship.
+type RelationshipSuggestion struct {
+	FromConceptID    int    `json:"from_concept_id"`
+	FromTitle        string `json:"from_title"`
+	ToConceptID      int    `json:"to_concept_id"`
+	ToTitle          string `json:"to_title"`
+	RelationshipType string `json:"relationship_type"`
+	Rationale        string `json:"rationale"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Content script step operations
+const (
+	ScriptOpReplace      = "replace"
+	ScriptOpRegexReplace = "regex_replace"
+	ScriptOpPrepend      = "prepend"
+	ScriptOpAppend       = "append"
+	ScriptOpTruncate     = "truncate"
+)
+
+// ContentScriptStep is one transform applied to a generated content field
+type ContentScriptStep struct {
+	Op        string `json:"op" binding:"required,oneof=replace regex_replace prepend append truncate"`
+	Field     string `json:"field,omitempty" binding:"omitempty,oneof=title body"` // Defaults to body
+	Find      string `json:"find,omitempty"`                                       // replace: literal text; regex_replace: RE2 pattern
+	Replace   string `json:"replace,omitempty"`                                    // regex_replace may use $1-style groups
+	Text      string `json:"text,omitempty"`                                       // prepend, append
+	MaxLength int    `json:"max_length,omitempty"`                                 // truncate, in characters
+}
+
+// ContentScriptSteps is a custom type for handling the JSONB steps column
+type ContentScriptSteps []ContentScriptStep
+
+// Scan implements the sql.Scanner interface
+func (s *ContentScriptSteps) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ContentScriptSteps")
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
+// Value implements the driver.Valuer interface
+func (s ContentScriptSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// ContentScript is a named list of steps, or a WebAssembly module, run on
+// generated content before it is saved
+type ContentScript struct {
+	ID        int                `json:"id" db:"id"`
+	Name      string             `json:"name" db:"name"`
+	Platform  *string            `json:"platform" db:"platform"`     // nil applies to every platform
+	Steps     ContentScriptSteps `json:"steps" db:"steps"`           // Empty for a module
+	Wasm      []byte             `json:"-" db:"wasm"`                // A WASI module, run instead of steps
+	WasmSize  int                `json:"wasm_size,omitempty" db:"-"` // Bytes in the module
+	Enabled   bool               `json:"enabled" db:"enabled"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// ContentScriptRequest represents the request body for creating or replacing a content script
+type ContentScriptRequest struct {
+	Name     string              `json:"name" binding:"required,max=255"`
+	Platform *string             `json:"platform"`
+	Steps    []ContentScriptStep `json:"steps" binding:"omitempty,dive"`
+	Wasm     []byte              `json:"wasm"`    // A base64-encoded WASI module, instead of steps
+	Enabled  *bool               `json:"enabled"` // Defaults to true
+}
+
+// PreviewContentScriptRequest represents sample content to run a script on
+type PreviewContentScriptRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// ContentSeries is a multi-week series of posts planned from the concepts
+// with a tag or in a collection, each stored as a draft scheduled on the
+// content calendar
+type ContentSeries struct {
+	ID           int                `json:"id" db:"id"`
+	Tag          *string            `json:"tag,omitempty" db:"tag"`
+	CollectionID *int               `json:"collection_id,omitempty" db:"collection_id"`
+	Title        string             `json:"title" db:"title"`
+	Summary      string             `json:"summary" db:"summary"`
+	Weeks        int                `json:"weeks" db:"weeks"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+	Posts        []GeneratedContent `json:"posts" db:"-"`              // In series order
+	Warnings     []StageWarning     `json:"warnings,omitempty" db:"-"` // Posts that failed to generate
+}
+
+// SeriesPostPlan is one planned post of a content series
+type SeriesPostPlan struct {
+	ConceptIDs []int  `json:"concept_ids"`
+	Platform   string `json:"platform"`
+	Angle      string `json:"angle"` // What the post covers, and how it builds on the ones before it
+}
+
+// ContentSeriesPlan is Claude's plan for a content series, its posts in the
+// order to publish them
+type ContentSeriesPlan struct {
+	Title   string           `json:"title"`
+	Summary string           `json:"summary"`
+	Posts   []SeriesPostPlan `json:"posts"`
+}
+
+// PlanContentSeriesRequest represents the request body for planning a content
+// series. Exactly one of Tag or CollectionID must be set.
+type PlanContentSeriesRequest struct {
+	Tag          string   `json:"tag" binding:"max=100"`
+	CollectionID *int     `json:"collection_id,omitempty"`
+	Posts        int      `json:"posts" binding:"required,min=1,max=20"`
+	Weeks        int      `json:"weeks" binding:"required,min=1,max=12"`
+	Platforms    []string `json:"platforms" binding:"dive,oneof=linkedin twitter blog"` // The default_platforms setting when empty
+	StartsOn     string   `json:"starts_on" binding:"omitempty,datetime=2006-01-02"`    // The first post's date; tomorrow when empty
+	OwnerID      *int     `json:"-"`                                                    // Limits the series to this user's and shared concepts, when set
+}
+
+// ContentCalendarQuery represents the query parameters for the content calendar
+type ContentCalendarQuery struct {
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02"` // Today when empty
+	To   string `form:"to" binding:"omitempty,datetime=2006-01-02"`   // Four weeks after from when empty
+}
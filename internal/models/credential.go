@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Credential is an encrypted integration token. The secret itself is never
+// returned by the API.
+type Credential struct {
+	ID         int        `json:"id" db:"id"`
+	Provider   string     `json:"provider" db:"provider"`
+	Name       string     `json:"name" db:"name"`
+	Ciphertext []byte     `json:"-" db:"ciphertext"`
+	KeyID      string     `json:"key_id" db:"key_id"` // Master key the secret is sealed under
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+}
+
+// CreateCredentialRequest represents the request body for storing a credential
+type CreateCredentialRequest struct {
+	Provider string `json:"provider" binding:"required,oneof=linkedin x notion sendgrid zoom google assemblyai rev"`
+	Name     string `json:"name" binding:"max=255"` // Defaults to "default"
+	Secret   string `json:"secret" binding:"required"`
+}
+
+// UpdateCredentialRequest represents the request body for replacing a credential's secret
+type UpdateCredentialRequest struct {
+	Secret string `json:"secret" binding:"required"`
+}
+
+// RotateCredentialsResult reports a master key rotation
+type RotateCredentialsResult struct {
+	KeyID   string `json:"key_id"`  // The current master key
+	Rotated int    `json:"rotated"` // Credentials re-encrypted under it
+}
+
+// RotateEncryptionResult reports rewriting the text encrypted at rest
+type RotateEncryptionResult struct {
+	Encrypted bool   `json:"encrypted"`        // Whether encryption at rest is on
+	KeyID     string `json:"key_id,omitempty"` // The key text is now sealed under, when it's on
+	Rewritten int    `json:"rewritten"`        // Values sealed under it, or decrypted when it's off
+	Skipped   int    `json:"skipped"`          // Values edited during the rotation, already written the current way
+}
@@ -0,0 +1,24 @@
+package models
+
+// Diagram formats
+const (
+	DiagramMermaid  = "mermaid"
+	DiagramGraphviz = "dot"
+)
+
+// GenerateDiagramRequest represents the request body for generating a diagram
+type GenerateDiagramRequest struct {
+	Format string `json:"format" binding:"omitempty,oneof=mermaid dot"` // Default mermaid
+	Render bool   `json:"render"`                                       // Also render the source to SVG
+}
+
+// Diagram is diagram source Claude wrote to illustrate a concept or the
+// relations among a source's concepts. Diagrams aren't stored.
+type Diagram struct {
+	Format      string `json:"format"`
+	Title       string `json:"title"`
+	Source      string `json:"source"`
+	SVG         string `json:"svg,omitempty"`          // Set when rendering was requested
+	RenderError string `json:"render_error,omitempty"` // Why rendering failed, leaving only the source
+	ConceptIDs  []int  `json:"concept_ids"`            // The concepts it draws on
+}
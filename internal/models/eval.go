@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// EvalCase is a stored transcript with the concepts a good extraction should cover
+type EvalCase struct {
+	ID               int         `json:"id" db:"id"`
+	Name             string      `json:"name" db:"name"`
+	Transcript       string      `json:"transcript" db:"transcript"`
+	ExpectedConcepts StringArray `json:"expected_concepts" db:"expected_concepts"`
+	SourceContentID  *int        `json:"source_content_id,omitempty" db:"source_content_id"` // Set when copied from a source
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CreateEvalCaseRequest represents the request body for adding an eval case.
+// Give a transcript, or a source_content_id to copy the source's transcript.
+type CreateEvalCaseRequest struct {
+	Name             string   `json:"name" binding:"required,max=255"`
+	Transcript       string   `json:"transcript"`
+	SourceContentID  *int     `json:"source_content_id"`
+	ExpectedConcepts []string `json:"expected_concepts" binding:"required,min=1,dive,required"`
+}
+
+// EvalRun is one run of concept extraction over every eval case, scored by an
+// LLM judge. Model and prompt version identify what was evaluated, so runs can
+// be compared over time.
+type EvalRun struct {
+	ID            int          `json:"id" db:"id"`
+	Label         *string      `json:"label,omitempty" db:"label"`
+	Model         string       `json:"model" db:"model"`
+	PromptVersion string       `json:"prompt_version" db:"prompt_version"`
+	Instructions  *string      `json:"instructions,omitempty" db:"instructions"` // Extra stage instructions evaluated, if any
+	JudgeModel    string       `json:"judge_model" db:"judge_model"`
+	Cases         int          `json:"cases" db:"cases"`
+	Failed        int          `json:"failed" db:"failed"`       // Cases whose extraction or judging failed
+	Coverage      float64      `json:"coverage" db:"coverage"`   // Mean share of expected concepts covered
+	Precision     float64      `json:"precision" db:"precision"` // Mean share of extracted concepts judged relevant
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+	Results       []EvalResult `json:"results,omitempty"`
+}
+
+// EvalResult scores one eval case in a run
+type EvalResult struct {
+	CaseID     int         `json:"case_id" db:"case_id"`
+	CaseName   string      `json:"case_name" db:"case_name"`
+	Coverage   float64     `json:"coverage" db:"coverage"`
+	Precision  float64     `json:"precision" db:"precision"`
+	Extracted  StringArray `json:"extracted" db:"extracted"`   // Extracted concept titles
+	Missed     StringArray `json:"missed" db:"missed"`         // Expected concepts not covered
+	Irrelevant StringArray `json:"irrelevant" db:"irrelevant"` // Extracted concepts judged off-topic or wrong
+	Error      *string     `json:"error,omitempty" db:"error"`
+}
+
+// ConceptJudgement is the judge's verdict on one extraction
+type ConceptJudgement struct {
+	Covered  []bool // Per expected concept
+	Relevant []bool // Per extracted concept
+}
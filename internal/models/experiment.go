@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Prompt experiment variants
+const (
+	VariantA = "a"
+	VariantB = "b"
+)
+
+// PromptVariant is one prompt version under test. Set fields replace the
+// stage's model and instructions; an empty variant runs the stage as
+// configured, which makes a natural control.
+type PromptVariant struct {
+	Model        string `json:"model,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (v *PromptVariant) Scan(value interface{}) error {
+	if value == nil {
+		*v = PromptVariant{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan PromptVariant")
+	}
+
+	return json.Unmarshal(bytes, v)
+}
+
+// Value implements the driver.Valuer interface
+func (v PromptVariant) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+// PromptExperiment runs two prompt variants of a pipeline stage side by side.
+// While active, each new source is sampled with SampleRate and, if sampled,
+// gets variant A or B at random for that stage.
+type PromptExperiment struct {
+	ID         int           `json:"id" db:"id"`
+	Name       string        `json:"name" db:"name"`
+	Stage      string        `json:"stage" db:"stage"`
+	VariantA   PromptVariant `json:"variant_a" db:"variant_a"`
+	VariantB   PromptVariant `json:"variant_b" db:"variant_b"`
+	SampleRate float64       `json:"sample_rate" db:"sample_rate"` // Share of new sources enrolled, 0-1
+	Active     bool          `json:"active" db:"active"`
+	Winner     *string       `json:"winner,omitempty" db:"winner"` // a or b, recorded when stopped
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	EndedAt    *time.Time    `json:"ended_at,omitempty" db:"ended_at"`
+}
+
+// Variant returns the named variant's prompt
+func (e PromptExperiment) Variant(name string) PromptVariant {
+	if name == VariantB {
+		return e.VariantB
+	}
+	return e.VariantA
+}
+
+// CreatePromptExperimentRequest represents the request body for starting a prompt experiment
+type CreatePromptExperimentRequest struct {
+	Name       string        `json:"name" binding:"required,max=255"`
+	Stage      string        `json:"stage" binding:"required,oneof=concepts quizzes glossary action_items mentions content"`
+	VariantA   PromptVariant `json:"variant_a"`
+	VariantB   PromptVariant `json:"variant_b"`
+	SampleRate *float64      `json:"sample_rate" binding:"omitempty,gt=0,lte=1"` // Defaults to 1
+}
+
+// StopPromptExperimentRequest represents the request body for stopping a prompt experiment
+type StopPromptExperimentRequest struct {
+	Winner *string `json:"winner" binding:"omitempty,oneof=a b"`
+}
+
+// ArtifactFeedbackRequest represents the request body for rating a stage's output for a source
+type ArtifactFeedbackRequest struct {
+	Stage  string `json:"stage" binding:"required,oneof=concepts quizzes glossary action_items mentions content"`
+	Thumbs string `json:"thumbs" binding:"required,oneof=up down"`
+}
+
+// PromptVariantMetrics summarizes the downstream signals for sources that got
+// one variant. Edits count artifacts a user changed by hand after generation.
+type PromptVariantMetrics struct {
+	Variant        string   `json:"variant"`
+	Sources        int      `json:"sources"`
+	ThumbsUp       int      `json:"thumbs_up"` // Feedback on the experiment's stage
+	ThumbsDown     int      `json:"thumbs_down"`
+	Concepts       int      `json:"concepts"`
+	ConceptsEdited int      `json:"concepts_edited"`
+	Content        int      `json:"content"`
+	ContentEdited  int      `json:"content_edited"`
+	QuizAttempts   int      `json:"quiz_attempts"`
+	QuizCorrect    int      `json:"quiz_correct"`
+	QuizAccuracy   *float64 `json:"quiz_accuracy"` // Nil until there are attempts
+}
+
+// PromptExperimentReport compares an experiment's variants
+type PromptExperimentReport struct {
+	Experiment PromptExperiment       `json:"experiment"`
+	Variants   []PromptVariantMetrics `json:"variants"`
+}
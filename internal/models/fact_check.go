@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Fact check verdicts
+const (
+	VerdictSupported    = "supported"
+	VerdictUnsupported  = "unsupported"  // The transcript doesn't say this
+	VerdictContradicted = "contradicted" // The transcript says otherwise
+)
+
+// FactCheckClaim is one factual claim in generated content and what the
+// source transcript says about it
+type FactCheckClaim struct {
+	Claim       string     `json:"claim"`           // Quoted from the content's body
+	Range       *TextRange `json:"range,omitempty"` // Byte range of the claim in the body; nil if the quote wasn't found
+	Verdict     string     `json:"verdict"`
+	Explanation string     `json:"explanation"`
+	Segments    []int      `json:"segments"` // Indexes of the transcript segments the claim was checked against
+}
+
+// FactCheck is the result of checking generated content's claims against its
+// source transcript
+type FactCheck struct {
+	Claims        []FactCheckClaim `json:"claims"`
+	Unsupported   int              `json:"unsupported"`    // Claims unsupported or contradicted
+	AnnotatedBody string           `json:"annotated_body"` // The body with those claims marked inline
+	ContentHash   string           `json:"content_hash"`   // Of the title and body checked, to tell when they've been edited since
+	CheckedAt     time.Time        `json:"checked_at"`
+}
+
+// Scan implements the sql.Scanner interface
+func (f *FactCheck) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan FactCheck")
+	}
+
+	return json.Unmarshal(bytes, f)
+}
+
+// Value implements the driver.Valuer interface
+func (f FactCheck) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ReviewLog is one review of a concept: a quiz attempt or an imported Anki review
+type ReviewLog struct {
+	ConceptID  int       `json:"concept_id"`
+	Rating     string    `json:"rating"` // again, hard, good, easy
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
+// FSRSOptimization records FSRS weights fitted to the review history
+type FSRSOptimization struct {
+	ID            int        `json:"id" db:"id"`
+	Reviews       int        `json:"reviews" db:"reviews"`                 // Reviews with an earlier review of the same concept, which the fit predicts
+	Concepts      int        `json:"concepts" db:"concepts"`               // Concepts reviewed on at least two days
+	LogLossBefore float64    `json:"log_loss_before" db:"log_loss_before"` // Prediction error of the weights in effect
+	LogLossAfter  float64    `json:"log_loss_after" db:"log_loss_after"`   // Prediction error of the fitted weights
+	Weights       FloatArray `json:"weights" db:"weights"`
+	Applied       bool       `json:"applied" db:"applied"` // Whether the weights became the workspace's
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OptimizeFSRSRequest holds the query parameters of POST /api/reviews/optimize
+type OptimizeFSRSRequest struct {
+	DryRun bool `form:"dry_run"` // Fit and record without applying
+}
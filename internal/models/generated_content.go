@@ -33,17 +33,78 @@ func (a IntArray) Value() (driver.Value, error) {
 	return json.Marshal(a)
 }
 
+// StringArray is a custom type for handling PostgreSQL JSONB string arrays
+type StringArray []string
+
+// Scan implements the sql.Scanner interface
+func (a *StringArray) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan StringArray")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// Value implements the driver.Valuer interface
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// FloatArray is a custom type for handling PostgreSQL JSONB number arrays
+type FloatArray []float64
+
+// Scan implements the sql.Scanner interface
+func (a *FloatArray) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan FloatArray")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// Value implements the driver.Valuer interface
+func (a FloatArray) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
 // GeneratedContent represents marketing content created from concepts
 type GeneratedContent struct {
-	ID          int        `json:"id" db:"id"`
-	Platform    string     `json:"platform" db:"platform"` // linkedin, twitter, blog, email
-	Title       string     `json:"title" db:"title"`
-	Body        string     `json:"body" db:"body"`
-	ConceptIDs  IntArray   `json:"concept_ids" db:"concept_ids"` // JSON array of concept IDs
-	Status      string     `json:"status" db:"status"`           // draft, published
-	PublishedAt *time.Time `json:"published_at,omitempty" db:"published_at"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID             int                `json:"id" db:"id"`
+	Platform       string             `json:"platform" db:"platform"` // linkedin, twitter, blog, email
+	Title          string             `json:"title" db:"title"`
+	Body           string             `json:"body" db:"body"`
+	ConceptIDs     IntArray           `json:"concept_ids" db:"concept_ids"` // JSON array of concept IDs
+	Citations      Citations          `json:"citations" db:"citations"`     // Sentences tied to concept IDs and source timestamps
+	Status         string             `json:"status" db:"status"`           // draft, approved, published
+	Readability    *Readability       `json:"readability,omitempty" db:"readability"`
+	Moderation     *ContentModeration `json:"moderation,omitempty" db:"moderation"`
+	FactCheck      *FactCheck         `json:"fact_check,omitempty" db:"fact_check"`
+	SeriesID       *int               `json:"series_id,omitempty" db:"series_id"`
+	SeriesPosition *int               `json:"series_position,omitempty" db:"series_position"` // Order in the series, from 1
+	ScheduledFor   *time.Time         `json:"scheduled_for,omitempty" db:"scheduled_for"`     // When the post is planned to go out
+	Engagement     *ContentEngagement `json:"engagement,omitempty" db:"engagement"`
+	RecycledFromID *int               `json:"recycled_from_id,omitempty" db:"recycled_from_id"` // The published post this one refreshes
+	PublishedAt    *time.Time         `json:"published_at,omitempty" db:"published_at"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" db:"updated_at"`
 }
 
 // GenerateContentRequest represents the request body for generating content
@@ -59,3 +120,17 @@ type UpdateGeneratedContentRequest struct {
 	Body   *string `json:"body,omitempty"`
 	Status *string `json:"status,omitempty"`
 }
+
+// GeneratedContentListQuery filters and sorts GET /api/content
+type GeneratedContentListQuery struct {
+	Platform        string     `form:"platform" binding:"omitempty,oneof=linkedin twitter blog email"`
+	Status          string     `form:"status" binding:"omitempty,oneof=draft approved published"`
+	SourceContentID *int       `form:"source_content_id"` // Generated from a concept of this source
+	ConceptID       *int       `form:"concept_id"`
+	SeriesID        *int       `form:"series_id"`
+	From            *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`           // Created from this date (UTC)
+	To              *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`             // Created through this date (UTC)
+	Sort            string     `form:"sort" binding:"omitempty,oneof=created_at updated_at"` // created_at when omitted
+	Order           string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	OwnerID         *int       `form:"-"` // Limits the list to this user's and shared content, when set
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// GlossaryTerm is a domain term defined in one source's transcript
+type GlossaryTerm struct {
+	ID              int       `json:"id" db:"id"`
+	SourceContentID int       `json:"source_content_id" db:"source_content_id"`
+	Term            string    `json:"term" db:"term"`
+	Definition      string    `json:"definition" db:"definition"`
+	ConceptIDs      IntArray  `json:"concept_ids" db:"concept_ids"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// GlossaryDefinition is one source's definition of a merged glossary term
+type GlossaryDefinition struct {
+	TermID          int    `json:"term_id"`
+	SourceContentID int    `json:"source_content_id"`
+	Definition      string `json:"definition"`
+}
+
+// GlossaryEntry is a term merged across sources, matched case-insensitively
+type GlossaryEntry struct {
+	Term        string               `json:"term"`
+	Definitions []GlossaryDefinition `json:"definitions"`
+	ConceptIDs  []int                `json:"concept_ids"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Pipeline hook run statuses
+const (
+	HookRunSucceeded = "succeeded"
+	HookRunFailed    = "failed"
+)
+
+// PipelineHook is a webhook called after pipeline stages
+type PipelineHook struct {
+	ID        int         `json:"id" db:"id"`
+	Name      string      `json:"name" db:"name"`
+	URL       string      `json:"url" db:"url"`
+	Secret    *string     `json:"-" db:"secret"`      // Never returned by the API
+	Stages    StringArray `json:"stages" db:"stages"` // Empty runs after every stage
+	Enabled   bool        `json:"enabled" db:"enabled"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CreatePipelineHookRequest represents the request body for registering a webhook hook
+type CreatePipelineHookRequest struct {
+	Name   string   `json:"name" binding:"required,max=255"`
+	URL    string   `json:"url" binding:"required,url"`
+	Secret *string  `json:"secret"`
+	Stages []string `json:"stages"`
+}
+
+// ConceptPatch is a hook's change to one of the pipeline's concepts
+type ConceptPatch struct {
+	ID          int       `json:"id" binding:"required"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+}
+
+// HookResult is what a hook returns to merge back into the pipeline. Concept
+// patches are saved and seen by later stages; annotations are kept in the run log.
+type HookResult struct {
+	Concepts    []ConceptPatch `json:"concepts,omitempty"`
+	Annotations JSONObject     `json:"annotations,omitempty"`
+}
+
+// PipelineHookRun records one hook call after a stage
+type PipelineHookRun struct {
+	ID              int        `json:"id" db:"id"`
+	HookName        string     `json:"hook_name" db:"hook_name"`
+	Stage           string     `json:"stage" db:"stage"`
+	SourceContentID int        `json:"source_content_id" db:"source_content_id"`
+	Status          string     `json:"status" db:"status"` // succeeded or failed
+	Error           *string    `json:"error,omitempty" db:"error"`
+	ConceptsUpdated int        `json:"concepts_updated" db:"concepts_updated"`
+	Annotations     JSONObject `json:"annotations,omitempty" db:"annotations"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
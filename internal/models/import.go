@@ -0,0 +1,38 @@
+package models
+
+// Study tools decks can be imported from
+const (
+	ImportFormatAnki    = "anki"    // An Anki package (.apkg)
+	ImportFormatLogseq  = "logseq"  // A Logseq page (.md), graph (.zip), or JSON export (.json)
+	ImportFormatRemNote = "remnote" // A RemNote Markdown export (.md or .zip)
+)
+
+// ImportDeckRequest represents the form fields sent with an imported deck
+type ImportDeckRequest struct {
+	Format  string   `form:"format" binding:"omitempty,oneof=anki logseq remnote"` // Required unless the file is an .apkg
+	Title   string   `form:"title" binding:"max=500"`                              // Defaults to the file name
+	Tags    []string `form:"tags" binding:"max=20,dive,required,max=100"`          // Added to every imported concept
+	OwnerID *int     `form:"-"`                                                    // The signed-in user, set by the handler
+}
+
+// ImportedCard is a card to save as a concept, with its flashcard and, when
+// the deck has enough other answers to draw distractors from, a
+// multiple-choice question
+type ImportedCard struct {
+	Title       string
+	Description string
+	Tags        []string
+	Front       string
+	Back        string
+	Question    *QuizQuestion
+	AnkiNoteID  int64 // Links the flashcard to the Anki note it came from
+}
+
+// ImportResult reports what an import created
+type ImportResult struct {
+	SourceContent *SourceContent `json:"source_content"`
+	Concepts      int            `json:"concepts"`
+	Flashcards    int            `json:"flashcards"`
+	QuizQuestions int            `json:"quiz_questions"`
+	Duplicate     bool           `json:"duplicate"` // The file was imported before; nothing new was created
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IntegrityCheck is one referential integrity check and the rows it flagged
+type IntegrityCheck struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+	IDs         []int  `json:"ids"`                   // Flagged row IDs, up to the first 100
+	Informative bool   `json:"informative,omitempty"` // Flags rows that can be legitimate rather than broken
+}
+
+// IntegrityReport is the outcome of a referential integrity audit
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Healthy   bool             `json:"healthy"` // No check other than informative ones flagged anything
+	Checks    []IntegrityCheck `json:"checks"`
+	// Foreign keys that fall back to NO ACTION, blocking deletes of the rows they reference
+	UnguardedForeignKeys []string `json:"unguarded_foreign_keys"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed" // The pipeline ran; stage failures are in Warnings
+	JobFailed    = "failed"    // The source couldn't be fetched or saved
+)
+
+// Job step statuses
+const (
+	JobStepPending   = "pending"
+	JobStepRunning   = "running"
+	JobStepCompleted = "completed"
+	JobStepSkipped   = "skipped" // The job ended before reaching it
+)
+
+// JobStepTranscript is the first step of every job: fetching and saving the
+// source. The pipeline's stages follow.
+const JobStepTranscript = "transcript"
+
+// Job is a source queued for processing in the background. Its steps are
+// derived from its pipeline run when it's fetched.
+type Job struct {
+	ID              int                        `json:"id" db:"id"`
+	Status          string                     `json:"status" db:"status"`
+	Request         CreateSourceContentRequest `json:"request" db:"request"`
+	Spec            PipelineSpec               `json:"-" db:"spec"` // As resolved when queued, for steps before the run starts
+	SourceContentID *int                       `json:"source_content_id,omitempty" db:"source_content_id"`
+	Steps           []JobStep                  `json:"steps" db:"-"`
+	Warnings        StageWarnings              `json:"warnings" db:"warnings"`               // Failed parts of the run, once completed
+	Redactions      JSONObject                 `json:"redactions,omitempty" db:"redactions"` // Per scrub category, for new sources
+	Error           *string                    `json:"error,omitempty" db:"error"`
+	CreatedAt       time.Time                  `json:"created_at" db:"created_at"`
+	StartedAt       *time.Time                 `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt      *time.Time                 `json:"finished_at,omitempty" db:"finished_at"`
+	OwnerID         *int                       `json:"-" db:"owner_id"` // Who the saved source will belong to
+}
+
+// JobStep is one step of a job and how far it got
+type JobStep struct {
+	Name   string `json:"name"` // transcript, or a pipeline stage
+	Status string `json:"status"`
+}
+
+// JobEvent reports progress on one of a running job's steps, as it happens
+type JobEvent struct {
+	Step     string    `json:"step"`   // transcript, or a pipeline stage
+	Status   string    `json:"status"` // running, or completed once the whole step is
+	Message  string    `json:"message"`
+	Done     int       `json:"done,omitempty"`  // Concepts or platforms finished so far...
+	Total    int       `json:"total,omitempty"` // ...of this many, for stages that work through several
+	Platform string    `json:"platform,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Scan implements the sql.Scanner interface
+func (r *CreateSourceContentRequest) Scan(value interface{}) error {
+	if value == nil {
+		*r = CreateSourceContentRequest{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan CreateSourceContentRequest")
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r CreateSourceContentRequest) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// StageWarnings is a list of stage warnings stored as JSONB
+type StageWarnings []StageWarning
+
+// Scan implements the sql.Scanner interface
+func (w *StageWarnings) Scan(value interface{}) error {
+	if value == nil {
+		*w = StageWarnings{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan StageWarnings")
+	}
+
+	return json.Unmarshal(bytes, w)
+}
+
+// Value implements the driver.Valuer interface
+func (w StageWarnings) Value() (driver.Value, error) {
+	if w == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(w)
+}
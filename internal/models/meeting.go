@@ -0,0 +1,32 @@
+package models
+
+// Meeting providers
+const (
+	MeetingProviderZoom = "zoom" // Zoom cloud recordings
+	MeetingProviderMeet = "meet" // Google Meet conference records
+)
+
+// ImportMeetingRequest represents the request body for importing a meeting's
+// transcript from a provider
+type ImportMeetingRequest struct {
+	Provider   string `json:"provider" binding:"required,oneof=zoom meet"`
+	MeetingID  string `json:"meeting_id" binding:"required"` // Zoom meeting ID or UUID, or Meet conference record
+	Credential string `json:"credential"`                    // Stored credential name; "default" when omitted
+	PipelineID *int   `json:"pipeline_id"`                   // Pipeline definition to run; the default when omitted
+	Profile    string `json:"profile" binding:"max=100"`     // Processing profile to apply, by name
+
+	QuizQuestions *QuestionCount `json:"quiz_questions"`                                // Overrides the pipeline's questions per concept
+	TargetGrade   *float64       `json:"target_grade" binding:"omitempty,min=1,max=18"` // Overrides the pipeline's content grade level
+	Scrub         *bool          `json:"scrub"`                                         // Overrides SCRUB_TRANSCRIPTS for this source
+	OwnerID       *int           `json:"-"`                                             // The signed-in user, set by the handler
+}
+
+// UploadMeetingRequest represents the form fields sent with an uploaded
+// meeting transcript
+type UploadMeetingRequest struct {
+	Title      string `form:"title"`                     // Defaults to the file name
+	PipelineID *int   `form:"pipeline_id"`               // Pipeline definition to run; the default when omitted
+	Profile    string `form:"profile" binding:"max=100"` // Processing profile to apply, by name
+	Scrub      *bool  `form:"scrub"`                     // Overrides SCRUB_TRANSCRIPTS for this source
+	OwnerID    *int   `form:"-"`                         // The signed-in user, set by the handler
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Mention kinds
+const (
+	MentionBook      = "book"
+	MentionTool      = "tool"
+	MentionPerson    = "person"
+	MentionFramework = "framework"
+)
+
+// MentionKinds lists every kind of named entity extracted from sources
+var MentionKinds = []string{MentionBook, MentionTool, MentionPerson, MentionFramework}
+
+// Mention is a book, tool, person, or framework named in one source
+type Mention struct {
+	ID              int       `json:"id" db:"id"`
+	SourceContentID int       `json:"source_content_id" db:"source_content_id"`
+	Kind            string    `json:"kind" db:"kind"` // book, tool, person, framework
+	Name            string    `json:"name" db:"name"`
+	Context         string    `json:"context" db:"context"` // Why the source mentions it
+	URL             *string   `json:"url,omitempty" db:"url"`
+	Recommended     bool      `json:"recommended" db:"recommended"` // The speaker recommends it
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// MentionSource is one source's mention of an entity
+type MentionSource struct {
+	MentionID       int    `json:"mention_id"`
+	SourceContentID int    `json:"source_content_id"`
+	SourceTitle     string `json:"source_title"`
+	Context         string `json:"context"`
+	Recommended     bool   `json:"recommended"`
+}
+
+// MentionEntry is an entity merged across sources, matched by kind and
+// case-insensitive name
+type MentionEntry struct {
+	Kind        string          `json:"kind"`
+	Name        string          `json:"name"`
+	URL         *string         `json:"url,omitempty"`
+	Recommended bool            `json:"recommended"` // Recommended by at least one source
+	Sources     []MentionSource `json:"sources"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Generated content statuses, in the order content moves through them
+const (
+	ContentDraft     = "draft"
+	ContentApproved  = "approved"
+	ContentPublished = "published"
+)
+
+// Moderation checks
+const (
+	ModerationRules = "rules" // Banned terms, profanity, and figures missing from the sources
+	ModerationLLM   = "llm"   // Claude reviews the content against its concepts and sources
+)
+
+// ModerationFactCheck marks findings carried over from the content's fact
+// check, included whenever moderation runs
+const ModerationFactCheck = "fact_check"
+
+// Moderation finding kinds
+const (
+	FindingClaim     = "claim"     // A claim its concepts and sources don't support
+	FindingStatistic = "statistic" // A figure that doesn't appear in its sources
+	FindingLanguage  = "language"  // Profanity, banned terms, or off-brand wording
+)
+
+// ModerationFinding is one problem a moderation check flagged in generated content
+type ModerationFinding struct {
+	Kind    string `json:"kind"`
+	Excerpt string `json:"excerpt"` // The flagged text, quoted from the content
+	Message string `json:"message"`
+	Check   string `json:"check"` // rules, llm, or fact_check
+}
+
+// ContentModeration is the result of the latest moderation check of a piece of
+// generated content
+type ContentModeration struct {
+	Checks         []string            `json:"checks"`
+	Findings       []ModerationFinding `json:"findings"`
+	ContentHash    string              `json:"content_hash"` // Of the title and body checked, to tell when they've been edited since
+	CheckedAt      time.Time           `json:"checked_at"`
+	AcknowledgedAt *time.Time          `json:"acknowledged_at,omitempty"` // When the findings were accepted to approve or publish anyway
+}
+
+// Scan implements the sql.Scanner interface
+func (m *ContentModeration) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ContentModeration")
+	}
+
+	return json.Unmarshal(bytes, m)
+}
+
+// Value implements the driver.Valuer interface
+func (m ContentModeration) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// UpdateContentStatusRequest represents the request body for moving generated
+// content between draft, approved, and published
+type UpdateContentStatusRequest struct {
+	Status              string `json:"status" binding:"required,oneof=draft approved published"`
+	AcknowledgeFindings bool   `json:"acknowledge_findings"` // Approve or publish despite moderation findings
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Notification event types
+const (
+	EventPipelineComplete    = "pipeline_complete"
+	EventReviewDue           = "review_due"
+	EventPublishSucceeded    = "publish_succeeded"
+	EventPublishFailed       = "publish_failed"
+	EventRecycleSuggested    = "recycle_suggested"
+	EventDailyBriefing       = "daily_briefing"
+	EventDataRequestComplete = "data_request_complete"
+)
+
+// NotificationEventTypes lists every event that can produce a notification
+var NotificationEventTypes = []string{
+	EventPipelineComplete,
+	EventReviewDue,
+	EventPublishSucceeded,
+	EventPublishFailed,
+	EventRecycleSuggested,
+	EventDailyBriefing,
+	EventDataRequestComplete,
+}
+
+// JSONObject is a custom type for handling PostgreSQL JSONB objects
+type JSONObject map[string]interface{}
+
+// Scan implements the sql.Scanner interface
+func (o *JSONObject) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan JSONObject")
+	}
+
+	return json.Unmarshal(bytes, o)
+}
+
+// Value implements the driver.Valuer interface
+func (o JSONObject) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// Notification represents an in-app notification
+type Notification struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    *int       `json:"user_id,omitempty" db:"user_id"` // Who it's for; nil for the workspace
+	EventType string     `json:"event_type" db:"event_type"`     // pipeline_complete, review_due, publish_succeeded, publish_failed, recycle_suggested, daily_briefing, data_request_complete
+	Title     string     `json:"title" db:"title"`
+	Body      string     `json:"body" db:"body"`
+	Data      JSONObject `json:"data,omitempty" db:"data"`
+	ReadAt    *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationPreference controls which channels an event is delivered to,
+// for a user or the workspace
+type NotificationPreference struct {
+	EventType string    `json:"event_type" db:"event_type"`
+	InApp     bool      `json:"in_app" db:"in_app"`
+	Email     bool      `json:"email" db:"email"`
+	Webhook   bool      `json:"webhook" db:"webhook"`
+	Push      bool      `json:"push" db:"push"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateNotificationPreferenceRequest represents the request body for updating an event's preferences
+type UpdateNotificationPreferenceRequest struct {
+	InApp   *bool `json:"in_app,omitempty"`
+	Email   *bool `json:"email,omitempty"`
+	Webhook *bool `json:"webhook,omitempty"`
+	Push    *bool `json:"push,omitempty"`
+}
+
+// Push providers
+const (
+	PushProviderNtfy   = "ntfy"
+	PushProviderGotify = "gotify"
+)
+
+// PushSubscription is a phone or browser push target reached through ntfy or Gotify
+type PushSubscription struct {
+	ID              int        `json:"id" db:"id"`
+	Provider        string     `json:"provider" db:"provider"` // ntfy or gotify
+	Endpoint        string     `json:"endpoint" db:"endpoint"` // ntfy topic URL or Gotify server URL
+	Token           *string    `json:"-" db:"token"`           // Never returned
+	Name            *string    `json:"name,omitempty" db:"name"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty" db:"last_delivered_at"`
+	LastError       *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreatePushSubscriptionRequest represents the request body for adding a push subscription
+type CreatePushSubscriptionRequest struct {
+	Provider string  `json:"provider" binding:"required,oneof=ntfy gotify"`
+	Endpoint string  `json:"endpoint" binding:"required,url"`
+	Token    *string `json:"token,omitempty"`
+	Name     *string `json:"name,omitempty"`
+}
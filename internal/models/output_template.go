@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OutputTemplate is a user Go template for rendering generated content
+type OutputTemplate struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Body        string    `json:"body" db:"body"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OutputTemplateRequest represents the request body for creating or replacing an output template
+type OutputTemplateRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Body        string `json:"body" binding:"required"`
+	ContentType string `json:"content_type" binding:"max=100"` // Defaults to text/plain; checked by services.OutputContentType
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a cursor token can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a keyset position: the (created_at, id) of the last row on a page.
+// Lists are ordered by both, newest first, so pages stay stable while rows are
+// added. Lists sorted by another timestamp keep that one in CreatedAt.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "," + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token returned by Cursor.Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, id, found := strings.Cut(string(raw), ",")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{CreatedAt: time.Unix(0, n).UTC(), ID: i}, nil
+}
+
+// Page selects one page of a keyset-paginated list
+type Page struct {
+	After *Cursor // Start after this position; nil for the first page
+	Limit int
+}
+
+// Sort orders for lists that take ?order=
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc" // The default
+)
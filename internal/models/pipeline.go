@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Pipeline stage names
+const (
+	StageConcepts    = "concepts"
+	StageQuizzes     = "quizzes"
+	StageGlossary    = "glossary"
+	StageActionItems = "action_items"
+	StageMentions    = "mentions"
+	StageContent     = "content"
+	StageFactCheck   = "fact_check" // Checks the content stage's posts against the transcript
+)
+
+// PipelineStages lists every stage a pipeline can run
+var PipelineStages = []string{StageConcepts, StageQuizzes, StageGlossary, StageActionItems, StageMentions, StageContent, StageFactCheck}
+
+// ContentPlatforms lists the platforms the content stage can generate for
+var ContentPlatforms = []string{"linkedin", "twitter", "blog"}
+
+// PipelineStage configures one stage of a pipeline
+type PipelineStage struct {
+	Name         string         `json:"name" binding:"required"`
+	Model        string         `json:"model,omitempty"`                                         // Claude model for this stage; CLAUDE_MODEL when empty
+	Instructions string         `json:"instructions,omitempty"`                                  // Appended to the stage's prompt
+	Platforms    []string       `json:"platforms,omitempty"`                                     // Content stage only; all platforms when empty
+	Questions    *QuestionCount `json:"questions,omitempty"`                                     // Quizzes stage only; QUIZ_QUESTIONS_MIN/MAX when nil
+	Concepts     *ConceptCount  `json:"concepts,omitempty"`                                      // Concepts stage only; the workspace's concepts per source when nil
+	TargetGrade  *float64       `json:"target_grade,omitempty" binding:"omitempty,min=1,max=18"` // Content stage only; Flesch-Kincaid grade level to write at
+}
+
+// Pipeline run statuses
+const (
+	PipelineRunRunning    = "running"
+	PipelineRunCompleted  = "completed"
+	PipelineRunIncomplete = "incomplete" // Interrupted, or concept extraction failed; can be resumed
+)
+
+// PipelineRun checkpoints a source's pipeline run
+type PipelineRun struct {
+	SourceContentID int          `json:"source_content_id" db:"source_content_id"`
+	Spec            PipelineSpec `json:"spec" db:"spec"`
+	Status          string       `json:"status" db:"status"`
+	CompletedStages StringArray  `json:"completed_stages" db:"completed_stages"` // In completion order
+	StartedAt       time.Time    `json:"started_at" db:"started_at"`
+	UpdatedAt       time.Time    `json:"updated_at" db:"updated_at"`
+	FinishedAt      *time.Time   `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// StageWarning reports part of a pipeline run that failed while the rest
+// succeeded, so clients can show and retry just that part
+type StageWarning struct {
+	Stage     string `json:"stage"`
+	ConceptID *int   `json:"concept_id,omitempty"` // Set when one concept's quiz questions failed
+	Platform  string `json:"platform,omitempty"`   // Set when one platform's content failed
+	Message   string `json:"message"`
+}
+
+// RetryStageRequest represents the request body for re-running one stage of
+// a source's pipeline, typically for the warnings a run reported
+type RetryStageRequest struct {
+	Stage       string   `json:"stage" binding:"required,oneof=quizzes glossary action_items mentions content fact_check"`
+	ConceptIDs  []int    `json:"concept_ids"`                                          // Quizzes only; concepts without questions when empty
+	Platforms   []string `json:"platforms" binding:"dive,oneof=linkedin twitter blog"` // Content only; the pipeline's platforms when empty
+	TargetGrade *float64 `json:"target_grade" binding:"omitempty,min=1,max=18"`        // Content only; the pipeline's target grade when nil
+}
+
+// QuestionCount is how many quiz questions to generate per concept
+type QuestionCount struct {
+	Min int `json:"min" binding:"min=1,max=10"`
+	Max int `json:"max" binding:"min=1,max=10,gtefield=Min"`
+}
+
+// PipelineSpec is the ordered list of stages a pipeline runs
+type PipelineSpec struct {
+	Stages []PipelineStage `json:"stages" binding:"required,min=1,dive"`
+
+	Profile string `json:"-"` // Processing profile the spec was resolved for, recorded on new sources
+}
+
+// Scan implements the sql.Scanner interface
+func (p *PipelineSpec) Scan(value interface{}) error {
+	if value == nil {
+		*p = PipelineSpec{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan PipelineSpec")
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// Value implements the driver.Valuer interface
+func (p PipelineSpec) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// PipelineDefinition is a named, stored pipeline spec. The default pipeline
+// processes new sources unless a request names another.
+type PipelineDefinition struct {
+	ID        int          `json:"id" db:"id"`
+	Name      string       `json:"name" db:"name"`
+	Spec      PipelineSpec `json:"spec" db:"spec"`
+	IsDefault bool         `json:"is_default" db:"is_default"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// PipelineDefinitionRequest represents the request body for creating or replacing a pipeline
+type PipelineDefinitionRequest struct {
+	Name string       `json:"name" binding:"required,max=255"`
+	Spec PipelineSpec `json:"spec" binding:"required"`
+}
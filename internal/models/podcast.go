@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ImportPodcastRequest represents the request body for importing podcast
+// episodes from an RSS feed or an episode's audio URL
+type ImportPodcastRequest struct {
+	URL        string `json:"url" binding:"required"`                    // RSS feed, or an episode's audio file
+	Episodes   int    `json:"episodes" binding:"omitempty,min=1,max=20"` // Newest episodes of a feed to import; 1 when omitted
+	PipelineID *int   `json:"pipeline_id"`                               // Pipeline definition to run; the default when omitted
+	Profile    string `json:"profile" binding:"max=100"`                 // Processing profile to apply, by name
+
+	QuizQuestions *QuestionCount `json:"quiz_questions"`                                // Overrides the pipeline's questions per concept
+	TargetGrade   *float64       `json:"target_grade" binding:"omitempty,min=1,max=18"` // Overrides the pipeline's content grade level
+	Scrub         *bool          `json:"scrub"`                                         // Overrides SCRUB_TRANSCRIPTS for this source
+	OwnerID       *int           `json:"-"`                                             // The signed-in user, set by the handler
+}
+
+// PodcastImport lists the episodes a podcast import queued
+type PodcastImport struct {
+	Feed     string           `json:"feed,omitempty"` // The podcast's title; empty for an episode URL
+	Episodes []PodcastEpisode `json:"episodes"`
+}
+
+// PodcastEpisode is an episode picked for import, with the job transcribing
+// it, or why it was skipped
+type PodcastEpisode struct {
+	Title       string     `json:"title"`
+	AudioURL    string     `json:"audio_url"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Duration    int        `json:"duration,omitempty"` // Seconds, when the feed says
+	Job         *Job       `json:"job,omitempty"`
+	Skipped     string     `json:"skipped,omitempty"`
+}
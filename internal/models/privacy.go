@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Data request kinds
+const (
+	DataRequestExport = "export" // Archive everything stored, for download
+	DataRequestDelete = "delete" // Delete the account, and the workspace's data with its last user
+)
+
+// DataRequest is a user's request to export their data or delete their
+// account, carried out in the background. Its status is one of the job
+// statuses.
+type DataRequest struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      *int       `json:"user_id,omitempty" db:"user_id"` // Cleared once a deletion removes the user
+	Kind        string     `json:"kind" db:"kind"`
+	Status      string     `json:"status" db:"status"`
+	ExportKey   *string    `json:"-" db:"export_key"`
+	DownloadURL *string    `json:"download_url,omitempty" db:"-"` // Signed link to a finished export's ZIP archive
+	Result      JSONObject `json:"result,omitempty" db:"result"`  // Rows and files exported or deleted
+	Error       *string    `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// DeleteAccountRequest represents the request body for DELETE /api/me
+type DeleteAccountRequest struct {
+	Confirm bool `json:"confirm" binding:"required"` // Must be true
+}
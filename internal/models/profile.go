@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ConceptCount is how many concepts to extract per source
+type ConceptCount struct {
+	Min int `json:"min" binding:"min=1,max=20"`
+	Max int `json:"max" binding:"min=1,max=20,gtefield=Min"`
+}
+
+// Scan implements the sql.Scanner interface
+func (c *ConceptCount) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ConceptCount")
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements the driver.Valuer interface
+func (c ConceptCount) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// StageInstructions maps pipeline stage names to extra prompt instructions
+type StageInstructions map[string]string
+
+// Scan implements the sql.Scanner interface
+func (s *StageInstructions) Scan(value interface{}) error {
+	if value == nil {
+		*s = StageInstructions{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan StageInstructions")
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
+// Value implements the driver.Valuer interface
+func (s StageInstructions) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(s)
+}
+
+// ProcessingProfile tailors processing to a content genre, such as a sales
+// call or a conference talk. A source submitted with a profile runs the
+// profile's pipeline with its concept count, platforms, and instructions.
+type ProcessingProfile struct {
+	ID           int               `json:"id" db:"id"`
+	Name         string            `json:"name" db:"name"`
+	Description  *string           `json:"description,omitempty" db:"description"`
+	PipelineID   *int              `json:"pipeline_id,omitempty" db:"pipeline_id"` // Pipeline definition to run; the default when unset
+	Concepts     *ConceptCount     `json:"concepts,omitempty" db:"concepts"`       // Overrides the concepts per source
+	Platforms    StringArray       `json:"platforms" db:"platforms"`               // Overrides the content stage's platforms when set
+	Instructions StageInstructions `json:"instructions" db:"instructions"`         // Appended to each named stage's instructions
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ProcessingProfileRequest represents the request body for creating or replacing a profile
+type ProcessingProfileRequest struct {
+	Name         string            `json:"name" binding:"required,max=100"`
+	Description  *string           `json:"description"`
+	PipelineID   *int              `json:"pipeline_id"`
+	Concepts     *ConceptCount     `json:"concepts"`
+	Platforms    []string          `json:"platforms" binding:"dive,oneof=linkedin twitter blog"`
+	Instructions StageInstructions `json:"instructions" binding:"dive,keys,oneof=concepts quizzes glossary action_items mentions content fact_check,endkeys,max=4000"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// What a query searches
+const (
+	QueryFromConcepts = "concepts"
+	QueryFromSources  = "sources"
+)
+
+// QueryRequest represents the request body for querying concepts or sources
+type QueryRequest struct {
+	Query           string `json:"query" binding:"required,max=1000"`
+	From            string `json:"from" binding:"omitempty,oneof=concepts sources"` // Defaults to concepts
+	IncludeArchived bool   `json:"include_archived"`
+	Limit           int    `json:"limit" binding:"omitempty,min=1,max=200"` // Defaults to 50
+	Cursor          string `json:"cursor"`                                  // next_cursor from the previous page
+}
@@ -1,53 +1,154 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 // QuizQuestion represents a generated quiz question
 type QuizQuestion struct {
-	ID            int       `json:"id" db:"id"`
-	ConceptID     int       `json:"concept_id" db:"concept_id"`
-	Question      string    `json:"question" db:"question"`
-	OptionA       string    `json:"option_a" db:"option_a"`
-	OptionB       string    `json:"option_b" db:"option_b"`
-	OptionC       string    `json:"option_c" db:"option_c"`
-	OptionD       string    `json:"option_d" db:"option_d"`
-	CorrectAnswer string    `json:"correct_answer" db:"correct_answer"` // A, B, C, or D
-	Explanation   string    `json:"explanation" db:"explanation"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ID                   int        `json:"id" db:"id"`
+	ConceptID            int        `json:"concept_id" db:"concept_id"`
+	Question             string     `json:"question" db:"question"`
+	OptionA              string     `json:"option_a" db:"option_a"`
+	OptionB              string     `json:"option_b" db:"option_b"`
+	OptionC              string     `json:"option_c" db:"option_c"`
+	OptionD              string     `json:"option_d" db:"option_d"`
+	CorrectAnswer        string     `json:"correct_answer" db:"correct_answer"` // A, B, C, or D
+	Explanation          string     `json:"explanation" db:"explanation"`
+	DistractorRationales StringMap  `json:"distractor_rationales" db:"distractor_rationales"` // Incorrect option letter -> why it's wrong
+	Lapses               int        `json:"lapses" db:"lapses"`                               // Times answered wrong
+	SuspendedAt          *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`         // Set when the question becomes a leech
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StringMap is a custom type for handling PostgreSQL JSONB string-to-string objects
+type StringMap map[string]string
+
+// Scan implements the sql.Scanner interface
+func (m *StringMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan StringMap")
+	}
+
+	return json.Unmarshal(bytes, m)
+}
+
+// Value implements the driver.Valuer interface
+func (m StringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
 }
 
 // QuizAttempt represents a user's quiz answer tracking
 type QuizAttempt struct {
-	ID             int       `json:"id" db:"id"`
-	QuestionID     int       `json:"question_id" db:"question_id"`
-	SelectedAnswer string    `json:"selected_answer" db:"selected_answer"`
-	Correct        bool      `json:"correct" db:"correct"`
-	AttemptedAt    time.Time `json:"attempted_at" db:"attempted_at"`
+	ID              int       `json:"id" db:"id"`
+	QuestionID      int       `json:"question_id" db:"question_id"`
+	SelectedAnswer  string    `json:"selected_answer" db:"selected_answer"` // Canonical letter
+	Correct         bool      `json:"correct" db:"correct"`
+	SessionID       *int      `json:"session_id,omitempty" db:"session_id"`
+	DisplayedAnswer *string   `json:"displayed_answer,omitempty" db:"displayed_answer"` // Letter as shown in a shuffled session
+	Rating          *string   `json:"rating,omitempty" db:"rating"`                     // again, hard, good, easy
+	TimeToAnswerMs  *int      `json:"time_to_answer_ms,omitempty" db:"time_to_answer_ms"`
+	LatencyMs       *int      `json:"latency_ms,omitempty" db:"latency_ms"` // Server-measured, session attempts only
+	TimedOut        bool      `json:"timed_out" db:"timed_out"`
+	AttemptedAt     time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// GenerateQuizzesRequest represents the request body for generating more
+// questions for a concept
+type GenerateQuizzesRequest struct {
+	Count int `json:"count" binding:"required,min=1,max=10"`
+}
+
+// QuizAttemptFilter narrows a quiz attempt listing; nil fields don't filter
+type QuizAttemptFilter struct {
+	QuestionID *int
+	SessionID  *int
+	ConceptID  *int
 }
 
 // LearningProgress represents spaced repetition tracking
 type LearningProgress struct {
-	ID                 int       `json:"id" db:"id"`
-	ConceptID          int       `json:"concept_id" db:"concept_id"`
-	MasteryLevel       int       `json:"mastery_level" db:"mastery_level"` // 0-5
-	ConsecutiveCorrect int       `json:"consecutive_correct" db:"consecutive_correct"`
-	LastReviewedAt     time.Time `json:"last_reviewed_at" db:"last_reviewed_at"`
-	NextReviewAt       time.Time `json:"next_review_at" db:"next_review_at"`
-	CreatedAt          time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int        `json:"id" db:"id"`
+	ConceptID          int        `json:"concept_id" db:"concept_id"`
+	MasteryLevel       int        `json:"mastery_level" db:"mastery_level"` // 0-5
+	ConsecutiveCorrect int        `json:"consecutive_correct" db:"consecutive_correct"`
+	EaseFactor         float64    `json:"ease_factor" db:"ease_factor"`
+	IntervalDays       int        `json:"interval_days" db:"interval_days"`
+	Stability          *float64   `json:"stability,omitempty" db:"stability"`   // FSRS memory stability in days; set once scheduled by FSRS
+	Difficulty         *float64   `json:"difficulty,omitempty" db:"difficulty"` // FSRS difficulty, 1-10
+	LastReviewedAt     *time.Time `json:"last_reviewed_at,omitempty" db:"last_reviewed_at"`
+	NextReviewAt       *time.Time `json:"next_review_at,omitempty" db:"next_review_at"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AttemptSummary aggregates quiz attempts for a concept or question
+type AttemptSummary struct {
+	TotalAttempts   int        `json:"total_attempts"`
+	CorrectAttempts int        `json:"correct_attempts"`
+	Accuracy        float64    `json:"accuracy"` // 0-1, zero when there are no attempts
+	LastAttemptedAt *time.Time `json:"last_attempted_at,omitempty"`
 }
 
-// AnswerQuizRequest represents the request body for answering a quiz question
+// Self-reported recall ratings, as in Anki
+const (
+	RatingAgain = "again"
+	RatingHard  = "hard"
+	RatingGood  = "good"
+	RatingEasy  = "easy"
+)
+
+// AnswerQuizRequest represents the request body for answering a quiz question.
+// Rating is optional; without it a correct answer counts as good and a wrong one as again.
 type AnswerQuizRequest struct {
 	QuestionID     int    `json:"question_id" binding:"required"`
 	SelectedAnswer string `json:"selected_answer" binding:"required,oneof=A B C D"`
+	Rating         string `json:"rating,omitempty" binding:"omitempty,oneof=again hard good easy"`
+	TimeToAnswerMs *int   `json:"time_to_answer_ms,omitempty" binding:"omitempty,min=0"`
 }
 
 // AnswerQuizResponse represents the response after answering a quiz question
 type AnswerQuizResponse struct {
-	Correct        bool      `json:"correct"`
-	CorrectAnswer  string    `json:"correct_answer"`
-	Explanation    string    `json:"explanation"`
-	NextReviewAt   time.Time `json:"next_review_at"`
-	MasteryLevel   int       `json:"mastery_level"`
+	AttemptID     int               `json:"attempt_id"`
+	Correct       bool              `json:"correct"`
+	CorrectAnswer string            `json:"correct_answer"`
+	Explanation   string            `json:"explanation"`
+	Rationale     string            `json:"rationale,omitempty"` // Why the selected option is wrong; empty when correct
+	Rating        string            `json:"rating"`              // Rating used for scheduling
+	Leech         bool              `json:"leech,omitempty"`     // Question was just suspended as a leech
+	NextReviewAt  time.Time         `json:"next_review_at"`
+	MasteryLevel  int               `json:"mastery_level"`
+	Progress      *LearningProgress `json:"progress"`
+}
+
+// QuizExplanation is a deeper explanation of a quiz question, cached per question
+type QuizExplanation struct {
+	QuestionID    int       `json:"question_id" db:"question_id"`
+	Explanation   string    `json:"explanation" db:"explanation"`
+	Analogy       string    `json:"analogy" db:"analogy"`
+	WorkedExample string    `json:"worked_example" db:"worked_example"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Flashcard is a plain front/back card, typically converted from a leech question
+type Flashcard struct {
+	ID         int       `json:"id" db:"id"`
+	ConceptID  int       `json:"concept_id" db:"concept_id"`
+	QuestionID *int      `json:"question_id,omitempty" db:"question_id"`
+	Front      string    `json:"front" db:"front"`
+	Back       string    `json:"back" db:"back"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
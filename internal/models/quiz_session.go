@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// QuizSession is one sitting of quiz questions served with shuffled options
+type QuizSession struct {
+	ID               int       `json:"id" db:"id"`
+	SourceContentID  *int      `json:"source_content_id,omitempty" db:"source_content_id"`
+	ConceptID        *int      `json:"concept_id,omitempty" db:"concept_id"`
+	Kind             string    `json:"kind" db:"kind"` // quiz or review
+	Tag              *string   `json:"tag,omitempty" db:"tag"`
+	CollectionID     *int      `json:"collection_id,omitempty" db:"collection_id"`
+	TimeLimitSeconds *int      `json:"time_limit_seconds,omitempty" db:"time_limit_seconds"` // Per-question limit in timed mode
+	OwnerID          *int      `json:"-" db:"owner_id"`                                      // The user who started it; nil for shared sessions
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// Quiz session kinds
+const (
+	SessionKindQuiz   = "quiz"
+	SessionKindReview = "review"
+)
+
+// QuizSessionQuestion records how a question's options were shuffled in a session
+type QuizSessionQuestion struct {
+	SessionID   int    `json:"session_id" db:"session_id"`
+	QuestionID  int    `json:"question_id" db:"question_id"`
+	Position    int    `json:"position" db:"position"`
+	OptionOrder string `json:"option_order" db:"option_order"` // Canonical letter shown at displayed A, B, C, D
+}
+
+// ServedQuestion is a quiz question as presented in a session, with options in
+// displayed order and without the answer or explanation
+type ServedQuestion struct {
+	QuestionID int    `json:"question_id"`
+	ConceptID  int    `json:"concept_id"`
+	Position   int    `json:"position"`
+	Question   string `json:"question"`
+	OptionA    string `json:"option_a"`
+	OptionB    string `json:"option_b"`
+	OptionC    string `json:"option_c"`
+	OptionD    string `json:"option_d"`
+}
+
+// QuizSessionDetail is a session with its served questions
+type QuizSessionDetail struct {
+	Session   QuizSession       `json:"session"`
+	Questions []ServedQuestion  `json:"questions"`
+	Score     *QuizSessionScore `json:"score,omitempty"`
+}
+
+// QuizSessionScore scores the first answer to each question in a session. In
+// timed mode a correct answer earns between 1 point (instant) and 0.5 points
+// (at the limit); answers past the limit earn nothing. Untimed correct answers
+// earn 1 point.
+type QuizSessionScore struct {
+	Answered     int      `json:"answered"`
+	Correct      int      `json:"correct"`
+	TimedOut     int      `json:"timed_out"`
+	Accuracy     float64  `json:"accuracy"` // 0-1, zero when nothing is answered
+	AvgLatencyMs *float64 `json:"avg_latency_ms,omitempty"`
+	Points       float64  `json:"points"`
+	MaxPoints    int      `json:"max_points"` // One per question in the session
+}
+
+// CreateQuizSessionRequest represents the request body for starting a quiz session.
+// Exactly one of SourceContentID or ConceptID must be set.
+type CreateQuizSessionRequest struct {
+	SourceContentID  *int `json:"source_content_id,omitempty"`
+	ConceptID        *int `json:"concept_id,omitempty"`
+	TimeLimitSeconds *int `json:"time_limit_seconds,omitempty" binding:"omitempty,min=1,max=3600"`
+	OwnerID          *int `json:"-"` // Limits the session to this user's and shared content, when set
+	UserID           *int `json:"-"` // The signed-in user starting it, who owns the session
+}
+
+// ReviewSessionRequest filters a themed review session. At least one of Tag,
+// SourceContentID, or CollectionID must be set; each narrows the selection.
+type ReviewSessionRequest struct {
+	Tag             *string
+	SourceContentID *int
+	CollectionID    *int
+	Limit           int            // Maximum number of questions
+	Location        *time.Location // Zone deciding what's due today; nil uses UTC
+	OwnerID         *int           // Limits the session to this user's and shared concepts, when set
+	UserID          *int           // The signed-in user starting it, who owns the session
+}
+
+// SessionAnswerResponse represents the response after answering a question in a session
+type SessionAnswerResponse struct {
+	AttemptID     int               `json:"attempt_id"`
+	Correct       bool              `json:"correct"`
+	CorrectAnswer string            `json:"correct_answer"` // Displayed letter of the correct option
+	Explanation   string            `json:"explanation"`
+	Rationale     string            `json:"rationale,omitempty"` // Why the selected option is wrong; empty when correct
+	Rating        string            `json:"rating"`              // Rating used for scheduling
+	Leech         bool              `json:"leech,omitempty"`     // Question was just suspended as a leech
+	LatencyMs     int               `json:"latency_ms"`          // Server-measured time since the question became current
+	TimedOut      bool              `json:"timed_out,omitempty"` // Answered after the session's time limit
+	Progress      *LearningProgress `json:"progress"`
+}
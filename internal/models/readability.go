@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Readability scores how hard generated content is to read
+type Readability struct {
+	Words              int      `json:"words"`
+	Sentences          int      `json:"sentences"`
+	ReadingTimeSeconds int      `json:"reading_time_seconds"`
+	GradeLevel         float64  `json:"grade_level"`            // Flesch-Kincaid: the US school grade that can follow the text
+	PassiveRatio       float64  `json:"passive_ratio"`          // Share of sentences in the passive voice, 0-1
+	TargetGrade        *float64 `json:"target_grade,omitempty"` // The grade level generation aimed for, if any
+}
+
+// Scan implements the sql.Scanner interface
+func (r *Readability) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Readability")
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
+// Value implements the driver.Valuer interface
+func (r Readability) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Reasons published content is suggested for recycling
+const (
+	RecycleHighPerforming = "high_performing" // Engagement rate among the top quarter of its platform's posts
+	RecycleEvergreen      = "evergreen"       // Old, and nothing in it dates it
+)
+
+// ContentEngagement is the latest engagement imported for a published post
+type ContentEngagement struct {
+	Impressions int       `json:"impressions"`
+	Reactions   int       `json:"reactions"`
+	Comments    int       `json:"comments"`
+	Shares      int       `json:"shares"`
+	Clicks      int       `json:"clicks"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// Rate returns interactions per impression, 0 without impressions
+func (e ContentEngagement) Rate() float64 {
+	if e.Impressions == 0 {
+		return 0
+	}
+	return float64(e.Reactions+e.Comments+e.Shares+e.Clicks) / float64(e.Impressions)
+}
+
+// Scan implements the sql.Scanner interface
+func (e *ContentEngagement) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ContentEngagement")
+	}
+
+	return json.Unmarshal(bytes, e)
+}
+
+// Value implements the driver.Valuer interface
+func (e ContentEngagement) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// RecordEngagementRequest represents the request body for importing a post's
+// engagement, replacing what was imported before
+type RecordEngagementRequest struct {
+	Impressions int `json:"impressions" binding:"min=0"`
+	Reactions   int `json:"reactions" binding:"min=0"`
+	Comments    int `json:"comments" binding:"min=0"`
+	Shares      int `json:"shares" binding:"min=0"`
+	Clicks      int `json:"clicks" binding:"min=0"`
+}
+
+// RecycleSuggestion surfaces a published post worth recycling
+type RecycleSuggestion struct {
+	ID                 int               `json:"id" db:"id"`
+	GeneratedContentID int               `json:"generated_content_id" db:"generated_content_id"`
+	Reason             string            `json:"reason" db:"reason"` // high_performing, evergreen
+	EngagementRate     *float64          `json:"engagement_rate,omitempty" db:"engagement_rate"`
+	DismissedAt        *time.Time        `json:"dismissed_at,omitempty" db:"dismissed_at"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+	Content            *GeneratedContent `json:"content,omitempty" db:"-"`
+}
+
+// RecycleSuggestionsQuery represents the query parameters for listing recycle suggestions
+type RecycleSuggestionsQuery struct {
+	IncludeDismissed bool `form:"include_dismissed"`
+}
+
+// RecycleContentRequest represents the request body for generating a
+// refreshed variant of a published post
+type RecycleContentRequest struct {
+	Platform string `json:"platform" binding:"omitempty,oneof=linkedin twitter blog"` // The original's platform when empty
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Retention policy names
+const (
+	RetentionArchived = "archived"
+	RetentionAudio    = "audio"
+	RetentionExports  = "exports"
+)
+
+// RetentionPolicy describes one configured retention rule
+type RetentionPolicy struct {
+	Name    string `json:"name"`
+	After   string `json:"after"` // Age at which items are removed, as a Go duration; "0s" when disabled
+	Enabled bool   `json:"enabled"`
+}
+
+// RetentionItem is a row or stored object a retention policy removes
+type RetentionItem struct {
+	Policy string    `json:"policy"`
+	Kind   string    `json:"kind"` // source_content, concept, or object
+	ID     *int      `json:"id,omitempty"`
+	Key    string    `json:"key,omitempty"` // Object key, for stored objects
+	Title  string    `json:"title,omitempty"`
+	Since  time.Time `json:"since"` // When archived, or when the object was last modified
+}
+
+// RetentionReport lists what a retention run removed, or would remove on a dry run
+type RetentionReport struct {
+	DryRun   bool              `json:"dry_run"`
+	RanAt    time.Time         `json:"ran_at"`
+	Policies []RetentionPolicy `json:"policies"`
+	Items    []RetentionItem   `json:"items"`
+	Counts   map[string]int    `json:"counts"` // Items per policy
+	Errors   []string          `json:"errors,omitempty"`
+}
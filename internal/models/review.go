@@ -0,0 +1,33 @@
+package models
+
+// DueReview is a concept whose review is due, with its schedule and a
+// question to review it with
+type DueReview struct {
+	Concept  Concept          `json:"concept"`
+	Progress LearningProgress `json:"progress"`
+	Question *ServedQuestion  `json:"question"` // Least recently answered unsuspended question; null when it has none
+}
+
+// DueConcept is a concept whose review is due as the database holds it, with
+// its schedule and the question to review it with, nil when it has none
+type DueConcept struct {
+	Concept  Concept
+	Progress LearningProgress
+	Question *QuizQuestion
+}
+
+// DueReviews is today's review queue: the most overdue concepts, up to what's
+// left of the daily limit
+type DueReviews struct {
+	Reviews       []DueReview `json:"reviews"`
+	Count         int         `json:"count"`
+	Due           int         `json:"due"`            // Concepts due now, including those past the limit
+	ReviewedToday int         `json:"reviewed_today"` // Concepts reviewed since local midnight, which count toward the limit
+	DailyLimit    int         `json:"daily_limit"`
+}
+
+// CompleteReviewRequest represents the request body for completing a concept's
+// review without a quiz question
+type CompleteReviewRequest struct {
+	Rating string `json:"rating" binding:"required,oneof=again hard good easy"` // How well the concept was recalled
+}
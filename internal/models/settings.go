@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Spaced repetition scheduling algorithms
+const (
+	ReviewAlgorithmSM2   = "sm2"   // SuperMemo 2, as adapted by Anki
+	ReviewAlgorithmFSRS  = "fsrs"  // Free Spaced Repetition Scheduler (FSRS-4.5)
+	ReviewAlgorithmFixed = "fixed" // A fixed ladder of intervals
+)
+
+// ReviewSettings are the spaced repetition algorithm and its parameters
+type ReviewSettings struct {
+	Algorithm           string `json:"algorithm"`             // sm2, fsrs, or fixed
+	RelearnDelayMinutes int    `json:"relearn_delay_minutes"` // Delay before a failed concept is due again
+	MaximumIntervalDays int    `json:"maximum_interval_days"` // Cap on any interval
+	DailyLimit          int    `json:"daily_limit"`           // Most concepts the daily review queue serves a day
+
+	// SM-2
+	StartingEase float64 `json:"starting_ease"` // Ease factor of a concept's first review
+	MinimumEase  float64 `json:"minimum_ease"`  // Floor the ease factor never drops below
+	HardInterval float64 `json:"hard_interval"` // Interval multiplier for a hard rating
+	EasyBonus    float64 `json:"easy_bonus"`    // Extra interval multiplier for an easy rating
+
+	// Fixed: days until the next review after 1, 2, ... consecutive correct
+	// reviews, the last repeating
+	FixedIntervals []int `json:"fixed_intervals"`
+
+	// FSRS
+	RequestRetention float64   `json:"request_retention"` // Recall probability reviews are scheduled at
+	Weights          []float64 `json:"weights"`           // The 17 FSRS-4.5 model weights
+}
+
+// WorkspaceSettings is the configuration in effect: stored overrides on top of
+// the environment's defaults
+type WorkspaceSettings struct {
+	DefaultPlatforms []string        `json:"default_platforms"` // Content platforms for pipelines that don't list any
+	ConceptsMin      int             `json:"concepts_min"`
+	ConceptsMax      int             `json:"concepts_max"`
+	DefaultModel     string          `json:"default_model"` // Claude model for stages that don't name one
+	Timezone         string          `json:"timezone"`      // IANA zone for users without one and for background jobs
+	Review           ReviewSettings  `json:"review"`
+	Features         map[string]bool `json:"features"` // Feature flags, toggled through the admin API
+}
+
+// ReviewSettingsOverrides are the spaced repetition parameters set through the API
+type ReviewSettingsOverrides struct {
+	Algorithm           *string    `json:"algorithm,omitempty" binding:"omitempty,oneof=sm2 fsrs fixed"`
+	RelearnDelayMinutes *int       `json:"relearn_delay_minutes,omitempty" binding:"omitempty,min=1,max=1440"`
+	MaximumIntervalDays *int       `json:"maximum_interval_days,omitempty" binding:"omitempty,min=1,max=36500"`
+	DailyLimit          *int       `json:"daily_limit,omitempty" binding:"omitempty,min=1,max=9999"`
+	StartingEase        *float64   `json:"starting_ease,omitempty" binding:"omitempty,min=1.3,max=5"`
+	MinimumEase         *float64   `json:"minimum_ease,omitempty" binding:"omitempty,min=1,max=3"`
+	HardInterval        *float64   `json:"hard_interval,omitempty" binding:"omitempty,min=1,max=2"`
+	EasyBonus           *float64   `json:"easy_bonus,omitempty" binding:"omitempty,min=1,max=3"`
+	FixedIntervals      *[]int     `json:"fixed_intervals,omitempty" binding:"omitempty,min=1,max=20,dive,min=1,max=36500"`
+	RequestRetention    *float64   `json:"request_retention,omitempty" binding:"omitempty,min=0.7,max=0.97"`
+	Weights             *[]float64 `json:"weights,omitempty" binding:"omitempty,len=17,dive,min=0,max=100"`
+}
+
+// SettingsOverrides are the workspace settings set through the API. Unset
+// fields fall back to the environment.
+type SettingsOverrides struct {
+	DefaultPlatforms *[]string                `json:"default_platforms,omitempty"`
+	ConceptsMin      *int                     `json:"concepts_min,omitempty"`
+	ConceptsMax      *int                     `json:"concepts_max,omitempty"`
+	DefaultModel     *string                  `json:"default_model,omitempty"`
+	Timezone         *string                  `json:"timezone,omitempty"`
+	Review           *ReviewSettingsOverrides `json:"review,omitempty"`
+	Features         map[string]bool          `json:"features,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (o *SettingsOverrides) Scan(value interface{}) error {
+	if value == nil {
+		*o = SettingsOverrides{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan SettingsOverrides")
+	}
+
+	return json.Unmarshal(bytes, o)
+}
+
+// Value implements the driver.Valuer interface
+func (o SettingsOverrides) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+// UpdateSettingsRequest represents the request body for changing workspace
+// settings. Given fields are overridden (review parameters individually);
+// settings named in Reset go back to the environment's values.
+type UpdateSettingsRequest struct {
+	DefaultPlatforms *[]string                `json:"default_platforms" binding:"omitempty,min=1,dive,oneof=linkedin twitter blog"`
+	ConceptsMin      *int                     `json:"concepts_min" binding:"omitempty,min=1,max=20"`
+	ConceptsMax      *int                     `json:"concepts_max" binding:"omitempty,min=1,max=20"`
+	DefaultModel     *string                  `json:"default_model" binding:"omitempty,min=1,max=100"`
+	Timezone         *string                  `json:"timezone" binding:"omitempty,max=64"`
+	Review           *ReviewSettingsOverrides `json:"review"`
+	Reset            []string                 `json:"reset" binding:"dive,oneof=default_platforms concepts_min concepts_max default_model timezone review"`
+}
+
+// SettingsResponse is the workspace configuration in effect and what overrides it
+type SettingsResponse struct {
+	Settings  WorkspaceSettings `json:"settings"`
+	Overrides SettingsOverrides `json:"overrides"`
+	UpdatedAt *time.Time        `json:"updated_at,omitempty"` // When the overrides last changed; unset if never
+
+	Rescheduled *int `json:"rescheduled,omitempty"` // Concepts rescheduled because the review algorithm changed
+}
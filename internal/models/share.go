@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ShareLink publishes a concept or a generated content piece at a public URL.
+// Exactly one of ConceptID and GeneratedContentID is set.
+type ShareLink struct {
+	Token              string    `json:"token" db:"token"`
+	ConceptID          *int      `json:"concept_id,omitempty" db:"concept_id"`
+	GeneratedContentID *int      `json:"generated_content_id,omitempty" db:"generated_content_id"`
+	URL                string    `json:"url" db:"-"` // Public page URL, filled in by the handler
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// What a share link publishes
+const (
+	ShareTypeConcept = "concept"
+	ShareTypeContent = "content"
+)
+
+// PublicShare is a share link's item as the public read API serves it. Type
+// says which of Concept and Content is set.
+type PublicShare struct {
+	Token   string         `json:"token"`
+	Type    string         `json:"type"` // concept or content
+	URL     string         `json:"url"`  // Public page URL, filled in by the handler
+	Concept *PublicConcept `json:"concept,omitempty"`
+	Content *PublicContent `json:"content,omitempty"`
+}
+
+// PublicConcept is a shared concept with its quiz set
+type PublicConcept struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Tags        StringArray          `json:"tags"`
+	SourceTitle string               `json:"source_title,omitempty"`
+	SourceURL   string               `json:"source_url,omitempty"`
+	Quiz        []PublicQuizQuestion `json:"quiz"` // Active questions, with answers so embeds can grade them
+}
+
+// PublicQuizQuestion is a quiz question of a shared concept
+type PublicQuizQuestion struct {
+	Question      string `json:"question"`
+	OptionA       string `json:"option_a"`
+	OptionB       string `json:"option_b"`
+	OptionC       string `json:"option_c"`
+	OptionD       string `json:"option_d"`
+	CorrectAnswer string `json:"correct_answer"` // A, B, C, or D
+	Explanation   string `json:"explanation"`
+}
+
+// PublicContent is a shared generated content piece
+type PublicContent struct {
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	Platform    string     `json:"platform"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
@@ -4,13 +4,19 @@ import "time"
 
 // SourceContent represents the original video/article/PDF
 type SourceContent struct {
-	ID          int       `json:"id" db:"id"`
-	Type        string    `json:"type" db:"type"` // youtube, pdf, article
-	URL         string    `json:"url" db:"url"`
-	Title       string    `json:"title" db:"title"`
-	Transcript  string    `json:"transcript" db:"transcript"`
-	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID                    int                `json:"id" db:"id"`
+	Type                  string             `json:"type" db:"type"` // youtube, pdf, article, meeting, recording, podcast, import
+	URL                   string             `json:"url" db:"url"`
+	Title                 string             `json:"title" db:"title"`
+	Transcript            string             `json:"transcript" db:"transcript"`
+	Language              *string            `json:"language,omitempty" db:"language"`                       // Detected transcript language; unknown for older sources
+	OriginalTranscript    *string            `json:"original_transcript,omitempty" db:"original_transcript"` // Set once the transcript has been corrected
+	TranscriptCorrectedAt *time.Time         `json:"transcript_corrected_at,omitempty" db:"transcript_corrected_at"`
+	Profile               *string            `json:"profile,omitempty" db:"profile"`   // Processing profile the source was submitted with
+	Chapters              TranscriptChapters `json:"chapters,omitempty" db:"chapters"` // Detected by the transcription provider, when it does
+	ProcessedAt           time.Time          `json:"processed_at" db:"processed_at"`
+	ArchivedAt            *time.Time         `json:"archived_at,omitempty" db:"archived_at"`
+	CreatedAt             time.Time          `json:"created_at" db:"created_at"`
 }
 
 // CreateSourceContentRequest represents the request body for ingesting content
@@ -19,4 +25,82 @@ type CreateSourceContentRequest struct {
 	URL        string `json:"url" binding:"required"`
 	Title      string `json:"title"`
 	Transcript string `json:"transcript"`
+	Language   string `json:"language"`                  // Transcript language, when known
+	PipelineID *int   `json:"pipeline_id"`               // Pipeline definition to run; the default when omitted
+	Profile    string `json:"profile" binding:"max=100"` // Processing profile to apply, by name
+
+	QuizQuestions *QuestionCount `json:"quiz_questions"`                                // Overrides the pipeline's questions per concept
+	TargetGrade   *float64       `json:"target_grade" binding:"omitempty,min=1,max=18"` // Overrides the pipeline's content grade level
+	Scrub         *bool          `json:"scrub"`                                         // Overrides SCRUB_TRANSCRIPTS for this source
+
+	// Caption languages to try in order, "auto" being the spoken language
+	// (default ["auto", "en"]). With Translate, captions found in another
+	// language are translated into the first listed.
+	Languages []string `json:"languages" binding:"max=10,dive,required,max=20"`
+	Translate bool     `json:"translate"`
+
+	// Set from caption tracks and transcription providers that return them,
+	// not by clients
+	Words    TranscriptWords    `json:"-"`
+	Chapters TranscriptChapters `json:"-"`
+	Segments []TimedSegment     `json:"-"`
+
+	OwnerID *int `json:"-"` // The signed-in user, set by the handler; nil for shared sources
+}
+
+// CaptureQuery holds the query of a bookmarklet capture
+type CaptureQuery struct {
+	URL string `form:"url" binding:"required"`
+}
+
+// SourceContentListQuery filters and sorts GET /api/source-content
+type SourceContentListQuery struct {
+	Type            string     `form:"type" binding:"omitempty,oneof=youtube pdf article meeting recording podcast import"`
+	From            *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"` // Created from this date (UTC)
+	To              *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`   // Created through this date (UTC)
+	IncludeArchived bool       `form:"include_archived"`
+	Sort            string     `form:"sort" binding:"omitempty,oneof=created_at processed_at"` // created_at when omitted
+	Order           string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	OwnerID         *int       `form:"-"` // Limits the list to this user's and shared sources, when set
+}
+
+// TranscriptReplacement replaces every occurrence of Find in a transcript
+type TranscriptReplacement struct {
+	Find    string `json:"find" binding:"required"`
+	Replace string `json:"replace"`
+}
+
+// CorrectTranscriptRequest represents the request body for correcting a transcript.
+// Either a full corrected transcript or a list of replacements is applied.
+type CorrectTranscriptRequest struct {
+	Transcript   *string                 `json:"transcript"`
+	Replacements []TranscriptReplacement `json:"replacements" binding:"omitempty,dive"`
+	Reextract    bool                    `json:"reextract"` // Re-run concept extraction against the corrected text
+}
+
+// TranscriptCorrectionResult reports what a transcript correction changed
+type TranscriptCorrectionResult struct {
+	SourceContent      *SourceContent `json:"source_content"`
+	Changed            bool           `json:"changed"`
+	Replacements       int            `json:"replacements"`        // Occurrences replaced across all replacements
+	ReextractSuggested bool           `json:"reextract_suggested"` // The transcript changed but concepts were not re-extracted
+	Reextracted        bool           `json:"reextracted"`
+	ArchivedConcepts   int            `json:"archived_concepts,omitempty"`
+	Concepts           []Concept      `json:"concepts,omitempty"`
+	Quizzes            []QuizQuestion `json:"quizzes,omitempty"`
+	Glossary           []GlossaryTerm `json:"glossary,omitempty"`
+	ActionItems        []ActionItem   `json:"action_items,omitempty"`
+	Mentions           []Mention      `json:"mentions,omitempty"`
+	Warnings           []StageWarning `json:"warnings,omitempty"` // Failed parts of the re-extraction
+}
+
+// SourceContentSummary is source content metadata without the transcript
+type SourceContentSummary struct {
+	ID          int        `json:"id" db:"id"`
+	Type        string     `json:"type" db:"type"`
+	URL         string     `json:"url" db:"url"`
+	Title       string     `json:"title" db:"title"`
+	ProcessedAt time.Time  `json:"processed_at" db:"processed_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+// QuestionStats summarizes how a quiz question performs, to help spot bad questions
+type QuestionStats struct {
+	QuestionID        int            `json:"question_id"`
+	Attempts          AttemptSummary `json:"attempts"`
+	AvgTimeToAnswerMs *float64       `json:"avg_time_to_answer_ms,omitempty"`
+	OptionCounts      map[string]int `json:"option_counts"` // Canonical letter -> times selected
+	// Discrimination is the point-biserial correlation between answering this
+	// question correctly and the score on the rest of the session. Low or
+	// negative values flag questions that don't separate learners who know the
+	// material from those who don't. Omitted until enough sessions exist.
+	Discrimination        *float64 `json:"discrimination,omitempty"`
+	DiscriminationSamples int      `json:"discrimination_samples"`
+}
+
+// ConceptStats aggregates question stats across a concept
+type ConceptStats struct {
+	ConceptID         int             `json:"concept_id"`
+	Attempts          AttemptSummary  `json:"attempts"`
+	AvgTimeToAnswerMs *float64        `json:"avg_time_to_answer_ms,omitempty"`
+	Questions         []QuestionStats `json:"questions"`
+}
+
+// DiscriminationSample is one session attempt: whether the question was answered
+// correctly and the accuracy on the session's other questions
+type DiscriminationSample struct {
+	Correct   bool
+	RestScore float64
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// TranscriptSegment is a passage of a source transcript. Offsets are byte
+// positions in the full transcript. Timing is only set when the source has
+// timed segments; URL then jumps to that point in the video.
+type TranscriptSegment struct {
+	Index        int      `json:"index"`
+	Text         string   `json:"text"`
+	StartOffset  int      `json:"start_offset"`
+	EndOffset    int      `json:"end_offset"`
+	StartSeconds *float64 `json:"start_seconds,omitempty"`
+	EndSeconds   *float64 `json:"end_seconds,omitempty"`
+	URL          *string  `json:"url,omitempty"`
+}
+
+// TextRange is a byte range within a segment's text
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// TranscriptHighlight marks a segment that supports a concept
+type TranscriptHighlight struct {
+	ConceptID    int         `json:"concept_id"`
+	ConceptTitle string      `json:"concept_title"`
+	SegmentIndex int         `json:"segment_index"`
+	Score        float64     `json:"score"`   // 0-1 share of the concept's key terms found in the segment
+	Matches      []TextRange `json:"matches"` // Key term occurrences to highlight
+}
+
+// TranscriptView is a segmented transcript with concept highlights
+type TranscriptView struct {
+	SourceContentID int                   `json:"source_content_id"`
+	Title           string                `json:"title"`
+	URL             string                `json:"url"`
+	Segments        []TranscriptSegment   `json:"segments"`
+	Highlights      []TranscriptHighlight `json:"highlights"`
+	Chapters        TranscriptChapters    `json:"chapters,omitempty"`
+	TimedSegments   []TimedSegment        `json:"timed_segments,omitempty"` // The caption cues, for YouTube sources
+}
+
+// TimedSegment is a timed cue of the caption track a source's transcript was
+// built from
+type TimedSegment struct {
+	Start float64 `json:"start"` // Seconds
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// TranscriptWord is one word of a transcript, timed by the transcription
+// provider that produced it
+type TranscriptWord struct {
+	Text    string  `json:"text"`
+	Start   float64 `json:"start"` // Seconds
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker,omitempty"` // Set when the provider labeled speakers
+}
+
+// TranscriptWords is a custom type for handling PostgreSQL JSONB word arrays
+type TranscriptWords []TranscriptWord
+
+// Scan implements the sql.Scanner interface
+func (w *TranscriptWords) Scan(value interface{}) error {
+	if value == nil {
+		*w = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan TranscriptWords")
+	}
+
+	return json.Unmarshal(bytes, w)
+}
+
+// Value implements the driver.Valuer interface. Untimed transcripts store NULL.
+func (w TranscriptWords) Value() (driver.Value, error) {
+	if len(w) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(w)
+}
+
+// TranscriptChapter is a section of a transcript that its transcription
+// provider detected, with a headline and summary
+type TranscriptChapter struct {
+	Headline     string  `json:"headline"`
+	Summary      string  `json:"summary"`
+	StartSeconds float64 `json:"start_seconds"`
+	EndSeconds   float64 `json:"end_seconds"`
+}
+
+// TranscriptChapters is a custom type for handling PostgreSQL JSONB chapter arrays
+type TranscriptChapters []TranscriptChapter
+
+// Scan implements the sql.Scanner interface
+func (c *TranscriptChapters) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan TranscriptChapters")
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements the driver.Valuer interface. Sources without chapters store NULL.
+func (c TranscriptChapters) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
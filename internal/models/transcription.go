@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Pending transcription statuses
+const (
+	TranscriptionPending    = "pending"    // Waiting for the provider's callback
+	TranscriptionProcessing = "processing" // The callback arrived and the pipeline is running
+	TranscriptionCompleted  = "completed"
+	TranscriptionFailed     = "failed" // The provider's job failed, or its transcript couldn't be processed
+)
+
+// PendingTranscription is a source being transcribed out-of-band by an
+// external service, which becomes a source content once the service calls
+// back with the finished transcript
+type PendingTranscription struct {
+	ID              int          `json:"id" db:"id"`
+	Token           string       `json:"-" db:"token"` // Secret that authenticates the callback
+	Provider        string       `json:"provider" db:"provider"`
+	Title           string       `json:"title" db:"title"`
+	Credential      string       `json:"credential,omitempty" db:"credential"` // Stored credential the transcript is fetched with
+	Spec            PipelineSpec `json:"spec" db:"spec"`
+	Scrub           *bool        `json:"scrub,omitempty" db:"scrub"`
+	Status          string       `json:"status" db:"status"`
+	JobID           *string      `json:"job_id,omitempty" db:"job_id"` // The provider's job, once the callback names it
+	Error           *string      `json:"error,omitempty" db:"error"`
+	SourceContentID *int         `json:"source_content_id,omitempty" db:"source_content_id"`
+	OwnerID         *int         `json:"-" db:"owner_id"`     // Who the saved source will belong to
+	CallbackURL     string       `json:"callback_url" db:"-"` // Webhook URL to give the provider, filled in by the handler
+	CreatedAt       time.Time    `json:"created_at" db:"created_at"`
+	CompletedAt     *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// CreateTranscriptionRequest represents the request body for registering a
+// source that an external service will transcribe
+type CreateTranscriptionRequest struct {
+	Provider   string `json:"provider" binding:"required,oneof=assemblyai deepgram rev"`
+	Title      string `json:"title" binding:"required,max=500"`
+	Credential string `json:"credential"`                // Stored assemblyai or rev credential; "default" when omitted
+	PipelineID *int   `json:"pipeline_id"`               // Pipeline definition to run; the default when omitted
+	Profile    string `json:"profile" binding:"max=100"` // Processing profile to apply, by name
+
+	QuizQuestions *QuestionCount `json:"quiz_questions"`                                // Overrides the pipeline's questions per concept
+	TargetGrade   *float64       `json:"target_grade" binding:"omitempty,min=1,max=18"` // Overrides the pipeline's content grade level
+	Scrub         *bool          `json:"scrub"`                                         // Overrides SCRUB_TRANSCRIPTS for this source
+	OwnerID       *int           `json:"-"`                                             // The signed-in user, set by the handler
+}
+
+// TranscriptionsQuery filters the pending transcriptions list
+type TranscriptionsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending processing completed failed"`
+}
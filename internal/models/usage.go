@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Metered usage
+const (
+	UsageSourcesProcessed = "sources_processed" // New sources saved, of any type
+	UsageClaudeTokens     = "claude_tokens"     // Input and output tokens of Claude responses
+	UsagePostsPublished   = "posts_published"   // Generated content published for the first time
+)
+
+// UsageEvent is one metered use, kept until the billing sink takes it
+type UsageEvent struct {
+	ID              int64      `json:"id" db:"id"`
+	Tenant          string     `json:"tenant" db:"tenant"`
+	Metric          string     `json:"metric" db:"metric"`
+	Quantity        int64      `json:"quantity" db:"quantity"`
+	SourceContentID *int       `json:"source_content_id,omitempty" db:"source_content_id"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	ReportedAt      *time.Time `json:"reported_at,omitempty" db:"reported_at"` // When the billing sink took it
+}
+
+// UsageQuery selects the usage GET /api/usage totals
+type UsageQuery struct {
+	From     *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`   // From this date (UTC); 30 days before To when omitted
+	To       *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`     // Through this date (UTC); today when omitted
+	Interval string     `form:"interval" binding:"omitempty,oneof=day month"` // Period totals are grouped by; day when omitted
+	Tenant   string     `form:"tenant" binding:"max=100"`                     // Only this tenant's usage
+}
+
+// UsageTotal is a tenant's usage of one metric in one period
+type UsageTotal struct {
+	Tenant   string `json:"tenant" db:"tenant"`
+	Metric   string `json:"metric" db:"metric"`
+	Period   string `json:"period" db:"period"` // YYYY-MM-DD, or YYYY-MM by month
+	Quantity int64  `json:"quantity" db:"quantity"`
+}
+
+// UsageReport totals metered usage per tenant, metric, and period
+type UsageReport struct {
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Interval   string       `json:"interval"`
+	Totals     []UsageTotal `json:"totals"`
+	Unreported int          `json:"unreported"` // Events the billing sink hasn't taken yet
+	Sink       string       `json:"sink,omitempty"`
+}
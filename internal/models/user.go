@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// User roles
+const (
+	RoleViewer      = "viewer"
+	RoleContributor = "contributor"
+	RoleEditor      = "editor"
+	RoleAdmin       = "admin"
+)
+
+// RolePermissions are the permissions each role grants
+var RolePermissions = map[string][]string{
+	RoleViewer:      {ScopeRead},
+	RoleContributor: {ScopeRead, ScopeIngest},
+	RoleEditor:      {ScopeRead, ScopeIngest, ScopeWrite, ScopePublish},
+	RoleAdmin:       {ScopeAdmin},
+}
+
+// User is an account that signs in through one or more identity providers
+type User struct {
+	ID          int            `json:"id" db:"id"`
+	Email       *string        `json:"email,omitempty" db:"email"`
+	Name        string         `json:"name" db:"name"`
+	Role        string         `json:"role" db:"role"`
+	Timezone    *string        `json:"timezone,omitempty" db:"timezone"` // IANA zone; unset uses the server's TIMEZONE
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time     `json:"last_login_at,omitempty" db:"last_login_at"`
+	Identities  []UserIdentity `json:"identities,omitempty" db:"-"`
+}
+
+// UserIdentity links a user to an account at an identity provider
+type UserIdentity struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"` // google, github, or oidc
+	Subject   string    `json:"subject" db:"subject"`
+	Email     *string   `json:"email,omitempty" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// UpdateUserRoleRequest represents the request body for changing a user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer contributor editor admin"`
+}
+
+// InviteUserRequest represents the request body for inviting a user
+type InviteUserRequest struct {
+	Email string `json:"email" binding:"required,email,max=320"`
+	Name  string `json:"name" binding:"max=255"`
+	Role  string `json:"role" binding:"required,oneof=viewer contributor editor admin"`
+}
+
+// UpdateUserSettingsRequest represents the request body for PATCH /api/me
+type UpdateUserSettingsRequest struct {
+	Timezone *string `json:"timezone"` // IANA zone, e.g. America/New_York
+}
+
+// Me describes the caller: who they are and what they may do
+type Me struct {
+	Kind        string    `json:"kind"` // user, token, or anonymous (authentication not yet enabled)
+	User        *User     `json:"user,omitempty"`
+	Token       *APIToken `json:"token,omitempty"`
+	Permissions []string  `json:"permissions"`
+	Timezone    string    `json:"timezone"` // Zone used for "due today" and other local times
+}
+
+// LoginResult is returned after a successful sign-in
+type LoginResult struct {
+	Token     string    `json:"token"` // Session JWT, also set as a cookie
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+	Linked    bool      `json:"linked"` // The identity was newly linked to an existing user
+}
@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNotConfigured is returned when SECRETS_MASTER_KEY is not set
+	ErrNotConfigured = errors.New("SECRETS_MASTER_KEY environment variable is not set")
+
+	// ErrUnknownKey is returned when a ciphertext was sealed under a key that isn't loaded
+	ErrUnknownKey = errors.New("ciphertext was encrypted with an unknown master key")
+)
+
+// Keyring seals secrets with AES-256-GCM under the current master key and
+// opens them under the current or any previous key, so keys can be rotated
+type Keyring struct {
+	currentID string
+	keys      map[string]cipher.AEAD
+}
+
+// Default is the keyring loaded by Init; nil when no master key is configured
+var Default *Keyring
+
+// Init loads Default from SECRETS_MASTER_KEY and the comma-separated
+// SECRETS_PREVIOUS_KEYS (base64, 32 bytes each). A KMS can supply them
+// through the environment. Without a master key, Default stays nil and
+// credential storage is unavailable.
+func Init() error {
+	master := os.Getenv("SECRETS_MASTER_KEY")
+	if master == "" {
+		return nil
+	}
+
+	var previous []string
+	for _, key := range strings.Split(os.Getenv("SECRETS_PREVIOUS_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			previous = append(previous, key)
+		}
+	}
+
+	keyring, err := NewKeyring(master, previous...)
+	if err != nil {
+		return err
+	}
+
+	Default = keyring
+	return nil
+}
+
+// NewKeyring creates a keyring that seals with current and also opens with previous
+func NewKeyring(current string, previous ...string) (*Keyring, error) {
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+
+	for i, encoded := range append([]string{current}, previous...) {
+		id, aead, err := parseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			k.currentID = id
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+// CurrentKeyID identifies the key new secrets are sealed under
+func (k *Keyring) CurrentKeyID() string {
+	return k.currentID
+}
+
+// Seal encrypts plaintext under the current key, binding it to aad (which
+// must be given again to open it). The nonce is prepended to the ciphertext.
+func (k *Keyring) Seal(plaintext, aad string) ([]byte, string, error) {
+	aead := k.keys[k.currentID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad)), k.currentID, nil
+}
+
+// Open decrypts a ciphertext sealed under keyID
+func (k *Keyring) Open(ciphertext []byte, keyID, aad string) (string, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return "", fmt.Errorf("ciphertext is too short")
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// parseKey decodes a base64 AES-256 key, identifying it by a hash prefix
+func parseKey(encoded string) (string, cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 32 {
+		return "", nil, fmt.Errorf("master keys must be 32 bytes, base64-encoded")
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:4]), aead, nil
+}
@@ -0,0 +1,116 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealedTextPrefix marks a text column value sealed by SealText:
+// enc:v2:<key ID>:<base64 nonce and ciphertext>
+const sealedTextPrefix = "enc:v2:"
+
+// legacySealedTextPrefix marks a value sealed before values were bound to
+// their column and row, bound to its table only. They're still read, and
+// rotating encryption rewrites them.
+const legacySealedTextPrefix = "enc:v1:"
+
+// TextBinding is where a sealed text value is stored. A value only opens
+// where it was sealed, so it can't be copied to another column or row.
+type TextBinding struct {
+	Table  string
+	Column string
+	Row    int
+}
+
+// aad is the additional data a value stored at b is sealed with
+func (b TextBinding) aad() string {
+	return fmt.Sprintf("%s.%s:%d", b.Table, b.Column, b.Row)
+}
+
+// encryptText is whether SealText encrypts, set by InitTextEncryption
+var encryptText bool
+
+// InitTextEncryption turns on encryption at rest of transcripts and generated
+// content when ENCRYPT_AT_REST is true. It needs the master key, so call it
+// after Init. Stored text is readable whether or not it's on.
+func InitTextEncryption() error {
+	if os.Getenv("ENCRYPT_AT_REST") != "true" {
+		return nil
+	}
+	if Default == nil {
+		return fmt.Errorf("ENCRYPT_AT_REST needs a master key: %w", ErrNotConfigured)
+	}
+
+	encryptText = true
+	return nil
+}
+
+// TextEncryption reports whether new text is encrypted at rest
+func TextEncryption() bool {
+	return encryptText
+}
+
+// SealText encrypts a text column value under the current master key, bound
+// to where it's stored. Returns it unchanged when encryption at rest is off.
+func SealText(plaintext string, at TextBinding) (string, error) {
+	if !encryptText || plaintext == "" {
+		return plaintext, nil
+	}
+
+	ciphertext, keyID, err := Default.Seal(plaintext, at.aad())
+	if err != nil {
+		return "", err
+	}
+
+	return sealedTextPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// OpenText decrypts a text column value sealed by SealText for where it's
+// stored. Plaintext values are returned unchanged.
+func OpenText(stored string, at TextBinding) (string, error) {
+	keyID, encoded, legacy, ok := parseSealedText(stored)
+	if !ok {
+		return stored, nil
+	}
+	if Default == nil {
+		return "", ErrNotConfigured
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode sealed text: %w", err)
+	}
+	aad := at.aad()
+	if legacy {
+		aad = at.Table
+	}
+	return Default.Open(ciphertext, keyID, aad)
+}
+
+// TextKeyID returns the key a stored text value is sealed under, and whether it's sealed
+func TextKeyID(stored string) (string, bool) {
+	keyID, _, _, ok := parseSealedText(stored)
+	return keyID, ok
+}
+
+// IsSealedText reports whether a stored text value is sealed, in either format
+func IsSealedText(stored string) bool {
+	_, _, _, ok := parseSealedText(stored)
+	return ok
+}
+
+// parseSealedText splits a sealed text value into its key ID and ciphertext,
+// and says whether it's in the legacy format
+func parseSealedText(stored string) (keyID, encoded string, legacy, ok bool) {
+	rest, ok := strings.CutPrefix(stored, sealedTextPrefix)
+	if !ok {
+		if rest, ok = strings.CutPrefix(stored, legacySealedTextPrefix); !ok {
+			return "", "", false, false
+		}
+		legacy = true
+	}
+	keyID, encoded, ok = strings.Cut(rest, ":")
+	return keyID, encoded, legacy, ok
+}
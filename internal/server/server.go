@@ -0,0 +1,397 @@
+// Package server runs the HTTP server: plain HTTP, or HTTPS with HTTP/2 from
+// certificate files or certificates obtained from an ACME CA such as Let's
+// Encrypt, so small deployments don't need a reverse proxy for HTTPS. It
+// listens on a TCP port, a Unix domain socket, or a socket passed in by
+// systemd socket activation.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultAutocertCache is where ACME certificates are cached when
+// TLS_AUTOCERT_CACHE is unset
+const DefaultAutocertCache = "autocert-cache"
+
+// DefaultUnixSocketMode is the permission a Unix socket is given when
+// UNIX_SOCKET_MODE is unset: the owner and its group, such as a reverse
+// proxy's, can connect
+const DefaultUnixSocketMode fs.FileMode = 0660
+
+// shutdownTimeout bounds how long in-flight requests and background work are
+// given to finish after SIGTERM or SIGINT
+const shutdownTimeout = 30 * time.Second
+
+// Timeouts bounding how long a connection may hold the server up: sending a
+// request's headers, reading the whole request, uploads included, and
+// idling between keep-alive requests. There's no write timeout, so
+// streamed responses can run as long as they need.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 10 * time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+// systemdListenFD is the first file descriptor systemd passes to a
+// socket-activated service (SD_LISTEN_FDS_START)
+const systemdListenFD = 3
+
+// Config is how the server listens
+type Config struct {
+	Port string
+
+	// Listen on this Unix domain socket instead of PORT; empty for none
+	UnixSocket     string
+	UnixSocketMode fs.FileMode
+
+	// Serve the socket systemd passed in (LISTEN_FDS) instead of opening one
+	SocketActivated bool
+
+	// TLS from certificate files
+	CertFile string
+	KeyFile  string
+
+	// TLS from an ACME CA, for these domains only
+	AutocertDomains []string
+	AutocertCache   string // Directory certificates and the account key are kept in
+	AutocertEmail   string // Contact for expiry and problem notices (optional)
+
+	// Port for a plain HTTP listener that redirects to HTTPS and answers ACME
+	// HTTP-01 challenges; empty for none
+	RedirectPort string
+
+	// Serve unencrypted HTTP/2 (h2c) alongside HTTP/1.1 when TLS is off, for
+	// proxies that speak it to the backend
+	CleartextHTTP2 bool
+}
+
+// LoadConfig reads the listener settings from the environment. Certificate
+// files and ACME are alternatives; setting both is an error.
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		Port:           os.Getenv("PORT"),
+		CertFile:       os.Getenv("TLS_CERT_FILE"),
+		KeyFile:        os.Getenv("TLS_KEY_FILE"),
+		AutocertCache:  os.Getenv("TLS_AUTOCERT_CACHE"),
+		AutocertEmail:  os.Getenv("TLS_AUTOCERT_EMAIL"),
+		RedirectPort:   os.Getenv("HTTP_REDIRECT_PORT"),
+		UnixSocket:     os.Getenv("UNIX_SOCKET"),
+		UnixSocketMode: DefaultUnixSocketMode,
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	if v := os.Getenv("UNIX_SOCKET_MODE"); v != "" {
+		mode, err := strconv.ParseUint(v, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, fmt.Errorf("invalid UNIX_SOCKET_MODE %q: want an octal permission such as 0660", v)
+		}
+		cfg.UnixSocketMode = fs.FileMode(mode)
+	}
+
+	activated, err := socketActivated()
+	if err != nil {
+		return nil, err
+	}
+	cfg.SocketActivated = activated
+	if cfg.AutocertCache == "" {
+		cfg.AutocertCache = DefaultAutocertCache
+	}
+	for _, domain := range strings.Split(os.Getenv("TLS_AUTOCERT_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			cfg.AutocertDomains = append(cfg.AutocertDomains, domain)
+		}
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.CertFile != "" && len(cfg.AutocertDomains) > 0 {
+		return nil, errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS are alternatives; set one")
+	}
+	if cfg.RedirectPort != "" && !cfg.TLS() {
+		return nil, errors.New("HTTP_REDIRECT_PORT needs TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS")
+	}
+	if cfg.RedirectPort != "" && cfg.RedirectPort == cfg.Port {
+		return nil, errors.New("HTTP_REDIRECT_PORT must differ from PORT")
+	}
+	if cfg.RedirectPort != "" && (cfg.UnixSocket != "" || cfg.SocketActivated) {
+		// Redirects point at PORT, which isn't listened on
+		return nil, errors.New("HTTP_REDIRECT_PORT needs the server to listen on PORT")
+	}
+
+	if v := os.Getenv("HTTP2_CLEARTEXT"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP2_CLEARTEXT %q: %w", v, err)
+		}
+		cfg.CleartextHTTP2 = enabled
+	}
+
+	return cfg, nil
+}
+
+// TLS reports whether the server terminates TLS itself
+func (cfg *Config) TLS() bool {
+	return cfg.CertFile != "" || len(cfg.AutocertDomains) > 0
+}
+
+// Addr describes where the server listens, for logging
+func (cfg *Config) Addr() string {
+	switch {
+	case cfg.SocketActivated:
+		return "the systemd socket"
+	case cfg.UnixSocket != "":
+		return "unix socket " + cfg.UnixSocket
+	default:
+		return "port " + cfg.Port
+	}
+}
+
+// ListenAndServe serves handler as cfg says until the listener fails, or
+// until SIGTERM or SIGINT, when stop is called to stop the server's
+// background work, in-flight requests and stop are given shutdownTimeout to
+// finish between them, and nil is returned. stop may be nil. Over TLS,
+// clients that support it are served HTTP/2.
+func ListenAndServe(cfg *Config, handler http.Handler, stop func(ctx context.Context) error) error {
+	srv := newServer(":"+cfg.Port, handler)
+	var redirect *http.Server
+	if cfg.TLS() && cfg.RedirectPort != "" {
+		redirect = newServer(":"+cfg.RedirectPort, redirectToHTTPS(cfg.Port))
+	}
+
+	ln, err := listen(cfg)
+	if err != nil {
+		return err
+	}
+	if ln.Addr().Network() == "unix" {
+		srv.Handler = trustUnixPeer(handler)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		shutdownOnSignal(srv, redirect, stop)
+	}()
+
+	err = serve(cfg, srv, redirect, ln)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-stopped // Wait for in-flight requests
+		return nil
+	}
+	return err
+}
+
+// newServer returns a server of handler on addr with the timeouts above
+func newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+}
+
+// trustUnixPeer serves handler with each request's RemoteAddr set to the
+// client address its peer passed on. A Unix socket peer has no address of its
+// own, and only a local process the socket's permissions admit, such as the
+// reverse proxy, can connect, so the peer is trusted as a proxy: the last
+// X-Forwarded-For entry, or else X-Real-IP, is the client it took the request
+// from. That entry is taken off X-Forwarded-For, so proxies before it are
+// trusted only as TRUSTED_PROXIES says. A request with neither came from a
+// local client.
+func trustUnixPeer(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		client := "127.0.0.1"
+
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		last := strings.TrimSpace(hops[len(hops)-1])
+		r.Header.Del("X-Forwarded-For")
+		if net.ParseIP(last) != nil {
+			client = last
+			if rest := strings.Join(hops[:len(hops)-1], ","); rest != "" {
+				r.Header.Set("X-Forwarded-For", rest)
+			}
+		} else if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+			client = ip
+		}
+
+		r.RemoteAddr = net.JoinHostPort(client, "0")
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// serve serves srv on ln, over TLS when cfg says so, and redirect, when not
+// nil, on its own address
+func serve(cfg *Config, srv, redirect *http.Server, ln net.Listener) error {
+	if !cfg.TLS() {
+		if cfg.CleartextHTTP2 {
+			srv.Protocols = new(http.Protocols)
+			srv.Protocols.SetHTTP1(true)
+			srv.Protocols.SetUnencryptedHTTP2(true)
+		}
+		return srv.Serve(ln)
+	}
+
+	if len(cfg.AutocertDomains) > 0 {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCache),
+			Email:      cfg.AutocertEmail,
+		}
+		srv.TLSConfig = m.TLSConfig() // Also answers TLS-ALPN-01 challenges on PORT
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		if redirect != nil {
+			redirect.Handler = m.HTTPHandler(redirect.Handler) // Answers HTTP-01 challenges
+		}
+	} else {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if redirect != nil {
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("HTTP redirect listener failed: %v", err)
+			}
+		}()
+	}
+
+	return srv.ServeTLS(ln, cfg.CertFile, cfg.KeyFile)
+}
+
+// listen opens the listener cfg names: the systemd socket, a Unix socket,
+// or PORT on every interface
+func listen(cfg *Config) (net.Listener, error) {
+	switch {
+	case cfg.SocketActivated:
+		f := os.NewFile(systemdListenFD, "systemd socket")
+		defer f.Close() // FileListener holds its own copy
+		ln, err := net.FileListener(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use the systemd socket: %w", err)
+		}
+		return ln, nil
+	case cfg.UnixSocket != "":
+		return listenUnix(cfg.UnixSocket, cfg.UnixSocketMode)
+	default:
+		return net.Listen("tcp", ":"+cfg.Port)
+	}
+}
+
+// listenUnix listens on a Unix socket at path with the given permission. A
+// socket file left by a server that didn't shut down cleanly is replaced;
+// one another server is still accepting on is an error.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("UNIX_SOCKET %s exists and isn't a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("UNIX_SOCKET %s is in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path) // The socket file is removed on Close
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set UNIX_SOCKET permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// socketActivated reports whether systemd passed this process a listening
+// socket. The variables are unset so processes the server starts don't think
+// the socket is theirs.
+func socketActivated() (bool, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	if pid == "" || fds == "" {
+		return false, nil
+	}
+	// Meant for a parent that didn't unset them
+	if pid != strconv.Itoa(os.Getpid()) {
+		return false, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if n, err := strconv.Atoi(fds); err != nil || n != 1 {
+		return false, fmt.Errorf("systemd passed LISTEN_FDS=%s; the server takes exactly one socket", fds)
+	}
+	return true, nil
+}
+
+// shutdownOnSignal waits for SIGTERM or SIGINT, then stops srv and redirect,
+// if not nil, accepting connections and waits up to shutdownTimeout for
+// their in-flight requests, and for stopBackground alongside them. Under socket activation systemd keeps
+// the socket open meanwhile, so connections made during a restart queue for
+// the next process instead of failing.
+func shutdownOnSignal(srv, redirect *http.Server, stopBackground func(ctx context.Context) error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	<-ctx.Done()
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for in-flight requests and background work...", shutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var pending sync.WaitGroup
+	if stopBackground != nil {
+		pending.Go(func() {
+			if err := stopBackground(ctx); err != nil {
+				log.Printf("Background work did not stop cleanly: %v", err)
+			}
+		})
+	}
+	if redirect != nil {
+		pending.Go(func() {
+			if err := redirect.Shutdown(ctx); err != nil {
+				log.Printf("HTTP redirect listener shutdown did not finish cleanly: %v", err)
+			}
+		})
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown did not finish cleanly: %v", err)
+	}
+	pending.Wait()
+}
+
+// redirectToHTTPS redirects requests to the same URL over HTTPS on tlsPort
+func redirectToHTTPS(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/claude"
+)
+
+// FeatureEnabled reports whether a feature flag is on
+func FeatureEnabled(feature string) bool {
+	return CurrentSettings().Features[feature]
+}
+
+// GetFeatures returns the feature flags in effect and the ones overridden
+func GetFeatures() (*models.FeaturesResponse, error) {
+	overrides, updatedAt, err := db.GetSettingsOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	return featuresResponse(CurrentSettings(), overrides, updatedAt), nil
+}
+
+// UpdateFeatures resets the feature flags req names, sets the ones it
+// gives, and applies the result immediately
+func UpdateFeatures(req models.UpdateFeaturesRequest) (*models.FeaturesResponse, error) {
+	overrides, _, err := db.GetSettingsOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, feature := range req.Reset {
+		delete(overrides.Features, feature)
+	}
+	for feature, enabled := range req.Features {
+		if overrides.Features == nil {
+			overrides.Features = map[string]bool{}
+		}
+		overrides.Features[feature] = enabled
+	}
+
+	settings := applyOverrides(envSettings(), overrides)
+	updatedAt, err := db.SaveSettingsOverrides(overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := activateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return featuresResponse(settings, overrides, updatedAt), nil
+}
+
+// featuresResponse reports the feature flags of settings and overrides
+func featuresResponse(settings models.WorkspaceSettings, overrides models.SettingsOverrides, updatedAt *time.Time) *models.FeaturesResponse {
+	response := &models.FeaturesResponse{
+		Features:  settings.Features,
+		Overrides: overrides.Features,
+		UpdatedAt: updatedAt,
+	}
+	if response.Overrides == nil {
+		response.Overrides = map[string]bool{}
+	}
+	return response
+}
+
+// GetTenants lists the tenants usage has been metered for, and this
+// deployment's tenant with its users and sources even before it has any
+func GetTenants() ([]models.Tenant, error) {
+	tenants, err := db.GetUsageTenants()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := db.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := db.CountSourceContents()
+	if err != nil {
+		return nil, err
+	}
+	userCount := len(users)
+
+	name := usageTenant()
+	i := slices.IndexFunc(tenants, func(t models.Tenant) bool { return t.Name == name })
+	if i < 0 {
+		tenants = append([]models.Tenant{{Name: name}}, tenants...)
+		i = 0
+	}
+	tenants[i].Current = true
+	tenants[i].Users = &userCount
+	tenants[i].Sources = &sources
+
+	return tenants, nil
+}
+
+// GetJobQueue returns a page of jobs, newest first and with each step's
+// progress, and how many jobs there are of each status
+func GetJobQueue(query models.JobQuery, page models.Page) ([]models.Job, *models.Cursor, map[string]int, error) {
+	jobs, next, err := db.GetJobs(query.Status, page)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i := range jobs {
+		var run *models.PipelineRun
+		if jobs[i].SourceContentID != nil {
+			if run, err = db.GetPipelineRun(*jobs[i].SourceContentID); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		jobs[i].Steps = jobSteps(&jobs[i], run)
+	}
+
+	counts, err := db.CountJobsByStatus()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return jobs, next, counts, nil
+}
+
+// LLMSpendReport totals Claude requests and tokens per model and day or month,
+// from query.From through query.To (the last 30 days by default), estimating
+// their cost at list prices
+func LLMSpendReport(query models.LLMSpendQuery) (*models.LLMSpendReport, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if query.To != nil {
+		to = *query.To
+	}
+	from := to.Add(-usageReportWindow)
+	if query.From != nil {
+		from = *query.From
+	}
+	if from.After(to) {
+		return nil, ErrInvalidUsageRange
+	}
+
+	interval := query.Interval
+	if interval == "" {
+		interval = "day"
+	}
+
+	spend, err := db.GetLLMUsage(from, to.AddDate(0, 0, 1), interval == "month")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.LLMSpendReport{
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Interval: interval,
+		Spend:    spend,
+	}
+	for i := range spend {
+		price, ok := claude.PriceOf(spend[i].Model)
+		if !ok {
+			if !slices.Contains(report.UnpricedModels, spend[i].Model) {
+				report.UnpricedModels = append(report.UnpricedModels, spend[i].Model)
+			}
+			continue
+		}
+		cost := price.Cost(spend[i].InputTokens, spend[i].OutputTokens)
+		spend[i].CostUSD = &cost
+		report.TotalCostUSD += cost
+	}
+
+	return report, nil
+}
+
+// ReprocessSource runs a source's pipeline again from scratch, whatever state
+// its last run was left in: its concepts are archived, and concepts and every
+// stage's artifacts are regenerated from its transcript with the pipeline it
+// resolves to now. If extraction fails, the run is left incomplete to resume.
+func (s *SourceContentService) ReprocessSource(ctx context.Context, sourceID int) (*ProcessResult, error) {
+	sourceContent, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := resolveSourceSpec(sourceContent)
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := db.ArchiveConceptsBySourceContentID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.RestartPipelineRun(sourceID, spec); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Reprocessing source content ID: %d (archived %d concepts)", sourceID, archived)
+	return s.resumePipeline(ctx, sourceContent, &models.PipelineRun{SourceContentID: sourceID, Spec: spec, Status: models.PipelineRunRunning})
+}
+
+// PurgeSourceContent deletes a source, the generated content drawn only from
+// it, and its audio summary. Returns how many pieces of content were deleted.
+func PurgeSourceContent(ctx context.Context, sourceID int) (int, error) {
+	deletedContent, err := db.PurgeSourceContent(sourceID)
+	if err != nil {
+		return 0, err
+	}
+	RemoveAudioSummaryFiles(ctx, sourceID)
+	return deletedContent, nil
+}
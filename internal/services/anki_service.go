@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ankiRatings maps Anki's answer buttons (ease 1-4) to lattice ratings
+var ankiRatings = map[int]string{
+	1: models.RatingAgain,
+	2: models.RatingHard,
+	3: models.RatingGood,
+	4: models.RatingEasy,
+}
+
+// AnkiService syncs cards and reviews with Anki through an AnkiConnect bridge.
+// The bridge pulls pending notes, adds them with AnkiConnect addNotes, links the
+// returned note IDs back, and pushes reviews read with cardReviews.
+type AnkiService struct {
+	deckName string
+}
+
+// NewAnkiService creates a new Anki sync service
+func NewAnkiService() *AnkiService {
+	deckName := os.Getenv("ANKI_DECK")
+	if deckName == "" {
+		deckName = "Lattice"
+	}
+
+	return &AnkiService{
+		deckName: deckName,
+	}
+}
+
+// GetSyncState reports where a bridge syncing the concepts owner can see
+// should resume
+func (s *AnkiService) GetSyncState(ctx context.Context, owner *int) (*models.AnkiSyncState, error) {
+	lastReviewID, linked, err := db.GetAnkiSyncCounts(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.PendingNotes(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AnkiSyncState{
+		LastReviewID: lastReviewID,
+		PendingNotes: len(pending),
+		LinkedNotes:  linked,
+	}, nil
+}
+
+// PendingNotes returns the questions and flashcards owner can see not yet in
+// Anki as Basic notes, in a subdeck per source
+func (s *AnkiService) PendingNotes(ctx context.Context, owner *int) ([]models.AnkiNote, error) {
+	questions, err := db.GetUnlinkedAnkiQuestions(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	flashcards, err := db.GetUnlinkedAnkiFlashcards(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	concepts := map[int]*models.Concept{}
+	decks := map[int]string{}
+	lookup := func(conceptID int) (*models.Concept, string, error) {
+		if concept, ok := concepts[conceptID]; ok {
+			return concept, decks[conceptID], nil
+		}
+
+		concept, err := db.GetConceptByID(conceptID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		deck := s.deckName
+		if concept.SourceContentID != nil {
+			if source, err := db.GetSourceContentSummaryByID(*concept.SourceContentID); err == nil {
+				// "::" separates subdecks in Anki
+				deck += "::" + strings.ReplaceAll(source.Title, "::", ":")
+			}
+		}
+
+		concepts[conceptID] = concept
+		decks[conceptID] = deck
+		return concept, deck, nil
+	}
+
+	notes := make([]models.AnkiNote, 0, len(questions)+len(flashcards))
+	for _, q := range questions {
+		concept, deck, err := lookup(q.ConceptID)
+		if err != nil {
+			return nil, err
+		}
+
+		front := fmt.Sprintf("%s<ol type=\"A\"><li>%s</li><li>%s</li><li>%s</li><li>%s</li></ol>",
+			html.EscapeString(q.Question),
+			html.EscapeString(q.OptionA), html.EscapeString(q.OptionB),
+			html.EscapeString(q.OptionC), html.EscapeString(q.OptionD))
+		back := fmt.Sprintf("<b>%s</b>: %s<br><br>%s",
+			q.CorrectAnswer, html.EscapeString(optionText(q, q.CorrectAnswer)), html.EscapeString(q.Explanation))
+
+		notes = append(notes, ankiNote(fmt.Sprintf("question:%d", q.ID), deck, front, back, concept))
+	}
+
+	for _, f := range flashcards {
+		concept, deck, err := lookup(f.ConceptID)
+		if err != nil {
+			return nil, err
+		}
+
+		back := strings.ReplaceAll(html.EscapeString(f.Back), "\n", "<br>")
+		notes = append(notes, ankiNote(fmt.Sprintf("flashcard:%d", f.ID), deck, html.EscapeString(f.Front), back, concept))
+	}
+
+	return notes, nil
+}
+
+// LinkNotes records the Anki note IDs created for the pending notes of items
+// owner can see
+func (s *AnkiService) LinkNotes(ctx context.Context, links []models.AnkiNoteLink, owner *int) error {
+	for i := range links {
+		kind, idStr, ok := strings.Cut(links[i].Ref, ":")
+		id, err := strconv.Atoi(idStr)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid ref %q", links[i].Ref)
+		}
+
+		switch kind {
+		case "question":
+			links[i].QuestionID = &id
+		case "flashcard":
+			links[i].FlashcardID = &id
+		default:
+			return fmt.Errorf("invalid ref %q", links[i].Ref)
+		}
+	}
+
+	return db.CreateAnkiNoteLinks(links, owner)
+}
+
+// ApplyReviews imports Anki reviews in the order they happened and reschedules
+// each linked concept as if the review had been done in lattice. Reviews that
+// were already imported, or belong to notes not linked to a concept owner can
+// see, are skipped.
+func (s *AnkiService) ApplyReviews(ctx context.Context, reviews []models.AnkiReview, owner *int) (*models.AnkiReviewSyncResult, error) {
+	sort.Slice(reviews, func(i, j int) bool {
+		return reviews[i].ReviewID < reviews[j].ReviewID
+	})
+
+	result := &models.AnkiReviewSyncResult{}
+	for _, review := range reviews {
+		conceptID, err := db.GetConceptIDForAnkiNote(review.NoteID, owner)
+		if err != nil {
+			return nil, err
+		}
+		if conceptID == nil {
+			result.Unlinked++
+			continue
+		}
+
+		created, err := db.CreateAnkiReview(review, *conceptID)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			result.Duplicate++
+			continue
+		}
+
+		progress, err := db.GetLearningProgressByConceptID(*conceptID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Revlog ids are the review time in epoch milliseconds
+		reviewedAt := time.UnixMilli(review.ReviewID)
+		if _, err := db.UpsertLearningProgress(scheduleReview(*conceptID, progress, ankiRatings[review.Ease], reviewedAt)); err != nil {
+			return nil, err
+		}
+		result.Applied++
+	}
+
+	return result, nil
+}
+
+// ankiNote builds a Basic note tagged with the concept's tags
+func ankiNote(ref, deck, front, back string, concept *models.Concept) models.AnkiNote {
+	tags := []string{"lattice"}
+	for _, tag := range concept.Tags {
+		// Anki tags can't contain spaces
+		tags = append(tags, strings.ReplaceAll(tag, " ", "_"))
+	}
+
+	return models.AnkiNote{
+		Ref:       ref,
+		DeckName:  deck,
+		ModelName: "Basic",
+		Fields: map[string]string{
+			"Front": front,
+			"Back":  back,
+		},
+		Tags: tags,
+	}
+}
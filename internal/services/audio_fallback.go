@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+	"github.com/mostlyerror/lattice/pkg/speech"
+	"github.com/mostlyerror/lattice/pkg/transcription"
+	"github.com/mostlyerror/lattice/pkg/youtube"
+)
+
+// Longest audio transcribed by default
+const (
+	defaultMaxAudioDuration    = time.Hour     // Keeps downloads within Whisper's 25 MB upload limit
+	defaultMaxProviderDuration = 4 * time.Hour // AssemblyAI and Deepgram take much larger files
+)
+
+// AudioFallback transcribes a video's audio when it has no captions, or
+// instead of its captions when skipCaptions is set, and podcast episodes
+type AudioFallback struct {
+	client       *speech.Client        // OpenAI-compatible backend
+	provider     *transcription.Client // Set instead of client for AssemblyAI and Deepgram
+	local        *speech.WhisperCPP    // Set instead of client for whisper.cpp
+	maxDuration  time.Duration         // Longer videos, and those of unknown length, aren't transcribed
+	skipCaptions bool
+}
+
+// AudioTranscript is the transcript of a video's audio, with the word
+// timings and chapters of providers that return them
+type AudioTranscript struct {
+	youtube.Transcript
+	Words    models.TranscriptWords
+	Chapters models.TranscriptChapters
+}
+
+// LoadAudioFallback configures the fallback from the speech-to-text backend
+// and STT_MAX_DURATION (defaults to 1h, or 4h for the other backends).
+// STT_PROVIDER picks the backend: openai (the default) for any
+// OpenAI-compatible API (STT_API_URL, STT_API_KEY, STT_MODEL), or assemblyai
+// or deepgram, which label speakers and time each word, with STT_API_KEY and
+// optionally STT_MODEL, or whispercpp to transcribe locally (see
+// speech.NewWhisperCPP). STT_SKIP_CAPTIONS transcribes every video's audio
+// rather than using its captions. It returns nil, nil when no backend is
+// configured.
+func LoadAudioFallback() (*AudioFallback, error) {
+	fallback := &AudioFallback{maxDuration: defaultMaxAudioDuration}
+
+	switch provider := strings.ToLower(os.Getenv("STT_PROVIDER")); provider {
+	case "", "openai":
+		client, err := speech.NewClient()
+		if errors.Is(err, speech.ErrNotConfigured) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fallback.client = client
+	case transcription.AssemblyAI, transcription.Deepgram:
+		client, err := transcription.NewClient(provider, os.Getenv("STT_API_KEY"), os.Getenv("STT_MODEL"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid STT_PROVIDER: %w", err)
+		}
+		fallback.provider = client
+		fallback.maxDuration = defaultMaxProviderDuration
+	case "whispercpp":
+		local, err := speech.NewWhisperCPP()
+		if err != nil {
+			return nil, fmt.Errorf("invalid STT_PROVIDER: %w", err)
+		}
+		fallback.local = local
+		fallback.maxDuration = defaultMaxProviderDuration
+	default:
+		return nil, fmt.Errorf("invalid STT_PROVIDER: must be openai, assemblyai, deepgram, or whispercpp")
+	}
+
+	if str := os.Getenv("STT_SKIP_CAPTIONS"); str != "" {
+		skip, err := strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STT_SKIP_CAPTIONS: must be true or false")
+		}
+		fallback.skipCaptions = skip
+	}
+
+	if str := os.Getenv("STT_MAX_DURATION"); str != "" {
+		duration, err := time.ParseDuration(str)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid STT_MAX_DURATION: must be a positive duration")
+		}
+		fallback.maxDuration = duration
+	}
+
+	return fallback, nil
+}
+
+// SkipsCaptions reports whether videos' audio is transcribed even when they have captions
+func (f *AudioFallback) SkipsCaptions() bool {
+	return f != nil && f.skipCaptions
+}
+
+// Transcribe downloads a video's audio track and transcribes it. The audio
+// is also kept in object storage, when configured, until
+// RETENTION_AUDIO_AFTER prunes it.
+func (f *AudioFallback) Transcribe(ctx context.Context, yt *youtube.Client, url string, metadata *youtube.Metadata) (*AudioTranscript, error) {
+	duration := time.Duration(metadata.Duration) * time.Second
+	if duration == 0 || duration > f.maxDuration {
+		return nil, fmt.Errorf("%w of %s for audio transcription", ErrVideoTooLong, f.maxDuration)
+	}
+
+	dir, err := os.MkdirTemp("", "lattice-audio-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.Printf("Downloading audio for transcription...")
+	path, err := yt.DownloadAudio(ctx, url, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download audio: %w", err)
+	}
+
+	f.storeAudio(ctx, url, path)
+
+	// The uploader's language, when set, spares the backend detecting it
+	language, _, _ := strings.Cut(metadata.Language, "-")
+
+	log.Printf("Transcribing %s of audio...", duration)
+	return f.transcribeFile(ctx, path, language)
+}
+
+// transcribeFile transcribes a downloaded audio file with the configured
+// backend. A non-empty language (ISO 639-1) skips the backend's detection.
+func (f *AudioFallback) transcribeFile(ctx context.Context, path, language string) (*AudioTranscript, error) {
+	if f.local != nil {
+		transcribed, err := f.local.Transcribe(ctx, path, language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+		}
+		return &AudioTranscript{Transcript: youtube.Transcript{Text: transcribed.Text, Language: transcribed.Language}}, nil
+	}
+
+	audio, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer audio.Close()
+
+	if f.provider != nil {
+		result, err := f.provider.Transcribe(ctx, filepath.Base(path), audio, language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+		}
+
+		words, chapters := transcriptTiming(result)
+		return &AudioTranscript{
+			Transcript: youtube.Transcript{Text: result.Transcript.Text(), Language: result.Transcript.Language},
+			Words:      words,
+			Chapters:   chapters,
+		}, nil
+	}
+
+	transcribed, err := f.client.Transcribe(ctx, filepath.Base(path), audio, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	return &AudioTranscript{Transcript: youtube.Transcript{
+		Text:     transcribed.Text,
+		Language: transcribed.Language,
+	}}, nil
+}
+
+// storeAudio copies downloaded audio into object storage. Failures are
+// logged, since the transcript doesn't depend on it.
+func (f *AudioFallback) storeAudio(ctx context.Context, url, path string) {
+	if storage.Store == nil {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Warning: failed to store audio: %v", err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Printf("Warning: failed to store audio: %v", err)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(url))
+	ext := filepath.Ext(path)
+	key := storage.PrefixAudio + hex.EncodeToString(sum[:8]) + ext
+	if err := storage.Store.Put(ctx, key, file, info.Size(), mime.TypeByExtension(ext)); err != nil {
+		log.Printf("Warning: failed to store audio: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+	"github.com/mostlyerror/lattice/pkg/tts"
+)
+
+const (
+	// audioSummaryURLExpiry is how long audio URLs stay valid: the longest S3
+	// allows, since podcast apps download episodes whenever they refresh
+	audioSummaryURLExpiry = 7 * 24 * time.Hour
+
+	// podcastFeedLimit is how many of the newest audio summaries the feed lists
+	podcastFeedLimit = 100
+)
+
+// GenerateAudioSummary reads a source's summary and concepts aloud with the
+// configured text-to-speech provider (see tts.NewClient), and stores the MP3
+// in object storage, replacing any earlier audio summary
+func GenerateAudioSummary(ctx context.Context, sourceID int) (*models.AudioSummary, error) {
+	client, err := tts.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := AudioSummaryScript(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	audio, err := client.Synthesize(ctx, script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize audio summary: %w", err)
+	}
+
+	key := audioSummaryKey(sourceID)
+	if err := storage.Store.Put(ctx, key, bytes.NewReader(audio), int64(len(audio)), tts.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store audio summary: %w", err)
+	}
+
+	summary, err := db.SaveAudioSummary(models.AudioSummary{
+		SourceContentID: sourceID,
+		StorageKey:      key,
+		SizeBytes:       int64(len(audio)),
+		Provider:        client.Provider(),
+		Voice:           client.Voice(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, signAudioSummary(ctx, summary)
+}
+
+// GetAudioSummary retrieves a source's audio summary with a download URL
+func GetAudioSummary(ctx context.Context, sourceID int) (*models.AudioSummary, error) {
+	summary, err := db.GetAudioSummary(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return summary, signAudioSummary(ctx, summary)
+}
+
+// DeleteAudioSummary deletes a source's audio summary and its audio
+func DeleteAudioSummary(ctx context.Context, sourceID int) error {
+	summary, err := db.DeleteAudioSummary(sourceID)
+	if err != nil {
+		return err
+	}
+	if err := storage.Store.Delete(ctx, summary.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete audio summary: %w", err)
+	}
+	return nil
+}
+
+// RemoveAudioSummaryFiles removes deleted sources' audio summaries from
+// storage; their rows cascade with the sources. Failures are logged, since
+// the sources are already gone.
+func RemoveAudioSummaryFiles(ctx context.Context, sourceIDs ...int) {
+	for _, id := range sourceIDs {
+		if err := storage.Store.Delete(ctx, audioSummaryKey(id)); err != nil {
+			log.Printf("Warning: failed to delete audio summary of source %d: %v", id, err)
+		}
+	}
+}
+
+// audioSummaryKey is where a source's audio summary is stored
+func audioSummaryKey(sourceID int) string {
+	return storage.PrefixPodcast + strconv.Itoa(sourceID) + ".mp3"
+}
+
+// signAudioSummary fills in a summary's download URL
+func signAudioSummary(ctx context.Context, summary *models.AudioSummary) error {
+	url, err := storage.Store.SignedURL(ctx, summary.StorageKey, audioSummaryURLExpiry)
+	if err != nil {
+		return fmt.Errorf("failed to sign audio summary URL: %w", err)
+	}
+	summary.URL = url
+	return nil
+}
+
+// AudioSummaryScript writes the text an audio summary reads: the source's
+// title, its chapter summaries when the transcription provider detected
+// chapters, and its concepts in teaching order with their descriptions.
+// Archived concepts are left out.
+func AudioSummaryScript(sourceID int) (string, error) {
+	source, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return "", err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get concepts: %w", err)
+	}
+	if len(concepts) == 0 {
+		return "", fmt.Errorf("source has no concepts")
+	}
+
+	title := source.Title
+	if title == "" {
+		title = "this source"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of %s.\n\n", spokenTitle(title))
+
+	var chapters []string
+	for _, chapter := range source.Chapters {
+		if summary := strings.TrimSpace(chapter.Summary); summary != "" {
+			chapters = append(chapters, strings.Join(strings.Fields(summary), " "))
+		}
+	}
+	if len(chapters) > 0 {
+		b.WriteString(strings.Join(chapters, " "))
+		b.WriteString("\n\n")
+	}
+
+	if len(concepts) == 1 {
+		b.WriteString("It covers one concept.\n\n")
+	} else {
+		fmt.Fprintf(&b, "It covers %d concepts.\n\n", len(concepts))
+	}
+	for i, concept := range concepts {
+		fmt.Fprintf(&b, "Concept %d: %s.\n", i+1, spokenTitle(concept.Title))
+		if description := strings.TrimSpace(concept.Description); description != "" {
+			b.WriteString(strings.Join(strings.Fields(description), " "))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "That's the end of the summary of %s.", spokenTitle(title))
+	return b.String(), nil
+}
+
+// spokenTitle collapses a title's whitespace and drops trailing periods,
+// since the script ends the sentence itself
+func spokenTitle(text string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(text), " "), ".")
+}
+
+// podcastFeed is an RSS 2.0 feed of audio summaries
+type podcastFeed struct {
+	XMLName xml.Name       `xml:"rss"`
+	Version string         `xml:"version,attr"`
+	Channel podcastChannel `xml:"channel"`
+}
+
+type podcastChannel struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	Items       []podcastItem `xml:"item"`
+}
+
+type podcastItem struct {
+	Title       string           `xml:"title"`
+	Link        string           `xml:"link,omitempty"`
+	Description string           `xml:"description"`
+	GUID        podcastGUID      `xml:"guid"`
+	PubDate     string           `xml:"pubDate"`
+	Enclosure   podcastEnclosure `xml:"enclosure"`
+}
+
+type podcastGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type podcastEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RenderPodcastFeed renders the newest audio summaries as a podcast RSS
+// feed, with link as the public origin. Episode URLs are signed, so they
+// expire; podcast apps get fresh ones each time they refresh the feed.
+func RenderPodcastFeed(ctx context.Context, link string) ([]byte, error) {
+	summaries, err := db.GetAudioSummaries(podcastFeedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := podcastFeed{
+		Version: "2.0",
+		Channel: podcastChannel{
+			Title:       "Lattice audio summaries",
+			Link:        link,
+			Description: "Summaries of your sources and their concepts, read aloud",
+			Items:       make([]podcastItem, 0, len(summaries)),
+		},
+	}
+	for i := range summaries {
+		summary := &summaries[i]
+		if err := signAudioSummary(ctx, summary); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(summary.URL, "/") {
+			summary.URL = link + summary.URL // Local storage without PUBLIC_BASE_URL
+		}
+		feed.Channel.Items = append(feed.Channel.Items, podcastItem{
+			Title:       summary.Title,
+			Link:        summary.SourceURL,
+			Description: "Audio summary of " + summary.Title,
+			// Regenerating changes the GUID, so apps download the new audio
+			GUID:      podcastGUID{IsPermaLink: "false", Value: fmt.Sprintf("lattice-audio-summary-%d-%d", summary.SourceContentID, summary.CreatedAt.Unix())},
+			PubDate:   summary.CreatedAt.Format(time.RFC1123Z),
+			Enclosure: podcastEnclosure{URL: summary.URL, Length: summary.SizeBytes, Type: tts.ContentType},
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render podcast feed: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+)
+
+// Entries of a backup archive
+const (
+	backupManifestEntry = "manifest.json"
+	backupTablesDir     = "tables/"  // One file per table, a JSON row per line
+	backupObjectsDir    = "objects/" // Stored objects, under their keys
+)
+
+// CreateBackup writes a ZIP archive of every table, read from one snapshot,
+// and a manifest of the object store, copying the objects into the archive
+// too when includeObjects is set. Objects are listed after the snapshot, so
+// every object it references is listed unless deleted meanwhile.
+func CreateBackup(ctx context.Context, w io.Writer, includeObjects bool) (*models.BackupManifest, error) {
+	archive := zip.NewWriter(w)
+	manifest := &models.BackupManifest{Format: models.BackupFormat, CreatedAt: time.Now().UTC()}
+
+	// Rows arrive table by table, each table in its own entry
+	var current string
+	var entry io.Writer
+	migrations, tables, err := db.DumpDatabase(func(table string, row json.RawMessage) error {
+		if table != current {
+			var err error
+			if entry, err = archive.Create(backupTablesDir + table + ".ndjson"); err != nil {
+				return fmt.Errorf("failed to add %s to backup: %w", table, err)
+			}
+			current = table
+		}
+		if _, err := entry.Write(append(row, '\n')); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", table, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Migrations = migrations
+	manifest.Tables = tables
+
+	objects, err := storage.Store.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	manifest.Objects = make([]models.BackupObject, 0, len(objects))
+	for _, object := range objects {
+		listed := models.BackupObject{Key: object.Key, Size: object.Size}
+		if includeObjects {
+			sum, size, err := backupObject(ctx, archive, object.Key)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue // Deleted since it was listed
+			}
+			if err != nil {
+				return nil, err
+			}
+			listed.SHA256, listed.Size, listed.Included = sum, size, true
+		}
+		manifest.Objects = append(manifest.Objects, listed)
+	}
+
+	entry, err = archive.Create(backupManifestEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add manifest to backup: %w", err)
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// backupObject copies a stored object into the archive under objects/,
+// returning its SHA-256 and size
+func backupObject(ctx context.Context, archive *zip.Writer, key string) (string, int64, error) {
+	r, err := storage.Store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", 0, err
+		}
+		return "", 0, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer r.Close()
+
+	w, err := archive.Create(backupObjectsDir + key)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to add %s to backup: %w", key, err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), r)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to back up %s: %w", key, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// RestoreBackup restores a backup archive made by CreateBackup into a fresh
+// instance at the same migrations. The archive's objects are put in the
+// store first, checked against their hashes; then the rows are loaded in a
+// single transaction, and references to objects that are neither in the
+// archive nor already in the store are unlinked.
+func RestoreBackup(ctx context.Context, archivePath string) (*models.RestoreResult, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer archive.Close()
+
+	entries := map[string]*zip.File{}
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+
+	manifest, err := readBackupManifest(entries[backupManifestEntry])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RestoreResult{ObjectsMissing: []string{}}
+	included := map[string]bool{}
+	for _, object := range manifest.Objects {
+		if !object.Included {
+			continue
+		}
+		entry := entries[backupObjectsDir+object.Key]
+		if entry == nil {
+			return nil, fmt.Errorf("backup is missing object %s", object.Key)
+		}
+		if err := restoreObject(ctx, entry, object); err != nil {
+			return nil, err
+		}
+		included[object.Key] = true
+		result.ObjectsRestored++
+	}
+
+	// Objects left out of the archive must already be in the store
+	objects, err := storage.Store.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	stored := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		stored[object.Key] = true
+	}
+	for _, object := range manifest.Objects {
+		switch {
+		case included[object.Key]:
+		case stored[object.Key]:
+			result.ObjectsPresent++
+		default:
+			result.ObjectsMissing = append(result.ObjectsMissing, object.Key)
+		}
+	}
+
+	rowCounts := make(map[string]int, len(manifest.Tables))
+	tables := make([]string, 0, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		rowCounts[table.Name] = table.Rows
+		tables = append(tables, table.Name)
+	}
+
+	load := func(table string, insert func(row json.RawMessage) error) error {
+		n, err := loadBackupTable(entries[backupTablesDir+table+".ndjson"], insert)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from backup: %w", table, err)
+		}
+		if n != rowCounts[table] {
+			return fmt.Errorf("backup has %d rows of %s, but its manifest lists %d", n, table, rowCounts[table])
+		}
+		return nil
+	}
+
+	result.Rows, result.Unlinked, err = db.RestoreDatabase(manifest.Migrations, tables, load, func(key string) bool {
+		return stored[key]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readBackupManifest reads and checks a backup's manifest
+func readBackupManifest(entry *zip.File) (*models.BackupManifest, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("backup has no %s", backupManifestEntry)
+	}
+	r, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer r.Close()
+
+	var manifest models.BackupManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.Format != models.BackupFormat {
+		return nil, fmt.Errorf("unsupported backup format %d", manifest.Format)
+	}
+	return &manifest, nil
+}
+
+// restoreObject puts an archived object in the store under its key, deleting
+// it again when its contents don't match the manifest
+func restoreObject(ctx context.Context, entry *zip.File, object models.BackupObject) error {
+	r, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", object.Key, err)
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	contentType := mime.TypeByExtension(path.Ext(object.Key))
+	if err := storage.Store.Put(ctx, object.Key, io.TeeReader(r, hash), object.Size, contentType); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", object.Key, err)
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != object.SHA256 {
+		if err := storage.Store.Delete(ctx, object.Key); err != nil {
+			return fmt.Errorf("failed to delete corrupt %s: %w", object.Key, err)
+		}
+		return fmt.Errorf("backup's copy of %s is corrupt", object.Key)
+	}
+	return nil
+}
+
+// loadBackupTable passes each row of a table's entry to insert, returning the
+// rows read. A table without rows has no entry.
+func loadBackupTable(entry *zip.File, insert func(row json.RawMessage) error) (int, error) {
+	if entry == nil {
+		return 0, nil
+	}
+	r, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	// Rows hold whole transcripts, too long for a bufio.Scanner's lines
+	reader := bufio.NewReader(r)
+	n := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if err := insert(line); err != nil {
+				return 0, err
+			}
+			n++
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/billing"
+	"github.com/mostlyerror/lattice/pkg/llm"
+)
+
+// DefaultUsageTenant is the tenant usage is metered for unless BILLING_TENANT is set
+const DefaultUsageTenant = "default"
+
+// usageReportBatch is how many usage events are sent to the sink at a time
+const usageReportBatch = 100
+
+// usageReportWindow is how far back usage totals go by default
+const usageReportWindow = 30 * 24 * time.Hour
+
+// ErrInvalidUsageRange is returned for usage queries that end before they start
+var ErrInvalidUsageRange = errors.New("from must not be after to")
+
+// RecordUsage meters quantity of a metric for this deployment's tenant.
+// Failures are logged, since the work being metered already happened.
+func RecordUsage(metric string, quantity int64, sourceContentID *int) {
+	if quantity <= 0 {
+		return
+	}
+	if err := db.RecordUsageEvent(usageTenant(), metric, quantity, sourceContentID); err != nil {
+		log.Printf("Warning: failed to meter %s: %v", metric, err)
+	}
+}
+
+// meterClaudeTokens meters the tokens of each Claude response, and adds them
+// to the model's totals that LLM spend is estimated from
+func meterClaudeTokens(model string, inputTokens, outputTokens int) {
+	RecordUsage(models.UsageClaudeTokens, int64(inputTokens+outputTokens), nil)
+	if err := db.RecordLLMUsage(model, inputTokens, outputTokens); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// usageTenant is the tenant usage is metered for: BILLING_TENANT, since each
+// deployment serves one organization
+func usageTenant() string {
+	if tenant := os.Getenv("BILLING_TENANT"); tenant != "" {
+		return tenant
+	}
+	return DefaultUsageTenant
+}
+
+// monthlyTokens is the budget Claude calls draw on this month, when
+// CLAUDE_MONTHLY_TOKEN_LIMIT sets one
+var monthlyTokens struct {
+	mu     sync.Mutex
+	month  string // YYYY-MM, in UTC
+	limit  int
+	budget *llm.TokenBudget
+}
+
+// monthlyTokenBudget returns what's left this UTC month of the tenant's
+// CLAUDE_MONTHLY_TOKEN_LIMIT, after the Claude tokens already metered for it,
+// or nil when there's no limit. If metering can't be read, calls go ahead
+// and it's read again on the next one.
+func monthlyTokenBudget() *llm.TokenBudget {
+	limit, err := strconv.Atoi(os.Getenv("CLAUDE_MONTHLY_TOKEN_LIMIT"))
+	if err != nil || limit <= 0 {
+		return nil
+	}
+
+	monthlyTokens.mu.Lock()
+	defer monthlyTokens.mu.Unlock()
+
+	now := time.Now().UTC()
+	month := now.Format("2006-01")
+	if monthlyTokens.budget != nil && monthlyTokens.month == month && monthlyTokens.limit == limit {
+		return monthlyTokens.budget
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	used, err := db.SumUsageSince(usageTenant(), models.UsageClaudeTokens, monthStart)
+	if err != nil {
+		log.Printf("Warning: not enforcing CLAUDE_MONTHLY_TOKEN_LIMIT: %v", err)
+		return nil
+	}
+
+	monthlyTokens.month = month
+	monthlyTokens.limit = limit
+	monthlyTokens.budget = llm.NewTokenBudget(int(max(0, int64(limit)-used)))
+	return monthlyTokens.budget
+}
+
+// UsageReporter sends metered usage to the billing sink in the background
+type UsageReporter struct {
+	sink billing.Sink // nil when usage is only recorded
+}
+
+// NewUsageReporter creates a reporter for the sink BILLING_SINK names (see
+// billing.NewSink). Without one, usage is still recorded and totalled.
+func NewUsageReporter() (*UsageReporter, error) {
+	sink, err := billing.NewSink()
+	if err != nil {
+		return nil, err
+	}
+	return &UsageReporter{sink: sink}, nil
+}
+
+// StartUsageReporting reports usage every interval until ctx is cancelled.
+// It returns at once when no sink is configured.
+func (r *UsageReporter) StartUsageReporting(ctx context.Context, interval time.Duration) {
+	if r.sink == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reported, err := r.Report(ctx); err != nil {
+				log.Printf("Warning: usage reporting failed after %d events: %v", reported, err)
+			}
+		}
+	}
+}
+
+// Report sends every unreported usage event to the sink, oldest first, and
+// returns how many it took. Events it didn't take stay unreported, to be
+// sent again on the next report.
+func (r *UsageReporter) Report(ctx context.Context) (int, error) {
+	if r.sink == nil {
+		return 0, billing.ErrNotConfigured
+	}
+
+	reported := 0
+	for {
+		events, err := db.GetUnreportedUsageEvents(usageReportBatch)
+		if err != nil || len(events) == 0 {
+			return reported, err
+		}
+
+		batch := make([]billing.Event, len(events))
+		for i, event := range events {
+			batch[i] = billing.Event{
+				Identifier:      fmt.Sprintf("lattice-usage-%d", event.ID),
+				Tenant:          event.Tenant,
+				Metric:          event.Metric,
+				Quantity:        event.Quantity,
+				SourceContentID: event.SourceContentID,
+				Timestamp:       event.CreatedAt,
+			}
+		}
+
+		taken, sinkErr := r.sink.Report(ctx, batch)
+		ids := make([]int64, 0, taken)
+		for _, event := range events[:taken] {
+			ids = append(ids, event.ID)
+		}
+		if err := db.MarkUsageEventsReported(ids); err != nil {
+			return reported, err
+		}
+		reported += taken
+
+		if sinkErr != nil {
+			return reported, sinkErr
+		}
+		if len(events) < usageReportBatch {
+			return reported, nil
+		}
+	}
+}
+
+// UsageReport totals metered usage per tenant, metric, and day or month, from
+// query.From through query.To (the last 30 days by default)
+func (r *UsageReporter) UsageReport(query models.UsageQuery) (*models.UsageReport, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if query.To != nil {
+		to = *query.To
+	}
+	from := to.Add(-usageReportWindow)
+	if query.From != nil {
+		from = *query.From
+	}
+	if from.After(to) {
+		return nil, ErrInvalidUsageRange
+	}
+
+	interval := query.Interval
+	if interval == "" {
+		interval = "day"
+	}
+
+	totals, err := db.GetUsageTotals(from, to.AddDate(0, 0, 1), interval == "month", query.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	unreported, err := db.CountUnreportedUsageEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UsageReport{
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		Interval:   interval,
+		Totals:     totals,
+		Unreported: unreported,
+	}
+	if r.sink != nil {
+		report.Sink = r.sink.Name()
+	}
+	return report, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// bootstrapRecentLimit is how many recent sources and drafts GetBootstrap lists
+const bootstrapRecentLimit = 5
+
+// GetBootstrap gathers a dashboard's workspace data, of what owner can see:
+// counts, the review queue's size with days taken in loc, and recent sources
+// and drafts. The caller's identity is left for the handler to fill in.
+func GetBootstrap(loc *time.Location, owner *int) (*models.Bootstrap, error) {
+	counts, err := db.GetWorkspaceCounts(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	limit := CurrentSettings().Review.DailyLimit
+	reviewed, err := db.CountConceptsReviewedSince(endOfDay(now, loc).AddDate(0, 0, -1), owner)
+	if err != nil {
+		return nil, err
+	}
+	due, err := db.CountDueConcepts(now, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := db.GetRecentSourceContents(bootstrapRecentLimit, owner)
+	if err != nil {
+		return nil, err
+	}
+	drafts, err := db.GetRecentDrafts(bootstrapRecentLimit, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Bootstrap{
+		Counts: *counts,
+		Reviews: models.ReviewSummary{
+			Due:           due,
+			ReviewedToday: reviewed,
+			DailyLimit:    limit,
+			Remaining:     max(0, min(due, limit-reviewed)),
+		},
+		RecentSources: sources,
+		RecentDrafts:  drafts,
+	}, nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+	"github.com/mostlyerror/lattice/pkg/tts"
+)
+
+// Briefing limits
+const (
+	briefingDueListed     = 5                   // Due concepts named in a briefing; the rest are counted
+	briefingNewListed     = 10                  // New concepts named in a briefing
+	briefingResurfaceAge  = 30 * 24 * time.Hour // How old a concept must be to resurface
+	briefingResurfaceGap  = 30 * 24 * time.Hour // How long before a resurfaced concept can resurface again
+	briefingDefaultWindow = 24 * time.Hour      // How far back the first briefing looks for new concepts
+)
+
+// ErrNothingToBrief is returned when no concept is due, new, or old enough to resurface
+var ErrNothingToBrief = errors.New("nothing to brief: no concepts are due, new, or old enough to resurface")
+
+// StartDailyBriefing delivers a daily briefing at hour:minute in
+// DefaultLocation, until ctx is cancelled. Days with nothing to brief are
+// skipped.
+func (s *NotificationService) StartDailyBriefing(ctx context.Context, hour, minute int) {
+	for {
+		timer := time.NewTimer(time.Until(nextLocalTime(time.Now(), hour, minute, DefaultLocation())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if _, err := s.DeliverBriefing(ctx, nil); errors.Is(err, ErrNothingToBrief) {
+				log.Printf("Skipped daily briefing: %v", err)
+			} else if err != nil {
+				log.Printf("Daily briefing failed: %v", err)
+			}
+		}
+	}
+}
+
+// DeliverBriefing generates today's briefing for owner, or the workspace's for
+// a nil owner, and sends it to them as a daily_briefing notification, to the
+// channels enabled for it
+func (s *NotificationService) DeliverBriefing(ctx context.Context, owner *int) (*models.Briefing, error) {
+	briefing, err := s.GenerateBriefing(ctx, time.Now(), owner)
+	if err != nil {
+		return nil, err
+	}
+
+	body := briefing.Body
+	if briefing.AudioURL != "" {
+		body += "\n\nListen: " + briefing.AudioURL
+	}
+	s.NotifyUser(ctx, owner, models.EventDailyBriefing, briefing.Title, body, models.JSONObject{
+		"briefing_id": briefing.ID,
+		"date":        briefing.Date,
+		"due_count":   briefing.DueCount,
+		"new_count":   len(briefing.NewConceptIDs),
+		"audio_url":   briefing.AudioURL,
+	})
+
+	return briefing, nil
+}
+
+// GenerateBriefing writes owner's briefing for now's day in DefaultLocation,
+// from the concepts they can see (every concept for the workspace's, when
+// owner is nil): those due for review by the end of the day, those created
+// since their previous day's briefing, and the active concept gone longest
+// without a review. With BRIEFING_AUDIO=true it's also read aloud, when
+// text-to-speech is configured. Regenerating a day's briefing replaces it.
+func (s *NotificationService) GenerateBriefing(ctx context.Context, now time.Time, owner *int) (*models.Briefing, error) {
+	loc := DefaultLocation()
+	local := now.In(loc)
+	date := local.Format("2006-01-02")
+	dueBefore := endOfDay(now, loc)
+
+	dueCount, err := db.CountDueConcepts(dueBefore, owner)
+	if err != nil {
+		return nil, err
+	}
+	due, err := db.GetDueConcepts(dueBefore, briefingDueListed, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	since := now.Add(-briefingDefaultWindow)
+	previous, err := db.GetPreviousBriefing(date, owner)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		since = previous.CreatedAt
+	}
+	fresh, err := db.GetConceptsCreatedSince(since, briefingNewListed+1, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	resurfaced, err := db.GetResurfaceConcept(now.Add(-briefingResurfaceAge), dueBefore, now.Add(-briefingResurfaceGap), owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if dueCount == 0 && len(fresh) == 0 && resurfaced == nil {
+		return nil, ErrNothingToBrief
+	}
+
+	briefing := models.Briefing{
+		Date:          date,
+		Title:         "Your briefing for " + local.Format("Monday, January 2"),
+		Body:          briefingBody(dueCount, due, fresh, resurfaced),
+		DueCount:      dueCount,
+		DueConceptIDs: conceptIDs(due),
+		NewConceptIDs: conceptIDs(fresh[:min(len(fresh), briefingNewListed)]),
+		OwnerID:       owner,
+	}
+	if resurfaced != nil {
+		briefing.ResurfacedConceptID = &resurfaced.ID
+	}
+
+	if s.briefingAudio {
+		// The text briefing still goes out when it can't be read aloud
+		key, err := recordBriefing(ctx, date, briefing.Body, owner)
+		if err != nil {
+			log.Printf("Warning: failed to read briefing aloud: %v", err)
+		} else {
+			briefing.AudioKey = &key
+		}
+	}
+
+	saved, err := db.SaveBriefing(briefing)
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, SignBriefingAudio(ctx, saved)
+}
+
+// SignBriefingAudio fills in a briefing's audio URL, when it was read aloud
+func SignBriefingAudio(ctx context.Context, briefing *models.Briefing) error {
+	if briefing.AudioKey == nil {
+		return nil
+	}
+
+	url, err := storage.Store.SignedURL(ctx, *briefing.AudioKey, audioSummaryURLExpiry)
+	if err != nil {
+		return fmt.Errorf("failed to sign briefing audio URL: %w", err)
+	}
+	briefing.AudioURL = url
+	return nil
+}
+
+// recordBriefing reads owner's briefing aloud and stores the MP3, returning
+// its key
+func recordBriefing(ctx context.Context, date, body string, owner *int) (string, error) {
+	client, err := tts.NewClient()
+	if err != nil {
+		return "", err
+	}
+
+	audio, err := client.Synthesize(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	key := storage.PrefixPodcast + "briefings/" + date + ".mp3"
+	if owner != nil {
+		key = fmt.Sprintf("%sbriefings/%d/%s.mp3", storage.PrefixPodcast, *owner, date)
+	}
+	if err := storage.Store.Put(ctx, key, bytes.NewReader(audio), int64(len(audio)), tts.ContentType); err != nil {
+		return "", fmt.Errorf("failed to store briefing audio: %w", err)
+	}
+	return key, nil
+}
+
+// briefingBody writes a briefing's plain text. fresh may hold one concept
+// more than are listed, to tell that there are more.
+func briefingBody(dueCount int, due, fresh []models.Concept, resurfaced *models.Concept) string {
+	var b strings.Builder
+
+	if dueCount == 0 {
+		b.WriteString("Nothing is due for review today.\n")
+	} else {
+		fmt.Fprintf(&b, "Due for review today (%d):\n", dueCount)
+		for _, c := range due {
+			fmt.Fprintf(&b, "- %s\n", c.Title)
+		}
+		if dueCount > len(due) {
+			fmt.Fprintf(&b, "- and %d more\n", dueCount-len(due))
+		}
+	}
+
+	if len(fresh) > 0 {
+		b.WriteString("\nNew since your last briefing:\n")
+		for i, c := range fresh {
+			if i == briefingNewListed {
+				b.WriteString("- and more\n")
+				break
+			}
+			fmt.Fprintf(&b, "- %s\n", c.Title)
+		}
+	}
+
+	if resurfaced != nil {
+		fmt.Fprintf(&b, "\nFrom the archive: %s\n", resurfaced.Title)
+		if description := strings.Join(strings.Fields(resurfaced.Description), " "); description != "" {
+			b.WriteString(description + "\n")
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// conceptIDs returns the IDs of concepts
+func conceptIDs(concepts []models.Concept) models.IntArray {
+	ids := models.IntArray{}
+	for _, c := range concepts {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
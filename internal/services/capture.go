@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/youtube"
+)
+
+// CaptureURL queues the YouTube video at url for the default pipeline, for
+// bookmarklet captures, to be owned by ownerID. When url was already ingested
+// it returns the existing source and queues nothing.
+func (s *SourceContentService) CaptureURL(url string, ownerID *int) (*models.SourceContent, error) {
+	if err := youtube.ValidateURL(url); err != nil {
+		return nil, err
+	}
+
+	existing, err := db.GetSourceContentByURL(url, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	job, err := s.EnqueueSourceContent(models.CreateSourceContentRequest{Type: "youtube", URL: url, OwnerID: ownerID})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Captured %s as job %d", url, job.ID)
+	return nil, nil
+}
+
+// capturePageData is the template input for a capture confirmation
+type capturePageData struct {
+	Heading string
+	Message string
+	URL     string
+}
+
+// RenderCapturePage renders the confirmation a bookmarklet capture opens
+func RenderCapturePage(heading, message, url string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := capturePageTemplate.Execute(&buf, capturePageData{Heading: heading, Message: message, URL: url}); err != nil {
+		return nil, fmt.Errorf("failed to render capture page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var capturePageTemplate = template.Must(template.New("capture").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Heading}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 30rem; margin: 3rem auto; padding: 0 1rem; color: #111; line-height: 1.55; }
+  h1 { font-size: 1.3rem; }
+  .url { color: #555; font-size: 0.9rem; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>{{.Heading}}</h1>
+<p>{{.Message}}</p>
+{{if .URL}}<p class="url">{{.URL}}</p>{{end}}
+</body>
+</html>
+`))
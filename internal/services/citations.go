@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// citationPrompt asks content generation to mark the concepts each sentence draws on
+const citationPrompt = `Citations: After each sentence that draws on one of the numbered concepts, add the concept's number in square brackets, such as [2] or [1, 3]. The markers are removed before publishing and don't count toward the length.`
+
+// citationMarkerPattern matches a citation marker and the space before it
+var citationMarkerPattern = regexp.MustCompile(`[ \t]?\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// citationSourceLabels name each source type in citations
+var citationSourceLabels = map[string]string{
+	"youtube":   "video",
+	"meeting":   "meeting",
+	"recording": "recording",
+	"podcast":   "podcast episode",
+	"pdf":       "PDF",
+	"article":   "article",
+	"import":    "imported deck",
+}
+
+// extractCitations removes the citation markers from a generated body and
+// returns it with a citation per sentence and concept marked. Markers number
+// concepts from 1; numbers outside that range are left in the text.
+func extractCitations(body string, concepts []models.Concept) (string, models.Citations) {
+	type marker struct {
+		at         int // Offset in the cleaned body
+		conceptIDs []int
+	}
+
+	var clean strings.Builder
+	var markers []marker
+	last := 0
+	for _, m := range citationMarkerPattern.FindAllStringSubmatchIndex(body, -1) {
+		if m[1] < len(body) && body[m[1]] == '(' {
+			continue // A Markdown link
+		}
+
+		var conceptIDs []int
+		for _, number := range strings.Split(body[m[2]:m[3]], ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(number)); err == nil && n >= 1 && n <= len(concepts) {
+				conceptIDs = append(conceptIDs, concepts[n-1].ID)
+			}
+		}
+		if len(conceptIDs) == 0 {
+			continue
+		}
+
+		clean.WriteString(body[last:m[0]])
+		markers = append(markers, marker{at: clean.Len(), conceptIDs: conceptIDs})
+		last = m[1]
+	}
+	clean.WriteString(body[last:])
+	text := clean.String()
+
+	conceptSources := make(map[int]*int, len(concepts))
+	for _, c := range concepts {
+		conceptSources[c.ID] = c.SourceContentID
+	}
+
+	citations := models.Citations{}
+	for _, m := range markers {
+		sentence := citedSentence(text, m.at)
+		if sentence == "" {
+			continue
+		}
+		for _, id := range m.conceptIDs {
+			if !slices.ContainsFunc(citations, func(c models.Citation) bool { return c.Sentence == sentence && c.ConceptID == id }) {
+				citations = append(citations, models.Citation{Sentence: sentence, ConceptID: id, SourceContentID: conceptSources[id]})
+			}
+		}
+	}
+
+	return text, citations
+}
+
+// citedSentence returns the sentence a marker at offset at belongs to: the one
+// ending just before it, or when the marker sits mid-sentence, the one it's in
+func citedSentence(text string, at int) string {
+	before := strings.TrimRight(text[:at], " \t")
+	end := len(before)
+	if before == "" || !strings.ContainsAny(before[len(before)-1:], ".!?") {
+		next := strings.IndexAny(text[at:], ".!?\n")
+		switch {
+		case next < 0:
+			end = len(text)
+		case text[at+next] == '\n':
+			end = at + next
+		default:
+			end = at + next + 1
+		}
+	}
+
+	start := strings.LastIndexAny(strings.TrimRight(text[:end], ".!?"), ".!?\n") + 1
+	return strings.TrimLeft(strings.TrimSpace(text[start:end]), "-*#> \t")
+}
+
+// anchorCitations points each citation at the transcript segment that best
+// supports its concept, with that segment's start time when it has one
+func anchorCitations(citations models.Citations, segments []models.TranscriptSegment, highlights []models.TranscriptHighlight) {
+	best := map[int]int{}
+	for _, h := range highlights {
+		if _, ok := best[h.ConceptID]; !ok {
+			best[h.ConceptID] = h.SegmentIndex // Highlights come best first per concept
+		}
+	}
+
+	for i := range citations {
+		if index, ok := best[citations[i].ConceptID]; ok {
+			citations[i].Segment = &index
+			citations[i].StartSeconds = segments[index].StartSeconds
+		}
+	}
+}
+
+// citedBody returns content's body with a Markdown footnote after each cited
+// sentence that's still in it, crediting the source at the cited point, as in
+// "Per [video, 14:32](...)". sources is keyed by source content ID.
+func citedBody(content models.GeneratedContent, sources map[int]*models.SourceContent) string {
+	body := content.Body
+
+	var notes []string
+	refs := map[int][]int{} // Footnote numbers by the offset they follow
+	for _, citation := range content.Citations {
+		if citation.SourceContentID == nil || sources[*citation.SourceContentID] == nil {
+			continue
+		}
+		start := strings.Index(body, citation.Sentence)
+		if citation.Sentence == "" || start < 0 {
+			continue // Edited out since generation
+		}
+
+		note := citationNote(sources[*citation.SourceContentID], citation.StartSeconds)
+		n := slices.Index(notes, note) + 1
+		if n == 0 {
+			notes = append(notes, note)
+			n = len(notes)
+		}
+		at := start + len(citation.Sentence)
+		if !slices.Contains(refs[at], n) {
+			refs[at] = append(refs[at], n)
+		}
+	}
+	if len(notes) == 0 {
+		return body
+	}
+
+	offsets := make([]int, 0, len(refs))
+	for at := range refs {
+		offsets = append(offsets, at)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, at := range offsets {
+		var marks strings.Builder
+		for _, n := range refs[at] {
+			marks.WriteString(fmt.Sprintf("[^%d]", n))
+		}
+		body = body[:at] + marks.String() + body[at:]
+	}
+
+	var footnotes strings.Builder
+	for i, note := range notes {
+		footnotes.WriteString(fmt.Sprintf("\n[^%d]: %s", i+1, note))
+	}
+	return strings.TrimRight(body, "\n") + "\n" + footnotes.String()
+}
+
+// citationNote credits a source, at startSeconds into it when known
+func citationNote(source *models.SourceContent, startSeconds *float64) string {
+	label := citationSourceLabels[source.Type]
+	if label == "" {
+		label = "source"
+	}
+	link := source.URL
+
+	if startSeconds != nil {
+		seconds := int(*startSeconds)
+		label += ", " + formatTimestamp(seconds)
+		if source.Type == "youtube" {
+			link = timestampedURL(link, seconds)
+		}
+	}
+
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return fmt.Sprintf("Per %s: %s", label, source.Title)
+	}
+	return fmt.Sprintf("Per [%s](%s): %s", label, link, source.Title)
+}
+
+// formatTimestamp formats seconds as m:ss, or h:mm:ss from an hour
+func formatTimestamp(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// timestampedURL returns a YouTube URL that starts playback at seconds
+func timestampedURL(link string, seconds int) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	query := parsed.Query()
+	query.Set("t", fmt.Sprintf("%ds", seconds))
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -4,60 +4,183 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/mostlyerror/lattice/internal/models"
-	"github.com/mostlyerror/lattice/pkg/claude"
+	"github.com/mostlyerror/lattice/pkg/llm"
+	"github.com/mostlyerror/lattice/pkg/youtube"
 )
 
-// ClaudeService handles all Claude API interactions
+// Transcript caps for prompts that send whole transcripts
+const (
+	chatTranscriptMaxChars     = 60000 // Per source chat turn
+	compareTranscriptMaxChars  = 15000 // Per compared source
+	moderateTranscriptMaxChars = 15000 // Per source of moderated content
+	translateChunkMaxChars     = 4000  // Per translation request, so the output fits in max tokens
+)
+
+// ClaudeService handles all interactions with the language model, sent to
+// the provider configured by LLM_PROVIDER (Claude by default)
 type ClaudeService struct {
-	client      *claude.Client
-	conceptsMin int
-	conceptsMax int
+	client       *llm.Client
+	model        string               // Pipeline stage model; the workspace default when empty
+	conceptsCap  int                  // Upper limit on concepts per source, 0 for none
+	concepts     *models.ConceptCount // Pipeline stage concepts per source; the workspace's when nil
+	quizMin      int                  // Quiz questions per concept
+	quizMax      int
+	instructions string   // Extra pipeline stage instructions appended to prompts
+	targetGrade  *float64 // Pipeline stage grade level for content; none when nil
+	language     string   // Source transcript language, when not English
+	thinking     int      // Extended thinking budget for reasoning-heavy requests, 0 for none
 }
 
 // NewClaudeService creates a new Claude service
 func NewClaudeService() (*ClaudeService, error) {
-	client, err := claude.NewClient()
+	client, err := llm.NewClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Claude client: %w", err)
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
-	// Get config from environment
-	conceptsMin := 3
-	conceptsMax := 7
+	// Get config from environment; concepts per source are workspace settings
+	quizMin := 2
+	quizMax := 3
+
+	if minStr := os.Getenv("QUIZ_QUESTIONS_MIN"); minStr != "" {
+		if min, err := strconv.Atoi(minStr); err == nil && min > 0 {
+			quizMin = min
+		}
+	}
 
-	if minStr := os.Getenv("CONCEPTS_MIN"); minStr != "" {
-		if min, err := strconv.Atoi(minStr); err == nil {
-			conceptsMin = min
+	if maxStr := os.Getenv("QUIZ_QUESTIONS_MAX"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+			quizMax = max
 		}
 	}
 
-	if maxStr := os.Getenv("CONCEPTS_MAX"); maxStr != "" {
-		if max, err := strconv.Atoi(maxStr); err == nil {
-			conceptsMax = max
+	// Extended thinking for comparisons and other reasoning-heavy requests
+	thinking := 8000
+	if budgetStr := os.Getenv("CLAUDE_THINKING_BUDGET"); budgetStr != "" {
+		if budget, err := strconv.Atoi(budgetStr); err == nil && budget >= 0 {
+			thinking = budget
 		}
 	}
 
 	return &ClaudeService{
-		client:      client,
-		conceptsMin: conceptsMin,
-		conceptsMax: conceptsMax,
+		client:   client.WithUsageHook(meterClaudeTokens),
+		quizMin:  quizMin,
+		quizMax:  max(quizMin, quizMax),
+		thinking: thinking,
 	}, nil
 }
 
+// api returns the client to send requests with: on the stage's model when the
+// pipeline names one, otherwise on the workspace's default model, within the
+// tenant's monthly token limit
+func (s *ClaudeService) api() *llm.Client {
+	model := s.model
+	if model == "" {
+		model = CurrentSettings().DefaultModel
+	}
+	client := s.client
+	if model != client.Model() {
+		client = client.WithModel(model)
+	}
+	if budget := monthlyTokenBudget(); budget != nil {
+		client = client.WithTokenBudget(budget)
+	}
+	return client
+}
+
+// reasoningAPI returns the client for requests where reasoning quality matters
+// more than latency: api() with extended thinking, unless it's turned off
+func (s *ClaudeService) reasoningAPI() *llm.Client {
+	if s.thinking == 0 {
+		return s.api()
+	}
+	return s.api().WithThinking(s.thinking)
+}
+
+// conceptRange returns how many concepts to extract per source: the stage's
+// count or the workspace setting, within the service's cap
+func (s *ClaudeService) conceptRange() (int, int) {
+	settings := CurrentSettings()
+	conceptsMin, conceptsMax := settings.ConceptsMin, settings.ConceptsMax
+	if s.concepts != nil {
+		conceptsMin, conceptsMax = s.concepts.Min, s.concepts.Max
+	}
+	if s.conceptsCap > 0 {
+		return min(conceptsMin, s.conceptsCap), min(conceptsMax, s.conceptsCap)
+	}
+	return conceptsMin, conceptsMax
+}
+
+// forStage returns a copy of the service configured for a pipeline stage's
+// model, extra instructions, counts, and target grade level
+func (s *ClaudeService) forStage(stage models.PipelineStage) *ClaudeService {
+	staged := *s
+	if stage.Model != "" {
+		staged.model = stage.Model
+	}
+	staged.instructions = strings.TrimSpace(stage.Instructions)
+	if stage.Questions != nil {
+		staged.quizMin, staged.quizMax = stage.Questions.Min, stage.Questions.Max
+	}
+	if stage.Concepts != nil {
+		staged.concepts = stage.Concepts
+	}
+	staged.targetGrade = stage.TargetGrade
+	return &staged
+}
+
+// withTokenBudget returns a copy of the service whose Claude calls draw on budget
+func (s *ClaudeService) withTokenBudget(budget *llm.TokenBudget) *ClaudeService {
+	budgeted := *s
+	budgeted.client = s.client.WithTokenBudget(budget)
+	return &budgeted
+}
+
+// withLanguage returns a copy of the service whose prompts say the source is
+// in language (a BCP 47 tag). English sources need no note.
+func (s *ClaudeService) withLanguage(language string) *ClaudeService {
+	localized := *s
+	localized.language = ""
+	if language != "" && language != youtube.DefaultLanguage && !strings.HasPrefix(language, youtube.DefaultLanguage+"-") {
+		localized.language = language
+	}
+	return &localized
+}
+
+// withInstructions appends the source's language and the stage's extra
+// instructions, if any, to a prompt
+func (s *ClaudeService) withInstructions(userPrompt string) string {
+	if s.language != "" {
+		userPrompt += fmt.Sprintf("\n\nThe transcript is in the language with BCP 47 tag %q. Write every title, description, question, and answer in that language, keeping JSON keys in English.", s.language)
+	}
+	if s.instructions == "" {
+		return userPrompt
+	}
+	return userPrompt + "\n\nAdditional instructions:\n" + s.instructions
+}
+
+// ConceptsPromptVersion identifies the concept extraction prompt in eval runs.
+// Bump it whenever that prompt changes, so regressions can be traced to it.
+const ConceptsPromptVersion = "concepts-v1"
+
 // ExtractConcepts extracts learnable concepts from a transcript
 func (s *ClaudeService) ExtractConcepts(ctx context.Context, transcript string, sourceContentID int) ([]models.Concept, error) {
 	// Build the prompt
-	systemPrompt := "You are an expert educator extracting core learnable concepts from content."
+	systemPrompt := promptFor("concepts", "You are an expert educator extracting core learnable concepts from content.")
 
+	conceptsMin, conceptsMax := s.conceptRange()
 	userPrompt := fmt.Sprintf(`Analyze this transcript and extract %d-%d concepts that someone should learn.
 
 For each concept:
 - Title: Clear, concise name (max 100 chars)
 - Description: Detailed explanation (2-4 sentences, focus on practical understanding)
+- Tags: 1-3 short lowercase topic tags (e.g. "pricing", "golang")
 
 Focus on:
 - Fundamental ideas and mental models
@@ -65,55 +188,419 @@ Focus on:
 - Key insights worth remembering
 
 Return ONLY a JSON array, no markdown formatting, no code blocks:
-[{"title": "...", "description": "..."}]
+[{"title": "...", "description": "...", "tags": ["..."]}]
 
 Transcript:
-%s`, s.conceptsMin, s.conceptsMax, transcript)
+%s`, conceptsMin, conceptsMax, transcript)
 
 	// Send request to Claude
-	responseText, err := s.client.SendMessageWithSystem(ctx, systemPrompt, userPrompt)
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract concepts: %w", err)
 	}
 
 	// Parse JSON response
 	var conceptData []struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
 	}
 
-	if err := claude.ParseJSONResponse(responseText, &conceptData); err != nil {
+	if err := llm.ParseJSONResponse(responseText, &conceptData); err != nil {
 		return nil, fmt.Errorf("failed to parse concept JSON: %w", err)
 	}
 
 	// Convert to models.Concept
 	concepts := make([]models.Concept, 0, len(conceptData))
 	for _, c := range conceptData {
+		tags := make(models.StringArray, 0, len(c.Tags))
+		for _, tag := range c.Tags {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
 		concepts = append(concepts, models.Concept{
 			Title:           c.Title,
 			Description:     c.Description,
 			SourceContentID: &sourceContentID,
+			Tags:            tags,
 		})
 	}
 
 	return concepts, nil
 }
 
+// JudgeConcepts asks Claude, as an eval judge, which expected concepts an
+// extraction covers and which extracted concepts are relevant and accurate
+func (s *ClaudeService) JudgeConcepts(ctx context.Context, transcript string, expected []string, extracted []models.Concept) (*models.ConceptJudgement, error) {
+	systemPrompt := promptFor("eval_judge", "You are a strict, impartial grader comparing a concept extraction against an answer key.")
+
+	var expectedText strings.Builder
+	for i, title := range expected {
+		fmt.Fprintf(&expectedText, "%d. %s\n", i+1, title)
+	}
+
+	var extractedText strings.Builder
+	for i, c := range extracted {
+		fmt.Fprintf(&extractedText, "%d. %s: %s\n", i+1, c.Title, c.Description)
+	}
+
+	userPrompt := fmt.Sprintf(`Grade this concept extraction from a transcript.
+
+An expected concept is covered when some extracted concept teaches the same idea, even if named differently.
+An extracted concept is relevant when the transcript actually teaches it and its description is accurate.
+
+Return ONLY a JSON object, no markdown formatting, no code blocks:
+{"covered": [numbers of the covered expected concepts], "relevant": [numbers of the relevant extracted concepts]}
+
+Expected concepts:
+%s
+Extracted concepts:
+%s
+Transcript:
+%s`, expectedText.String(), extractedText.String(), transcript)
+
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to judge concepts: %w", err)
+	}
+
+	var verdict struct {
+		Covered  []int `json:"covered"`
+		Relevant []int `json:"relevant"`
+	}
+	if err := llm.ParseJSONResponse(responseText, &verdict); err != nil {
+		return nil, fmt.Errorf("failed to parse judgement JSON: %w", err)
+	}
+
+	judgement := &models.ConceptJudgement{
+		Covered:  make([]bool, len(expected)),
+		Relevant: make([]bool, len(extracted)),
+	}
+	for _, n := range verdict.Covered {
+		if n >= 1 && n <= len(expected) {
+			judgement.Covered[n-1] = true
+		}
+	}
+	for _, n := range verdict.Relevant {
+		if n >= 1 && n <= len(extracted) {
+			judgement.Relevant[n-1] = true
+		}
+	}
+
+	return judgement, nil
+}
+
+// TranslateTranscript translates a transcript into language (a BCP 47 tag),
+// a few passages per request
+func (s *ClaudeService) TranslateTranscript(ctx context.Context, transcript, language string) (string, error) {
+	systemPrompt := promptFor("translate", "You are a professional translator of video transcripts. You translate faithfully, keeping the speaker's meaning, tone, and terminology.")
+
+	var translated []string
+	for _, chunk := range translationChunks(transcript) {
+		userPrompt := fmt.Sprintf(`Translate this transcript excerpt into the language with BCP 47 tag %q.
+
+Return ONLY the translation, with no preamble, notes, or formatting. Keep names, product names, and code as they are.
+
+Transcript excerpt:
+%s`, language, chunk)
+
+		responseText, err := s.api().SendMessageWithSystem(ctx, systemPrompt, userPrompt)
+		if err != nil {
+			return "", fmt.Errorf("failed to translate transcript: %w", err)
+		}
+		translated = append(translated, strings.TrimSpace(responseText))
+	}
+
+	return strings.Join(translated, "\n\n"), nil
+}
+
+// translationChunks groups a transcript's segments into chunks of at most
+// translateChunkMaxChars, cutting segments without spaces (such as Chinese
+// or Japanese text) where needed
+func translationChunks(transcript string) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, segment := range segmentTranscript(transcript) {
+		text := segment.Text
+		for len(text) > translateChunkMaxChars {
+			flush()
+			cut := translateChunkMaxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			chunks = append(chunks, text[:cut])
+			text = text[cut:]
+		}
+
+		if current.Len() > 0 && current.Len()+1+len(text) > translateChunkMaxChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(text)
+	}
+	flush()
+
+	return chunks
+}
+
+// sameLanguage reports whether two BCP 47 tags share a base language
+func sameLanguage(a, b string) bool {
+	baseA, _, _ := strings.Cut(a, "-")
+	baseB, _, _ := strings.Cut(b, "-")
+	return strings.EqualFold(baseA, baseB)
+}
+
+// ExtractGlossary extracts domain terms and their definitions from a transcript,
+// linking each term to the concepts it supports
+func (s *ClaudeService) ExtractGlossary(ctx context.Context, transcript string, sourceContentID int, concepts []models.Concept) ([]models.GlossaryTerm, error) {
+	systemPrompt := promptFor("glossary", "You are an expert technical editor compiling a glossary of domain terms.")
+
+	var conceptList strings.Builder
+	for i, c := range concepts {
+		conceptList.WriteString(fmt.Sprintf("%d. %s\n", i+1, c.Title))
+	}
+
+	userPrompt := fmt.Sprintf(`Extract the domain-specific terms from this transcript that a learner would need defined.
+
+For each term:
+- Term: The term as it is usually written (e.g. "Kubernetes", "p99 latency")
+- Definition: A self-contained definition in 1-2 sentences, as used in this transcript
+- Concepts: The numbers of the concepts below that the term supports (may be empty)
+
+Skip everyday words and terms that aren't explained or implied by the transcript.
+
+Concepts:
+%s
+Return ONLY a JSON array, no markdown formatting, no code blocks:
+[{"term": "...", "definition": "...", "concepts": [1]}]
+
+Transcript:
+%s`, conceptList.String(), transcript)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract glossary: %w", err)
+	}
+
+	var termData []struct {
+		Term       string `json:"term"`
+		Definition string `json:"definition"`
+		Concepts   []int  `json:"concepts"`
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &termData); err != nil {
+		return nil, fmt.Errorf("failed to parse glossary JSON: %w", err)
+	}
+
+	terms := make([]models.GlossaryTerm, 0, len(termData))
+	for _, t := range termData {
+		term := strings.TrimSpace(t.Term)
+		definition := strings.TrimSpace(t.Definition)
+		if term == "" || definition == "" {
+			continue
+		}
+
+		// Map concept numbers back to IDs, dropping any out of range
+		conceptIDs := models.IntArray{}
+		for _, n := range t.Concepts {
+			if n >= 1 && n <= len(concepts) {
+				conceptIDs = append(conceptIDs, concepts[n-1].ID)
+			}
+		}
+
+		terms = append(terms, models.GlossaryTerm{
+			SourceContentID: sourceContentID,
+			Term:            term,
+			Definition:      definition,
+			ConceptIDs:      conceptIDs,
+		})
+	}
+
+	return terms, nil
+}
+
+// ExtractActionItems extracts the explicit "do this" advice from a transcript,
+// linking each item to the concept it applies
+func (s *ClaudeService) ExtractActionItems(ctx context.Context, transcript string, sourceContentID int, concepts []models.Concept) ([]models.ActionItem, error) {
+	systemPrompt := promptFor("action_items", "You are a practical coach turning advice into a concrete to-do list.")
+
+	var conceptList strings.Builder
+	for i, c := range concepts {
+		conceptList.WriteString(fmt.Sprintf("%d. %s\n", i+1, c.Title))
+	}
+
+	userPrompt := fmt.Sprintf(`List the explicit actions this transcript tells the viewer to take.
+
+For each action item:
+- Text: One imperative sentence the viewer can check off (e.g. "Set up a weekly pricing review")
+- Concept: The number of the concept below it applies, or 0 if none
+
+Only include advice the speaker actually gives. Return an empty array if there is none.
+List items in the order they appear.
+
+Concepts:
+%s
+Return ONLY a JSON array, no markdown formatting, no code blocks:
+[{"text": "...", "concept": 1}]
+
+Transcript:
+%s`, conceptList.String(), transcript)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract action items: %w", err)
+	}
+
+	var itemData []struct {
+		Text    string `json:"text"`
+		Concept int    `json:"concept"`
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &itemData); err != nil {
+		return nil, fmt.Errorf("failed to parse action items JSON: %w", err)
+	}
+
+	items := make([]models.ActionItem, 0, len(itemData))
+	for _, item := range itemData {
+		text := strings.TrimSpace(item.Text)
+		if text == "" {
+			continue
+		}
+
+		var conceptID *int
+		if item.Concept >= 1 && item.Concept <= len(concepts) {
+			conceptID = &concepts[item.Concept-1].ID
+		}
+
+		items = append(items, models.ActionItem{
+			SourceContentID: sourceContentID,
+			ConceptID:       conceptID,
+			Text:            text,
+			Status:          models.ActionItemOpen,
+		})
+	}
+
+	return items, nil
+}
+
+// ExtractMentions extracts the books, tools, people, and frameworks named in a transcript
+func (s *ClaudeService) ExtractMentions(ctx context.Context, transcript string, sourceContentID int) ([]models.Mention, error) {
+	systemPrompt := promptFor("mentions", "You are a meticulous research assistant cataloguing everything a speaker references.")
+
+	userPrompt := fmt.Sprintf(`List the books, tools, people, and frameworks named in this transcript.
+
+For each mention:
+- Kind: One of "book", "tool", "person", "framework"
+- Name: The canonical name (for books, the title; add the author in context)
+- Context: One sentence on why the speaker mentions it
+- URL: The official homepage if you are certain of it, otherwise an empty string
+- Recommended: true if the speaker recommends it to the viewer
+
+Skip passing references that carry no information.
+
+Return ONLY a JSON array, no markdown formatting, no code blocks:
+[{"kind": "book", "name": "...", "context": "...", "url": "", "recommended": true}]
+
+Transcript:
+%s`, transcript)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract mentions: %w", err)
+	}
+
+	var mentionData []struct {
+		Kind        string `json:"kind"`
+		Name        string `json:"name"`
+		Context     string `json:"context"`
+		URL         string `json:"url"`
+		Recommended bool   `json:"recommended"`
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &mentionData); err != nil {
+		return nil, fmt.Errorf("failed to parse mentions JSON: %w", err)
+	}
+
+	mentions := make([]models.Mention, 0, len(mentionData))
+	for _, m := range mentionData {
+		kind := strings.ToLower(strings.TrimSpace(m.Kind))
+		name := strings.TrimSpace(m.Name)
+		if name == "" || !slices.Contains(models.MentionKinds, kind) {
+			continue
+		}
+
+		var url *string
+		if u := strings.TrimSpace(m.URL); strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") {
+			url = &u
+		}
+
+		mentions = append(mentions, models.Mention{
+			SourceContentID: sourceContentID,
+			Kind:            kind,
+			Name:            name,
+			Context:         strings.TrimSpace(m.Context),
+			URL:             url,
+			Recommended:     m.Recommended,
+		})
+	}
+
+	return mentions, nil
+}
+
 // GenerateQuiz generates quiz questions for a concept
 func (s *ClaudeService) GenerateQuiz(ctx context.Context, concept models.Concept) ([]models.QuizQuestion, error) {
-	systemPrompt := "You are an expert educator creating effective quiz questions that test understanding and application, not just recall."
+	count := fmt.Sprintf("%d-%d", s.quizMin, s.quizMax)
+	if s.quizMin == s.quizMax {
+		count = strconv.Itoa(s.quizMin)
+	}
+	return s.generateQuizQuestions(ctx, concept, count, nil)
+}
+
+// GenerateMoreQuiz generates count additional quiz questions for a concept,
+// avoiding repeats of its existing questions
+func (s *ClaudeService) GenerateMoreQuiz(ctx context.Context, concept models.Concept, existing []models.QuizQuestion, count int) ([]models.QuizQuestion, error) {
+	return s.generateQuizQuestions(ctx, concept, strconv.Itoa(count), existing)
+}
+
+// generateQuizQuestions asks Claude for count (a number or range) quiz
+// questions, each different from the existing ones
+func (s *ClaudeService) generateQuizQuestions(ctx context.Context, concept models.Concept, count string, existing []models.QuizQuestion) ([]models.QuizQuestion, error) {
+	systemPrompt := promptFor("quiz", "You are an expert educator creating effective quiz questions that test understanding and application, not just recall.")
+
+	var existingText strings.Builder
+	if len(existing) > 0 {
+		existingText.WriteString("\nExisting questions (ask about different aspects; don't repeat these):\n")
+		for _, q := range existing {
+			existingText.WriteString("- " + q.Question + "\n")
+		}
+	}
 
-	userPrompt := fmt.Sprintf(`Generate 2-3 quiz questions for this concept to test understanding and application.
+	userPrompt := fmt.Sprintf(`Generate %s quiz questions for this concept to test understanding and application.
 
 Concept:
 Title: %s
 Description: %s
-
+%s
 For each question:
 - Question: Tests understanding or application (avoid simple recall)
 - 4 options (A, B, C, D) - make them plausible
 - Correct answer (A, B, C, or D)
 - Explanation: Why correct answer is right and others are wrong (2-3 sentences)
+- Distractor rationales: For each incorrect option, one line on why it's wrong, addressing the misconception that makes it tempting
 
 Return ONLY a JSON array, no markdown formatting, no code blocks:
 [
@@ -124,49 +611,812 @@ Return ONLY a JSON array, no markdown formatting, no code blocks:
     "option_c": "...",
     "option_d": "...",
     "correct_answer": "B",
-    "explanation": "..."
+    "explanation": "...",
+    "distractor_rationales": {"A": "...", "C": "...", "D": "..."}
   }
-]`, concept.Title, concept.Description)
+]`, count, concept.Title, concept.Description, existingText.String())
 
 	// Send request to Claude
-	responseText, err := s.client.SendMessageWithSystem(ctx, systemPrompt, userPrompt)
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate quiz: %w", err)
 	}
 
 	// Parse JSON response
 	var quizData []struct {
-		Question      string `json:"question"`
-		OptionA       string `json:"option_a"`
-		OptionB       string `json:"option_b"`
-		OptionC       string `json:"option_c"`
-		OptionD       string `json:"option_d"`
-		CorrectAnswer string `json:"correct_answer"`
-		Explanation   string `json:"explanation"`
+		Question             string            `json:"question"`
+		OptionA              string            `json:"option_a"`
+		OptionB              string            `json:"option_b"`
+		OptionC              string            `json:"option_c"`
+		OptionD              string            `json:"option_d"`
+		CorrectAnswer        string            `json:"correct_answer"`
+		Explanation          string            `json:"explanation"`
+		DistractorRationales map[string]string `json:"distractor_rationales"`
 	}
 
-	if err := claude.ParseJSONResponse(responseText, &quizData); err != nil {
+	if err := llm.ParseJSONResponse(responseText, &quizData); err != nil {
 		return nil, fmt.Errorf("failed to parse quiz JSON: %w", err)
 	}
 
 	// Convert to models.QuizQuestion
 	questions := make([]models.QuizQuestion, 0, len(quizData))
 	for _, q := range quizData {
+		correctAnswer := strings.ToUpper(q.CorrectAnswer) // Normalize to uppercase
+
 		questions = append(questions, models.QuizQuestion{
-			ConceptID:     concept.ID,
-			Question:      q.Question,
-			OptionA:       q.OptionA,
-			OptionB:       q.OptionB,
-			OptionC:       q.OptionC,
-			OptionD:       q.OptionD,
-			CorrectAnswer: strings.ToUpper(q.CorrectAnswer), // Normalize to uppercase
-			Explanation:   q.Explanation,
+			ConceptID:            concept.ID,
+			Question:             q.Question,
+			OptionA:              q.OptionA,
+			OptionB:              q.OptionB,
+			OptionC:              q.OptionC,
+			OptionD:              q.OptionD,
+			CorrectAnswer:        correctAnswer,
+			Explanation:          q.Explanation,
+			DistractorRationales: distractorRationales(q.DistractorRationales, correctAnswer),
 		})
 	}
 
 	return questions, nil
 }
 
+// SimplifyQuestion generates an easier variant of a question the learner keeps
+// failing, testing the same idea with clearer wording and more distinct options
+func (s *ClaudeService) SimplifyQuestion(ctx context.Context, question models.QuizQuestion, concept models.Concept) (*models.QuizQuestion, error) {
+	systemPrompt := promptFor("simplify_question", "You are an expert educator rewriting quiz questions that learners repeatedly get wrong.")
+
+	userPrompt := fmt.Sprintf(`Learners keep failing this quiz question. Write ONE simpler variant that tests the same core idea.
+
+Concept:
+Title: %s
+Description: %s
+
+Original question: %s
+A) %s
+B) %s
+C) %s
+D) %s
+Correct answer: %s
+Explanation: %s
+
+Guidelines:
+- Use plain, direct wording; one idea per question
+- Make the incorrect options clearly distinct from the correct one, but still plausible
+- Explanation: Why the correct answer is right (2-3 sentences)
+- Distractor rationales: For each incorrect option, one line on why it's wrong
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{
+  "question": "...",
+  "option_a": "...",
+  "option_b": "...",
+  "option_c": "...",
+  "option_d": "...",
+  "correct_answer": "A",
+  "explanation": "...",
+  "distractor_rationales": {"B": "...", "C": "...", "D": "..."}
+}`,
+		concept.Title, concept.Description,
+		question.Question, question.OptionA, question.OptionB, question.OptionC, question.OptionD,
+		question.CorrectAnswer, question.Explanation)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simplify question: %w", err)
+	}
+
+	var data struct {
+		Question             string            `json:"question"`
+		OptionA              string            `json:"option_a"`
+		OptionB              string            `json:"option_b"`
+		OptionC              string            `json:"option_c"`
+		OptionD              string            `json:"option_d"`
+		CorrectAnswer        string            `json:"correct_answer"`
+		Explanation          string            `json:"explanation"`
+		DistractorRationales map[string]string `json:"distractor_rationales"`
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse question JSON: %w", err)
+	}
+
+	correctAnswer := strings.ToUpper(data.CorrectAnswer)
+	return &models.QuizQuestion{
+		ConceptID:            question.ConceptID,
+		Question:             data.Question,
+		OptionA:              data.OptionA,
+		OptionB:              data.OptionB,
+		OptionC:              data.OptionC,
+		OptionD:              data.OptionD,
+		CorrectAnswer:        correctAnswer,
+		Explanation:          data.Explanation,
+		DistractorRationales: distractorRationales(data.DistractorRationales, correctAnswer),
+	}, nil
+}
+
+// ProposeConceptSplit asks Claude to break a concept that lumps several ideas
+// together into two or more focused child concepts, assigning each existing
+// quiz question to the child it tests
+func (s *ClaudeService) ProposeConceptSplit(ctx context.Context, concept models.Concept, quizzes []models.QuizQuestion) ([]models.ConceptSplitChild, error) {
+	systemPrompt := promptFor("split_concept", "You are an expert educator restructuring learning material so each concept covers exactly one idea.")
+
+	var quizText strings.Builder
+	for _, q := range quizzes {
+		quizText.WriteString(fmt.Sprintf("- [%d] %s\n", q.ID, q.Question))
+	}
+	if quizText.Len() == 0 {
+		quizText.WriteString("(none)\n")
+	}
+
+	userPrompt := fmt.Sprintf(`This concept combines more than one idea. Split it into 2-4 focused child concepts.
+
+Concept:
+Title: %s
+Description: %s
+
+Existing quiz questions (ID in brackets):
+%s
+For each child concept:
+- Title: Clear, concise name (max 100 chars)
+- Description: Detailed explanation (2-4 sentences, focus on practical understanding)
+- Tags: 1-3 short lowercase topic tags
+- Question IDs: IDs of the existing questions that test this child (each question belongs to at most one child)
+
+Return ONLY a JSON array, no markdown formatting, no code blocks:
+[{"title": "...", "description": "...", "tags": ["..."], "question_ids": [1]}]`, concept.Title, concept.Description, quizText.String())
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to propose concept split: %w", err)
+	}
+
+	var children []models.ConceptSplitChild
+	if err := llm.ParseJSONResponse(responseText, &children); err != nil {
+		return nil, fmt.Errorf("failed to parse split JSON: %w", err)
+	}
+
+	if len(children) < 2 {
+		return nil, fmt.Errorf("Claude proposed %d child concepts, need at least 2", len(children))
+	}
+
+	// Drop question IDs that don't belong to this concept or are assigned twice
+	valid := make(map[int]bool, len(quizzes))
+	for _, q := range quizzes {
+		valid[q.ID] = true
+	}
+	for i := range children {
+		ids := children[i].QuestionIDs[:0]
+		for _, id := range children[i].QuestionIDs {
+			if valid[id] {
+				ids = append(ids, id)
+				valid[id] = false
+			}
+		}
+		children[i].QuestionIDs = ids
+	}
+
+	return children, nil
+}
+
+// GenerateDiagram asks Claude for Mermaid or Graphviz source that a visual
+// learner can follow. With a focus concept it illustrates that concept, drawing
+// on concepts as related ideas; otherwise it maps how concepts relate.
+// relations lists links already recorded, as "A -> B (type)".
+func (s *ClaudeService) GenerateDiagram(ctx context.Context, format string, focus *models.Concept, concepts []models.Concept, relations []string) (*models.Diagram, error) {
+	systemPrompt := promptFor("diagram", "You are an expert educator who explains ideas with simple, uncluttered diagrams.")
+
+	language := "Mermaid (a flowchart, or a mindmap if the ideas branch from one root)"
+	if format == models.DiagramGraphviz {
+		language = "Graphviz DOT (a digraph; no image, shapefile, or URL attributes)"
+	}
+
+	var conceptsText strings.Builder
+	for _, c := range concepts {
+		conceptsText.WriteString(fmt.Sprintf("- %s: %s\n", c.Title, c.Description))
+	}
+	if conceptsText.Len() == 0 {
+		conceptsText.WriteString("(none)\n")
+	}
+	relationsText := "(none)\n"
+	if len(relations) > 0 {
+		relationsText = "- " + strings.Join(relations, "\n- ") + "\n"
+	}
+
+	task := "Draw a concept map showing how these concepts relate: which builds on which, causes, or contrasts with another. Label every edge with the relationship."
+	subject := ""
+	if focus != nil {
+		task = "Draw a diagram that illustrates this concept, such as its parts, the steps of a process, or causes and effects. Include related concepts only where they clarify it."
+		subject = fmt.Sprintf("Concept:\nTitle: %s\nDescription: %s\n\n", focus.Title, focus.Description)
+	}
+
+	userPrompt := fmt.Sprintf(`%s
+
+%sConcepts:
+%s
+Known relationships:
+%s
+Write the diagram in %s.
+- At most 15 nodes, with labels of a few words each
+- Prefer a top-down layout
+- Quote labels that contain punctuation
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{"title": "...", "source": "..."}`, task, subject, conceptsText.String(), relationsText, language)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate diagram: %w", err)
+	}
+
+	var data struct {
+		Title  string `json:"title"`
+		Source string `json:"source"`
+	}
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse diagram JSON: %w", err)
+	}
+
+	source := strings.TrimSpace(data.Source)
+	if source == "" {
+		return nil, fmt.Errorf("Claude returned an empty diagram")
+	}
+
+	conceptIDs := make([]int, 0, len(concepts)+1)
+	if focus != nil {
+		conceptIDs = append(conceptIDs, focus.ID)
+	}
+	for _, c := range concepts {
+		conceptIDs = append(conceptIDs, c.ID)
+	}
+
+	return &models.Diagram{
+		Format:     format,
+		Title:      data.Title,
+		Source:     source,
+		ConceptIDs: conceptIDs,
+	}, nil
+}
+
+// SuggestConceptRelationships asks Claude which of concepts are
+// prerequisites of, related to, or contradict one another, skipping pairs
+// already linked in existing
+func (s *ClaudeService) SuggestConceptRelationships(ctx context.Context, concepts []models.Concept, existing []models.ConceptRelationship) ([]models.RelationshipSuggestion, error) {
+	systemPrompt := promptFor("relationships", "You are an expert educator mapping how ideas depend on and relate to one another.")
+
+	var conceptsText strings.Builder
+	for _, c := range concepts {
+		conceptsText.WriteString(fmt.Sprintf("- [%d] %s: %s\n", c.ID, c.Title, c.Description))
+	}
+	existingText := "(none)\n"
+	if len(existing) > 0 {
+		var b strings.Builder
+		for _, r := range existing {
+			b.WriteString(fmt.Sprintf("- %d -> %d (%s)\n", r.FromConceptID, r.ToConceptID, r.RelationshipType))
+		}
+		existingText = b.String()
+	}
+
+	userPrompt := fmt.Sprintf(`Suggest relationships between these concepts (ID in brackets).
+
+Concepts:
+%s
+Already linked (skip these pairs):
+%s
+Relationship types:
+- prerequisite: the from concept must be understood before the to concept makes sense
+- related: the concepts are closely connected and worth studying together
+- contradicts: the concepts make claims or recommendations that conflict
+
+Only suggest relationships a learner would find useful, at most one per pair of concepts. Give a one-sentence rationale for each.
+
+Return ONLY a JSON array, no markdown formatting, no code blocks:
+[{"from_concept_id": 1, "to_concept_id": 2, "relationship_type": "prerequisite", "rationale": "..."}]`, conceptsText.String(), existingText)
+
+	// Send request to Claude, thinking first: dependencies between ideas
+	// reward careful reasoning
+	responseText, err := s.reasoningAPI().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest relationships: %w", err)
+	}
+
+	var data []models.RelationshipSuggestion
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse relationships JSON: %w", err)
+	}
+
+	// Drop suggestions naming other concepts, unknown types, or pairs that
+	// are already linked or suggested
+	titles := make(map[int]string, len(concepts))
+	for _, c := range concepts {
+		titles[c.ID] = c.Title
+	}
+	type pair struct{ a, b int }
+	key := func(a, b int) pair {
+		if a > b {
+			a, b = b, a
+		}
+		return pair{a, b}
+	}
+	linked := make(map[pair]bool, len(existing))
+	for _, r := range existing {
+		linked[key(r.FromConceptID, r.ToConceptID)] = true
+	}
+
+	suggestions := []models.RelationshipSuggestion{}
+	for _, sg := range data {
+		fromTitle, okFrom := titles[sg.FromConceptID]
+		toTitle, okTo := titles[sg.ToConceptID]
+		k := key(sg.FromConceptID, sg.ToConceptID)
+		if !okFrom || !okTo || sg.FromConceptID == sg.ToConceptID || linked[k] || !slices.Contains(models.RelationshipTypes, sg.RelationshipType) {
+			continue
+		}
+		linked[k] = true
+		sg.FromTitle = fromTitle
+		sg.ToTitle = toTitle
+		suggestions = append(suggestions, sg)
+	}
+
+	return suggestions, nil
+}
+
+// ExplainQuestion generates a deeper explanation of a quiz question, with an
+// analogy and a worked example, grounded in the concept and its source
+func (s *ClaudeService) ExplainQuestion(ctx context.Context, question models.QuizQuestion, concept models.Concept, source *models.SourceContent) (*models.QuizExplanation, error) {
+	systemPrompt := promptFor("explain_question", "You are a patient expert tutor who explains ideas with vivid analogies and concrete worked examples.")
+
+	sourceContext := "(no source available)"
+	if source != nil {
+		sourceContext = fmt.Sprintf("Title: %s\nExcerpt: %s", source.Title, transcriptExcerpt(source.Transcript, concept, 4000))
+	}
+
+	userPrompt := fmt.Sprintf(`A learner wants a deeper explanation of this quiz question.
+
+Concept:
+Title: %s
+Description: %s
+
+Question: %s
+A) %s
+B) %s
+C) %s
+D) %s
+Correct answer: %s
+Short explanation: %s
+
+Source:
+%s
+
+Provide:
+- Explanation: Why the correct answer is right, building intuition step by step (1-2 paragraphs)
+- Analogy: An everyday analogy that makes the idea click (2-4 sentences)
+- Worked example: A concrete scenario applying the concept from start to finish
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{"explanation": "...", "analogy": "...", "worked_example": "..."}`,
+		concept.Title, concept.Description,
+		question.Question, question.OptionA, question.OptionB, question.OptionC, question.OptionD,
+		question.CorrectAnswer, question.Explanation, sourceContext)
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate explanation: %w", err)
+	}
+
+	var data struct {
+		Explanation   string `json:"explanation"`
+		Analogy       string `json:"analogy"`
+		WorkedExample string `json:"worked_example"`
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse explanation JSON: %w", err)
+	}
+
+	return &models.QuizExplanation{
+		QuestionID:    question.ID,
+		Explanation:   data.Explanation,
+		Analogy:       data.Analogy,
+		WorkedExample: data.WorkedExample,
+	}, nil
+}
+
+// DiscussSource answers a question about one source, grounded only in its
+// transcript and concepts. history holds the earlier turns, oldest first.
+func (s *ClaudeService) DiscussSource(ctx context.Context, source models.SourceContent, concepts []models.Concept, history []models.ChatMessage, message string) (string, error) {
+	var conceptList strings.Builder
+	for _, c := range concepts {
+		conceptList.WriteString(fmt.Sprintf("- %s: %s\n", c.Title, c.Description))
+	}
+
+	transcript := source.Transcript
+	if len(transcript) > chatTranscriptMaxChars {
+		transcript = strings.ToValidUTF8(transcript[:chatTranscriptMaxChars], "") + "\n[transcript truncated]"
+	}
+
+	systemPrompt := fmt.Sprintf(`You are a knowledgeable tutor discussing a single video with a learner.
+
+Answer using only the video's transcript and concepts below. When the video doesn't cover something, say so plainly instead of guessing, then offer what the video does say that is closest. Quote short phrases from the transcript when they help. Keep answers concise.
+
+Video: %s
+
+Concepts:
+%s
+Transcript:
+%s`, source.Title, conceptList.String(), transcript)
+
+	messages := make([]llm.Message, 0, len(history)+1)
+	for _, m := range history {
+		messages = append(messages, llm.Message{Role: m.Role, Content: m.Content})
+	}
+	messages = append(messages, llm.Message{Role: models.ChatRoleUser, Content: message})
+
+	reply, err := s.api().SendConversation(ctx, systemPrompt, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to discuss source: %w", err)
+	}
+
+	return strings.TrimSpace(reply), nil
+}
+
+// CompareSources produces a structured comparison of two or more sources from
+// their concepts and transcripts. concepts is keyed by source content ID.
+func (s *ClaudeService) CompareSources(ctx context.Context, sources []models.SourceContent, concepts map[int][]models.Concept) (*models.SourceComparison, error) {
+	systemPrompt := promptFor("compare_sources", "You are a careful research analyst comparing how different sources treat the same topic.")
+
+	var sourceText strings.Builder
+	for _, source := range sources {
+		transcript := source.Transcript
+		if len(transcript) > compareTranscriptMaxChars {
+			transcript = strings.ToValidUTF8(transcript[:compareTranscriptMaxChars], "") + "\n[transcript truncated]"
+		}
+
+		sourceText.WriteString(fmt.Sprintf("=== Source %d: %s ===\nConcepts:\n", source.ID, source.Title))
+		for _, c := range concepts[source.ID] {
+			sourceText.WriteString(fmt.Sprintf("- %s: %s\n", c.Title, c.Description))
+		}
+		sourceText.WriteString(fmt.Sprintf("Transcript:\n%s\n\n", transcript))
+	}
+
+	userPrompt := fmt.Sprintf(`Compare these sources.
+
+Provide:
+- Summary: 2-3 sentences on how the sources relate overall
+- Shared concepts: Ideas covered by two or more sources, with the IDs of those sources and a note on how their treatment differs
+- Disagreements: Topics where sources take different positions, with each source's position in one sentence
+- Unique points: Important ideas only one source makes
+
+Refer to sources by their numeric ID only.
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{"summary": "...",
+ "shared_concepts": [{"topic": "...", "source_content_ids": [1, 2], "notes": "..."}],
+ "disagreements": [{"topic": "...", "positions": [{"source_content_id": 1, "position": "..."}]}],
+ "unique_points": [{"source_content_id": 1, "point": "..."}]}
+
+%s`, sourceText.String())
+
+	// Send request to Claude, thinking first: comparing positions across
+	// sources rewards careful reasoning
+	responseText, err := s.reasoningAPI().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare sources: %w", err)
+	}
+
+	var data struct {
+		Summary string `json:"summary"`
+		models.ComparisonDetails
+	}
+
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison JSON: %w", err)
+	}
+
+	// Drop anything attributed to a source that wasn't compared
+	compared := make(map[int]bool, len(sources))
+	ids := make(models.IntArray, len(sources))
+	for i, source := range sources {
+		compared[source.ID] = true
+		ids[i] = source.ID
+	}
+
+	details := models.ComparisonDetails{
+		SharedConcepts: []models.SharedConcept{},
+		Disagreements:  []models.Disagreement{},
+		UniquePoints:   []models.UniquePoint{},
+	}
+	for _, shared := range data.SharedConcepts {
+		var sourceIDs []int
+		for _, id := range shared.SourceContentIDs {
+			if compared[id] {
+				sourceIDs = append(sourceIDs, id)
+			}
+		}
+		if len(sourceIDs) >= 2 {
+			shared.SourceContentIDs = sourceIDs
+			details.SharedConcepts = append(details.SharedConcepts, shared)
+		}
+	}
+	for _, d := range data.Disagreements {
+		var positions []models.ComparisonPosition
+		for _, p := range d.Positions {
+			if compared[p.SourceContentID] {
+				positions = append(positions, p)
+			}
+		}
+		if len(positions) >= 2 {
+			d.Positions = positions
+			details.Disagreements = append(details.Disagreements, d)
+		}
+	}
+	for _, u := range data.UniquePoints {
+		if compared[u.SourceContentID] {
+			details.UniquePoints = append(details.UniquePoints, u)
+		}
+	}
+
+	return &models.SourceComparison{
+		SourceContentIDs: ids,
+		Summary:          strings.TrimSpace(data.Summary),
+		Details:          details,
+	}, nil
+}
+
+// ModerateContent reviews generated content against the concepts it was
+// generated from and their sources, flagging claims they don't support,
+// statistics they don't contain, and language that breaks the guidelines
+func (s *ClaudeService) ModerateContent(ctx context.Context, content models.GeneratedContent, concepts []models.Concept, sources []models.SourceContent, guidelines string) ([]models.ModerationFinding, error) {
+	systemPrompt := promptFor("moderate", "You are a meticulous editor checking marketing content for accuracy and brand safety before it's published.")
+
+	var sourceText strings.Builder
+	sourceText.WriteString("Concepts:\n")
+	for _, c := range concepts {
+		sourceText.WriteString(fmt.Sprintf("- %s: %s\n", c.Title, c.Description))
+	}
+	for _, source := range sources {
+		transcript := source.Transcript
+		if len(transcript) > moderateTranscriptMaxChars {
+			transcript = strings.ToValidUTF8(transcript[:moderateTranscriptMaxChars], "") + "\n[transcript truncated]"
+		}
+		sourceText.WriteString(fmt.Sprintf("\n=== Source: %s ===\n%s\n", source.Title, transcript))
+	}
+
+	if guidelines == "" {
+		guidelines = "Professional and accurate: no profanity, insults, hype the sources don't back, or guarantees of results."
+	}
+
+	userPrompt := fmt.Sprintf(`Check this %s content before it's published.
+
+Flag:
+- claim: A factual claim the concepts and sources don't support
+- statistic: A number, percentage, or figure that doesn't appear in the concepts or sources
+- language: Wording that breaks the brand guidelines
+
+Brand guidelines:
+%s
+
+Only flag real problems; paraphrases of what the sources say are fine. Quote each excerpt exactly as it appears in the content.
+
+Return ONLY a JSON array, no markdown formatting, no code blocks, empty if nothing needs flagging:
+[{"kind": "claim", "excerpt": "...", "message": "..."}]
+
+Content:
+Title: %s
+%s
+
+%s`, content.Platform, guidelines, content.Title, content.Body, sourceText.String())
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate content: %w", err)
+	}
+
+	var flagged []models.ModerationFinding
+	if err := llm.ParseJSONResponse(responseText, &flagged); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation JSON: %w", err)
+	}
+
+	// Keep findings of known kinds only
+	findings := []models.ModerationFinding{}
+	for _, f := range flagged {
+		switch f.Kind {
+		case models.FindingClaim, models.FindingStatistic, models.FindingLanguage:
+			f.Check = models.ModerationLLM
+			findings = append(findings, f)
+		}
+	}
+
+	return findings, nil
+}
+
+// ExtractClaims lists the factual claims in generated content, each quoted
+// exactly from its body
+func (s *ClaudeService) ExtractClaims(ctx context.Context, content models.GeneratedContent) ([]string, error) {
+	systemPrompt := promptFor("extract_claims", "You are a meticulous fact-checker preparing a post for verification.")
+
+	userPrompt := s.withInstructions(fmt.Sprintf(`List the factual claims in this %s post: statements of fact, figures, quotes, and things attributed to the speaker or the video. Skip opinions, advice, questions, and calls to action.
+
+Quote each claim exactly as it appears in the post, as a single sentence or clause.
+
+Return ONLY a JSON array of strings, no markdown formatting, no code blocks, empty if the post makes no factual claims:
+["..."]
+
+Post:
+%s`, content.Platform, content.Body))
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract claims: %w", err)
+	}
+
+	var claims []string
+	if err := llm.ParseJSONResponse(responseText, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse claims JSON: %w", err)
+	}
+
+	return claims, nil
+}
+
+// VerifyClaims judges each claim against the transcript passages retrieved
+// for it. passages[i] holds claim i's passages; the verdicts come back in the
+// same order.
+func (s *ClaudeService) VerifyClaims(ctx context.Context, claims []string, passages [][]models.TranscriptSegment) ([]models.FactCheckClaim, error) {
+	systemPrompt := promptFor("verify_claims", "You are a meticulous fact-checker verifying a post against the transcript of the video it's based on.")
+
+	var claimText strings.Builder
+	for i, claim := range claims {
+		claimText.WriteString(fmt.Sprintf("=== Claim %d ===\n%s\nTranscript passages:\n", i+1, claim))
+		if len(passages[i]) == 0 {
+			claimText.WriteString("(no matching passages)\n")
+		}
+		for _, segment := range passages[i] {
+			claimText.WriteString(fmt.Sprintf("[%d] %s\n", segment.Index, segment.Text))
+		}
+		claimText.WriteString("\n")
+	}
+
+	userPrompt := s.withInstructions(fmt.Sprintf(`Check each claim against its transcript passages.
+
+For each claim, give a verdict:
+- supported: The passages say this, or clearly imply it
+- unsupported: The passages don't say this
+- contradicted: The passages say otherwise
+
+Judge only by the passages, not by outside knowledge. Explain each verdict in one sentence, citing the passage numbers in brackets.
+
+Return ONLY a JSON array with one entry per claim, in order, no markdown formatting, no code blocks:
+[{"claim": 1, "verdict": "supported", "explanation": "..."}]
+
+%s`, claimText.String()))
+
+	// Send request to Claude, thinking first: weighing a claim against
+	// passages rewards careful reasoning
+	responseText, err := s.reasoningAPI().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify claims: %w", err)
+	}
+
+	var verdicts []struct {
+		Claim       int    `json:"claim"`
+		Verdict     string `json:"verdict"`
+		Explanation string `json:"explanation"`
+	}
+	if err := llm.ParseJSONResponse(responseText, &verdicts); err != nil {
+		return nil, fmt.Errorf("failed to parse verdicts JSON: %w", err)
+	}
+
+	// Claims Claude skipped or gave an unknown verdict count as unsupported
+	checked := make([]models.FactCheckClaim, len(claims))
+	for i, claim := range claims {
+		checked[i] = models.FactCheckClaim{
+			Claim:       claim,
+			Verdict:     models.VerdictUnsupported,
+			Explanation: "Not verified",
+			Segments:    []int{},
+		}
+		for _, segment := range passages[i] {
+			checked[i].Segments = append(checked[i].Segments, segment.Index)
+		}
+	}
+	for _, v := range verdicts {
+		if v.Claim < 1 || v.Claim > len(claims) {
+			continue
+		}
+		switch v.Verdict {
+		case models.VerdictSupported, models.VerdictUnsupported, models.VerdictContradicted:
+			checked[v.Claim-1].Verdict = v.Verdict
+			checked[v.Claim-1].Explanation = strings.TrimSpace(v.Explanation)
+		}
+	}
+
+	return checked, nil
+}
+
+// PlanContentSeries plans a series of posts on a topic's concepts over a number
+// of weeks. Each planned post covers one or more of the concepts on one of
+// platforms, in the order to publish them.
+func (s *ClaudeService) PlanContentSeries(ctx context.Context, topic string, concepts []models.Concept, posts, weeks int, platforms []string) (*models.ContentSeriesPlan, error) {
+	systemPrompt := promptFor("plan_series", "You are a content strategist planning an editorial calendar that teaches a topic step by step.")
+
+	var conceptsText strings.Builder
+	for i, c := range concepts {
+		conceptsText.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, c.Title, c.Description))
+	}
+	if lead := resonancePrompt(concepts); lead != "" {
+		conceptsText.WriteString("\n" + lead + "\n")
+	}
+
+	userPrompt := s.withInstructions(fmt.Sprintf(`Plan a series of %d posts, published over %d weeks, about "%s" using these concepts:
+
+%s
+For each post, give:
+- concepts: The numbers of the 1-3 concepts it covers
+- platform: One of %s
+- angle: One or two sentences on what the post says and how it builds on the posts before it
+
+Order the posts as they should be published: foundations before the ideas that depend on them. Cover every concept at least once when there are enough posts, and vary the platforms.
+
+Also give the series a title, and a one-sentence summary.
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{"title": "...", "summary": "...", "posts": [{"concepts": [1, 2], "platform": "...", "angle": "..."}]}`,
+		posts, weeks, topic, conceptsText.String(), strings.Join(platforms, ", ")))
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan content series: %w", err)
+	}
+
+	var data struct {
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+		Posts   []struct {
+			Concepts []int  `json:"concepts"`
+			Platform string `json:"platform"`
+			Angle    string `json:"angle"`
+		} `json:"posts"`
+	}
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse content series JSON: %w", err)
+	}
+
+	// Drop concept numbers out of range and posts left without concepts, and
+	// give posts on an unrequested platform the next requested one
+	plan := &models.ContentSeriesPlan{
+		Title:   strings.TrimSpace(data.Title),
+		Summary: strings.TrimSpace(data.Summary),
+		Posts:   []models.SeriesPostPlan{},
+	}
+	if plan.Title == "" {
+		plan.Title = topic
+	}
+	for _, p := range data.Posts {
+		post := models.SeriesPostPlan{Platform: p.Platform, Angle: strings.TrimSpace(p.Angle)}
+		for _, n := range p.Concepts {
+			if n >= 1 && n <= len(concepts) && !slices.Contains(post.ConceptIDs, concepts[n-1].ID) {
+				post.ConceptIDs = append(post.ConceptIDs, concepts[n-1].ID)
+			}
+		}
+		if len(post.ConceptIDs) == 0 {
+			continue
+		}
+		if !slices.Contains(platforms, post.Platform) {
+			post.Platform = platforms[len(plan.Posts)%len(platforms)]
+		}
+		plan.Posts = append(plan.Posts, post)
+		if len(plan.Posts) == posts {
+			break
+		}
+	}
+
+	return plan, nil
+}
+
 // GenerateContent generates marketing content from concepts
 func (s *ClaudeService) GenerateContent(ctx context.Context, platform string, concepts []models.Concept) (*models.GeneratedContent, error) {
 	// Build concept summary
@@ -177,9 +1427,16 @@ func (s *ClaudeService) GenerateContent(ctx context.Context, platform string, co
 
 	// Get platform-specific prompt
 	systemPrompt, userPrompt := s.getContentPrompts(platform, conceptsText.String())
+	userPrompt += "\n\n" + citationPrompt
+	if lead := resonancePrompt(concepts); lead != "" {
+		userPrompt += "\n\n" + lead
+	}
+	if s.targetGrade != nil {
+		userPrompt += "\n\n" + gradeLevelPrompt(*s.targetGrade)
+	}
 
 	// Send request to Claude
-	responseText, err := s.client.SendMessageWithSystem(ctx, systemPrompt, userPrompt)
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, s.withInstructions(userPrompt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
@@ -190,7 +1447,7 @@ func (s *ClaudeService) GenerateContent(ctx context.Context, platform string, co
 		Body  string `json:"body"`
 	}
 
-	if err := claude.ParseJSONResponse(responseText, &contentData); err != nil {
+	if err := llm.ParseJSONResponse(responseText, &contentData); err != nil {
 		// If JSON parsing fails, treat the whole response as body and generate a title
 		contentData.Body = responseText
 		contentData.Title = s.generateTitleFromConcepts(concepts)
@@ -202,20 +1459,94 @@ func (s *ClaudeService) GenerateContent(ctx context.Context, platform string, co
 		conceptIDs[i] = c.ID
 	}
 
+	// Move the citation markers out of the body
+	body, citations := extractCitations(contentData.Body, concepts)
+
 	return &models.GeneratedContent{
 		Platform:   platform,
 		Title:      contentData.Title,
-		Body:       contentData.Body,
+		Body:       body,
 		ConceptIDs: models.IntArray(conceptIDs),
+		Citations:  citations,
 		Status:     "draft",
 	}, nil
 }
 
+// RefreshContent rewrites a published post as a fresh variant for platform,
+// from the current versions of its concepts and the changes since it was
+// published, which the variant acknowledges
+func (s *ClaudeService) RefreshContent(ctx context.Context, original models.GeneratedContent, platform string, concepts []models.Concept, changes []string) (*models.GeneratedContent, error) {
+	systemPrompt := promptFor("refresh_content", "You are a consultant refreshing a post that performed well, so it's worth sharing again without repeating itself.")
+
+	var conceptsText strings.Builder
+	for i, c := range concepts {
+		conceptsText.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, c.Title, c.Description))
+	}
+
+	changesText := "Nothing in the concepts has changed; bring a fresh angle, hook, or example instead."
+	if len(changes) > 0 {
+		changesText = "- " + strings.Join(changes, "\n- ")
+	}
+
+	published := "earlier"
+	if original.PublishedAt != nil {
+		published = "on " + original.PublishedAt.Format("January 2, 2006")
+	}
+
+	userPrompt := s.withInstructions(fmt.Sprintf(`Write a refreshed %s version of this %s post, first published %s.
+
+Original title: %s
+Original post:
+%s
+
+The concepts it's based on, as they stand now:
+%s
+What changed since it was published:
+%s
+
+Keep what made the original work, but don't reuse its sentences. Briefly acknowledge that you've written about this before, and say what's new or what you now see differently.
+
+Return ONLY JSON, no markdown formatting, no code blocks:
+{"title": "...", "body": "..."}
+
+%s`, platform, original.Platform, published, original.Title, original.Body, conceptsText.String(), changesText, citationPrompt))
+
+	// Send request to Claude
+	responseText, err := s.api().SendJSONMessageWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh content: %w", err)
+	}
+
+	var data struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := llm.ParseJSONResponse(responseText, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse refreshed content JSON: %w", err)
+	}
+
+	conceptIDs := make([]int, len(concepts))
+	for i, c := range concepts {
+		conceptIDs[i] = c.ID
+	}
+	body, citations := extractCitations(data.Body, concepts)
+
+	return &models.GeneratedContent{
+		Platform:       platform,
+		Title:          strings.TrimSpace(data.Title),
+		Body:           body,
+		ConceptIDs:     models.IntArray(conceptIDs),
+		Citations:      citations,
+		Status:         models.ContentDraft,
+		RecycledFromID: &original.ID,
+	}, nil
+}
+
 // getContentPrompts returns platform-specific prompts
 func (s *ClaudeService) getContentPrompts(platform, conceptsText string) (systemPrompt, userPrompt string) {
 	switch platform {
 	case "linkedin":
-		systemPrompt = "You are a consultant writing a LinkedIn post demonstrating expertise to attract clients."
+		systemPrompt = promptFor("content_linkedin", "You are a consultant writing a LinkedIn post demonstrating expertise to attract clients.")
 		userPrompt = fmt.Sprintf(`Create a LinkedIn case study post using these concepts:
 
 %s
@@ -233,7 +1564,7 @@ Return as JSON:
 {"title": "...", "body": "..."}`, conceptsText)
 
 	case "twitter":
-		systemPrompt = "You are a consultant creating an engaging X (Twitter) thread to demonstrate expertise."
+		systemPrompt = promptFor("content_twitter", "You are a consultant creating an engaging X (Twitter) thread to demonstrate expertise.")
 		userPrompt = fmt.Sprintf(`Create a 5-tweet thread about these concepts:
 
 %s
@@ -251,7 +1582,7 @@ Return as JSON:
 {"title": "Thread title", "body": "1/\n[tweet 1]\n\n2/\n[tweet 2]\n\n..."}`, conceptsText)
 
 	case "blog":
-		systemPrompt = "You are a consultant writing an educational blog post to demonstrate deep expertise."
+		systemPrompt = promptFor("content_blog", "You are a consultant writing an educational blog post to demonstrate deep expertise.")
 		userPrompt = fmt.Sprintf(`Write a comprehensive blog post tutorial using these concepts:
 
 %s
@@ -273,7 +1604,7 @@ Return as JSON:
 
 	default:
 		// Generic email format
-		systemPrompt = "You are a consultant creating valuable content to share with your network."
+		systemPrompt = promptFor("content_email", "You are a consultant creating valuable content to share with your network.")
 		userPrompt = fmt.Sprintf(`Create an email newsletter about these concepts:
 
 %s
@@ -307,3 +1638,51 @@ func (s *ClaudeService) generateTitleFromConcepts(concepts []models.Concept) str
 	// Use first concept as base
 	return fmt.Sprintf("%s and More", concepts[0].Title)
 }
+
+// distractorRationales keeps only rationales for the incorrect options, keyed by uppercase letter
+func distractorRationales(raw map[string]string, correctAnswer string) models.StringMap {
+	rationales := models.StringMap{}
+	for letter, rationale := range raw {
+		letter = strings.ToUpper(strings.TrimSpace(letter))
+		if len(letter) == 1 && strings.Contains("ABCD", letter) && letter != correctAnswer && rationale != "" {
+			rationales[letter] = rationale
+		}
+	}
+	return rationales
+}
+
+// transcriptExcerpt returns up to maxChars of transcript centred on the first
+// mention of the concept's most distinctive title word, falling back to the start
+func transcriptExcerpt(transcript string, concept models.Concept, maxChars int) string {
+	if len(transcript) <= maxChars {
+		return transcript
+	}
+
+	lower := strings.ToLower(transcript)
+	center := -1
+	longest := ""
+	for _, word := range strings.Fields(strings.ToLower(concept.Title)) {
+		word = strings.Trim(word, ".,:;!?()\"'")
+		if len(word) > len(longest) {
+			if idx := strings.Index(lower, word); idx >= 0 {
+				longest = word
+				center = idx
+			}
+		}
+	}
+
+	start := 0
+	if center >= 0 {
+		start = center - maxChars/2
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxChars
+	if end > len(transcript) {
+		end = len(transcript)
+		start = end - maxChars
+	}
+
+	return strings.ToValidUTF8(transcript[start:end], "")
+}
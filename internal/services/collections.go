@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/query"
+)
+
+// maxCollectionConcepts bounds the concepts a review session or content
+// series draws from a collection, the newest first
+const maxCollectionConcepts = 500
+
+// ErrEmptyCollection is returned when a content series is planned from a
+// collection no active concept matches
+var ErrEmptyCollection = errors.New("no active concepts match the collection")
+
+// ValidateCollection checks that a collection's query parses and names only
+// concept fields, returning an error wrapping query.ErrInvalidQuery otherwise
+func ValidateCollection(req models.CollectionRequest) error {
+	n, err := query.Parse(req.Query)
+	if err != nil {
+		return err
+	}
+	return db.CheckConceptQuery(n)
+}
+
+// CollectionConcepts retrieves a collection and a page of the active concepts
+// owner can see matching it now, newest first, with days in dates taken in
+// loc. The returned cursor is nil on the last page.
+func CollectionConcepts(id int, loc *time.Location, owner *int, page models.Page) (*models.Collection, []models.Concept, *models.Cursor, error) {
+	collection, err := db.GetCollectionByID(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	n, err := query.Parse(collection.Query)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	concepts, next, err := db.QueryConcepts(n, loc, false, owner, page)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return collection, concepts, next, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// DefaultConceptDuplicateThreshold is the title similarity at or above which
+// two concepts are reported as likely duplicates
+const DefaultConceptDuplicateThreshold = 0.5
+
+// FindDuplicateConcepts groups the active concepts owner can see across all
+// sources into clusters of likely duplicates: concepts whose titles are at least threshold
+// similar, directly or through another member. Each cluster lists its
+// suggested merge target first (the concept with the most quizzes, then the
+// oldest) and the request that merges the rest into it.
+func FindDuplicateConcepts(threshold float64, owner *int) ([]models.DuplicateCluster, error) {
+	pairs, concepts, err := db.GetDuplicateConceptCandidates(threshold, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterDuplicates(pairs, concepts), nil
+}
+
+// clusterDuplicates joins similar pairs into clusters with union-find, most
+// similar cluster first
+func clusterDuplicates(pairs []models.ConceptPair, concepts map[int]models.DuplicateConcept) []models.DuplicateCluster {
+	parent := map[int]int{}
+	var find func(id int) int
+	find = func(id int) int {
+		p, ok := parent[id]
+		if !ok || p == id {
+			parent[id] = id
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+
+	for _, p := range pairs {
+		if a, b := find(p.ConceptID), find(p.OtherID); a != b {
+			parent[max(a, b)] = min(a, b)
+		}
+	}
+
+	members := map[int][]models.DuplicateConcept{}
+	similarity := map[int]float64{}
+	for _, p := range pairs {
+		root := find(p.ConceptID)
+		similarity[root] = max(similarity[root], p.Similarity)
+	}
+	for id := range parent {
+		if c, ok := concepts[id]; ok {
+			root := find(id)
+			members[root] = append(members[root], c)
+		}
+	}
+
+	clusters := make([]models.DuplicateCluster, 0, len(members))
+	for root, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].QuizCount != group[j].QuizCount {
+				return group[i].QuizCount > group[j].QuizCount
+			}
+			if !group[i].CreatedAt.Equal(group[j].CreatedAt) {
+				return group[i].CreatedAt.Before(group[j].CreatedAt)
+			}
+			return group[i].ID < group[j].ID
+		})
+
+		mergeIDs := make([]int, 0, len(group)-1)
+		for _, c := range group[1:] {
+			mergeIDs = append(mergeIDs, c.ID)
+		}
+
+		clusters = append(clusters, models.DuplicateCluster{
+			Concepts:   group,
+			Similarity: similarity[root],
+			Merge: models.ConceptMergePayload{
+				Method: "POST",
+				Path:   fmt.Sprintf("/api/concepts/%d/merge", group[0].ID),
+				Body:   models.MergeConceptsRequest{ConceptIDs: mergeIDs},
+			},
+		})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Similarity != clusters[j].Similarity {
+			return clusters[i].Similarity > clusters[j].Similarity
+		}
+		return clusters[i].Concepts[0].ID < clusters[j].Concepts[0].ID
+	})
+
+	return clusters
+}
+
+// MergeConcepts merges concepts into the target concept, archiving them, and
+// returns the target with every quiz it now has
+func MergeConcepts(targetID int, req models.MergeConceptsRequest) (*models.ConceptMergeResult, error) {
+	target, merged, err := db.MergeConcepts(targetID, req.ConceptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	quizzes, err := db.GetQuizzesByConceptID(target.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quizzes: %w", err)
+	}
+
+	return &models.ConceptMergeResult{
+		Concept: *target,
+		Merged:  merged,
+		Quizzes: quizzes,
+	}, nil
+}
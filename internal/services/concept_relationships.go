@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// SuggestRelationships asks Claude how a source's active concepts relate.
+// Nothing is saved; the client creates the suggestions it accepts.
+func (s *ConceptService) SuggestRelationships(ctx context.Context, sourceID int) ([]models.RelationshipSuggestion, error) {
+	if _, err := db.GetSourceContentSummaryByID(sourceID); err != nil {
+		return nil, err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concepts: %w", err)
+	}
+	if len(concepts) < 2 {
+		return nil, fmt.Errorf("source has fewer than two concepts")
+	}
+
+	existing, err := db.GetConceptRelationships(models.ConceptRelationshipQuery{SourceContentID: sourceID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.claudeService.SuggestConceptRelationships(ctx, concepts, existing)
+}
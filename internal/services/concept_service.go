@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ConceptService assembles concept data spread across several tables and
+// handles concept restructuring
+type ConceptService struct {
+	claudeService *ClaudeService
+}
+
+// NewConceptService creates a new concept service
+func NewConceptService() (*ConceptService, error) {
+	claudeService, err := NewClaudeService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Claude service: %w", err)
+	}
+
+	return &ConceptService{
+		claudeService: claudeService,
+	}, nil
+}
+
+// GetConceptDetail retrieves a concept with its quizzes, attempt summary, mastery,
+// related concepts, source metadata, and the generated content that used it
+func (s *ConceptService) GetConceptDetail(ctx context.Context, id int) (*models.ConceptDetail, error) {
+	concept, err := db.GetConceptByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.ConceptDetail{
+		Concept:          *concept,
+		Quizzes:          []models.QuizQuestion{},
+		RelatedConcepts:  []models.RelatedConcept{},
+		GeneratedContent: []models.GeneratedContent{},
+	}
+
+	quizzes, err := db.GetQuizzesByConceptID(id, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quizzes: %w", err)
+	}
+	if quizzes != nil {
+		detail.Quizzes = quizzes
+	}
+
+	attempts, err := db.GetAttemptSummaryByConceptID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt summary: %w", err)
+	}
+	detail.Attempts = *attempts
+
+	detail.Progress, err = db.GetLearningProgressByConceptID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learning progress: %w", err)
+	}
+
+	detail.RelatedConcepts, err = db.GetRelatedConcepts(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related concepts: %w", err)
+	}
+
+	if concept.SourceContentID != nil {
+		source, err := db.GetSourceContentSummaryByID(*concept.SourceContentID)
+		if err != nil {
+			// The concept can outlive its source, so a missing source is not fatal
+			log.Printf("Warning: Failed to get source for concept %d: %v", id, err)
+		} else {
+			detail.Source = source
+		}
+	}
+
+	content, err := db.GetGeneratedContentByConceptIDs([]int{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generated content: %w", err)
+	}
+	if content != nil {
+		detail.GeneratedContent = content
+	}
+
+	return detail, nil
+}
+
+// ProposeSplit asks Claude how a concept could be split into focused children.
+// Nothing is saved; the client reviews the proposal and confirms it with ConfirmSplit.
+func (s *ConceptService) ProposeSplit(ctx context.Context, id int) (*models.ConceptSplitProposal, error) {
+	concept, err := db.GetConceptByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	quizzes, err := db.GetQuizzesByConceptID(id, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quizzes: %w", err)
+	}
+	if quizzes == nil {
+		quizzes = []models.QuizQuestion{}
+	}
+
+	children, err := s.claudeService.ProposeConceptSplit(ctx, *concept, quizzes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ConceptSplitProposal{
+		Concept:  *concept,
+		Quizzes:  quizzes,
+		Children: children,
+	}, nil
+}
+
+// ConfirmSplit applies a (possibly edited) split proposal: it creates the
+// children, moves the assigned quiz questions, archives the original, and
+// optionally generates quizzes for children that received no questions
+func (s *ConceptService) ConfirmSplit(ctx context.Context, id int, req models.ConfirmConceptSplitRequest) (*models.ConceptSplitResult, error) {
+	children := make([]models.Concept, len(req.Children))
+	questionIDs := make([][]int, len(req.Children))
+	for i, child := range req.Children {
+		children[i] = models.Concept{
+			Title:       child.Title,
+			Description: child.Description,
+			Tags:        models.StringArray(child.Tags),
+		}
+		questionIDs[i] = child.QuestionIDs
+	}
+
+	original, created, err := db.SplitConcept(id, children, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	WakeEmbedder()
+
+	result := &models.ConceptSplitResult{
+		Original: *original,
+		Children: created,
+		Quizzes:  []models.QuizQuestion{},
+	}
+
+	for _, child := range created {
+		quizzes, err := db.GetQuizzesByConceptID(child.ID, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quizzes: %w", err)
+		}
+
+		if len(quizzes) == 0 && req.RegenerateQuizzes {
+			generated, err := s.claudeService.GenerateQuiz(ctx, child)
+			if err != nil {
+				log.Printf("Warning: Failed to generate quiz for concept %d: %v", child.ID, err)
+				continue
+			}
+
+			quizzes, err = db.CreateQuizBatch(dedupeQuestions(generated, nil))
+			if err != nil {
+				log.Printf("Warning: Failed to save quizzes for concept %d: %v", child.ID, err)
+				continue
+			}
+		}
+
+		result.Quizzes = append(result.Quizzes, quizzes...)
+	}
+
+	return result, nil
+}
+
+// GenerateMoreQuizzes generates and saves up to count additional quiz questions
+// for a concept that needs more drilling. Near-duplicates of its existing
+// questions are discarded and regenerated once; what's still missing after that
+// is left out.
+func (s *ConceptService) GenerateMoreQuizzes(ctx context.Context, id int, count int) ([]models.QuizQuestion, error) {
+	concept, err := db.GetConceptByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := db.GetQuizzesByConceptID(id, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quizzes: %w", err)
+	}
+
+	var kept []models.QuizQuestion
+	for attempt := 0; attempt < 2 && len(kept) < count; attempt++ {
+		known := append(slices.Clone(existing), kept...)
+
+		generated, err := s.claudeService.GenerateMoreQuiz(ctx, *concept, known, count-len(kept))
+		if err != nil {
+			if len(kept) > 0 {
+				log.Printf("Warning: Failed to regenerate duplicate questions for concept %d: %v", id, err)
+				break
+			}
+			return nil, err
+		}
+
+		kept = append(kept, dedupeQuestions(generated, known)...)
+	}
+	if len(kept) > count {
+		kept = kept[:count]
+	}
+	if len(kept) == 0 {
+		return []models.QuizQuestion{}, nil
+	}
+
+	return db.CreateQuizBatch(kept)
+}
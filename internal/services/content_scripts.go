@@ -0,0 +1,229 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Limits on WebAssembly content scripts. A module that runs out of time or
+// memory is stopped, and the content is left as it was.
+const (
+	maxScriptModuleSize = 8 << 20         // Bytes in an uploaded module
+	scriptMemoryPages   = 1024            // 64 MiB of linear memory
+	scriptTimeout       = 2 * time.Second // Per run, after compiling
+	maxScriptOutput     = 1 << 20         // Bytes a run may write to stdout
+)
+
+// scriptCompilationCache keeps compiled modules between runs, so a script
+// isn't compiled again for each piece of content
+var scriptCompilationCache = wazero.NewCompilationCache()
+
+// scriptContent is what a WebAssembly script reads from stdin and writes to
+// stdout
+type scriptContent struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// ValidateContentScript checks a script's platform, that it's either steps or
+// a WebAssembly module, and that each step has the fields its op needs. Regex
+// patterns must compile as RE2, which runs in linear time, so scripts can't
+// hang content generation. Modules must compile and stay under
+// maxScriptModuleSize.
+func ValidateContentScript(ctx context.Context, req models.ContentScriptRequest) error {
+	if req.Platform != nil && !slices.Contains(models.ContentPlatforms, *req.Platform) {
+		return fmt.Errorf("unknown platform %q", *req.Platform)
+	}
+
+	switch {
+	case len(req.Steps) > 0 && len(req.Wasm) > 0:
+		return fmt.Errorf("a script has steps or a wasm module, not both")
+	case len(req.Wasm) > maxScriptModuleSize:
+		return fmt.Errorf("wasm module must be at most %d MiB", maxScriptModuleSize>>20)
+	case len(req.Wasm) > 0:
+		runtime := newScriptRuntime(ctx)
+		defer runtime.Close(ctx)
+		if _, err := runtime.CompileModule(ctx, req.Wasm); err != nil {
+			return fmt.Errorf("invalid wasm module: %w", err)
+		}
+		return nil
+	case len(req.Steps) == 0:
+		return fmt.Errorf("a script needs steps or a wasm module")
+	}
+
+	for i, step := range req.Steps {
+		switch step.Op {
+		case models.ScriptOpReplace:
+			if step.Find == "" {
+				return fmt.Errorf("step %d: replace needs find", i+1)
+			}
+		case models.ScriptOpRegexReplace:
+			if _, err := regexp.Compile(step.Find); err != nil {
+				return fmt.Errorf("step %d: invalid pattern: %w", i+1, err)
+			}
+		case models.ScriptOpPrepend, models.ScriptOpAppend:
+			if step.Text == "" {
+				return fmt.Errorf("step %d: %s needs text", i+1, step.Op)
+			}
+		case models.ScriptOpTruncate:
+			if step.MaxLength <= 0 {
+				return fmt.Errorf("step %d: truncate needs a positive max_length", i+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunContentScript applies a script to a title and body: its steps in order,
+// or its WebAssembly module. A module that fails leaves both unchanged and
+// returns the error.
+func RunContentScript(ctx context.Context, script models.ContentScript, title, body string) (string, string, error) {
+	if len(script.Wasm) > 0 {
+		return runScriptModule(ctx, script.Wasm, title, body)
+	}
+
+	for _, step := range script.Steps {
+		field := &body
+		if step.Field == "title" {
+			field = &title
+		}
+
+		switch step.Op {
+		case models.ScriptOpReplace:
+			*field = strings.ReplaceAll(*field, step.Find, step.Replace)
+		case models.ScriptOpRegexReplace:
+			re, err := regexp.Compile(step.Find)
+			if err != nil {
+				log.Printf("Warning: Skipping invalid pattern in content script %s: %v", script.Name, err)
+				continue
+			}
+			*field = re.ReplaceAllString(*field, step.Replace)
+		case models.ScriptOpPrepend:
+			*field = step.Text + *field
+		case models.ScriptOpAppend:
+			*field += step.Text
+		case models.ScriptOpTruncate:
+			if runes := []rune(*field); len(runes) > step.MaxLength {
+				*field = strings.TrimRightFunc(string(runes[:step.MaxLength]), unicode.IsSpace)
+			}
+		}
+	}
+
+	return title, body, nil
+}
+
+// newScriptRuntime returns a runtime for WebAssembly scripts, with WASI
+// instantiated and the memory and time limits above. Close it after use.
+func newScriptRuntime(ctx context.Context) wazero.Runtime {
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(scriptMemoryPages).
+		WithCloseOnContextDone(true). // Stops modules that loop past scriptTimeout
+		WithCompilationCache(scriptCompilationCache)
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+	return runtime
+}
+
+// runScriptModule runs a WASI command module that reads a JSON
+// {"title", "body"} from stdin and writes the transformed one to stdout. It
+// sees no files, environment, arguments, or network, and its clock and
+// random numbers aren't the host's.
+func runScriptModule(ctx context.Context, module []byte, title, body string) (string, string, error) {
+	runtime := newScriptRuntime(ctx)
+	defer runtime.Close(context.Background())
+
+	// Compiling takes time in the module's size, which is capped, and is
+	// cached for the next run
+	compiled, err := runtime.CompileModule(ctx, module)
+	if err != nil {
+		return title, body, fmt.Errorf("invalid wasm module: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+
+	input, err := json.Marshal(scriptContent{Title: title, Body: body})
+	if err != nil {
+		return title, body, err
+	}
+	stdout := &limitedBuffer{limit: maxScriptOutput}
+	config := wazero.NewModuleConfig().
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(io.Discard)
+
+	_, err = runtime.InstantiateModule(ctx, compiled, config)
+	var exitErr *sys.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return title, body, fmt.Errorf("script ran longer than %s", scriptTimeout)
+	case errors.As(err, &exitErr) && exitErr.ExitCode() != 0:
+		return title, body, fmt.Errorf("script exited with code %d", exitErr.ExitCode())
+	case err != nil && !errors.As(err, &exitErr):
+		return title, body, fmt.Errorf("script failed: %w", err)
+	case stdout.overflowed:
+		return title, body, fmt.Errorf("script wrote more than %d KiB", maxScriptOutput>>10)
+	}
+
+	var output scriptContent
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return title, body, fmt.Errorf("script output isn't a JSON title and body: %w", err)
+	}
+	return output.Title, output.Body, nil
+}
+
+// limitedBuffer is a buffer that refuses writes past limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit      int
+	overflowed bool
+}
+
+// Write implements io.Writer
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		b.overflowed = true
+		return 0, fmt.Errorf("output limit exceeded")
+	}
+	return b.Buffer.Write(p)
+}
+
+// applyContentScripts runs the enabled scripts for each piece's platform over
+// it. Content is left unchanged when scripts can't be loaded, and by a script
+// that fails.
+func applyContentScripts(ctx context.Context, contents []models.GeneratedContent) {
+	for i := range contents {
+		platform := contents[i].Platform
+		scripts, err := db.GetContentScripts(&platform)
+		if err != nil {
+			log.Printf("Warning: Failed to load content scripts for %s: %v", platform, err)
+			continue
+		}
+
+		for _, script := range scripts {
+			title, body, err := RunContentScript(ctx, script, contents[i].Title, contents[i].Body)
+			if err != nil {
+				log.Printf("Warning: Content script %s failed on %s content: %v", script.Name, platform, err)
+				continue
+			}
+			contents[i].Title, contents[i].Body = title, body
+		}
+	}
+}
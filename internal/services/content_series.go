@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ErrNoTaggedConcepts is returned when a content series is planned for a tag no active concept has
+var ErrNoTaggedConcepts = errors.New("no active concepts have that tag")
+
+// seriesPublishHour is the local hour of day series posts are scheduled for
+const seriesPublishHour = 9
+
+// PlanContentSeries plans a series of posts on the concepts with req.Tag or in
+// req.CollectionID, generates a draft of each, and stores them scheduled
+// evenly over req.Weeks from req.StartsOn, at 9:00 in loc. Posts that fail to
+// generate are left out and listed in the series' warnings.
+func (s *SourceContentService) PlanContentSeries(ctx context.Context, req models.PlanContentSeriesRequest, loc *time.Location) (*models.ContentSeries, error) {
+	concepts, topic, err := seriesConcepts(req, loc)
+	if err != nil {
+		return nil, err
+	}
+	AttachResonance(concepts)
+
+	platforms := req.Platforms
+	if len(platforms) == 0 {
+		platforms = CurrentSettings().DefaultPlatforms
+	}
+
+	start := endOfDay(time.Now(), loc)
+	if req.StartsOn != "" {
+		if start, err = time.ParseInLocation(time.DateOnly, req.StartsOn, loc); err != nil {
+			return nil, fmt.Errorf("invalid starts_on: %w", err)
+		}
+	}
+
+	plan, err := s.claudeService.PlanContentSeries(ctx, topic, concepts, req.Posts, req.Weeks, platforms)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Posts) == 0 {
+		return nil, fmt.Errorf("failed to plan content series: the plan has no posts")
+	}
+
+	byID := make(map[int]models.Concept, len(concepts))
+	for _, c := range concepts {
+		byID[c.ID] = c
+	}
+
+	log.Printf("Generating %d posts for content series %q...", len(plan.Posts), plan.Title)
+	schedule := seriesSchedule(start, len(plan.Posts), req.Weeks)
+	var posts []models.GeneratedContent
+	var warnings []models.StageWarning
+	for i, post := range plan.Posts {
+		postConcepts := make([]models.Concept, len(post.ConceptIDs))
+		for j, id := range post.ConceptIDs {
+			postConcepts[j] = byID[id]
+		}
+
+		stage := models.PipelineStage{Name: models.StageContent, Instructions: seriesPostInstructions(*plan, i)}
+		content, err := s.claudeService.forStage(stage).GenerateContent(ctx, post.Platform, postConcepts)
+		if err != nil {
+			log.Printf("Warning: Failed to generate post %d of content series %q: %v", i+1, plan.Title, err)
+			warnings = append(warnings, models.StageWarning{
+				Stage:    models.StageContent,
+				Platform: post.Platform,
+				Message:  fmt.Sprintf("failed to generate post %d (%s): %v", i+1, post.Platform, err),
+			})
+			continue
+		}
+		content.ScheduledFor = &schedule[i]
+		posts = append(posts, *content)
+	}
+	if len(posts) == 0 {
+		return nil, errors.New(warnings[0].Message)
+	}
+	finishContent(ctx, posts, nil)
+
+	var tag *string
+	if req.CollectionID == nil {
+		tag = &req.Tag
+	}
+	series, err := db.CreateContentSeries(models.ContentSeries{
+		Tag:          tag,
+		CollectionID: req.CollectionID,
+		Title:        plan.Title,
+		Summary:      plan.Summary,
+		Weeks:        req.Weeks,
+	}, posts)
+	if err != nil {
+		return nil, err
+	}
+	series.Warnings = warnings
+
+	return series, nil
+}
+
+// seriesConcepts returns the active concepts a series is planned from, those
+// with req.Tag or in req.CollectionID, and the topic to plan it on
+func seriesConcepts(req models.PlanContentSeriesRequest, loc *time.Location) ([]models.Concept, string, error) {
+	if req.CollectionID != nil {
+		collection, concepts, _, err := CollectionConcepts(*req.CollectionID, loc, req.OwnerID, models.Page{Limit: maxCollectionConcepts})
+		if err != nil {
+			return nil, "", err
+		}
+		if len(concepts) == 0 {
+			return nil, "", ErrEmptyCollection
+		}
+		return concepts, collection.Name, nil
+	}
+
+	concepts, err := db.GetConceptsByTag(req.Tag, req.OwnerID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(concepts) == 0 {
+		return nil, "", ErrNoTaggedConcepts
+	}
+	return concepts, req.Tag, nil
+}
+
+// seriesSchedule spreads count posts evenly over weeks from start's date, one
+// a day at most, each at seriesPublishHour in start's location
+func seriesSchedule(start time.Time, count, weeks int) []time.Time {
+	days := weeks * 7
+	schedule := make([]time.Time, count)
+	for i := range schedule {
+		day := i * days / count
+		schedule[i] = time.Date(start.Year(), start.Month(), start.Day()+day, seriesPublishHour, 0, 0, 0, start.Location()).UTC()
+	}
+	return schedule
+}
+
+// seriesPostInstructions places post i in its series, for its prompt
+func seriesPostInstructions(plan models.ContentSeriesPlan, i int) string {
+	instructions := fmt.Sprintf("This is post %d of %d in the series %q.", i+1, len(plan.Posts), plan.Title)
+	if plan.Summary != "" {
+		instructions += " " + plan.Summary
+	}
+	if angle := plan.Posts[i].Angle; angle != "" {
+		instructions += "\nThis post's angle: " + angle
+	}
+	return instructions
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+)
+
+// defaultCredentialName names a provider's credential when a request gives none
+const defaultCredentialName = "default"
+
+// credentialAAD binds a ciphertext to its provider and name, so a sealed
+// secret copied onto another row won't decrypt
+func credentialAAD(provider, name string) string {
+	return provider + "/" + name
+}
+
+// keyring returns the loaded master keyring, or an error when none is configured
+func keyring() (*secrets.Keyring, error) {
+	if secrets.Default == nil {
+		return nil, secrets.ErrNotConfigured
+	}
+	return secrets.Default, nil
+}
+
+// CreateCredential encrypts and stores an integration token
+func CreateCredential(req models.CreateCredentialRequest) (*models.Credential, error) {
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultCredentialName
+	}
+
+	ciphertext, keyID, err := k.Seal(req.Secret, credentialAAD(req.Provider, name))
+	if err != nil {
+		return nil, err
+	}
+
+	return db.CreateCredential(req.Provider, name, ciphertext, keyID)
+}
+
+// ReplaceCredentialSecret encrypts and stores a new secret for a credential
+func ReplaceCredentialSecret(id int, secret string) (*models.Credential, error) {
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := db.GetCredentialByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, keyID, err := k.Seal(secret, credentialAAD(credential.Provider, credential.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return db.UpdateCredentialSecret(id, ciphertext, keyID, false)
+}
+
+// CredentialSecret decrypts a provider's token for use by an integration. An
+// empty name means the provider's default credential.
+func CredentialSecret(provider, name string) (string, error) {
+	k, err := keyring()
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		name = defaultCredentialName
+	}
+
+	credential, err := db.GetCredentialByProvider(provider, name)
+	if err != nil {
+		return "", err
+	}
+
+	return k.Open(credential.Ciphertext, credential.KeyID, credentialAAD(credential.Provider, credential.Name))
+}
+
+// RotateCredentials re-encrypts every credential not sealed under the current
+// master key. Run it after moving the old key to SECRETS_PREVIOUS_KEYS; once
+// it succeeds, the old key can be dropped.
+func RotateCredentials() (*models.RotateCredentialsResult, error) {
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := db.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RotateCredentialsResult{KeyID: k.CurrentKeyID()}
+	for _, credential := range credentials {
+		if credential.KeyID == k.CurrentKeyID() {
+			continue
+		}
+
+		aad := credentialAAD(credential.Provider, credential.Name)
+		secret, err := k.Open(credential.Ciphertext, credential.KeyID, aad)
+		if err != nil {
+			return result, fmt.Errorf("failed to rotate credential %d: %w", credential.ID, err)
+		}
+
+		ciphertext, keyID, err := k.Seal(secret, aad)
+		if err != nil {
+			return result, err
+		}
+
+		if _, err := db.UpdateCredentialSecret(credential.ID, ciphertext, keyID, true); err != nil {
+			return result, err
+		}
+		result.Rotated++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/decks"
+)
+
+var (
+	// ErrImportFormatRequired is returned for imports whose format can't be
+	// told from the file name
+	ErrImportFormatRequired = errors.New("format is required unless the file is an .apkg: anki, logseq, or remnote")
+
+	// ErrImportTooLarge is returned for imports over MaxImportUpload
+	ErrImportTooLarge = errors.New("import file is larger than 100 MB")
+)
+
+const (
+	// MaxImportUpload bounds imported deck files, in bytes
+	MaxImportUpload = 100 << 20
+
+	// importOptionChars is the longest answer used as a multiple-choice option
+	importOptionChars = 200
+
+	// maxConceptTitle is the longest concept title, in characters
+	maxConceptTitle = 255
+)
+
+// ImportDeck imports the flashcards of an Anki, Logseq, or RemNote export as
+// a source of type import. Each card becomes a concept, described by its
+// answer, with the card as its flashcard. Where the deck has at least three
+// other short answers, the card also gets a multiple-choice question whose
+// distractors are the answers closest in length. Importing the same file
+// again returns the existing source.
+func ImportDeck(ctx context.Context, filename string, data []byte, req models.ImportDeckRequest) (*models.ImportResult, error) {
+	if len(data) > MaxImportUpload {
+		return nil, ErrImportTooLarge
+	}
+
+	format := req.Format
+	if format == "" {
+		if !strings.EqualFold(filepath.Ext(filename), ".apkg") {
+			return nil, ErrImportFormatRequired
+		}
+		format = models.ImportFormatAnki
+	}
+
+	sum := sha256.Sum256(data)
+	url := "import://" + hex.EncodeToString(sum[:])
+
+	existing, err := db.GetSourceContentByURL(url, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if existing != nil {
+		log.Printf("Deck already imported as source content ID: %d", existing.ID)
+		return &models.ImportResult{SourceContent: existing, Duplicate: true}, nil
+	}
+
+	var cards []decks.Card
+	switch format {
+	case models.ImportFormatAnki:
+		cards, err = decks.ParseAnki(ctx, data)
+	case models.ImportFormatLogseq:
+		cards, err = decks.ParseLogseq(filename, data)
+	case models.ImportFormatRemNote:
+		cards, err = decks.ParseRemNote(filename, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	title := req.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	log.Printf("Importing %d %s cards from %s", len(cards), format, filename)
+
+	var transcript strings.Builder
+	for _, card := range cards {
+		fmt.Fprintf(&transcript, "Q: %s\nA: %s\n\n", card.Front, card.Back)
+	}
+
+	source := models.CreateSourceContentRequest{
+		Type:       "import",
+		URL:        url,
+		Title:      title,
+		Transcript: strings.TrimSpace(transcript.String()),
+		OwnerID:    req.OwnerID,
+	}
+	result, err := db.CreateImportedDeck(source, importedCards(cards, req.Tags))
+	if err != nil {
+		return nil, err
+	}
+	WakeEmbedder()
+	return result, nil
+}
+
+// importedCards converts cards to concepts tagged with tags, their own tags,
+// and their deck, with multiple-choice questions where there are distractors
+func importedCards(cards []decks.Card, tags []string) []models.ImportedCard {
+	// Short single-line answers can be offered as options, ordered by length
+	// so each answer's nearest in length are beside it
+	var options []string
+	seen := map[string]bool{}
+	for _, card := range cards {
+		if utf8.RuneCountInString(card.Back) <= importOptionChars && !strings.Contains(card.Back, "\n") && !seen[card.Back] {
+			seen[card.Back] = true
+			options = append(options, card.Back)
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return utf8.RuneCountInString(options[i]) < utf8.RuneCountInString(options[j])
+	})
+	optionIndex := make(map[string]int, len(options))
+	for i, option := range options {
+		optionIndex[option] = i
+	}
+
+	imported := make([]models.ImportedCard, 0, len(cards))
+	for _, card := range cards {
+		conceptTags := append(append([]string{}, tags...), card.Tags...)
+		if deck := deckLeaf(card.Deck); deck != "" && !strings.EqualFold(deck, "default") {
+			conceptTags = append(conceptTags, deck)
+		}
+
+		title, description := card.Front, card.Back
+		if utf8.RuneCountInString(title) > maxConceptTitle {
+			title = string([]rune(title)[:maxConceptTitle-3]) + "..."
+			description = card.Front + "\n\n" + card.Back
+		}
+
+		var question *models.QuizQuestion
+		if index, ok := optionIndex[card.Back]; ok && len(options) >= 4 {
+			question = importedQuestion(card, options, index)
+		}
+
+		imported = append(imported, models.ImportedCard{
+			Title:       title,
+			Description: description,
+			Tags:        uniqueTags(conceptTags),
+			Front:       card.Front,
+			Back:        card.Back,
+			Question:    question,
+			AnkiNoteID:  card.AnkiNoteID,
+		})
+	}
+	return imported
+}
+
+// importedQuestion makes a multiple-choice question of a card whose answer
+// is options[index], with the three options nearest it in length as
+// distractors. options holds at least four, ordered by length.
+func importedQuestion(card decks.Card, options []string, index int) *models.QuizQuestion {
+	start := min(max(index-1, 0), len(options)-4)
+	distractors := make([]string, 0, 3)
+	for _, option := range options[start : start+4] {
+		if option != card.Back {
+			distractors = append(distractors, option)
+		}
+	}
+
+	// The answer's position follows from the question, so it's the same on
+	// every import of the card
+	h := fnv.New32a()
+	h.Write([]byte(card.Front))
+	correct := int(h.Sum32() % 4)
+	choices := append(distractors[:correct:correct], append([]string{card.Back}, distractors[correct:]...)...)
+
+	return &models.QuizQuestion{
+		Question:      card.Front,
+		OptionA:       choices[0],
+		OptionB:       choices[1],
+		OptionC:       choices[2],
+		OptionD:       choices[3],
+		CorrectAnswer: string(rune('A' + correct)),
+	}
+}
+
+// deckLeaf returns the innermost deck of a nested deck name, Anki's parts
+// separated by "::" and Logseq's and RemNote's by "/"
+func deckLeaf(deck string) string {
+	if i := strings.LastIndex(deck, "::"); i >= 0 {
+		deck = deck[i+2:]
+	}
+	if i := strings.LastIndex(deck, "/"); i >= 0 {
+		deck = deck[i+1:]
+	}
+	return strings.TrimSpace(deck)
+}
+
+// uniqueTags lowercases tags, dropping blanks and repeats
+func uniqueTags(tags []string) []string {
+	unique := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
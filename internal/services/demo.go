@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/llm"
+)
+
+// DemoConfig limits the public demo pipeline
+type DemoConfig struct {
+	RunsPerDay  int           // Per client IP, over a rolling 24 hours
+	MaxDuration time.Duration // Longest video accepted
+	MaxTokens   int           // Claude tokens (input plus output) per run
+}
+
+// LoadDemoConfig reads the demo settings. Returns nil without an error unless
+// DEMO_MODE=true.
+func LoadDemoConfig() (*DemoConfig, error) {
+	if os.Getenv("DEMO_MODE") != "true" {
+		return nil, nil
+	}
+
+	config := &DemoConfig{
+		RunsPerDay:  1,
+		MaxDuration: 15 * time.Minute,
+		MaxTokens:   40000,
+	}
+
+	if str := os.Getenv("DEMO_RUNS_PER_DAY"); str != "" {
+		runs, err := strconv.Atoi(str)
+		if err != nil || runs < 1 {
+			return nil, fmt.Errorf("invalid DEMO_RUNS_PER_DAY: must be a positive number")
+		}
+		config.RunsPerDay = runs
+	}
+
+	if str := os.Getenv("DEMO_MAX_DURATION"); str != "" {
+		duration, err := time.ParseDuration(str)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid DEMO_MAX_DURATION: must be a positive duration")
+		}
+		config.MaxDuration = duration
+	}
+
+	if str := os.Getenv("DEMO_MAX_TOKENS"); str != "" {
+		tokens, err := strconv.Atoi(str)
+		if err != nil || tokens < 1 {
+			return nil, fmt.Errorf("invalid DEMO_MAX_TOKENS: must be a positive number")
+		}
+		config.MaxTokens = tokens
+	}
+
+	return config, nil
+}
+
+// DemoPipelineSpec is the reduced pipeline demo runs get: concepts, a couple of
+// questions each, and a glossary. Nothing is published or generated for other
+// platforms.
+func DemoPipelineSpec() models.PipelineSpec {
+	return models.PipelineSpec{Stages: []models.PipelineStage{
+		{Name: models.StageConcepts},
+		{Name: models.StageQuizzes, Questions: &models.QuestionCount{Min: 1, Max: 2}},
+		{Name: models.StageGlossary},
+	}}
+}
+
+// demoConceptsMax caps concepts extracted in a demo run
+const demoConceptsMax = 5
+
+// ProcessDemoURL runs the demo pipeline for a video within config's duration
+// and token caps, returning the result and the tokens it used. Quotas are the
+// caller's job (see db.ClaimDemoRun). Stages after the budget runs out are
+// skipped like any failed stage, so the result may be partial.
+func (s *SourceContentService) ProcessDemoURL(ctx context.Context, url string, config DemoConfig) (*ProcessResult, int, error) {
+	log.Printf("Processing demo URL: %s", url)
+
+	existing, err := db.GetSourceContentByURL(url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+
+	if existing != nil {
+		result, err := s.getExistingProcessResult(ctx, existing)
+		return result, 0, err
+	}
+
+	budget := llm.NewTokenBudget(config.MaxTokens)
+	demo := *s
+	demo.claudeService = s.claudeService.withTokenBudget(budget)
+	demo.claudeService.conceptsCap = demoConceptsMax
+
+	result, err := demo.processNewVideo(ctx, url, DemoPipelineSpec(), config.MaxDuration)
+	return result, budget.Used(), err
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// diagramRenderTimeout bounds rendering a diagram to SVG; mmdc starts a
+// headless browser, so it takes a few seconds
+const diagramRenderTimeout = 30 * time.Second
+
+// ErrRendererNotFound is returned when rendering is requested but the
+// format's renderer (dot or mmdc) isn't installed
+var ErrRendererNotFound = errors.New("diagram renderer not installed")
+
+// dotFileAttribute matches Graphviz attributes that read local files
+var dotFileAttribute = regexp.MustCompile(`(?i)\b(image|imagepath|shapefile|fontpath)\s*=`)
+
+// GenerateConceptDiagram has Claude diagram a concept, with its related
+// concepts as context, and renders it to SVG when req asks
+func (s *ConceptService) GenerateConceptDiagram(ctx context.Context, id int, req models.GenerateDiagramRequest) (*models.Diagram, error) {
+	concept, err := db.GetConceptByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := db.GetRelatedConcepts(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related concepts: %w", err)
+	}
+
+	concepts := make([]models.Concept, 0, len(related))
+	relations := make([]string, 0, len(related))
+	for _, r := range related {
+		concepts = append(concepts, r.Concept)
+		if r.Direction == "outgoing" {
+			relations = append(relations, fmt.Sprintf("%s -> %s (%s)", concept.Title, r.Title, r.RelationshipType))
+		} else {
+			relations = append(relations, fmt.Sprintf("%s -> %s (%s)", r.Title, concept.Title, r.RelationshipType))
+		}
+	}
+
+	return s.generateDiagram(ctx, req, concept, concepts, relations)
+}
+
+// GenerateSourceDiagram has Claude map how a source's concepts relate, and
+// renders it to SVG when req asks
+func (s *ConceptService) GenerateSourceDiagram(ctx context.Context, sourceID int, req models.GenerateDiagramRequest) (*models.Diagram, error) {
+	if _, err := db.GetSourceContentSummaryByID(sourceID); err != nil {
+		return nil, err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concepts: %w", err)
+	}
+	if len(concepts) == 0 {
+		return nil, fmt.Errorf("source has no concepts")
+	}
+
+	// Relationships recorded between two of the source's concepts
+	titles := make(map[int]string, len(concepts))
+	for _, c := range concepts {
+		titles[c.ID] = c.Title
+	}
+	var relations []string
+	for _, c := range concepts {
+		related, err := db.GetRelatedConcepts(c.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get related concepts: %w", err)
+		}
+		for _, r := range related {
+			if _, ok := titles[r.ID]; ok && r.Direction == "outgoing" {
+				relations = append(relations, fmt.Sprintf("%s -> %s (%s)", c.Title, r.Title, r.RelationshipType))
+			}
+		}
+	}
+
+	return s.generateDiagram(ctx, req, nil, concepts, relations)
+}
+
+// generateDiagram asks Claude for the diagram in req's format and renders it
+// when req asks
+func (s *ConceptService) generateDiagram(ctx context.Context, req models.GenerateDiagramRequest, focus *models.Concept, concepts []models.Concept, relations []string) (*models.Diagram, error) {
+	format := req.Format
+	if format == "" {
+		format = models.DiagramMermaid
+	}
+
+	diagram, err := s.claudeService.GenerateDiagram(ctx, format, focus, concepts, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Render {
+		// A diagram that won't render is still returned, since its source
+		// can be fixed by hand
+		svg, err := RenderDiagram(ctx, format, diagram.Source)
+		switch {
+		case errors.Is(err, ErrRendererNotFound):
+			return nil, err
+		case err != nil:
+			diagram.RenderError = err.Error()
+		default:
+			diagram.SVG = string(svg)
+		}
+	}
+
+	return diagram, nil
+}
+
+// RenderDiagram renders diagram source to SVG with Graphviz's dot or the
+// Mermaid CLI (mmdc), found on PATH unless GRAPHVIZ_PATH or MERMAID_CLI_PATH
+// is set
+func RenderDiagram(ctx context.Context, format, source string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, diagramRenderTimeout)
+	defer cancel()
+
+	if format == models.DiagramGraphviz {
+		// Claude was asked not to use these, but its input includes transcripts
+		if dotFileAttribute.MatchString(source) {
+			return nil, fmt.Errorf("diagram uses attributes that read files")
+		}
+
+		path, err := rendererPath("GRAPHVIZ_PATH", "dot")
+		if err != nil {
+			return nil, err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path, "-Tsvg")
+		cmd.Stdin = bytes.NewBufferString(source)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to render diagram: %w: %s", err, stderr.String())
+		}
+		return stdout.Bytes(), nil
+	}
+
+	path, err := rendererPath("MERMAID_CLI_PATH", "mmdc")
+	if err != nil {
+		return nil, err
+	}
+
+	// mmdc reads and writes files
+	dir, err := os.MkdirTemp("", "lattice-diagram-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "diagram.mmd")
+	output := filepath.Join(dir, "diagram.svg")
+	if err := os.WriteFile(input, []byte(source), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write diagram: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, path, "--quiet", "--input", input, "--output", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to render diagram: %w: %s", err, out)
+	}
+
+	svg, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered diagram: %w", err)
+	}
+	return svg, nil
+}
+
+// rendererPath returns the renderer binary set by env, or name from PATH
+func rendererPath(env, name string) (string, error) {
+	if path := os.Getenv(env); path != "" {
+		return path, nil
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s not found on PATH (set %s)", ErrRendererNotFound, name, env)
+	}
+	return path, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/llm"
+)
+
+const (
+	// EmbeddingDimensions is the length of the stored concept embeddings,
+	// fixed by the concept_embeddings column
+	EmbeddingDimensions = 768
+
+	// embeddingBatch is how many concepts are embedded per request
+	embeddingBatch = 100
+
+	// embeddingPollInterval is how often the embedder looks for concepts it
+	// wasn't woken for, and retries after failures
+	embeddingPollInterval = time.Minute
+)
+
+// embedderWake wakes the embedder when concepts are created or edited
+var embedderWake = make(chan struct{}, 1)
+
+// WakeEmbedder tells the embedder there may be concepts to embed, without
+// waiting for it
+func WakeEmbedder() {
+	select {
+	case embedderWake <- struct{}{}:
+	default: // Already woken
+	}
+}
+
+// EmbeddingService embeds concepts and searches them by meaning
+type EmbeddingService struct {
+	embedder llm.Embedder
+	model    string
+}
+
+// NewEmbeddingService creates an embedding service for the provider named by
+// EMBEDDING_PROVIDER. Without one it returns llm.ErrNoEmbedder.
+func NewEmbeddingService() (*EmbeddingService, error) {
+	embedder, err := llm.NewEmbedder()
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddingService{embedder: embedder, model: llm.EmbeddingModel()}, nil
+}
+
+// StartEmbedding embeds concepts in the background until ctx is done: new
+// and edited ones as they're saved, and on startup any without an embedding
+// from the current model
+func (s *EmbeddingService) StartEmbedding(ctx context.Context) {
+	ticker := time.NewTicker(embeddingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.embedPending(ctx); err != nil {
+			log.Printf("Error embedding concepts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-embedderWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// embedPending embeds concepts in batches until none are left
+func (s *EmbeddingService) embedPending(ctx context.Context) error {
+	for {
+		texts, err := db.GetUnembeddedConcepts(s.model, embeddingBatch)
+		if err != nil {
+			return err
+		}
+		if len(texts) == 0 {
+			return nil
+		}
+
+		inputs := make([]string, len(texts))
+		for i, t := range texts {
+			inputs[i] = t.Text
+		}
+		vectors, err := s.embed(ctx, inputs, false)
+		if err != nil {
+			return err
+		}
+		if err := db.SaveConceptEmbeddings(s.model, texts, vectors); err != nil {
+			return err
+		}
+		log.Printf("Embedded %d concepts with %s", len(texts), s.model)
+
+		if len(texts) < embeddingBatch {
+			return nil
+		}
+	}
+}
+
+// SearchConcepts returns up to limit concepts owner can see closest in meaning
+// to q, most similar first. Concepts saved in the last moments may not be
+// embedded yet.
+func (s *EmbeddingService) SearchConcepts(ctx context.Context, q string, limit int, owner *int) ([]models.ConceptMatch, error) {
+	vectors, err := s.embed(ctx, []string{strings.TrimSpace(q)}, true)
+	if err != nil {
+		return nil, err
+	}
+	return db.SearchConceptsByEmbedding(vectors[0], s.model, limit, owner)
+}
+
+// embed returns the embeddings of texts, checking they fit the stored
+// column, and records the tokens spent
+func (s *EmbeddingService) embed(ctx context.Context, texts []string, query bool) ([][]float32, error) {
+	resp, err := s.embedder.Embed(ctx, llm.EmbedRequest{
+		Model:      s.model,
+		Texts:      texts,
+		Query:      query,
+		Dimensions: EmbeddingDimensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed: %w", err)
+	}
+
+	if err := db.RecordLLMUsage(s.model, resp.InputTokens, 0); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	for _, v := range resp.Vectors {
+		if len(v) != EmbeddingDimensions {
+			return nil, fmt.Errorf("%w: %s returns %d dimensions, but concept embeddings have %d - choose another EMBEDDING_MODEL",
+				llm.ErrAPIError, s.model, len(v), EmbeddingDimensions)
+		}
+	}
+	return resp.Vectors, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/secrets"
+)
+
+// encryptionRotateBatch is how many values RotateEncryption reads at a time
+const encryptionRotateBatch = 200
+
+// RotateEncryption rewrites the transcripts and generated content stored
+// differently from how they'd be written now: sealing plaintext, and text
+// under previous keys or the legacy format, with the current master key, or
+// decrypting it all when encryption at rest is off. Like RotateCredentials, run it after moving the
+// old key to SECRETS_PREVIOUS_KEYS, and drop the old key once it succeeds.
+func RotateEncryption() (*models.RotateEncryptionResult, error) {
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RotateEncryptionResult{Encrypted: secrets.TextEncryption()}
+	if result.Encrypted {
+		result.KeyID = k.CurrentKeyID()
+	}
+
+	for _, col := range db.SealedColumns {
+		afterID := 0
+		for {
+			values, err := db.GetSealedTextToRotate(col, afterID, result.KeyID, encryptionRotateBatch)
+			if err != nil {
+				return result, err
+			}
+			if len(values) == 0 {
+				break
+			}
+
+			for _, v := range values {
+				afterID = v.ID
+				text, err := secrets.OpenText(v.Stored, v.Binding())
+				if err != nil {
+					return result, fmt.Errorf("failed to rotate %s.%s %d: %w", v.Table, v.Column, v.ID, err)
+				}
+				stored, err := secrets.SealText(text, v.Binding())
+				if err != nil {
+					return result, err
+				}
+
+				replaced, err := db.ReplaceSealedText(v, stored)
+				if err != nil {
+					return result, err
+				}
+				if replaced {
+					result.Rewritten++
+				} else {
+					result.Skipped++
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// EvalOptions chooses what an eval run evaluates
+type EvalOptions struct {
+	Label        string // Optional note stored with the run
+	Model        string // Claude model for extraction; CLAUDE_MODEL when empty
+	Instructions string // Extra concept stage instructions to evaluate
+}
+
+// EvalService scores concept extraction against the stored eval cases
+type EvalService struct {
+	claudeService *ClaudeService
+	judge         *ClaudeService
+}
+
+// NewEvalService creates a new eval service. The judge uses EVAL_JUDGE_MODEL,
+// or CLAUDE_MODEL when unset; keep it fixed so scores stay comparable.
+func NewEvalService() (*EvalService, error) {
+	claudeService, err := NewClaudeService()
+	if err != nil {
+		return nil, err
+	}
+
+	judge := claudeService
+	if model := os.Getenv("EVAL_JUDGE_MODEL"); model != "" {
+		judge = claudeService.forStage(models.PipelineStage{Model: model})
+	}
+
+	return &EvalService{
+		claudeService: claudeService,
+		judge:         judge,
+	}, nil
+}
+
+// evalPromptVersion identifies the prompt evaluated: the concept prompt's
+// version, plus a hash of its PROMPTS_DIR override and any extra instructions
+func evalPromptVersion(instructions string) string {
+	override := promptFor("concepts", "")
+	if instructions == "" && override == "" {
+		return ConceptsPromptVersion
+	}
+	extra := instructions
+	if override != "" {
+		extra = override + "\x00" + instructions
+	}
+	sum := sha256.Sum256([]byte(extra))
+	return ConceptsPromptVersion + "+" + hex.EncodeToString(sum[:4])
+}
+
+// Run extracts concepts from every eval case, has the judge score coverage
+// and precision, and saves the run. A case that fails is recorded with its
+// error and left out of the run's means.
+func (s *EvalService) Run(ctx context.Context, opts EvalOptions) (*models.EvalRun, error) {
+	cases, err := db.GetEvalCases()
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no eval cases")
+	}
+
+	instructions := strings.TrimSpace(opts.Instructions)
+	extractor := s.claudeService.forStage(models.PipelineStage{
+		Name:         models.StageConcepts,
+		Model:        opts.Model,
+		Instructions: instructions,
+	})
+
+	run := models.EvalRun{
+		Model:         extractor.api().Model(),
+		PromptVersion: evalPromptVersion(instructions),
+		JudgeModel:    s.judge.api().Model(),
+		Cases:         len(cases),
+		Results:       make([]models.EvalResult, 0, len(cases)),
+	}
+	if opts.Label != "" {
+		run.Label = &opts.Label
+	}
+	if instructions != "" {
+		run.Instructions = &instructions
+	}
+
+	scored := 0
+	for _, evalCase := range cases {
+		log.Printf("Evaluating case %q...", evalCase.Name)
+		result := s.runCase(ctx, extractor, evalCase)
+		if result.Error != nil {
+			log.Printf("Warning: Eval case %q failed: %s", evalCase.Name, *result.Error)
+			run.Failed++
+		} else {
+			run.Coverage += result.Coverage
+			run.Precision += result.Precision
+			scored++
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	if scored > 0 {
+		run.Coverage /= float64(scored)
+		run.Precision /= float64(scored)
+	}
+
+	return db.CreateEvalRun(run)
+}
+
+// runCase extracts and judges one eval case
+func (s *EvalService) runCase(ctx context.Context, extractor *ClaudeService, evalCase models.EvalCase) models.EvalResult {
+	result := models.EvalResult{
+		CaseID:     evalCase.ID,
+		CaseName:   evalCase.Name,
+		Extracted:  models.StringArray{},
+		Missed:     models.StringArray{},
+		Irrelevant: models.StringArray{},
+	}
+	fail := func(err error) models.EvalResult {
+		msg := err.Error()
+		result.Error = &msg
+		return result
+	}
+
+	concepts, err := extractor.ExtractConcepts(ctx, evalCase.Transcript, 0)
+	if err != nil {
+		return fail(err)
+	}
+	for _, c := range concepts {
+		result.Extracted = append(result.Extracted, c.Title)
+	}
+
+	judgement, err := s.judge.JudgeConcepts(ctx, evalCase.Transcript, evalCase.ExpectedConcepts, concepts)
+	if err != nil {
+		return fail(err)
+	}
+
+	for i, covered := range judgement.Covered {
+		if !covered {
+			result.Missed = append(result.Missed, evalCase.ExpectedConcepts[i])
+		}
+	}
+	for i, relevant := range judgement.Relevant {
+		if !relevant {
+			result.Irrelevant = append(result.Irrelevant, concepts[i].Title)
+		}
+	}
+
+	if n := len(evalCase.ExpectedConcepts); n > 0 {
+		result.Coverage = float64(n-len(result.Missed)) / float64(n)
+	}
+	if n := len(concepts); n > 0 {
+		result.Precision = float64(n-len(result.Irrelevant)) / float64(n)
+	}
+
+	return result
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"log"
+	"math/rand/v2"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// withPromptExperiment returns stage with the prompt variant a source gets from
+// the stage's running experiment, recording the assignment. Sources outside the
+// experiment's sample, and any lookup failure, leave the stage as configured.
+func withPromptExperiment(stage models.PipelineStage, sourceContentID int) models.PipelineStage {
+	experiment, err := db.GetActivePromptExperiment(stage.Name)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return stage
+	}
+	if experiment == nil {
+		return stage
+	}
+
+	// Seeded by experiment and source, so re-runs land in the same arm
+	rng := rand.New(rand.NewPCG(uint64(experiment.ID), uint64(sourceContentID)))
+	if rng.Float64() >= experiment.SampleRate {
+		return stage
+	}
+	variant := models.VariantA
+	if rng.IntN(2) == 1 {
+		variant = models.VariantB
+	}
+
+	if err := db.AssignPromptVariant(experiment.ID, sourceContentID, variant); err != nil {
+		// Unrecorded runs would skew the comparison, so don't run the variant
+		log.Printf("Warning: %v", err)
+		return stage
+	}
+
+	log.Printf("Running %s stage with variant %s of experiment %q", stage.Name, variant, experiment.Name)
+	prompt := experiment.Variant(variant)
+	if prompt.Model != "" {
+		stage.Model = prompt.Model
+	}
+	if prompt.Instructions != "" {
+		stage.Instructions = prompt.Instructions
+	}
+	return stage
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// Fact check retrieval tuning
+const (
+	factCheckPassages = 3   // Transcript segments retrieved per claim
+	factCheckMinScore = 0.2 // Share of a claim's key terms a segment must contain
+)
+
+// factCheckMarkers are inserted after claims the transcript doesn't back
+var factCheckMarkers = map[string]string{
+	models.VerdictUnsupported:  " [unsupported by the source]",
+	models.VerdictContradicted: " [contradicted by the source]",
+}
+
+// factCheckContent checks each post's claims against the transcript and saves
+// the results, leaving posts whose check fails unchecked
+func factCheckContent(ctx context.Context, claudeService *ClaudeService, transcript string, contents []models.GeneratedContent) ([]models.GeneratedContent, []models.StageWarning) {
+	log.Printf("Fact-checking %d generated content pieces...", len(contents))
+	var warnings []models.StageWarning
+
+	segments := segmentTranscript(transcript)
+	checked := make([]models.GeneratedContent, len(contents))
+	for i, content := range contents {
+		checked[i] = content
+
+		factCheck, err := factCheckPost(ctx, claudeService, content, segments)
+		if err == nil {
+			var saved *models.GeneratedContent
+			if saved, err = db.SaveContentFactCheck(content.ID, *factCheck); err == nil {
+				checked[i] = *saved
+				continue
+			}
+		}
+
+		log.Printf("Warning: Failed to fact-check %s content %d: %v", content.Platform, content.ID, err)
+		warnings = append(warnings, models.StageWarning{
+			Stage:    models.StageFactCheck,
+			Platform: content.Platform,
+			Message:  fmt.Sprintf("failed to fact-check %s content: %v", content.Platform, err),
+		})
+	}
+
+	return checked, warnings
+}
+
+// factCheckPost extracts a post's claims, retrieves the transcript segments
+// each is about, and has Claude judge the claims against them
+func factCheckPost(ctx context.Context, claudeService *ClaudeService, content models.GeneratedContent, segments []models.TranscriptSegment) (*models.FactCheck, error) {
+	claims, err := claudeService.ExtractClaims(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	factCheck := &models.FactCheck{
+		Claims:        []models.FactCheckClaim{},
+		AnnotatedBody: content.Body,
+		ContentHash:   moderationHash(content),
+		CheckedAt:     time.Now(),
+	}
+	if len(claims) == 0 {
+		return factCheck, nil
+	}
+
+	passages := make([][]models.TranscriptSegment, len(claims))
+	for i, claim := range claims {
+		passages[i] = claimPassages(segments, claim)
+	}
+
+	if factCheck.Claims, err = claudeService.VerifyClaims(ctx, claims, passages); err != nil {
+		return nil, err
+	}
+
+	for i := range factCheck.Claims {
+		claim := &factCheck.Claims[i]
+		if start := strings.Index(content.Body, claim.Claim); start >= 0 {
+			claim.Range = &models.TextRange{Start: start, End: start + len(claim.Claim)}
+		}
+		if claim.Verdict != models.VerdictSupported {
+			factCheck.Unsupported++
+		}
+	}
+	factCheck.AnnotatedBody = annotateClaims(content.Body, factCheck.Claims)
+
+	return factCheck, nil
+}
+
+// claimPassages retrieves the segments containing the largest share of a
+// claim's key terms, in transcript order
+func claimPassages(segments []models.TranscriptSegment, claim string) []models.TranscriptSegment {
+	weights := map[string]float64{}
+	for _, term := range keyTerms(claim) {
+		weights[term] = 1
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		segment models.TranscriptSegment
+		score   float64
+	}
+	var candidates []scored
+	for _, segment := range segments {
+		_, found := matchTerms(segment.Text, weights)
+		if score := float64(len(found)) / float64(len(weights)); score >= factCheckMinScore {
+			candidates = append(candidates, scored{segment, score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > factCheckPassages {
+		candidates = candidates[:factCheckPassages]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].segment.Index < candidates[j].segment.Index
+	})
+
+	passages := make([]models.TranscriptSegment, len(candidates))
+	for i, c := range candidates {
+		passages[i] = c.segment
+	}
+	return passages
+}
+
+// annotateClaims marks the claims the transcript doesn't back inline, after
+// each one's text
+func annotateClaims(body string, claims []models.FactCheckClaim) string {
+	var marked []models.FactCheckClaim
+	for _, claim := range claims {
+		if factCheckMarkers[claim.Verdict] != "" && claim.Range != nil {
+			marked = append(marked, claim)
+		}
+	}
+
+	// Insert from the end so earlier ranges stay valid
+	sort.Slice(marked, func(i, j int) bool {
+		return marked[i].Range.End > marked[j].Range.End
+	})
+	for _, claim := range marked {
+		body = body[:claim.Range.End] + factCheckMarkers[claim.Verdict] + body[claim.Range.End:]
+	}
+
+	return body
+}
+
+// contentToFactCheck returns the posts a fact check stage covers: those the
+// run generated, or when it generated none, the posts already generated from
+// its concepts
+func contentToFactCheck(result *ProcessResult) ([]models.GeneratedContent, error) {
+	if len(result.GeneratedContent) > 0 || len(result.Concepts) == 0 {
+		return result.GeneratedContent, nil
+	}
+
+	conceptIDs := make([]int, len(result.Concepts))
+	for i, c := range result.Concepts {
+		conceptIDs[i] = c.ID
+	}
+	return db.GetGeneratedContentByConceptIDs(conceptIDs)
+}
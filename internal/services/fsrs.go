@@ -0,0 +1,107 @@
+package services
+
+import (
+	"math"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// FSRS-4.5 forgetting curve: R(t, S) = (1 + fsrsFactor*t/S)^fsrsDecay, so
+// recall probability is 90% when t = S
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// fsrsDefaultWeights are the FSRS-4.5 weights fitted to the reviews of many
+// Anki users
+var fsrsDefaultWeights = []float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// fsrsGrades maps ratings to FSRS grades, again (1) to easy (4)
+var fsrsGrades = map[string]float64{
+	models.RatingAgain: 1,
+	models.RatingHard:  2,
+	models.RatingGood:  3,
+	models.RatingEasy:  4,
+}
+
+// fsrsState is a concept's FSRS memory state: stability is the interval in
+// days at which recall drops to 90%, difficulty runs from 1 to 10
+type fsrsState struct {
+	stability  float64
+	difficulty float64
+}
+
+// fsrsInitial returns the memory state after a concept's first review
+func fsrsInitial(w []float64, grade float64) fsrsState {
+	return fsrsState{
+		stability:  w[int(grade)-1],
+		difficulty: fsrsInitialDifficulty(w, grade),
+	}
+}
+
+// fsrsInitialDifficulty returns the difficulty of a new concept first rated grade
+func fsrsInitialDifficulty(w []float64, grade float64) float64 {
+	return clampDifficulty(w[4] - (grade-3)*w[5])
+}
+
+// fsrsNext returns the memory state after a review rated grade elapsedDays
+// after the previous one
+func fsrsNext(w []float64, state fsrsState, grade, elapsedDays float64) fsrsState {
+	retrievability := math.Pow(1+fsrsFactor*elapsedDays/state.stability, fsrsDecay)
+
+	// Difficulty moves with the grade, reverting toward a good first rating
+	difficulty := state.difficulty - w[6]*(grade-3)
+	difficulty = clampDifficulty(w[7]*fsrsInitialDifficulty(w, 3) + (1-w[7])*difficulty)
+
+	var stability float64
+	if grade == 1 {
+		stability = w[11] *
+			math.Pow(state.difficulty, -w[12]) *
+			(math.Pow(state.stability+1, w[13]) - 1) *
+			math.Exp(w[14]*(1-retrievability))
+		stability = math.Min(stability, state.stability)
+	} else {
+		modifier := 1.0
+		switch grade {
+		case 2:
+			modifier = w[15]
+		case 4:
+			modifier = w[16]
+		}
+		stability = state.stability * (1 + math.Exp(w[8])*
+			(11-state.difficulty)*
+			math.Pow(state.stability, -w[9])*
+			(math.Exp(w[10]*(1-retrievability))-1)*
+			modifier)
+	}
+
+	return fsrsState{stability: stability, difficulty: difficulty}
+}
+
+// fsrsInterval returns the days until recall drops to requestRetention
+func fsrsInterval(stability, requestRetention float64) int {
+	interval := stability / fsrsFactor * (math.Pow(requestRetention, 1/fsrsDecay) - 1)
+	return max(1, int(math.Round(interval)))
+}
+
+// fsrsStateFromSM2 estimates the memory state of a concept scheduled by SM-2:
+// its interval stands in for stability and its ease for difficulty, the
+// starting ease mapping to the middle of the scale
+func fsrsStateFromSM2(w []float64, progress models.LearningProgress, startingEase float64) fsrsState {
+	stability := float64(progress.IntervalDays)
+	if stability <= 0 {
+		stability = w[0]
+	}
+	return fsrsState{
+		stability:  stability,
+		difficulty: clampDifficulty(5.5 + (startingEase-progress.EaseFactor)*5),
+	}
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Max(1, math.Min(10, d))
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ErrNotEnoughReviews is returned when the review history is too short to fit
+// FSRS weights to
+var ErrNotEnoughReviews = errors.New("not enough review history")
+
+// fsrsMinReviews is the number of predictable reviews (reviews of a concept
+// on a later day than its first) needed to fit weights, and the number of new
+// ones the optimizer job waits for before fitting again
+const fsrsMinReviews = 200
+
+// Optimizer tuning: Adam over weights scaled to their bounds
+const (
+	fsrsIterations   = 200
+	fsrsLearningRate = 0.01
+	fsrsGradientStep = 1e-4
+)
+
+// fsrsWeightBounds keep fitted weights within the ranges FSRS-4.5 allows
+var fsrsWeightBounds = [17][2]float64{
+	{0.1, 100}, {0.1, 100}, {0.1, 100}, {0.1, 100},
+	{1, 10}, {0.1, 5}, {0.1, 5}, {0, 0.5}, {0, 3},
+	{0.1, 0.8}, {0.01, 2.5}, {0.5, 5}, {0.01, 0.2},
+	{0.01, 0.9}, {0.01, 2}, {0, 1}, {1, 4},
+}
+
+// fsrsReview is a review the optimizer replays: its grade and the whole days
+// since the concept's previous review (0 for the first)
+type fsrsReview struct {
+	grade   float64
+	elapsed float64
+}
+
+// fsrsOptimizing serializes optimizer runs
+var fsrsOptimizing sync.Mutex
+
+// OptimizeFSRS fits the FSRS weights to the workspace's review history,
+// starting from the weights in effect, and records the run. Unless dryRun, the
+// fitted weights replace the workspace's when they predict recall better.
+func OptimizeFSRS(ctx context.Context, dryRun bool) (*models.FSRSOptimization, error) {
+	fsrsOptimizing.Lock()
+	defer fsrsOptimizing.Unlock()
+
+	history, err := db.GetReviewHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	sequences, reviews := fsrsSequences(history, DefaultLocation())
+	if reviews < fsrsMinReviews {
+		return nil, fmt.Errorf("%w: %d reviews on a later day than a concept's first, %d needed", ErrNotEnoughReviews, reviews, fsrsMinReviews)
+	}
+
+	current := CurrentSettings().Review.Weights
+	fitted, err := fitFSRSWeights(ctx, current, sequences)
+	if err != nil {
+		return nil, err
+	}
+
+	optimization := models.FSRSOptimization{
+		Reviews:       reviews,
+		Concepts:      len(sequences),
+		LogLossBefore: fsrsLogLoss(current, sequences),
+		LogLossAfter:  fsrsLogLoss(fitted, sequences),
+		Weights:       fitted,
+	}
+
+	if !dryRun && optimization.LogLossAfter < optimization.LogLossBefore {
+		weights := []float64(fitted)
+		if _, err := UpdateSettings(models.UpdateSettingsRequest{
+			Review: &models.ReviewSettingsOverrides{Weights: &weights},
+		}); err != nil {
+			return nil, fmt.Errorf("failed to apply fitted weights: %w", err)
+		}
+		optimization.Applied = true
+	}
+
+	return db.CreateFSRSOptimization(optimization)
+}
+
+// GetFSRSOptimizations returns the most recent optimization runs, newest first
+func GetFSRSOptimizations(limit int) ([]models.FSRSOptimization, error) {
+	return db.GetFSRSOptimizations(limit)
+}
+
+// StartFSRSOptimizer refits the FSRS weights every interval, once
+// fsrsMinReviews reviews have accumulated since the last fit
+func StartFSRSOptimizer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			optimizeFSRSIfDue(ctx)
+		}
+	}
+}
+
+// optimizeFSRSIfDue runs the optimizer when enough reviews are new since the last run
+func optimizeFSRSIfDue(ctx context.Context) {
+	last, err := db.GetFSRSOptimizations(1)
+	if err != nil {
+		log.Printf("Failed to check FSRS optimizations: %v", err)
+		return
+	}
+
+	history, err := db.GetReviewHistory()
+	if err != nil {
+		log.Printf("Failed to read review history: %v", err)
+		return
+	}
+	_, reviews := fsrsSequences(history, DefaultLocation())
+	if reviews < fsrsMinReviews || (len(last) > 0 && reviews < last[0].Reviews+fsrsMinReviews) {
+		return
+	}
+
+	optimization, err := OptimizeFSRS(ctx, false)
+	if err != nil {
+		log.Printf("FSRS optimization failed: %v", err)
+		return
+	}
+	log.Printf("FSRS optimization over %d reviews: log loss %.4f -> %.4f (applied: %t)",
+		optimization.Reviews, optimization.LogLossBefore, optimization.LogLossAfter, optimization.Applied)
+}
+
+// fsrsSequences turns review history, grouped by concept in review order, into
+// a sequence per concept reviewed on at least two days. Only a concept's first
+// review of each day in loc counts, as in FSRS. It also returns the number of
+// reviews after the first, which are those the weights predict.
+func fsrsSequences(history []models.ReviewLog, loc *time.Location) ([][]fsrsReview, int) {
+	var sequences [][]fsrsReview
+	var reviews int
+
+	var sequence []fsrsReview
+	var conceptID int
+	var lastDay time.Time
+	flush := func() {
+		if len(sequence) > 1 {
+			sequences = append(sequences, sequence)
+			reviews += len(sequence) - 1
+		}
+		sequence = nil
+	}
+
+	for _, review := range history {
+		reviewed := review.ReviewedAt.In(loc)
+		day := time.Date(reviewed.Year(), reviewed.Month(), reviewed.Day(), 0, 0, 0, 0, time.UTC)
+
+		if review.ConceptID != conceptID {
+			flush()
+			conceptID = review.ConceptID
+		} else if !day.After(lastDay) {
+			continue
+		}
+
+		elapsed := 0.0
+		if len(sequence) > 0 {
+			elapsed = math.Round(day.Sub(lastDay).Hours() / 24)
+		}
+		sequence = append(sequence, fsrsReview{grade: fsrsGrades[review.Rating], elapsed: elapsed})
+		lastDay = day
+	}
+	flush()
+
+	return sequences, reviews
+}
+
+// fsrsLogLoss replays the sequences with weights w and returns the mean binary
+// cross-entropy between predicted recall and whether each review recalled
+func fsrsLogLoss(w []float64, sequences [][]fsrsReview) float64 {
+	var total float64
+	var count int
+
+	for _, sequence := range sequences {
+		state := fsrsInitial(w, sequence[0].grade)
+		for _, review := range sequence[1:] {
+			r := math.Pow(1+fsrsFactor*review.elapsed/state.stability, fsrsDecay)
+			r = math.Max(1e-6, math.Min(1-1e-6, r))
+			if review.grade > 1 {
+				total -= math.Log(r)
+			} else {
+				total -= math.Log(1 - r)
+			}
+			count++
+
+			state = fsrsNext(w, state, review.grade, review.elapsed)
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// fitFSRSWeights minimizes the log loss with Adam from the start weights,
+// estimating gradients by finite differences. Weights are optimized scaled to
+// fsrsWeightBounds so each moves at a comparable rate.
+func fitFSRSWeights(ctx context.Context, start []float64, sequences [][]fsrsReview) (models.FloatArray, error) {
+	const beta1, beta2, epsilon = 0.9, 0.999, 1e-8
+
+	n := len(fsrsWeightBounds)
+	x := make([]float64, n)
+	for i, bound := range fsrsWeightBounds {
+		x[i] = math.Max(0, math.Min(1, (start[i]-bound[0])/(bound[1]-bound[0])))
+	}
+	weights := func(x []float64) []float64 {
+		w := make([]float64, n)
+		for i, bound := range fsrsWeightBounds {
+			w[i] = bound[0] + x[i]*(bound[1]-bound[0])
+		}
+		return w
+	}
+
+	m := make([]float64, n)
+	v := make([]float64, n)
+	gradient := make([]float64, n)
+	for t := 1; t <= fsrsIterations; t++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		loss := fsrsLogLoss(weights(x), sequences)
+		for i := range x {
+			shifted := append([]float64(nil), x...)
+			shifted[i] += fsrsGradientStep
+			gradient[i] = (fsrsLogLoss(weights(shifted), sequences) - loss) / fsrsGradientStep
+		}
+
+		for i := range x {
+			m[i] = beta1*m[i] + (1-beta1)*gradient[i]
+			v[i] = beta2*v[i] + (1-beta2)*gradient[i]*gradient[i]
+			mHat := m[i] / (1 - math.Pow(beta1, float64(t)))
+			vHat := v[i] / (1 - math.Pow(beta2, float64(t)))
+			x[i] = math.Max(0, math.Min(1, x[i]-fsrsLearningRate*mHat/(math.Sqrt(vHat)+epsilon)))
+		}
+	}
+
+	fitted := weights(x)
+	for i := range fitted {
+		fitted[i] = math.Round(fitted[i]*10000) / 10000
+	}
+	return fitted, nil
+}
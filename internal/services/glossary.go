@@ -0,0 +1,54 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetMergedGlossary returns the glossary across all unarchived sources owner
+// can see, with terms matched case-insensitively merged into one entry per term
+func GetMergedGlossary(owner *int) ([]models.GlossaryEntry, error) {
+	terms, err := db.GetGlossaryTerms(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeGlossary(terms), nil
+}
+
+// mergeGlossary groups terms (sorted by lowercase term) into entries. An entry
+// takes the spelling of its first definition and the union of concept IDs.
+func mergeGlossary(terms []models.GlossaryTerm) []models.GlossaryEntry {
+	entries := []models.GlossaryEntry{}
+
+	var key string
+	var conceptSeen map[int]bool
+	for _, t := range terms {
+		if k := strings.ToLower(t.Term); len(entries) == 0 || k != key {
+			key = k
+			conceptSeen = map[int]bool{}
+			entries = append(entries, models.GlossaryEntry{
+				Term:        t.Term,
+				Definitions: []models.GlossaryDefinition{},
+				ConceptIDs:  []int{},
+			})
+		}
+
+		entry := &entries[len(entries)-1]
+		entry.Definitions = append(entry.Definitions, models.GlossaryDefinition{
+			TermID:          t.ID,
+			SourceContentID: t.SourceContentID,
+			Definition:      t.Definition,
+		})
+		for _, id := range t.ConceptIDs {
+			if !conceptSeen[id] {
+				conceptSeen[id] = true
+				entry.ConceptIDs = append(entry.ConceptIDs, id)
+			}
+		}
+	}
+
+	return entries
+}
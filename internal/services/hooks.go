@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// StageHook is external code run after pipeline stages. Hooks receive a
+// snapshot of the result so far and return changes to merge back rather than
+// mutating it.
+type StageHook interface {
+	// Name identifies the hook in the run log
+	Name() string
+	// AfterStage runs after the named stage. A nil result merges nothing.
+	AfterStage(ctx context.Context, stage string, result ProcessResult) (*models.HookResult, error)
+}
+
+// stageHooks holds the hooks registered in code
+var (
+	stageHooksMu sync.RWMutex
+	stageHooks   []StageHook
+)
+
+// RegisterStageHook adds a hook that runs after every pipeline stage, in
+// registration order and before webhook hooks. Call it during startup.
+func RegisterStageHook(hook StageHook) {
+	stageHooksMu.Lock()
+	defer stageHooksMu.Unlock()
+	stageHooks = append(stageHooks, hook)
+}
+
+// StageHookPayload is the JSON body POSTed to webhook hooks. The response may
+// be a models.HookResult to merge back.
+type StageHookPayload struct {
+	Stage           string        `json:"stage"`
+	SourceContentID int           `json:"source_content_id"`
+	Result          ProcessResult `json:"result"`
+}
+
+// webhookHookTimeout bounds each webhook hook call
+const webhookHookTimeout = 30 * time.Second
+
+// webhookHook is a StageHook backed by a stored webhook
+type webhookHook struct {
+	hook       models.PipelineHook
+	httpClient *http.Client
+}
+
+// Name identifies the webhook by its stored name
+func (h webhookHook) Name() string {
+	return h.hook.Name
+}
+
+// AfterStage POSTs the stage payload to the webhook and decodes any HookResult
+// in the response. With a secret set, X-Lattice-Signature carries the body's
+// hex HMAC-SHA256.
+func (h webhookHook) AfterStage(ctx context.Context, stage string, result ProcessResult) (*models.HookResult, error) {
+	payload, err := json.Marshal(StageHookPayload{
+		Stage:           stage,
+		SourceContentID: result.SourceContent.ID,
+		Result:          result,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Lattice-Stage", stage)
+	if h.hook.Secret != nil && *h.hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(*h.hook.Secret))
+		mac.Write(payload)
+		req.Header.Set("X-Lattice-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call hook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook returned status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var hookResult models.HookResult
+	if err := json.Unmarshal(body, &hookResult); err != nil {
+		return nil, fmt.Errorf("failed to parse hook response: %w", err)
+	}
+
+	return &hookResult, nil
+}
+
+// hooksForStage returns the registered hooks followed by the enabled webhooks
+// subscribed to the stage
+func hooksForStage(stage string) []StageHook {
+	stageHooksMu.RLock()
+	hooks := slices.Clone(stageHooks)
+	stageHooksMu.RUnlock()
+
+	webhooks, err := db.GetPipelineHooks(true)
+	if err != nil {
+		log.Printf("Warning: Failed to load pipeline hooks: %v", err)
+		return hooks
+	}
+
+	client := &http.Client{Timeout: webhookHookTimeout}
+	for _, w := range webhooks {
+		if len(w.Stages) == 0 || slices.Contains(w.Stages, stage) {
+			hooks = append(hooks, webhookHook{hook: w, httpClient: client})
+		}
+	}
+
+	return hooks
+}
+
+// runHooks runs every hook for a completed stage and merges their results into
+// result. Hook failures are logged and recorded, never failing the pipeline.
+func runHooks(ctx context.Context, stage string, result *ProcessResult) {
+	for _, hook := range hooksForStage(stage) {
+		run := models.PipelineHookRun{
+			HookName:        hook.Name(),
+			Stage:           stage,
+			SourceContentID: result.SourceContent.ID,
+			Status:          models.HookRunSucceeded,
+		}
+
+		hookResult, err := hook.AfterStage(ctx, stage, *result)
+		if err == nil && hookResult != nil {
+			run.Annotations = hookResult.Annotations
+			run.ConceptsUpdated, err = mergeConceptPatches(result, hookResult.Concepts)
+		}
+		if err != nil {
+			log.Printf("Warning: Hook %s failed after %s stage: %v", hook.Name(), stage, err)
+			msg := err.Error()
+			run.Status = models.HookRunFailed
+			run.Error = &msg
+		}
+
+		if err := db.CreatePipelineHookRun(run); err != nil {
+			log.Printf("Warning: Failed to record hook run: %v", err)
+		}
+	}
+}
+
+// mergeConceptPatches saves a hook's concept changes and applies them to the
+// result, ignoring patches for concepts outside this pipeline run
+func mergeConceptPatches(result *ProcessResult, patches []models.ConceptPatch) (int, error) {
+	updated := 0
+	for _, patch := range patches {
+		i := slices.IndexFunc(result.Concepts, func(c models.Concept) bool { return c.ID == patch.ID })
+		if i < 0 || (patch.Title == nil && patch.Description == nil && patch.Tags == nil) {
+			continue
+		}
+
+		concept, err := db.UpdateConcept(patch.ID, models.UpdateConceptRequest{
+			Title:       patch.Title,
+			Description: patch.Description,
+			Tags:        patch.Tags,
+		})
+		if err != nil {
+			return updated, fmt.Errorf("failed to apply patch to concept %d: %w", patch.ID, err)
+		}
+
+		result.Concepts[i] = *concept
+		updated++
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/youtube"
+)
+
+// DefaultJobWorkers is how many jobs run at once unless JOB_WORKERS is set
+const DefaultJobWorkers = 2
+
+// jobPollInterval is how often idle workers look for jobs they weren't woken
+// for, such as those requeued at startup
+const jobPollInterval = 10 * time.Second
+
+// EnqueueSourceContent queues the YouTube video at req.URL for processing in
+// the background and returns its job. The URL, pipeline, and profile are
+// checked now, so mistakes fail the request rather than the job.
+func (s *SourceContentService) EnqueueSourceContent(req models.CreateSourceContentRequest) (*models.Job, error) {
+	if err := youtube.ValidateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := db.CreateJob(req, spec)
+	if err != nil {
+		return nil, err
+	}
+	s.wakeJobWorker()
+
+	job.Steps = jobSteps(job, nil)
+	return job, nil
+}
+
+// GetJob retrieves a job with each step's progress, which comes from its
+// source's pipeline run once the source is saved
+func GetJob(id int) (*models.Job, error) {
+	job, err := db.GetJobByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var run *models.PipelineRun
+	if job.SourceContentID != nil {
+		if run, err = db.GetPipelineRun(*job.SourceContentID); err != nil {
+			return nil, err
+		}
+	}
+
+	job.Steps = jobSteps(job, run)
+	return job, nil
+}
+
+// jobSteps reports how far each of a job's steps got: fetching the source,
+// then the stages of its run's spec, or of the spec it was queued with
+// before the run starts. Only the first unfinished step of a running job is
+// running.
+func jobSteps(job *models.Job, run *models.PipelineRun) []models.JobStep {
+	spec := job.Spec
+	completed := map[string]bool{models.JobStepTranscript: job.SourceContentID != nil}
+	if run != nil {
+		spec = run.Spec
+		for _, stage := range run.CompletedStages {
+			completed[stage] = true
+		}
+	}
+
+	names := []string{models.JobStepTranscript}
+	for _, stage := range spec.Stages {
+		names = append(names, stage.Name)
+	}
+
+	running := job.Status == models.JobRunning
+	finished := job.Status == models.JobCompleted || job.Status == models.JobFailed
+	steps := make([]models.JobStep, 0, len(names))
+	for _, name := range names {
+		status := models.JobStepPending
+		switch {
+		case completed[name]:
+			status = models.JobStepCompleted
+		case running:
+			status = models.JobStepRunning
+			running = false
+		case finished:
+			status = models.JobStepSkipped
+		}
+		steps = append(steps, models.JobStep{Name: name, Status: status})
+	}
+	return steps
+}
+
+// StartJobWorkers runs workers that run queued jobs until ctx is done, and
+// returns once they've all stopped
+func (s *SourceContentService) StartJobWorkers(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() { s.jobWorker(ctx) })
+	}
+	wg.Wait()
+}
+
+// jobWorker claims and runs queued jobs one at a time, sleeping until woken
+// or the next poll when none are queued. While the job_processing feature is
+// off, jobs stay queued.
+func (s *SourceContentService) jobWorker(ctx context.Context) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var job *models.Job
+		if FeatureEnabled(models.FeatureJobProcessing) {
+			var err error
+			if job, err = db.ClaimNextJob(); err != nil {
+				log.Printf("Error claiming job: %v", err)
+			}
+		}
+		if job != nil {
+			s.wakeJobWorker() // Another idle worker can take the next one
+			s.runJob(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.jobWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// wakeJobWorker wakes an idle worker, if any, to claim a queued job
+func (s *SourceContentService) wakeJobWorker() {
+	select {
+	case s.jobWake <- struct{}{}:
+	default:
+	}
+}
+
+// runJob runs a claimed job's pipeline and records its outcome. A panic
+// fails the job instead of taking down the server.
+func (s *SourceContentService) runJob(ctx context.Context, job *models.Job) {
+	log.Printf("Running job %d for %s", job.ID, job.Request.URL)
+
+	// Runs last, once the outcome is saved, so followers can fetch it
+	defer jobProgress.finish(job.ID)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %d panicked: %v", job.ID, r)
+			failure := fmt.Sprintf("job panicked: %v", r)
+			if err := db.FinishJob(job.ID, nil, nil, nil, &failure); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}()
+
+	tracked := s.withJob(job.ID)
+	job.Request.OwnerID = job.OwnerID
+	tracked.reportProgress(models.JobEvent{Step: models.JobStepTranscript, Message: fmt.Sprintf("Fetching %s", job.Request.URL)})
+
+	process := tracked.ProcessYouTubeURL
+	if job.Request.Type == "podcast" {
+		process = tracked.ProcessPodcastEpisode
+	}
+	result, err := process(ctx, job.Request)
+	if err != nil && ctx.Err() != nil {
+		// Left running, so the next start requeues it
+		log.Printf("Job %d interrupted by shutdown: %v", job.ID, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Job %d failed: %v", job.ID, err)
+		failure := err.Error()
+		if err := db.FinishJob(job.ID, nil, nil, nil, &failure); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return
+	}
+
+	var redactions models.JSONObject
+	if result.Redactions != nil {
+		redactions = models.JSONObject{}
+		for category, n := range result.Redactions {
+			redactions[category] = n
+		}
+	}
+	if err := db.FinishJob(job.ID, &result.SourceContent.ID, result.Warnings, redactions, nil); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	log.Printf("Job %d completed for source content ID: %d", job.ID, result.SourceContent.ID)
+}
+
+// withJob returns a copy of the service that records the source it saves on
+// job, so the job's progress can be followed
+func (s *SourceContentService) withJob(jobID int) *SourceContentService {
+	tracked := *s
+	tracked.jobID = jobID
+	return &tracked
+}
+
+// recordJobSource records the source the service's job is processing, if it
+// runs for one
+func (s *SourceContentService) recordJobSource(sourceID int) {
+	if s.jobID == 0 {
+		return
+	}
+	if err := db.SetJobSource(s.jobID, sourceID); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
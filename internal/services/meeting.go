@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/meeting"
+)
+
+var (
+	// ErrUnsupportedTranscript is returned for uploads that aren't a transcript
+	ErrUnsupportedTranscript = errors.New("transcript must be a .vtt, .srt, or .txt file")
+
+	// ErrTranscriptTooLarge is returned for uploads over MaxTranscriptUpload
+	ErrTranscriptTooLarge = errors.New("transcript file is larger than 10 MB")
+)
+
+// MaxTranscriptUpload bounds uploaded meeting transcripts, in bytes
+const MaxTranscriptUpload = 10 << 20
+
+// ProcessMeeting imports a meeting's transcript from Zoom or Google Meet, using
+// the stored zoom or google credential, and runs it through the pipeline like
+// any other source. Meetings already imported return their existing data.
+func (s *SourceContentService) ProcessMeeting(ctx context.Context, req models.ImportMeetingRequest) (*ProcessResult, error) {
+	var url, credentialProvider string
+	switch req.Provider {
+	case models.MeetingProviderZoom:
+		url, credentialProvider = "zoom://meetings/"+req.MeetingID, "zoom"
+	case models.MeetingProviderMeet:
+		url, credentialProvider = "meet://"+strings.TrimPrefix(req.MeetingID, "conferenceRecords/"), "google"
+	default:
+		return nil, fmt.Errorf("unknown meeting provider %q", req.Provider)
+	}
+
+	log.Printf("Importing %s meeting: %s", req.Provider, req.MeetingID)
+
+	existing, err := db.GetSourceContentByURL(url, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if existing != nil {
+		log.Printf("Meeting already imported, returning existing data for source content ID: %d", existing.ID)
+		return s.existingProcessResult(ctx, existing)
+	}
+
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := CredentialSecret(credentialProvider, req.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	var transcript *meeting.Transcript
+	switch req.Provider {
+	case models.MeetingProviderZoom:
+		client, err := meeting.NewZoomClient(credential)
+		if err != nil {
+			return nil, err
+		}
+		transcript, err = client.GetTranscript(ctx, req.MeetingID)
+		if err != nil {
+			return nil, err
+		}
+	case models.MeetingProviderMeet:
+		client, err := meeting.NewMeetClient(credential)
+		if err != nil {
+			return nil, err
+		}
+		transcript, err = client.GetTranscript(ctx, req.MeetingID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.withOwner(req.OwnerID).withScrubOverride(req.Scrub).processMeeting(ctx, url, transcript, spec)
+}
+
+// ProcessMeetingUpload runs an uploaded meeting transcript through the
+// pipeline. WebVTT and SRT files keep their cue timings and voice tags; plain
+// text is read as "Speaker: text" lines. Re-uploading the same file returns
+// the existing source.
+func (s *SourceContentService) ProcessMeetingUpload(ctx context.Context, filename string, data []byte, req models.UploadMeetingRequest) (*ProcessResult, error) {
+	if len(data) > MaxTranscriptUpload {
+		return nil, ErrTranscriptTooLarge
+	}
+
+	var transcript *meeting.Transcript
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".vtt", ".srt":
+		transcript = meeting.ParseVTT(data)
+	case ".txt":
+		transcript = meeting.ParseText(data)
+	default:
+		return nil, ErrUnsupportedTranscript
+	}
+	if len(transcript.Utterances) == 0 {
+		return nil, meeting.ErrNoTranscript
+	}
+
+	transcript.Title = req.Title
+	if transcript.Title == "" {
+		transcript.Title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	sum := sha256.Sum256(data)
+	url := "upload://" + hex.EncodeToString(sum[:])
+
+	log.Printf("Processing uploaded meeting transcript: %s", filename)
+
+	existing, err := db.GetSourceContentByURL(url, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if existing != nil {
+		log.Printf("Transcript already uploaded, returning existing data for source content ID: %d", existing.ID)
+		return s.existingProcessResult(ctx, existing)
+	}
+
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOwner(req.OwnerID).withScrubOverride(req.Scrub).processMeeting(ctx, url, transcript, spec)
+}
+
+// processMeeting saves a meeting transcript, attributed by speaker, and runs
+// spec over it
+func (s *SourceContentService) processMeeting(ctx context.Context, url string, transcript *meeting.Transcript, spec models.PipelineSpec) (*ProcessResult, error) {
+	text := transcript.Text()
+	if text == "" {
+		return nil, meeting.ErrNoTranscript
+	}
+
+	log.Printf("Meeting transcript has %d utterances from %d speakers", len(transcript.Utterances), len(transcript.Speakers()))
+
+	return s.processSource(ctx, models.CreateSourceContentRequest{
+		Type:       "meeting",
+		URL:        url,
+		Title:      transcript.Title,
+		Transcript: text,
+		Language:   transcript.Language,
+	}, spec)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// mindmapLabelMaxChars keeps mind-map nodes to a glanceable length
+const mindmapLabelMaxChars = 60
+
+// RenderMindmap renders a source as a Mermaid mind map: the source at the
+// root, its concepts in teaching order, and under each concept its glossary
+// terms and open action items, or the first sentence of its description when
+// it has neither. Archived concepts are left out.
+func RenderMindmap(sourceID int) (string, error) {
+	source, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return "", err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return "", err
+	}
+
+	terms, err := db.GetGlossaryTermsBySourceContentID(sourceID)
+	if err != nil {
+		return "", err
+	}
+
+	open := models.ActionItemOpen
+	items, err := db.GetActionItems(&sourceID, &open, nil)
+	if err != nil {
+		return "", err
+	}
+
+	points := map[int][]string{}
+	for _, t := range terms {
+		for _, id := range t.ConceptIDs {
+			points[id] = append(points[id], t.Term)
+		}
+	}
+	for _, item := range items {
+		if item.ConceptID != nil {
+			points[*item.ConceptID] = append(points[*item.ConceptID], item.Text)
+		}
+	}
+
+	title := source.Title
+	if title == "" {
+		title = source.URL
+	}
+
+	var b strings.Builder
+	b.WriteString("mindmap\n")
+	fmt.Fprintf(&b, "  root((\"%s\"))\n", mindmapLabel(title))
+	for i, concept := range concepts {
+		fmt.Fprintf(&b, "    c%d(\"%s\")\n", i+1, mindmapLabel(concept.Title))
+
+		subpoints := points[concept.ID]
+		if len(subpoints) == 0 && concept.Description != "" {
+			subpoints = []string{citedSentence(concept.Description, 0)}
+		}
+		for j, point := range subpoints {
+			fmt.Fprintf(&b, "      c%d_%d[\"%s\"]\n", i+1, j+1, mindmapLabel(point))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// mindmapLabel makes text safe inside a quoted Mermaid node label: one line,
+// no double quotes or backticks, and short
+func mindmapLabel(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.NewReplacer(`"`, "'", "`", "'").Replace(text)
+	return truncateText(text, mindmapLabelMaxChars)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ErrModerationOff is returned when moderation is requested but no checks are configured
+var ErrModerationOff = errors.New("moderation is off: set MODERATION_CHECKS")
+
+// ErrContentFlagged is returned when content with moderation findings is
+// approved or published without acknowledging them
+var ErrContentFlagged = errors.New("content has unacknowledged moderation findings")
+
+// figurePattern matches figures in content: numbers with an optional currency
+// sign, and a percent, multiplier, or magnitude suffix
+var figurePattern = regexp.MustCompile(`(?i)[$€£]?\d[\d,]*(?:\.\d+)?(?:\s?(?:%|percent\b|x\b|k\b|m\b|bn?\b|thousand\b|million\b|billion\b))?`)
+
+// numberPattern matches the numbers figures are looked up by in the sources
+var numberPattern = regexp.MustCompile(`\d[\d,]*(?:\.\d+)?`)
+
+// ModerationService checks generated content before it's approved or
+// published, with rules, Claude, or both
+type ModerationService struct {
+	claudeService *ClaudeService // Set when the llm check is on
+	checks        []string
+	bannedTerms   []*regexp.Regexp
+	guidelines    string // Brand guidelines for the llm check
+}
+
+// NewModerationService creates a moderation service from MODERATION_CHECKS, a
+// comma-separated list of checks (rules, llm) that's off when unset,
+// MODERATION_BANNED_TERMS, a comma-separated list of terms the rules check
+// flags, and MODERATION_GUIDELINES, brand guidelines for the llm check
+func NewModerationService() (*ModerationService, error) {
+	s := &ModerationService{guidelines: strings.TrimSpace(os.Getenv("MODERATION_GUIDELINES"))}
+
+	for _, check := range strings.Split(os.Getenv("MODERATION_CHECKS"), ",") {
+		check = strings.ToLower(strings.TrimSpace(check))
+		switch {
+		case check == "" || slices.Contains(s.checks, check):
+			continue
+		case check == models.ModerationRules || check == models.ModerationLLM:
+			s.checks = append(s.checks, check)
+		default:
+			return nil, fmt.Errorf("invalid MODERATION_CHECKS: unknown check %q", check)
+		}
+	}
+
+	for _, term := range strings.Split(os.Getenv("MODERATION_BANNED_TERMS"), ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			s.bannedTerms = append(s.bannedTerms, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
+		}
+	}
+
+	if slices.Contains(s.checks, models.ModerationLLM) {
+		claudeService, err := NewClaudeService()
+		if err != nil {
+			return nil, err
+		}
+		s.claudeService = claudeService
+	}
+
+	return s, nil
+}
+
+// ModerateContent runs the moderation checks on generated content and attaches
+// the findings to it
+func (s *ModerationService) ModerateContent(ctx context.Context, id int) (*models.GeneratedContent, error) {
+	if len(s.checks) == 0 {
+		return nil, ErrModerationOff
+	}
+
+	content, err := db.GetGeneratedContentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	moderation, err := s.moderate(ctx, *content)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.SaveContentModeration(id, *moderation)
+}
+
+// SetContentStatus moves generated content to a status. With moderation on,
+// or a fact check to go by, approving or publishing checks the content first
+// unless neither it nor its fact check changed since its last check, and
+// findings block the move until acknowledged. A blocked move returns the content with its findings and
+// ErrContentFlagged.
+func (s *ModerationService) SetContentStatus(ctx context.Context, id int, req models.UpdateContentStatusRequest) (*models.GeneratedContent, error) {
+	content, err := db.GetGeneratedContentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	moderation := content.Moderation
+	if req.Status != models.ContentDraft && (len(s.checks) > 0 || content.FactCheck != nil) {
+		if moderation == nil || moderation.ContentHash != moderationHash(*content) || !slices.Equal(moderation.Checks, s.checks) ||
+			(content.FactCheck != nil && content.FactCheck.CheckedAt.After(moderation.CheckedAt)) {
+			if moderation, err = s.moderate(ctx, *content); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(moderation.Findings) > 0 && moderation.AcknowledgedAt == nil {
+			if !req.AcknowledgeFindings {
+				flagged, err := db.SaveContentModeration(id, *moderation)
+				if err != nil {
+					return nil, err
+				}
+				return flagged, ErrContentFlagged
+			}
+			now := time.Now()
+			moderation.AcknowledgedAt = &now
+		}
+	}
+
+	updated, err := db.SetGeneratedContentStatus(id, req.Status, moderation)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Status == models.ContentPublished && content.PublishedAt == nil {
+		RecordUsage(models.UsagePostsPublished, 1, nil)
+	}
+	return updated, nil
+}
+
+// moderate runs the configured checks on content, adding the unsupported
+// claims of its fact check
+func (s *ModerationService) moderate(ctx context.Context, content models.GeneratedContent) (*models.ContentModeration, error) {
+	concepts, sources, err := moderationSources(content)
+	if err != nil {
+		return nil, err
+	}
+
+	moderation := &models.ContentModeration{
+		Checks:      s.checks,
+		Findings:    []models.ModerationFinding{},
+		ContentHash: moderationHash(content),
+		CheckedAt:   time.Now(),
+	}
+
+	if slices.Contains(s.checks, models.ModerationRules) {
+		moderation.Findings = append(moderation.Findings, s.ruleFindings(content, concepts, sources)...)
+	}
+
+	if slices.Contains(s.checks, models.ModerationLLM) {
+		findings, err := s.claudeService.ModerateContent(ctx, content, concepts, sources, s.guidelines)
+		if err != nil {
+			return nil, err
+		}
+		moderation.Findings = append(moderation.Findings, findings...)
+	}
+
+	// A fact check of the same text flags claims the transcript doesn't back
+	if content.FactCheck != nil && content.FactCheck.ContentHash == moderation.ContentHash {
+		for _, claim := range content.FactCheck.Claims {
+			if claim.Verdict != models.VerdictSupported {
+				moderation.Findings = append(moderation.Findings, models.ModerationFinding{
+					Kind:    models.FindingClaim,
+					Excerpt: claim.Claim,
+					Message: fmt.Sprintf("Fact check: %s. %s", claim.Verdict, claim.Explanation),
+					Check:   models.ModerationFactCheck,
+				})
+			}
+		}
+	}
+
+	return moderation, nil
+}
+
+// ruleFindings flags profanity, banned terms, and figures that appear in
+// neither the content's concepts nor their sources' transcripts
+func (s *ModerationService) ruleFindings(content models.GeneratedContent, concepts []models.Concept, sources []models.SourceContent) []models.ModerationFinding {
+	text := content.Title + "\n" + content.Body
+
+	var findings []models.ModerationFinding
+	seen := map[string]bool{}
+	flag := func(kind, excerpt, message string) {
+		if key := kind + "\x00" + strings.ToLower(excerpt); !seen[key] {
+			seen[key] = true
+			findings = append(findings, models.ModerationFinding{Kind: kind, Excerpt: excerpt, Message: message, Check: models.ModerationRules})
+		}
+	}
+
+	for _, match := range profanityPattern.FindAllString(text, -1) {
+		flag(models.FindingLanguage, match, "Profanity")
+	}
+	for _, term := range s.bannedTerms {
+		for _, match := range term.FindAllString(text, -1) {
+			flag(models.FindingLanguage, match, "Banned term")
+		}
+	}
+
+	var sourceText strings.Builder
+	for _, c := range concepts {
+		sourceText.WriteString(c.Title + "\n" + c.Description + "\n")
+	}
+	for _, source := range sources {
+		sourceText.WriteString(source.Transcript + "\n")
+	}
+	known := map[string]bool{}
+	for _, number := range numberPattern.FindAllString(sourceText.String(), -1) {
+		known[normalizeNumber(number)] = true
+	}
+
+	for _, figure := range figurePattern.FindAllString(text, -1) {
+		number := normalizeNumber(numberPattern.FindString(figure))
+		if known[number] {
+			continue
+		}
+		// Small bare counts ("3 ways") are the content's own structure
+		if value, err := strconv.ParseFloat(number, 64); err == nil && value < 10 && number == strings.TrimSpace(figure) {
+			continue
+		}
+		flag(models.FindingStatistic, strings.TrimSpace(figure), "Figure doesn't appear in the source concepts or transcripts")
+	}
+
+	return findings
+}
+
+// normalizeNumber drops thousands separators and trailing decimal zeros, so
+// "1,200" matches "1200" and "2.50" matches "2.5"
+func normalizeNumber(number string) string {
+	number = strings.ReplaceAll(number, ",", "")
+	if strings.Contains(number, ".") {
+		number = strings.TrimRight(strings.TrimRight(number, "0"), ".")
+	}
+	return number
+}
+
+// moderationSources loads the concepts generated content was made from and
+// their sources, skipping any deleted since
+func moderationSources(content models.GeneratedContent) ([]models.Concept, []models.SourceContent, error) {
+	var concepts []models.Concept
+	var sources []models.SourceContent
+	loaded := map[int]bool{}
+
+	for _, id := range content.ConceptIDs {
+		concept, err := db.GetConceptByID(id)
+		if err != nil {
+			if err.Error() == "concept not found" {
+				continue
+			}
+			return nil, nil, err
+		}
+		concepts = append(concepts, *concept)
+
+		if concept.SourceContentID == nil || loaded[*concept.SourceContentID] {
+			continue
+		}
+		loaded[*concept.SourceContentID] = true
+
+		source, err := db.GetSourceContentByID(*concept.SourceContentID)
+		if err != nil {
+			if err.Error() == "source content not found" {
+				continue
+			}
+			return nil, nil, err
+		}
+		sources = append(sources, *source)
+	}
+
+	return concepts, sources, nil
+}
+
+// moderationHash fingerprints the text a moderation check covers
+func moderationHash(content models.GeneratedContent) string {
+	sum := sha256.Sum256([]byte(content.Title + "\n" + content.Body))
+	return hex.EncodeToString(sum[:])
+}
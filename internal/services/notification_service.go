@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// NotificationService delivers events to the in-app store, email, webhooks, and
+// push subscriptions according to the notification preferences of the user
+// notified, or the workspace's
+type NotificationService struct {
+	webhookURL string
+	smtpHost   string
+	smtpPort   string
+	smtpUser   string
+	smtpPass   string
+	emailFrom  string
+	emailTo    string
+	httpClient *http.Client
+
+	briefingAudio bool // Read daily briefings aloud (BRIEFING_AUDIO)
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService() *NotificationService {
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	return &NotificationService{
+		webhookURL: os.Getenv("NOTIFY_WEBHOOK_URL"),
+		smtpHost:   os.Getenv("SMTP_HOST"),
+		smtpPort:   smtpPort,
+		smtpUser:   os.Getenv("SMTP_USERNAME"),
+		smtpPass:   os.Getenv("SMTP_PASSWORD"),
+		emailFrom:  os.Getenv("SMTP_FROM"),
+		emailTo:    os.Getenv("NOTIFY_EMAIL_TO"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		briefingAudio: os.Getenv("BRIEFING_AUDIO") == "true",
+	}
+}
+
+// Notify delivers a workspace event to every channel the workspace's
+// preferences enable for it.
+// Delivery failures are logged rather than returned so callers never fail
+// their own work because a notification could not be sent.
+func (s *NotificationService) Notify(ctx context.Context, eventType, title, body string, data models.JSONObject) {
+	s.NotifyUser(ctx, nil, eventType, title, body, data)
+}
+
+// NotifyUser delivers an event meant for one user to every channel their
+// preferences enable for it: an in-app notification only they see, and an
+// email to their address. Webhooks and push subscriptions are the
+// workspace's. A nil user is the workspace, as with Notify.
+func (s *NotificationService) NotifyUser(ctx context.Context, userID *int, eventType, title, body string, data models.JSONObject) {
+	pref, err := db.GetNotificationPreference(eventType, userID)
+	if err != nil {
+		log.Printf("Warning: Failed to load notification preferences for %s: %v", eventType, err)
+		return
+	}
+
+	notification := &models.Notification{
+		UserID:    userID,
+		EventType: eventType,
+		Title:     title,
+		Body:      body,
+		Data:      data,
+	}
+
+	if pref.InApp {
+		created, err := db.CreateNotification(notification)
+		if err != nil {
+			log.Printf("Warning: Failed to store notification: %v", err)
+		} else {
+			notification = created
+		}
+	}
+
+	if pref.Email {
+		if err := s.sendEmail(userID, title, body); err != nil {
+			log.Printf("Warning: Failed to send %s email: %v", eventType, err)
+		}
+	}
+
+	if pref.Webhook {
+		if err := s.sendWebhook(ctx, notification); err != nil {
+			log.Printf("Warning: Failed to send %s webhook: %v", eventType, err)
+		}
+	}
+
+	if pref.Push {
+		s.sendPushAll(ctx, notification)
+	}
+}
+
+// SendTestPush sends a test message to one push subscription and returns the delivery error
+func (s *NotificationService) SendTestPush(ctx context.Context, id int) error {
+	sub, err := db.GetPushSubscriptionByID(id)
+	if err != nil {
+		return err
+	}
+
+	deliveryErr := s.sendPush(ctx, *sub, &models.Notification{
+		Title: "Lattice test notification",
+		Body:  "Push notifications are working.",
+	})
+	if err := db.RecordPushDelivery(sub.ID, deliveryErr); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	return deliveryErr
+}
+
+// StartReviewReminders sends a review_due notification every interval while
+// concepts are due for review, until ctx is cancelled
+func (s *NotificationService) StartReviewReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.notifyDueReviews(ctx, time.Now(), "due for review")
+		}
+	}
+}
+
+// StartReviewDigest sends a daily review_due digest of the concepts due that
+// day, at hour:minute in DefaultLocation, until ctx is cancelled
+func (s *NotificationService) StartReviewDigest(ctx context.Context, hour, minute int) {
+	for {
+		timer := time.NewTimer(time.Until(nextLocalTime(time.Now(), hour, minute, DefaultLocation())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.notifyDueReviews(ctx, endOfDay(time.Now(), DefaultLocation()), "due today")
+		}
+	}
+}
+
+// notifyDueReviews sends a review_due notification when any concepts are due
+// before dueBefore
+func (s *NotificationService) notifyDueReviews(ctx context.Context, dueBefore time.Time, when string) {
+	due, err := db.CountDueConcepts(dueBefore, nil)
+	if err != nil {
+		log.Printf("Warning: Failed to check due reviews: %v", err)
+		return
+	}
+	if due == 0 {
+		return
+	}
+
+	noun := "concepts are"
+	if due == 1 {
+		noun = "concept is"
+	}
+	s.Notify(ctx, models.EventReviewDue,
+		"Time to review",
+		fmt.Sprintf("%d %s %s.", due, noun, when),
+		models.JSONObject{"due_count": due})
+}
+
+// sendPushAll delivers a notification to every push subscription, recording each outcome
+func (s *NotificationService) sendPushAll(ctx context.Context, notification *models.Notification) {
+	subscriptions, err := db.GetPushSubscriptions()
+	if err != nil {
+		log.Printf("Warning: Failed to load push subscriptions: %v", err)
+		return
+	}
+
+	for _, sub := range subscriptions {
+		deliveryErr := s.sendPush(ctx, sub, notification)
+		if deliveryErr != nil {
+			log.Printf("Warning: Failed to send %s push to subscription %d: %v", notification.EventType, sub.ID, deliveryErr)
+		}
+		if err := db.RecordPushDelivery(sub.ID, deliveryErr); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// sendPush delivers a notification to one ntfy topic or Gotify server
+func (s *NotificationService) sendPush(ctx context.Context, sub models.PushSubscription, notification *models.Notification) error {
+	var req *http.Request
+	var err error
+
+	switch sub.Provider {
+	case models.PushProviderNtfy:
+		// ntfy takes the message as the body and metadata as headers
+		req, err = http.NewRequestWithContext(ctx, "POST", sub.Endpoint, strings.NewReader(notification.Body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Title", notification.Title)
+		if notification.EventType != "" {
+			req.Header.Set("Tags", notification.EventType)
+		}
+		if sub.Token != nil && *sub.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+*sub.Token)
+		}
+
+	case models.PushProviderGotify:
+		payload, err := json.Marshal(map[string]interface{}{
+			"title":    notification.Title,
+			"message":  notification.Body,
+			"priority": 5,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal push message: %w", err)
+		}
+
+		req, err = http.NewRequestWithContext(ctx, "POST", strings.TrimRight(sub.Endpoint, "/")+"/message", bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sub.Token != nil {
+			req.Header.Set("X-Gotify-Key", *sub.Token)
+		}
+
+	default:
+		return fmt.Errorf("unknown push provider: %s", sub.Provider)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("push provider returned status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sendEmail sends a plain-text notification email over SMTP to the user's
+// address, or NOTIFY_EMAIL_TO for the workspace
+func (s *NotificationService) sendEmail(userID *int, subject, body string) error {
+	to := s.emailTo
+	if userID != nil {
+		user, err := db.GetUserByID(*userID)
+		if err != nil {
+			return err
+		}
+		if user.Email == nil {
+			return fmt.Errorf("user %d has no email address", *userID)
+		}
+		to = *user.Email
+	}
+
+	if s.smtpHost == "" || s.emailFrom == "" || (userID == nil && to == "") {
+		return fmt.Errorf("email delivery is not configured (SMTP_HOST, SMTP_FROM, NOTIFY_EMAIL_TO)")
+	}
+
+	var auth smtp.Auth
+	if s.smtpUser != "" {
+		auth = smtp.PlainAuth("", s.smtpUser, s.smtpPass, s.smtpHost)
+	}
+
+	// Line breaks would end the header, letting a title or address add its own
+	from, to := headerValue(s.emailFrom), headerValue(to)
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", from, to, headerValue(subject), body)
+
+	return smtp.SendMail(s.smtpHost+":"+s.smtpPort, auth, from, []string{to}, []byte(msg))
+}
+
+// headerValue is s on one line, for a mail header: each run of line breaks
+// becomes a space
+func headerValue(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
+
+// sendWebhook POSTs the notification as JSON to the configured webhook URL
+func (s *NotificationService) sendWebhook(ctx context.Context, notification *models.Notification) error {
+	if s.webhookURL == "" {
+		return fmt.Errorf("webhook delivery is not configured (NOTIFY_WEBHOOK_URL)")
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
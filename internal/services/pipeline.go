@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// BuiltinPipelineSpec is the pipeline run when no default definition is stored:
+// every stage, with content for every platform
+func BuiltinPipelineSpec() models.PipelineSpec {
+	stages := make([]models.PipelineStage, len(models.PipelineStages))
+	for i, name := range models.PipelineStages {
+		stages[i] = models.PipelineStage{Name: name}
+	}
+	return models.PipelineSpec{Stages: stages}
+}
+
+// ValidatePipelineSpec checks that a spec starts with the concepts stage, which
+// every other stage builds on, names each known stage at most once, and fact
+// checks only after generating the content to check
+func ValidatePipelineSpec(spec models.PipelineSpec) error {
+	if len(spec.Stages) == 0 || spec.Stages[0].Name != models.StageConcepts {
+		return fmt.Errorf("the first stage must be %q", models.StageConcepts)
+	}
+
+	seen := make(map[string]bool, len(spec.Stages))
+	for _, stage := range spec.Stages {
+		if !slices.Contains(models.PipelineStages, stage.Name) {
+			return fmt.Errorf("unknown stage %q", stage.Name)
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("stage %q is listed more than once", stage.Name)
+		}
+		seen[stage.Name] = true
+
+		if len(stage.Platforms) > 0 && stage.Name != models.StageContent {
+			return fmt.Errorf("platforms only apply to the %q stage", models.StageContent)
+		}
+		if stage.Questions != nil && stage.Name != models.StageQuizzes {
+			return fmt.Errorf("questions only apply to the %q stage", models.StageQuizzes)
+		}
+		if stage.Concepts != nil && stage.Name != models.StageConcepts {
+			return fmt.Errorf("concepts only apply to the %q stage", models.StageConcepts)
+		}
+		if stage.TargetGrade != nil && stage.Name != models.StageContent {
+			return fmt.Errorf("target_grade only applies to the %q stage", models.StageContent)
+		}
+		if stage.Name == models.StageFactCheck && !seen[models.StageContent] {
+			return fmt.Errorf("the %q stage must come after the %q stage", models.StageFactCheck, models.StageContent)
+		}
+		for _, platform := range stage.Platforms {
+			if !slices.Contains(models.ContentPlatforms, platform) {
+				return fmt.Errorf("unknown platform %q", platform)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolvePipelineSpec returns the spec of the given pipeline definition, or of
+// the default definition when pipelineID is nil, falling back to the built-in pipeline
+func resolvePipelineSpec(pipelineID *int) (models.PipelineSpec, error) {
+	var definition *models.PipelineDefinition
+	var err error
+	if pipelineID != nil {
+		definition, err = db.GetPipelineDefinitionByID(*pipelineID)
+	} else {
+		definition, err = db.GetDefaultPipelineDefinition()
+	}
+	if err != nil {
+		return models.PipelineSpec{}, err
+	}
+
+	if definition == nil {
+		return BuiltinPipelineSpec(), nil
+	}
+
+	log.Printf("Using pipeline %q", definition.Name)
+	return definition.Spec, nil
+}
+
+// sourceLanguage returns a source's transcript language, or "" when unknown
+func sourceLanguage(sourceContent *models.SourceContent) string {
+	if sourceContent.Language == nil {
+		return ""
+	}
+	return *sourceContent.Language
+}
+
+// emptyProcessResult is a result for a saved source before any stage has run
+func emptyProcessResult(sourceContent *models.SourceContent) *ProcessResult {
+	return &ProcessResult{
+		SourceContent:    sourceContent,
+		Concepts:         []models.Concept{},
+		Quizzes:          []models.QuizQuestion{},
+		Glossary:         []models.GlossaryTerm{},
+		ActionItems:      []models.ActionItem{},
+		Mentions:         []models.Mention{},
+		GeneratedContent: []models.GeneratedContent{},
+		Warnings:         []models.StageWarning{},
+	}
+}
+
+// stageWarning reports a whole stage's failure
+func stageWarning(stage string, err error) models.StageWarning {
+	return models.StageWarning{Stage: stage, Message: err.Error()}
+}
+
+// warn records a whole stage's failure on the result
+func (r *ProcessResult) warn(stage string, err error) {
+	r.Warnings = append(r.Warnings, stageWarning(stage, err))
+}
+
+// extractConcepts extracts and saves a source's concepts
+func extractConcepts(ctx context.Context, claudeService *ClaudeService, transcript string, sourceContentID int) ([]models.Concept, error) {
+	log.Printf("Extracting concepts from transcript...")
+	concepts, err := claudeService.ExtractConcepts(ctx, transcript, sourceContentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract concepts: %w", err)
+	}
+
+	// Save concepts to database
+	log.Printf("Saving %d concepts to database...", len(concepts))
+	savedConcepts, err := db.CreateConceptsBatch(concepts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save concepts: %w", err)
+	}
+	WakeEmbedder()
+
+	log.Printf("Concepts saved successfully")
+	return savedConcepts, nil
+}
+
+// runStages runs the stages that follow concept extraction, in order, filling
+// in result and running stage hooks and checkpointing after each. A failed stage is logged,
+// leaves its artifact empty, and adds to result's warnings. Running prompt experiments may swap a stage's prompt.
+func (s *SourceContentService) runStages(ctx context.Context, stages []models.PipelineStage, result *ProcessResult, transcript string) {
+	sourceID := result.SourceContent.ID
+
+	for _, stage := range stages {
+		stage = withPromptExperiment(stage, sourceID)
+		claudeService := s.claudeService.withLanguage(sourceLanguage(result.SourceContent)).forStage(stage)
+
+		s.reportProgress(models.JobEvent{Step: stage.Name, Message: fmt.Sprintf("Running %s", stage.Name)})
+
+		var warnings []models.StageWarning
+		switch stage.Name {
+		case models.StageQuizzes:
+			result.Quizzes, warnings = generateQuizzes(ctx, claudeService, result.Concepts, s.reportProgress)
+		case models.StageGlossary:
+			result.Glossary, warnings = extractGlossary(ctx, claudeService, sourceID, transcript, result.Concepts)
+		case models.StageActionItems:
+			result.ActionItems, warnings = extractActionItems(ctx, claudeService, sourceID, transcript, result.Concepts)
+		case models.StageMentions:
+			result.Mentions, warnings = extractMentions(ctx, claudeService, sourceID, transcript)
+		case models.StageContent:
+			platforms := stage.Platforms
+			if len(platforms) == 0 {
+				platforms = CurrentSettings().DefaultPlatforms
+			}
+			result.GeneratedContent, warnings = generateContent(ctx, claudeService, platforms, result.Concepts, sourceSegments(sourceID, transcript), s.reportProgress)
+		case models.StageFactCheck:
+			contents, err := contentToFactCheck(result)
+			if err != nil {
+				log.Printf("Warning: Failed to get generated content to fact-check: %v", err)
+				warnings = []models.StageWarning{stageWarning(models.StageFactCheck, err)}
+				break
+			}
+			result.GeneratedContent, warnings = factCheckContent(ctx, claudeService, transcript, contents)
+		default:
+			log.Printf("Warning: Skipping unknown pipeline stage %q", stage.Name)
+			continue
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+
+		runHooks(ctx, stage.Name, result)
+		checkpointStage(sourceID, stage.Name)
+		s.reportProgress(models.JobEvent{Step: stage.Name, Status: models.JobStepCompleted, Message: stageSummary(stage.Name, result, len(warnings))})
+	}
+}
+
+// stageSummary describes what a finished stage left on result
+func stageSummary(stage string, result *ProcessResult, failures int) string {
+	var summary string
+	switch stage {
+	case models.StageQuizzes:
+		summary = fmt.Sprintf("Generated %d quiz questions", len(result.Quizzes))
+	case models.StageGlossary:
+		summary = fmt.Sprintf("Extracted %d glossary terms", len(result.Glossary))
+	case models.StageActionItems:
+		summary = fmt.Sprintf("Extracted %d action items", len(result.ActionItems))
+	case models.StageMentions:
+		summary = fmt.Sprintf("Extracted %d mentions", len(result.Mentions))
+	case models.StageContent:
+		summary = fmt.Sprintf("Generated %d content drafts", len(result.GeneratedContent))
+	case models.StageFactCheck:
+		summary = fmt.Sprintf("Fact-checked %d content drafts", len(result.GeneratedContent))
+	default:
+		summary = fmt.Sprintf("Finished %s", stage)
+	}
+	if failures > 0 {
+		summary += fmt.Sprintf(" (%d warnings)", failures)
+	}
+	return summary
+}
+
+// generateContent generates marketing content for each platform, citing the
+// transcript's segments, runs content scripts over it, scores its readability, and saves it, skipping platforms
+// whose generation fails. report is told as each platform finishes.
+func generateContent(ctx context.Context, claudeService *ClaudeService, platforms []string, concepts []models.Concept, segments []models.TranscriptSegment, report func(models.JobEvent)) ([]models.GeneratedContent, []models.StageWarning) {
+	log.Printf("Generating marketing content...")
+	var generatedContents []models.GeneratedContent
+	var warnings []models.StageWarning
+
+	highlights := conceptHighlights(segments, concepts)
+
+	// Prompts lead with the concepts the audience engaged with
+	concepts = slices.Clone(concepts)
+	AttachResonance(concepts)
+
+	for i, platform := range platforms {
+		content, err := claudeService.GenerateContent(ctx, platform, concepts)
+		if err != nil {
+			log.Printf("Warning: Failed to generate %s content: %v", platform, err)
+			warnings = append(warnings, models.StageWarning{
+				Stage:    models.StageContent,
+				Platform: platform,
+				Message:  fmt.Sprintf("failed to generate %s content: %v", platform, err),
+			})
+			report(models.JobEvent{Step: models.StageContent, Platform: platform, Done: i + 1, Total: len(platforms), Message: fmt.Sprintf("Failed to generate %s content", platform)})
+			continue
+		}
+		anchorCitations(content.Citations, segments, highlights)
+		generatedContents = append(generatedContents, *content)
+		report(models.JobEvent{Step: models.StageContent, Platform: platform, Done: i + 1, Total: len(platforms), Message: fmt.Sprintf("Generated %s content", platform)})
+	}
+
+	finishContent(ctx, generatedContents, claudeService.targetGrade)
+
+	// Save generated content to database
+	if len(generatedContents) > 0 {
+		log.Printf("Saving %d generated content pieces to database...", len(generatedContents))
+		savedContent, err := db.CreateGeneratedContentBatch(generatedContents)
+		if err != nil {
+			log.Printf("Warning: Failed to save generated content: %v", err)
+			generatedContents = []models.GeneratedContent{}
+			warnings = append(warnings, stageWarning(models.StageContent, fmt.Errorf("failed to save generated content: %w", err)))
+		} else {
+			generatedContents = savedContent
+			log.Printf("Generated content saved successfully")
+		}
+	}
+
+	return generatedContents, warnings
+}
+
+// finishContent runs the content scripts over newly generated content and
+// scores its readability against targetGrade
+func finishContent(ctx context.Context, contents []models.GeneratedContent, targetGrade *float64) {
+	applyContentScripts(ctx, contents)
+	for i := range contents {
+		contents[i].Readability = scoreReadability(contents[i].Body, targetGrade)
+	}
+}
+
+// RetryStage re-runs one stage of a source's pipeline with the settings for it
+// of the default pipeline, or of the source's profile if it has one. Quizzes are generated for req.ConceptIDs, or
+// for the concepts that have no questions; content for req.Platforms, or
+// the stage's platforms, at req.TargetGrade when set. The result holds that stage's new artifacts and
+// warnings.
+func (s *SourceContentService) RetryStage(ctx context.Context, sourceID int, req models.RetryStageRequest) (*ProcessResult, error) {
+	sourceContent, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := resolveSourceSpec(sourceContent)
+	if err != nil {
+		return nil, err
+	}
+	stage := models.PipelineStage{Name: req.Stage}
+	for _, configured := range spec.Stages {
+		if configured.Name == req.Stage {
+			stage = configured
+		}
+	}
+	if len(req.Platforms) > 0 {
+		stage.Platforms = req.Platforms
+	}
+	if req.TargetGrade != nil {
+		stage.TargetGrade = req.TargetGrade
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Stage == models.StageQuizzes {
+		concepts, err = conceptsToQuiz(sourceID, concepts, req.ConceptIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Retrying %s stage for source content ID: %d", req.Stage, sourceID)
+	result := emptyProcessResult(sourceContent)
+	result.Concepts = concepts
+	s.runStages(ctx, []models.PipelineStage{stage}, result, sourceContent.Transcript)
+
+	return result, nil
+}
+
+// conceptsToQuiz picks the concepts a quizzes retry covers: those in ids, or
+// those without any questions when ids is empty
+func conceptsToQuiz(sourceID int, concepts []models.Concept, ids []int) ([]models.Concept, error) {
+	if len(ids) > 0 {
+		return slices.DeleteFunc(concepts, func(c models.Concept) bool {
+			return !slices.Contains(ids, c.ID)
+		}), nil
+	}
+
+	quizzes, err := db.GetQuizzesBySourceContentID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	quizzed := map[int]bool{}
+	for _, q := range quizzes {
+		quizzed[q.ConceptID] = true
+	}
+
+	return slices.DeleteFunc(concepts, func(c models.Concept) bool {
+		return quizzed[c.ID]
+	}), nil
+}
+
+// startPipelineRun records that a source's run of spec has started. Like the
+// other checkpoint helpers, it logs failures rather than failing the run.
+func startPipelineRun(sourceID int, spec models.PipelineSpec) {
+	if err := db.StartPipelineRun(sourceID, spec); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// checkpointStage records that a stage of a source's run finished
+func checkpointStage(sourceID int, stage string) {
+	if err := db.CompletePipelineStage(sourceID, stage); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// finishPipelineRun records how a source's run ended
+func finishPipelineRun(sourceID int, status string) {
+	if err := db.FinishPipelineRun(sourceID, status); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// ResumePipeline finishes an incomplete run: one interrupted by a restart,
+// or whose concept extraction failed. Stages already checkpointed are
+// skipped, and concepts already saved are kept rather than re-extracted.
+// The result holds all of the source's artifacts and the resumed stages'
+// warnings.
+func (s *SourceContentService) ResumePipeline(ctx context.Context, sourceID int) (*ProcessResult, error) {
+	sourceContent, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := db.GetPipelineRun(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil || run.Status != models.PipelineRunIncomplete {
+		return nil, fmt.Errorf("pipeline run is not incomplete")
+	}
+
+	return s.resumePipeline(ctx, sourceContent, run)
+}
+
+// resumePipeline re-runs the stages of run that haven't completed
+func (s *SourceContentService) resumePipeline(ctx context.Context, sourceContent *models.SourceContent, run *models.PipelineRun) (*ProcessResult, error) {
+	sourceID := sourceContent.ID
+	log.Printf("Resuming pipeline for source content ID: %d (completed: %v)", sourceID, []string(run.CompletedStages))
+	startPipelineRun(sourceID, run.Spec)
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := emptyProcessResult(sourceContent)
+	if len(concepts) == 0 {
+		s.reportProgress(models.JobEvent{Step: models.StageConcepts, Message: "Extracting concepts"})
+		claudeService := s.claudeService.withLanguage(sourceLanguage(sourceContent)).forStage(withPromptExperiment(run.Spec.Stages[0], sourceID))
+		concepts, err = extractConcepts(ctx, claudeService, sourceContent.Transcript, sourceID)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			result.warn(models.StageConcepts, err)
+			finishPipelineRun(sourceID, models.PipelineRunIncomplete)
+			return result, nil
+		}
+		result.Concepts = concepts
+		runHooks(ctx, models.StageConcepts, result)
+		s.reportProgress(models.JobEvent{Step: models.StageConcepts, Status: models.JobStepCompleted, Message: fmt.Sprintf("Extracted %d concepts", len(concepts))})
+	}
+	result.Concepts = concepts
+	checkpointStage(sourceID, models.StageConcepts)
+
+	var remaining []models.PipelineStage
+	for _, stage := range run.Spec.Stages[1:] {
+		if !slices.Contains(run.CompletedStages, stage.Name) {
+			remaining = append(remaining, stage)
+		}
+	}
+	s.runStages(ctx, remaining, result, sourceContent.Transcript)
+	finishPipelineRun(sourceID, models.PipelineRunCompleted)
+
+	full, err := s.getExistingProcessResult(ctx, sourceContent)
+	if err != nil {
+		return nil, err
+	}
+	full.Warnings = result.Warnings
+	return full, nil
+}
+
+// existingProcessResult returns a source that was already ingested, first
+// resuming its run if that was left incomplete
+func (s *SourceContentService) existingProcessResult(ctx context.Context, sourceContent *models.SourceContent) (*ProcessResult, error) {
+	run, err := db.GetPipelineRun(sourceContent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if run != nil && run.Status == models.PipelineRunIncomplete {
+		return s.resumePipeline(ctx, sourceContent, run)
+	}
+	return s.getExistingProcessResult(ctx, sourceContent)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ErrNoSpeechToText is returned for podcast imports when no speech-to-text
+// backend is configured to transcribe them
+var ErrNoSpeechToText = errors.New("speech-to-text is not configured - set STT_PROVIDER, STT_API_KEY, or STT_API_URL")
+
+// EnqueuePodcast queues the newest req.Episodes episodes (one by default) of
+// the RSS feed at req.URL, or the episode whose audio req.URL is, to be
+// transcribed and processed in the background. Episodes the feed says are
+// longer than STT_MAX_DURATION are skipped. The feed, pipeline, and profile
+// are checked now, so mistakes fail the request rather than the jobs.
+func (s *SourceContentService) EnqueuePodcast(ctx context.Context, req models.ImportPodcastRequest) (*models.PodcastImport, error) {
+	if s.audioFallback == nil {
+		return nil, ErrNoSpeechToText
+	}
+
+	feed, err := s.podcastClient.Fetch(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	count := req.Episodes
+	if count == 0 {
+		count = 1
+	}
+
+	// The feed's language, such as "en-us", spares the backend detecting it
+	language, _, _ := strings.Cut(strings.ToLower(feed.Language), "-")
+
+	result := &models.PodcastImport{Feed: feed.Title, Episodes: []models.PodcastEpisode{}}
+	for _, episode := range feed.Episodes[:min(count, len(feed.Episodes))] {
+		title := episode.Title
+		if title == "" {
+			title = feed.Title
+		}
+
+		imported := models.PodcastEpisode{
+			Title:    title,
+			AudioURL: episode.AudioURL,
+			Duration: int(episode.Duration.Seconds()),
+		}
+		if !episode.Published.IsZero() {
+			imported.PublishedAt = &episode.Published
+		}
+
+		if episode.Duration > s.audioFallback.maxDuration {
+			imported.Skipped = fmt.Sprintf("episode is longer than the limit of %s for audio transcription", s.audioFallback.maxDuration)
+			result.Episodes = append(result.Episodes, imported)
+			continue
+		}
+
+		job, err := db.CreateJob(models.CreateSourceContentRequest{
+			Type:          "podcast",
+			URL:           episode.AudioURL,
+			Title:         title,
+			Language:      language,
+			PipelineID:    req.PipelineID,
+			Profile:       req.Profile,
+			QuizQuestions: req.QuizQuestions,
+			TargetGrade:   req.TargetGrade,
+			Scrub:         req.Scrub,
+			OwnerID:       req.OwnerID,
+		}, spec)
+		if err != nil {
+			return nil, err
+		}
+		job.Steps = jobSteps(job, nil)
+		imported.Job = job
+		result.Episodes = append(result.Episodes, imported)
+	}
+	s.wakeJobWorker()
+
+	return result, nil
+}
+
+// ProcessPodcastEpisode downloads and transcribes the podcast episode whose
+// audio is at req.URL, and runs it through the pipeline like
+// ProcessYouTubeURL. Episodes already processed return their existing data.
+func (s *SourceContentService) ProcessPodcastEpisode(ctx context.Context, req models.CreateSourceContentRequest) (*ProcessResult, error) {
+	log.Printf("Processing podcast episode: %s", req.URL)
+
+	existing, err := db.GetSourceContentByURL(req.URL, req.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if existing != nil {
+		log.Printf("Episode already processed, returning existing data for source content ID: %d", existing.ID)
+		s.recordJobSource(existing.ID)
+		return s.existingProcessResult(ctx, existing)
+	}
+
+	if s.audioFallback == nil {
+		return nil, ErrNoSpeechToText
+	}
+
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOwner(req.OwnerID).withScrubOverride(req.Scrub).processPodcastEpisode(ctx, req, spec)
+}
+
+// processPodcastEpisode downloads, transcribes, saves, and runs spec over an
+// episode that hasn't been processed yet. The audio is also kept in object
+// storage, when configured, until RETENTION_AUDIO_AFTER prunes it.
+func (s *SourceContentService) processPodcastEpisode(ctx context.Context, req models.CreateSourceContentRequest, spec models.PipelineSpec) (*ProcessResult, error) {
+	dir, err := os.MkdirTemp("", "lattice-podcast-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.Printf("Downloading episode audio...")
+	path, err := s.podcastClient.DownloadAudio(ctx, req.URL, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download audio: %w", err)
+	}
+
+	s.audioFallback.storeAudio(ctx, req.URL, path)
+
+	log.Printf("Transcribing episode audio...")
+	audio, err := s.audioFallback.transcribeFile(ctx, path, req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.processSource(ctx, models.CreateSourceContentRequest{
+		Type:       "podcast",
+		URL:        req.URL,
+		Title:      req.Title,
+		Transcript: audio.Text,
+		Language:   audio.Language,
+		Words:      audio.Words,
+		Chapters:   audio.Chapters,
+	}, spec)
+}
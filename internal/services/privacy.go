@@ -0,0 +1,394 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+)
+
+// dataExportURLExpiry is how long a finished export's download link works.
+// Fetching the request again signs a new one, until the exports retention
+// policy deletes the archive.
+const dataExportURLExpiry = 24 * time.Hour
+
+// dataObjectPrefixes hold the stored objects that belong to the workspace's
+// data. Exports are left out of exports, and deleted with the workspace.
+var dataObjectPrefixes = []string{
+	storage.PrefixUploads,
+	storage.PrefixAudio,
+	storage.PrefixKeyframes,
+	storage.PrefixTranscripts,
+	storage.PrefixPodcast,
+}
+
+// PrivacyService carries out users' requests to export all their data or
+// delete their accounts, one at a time in the background, and notifies when
+// each finishes
+type PrivacyService struct {
+	notifier *NotificationService
+	wake     chan struct{}
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService() *PrivacyService {
+	return &PrivacyService{
+		notifier: NewNotificationService(),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// RequestExport queues an export of a user's account with the data and
+// files they own, or for admins the whole workspace's. A request already
+// pending is returned instead of queueing another.
+func (s *PrivacyService) RequestExport(userID int) (*models.DataRequest, error) {
+	return s.request(userID, models.DataRequestExport)
+}
+
+// RequestDeletion queues the deletion of a user's account and what they own,
+// and with the last account the workspace's data. The last admin can't be
+// deleted while other users remain, nor the last account without an API
+// token, which would leave the API open.
+func (s *PrivacyService) RequestDeletion(userID int) (*models.DataRequest, error) {
+	if err := db.CheckUserDeletable(userID); err != nil {
+		return nil, err
+	}
+	return s.request(userID, models.DataRequestDelete)
+}
+
+// request queues a data request of kind for a user unless one is pending
+func (s *PrivacyService) request(userID int, kind string) (*models.DataRequest, error) {
+	pending, err := db.GetPendingDataRequest(userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return pending, nil
+	}
+
+	req, err := db.CreateDataRequest(userID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return req, nil
+}
+
+// GetDataRequests lists a user's data requests, newest first, with download
+// links for finished exports
+func GetDataRequests(ctx context.Context, userID int) ([]models.DataRequest, error) {
+	requests, err := db.GetDataRequestsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		signExportURL(ctx, &requests[i])
+	}
+	return requests, nil
+}
+
+// GetDataRequest retrieves one of a user's data requests, with its download
+// link once an export finishes. Other users' requests aren't found.
+func GetDataRequest(ctx context.Context, userID, id int) (*models.DataRequest, error) {
+	req, err := db.GetDataRequestByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == nil || *req.UserID != userID {
+		return nil, fmt.Errorf("data request not found")
+	}
+
+	signExportURL(ctx, req)
+	return req, nil
+}
+
+// signExportURL sets the download link of a finished export whose archive
+// is still stored
+func signExportURL(ctx context.Context, req *models.DataRequest) {
+	if req.ExportKey == nil {
+		return
+	}
+	url, err := storage.Store.SignedURL(ctx, *req.ExportKey, dataExportURLExpiry)
+	if err != nil {
+		log.Printf("Warning: Failed to sign data export %d URL: %v", req.ID, err)
+		return
+	}
+	req.DownloadURL = &url
+}
+
+// StartDataRequests carries out queued data requests until ctx is done,
+// sleeping until a request is made or the next poll when none are queued
+func (s *PrivacyService) StartDataRequests(ctx context.Context) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		req, err := db.ClaimNextDataRequest()
+		if err != nil {
+			log.Printf("Error claiming data request: %v", err)
+		}
+		if req != nil {
+			s.run(ctx, req)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run carries out a claimed data request, records its outcome, and notifies.
+// A panic fails the request instead of taking down the server.
+func (s *PrivacyService) run(ctx context.Context, req *models.DataRequest) {
+	log.Printf("Running data request %d (%s)", req.ID, req.Kind)
+
+	var exportKey *string
+	var result models.JSONObject
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("data request panicked: %v", r)
+			}
+		}()
+		if req.Kind == models.DataRequestExport {
+			exportKey, result, err = s.export(ctx, req)
+		} else {
+			result, err = s.deleteAccount(ctx, req)
+		}
+	}()
+
+	if err != nil && ctx.Err() != nil {
+		// Left running, so the next start requeues it
+		log.Printf("Data request %d interrupted by shutdown: %v", req.ID, err)
+		return
+	}
+
+	var failure *string
+	if err != nil {
+		log.Printf("Data request %d failed: %v", req.ID, err)
+		message := err.Error()
+		failure = &message
+	}
+	if err := db.FinishDataRequest(req.ID, exportKey, result, failure); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	data := models.JSONObject{"data_request_id": req.ID, "kind": req.Kind}
+	// Only the requester hears how it went, unless their account is gone
+	switch {
+	case failure != nil && req.Kind == models.DataRequestExport:
+		s.notifier.NotifyUser(ctx, req.UserID, models.EventDataRequestComplete, "Data export failed", *failure, data)
+	case failure != nil:
+		s.notifier.NotifyUser(ctx, req.UserID, models.EventDataRequestComplete, "Account deletion failed", *failure, data)
+	case req.Kind == models.DataRequestExport:
+		s.notifier.NotifyUser(ctx, req.UserID, models.EventDataRequestComplete, "Your data export is ready",
+			fmt.Sprintf("Download it from GET /api/me/data-requests/%d within %s.", req.ID, dataExportURLExpiry), data)
+	case result["workspace_deleted"] == true:
+		s.notifier.Notify(ctx, models.EventDataRequestComplete, "Account deleted",
+			"The last account was deleted, along with the workspace's sources, content, and files.", data)
+	default:
+		s.notifier.Notify(ctx, models.EventDataRequestComplete, "Account deleted",
+			"An account and its sign-in identities were deleted.", data)
+	}
+}
+
+// export writes a ZIP archive of the user's account with the rows and
+// stored files they own, or for an admin every data table and stored file,
+// and stores it under the user's exports
+func (s *PrivacyService) export(ctx context.Context, req *models.DataRequest) (*string, models.JSONObject, error) {
+	if req.UserID == nil {
+		return nil, nil, fmt.Errorf("the account was deleted before its export ran")
+	}
+	user, err := db.GetUserByID(*req.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "lattice-export-*.zip")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	writeJSON := func(name string, v interface{}) error {
+		w, err := archive.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to export: %w", name, err)
+		}
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	if err := writeJSON("account.json", map[string]interface{}{"user": user, "exported_at": time.Now().UTC()}); err != nil {
+		return nil, nil, err
+	}
+	tables := 0
+	writeTable := func(table string, rows json.RawMessage) error {
+		tables++
+		return writeJSON("data/"+table+".json", rows)
+	}
+
+	workspace := user.Role == models.RoleAdmin
+	var keys []string
+	if workspace {
+		if err := db.ExportWorkspaceData(writeTable); err != nil {
+			return nil, nil, err
+		}
+		for _, prefix := range dataObjectPrefixes {
+			objects, err := storage.Store.List(ctx, prefix)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+			}
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+			}
+		}
+	} else {
+		if err := db.ExportUserData(user.ID, writeTable); err != nil {
+			return nil, nil, err
+		}
+		if keys, err = db.GetUserObjectKeys(user.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	files := 0
+	for _, key := range keys {
+		if err := exportObject(ctx, archive, key); err != nil {
+			return nil, nil, err
+		}
+		files++
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to finish export: %w", err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to size export: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, fmt.Errorf("failed to rewind export: %w", err)
+	}
+
+	key := fmt.Sprintf("%sdata-export-%d.zip", userExportsPrefix(*req.UserID), req.ID)
+	if err := storage.Store.Put(ctx, key, tmp, size, "application/zip"); err != nil {
+		return nil, nil, fmt.Errorf("failed to store export: %w", err)
+	}
+
+	scope := "account"
+	if workspace {
+		scope = "workspace"
+	}
+	return &key, models.JSONObject{"scope": scope, "tables": tables, "files": files, "size": size}, nil
+}
+
+// exportObject copies a stored object into the archive under files/
+func exportObject(ctx context.Context, archive *zip.Writer, key string) error {
+	r, err := storage.Store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil // Deleted since it was listed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer r.Close()
+
+	w, err := archive.Create("files/" + key)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", key, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to export %s: %w", key, err)
+	}
+	return nil
+}
+
+// deleteAccount deletes the user's exports, the files they own, and their
+// account with its rows, and with the last account every data table and
+// stored file. A deletion interrupted after the account went picks up with
+// the files.
+func (s *PrivacyService) deleteAccount(ctx context.Context, req *models.DataRequest) (models.JSONObject, error) {
+	var rows interface{}
+	var workspaceDeleted bool
+	files := 0
+	if req.UserID != nil {
+		if _, err := deleteObjects(ctx, userExportsPrefix(*req.UserID)); err != nil {
+			return nil, err
+		}
+
+		keys, err := db.GetUserObjectKeys(*req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if err := storage.Store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return nil, fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+			files++
+		}
+
+		counts, lastUser, err := db.DeleteUserAccount(req.ID, *req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		rows, workspaceDeleted = counts, lastUser
+	} else {
+		rows = req.Result["rows"]
+		workspaceDeleted = req.Result["workspace_deleted"] == true
+	}
+
+	if workspaceDeleted {
+		for _, prefix := range append(dataObjectPrefixes, storage.PrefixExports) {
+			n, err := deleteObjects(ctx, prefix)
+			files += n
+			if err != nil {
+				return models.JSONObject{"rows": rows, "workspace_deleted": true, "files": files}, err
+			}
+		}
+	}
+
+	log.Printf("Data request %d deleted an account (workspace data: %v, files: %d)", req.ID, workspaceDeleted, files)
+	return models.JSONObject{"rows": rows, "workspace_deleted": workspaceDeleted, "files": files}, nil
+}
+
+// userExportsPrefix is where a user's data exports are stored
+func userExportsPrefix(userID int) string {
+	return storage.PrefixExports + "users/" + strconv.Itoa(userID) + "/"
+}
+
+// deleteObjects deletes every object stored under prefix, returning how many
+func deleteObjects(ctx context.Context, prefix string) (int, error) {
+	objects, err := storage.Store.List(ctx, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	for i, object := range objects {
+		if err := storage.Store.Delete(ctx, object.Key); err != nil {
+			return i, fmt.Errorf("failed to delete %s: %w", object.Key, err)
+		}
+	}
+	return len(objects), nil
+}
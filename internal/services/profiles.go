@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ValidateProfile checks that a profile's pipeline, if it names one, exists
+func ValidateProfile(req models.ProcessingProfileRequest) error {
+	if req.PipelineID != nil {
+		if _, err := db.GetPipelineDefinitionByID(*req.PipelineID); err != nil {
+			if err.Error() == "pipeline not found" {
+				return fmt.Errorf("pipeline %d not found", *req.PipelineID)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyProfile adjusts a pipeline spec for a profile: its concept count on the
+// concepts stage, its platforms on the content stage, and its instructions
+// appended to each stage's own
+func applyProfile(spec models.PipelineSpec, profile models.ProcessingProfile) models.PipelineSpec {
+	stages := slices.Clone(spec.Stages)
+	for i := range stages {
+		stage := &stages[i]
+		switch {
+		case stage.Name == models.StageConcepts && profile.Concepts != nil:
+			stage.Concepts = profile.Concepts
+		case stage.Name == models.StageContent && len(profile.Platforms) > 0:
+			stage.Platforms = profile.Platforms
+		}
+
+		if extra := strings.TrimSpace(profile.Instructions[stage.Name]); extra != "" {
+			if stage.Instructions == "" {
+				stage.Instructions = extra
+			} else {
+				stage.Instructions += "\n\n" + extra
+			}
+		}
+	}
+
+	return models.PipelineSpec{Stages: stages, Profile: profile.Name}
+}
+
+// resolveSourceSpec returns the pipeline an existing source's stages re-run
+// with: its profile's, adjusted by the profile, while that still exists, and
+// otherwise the default
+func resolveSourceSpec(sourceContent *models.SourceContent) (models.PipelineSpec, error) {
+	if sourceContent.Profile == nil {
+		return resolvePipelineSpec(nil)
+	}
+
+	spec, err := resolveIngestSpec(*sourceContent.Profile, nil, nil, nil)
+	if err != nil && err.Error() == "profile not found" {
+		log.Printf("Warning: Profile %q of source content ID %d no longer exists; using the default pipeline", *sourceContent.Profile, sourceContent.ID)
+		return resolvePipelineSpec(nil)
+	}
+	return spec, err
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// progressBuffer is how many events a slow subscriber can fall behind by
+// before further events are dropped for it
+const progressBuffer = 64
+
+// progressBroker fans out the progress of jobs run by this server's workers
+// to the clients following them
+type progressBroker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan models.JobEvent]bool // By job ID
+}
+
+// jobProgress carries the progress of every job this server runs
+var jobProgress = &progressBroker{subscribers: map[int]map[chan models.JobEvent]bool{}}
+
+// SubscribeJobProgress follows a job's progress events. The channel is closed
+// once the job finishes, after its outcome is saved. Call unsubscribe when done
+// following it.
+func SubscribeJobProgress(jobID int) (events <-chan models.JobEvent, unsubscribe func()) {
+	ch := make(chan models.JobEvent, progressBuffer)
+
+	jobProgress.mu.Lock()
+	if jobProgress.subscribers[jobID] == nil {
+		jobProgress.subscribers[jobID] = map[chan models.JobEvent]bool{}
+	}
+	jobProgress.subscribers[jobID][ch] = true
+	jobProgress.mu.Unlock()
+
+	return ch, func() {
+		jobProgress.mu.Lock()
+		defer jobProgress.mu.Unlock()
+		if jobProgress.subscribers[jobID][ch] {
+			delete(jobProgress.subscribers[jobID], ch)
+			if len(jobProgress.subscribers[jobID]) == 0 {
+				delete(jobProgress.subscribers, jobID)
+			}
+			close(ch)
+		}
+	}
+}
+
+// publish sends event to a job's subscribers, never blocking the job
+func (b *progressBroker) publish(jobID int, event models.JobEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[jobID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// finish closes a finished job's subscriptions
+func (b *progressBroker) finish(jobID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[jobID] {
+		close(ch)
+	}
+	delete(b.subscribers, jobID)
+}
+
+// reportProgress publishes progress on the service's job, if it runs for one
+func (s *SourceContentService) reportProgress(event models.JobEvent) {
+	if s.jobID == 0 {
+		return
+	}
+	if event.Status == "" {
+		event.Status = models.JobStepRunning
+	}
+	event.Time = time.Now().UTC()
+	jobProgress.publish(s.jobID, event)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// PromptNames are the system prompts PROMPTS_DIR can replace, each with a
+// file named after it plus .txt
+var PromptNames = []string{
+	"action_items",
+	"compare_sources",
+	"concepts",
+	"content_blog",
+	"content_email",
+	"content_linkedin",
+	"content_twitter",
+	"diagram",
+	"eval_judge",
+	"explain_question",
+	"extract_claims",
+	"glossary",
+	"mentions",
+	"moderate",
+	"plan_series",
+	"quiz",
+	"refresh_content",
+	"relationships",
+	"simplify_question",
+	"split_concept",
+	"translate",
+	"verify_claims",
+}
+
+// promptOverrides holds the system prompts read from PROMPTS_DIR by name,
+// swapped whole when they're reloaded
+var promptOverrides atomic.Pointer[map[string]string]
+
+// LoadPrompts reads the system prompt overrides in PROMPTS_DIR and puts them
+// into effect for requests made from now on. With PROMPTS_DIR unset, the
+// built-in prompts are used.
+func LoadPrompts() error {
+	activate, err := PreparePrompts()
+	if err != nil {
+		return err
+	}
+	activate()
+	return nil
+}
+
+// PreparePrompts reads the prompt overrides as LoadPrompts does, and returns
+// the function that puts them into effect
+func PreparePrompts() (func(), error) {
+	prompts, err := readPrompts()
+	if err != nil {
+		return nil, err
+	}
+	return func() { promptOverrides.Store(&prompts) }, nil
+}
+
+// readPrompts reads the .txt files in PROMPTS_DIR by name. Any other .txt
+// file is an error, so a misspelt name isn't silently ignored.
+func readPrompts() (map[string]string, error) {
+	prompts := map[string]string{}
+	dir := os.Getenv("PROMPTS_DIR")
+	if dir == "" {
+		return prompts, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PROMPTS_DIR: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if !slices.Contains(PromptNames, name) {
+			return nil, fmt.Errorf("invalid PROMPTS_DIR: unknown prompt %q", entry.Name())
+		}
+
+		text, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Removed while reading
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt %q: %w", entry.Name(), err)
+		}
+		if prompt := strings.TrimSpace(string(text)); prompt != "" {
+			prompts[name] = prompt
+		}
+	}
+
+	return prompts, nil
+}
+
+// PromptOverrides returns the names of the system prompts PROMPTS_DIR
+// replaces, sorted
+func PromptOverrides() []string {
+	names := []string{}
+	if prompts := promptOverrides.Load(); prompts != nil {
+		for name := range *prompts {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// promptFor returns the system prompt named name: its override from
+// PROMPTS_DIR, or fallback
+func promptFor(name, fallback string) string {
+	if prompts := promptOverrides.Load(); prompts != nil {
+		if prompt, ok := (*prompts)[name]; ok {
+			return prompt
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// defaultDuplicateThreshold is the question similarity at or above which a
+// generated question counts as a near-duplicate
+const defaultDuplicateThreshold = 0.7
+
+// duplicateThreshold returns QUIZ_DUPLICATE_THRESHOLD, or the default
+func duplicateThreshold() float64 {
+	if str := os.Getenv("QUIZ_DUPLICATE_THRESHOLD"); str != "" {
+		if threshold, err := strconv.ParseFloat(str, 64); err == nil && threshold > 0 && threshold <= 1 {
+			return threshold
+		}
+	}
+	return defaultDuplicateThreshold
+}
+
+// dedupeQuestions drops candidates that are near-duplicates of an existing
+// question or of an earlier candidate, returning the rest in order
+func dedupeQuestions(candidates, existing []models.QuizQuestion) []models.QuizQuestion {
+	threshold := duplicateThreshold()
+
+	seen := make([]map[string]int, 0, len(existing)+len(candidates))
+	for _, q := range existing {
+		seen = append(seen, trigrams(q.Question))
+	}
+
+	kept := make([]models.QuizQuestion, 0, len(candidates))
+	for _, q := range candidates {
+		grams := trigrams(q.Question)
+
+		duplicate := false
+		for _, other := range seen {
+			if diceSimilarity(grams, other) >= threshold {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			log.Printf("Discarding near-duplicate quiz question for concept %d: %q", q.ConceptID, q.Question)
+			continue
+		}
+
+		seen = append(seen, grams)
+		kept = append(kept, q)
+	}
+
+	return kept
+}
+
+// trigrams counts the character trigrams of text, normalized to lowercase
+// letters and digits separated by single spaces, so punctuation and case
+// changes don't hide a duplicate
+func trigrams(text string) map[string]int {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+
+	runes := []rune(" " + strings.TrimSpace(b.String()) + " ")
+	grams := make(map[string]int, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])]++
+	}
+	return grams
+}
+
+// diceSimilarity is the Sørensen–Dice coefficient of two trigram multisets:
+// 1 for identical text, 0 for nothing in common
+func diceSimilarity(a, b map[string]int) float64 {
+	total := 0
+	for _, n := range a {
+		total += n
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	shared := 0
+	for gram, n := range a {
+		shared += min(n, b[gram])
+	}
+	return 2 * float64(shared) / float64(total)
+}
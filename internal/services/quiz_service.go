@@ -0,0 +1,707 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// optionLetters are the canonical option letters in stored order
+const optionLetters = "ABCD"
+
+// weakMasteryLevel is the mastery level at or below which a concept is reviewed
+// even when it isn't due yet
+const weakMasteryLevel = 2
+
+// QuizService serves quiz questions with shuffled options, grades answers
+// against the canonical correct answer, and expands explanations
+type QuizService struct {
+	claudeService  *ClaudeService
+	leechThreshold int
+}
+
+// NewQuizService creates a new quiz service
+func NewQuizService() (*QuizService, error) {
+	claudeService, err := NewClaudeService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Claude service: %w", err)
+	}
+
+	// Anki suspends a card after 8 lapses by default
+	leechThreshold := 8
+	if thresholdStr := os.Getenv("LEECH_THRESHOLD"); thresholdStr != "" {
+		if threshold, err := strconv.Atoi(thresholdStr); err == nil && threshold > 0 {
+			leechThreshold = threshold
+		}
+	}
+
+	return &QuizService{
+		claudeService:  claudeService,
+		leechThreshold: leechThreshold,
+	}, nil
+}
+
+// StartSession creates a quiz session over a concept's or source's questions.
+// Question order and each question's option order are shuffled, which also
+// evens out Claude's bias toward certain correct-answer letters. A concept or
+// source req.OwnerID can't see is not found.
+func (s *QuizService) StartSession(ctx context.Context, req models.CreateQuizSessionRequest) (*models.QuizSessionDetail, error) {
+	if (req.SourceContentID == nil) == (req.ConceptID == nil) {
+		return nil, fmt.Errorf("exactly one of source_content_id or concept_id is required")
+	}
+
+	table, id, missing := "concepts", req.ConceptID, "concept not found"
+	if req.SourceContentID != nil {
+		table, id, missing = "source_contents", req.SourceContentID, "source content not found"
+	}
+	hidden, err := db.CountHidden(table, []int{*id}, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden > 0 {
+		return nil, fmt.Errorf("%s", missing)
+	}
+
+	questions, err := s.sessionQuestions(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("no quiz questions available")
+	}
+
+	rand.Shuffle(len(questions), func(i, j int) {
+		questions[i], questions[j] = questions[j], questions[i]
+	})
+
+	return createSession(models.QuizSession{
+		SourceContentID: req.SourceContentID,
+		ConceptID:        req.ConceptID,
+		Kind:             models.SessionKindQuiz,
+		TimeLimitSeconds: req.TimeLimitSeconds,
+		OwnerID:          req.UserID,
+	}, questions)
+}
+
+// StartReviewSession creates a themed review session over concepts due today
+// (in req.Location) or weak, for a tag, source, and/or collection. The most
+// overdue and weakest concepts are served first. Returns nil when nothing
+// needs review.
+func (s *QuizService) StartReviewSession(ctx context.Context, req models.ReviewSessionRequest) (*models.QuizSessionDetail, error) {
+	if req.Tag == nil && req.SourceContentID == nil && req.CollectionID == nil {
+		return nil, fmt.Errorf("tag, source_content_id, or collection_id is required")
+	}
+	if req.Tag != nil {
+		tag := strings.ToLower(strings.TrimSpace(*req.Tag))
+		req.Tag = &tag
+	}
+
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var conceptIDs []int
+	if req.CollectionID != nil {
+		_, matched, _, err := CollectionConcepts(*req.CollectionID, loc, req.OwnerID, models.Page{Limit: maxCollectionConcepts})
+		if err != nil {
+			return nil, err
+		}
+		conceptIDs = make([]int, len(matched))
+		for i, c := range matched {
+			conceptIDs[i] = c.ID
+		}
+	}
+
+	concepts, err := db.GetReviewConcepts(req.Tag, req.SourceContentID, conceptIDs, weakMasteryLevel, endOfDay(time.Now(), loc), req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []models.QuizQuestion
+	for _, concept := range concepts {
+		if req.Limit > 0 && len(questions) >= req.Limit {
+			break
+		}
+
+		quizzes, err := db.GetQuizzesByConceptID(concept.ID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// Shuffle within a concept so priority order across concepts is kept
+		rand.Shuffle(len(quizzes), func(i, j int) {
+			quizzes[i], quizzes[j] = quizzes[j], quizzes[i]
+		})
+		questions = append(questions, quizzes...)
+	}
+	if req.Limit > 0 && len(questions) > req.Limit {
+		questions = questions[:req.Limit]
+	}
+	if len(questions) == 0 {
+		return nil, nil
+	}
+
+	return createSession(models.QuizSession{
+		SourceContentID: req.SourceContentID,
+		Kind:            models.SessionKindReview,
+		Tag:             req.Tag,
+		CollectionID:    req.CollectionID,
+		OwnerID:         req.UserID,
+	}, questions)
+}
+
+// GetSession retrieves a session with its questions in the order and option
+// layout they were originally served, and its score so far
+func (s *QuizService) GetSession(ctx context.Context, id int) (*models.QuizSessionDetail, error) {
+	session, err := db.GetQuizSessionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := db.GetQuizSessionQuestions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	served := make([]models.ServedQuestion, 0, len(mappings))
+	for _, m := range mappings {
+		q, err := db.GetQuizQuestionByID(m.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+		served = append(served, serveQuestion(*q, m))
+	}
+
+	attempts, err := db.GetQuizSessionAttempts(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.QuizSessionDetail{
+		Session:   *session,
+		Questions: served,
+		Score:     scoreSession(*session, len(mappings), attempts),
+	}, nil
+}
+
+// AnswerInSession grades an answer given in displayed letters, records the
+// attempt with the canonical letter, reschedules the concept from the learner's
+// confidence rating, and reports the correct displayed letter
+func (s *QuizService) AnswerInSession(ctx context.Context, sessionID int, req models.AnswerQuizRequest) (*models.SessionAnswerResponse, error) {
+	mapping, err := db.GetQuizSessionQuestion(sessionID, req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := db.GetQuizQuestionByID(req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := db.GetQuizSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	latency, err := db.GetQuizSessionElapsedMs(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	timedOut := session.TimeLimitSeconds != nil && latency > *session.TimeLimitSeconds*1000
+
+	displayed := strings.ToUpper(req.SelectedAnswer)
+	canonical := canonicalAnswer(mapping.OptionOrder, displayed)
+	correct := canonical == question.CorrectAnswer
+	rating := effectiveRating(correct, req.Rating)
+
+	attempt, leech, progress, err := s.recordAnswer(question, models.QuizAttempt{
+		QuestionID:      question.ID,
+		SelectedAnswer:  canonical,
+		Correct:         correct,
+		SessionID:       &sessionID,
+		DisplayedAnswer: &displayed,
+		Rating:          &rating,
+		TimeToAnswerMs:  req.TimeToAnswerMs,
+		LatencyMs:       &latency,
+		TimedOut:        timedOut,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SessionAnswerResponse{
+		AttemptID:     attempt.ID,
+		Correct:       correct,
+		CorrectAnswer: displayedAnswer(mapping.OptionOrder, question.CorrectAnswer),
+		Explanation:   question.Explanation,
+		Rationale:     question.DistractorRationales[canonical],
+		Rating:        rating,
+		Leech:         leech,
+		LatencyMs:     latency,
+		TimedOut:      timedOut,
+		Progress:      progress,
+	}, nil
+}
+
+// Answer grades an answer to a question outside any session, records the
+// attempt, and reschedules the concept from the learner's confidence rating
+func (s *QuizService) Answer(ctx context.Context, req models.AnswerQuizRequest) (*models.AnswerQuizResponse, error) {
+	question, err := db.GetQuizQuestionByID(req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := strings.ToUpper(req.SelectedAnswer)
+	correct := selected == question.CorrectAnswer
+	rating := effectiveRating(correct, req.Rating)
+
+	attempt, leech, progress, err := s.recordAnswer(question, models.QuizAttempt{
+		QuestionID:     question.ID,
+		SelectedAnswer: selected,
+		Correct:        correct,
+		Rating:         &rating,
+		TimeToAnswerMs: req.TimeToAnswerMs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.AnswerQuizResponse{
+		AttemptID:     attempt.ID,
+		Correct:       correct,
+		CorrectAnswer: question.CorrectAnswer,
+		Explanation:   question.Explanation,
+		Rationale:     question.DistractorRationales[selected],
+		Rating:        rating,
+		Leech:         leech,
+		MasteryLevel:  progress.MasteryLevel,
+		Progress:      progress,
+	}
+	if progress.NextReviewAt != nil {
+		resp.NextReviewAt = *progress.NextReviewAt
+	}
+	return resp, nil
+}
+
+// recordAnswer saves an attempt at a question, counts a wrong one as a lapse
+// (suspending the question once it's a leech), and reschedules the question's
+// concept from the attempt's rating
+func (s *QuizService) recordAnswer(question *models.QuizQuestion, attempt models.QuizAttempt) (*models.QuizAttempt, bool, *models.LearningProgress, error) {
+	saved, err := db.CreateQuizAttempt(attempt)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	leech := false
+	if !attempt.Correct {
+		leech, err = db.RecordQuestionLapse(question.ID, s.leechThreshold)
+		if err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	progress, err := db.GetLearningProgressByConceptID(question.ConceptID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	progress, err = db.UpsertLearningProgress(scheduleReview(question.ConceptID, progress, *attempt.Rating, time.Now()))
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	return saved, leech, progress, nil
+}
+
+// ExplainMore returns a deeper explanation for a question, generating and
+// caching it on first request. refresh forces regeneration.
+func (s *QuizService) ExplainMore(ctx context.Context, questionID int, refresh bool) (*models.QuizExplanation, bool, error) {
+	if !refresh {
+		cached, err := db.GetQuizExplanation(questionID)
+		if err != nil {
+			return nil, false, err
+		}
+		if cached != nil {
+			return cached, true, nil
+		}
+	}
+
+	question, err := db.GetQuizQuestionByID(questionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	concept, err := db.GetConceptByID(question.ConceptID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var source *models.SourceContent
+	if concept.SourceContentID != nil {
+		source, err = db.GetSourceContentByID(*concept.SourceContentID)
+		if err != nil {
+			// Explanations still work from the concept alone
+			source = nil
+		}
+	}
+
+	explanation, err := s.claudeService.ExplainQuestion(ctx, *question, *concept, source)
+	if err != nil {
+		return nil, false, err
+	}
+
+	saved, err := db.UpsertQuizExplanation(*explanation)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return saved, false, nil
+}
+
+// GetLeeches lists suspended leech questions owner can see
+func (s *QuizService) GetLeeches(ctx context.Context, owner *int) ([]models.QuizQuestion, error) {
+	return db.GetLeechQuestions(owner)
+}
+
+// RegenerateLeech replaces a leech with a simpler variant of the question. The
+// original stays suspended so its attempt history is kept.
+func (s *QuizService) RegenerateLeech(ctx context.Context, questionID int) (*models.QuizQuestion, error) {
+	question, concept, err := s.leech(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := s.claudeService.SimplifyQuestion(ctx, *question, *concept)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := db.CreateQuizBatch([]models.QuizQuestion{*variant})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created[0], nil
+}
+
+// ConvertLeechToFlashcard turns a leech into a front/back flashcard built from
+// the question, its correct option, and the explanation. The original stays suspended.
+func (s *QuizService) ConvertLeechToFlashcard(ctx context.Context, questionID int) (*models.Flashcard, error) {
+	question, _, err := s.leech(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := map[string]string{
+		"A": question.OptionA,
+		"B": question.OptionB,
+		"C": question.OptionC,
+		"D": question.OptionD,
+	}
+
+	back := options[question.CorrectAnswer]
+	if question.Explanation != "" {
+		back += "\n\n" + question.Explanation
+	}
+
+	return db.CreateFlashcard(models.Flashcard{
+		ConceptID:  question.ConceptID,
+		QuestionID: &question.ID,
+		Front:      question.Question,
+		Back:       back,
+	})
+}
+
+// UnsuspendQuestion returns a suspended question to sessions with its lapses reset
+func (s *QuizService) UnsuspendQuestion(ctx context.Context, questionID int) (*models.QuizQuestion, error) {
+	return db.SetQuizQuestionSuspended(questionID, false)
+}
+
+// leech loads a suspended question and its concept
+func (s *QuizService) leech(questionID int) (*models.QuizQuestion, *models.Concept, error) {
+	question, err := db.GetQuizQuestionByID(questionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if question.SuspendedAt == nil {
+		return nil, nil, fmt.Errorf("quiz question is not a leech")
+	}
+
+	concept, err := db.GetConceptByID(question.ConceptID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return question, concept, nil
+}
+
+// GetQuestionStats computes attempt, timing, option, and discrimination stats for a question
+func (s *QuizService) GetQuestionStats(ctx context.Context, questionID int) (*models.QuestionStats, error) {
+	if _, err := db.GetQuizQuestionByID(questionID); err != nil {
+		return nil, err
+	}
+
+	return questionStats(questionID)
+}
+
+// GetConceptStats computes stats for each of a concept's questions, including
+// suspended leeches, plus concept-wide totals
+func (s *QuizService) GetConceptStats(ctx context.Context, conceptID int) (*models.ConceptStats, error) {
+	if _, err := db.GetConceptByID(conceptID); err != nil {
+		return nil, err
+	}
+
+	quizzes, err := db.GetQuizzesByConceptID(conceptID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := db.GetAttemptSummaryByConceptID(conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	avgTime, err := db.GetConceptAverageTimeToAnswer(conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.ConceptStats{
+		ConceptID:         conceptID,
+		Attempts:          *attempts,
+		AvgTimeToAnswerMs: avgTime,
+		Questions:         make([]models.QuestionStats, 0, len(quizzes)),
+	}
+	for _, q := range quizzes {
+		qs, err := questionStats(q.ID)
+		if err != nil {
+			return nil, err
+		}
+		stats.Questions = append(stats.Questions, *qs)
+	}
+
+	return stats, nil
+}
+
+// sessionQuestions loads the questions a new session draws from, skipping
+// archived concepts and suspended leeches
+func (s *QuizService) sessionQuestions(req models.CreateQuizSessionRequest) ([]models.QuizQuestion, error) {
+	if req.ConceptID != nil {
+		concept, err := db.GetConceptByID(*req.ConceptID)
+		if err != nil {
+			return nil, err
+		}
+		if concept.ArchivedAt != nil {
+			return nil, fmt.Errorf("concept is archived")
+		}
+		return db.GetQuizzesByConceptID(concept.ID, false)
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(*req.SourceContentID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []models.QuizQuestion
+	for _, concept := range concepts {
+		quizzes, err := db.GetQuizzesByConceptID(concept.ID, false)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, quizzes...)
+	}
+
+	return questions, nil
+}
+
+// minDiscriminationSamples is the number of session attempts needed before a
+// discrimination index is reported
+const minDiscriminationSamples = 5
+
+// questionStats loads a question's stats and computes its discrimination index
+func questionStats(questionID int) (*models.QuestionStats, error) {
+	stats, err := db.GetQuestionAttemptStats(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := db.GetDiscriminationSamples(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.DiscriminationSamples = len(samples)
+	if len(samples) >= minDiscriminationSamples {
+		stats.Discrimination = pointBiserial(samples)
+	}
+
+	return stats, nil
+}
+
+// pointBiserial correlates correctness with rest-of-session score. Returns nil
+// when either variable has no variance, since the correlation is undefined.
+func pointBiserial(samples []models.DiscriminationSample) *float64 {
+	var n, sumX, sumY, sumXY, sumXX, sumYY float64
+	for _, s := range samples {
+		x := 0.0
+		if s.Correct {
+			x = 1
+		}
+		y := s.RestScore
+
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+		sumYY += y * y
+	}
+
+	cov := sumXY/n - (sumX/n)*(sumY/n)
+	varX := sumXX/n - (sumX/n)*(sumX/n)
+	varY := sumYY/n - (sumY/n)*(sumY/n)
+	if varX <= 0 || varY <= 0 {
+		return nil
+	}
+
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
+
+// scoreSession scores the first attempt at each question in a session
+func scoreSession(session models.QuizSession, questionCount int, attempts []models.QuizAttempt) *models.QuizSessionScore {
+	score := &models.QuizSessionScore{MaxPoints: questionCount}
+
+	seen := map[int]bool{}
+	var latencyTotal, latencyCount int
+	for _, a := range attempts {
+		if seen[a.QuestionID] {
+			continue
+		}
+		seen[a.QuestionID] = true
+
+		score.Answered++
+		if a.LatencyMs != nil {
+			latencyTotal += *a.LatencyMs
+			latencyCount++
+		}
+		if a.TimedOut {
+			score.TimedOut++
+		}
+		if !a.Correct {
+			continue
+		}
+		score.Correct++
+
+		switch {
+		case a.TimedOut:
+			// No points past the limit
+		case session.TimeLimitSeconds != nil && a.LatencyMs != nil:
+			fraction := float64(*a.LatencyMs) / float64(*session.TimeLimitSeconds*1000)
+			score.Points += 1 - 0.5*math.Min(1, fraction)
+		default:
+			score.Points++
+		}
+	}
+
+	if score.Answered > 0 {
+		score.Accuracy = float64(score.Correct) / float64(score.Answered)
+	}
+	if latencyCount > 0 {
+		avg := float64(latencyTotal) / float64(latencyCount)
+		score.AvgLatencyMs = &avg
+	}
+	score.Points = math.Round(score.Points*100) / 100
+
+	return score
+}
+
+// createSession stores a session serving questions in the given order, each with
+// its options shuffled
+func createSession(session models.QuizSession, questions []models.QuizQuestion) (*models.QuizSessionDetail, error) {
+	mappings := make([]models.QuizSessionQuestion, len(questions))
+	for i, q := range questions {
+		mappings[i] = models.QuizSessionQuestion{
+			QuestionID:  q.ID,
+			Position:    i,
+			OptionOrder: shuffledOptionOrder(),
+		}
+	}
+
+	created, err := db.CreateQuizSession(session, mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	served := make([]models.ServedQuestion, len(questions))
+	for i, q := range questions {
+		served[i] = serveQuestion(q, mappings[i])
+	}
+
+	return &models.QuizSessionDetail{
+		Session:   *created,
+		Questions: served,
+	}, nil
+}
+
+// shuffledOptionOrder returns a random permutation of the option letters
+func shuffledOptionOrder() string {
+	order := []byte(optionLetters)
+	rand.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return string(order)
+}
+
+// canonicalAnswer maps a displayed letter back to the stored option letter
+func canonicalAnswer(optionOrder, displayed string) string {
+	idx := strings.Index(optionLetters, displayed)
+	if idx < 0 || idx >= len(optionOrder) {
+		return displayed
+	}
+	return string(optionOrder[idx])
+}
+
+// displayedAnswer maps a stored option letter to the letter it was shown under
+func displayedAnswer(optionOrder, canonical string) string {
+	idx := strings.Index(optionOrder, canonical)
+	if idx < 0 {
+		return canonical
+	}
+	return string(optionLetters[idx])
+}
+
+// serveQuestion lays out a question's options in the session's displayed order
+func serveQuestion(q models.QuizQuestion, m models.QuizSessionQuestion) models.ServedQuestion {
+	options := map[byte]string{
+		'A': q.OptionA,
+		'B': q.OptionB,
+		'C': q.OptionC,
+		'D': q.OptionD,
+	}
+
+	order := m.OptionOrder
+	if len(order) != len(optionLetters) {
+		order = optionLetters
+	}
+
+	return models.ServedQuestion{
+		QuestionID: q.ID,
+		ConceptID:  q.ConceptID,
+		Position:   m.Position,
+		Question:   q.Question,
+		OptionA:    options[order[0]],
+		OptionB:    options[order[1]],
+		OptionC:    options[order[2]],
+		OptionD:    options[order[3]],
+	}
+}
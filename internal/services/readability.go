@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// readingWordsPerMinute is the average adult's silent reading speed
+const readingWordsPerMinute = 238
+
+var (
+	// markdownCodePattern matches fenced code blocks and inline code, which aren't prose
+	markdownCodePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// markdownLinkPattern matches Markdown links and images, keeping their text
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	// bareURLPattern matches URLs left in the text
+	bareURLPattern = regexp.MustCompile(`https?://\S+`)
+	// markdownLinePattern matches heading, quote, and list markers at the start of a line
+	markdownLinePattern = regexp.MustCompile(`(?m)^\s*(?:#{1,6}|>|[-*+]|\d+[.)])\s+`)
+	// sentenceEndPattern matches sentence-ending punctuation and line breaks,
+	// so headings and list items count as sentences of their own
+	sentenceEndPattern = regexp.MustCompile(`[.!?]+(?:["')\]]*)(?:\s+|$)|\n+`)
+	// wordPattern matches words, including contractions and numbers
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’][\p{L}]+)*`)
+	// vowelGroupPattern matches the vowel runs words' syllables are counted by
+	vowelGroupPattern = regexp.MustCompile(`[aeiouy]+`)
+	// passivePattern matches a form of "be" or "get", an optional adverb, and
+	// a word that may be a past participle
+	passivePattern = regexp.MustCompile(`(?i)\b(?:am|is|are|was|were|be|been|being|get|gets|got|gotten)\s+(?:\w+ly\s+)?(\w+)\b`)
+)
+
+// irregularParticiples are common past participles that don't end in -ed
+var irregularParticiples = map[string]bool{
+	"become": true, "begun": true, "bitten": true, "bought": true, "broken": true, "brought": true,
+	"built": true, "caught": true, "chosen": true, "cut": true, "done": true, "drawn": true,
+	"driven": true, "eaten": true, "fallen": true, "felt": true, "forbidden": true, "forgotten": true,
+	"found": true, "frozen": true, "given": true, "grown": true, "heard": true, "held": true,
+	"hidden": true, "hit": true, "hurt": true, "kept": true, "known": true, "laid": true,
+	"led": true, "left": true, "lost": true, "made": true, "meant": true, "met": true,
+	"mistaken": true, "overcome": true, "overtaken": true, "paid": true, "proven": true, "put": true,
+	"read": true, "ridden": true, "run": true, "said": true, "seen": true, "sent": true,
+	"set": true, "shaken": true, "shown": true, "shut": true, "sold": true, "spent": true,
+	"spoken": true, "spread": true, "stolen": true, "struck": true, "taken": true, "taught": true,
+	"thought": true, "thrown": true, "told": true, "undertaken": true, "understood": true, "won": true,
+	"written": true,
+}
+
+// notParticiples end in -ed without being past participles
+var notParticiples = map[string]bool{
+	"bed": true, "embed": true, "feed": true, "indeed": true, "need": true, "red": true,
+	"seed": true, "shed": true, "speed": true,
+}
+
+// scoreReadability scores a generated body's reading time, Flesch-Kincaid
+// grade level, and share of passive sentences, ignoring its Markdown
+func scoreReadability(body string, targetGrade *float64) *models.Readability {
+	text := markdownCodePattern.ReplaceAllString(body, " ")
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = bareURLPattern.ReplaceAllString(text, " ")
+	text = markdownLinePattern.ReplaceAllString(text, "")
+
+	readability := &models.Readability{TargetGrade: targetGrade}
+
+	var syllables, passive int
+	for _, sentence := range sentenceEndPattern.Split(text, -1) {
+		words := wordPattern.FindAllString(sentence, -1)
+		if len(words) == 0 {
+			continue
+		}
+		readability.Sentences++
+		readability.Words += len(words)
+		for _, word := range words {
+			syllables += countSyllables(word)
+		}
+		if isPassive(sentence) {
+			passive++
+		}
+	}
+	if readability.Words == 0 {
+		return readability
+	}
+
+	words, sentences := float64(readability.Words), float64(readability.Sentences)
+	readability.ReadingTimeSeconds = int(math.Ceil(words / readingWordsPerMinute * 60))
+	grade := 0.39*words/sentences + 11.8*float64(syllables)/words - 15.59
+	readability.GradeLevel = math.Round(max(grade, 0)*10) / 10
+	readability.PassiveRatio = math.Round(float64(passive)/sentences*100) / 100
+
+	return readability
+}
+
+// countSyllables estimates a word's syllables from its vowel groups, not
+// counting a silent final e. Numbers count as one.
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	if i := strings.IndexAny(word, "'’"); i >= 0 {
+		word = word[:i] // "don't" counts as "don"
+	}
+
+	count := len(vowelGroupPattern.FindAllString(word, -1))
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
+
+// isPassive reports whether a sentence has a form of "be" or "get" followed
+// by a past participle, as in "the report was written"
+func isPassive(sentence string) bool {
+	for _, match := range passivePattern.FindAllStringSubmatch(sentence, -1) {
+		word := strings.ToLower(match[1])
+		if irregularParticiples[word] || (len(word) > 3 && strings.HasSuffix(word, "ed") && !notParticiples[word]) {
+			return true
+		}
+	}
+	return false
+}
+
+// gradeLevelPrompt asks content generation to write for a Flesch-Kincaid grade level
+func gradeLevelPrompt(grade float64) string {
+	return fmt.Sprintf("Readability: Write at about a US grade %g reading level (Flesch-Kincaid). Prefer short sentences, common words, and the active voice as the level goes down.", grade)
+}
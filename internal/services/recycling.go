@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// ErrNotPublished is returned when engagement is imported for, or a variant
+// requested of, content that isn't published
+var ErrNotPublished = errors.New("content isn't published")
+
+// ErrNoConceptsLeft is returned when recycling a post whose concepts have all been deleted
+var ErrNoConceptsLeft = errors.New("the post's concepts have all been deleted")
+
+// Recycling thresholds
+const (
+	recycleMinAge         = 60 * 24 * time.Hour  // High-performing posts are suggested once published this long
+	evergreenMinAge       = 180 * 24 * time.Hour // Posts count as old once published this long
+	recycleMinImpressions = 100                  // Impressions a post needs for its engagement rate to count
+	recycleMinPosts       = 4                    // Posts with counted engagement a platform needs to rank them
+	recycleNewConcepts    = 5                    // Newer related concepts offered to a refreshed variant
+)
+
+// timeSensitivePattern matches wording that dates a post, which keeps it
+// from counting as evergreen
+var timeSensitivePattern = regexp.MustCompile(`(?i)\b(?:(?:19|20)\d{2}|today|tonight|tomorrow|yesterday|(?:this|last|next) (?:week|month|quarter|year)|recently|breaking|upcoming|just (?:launched|released|announced)|Q[1-4]|january|february|april|june|july|august|september|october|november|december)\b`)
+
+// RecordEngagement stores the engagement imported for a published post
+func (s *SourceContentService) RecordEngagement(id int, req models.RecordEngagementRequest) (*models.GeneratedContent, error) {
+	content, err := db.GetGeneratedContentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if content.Status != models.ContentPublished {
+		return nil, ErrNotPublished
+	}
+
+	return db.SaveContentEngagement(id, models.ContentEngagement{
+		Impressions: req.Impressions,
+		Reactions:   req.Reactions,
+		Comments:    req.Comments,
+		Shares:      req.Shares,
+		Clicks:      req.Clicks,
+		RecordedAt:  time.Now(),
+	})
+}
+
+// SuggestRecycling surfaces the published posts worth recycling that haven't
+// been suggested or recycled yet: those whose engagement rate ranks in the
+// top quarter of their platform's, and old ones that nothing in dates. It
+// returns the new suggestions, and notifies when there are any.
+func (s *SourceContentService) SuggestRecycling(ctx context.Context) ([]models.RecycleSuggestion, error) {
+	candidates, err := db.GetRecyclingCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	engagement, err := db.GetPublishedEngagement()
+	if err != nil {
+		return nil, err
+	}
+	thresholds := engagementThresholds(engagement)
+
+	now := time.Now()
+	suggestions := []models.RecycleSuggestion{}
+	for _, content := range candidates {
+		suggestion, ok := recycleReason(content, thresholds, now)
+		if !ok {
+			continue
+		}
+
+		created, err := db.CreateRecycleSuggestion(suggestion)
+		if err != nil {
+			return suggestions, err
+		}
+		created.Content = &content
+		suggestions = append(suggestions, *created)
+	}
+
+	if len(suggestions) > 0 {
+		noun := "posts are"
+		if len(suggestions) == 1 {
+			noun = "post is"
+		}
+		s.notifier.Notify(ctx, models.EventRecycleSuggested,
+			"Content worth recycling",
+			fmt.Sprintf("%d published %s worth sharing again.", len(suggestions), noun),
+			models.JSONObject{"suggestion_count": len(suggestions)})
+	}
+
+	return suggestions, nil
+}
+
+// StartRecycling runs SuggestRecycling every interval, until ctx is cancelled
+func (s *SourceContentService) StartRecycling(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			suggestions, err := s.SuggestRecycling(ctx)
+			if err != nil {
+				log.Printf("Recycling suggestions failed: %v", err)
+				continue
+			}
+			if len(suggestions) > 0 {
+				log.Printf("Suggested %d published posts for recycling", len(suggestions))
+			}
+		}
+	}
+}
+
+// GetRecycleSuggestions returns the recycle suggestions for posts not
+// recycled since, each with its post, skipping dismissed ones unless
+// includeDismissed is set
+func (s *SourceContentService) GetRecycleSuggestions(includeDismissed bool) ([]models.RecycleSuggestion, error) {
+	suggestions, err := db.GetRecycleSuggestions(includeDismissed)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range suggestions {
+		if suggestions[i].Content, err = db.GetGeneratedContentByID(suggestions[i].GeneratedContentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return suggestions, nil
+}
+
+// RecycleContent generates and saves a refreshed draft of a published post,
+// for req.Platform or the post's own, from the current versions of its
+// concepts and any newer concepts sharing their tags, noting what changed
+// since it was published
+func (s *SourceContentService) RecycleContent(ctx context.Context, id int, req models.RecycleContentRequest) (*models.GeneratedContent, error) {
+	original, err := db.GetGeneratedContentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != models.ContentPublished || original.PublishedAt == nil {
+		return nil, ErrNotPublished
+	}
+
+	concepts, _, err := moderationSources(*original)
+	if err != nil {
+		return nil, err
+	}
+	if len(concepts) == 0 {
+		return nil, ErrNoConceptsLeft
+	}
+
+	newConcepts, changes, err := recycleChanges(*original, concepts)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := req.Platform
+	if platform == "" {
+		platform = original.Platform
+	}
+
+	variant, err := s.claudeService.RefreshContent(ctx, *original, platform, append(concepts, newConcepts...), changes)
+	if err != nil {
+		return nil, err
+	}
+	variants := []models.GeneratedContent{*variant}
+	finishContent(ctx, variants, nil)
+
+	return db.CreateGeneratedContent(&variants[0])
+}
+
+// engagementThresholds returns, per platform with at least recycleMinPosts
+// posts with counted engagement, the engagement rate of its top quarter
+func engagementThresholds(engagement map[string][]models.ContentEngagement) map[string]float64 {
+	thresholds := map[string]float64{}
+	for platform, posts := range engagement {
+		var rates []float64
+		for _, e := range posts {
+			if e.Impressions >= recycleMinImpressions {
+				rates = append(rates, e.Rate())
+			}
+		}
+		if len(rates) < recycleMinPosts {
+			continue
+		}
+		sort.Float64s(rates)
+		thresholds[platform] = rates[len(rates)*3/4]
+	}
+	return thresholds
+}
+
+// recycleReason returns the suggestion to make for a published post, if any
+func recycleReason(content models.GeneratedContent, thresholds map[string]float64, now time.Time) (models.RecycleSuggestion, bool) {
+	suggestion := models.RecycleSuggestion{GeneratedContentID: content.ID}
+	age := now.Sub(*content.PublishedAt)
+
+	if e := content.Engagement; e != nil && e.Impressions >= recycleMinImpressions && age >= recycleMinAge {
+		if threshold, ok := thresholds[content.Platform]; ok && e.Rate() >= threshold {
+			rate := e.Rate()
+			suggestion.Reason = models.RecycleHighPerforming
+			suggestion.EngagementRate = &rate
+			return suggestion, true
+		}
+	}
+
+	if age >= evergreenMinAge && !timeSensitivePattern.MatchString(content.Title+"\n"+content.Body) {
+		suggestion.Reason = models.RecycleEvergreen
+		return suggestion, true
+	}
+
+	return suggestion, false
+}
+
+// recycleChanges describes what changed in a post's concepts since it was
+// published: concepts deleted or updated since, and up to recycleNewConcepts
+// newer concepts sharing their tags, which it also returns
+func recycleChanges(original models.GeneratedContent, concepts []models.Concept) ([]models.Concept, []string, error) {
+	var changes []string
+	if deleted := len(original.ConceptIDs) - len(concepts); deleted > 0 {
+		changes = append(changes, fmt.Sprintf("%d of the original's concepts no longer apply", deleted))
+	}
+
+	var tags []string
+	for _, c := range concepts {
+		if c.UpdatedAt.After(*original.PublishedAt) {
+			changes = append(changes, fmt.Sprintf("%q has been updated: %s", c.Title, c.Description))
+		}
+		for _, tag := range c.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	var newConcepts []models.Concept
+	for _, tag := range tags {
+		tagged, err := db.GetConceptsByTag(tag, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range tagged {
+			if len(newConcepts) == recycleNewConcepts {
+				break
+			}
+			if !c.CreatedAt.After(*original.PublishedAt) || slices.Contains(original.ConceptIDs, c.ID) ||
+				slices.ContainsFunc(newConcepts, func(n models.Concept) bool { return n.ID == c.ID }) {
+				continue
+			}
+			newConcepts = append(newConcepts, c)
+			changes = append(changes, fmt.Sprintf("New related concept %q: %s", c.Title, c.Description))
+		}
+	}
+
+	return newConcepts, changes, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// DefaultOutputTemplate is rendered when no template is named
+const DefaultOutputTemplate = "markdown"
+
+// outputMediaTypes are the media types a template may render as. Browsers
+// run scripts in none of them, so a stored template can't serve a page that
+// acts as whoever opens it.
+var outputMediaTypes = []string{"text/plain", "text/markdown", "text/csv", "application/json", "application/yaml"}
+
+// builtinOutputTemplates are available without storing anything. A stored
+// template with the same name takes precedence.
+var builtinOutputTemplates = map[string]models.OutputTemplate{
+	"markdown": {
+		Name:        "markdown",
+		ContentType: "text/markdown; charset=utf-8",
+		Body: `# {{ .Content.Title }}
+
+{{ .CitedBody }}
+{{ with .Concepts }}
+## Concepts
+{{ range . }}
+- **{{ .Title }}**: {{ .Description }}{{ end }}
+{{ end }}{{ with .Source }}
+Source: [{{ .Title }}]({{ .URL }})
+{{ end }}`,
+	},
+	"hugo": {
+		Name:        "hugo",
+		ContentType: "text/markdown; charset=utf-8",
+		Body: `---
+title: {{ quote .Content.Title }}
+date: {{ .Content.CreatedAt.Format "2006-01-02T15:04:05Z07:00" }}
+draft: {{ ne .Content.Status "published" }}
+slug: {{ quote (slug .Content.Title) }}
+tags: [{{ range $i, $tag := .Tags }}{{ if $i }}, {{ end }}{{ quote $tag }}{{ end }}]
+---
+
+{{ .CitedBody }}
+`,
+	},
+	"jekyll": {
+		Name:        "jekyll",
+		ContentType: "text/markdown; charset=utf-8",
+		Body: `---
+layout: post
+title: {{ quote .Content.Title }}
+date: {{ .Content.CreatedAt.Format "2006-01-02 15:04:05 -0700" }}
+tags: [{{ range $i, $tag := .Tags }}{{ if $i }}, {{ end }}{{ quote $tag }}{{ end }}]
+---
+
+{{ .CitedBody }}
+`,
+	},
+}
+
+// outputTemplateFuncs are the functions available to output templates
+var outputTemplateFuncs = template.FuncMap{
+	"quote": strconv.Quote, // A double-quoted string, valid YAML and TOML
+	"slug":  slugify,
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"now":   time.Now,
+}
+
+// renderData is the input to output templates
+type renderData struct {
+	Content   models.GeneratedContent
+	CitedBody string                // The body with citation footnotes
+	Concepts  []models.Concept      // The concepts the content was generated from
+	Tags      []string              // The concepts' tags, de-duplicated
+	Source    *models.SourceContent // The first concept's source, if any
+}
+
+// BuiltinOutputTemplates returns the names of the built-in templates
+func BuiltinOutputTemplates() []string {
+	names := make([]string, 0, len(builtinOutputTemplates))
+	for name := range builtinOutputTemplates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// OutputContentType checks that a template's content type is one of
+// outputMediaTypes, returning it as served, with a UTF-8 charset
+func OutputContentType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(outputMediaTypes, mediaType) {
+		return "", fmt.Errorf("content_type must be one of %s", strings.Join(outputMediaTypes, ", "))
+	}
+	return mediaType + "; charset=utf-8", nil
+}
+
+// ParseOutputTemplate checks that a template body parses
+func ParseOutputTemplate(body string) (*template.Template, error) {
+	return template.New("output").Funcs(outputTemplateFuncs).Option("missingkey=error").Parse(body)
+}
+
+// RenderContent renders a piece of generated content with the named template,
+// returning the output and its content type
+func RenderContent(contentID int, templateName string) ([]byte, string, error) {
+	content, err := db.GetGeneratedContentByID(contentID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	stored, err := db.GetOutputTemplateByName(templateName)
+	if err != nil {
+		return nil, "", err
+	}
+	var outputTemplate models.OutputTemplate
+	if stored != nil {
+		outputTemplate = *stored
+	} else if builtin, ok := builtinOutputTemplates[templateName]; ok {
+		outputTemplate = builtin
+	} else {
+		return nil, "", fmt.Errorf("template not found")
+	}
+
+	tmpl, err := ParseOutputTemplate(outputTemplate.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse template %s: %w", templateName, err)
+	}
+
+	data := renderData{Content: *content, Concepts: []models.Concept{}, Tags: []string{}}
+	sources := map[int]*models.SourceContent{}
+	for _, id := range content.ConceptIDs {
+		concept, err := db.GetConceptByID(id)
+		if err != nil {
+			continue // Concept deleted since the content was generated
+		}
+		data.Concepts = append(data.Concepts, *concept)
+		for _, tag := range concept.Tags {
+			if !slices.Contains(data.Tags, tag) {
+				data.Tags = append(data.Tags, tag)
+			}
+		}
+		if concept.SourceContentID != nil {
+			if _, loaded := sources[*concept.SourceContentID]; !loaded {
+				sources[*concept.SourceContentID], _ = db.GetSourceContentByID(*concept.SourceContentID) // nil if deleted
+			}
+			if data.Source == nil {
+				data.Source = sources[*concept.SourceContentID]
+			}
+		}
+	}
+	data.CitedBody = citedBody(*content, sources)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, "", fmt.Errorf("failed to render template %s: %w", templateName, err)
+	}
+
+	// Stored before content types were checked
+	contentType, err := OutputContentType(outputTemplate.ContentType)
+	if err != nil {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// slugify lowercases text and joins its letters and digits with hyphens
+func slugify(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// resonanceLeadScore is the resonance at which content generation is told to
+// lead with a concept
+const resonanceLeadScore = 1.2
+
+// MaxResonanceSortConcepts bounds the concepts ranked for ?sort=resonance,
+// the newest first; older ones aren't listed
+const MaxResonanceSortConcepts = 5000
+
+// AttachResonance sets the resonance of each concept whose published posts
+// have counted engagement. Failures are logged, leaving the concepts without it.
+func AttachResonance(concepts []models.Concept) {
+	resonance, err := db.GetConceptResonance(recycleMinImpressions)
+	if err != nil {
+		log.Printf("Warning: Failed to load concept resonance: %v", err)
+		return
+	}
+
+	for i := range concepts {
+		if r, ok := resonance[concepts[i].ID]; ok {
+			r.Score = math.Round(r.Score*100) / 100
+			concepts[i].Resonance = &r
+		}
+	}
+}
+
+// ListConcepts retrieves a page of concepts for GET /api/concepts, with their
+// resonance, and the cursor of the next page. Sorted by resonance, it ranks
+// the first MaxResonanceSortConcepts matching concepts and pages through them
+// by query.Offset instead, returning the next page's offset. Either is nil on
+// the last page.
+func ListConcepts(query models.ConceptListQuery, page models.Page) ([]models.Concept, *models.Cursor, *int, error) {
+	if query.Sort != "resonance" {
+		concepts, next, err := db.GetConcepts(query, page)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		AttachResonance(concepts)
+		return concepts, next, nil, nil
+	}
+
+	concepts, _, err := db.GetConcepts(query, models.Page{Limit: MaxResonanceSortConcepts})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	AttachResonance(concepts)
+	SortByResonance(concepts)
+
+	start := min(query.Offset, len(concepts))
+	end := min(start+page.Limit, len(concepts))
+	var next *int
+	if end < len(concepts) {
+		next = &end
+	}
+	return concepts[start:end], nil, next, nil
+}
+
+// SortByResonance orders concepts by resonance, highest first, keeping those
+// without any last in their existing order
+func SortByResonance(concepts []models.Concept) {
+	sort.SliceStable(concepts, func(i, j int) bool {
+		a, b := concepts[i].Resonance, concepts[j].Resonance
+		return a != nil && (b == nil || a.Score > b.Score)
+	})
+}
+
+// resonancePrompt asks content generation to lead with the concepts whose
+// posts engaged the audience most, or returns "" when none stand out
+func resonancePrompt(concepts []models.Concept) string {
+	var leads []models.Concept
+	for _, c := range concepts {
+		if c.Resonance != nil && c.Resonance.Score >= resonanceLeadScore {
+			leads = append(leads, c)
+		}
+	}
+	if len(leads) == 0 {
+		return ""
+	}
+	SortByResonance(leads)
+
+	titles := make([]string, len(leads))
+	for i, c := range leads {
+		titles[i] = fmt.Sprintf("%q", c.Title)
+	}
+	return fmt.Sprintf("Audience resonance: Your audience engaged most with posts on %s. Lead with the concepts your audience engaged with.", strings.Join(titles, ", "))
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/internal/storage"
+)
+
+// RetentionService enforces retention policies: purging long-archived rows and
+// pruning stored audio and export files once they're no longer needed
+type RetentionService struct {
+	archivedAfter time.Duration // Archived sources and concepts are purged after this
+	audioAfter    time.Duration // Raw audio is deleted after this, once transcribed
+	exportsAfter  time.Duration // Export files are deleted after this
+}
+
+// NewRetentionService creates a retention service from RETENTION_ARCHIVED_AFTER
+// (default 720h), RETENTION_AUDIO_AFTER (default 24h), and RETENTION_EXPORTS_AFTER
+// (default 168h). A zero duration disables that policy.
+func NewRetentionService() (*RetentionService, error) {
+	s := &RetentionService{
+		archivedAfter: 30 * 24 * time.Hour,
+		audioAfter:    24 * time.Hour,
+		exportsAfter:  7 * 24 * time.Hour,
+	}
+
+	for name, field := range map[string]*time.Duration{
+		"RETENTION_ARCHIVED_AFTER": &s.archivedAfter,
+		"RETENTION_AUDIO_AFTER":    &s.audioAfter,
+		"RETENTION_EXPORTS_AFTER":  &s.exportsAfter,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid %s: %q", name, value)
+		}
+		*field = parsed
+	}
+
+	return s, nil
+}
+
+// Policies returns the configured policies
+func (s *RetentionService) Policies() []models.RetentionPolicy {
+	return []models.RetentionPolicy{
+		{Name: models.RetentionArchived, After: s.archivedAfter.String(), Enabled: s.archivedAfter > 0},
+		{Name: models.RetentionAudio, After: s.audioAfter.String(), Enabled: s.audioAfter > 0},
+		{Name: models.RetentionExports, After: s.exportsAfter.String(), Enabled: s.exportsAfter > 0},
+	}
+}
+
+// Run applies every enabled policy, or with dryRun only reports what would be
+// removed. A failing policy is recorded in the report and the others still run.
+func (s *RetentionService) Run(ctx context.Context, dryRun bool) *models.RetentionReport {
+	now := time.Now()
+	report := &models.RetentionReport{
+		DryRun:   dryRun,
+		RanAt:    now,
+		Policies: s.Policies(),
+		Items:    []models.RetentionItem{},
+		Counts:   map[string]int{},
+	}
+
+	collect := func(policy string, items []models.RetentionItem, err error) {
+		if err != nil {
+			log.Printf("Warning: Retention policy %s failed: %v", policy, err)
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", policy, err))
+		}
+		report.Items = append(report.Items, items...)
+		report.Counts[policy] = len(items)
+	}
+
+	if s.archivedAfter > 0 {
+		items, err := s.purgeArchived(now.Add(-s.archivedAfter), dryRun)
+		collect(models.RetentionArchived, items, err)
+	}
+	if s.audioAfter > 0 {
+		items, err := s.pruneObjects(ctx, models.RetentionAudio, storage.PrefixAudio, now.Add(-s.audioAfter), dryRun)
+		collect(models.RetentionAudio, items, err)
+	}
+	if s.exportsAfter > 0 {
+		items, err := s.pruneObjects(ctx, models.RetentionExports, storage.PrefixExports, now.Add(-s.exportsAfter), dryRun)
+		collect(models.RetentionExports, items, err)
+	}
+
+	return report
+}
+
+// StartRetention runs the retention policies every interval until ctx is cancelled
+func (s *RetentionService) StartRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := s.Run(ctx, false)
+			if len(report.Items) > 0 {
+				log.Printf("Retention removed %d items (archived: %d, audio: %d, exports: %d)",
+					len(report.Items), report.Counts[models.RetentionArchived],
+					report.Counts[models.RetentionAudio], report.Counts[models.RetentionExports])
+			}
+		}
+	}
+}
+
+// purgeArchived deletes sources and concepts archived before cutoff
+func (s *RetentionService) purgeArchived(cutoff time.Time, dryRun bool) ([]models.RetentionItem, error) {
+	items, err := db.GetExpiredArchivedItems(cutoff)
+	if err != nil || dryRun || len(items) == 0 {
+		return items, err
+	}
+
+	var conceptIDs, sourceIDs []int
+	for _, item := range items {
+		if item.Kind == "concept" {
+			conceptIDs = append(conceptIDs, *item.ID)
+		} else {
+			sourceIDs = append(sourceIDs, *item.ID)
+		}
+	}
+
+	if err := db.PurgeArchivedItems(conceptIDs, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// pruneObjects deletes objects under prefix last modified before cutoff,
+// returning those it deleted (or would delete)
+func (s *RetentionService) pruneObjects(ctx context.Context, policy, prefix string, cutoff time.Time, dryRun bool) ([]models.RetentionItem, error) {
+	if storage.Store == nil {
+		return nil, nil
+	}
+
+	objects, err := storage.Store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.RetentionItem
+	for _, object := range objects {
+		if !object.LastModified.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := storage.Store.Delete(ctx, object.Key); err != nil {
+				return items, err
+			}
+		}
+		items = append(items, models.RetentionItem{
+			Policy: policy,
+			Kind:   "object",
+			Key:    object.Key,
+			Title:  strings.TrimPrefix(object.Key, prefix),
+			Since:  object.LastModified,
+		})
+	}
+
+	return items, nil
+}
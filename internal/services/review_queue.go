@@ -0,0 +1,68 @@
+package services
+
+import (
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// GetDueReviews returns the daily review queue: active concepts owner can see
+// whose next review has passed, most overdue first, each with a question to
+// review it with. Concepts reviewed since midnight in loc count toward the
+// daily limit, so the queue holds at most what's left of it.
+func GetDueReviews(loc *time.Location, owner *int) (*models.DueReviews, error) {
+	now := time.Now()
+	limit := CurrentSettings().Review.DailyLimit
+
+	reviewed, err := db.CountConceptsReviewedSince(endOfDay(now, loc).AddDate(0, 0, -1), owner)
+	if err != nil {
+		return nil, err
+	}
+	due, err := db.CountDueConcepts(now, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &models.DueReviews{
+		Reviews:       []models.DueReview{},
+		Due:           due,
+		ReviewedToday: reviewed,
+		DailyLimit:    limit,
+	}
+	if due == 0 || reviewed >= limit {
+		return queue, nil
+	}
+
+	concepts, err := db.GetDueReviews(now, limit-reviewed, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range concepts {
+		review := models.DueReview{Concept: d.Concept, Progress: d.Progress}
+		if d.Question != nil {
+			served := serveQuestion(*d.Question, models.QuizSessionQuestion{})
+			review.Question = &served
+		}
+		queue.Reviews = append(queue.Reviews, review)
+	}
+	queue.Count = len(queue.Reviews)
+
+	return queue, nil
+}
+
+// CompleteReview advances a concept's schedule after a review the learner
+// rated themselves, without answering a question
+func CompleteReview(conceptID int, rating string) (*models.LearningProgress, error) {
+	if _, err := db.GetConceptByID(conceptID); err != nil {
+		return nil, err
+	}
+
+	progress, err := db.GetLearningProgressByConceptID(conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.UpsertLearningProgress(scheduleReview(conceptID, progress, rating, time.Now()))
+}
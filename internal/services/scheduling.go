@@ -0,0 +1,186 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// maxMasteryLevel is the highest mastery level a concept reaches. The
+// scheduling algorithm and its parameters are workspace settings.
+const maxMasteryLevel = 5
+
+// effectiveRating resolves the rating used for scheduling. A wrong answer is
+// always again; a correct one uses the learner's rating, defaulting to good.
+func effectiveRating(correct bool, rating string) string {
+	if !correct {
+		return models.RatingAgain
+	}
+	if rating == "" {
+		return models.RatingGood
+	}
+	return rating
+}
+
+// scheduleReview advances a concept's progress after a review rated at rating,
+// using the workspace's review algorithm. progress may be nil for a concept
+// that has never been reviewed.
+func scheduleReview(conceptID int, progress *models.LearningProgress, rating string, now time.Time) models.LearningProgress {
+	params := CurrentSettings().Review
+	next := models.LearningProgress{
+		ConceptID:  conceptID,
+		EaseFactor: params.StartingEase,
+	}
+	if progress != nil {
+		next = *progress
+	}
+
+	switch rating {
+	case models.RatingAgain:
+		next.ConsecutiveCorrect = 0
+		next.MasteryLevel--
+	case models.RatingHard:
+		next.ConsecutiveCorrect++
+	case models.RatingGood, models.RatingEasy:
+		next.ConsecutiveCorrect++
+		next.MasteryLevel++
+	}
+	next.MasteryLevel = max(0, min(maxMasteryLevel, next.MasteryLevel))
+
+	var interval int
+	switch params.Algorithm {
+	case models.ReviewAlgorithmFSRS:
+		interval = scheduleFSRS(&next, params, rating, now)
+	case models.ReviewAlgorithmFixed:
+		interval = fixedInterval(next.ConsecutiveCorrect, params.FixedIntervals)
+	default:
+		interval = scheduleSM2(&next, params, rating)
+	}
+
+	setDue(&next, params, interval, now)
+	next.LastReviewedAt = &now
+
+	return next
+}
+
+// scheduleSM2 updates the ease factor for rating and returns the next interval
+func scheduleSM2(next *models.LearningProgress, params models.ReviewSettings, rating string) int {
+	interval := next.IntervalDays
+	switch rating {
+	case models.RatingAgain:
+		next.EaseFactor -= 0.2
+		interval = 0
+	case models.RatingHard:
+		next.EaseFactor -= 0.15
+		interval = int(math.Max(1, math.Round(float64(interval)*params.HardInterval)))
+	case models.RatingGood:
+		switch {
+		case interval == 0:
+			interval = 1
+		case interval == 1:
+			interval = 3
+		default:
+			interval = int(math.Round(float64(interval) * next.EaseFactor))
+		}
+	case models.RatingEasy:
+		next.EaseFactor += 0.15
+		if interval == 0 {
+			interval = 4
+		} else {
+			interval = int(math.Round(float64(interval) * next.EaseFactor * params.EasyBonus))
+		}
+	}
+
+	next.EaseFactor = math.Max(params.MinimumEase, next.EaseFactor)
+	return interval
+}
+
+// scheduleFSRS updates the memory state for rating and returns the next
+// interval. A concept without a memory state starts one, from its SM-2
+// progress if it has any.
+func scheduleFSRS(next *models.LearningProgress, params models.ReviewSettings, rating string, now time.Time) int {
+	grade := fsrsGrades[rating]
+
+	var state fsrsState
+	switch {
+	case next.Stability != nil && next.Difficulty != nil && next.LastReviewedAt != nil:
+		elapsed := math.Max(0, now.Sub(*next.LastReviewedAt).Hours()/24)
+		state = fsrsNext(params.Weights, fsrsState{*next.Stability, *next.Difficulty}, grade, elapsed)
+	case next.LastReviewedAt != nil:
+		elapsed := math.Max(0, now.Sub(*next.LastReviewedAt).Hours()/24)
+		state = fsrsNext(params.Weights, fsrsStateFromSM2(params.Weights, *next, params.StartingEase), grade, elapsed)
+	default:
+		state = fsrsInitial(params.Weights, grade)
+	}
+	next.Stability = &state.stability
+	next.Difficulty = &state.difficulty
+
+	if rating == models.RatingAgain {
+		return 0
+	}
+	return fsrsInterval(state.stability, params.RequestRetention)
+}
+
+// fixedInterval returns the interval after consecutive correct reviews, the
+// last of intervals repeating once they run out
+func fixedInterval(consecutive int, intervals []int) int {
+	if consecutive == 0 || len(intervals) == 0 {
+		return 0
+	}
+	return intervals[min(consecutive, len(intervals))-1]
+}
+
+// setDue caps interval and schedules the next review that many days after
+// reviewedAt; an interval of 0 schedules it after the relearn delay
+func setDue(next *models.LearningProgress, params models.ReviewSettings, interval int, reviewedAt time.Time) {
+	interval = min(interval, params.MaximumIntervalDays)
+
+	due := reviewedAt.Add(time.Duration(params.RelearnDelayMinutes) * time.Minute)
+	if interval > 0 {
+		due = reviewedAt.AddDate(0, 0, interval)
+	}
+	next.IntervalDays = interval
+	next.NextReviewAt = &due
+}
+
+// RescheduleReviews moves every reviewed concept onto the schedule of the
+// workspace's review algorithm, counting from its last review, and returns
+// how many were rescheduled. Mastery and review streaks are kept.
+func RescheduleReviews() (int, error) {
+	params := CurrentSettings().Review
+
+	progress, err := db.GetReviewedLearningProgress()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range progress {
+		lp := &progress[i]
+
+		interval := lp.IntervalDays
+		switch params.Algorithm {
+		case models.ReviewAlgorithmFSRS:
+			state := fsrsStateFromSM2(params.Weights, *lp, params.StartingEase)
+			lp.Stability = &state.stability
+			lp.Difficulty = &state.difficulty
+			if interval > 0 {
+				interval = fsrsInterval(state.stability, params.RequestRetention)
+			}
+		case models.ReviewAlgorithmFixed:
+			lp.Stability, lp.Difficulty = nil, nil
+			interval = fixedInterval(lp.ConsecutiveCorrect, params.FixedIntervals)
+		default:
+			lp.Stability, lp.Difficulty = nil, nil
+		}
+
+		setDue(lp, params, interval, *lp.LastReviewedAt)
+	}
+
+	if err := db.UpdateReviewSchedules(progress); err != nil {
+		return 0, err
+	}
+
+	return len(progress), nil
+}
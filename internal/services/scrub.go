@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Scrub categories
+const (
+	ScrubEmail     = "email"
+	ScrubPhone     = "phone"
+	ScrubCard      = "card" // Payment card numbers passing the Luhn check
+	ScrubSSN       = "ssn"
+	ScrubIP        = "ip"
+	ScrubProfanity = "profanity"
+)
+
+// scrubPII expands to every PII category
+const scrubPII = "pii"
+
+var piiCategories = []string{ScrubEmail, ScrubPhone, ScrubCard, ScrubSSN, ScrubIP}
+
+// scrubPatterns match each category, applied in this order so that broader
+// patterns (phones) don't claim parts of narrower ones (emails, cards)
+var scrubPatterns = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{ScrubEmail, regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)*\.[a-zA-Z]{2,}`)},
+	{ScrubCard, regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)},
+	{ScrubSSN, regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	{ScrubPhone, regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b|\+\d{1,3}(?:[ .-]?\d{2,4}){3,4}\b`)},
+	{ScrubIP, regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+}
+
+// profanityPattern matches common English profanity
+var profanityPattern = regexp.MustCompile(`(?i)\b(?:(?:mother)?fuck(?:s|ed|er|ers|ing)?|(?:bull)?shit(?:s|ty)?|bitch(?:es)?|asshole(?:s)?|bastard(?:s)?|cunt(?:s)?|dick(?:head)?s?|piss(?:ed)?)\b`)
+
+// Scrubber redacts PII, and optionally profanity, from text before it's
+// stored or sent to Claude
+type Scrubber struct {
+	categories []string
+}
+
+// NewScrubber creates a scrubber for the given categories; "pii" stands for
+// every PII category
+func NewScrubber(categories []string) (*Scrubber, error) {
+	s := &Scrubber{}
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		switch {
+		case category == "":
+			continue
+		case category == scrubPII:
+			s.categories = append(s.categories, piiCategories...)
+		case category == ScrubProfanity || slices.Contains(piiCategories, category):
+			s.categories = append(s.categories, category)
+		default:
+			return nil, fmt.Errorf("unknown scrub category %q", category)
+		}
+	}
+	return s, nil
+}
+
+// LoadScrubber reads SCRUB_TRANSCRIPTS, a comma-separated list of categories
+// (e.g. "pii,profanity"). Returns nil without an error when it's unset.
+func LoadScrubber() (*Scrubber, error) {
+	value := os.Getenv("SCRUB_TRANSCRIPTS")
+	if value == "" {
+		return nil, nil
+	}
+
+	scrubber, err := NewScrubber(strings.Split(value, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCRUB_TRANSCRIPTS: %w", err)
+	}
+	return scrubber, nil
+}
+
+// Scrub returns text with matches replaced by a [CATEGORY] placeholder (or
+// masked, for profanity), and how many were redacted per category
+func (s *Scrubber) Scrub(text string) (string, map[string]int) {
+	counts := map[string]int{}
+	if s == nil {
+		return text, counts
+	}
+
+	for _, p := range scrubPatterns {
+		if !slices.Contains(s.categories, p.category) {
+			continue
+		}
+		placeholder := "[" + strings.ToUpper(p.category) + "]"
+		text = p.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if p.category == ScrubCard && !luhnValid(match) {
+				return match
+			}
+			counts[p.category]++
+			return placeholder
+		})
+	}
+
+	if slices.Contains(s.categories, ScrubProfanity) {
+		text = profanityPattern.ReplaceAllStringFunc(text, func(match string) string {
+			counts[ScrubProfanity]++
+			return match[:1] + strings.Repeat("*", len(match)-1)
+		})
+	}
+
+	return text, counts
+}
+
+// luhnValid reports whether a digit string, ignoring spaces and dashes,
+// passes the Luhn checksum used by payment cards
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		ch := number[i]
+		if ch < '0' || ch > '9' {
+			continue
+		}
+		digit := int(ch - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/llm"
+)
+
+// ErrInvalidSettings is returned when a settings change would leave the
+// workspace misconfigured
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// defaultReviewSettings schedule with SM-2 tuned to match Anki's defaults
+var defaultReviewSettings = models.ReviewSettings{
+	Algorithm:           models.ReviewAlgorithmSM2,
+	RelearnDelayMinutes: 10,
+	MaximumIntervalDays: 36500,
+	DailyLimit:          200,
+	StartingEase:        2.5,
+	MinimumEase:         1.3,
+	HardInterval:        1.2,
+	EasyBonus:           1.3,
+	FixedIntervals:      []int{1, 3, 7, 14, 30, 60, 120},
+	RequestRetention:    0.9,
+	Weights:             fsrsDefaultWeights,
+}
+
+// activeSettings are the workspace settings in effect with their loaded time zone
+type activeSettings struct {
+	models.WorkspaceSettings
+	location *time.Location
+}
+
+// current holds the settings in effect, swapped whole when they change
+var current atomic.Pointer[activeSettings]
+
+func init() {
+	settings := envSettings()
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC // InitSettings reports the invalid TIMEZONE
+	}
+	current.Store(&activeSettings{WorkspaceSettings: settings, location: loc})
+}
+
+// InitSettings loads the workspace settings: the environment's configuration
+// with the stored overrides applied
+func InitSettings() error {
+	activate, err := PrepareSettings()
+	if err != nil {
+		return err
+	}
+	activate()
+	return nil
+}
+
+// PrepareSettings loads the workspace settings as InitSettings does, and
+// returns the function that puts them into effect
+func PrepareSettings() (func(), error) {
+	if name := os.Getenv("TIMEZONE"); name != "" {
+		if _, err := time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("invalid TIMEZONE: %w", err)
+		}
+	}
+	for feature := range envSettings().Features {
+		if !slices.Contains(models.Features, feature) {
+			return nil, fmt.Errorf("invalid DISABLED_FEATURES: unknown feature %q", feature)
+		}
+	}
+
+	overrides, _, err := db.GetSettingsOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := newActiveSettings(applyOverrides(envSettings(), overrides))
+	if err != nil {
+		return nil, err
+	}
+
+	return func() { current.Store(active) }, nil
+}
+
+// CurrentSettings returns the workspace settings in effect
+func CurrentSettings() models.WorkspaceSettings {
+	return current.Load().WorkspaceSettings
+}
+
+// GetSettings returns the workspace settings in effect and the overrides behind them
+func GetSettings() (*models.SettingsResponse, error) {
+	overrides, updatedAt, err := db.GetSettingsOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SettingsResponse{
+		Settings:  CurrentSettings(),
+		Overrides: overrides,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// UpdateSettings resets the settings req names, overrides the ones it sets, and
+// applies the result immediately
+func UpdateSettings(req models.UpdateSettingsRequest) (*models.SettingsResponse, error) {
+	overrides, _, err := db.GetSettingsOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range req.Reset {
+		switch name {
+		case "default_platforms":
+			overrides.DefaultPlatforms = nil
+		case "concepts_min":
+			overrides.ConceptsMin = nil
+		case "concepts_max":
+			overrides.ConceptsMax = nil
+		case "default_model":
+			overrides.DefaultModel = nil
+		case "timezone":
+			overrides.Timezone = nil
+		case "review":
+			overrides.Review = nil
+		}
+	}
+
+	if req.DefaultPlatforms != nil {
+		overrides.DefaultPlatforms = req.DefaultPlatforms
+	}
+	if req.ConceptsMin != nil {
+		overrides.ConceptsMin = req.ConceptsMin
+	}
+	if req.ConceptsMax != nil {
+		overrides.ConceptsMax = req.ConceptsMax
+	}
+	if req.DefaultModel != nil {
+		overrides.DefaultModel = req.DefaultModel
+	}
+	if req.Timezone != nil {
+		if err := ValidateTimezone(*req.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+		}
+		overrides.Timezone = req.Timezone
+	}
+	if req.Review != nil {
+		review := models.ReviewSettingsOverrides{}
+		if overrides.Review != nil {
+			review = *overrides.Review
+		}
+		mergeReviewOverrides(&review, *req.Review)
+		overrides.Review = &review
+	}
+
+	settings := applyOverrides(envSettings(), overrides)
+	if settings.ConceptsMin > settings.ConceptsMax {
+		return nil, fmt.Errorf("%w: concepts_min (%d) can't exceed concepts_max (%d)", ErrInvalidSettings, settings.ConceptsMin, settings.ConceptsMax)
+	}
+	if settings.Review.MinimumEase > settings.Review.StartingEase {
+		return nil, fmt.Errorf("%w: review minimum_ease can't exceed starting_ease", ErrInvalidSettings)
+	}
+	for _, w := range settings.Review.Weights[:4] {
+		if w <= 0 {
+			return nil, fmt.Errorf("%w: review weights 0-3 are initial stabilities and must be positive", ErrInvalidSettings)
+		}
+	}
+
+	previous := CurrentSettings()
+	updatedAt, err := db.SaveSettingsOverrides(overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := activateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	response := &models.SettingsResponse{
+		Settings:  settings,
+		Overrides: overrides,
+		UpdatedAt: updatedAt,
+	}
+
+	// Move every reviewed concept onto the new algorithm's schedule
+	if settings.Review.Algorithm != previous.Review.Algorithm {
+		rescheduled, err := RescheduleReviews()
+		if err != nil {
+			return nil, fmt.Errorf("settings were saved, but rescheduling reviews failed (retry with POST /api/reviews/reschedule): %w", err)
+		}
+		response.Rescheduled = &rescheduled
+	}
+
+	return response, nil
+}
+
+// activateSettings puts settings into effect
+func activateSettings(settings models.WorkspaceSettings) error {
+	active, err := newActiveSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	current.Store(active)
+	return nil
+}
+
+// newActiveSettings loads the time zone of settings
+func newActiveSettings(settings models.WorkspaceSettings) (*activeSettings, error) {
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	return &activeSettings{WorkspaceSettings: settings, location: loc}, nil
+}
+
+// envSettings returns the settings configured by the environment
+func envSettings() models.WorkspaceSettings {
+	settings := models.WorkspaceSettings{
+		DefaultPlatforms: models.ContentPlatforms,
+		ConceptsMin:      3,
+		ConceptsMax:      7,
+		DefaultModel:     llm.DefaultModel(),
+		Timezone:         "UTC",
+		Review:           defaultReviewSettings,
+		Features:         map[string]bool{},
+	}
+
+	for _, feature := range models.Features {
+		settings.Features[feature] = true
+	}
+	for _, feature := range strings.Split(os.Getenv("DISABLED_FEATURES"), ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			settings.Features[feature] = false
+		}
+	}
+
+	if min, err := strconv.Atoi(os.Getenv("CONCEPTS_MIN")); err == nil {
+		settings.ConceptsMin = min
+	}
+	if max, err := strconv.Atoi(os.Getenv("CONCEPTS_MAX")); err == nil {
+		settings.ConceptsMax = max
+	}
+	if name := os.Getenv("TIMEZONE"); name != "" {
+		settings.Timezone = name
+	}
+
+	return settings
+}
+
+// applyOverrides returns settings with the overrides that are set replacing its values
+func applyOverrides(settings models.WorkspaceSettings, overrides models.SettingsOverrides) models.WorkspaceSettings {
+	if overrides.DefaultPlatforms != nil {
+		settings.DefaultPlatforms = *overrides.DefaultPlatforms
+	}
+	if overrides.ConceptsMin != nil {
+		settings.ConceptsMin = *overrides.ConceptsMin
+	}
+	if overrides.ConceptsMax != nil {
+		settings.ConceptsMax = *overrides.ConceptsMax
+	}
+	if overrides.DefaultModel != nil {
+		settings.DefaultModel = *overrides.DefaultModel
+	}
+	if overrides.Timezone != nil {
+		settings.Timezone = *overrides.Timezone
+	}
+	for feature, enabled := range overrides.Features {
+		settings.Features[feature] = enabled
+	}
+
+	if review := overrides.Review; review != nil {
+		if review.Algorithm != nil {
+			settings.Review.Algorithm = *review.Algorithm
+		}
+		if review.RelearnDelayMinutes != nil {
+			settings.Review.RelearnDelayMinutes = *review.RelearnDelayMinutes
+		}
+		if review.MaximumIntervalDays != nil {
+			settings.Review.MaximumIntervalDays = *review.MaximumIntervalDays
+		}
+		if review.DailyLimit != nil {
+			settings.Review.DailyLimit = *review.DailyLimit
+		}
+		if review.StartingEase != nil {
+			settings.Review.StartingEase = *review.StartingEase
+		}
+		if review.MinimumEase != nil {
+			settings.Review.MinimumEase = *review.MinimumEase
+		}
+		if review.HardInterval != nil {
+			settings.Review.HardInterval = *review.HardInterval
+		}
+		if review.EasyBonus != nil {
+			settings.Review.EasyBonus = *review.EasyBonus
+		}
+		if review.FixedIntervals != nil {
+			settings.Review.FixedIntervals = *review.FixedIntervals
+		}
+		if review.RequestRetention != nil {
+			settings.Review.RequestRetention = *review.RequestRetention
+		}
+		if review.Weights != nil {
+			settings.Review.Weights = *review.Weights
+		}
+	}
+
+	return settings
+}
+
+// mergeReviewOverrides sets the review overrides that changes sets
+func mergeReviewOverrides(review *models.ReviewSettingsOverrides, changes models.ReviewSettingsOverrides) {
+	if changes.Algorithm != nil {
+		review.Algorithm = changes.Algorithm
+	}
+	if changes.RelearnDelayMinutes != nil {
+		review.RelearnDelayMinutes = changes.RelearnDelayMinutes
+	}
+	if changes.MaximumIntervalDays != nil {
+		review.MaximumIntervalDays = changes.MaximumIntervalDays
+	}
+	if changes.DailyLimit != nil {
+		review.DailyLimit = changes.DailyLimit
+	}
+	if changes.StartingEase != nil {
+		review.StartingEase = changes.StartingEase
+	}
+	if changes.MinimumEase != nil {
+		review.MinimumEase = changes.MinimumEase
+	}
+	if changes.HardInterval != nil {
+		review.HardInterval = changes.HardInterval
+	}
+	if changes.EasyBonus != nil {
+		review.EasyBonus = changes.EasyBonus
+	}
+	if changes.FixedIntervals != nil {
+		review.FixedIntervals = changes.FixedIntervals
+	}
+	if changes.RequestRetention != nil {
+		review.RequestRetention = changes.RequestRetention
+	}
+	if changes.Weights != nil {
+		review.Weights = changes.Weights
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// shareDescriptionMaxChars bounds og:description, which unfurls truncate anyway
+const shareDescriptionMaxChars = 200
+
+// ShareConcept returns the share link for a concept, creating it on first share.
+// Archived concepts can't be shared.
+func ShareConcept(conceptID int) (*models.ShareLink, error) {
+	concept, err := db.GetConceptByID(conceptID)
+	if err != nil {
+		return nil, err
+	}
+	if concept.ArchivedAt != nil {
+		return nil, fmt.Errorf("concept is archived")
+	}
+
+	return createShareLink(models.ShareLink{ConceptID: &conceptID})
+}
+
+// ShareGeneratedContent returns the share link for a generated content piece,
+// creating it on first share
+func ShareGeneratedContent(contentID int) (*models.ShareLink, error) {
+	if _, err := db.GetGeneratedContentByID(contentID); err != nil {
+		return nil, err
+	}
+
+	return createShareLink(models.ShareLink{GeneratedContentID: &contentID})
+}
+
+// createShareLink gives a link a random token and stores it
+func createShareLink(link models.ShareLink) (*models.ShareLink, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+	link.Token = hex.EncodeToString(token)
+
+	return db.CreateShareLink(link)
+}
+
+// sharePageData is the template input for a public share page
+type sharePageData struct {
+	Title       string
+	Description string // Plain-text summary for og:description
+	Paragraphs  []string
+	Tags        []string
+	Type        string // og:type
+	URL         string // Canonical page URL
+	SourceTitle string
+	SourceURL   string
+}
+
+// GetPublicShare retrieves the item a share link publishes: a concept with
+// its active quiz questions, or a generated content piece. Links to archived
+// concepts don't resolve.
+func GetPublicShare(token string) (*models.PublicShare, error) {
+	link, err := db.GetShareLinkByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.PublicShare{Token: link.Token}
+
+	switch {
+	case link.ConceptID != nil:
+		concept, err := db.GetConceptByID(*link.ConceptID)
+		if err != nil {
+			return nil, err
+		}
+		if concept.ArchivedAt != nil {
+			return nil, fmt.Errorf("share link not found")
+		}
+
+		pc := &models.PublicConcept{
+			Title:       concept.Title,
+			Description: concept.Description,
+			Tags:        concept.Tags,
+			Quiz:        []models.PublicQuizQuestion{},
+		}
+		if concept.SourceContentID != nil {
+			if source, err := db.GetSourceContentSummaryByID(*concept.SourceContentID); err == nil {
+				pc.SourceTitle = source.Title
+				pc.SourceURL = source.URL
+			}
+		}
+
+		quizzes, err := db.GetQuizzesByConceptID(concept.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quizzes: %w", err)
+		}
+		for _, q := range quizzes {
+			pc.Quiz = append(pc.Quiz, models.PublicQuizQuestion{
+				Question:      q.Question,
+				OptionA:       q.OptionA,
+				OptionB:       q.OptionB,
+				OptionC:       q.OptionC,
+				OptionD:       q.OptionD,
+				CorrectAnswer: q.CorrectAnswer,
+				Explanation:   q.Explanation,
+			})
+		}
+
+		share.Type = models.ShareTypeConcept
+		share.Concept = pc
+	case link.GeneratedContentID != nil:
+		content, err := db.GetGeneratedContentByID(*link.GeneratedContentID)
+		if err != nil {
+			return nil, err
+		}
+
+		share.Type = models.ShareTypeContent
+		share.Content = &models.PublicContent{
+			Title:       content.Title,
+			Body:        content.Body,
+			Platform:    content.Platform,
+			PublishedAt: content.PublishedAt,
+		}
+	}
+
+	return share, nil
+}
+
+// RenderSharePage renders the public HTML page for a share link. pageURL is
+// the page's absolute URL, used for og:url.
+func RenderSharePage(token, pageURL string) ([]byte, error) {
+	share, err := GetPublicShare(token)
+	if err != nil {
+		return nil, err
+	}
+
+	data := sharePageData{URL: pageURL, Type: "article"}
+
+	switch {
+	case share.Concept != nil:
+		data.Title = share.Concept.Title
+		data.Description = share.Concept.Description
+		data.Paragraphs = paragraphs(share.Concept.Description)
+		data.Tags = share.Concept.Tags
+		data.SourceTitle = share.Concept.SourceTitle
+		data.SourceURL = share.Concept.SourceURL
+	case share.Content != nil:
+		data.Title = share.Content.Title
+		data.Description = share.Content.Body
+		data.Paragraphs = paragraphs(share.Content.Body)
+	}
+
+	data.Description = truncateText(strings.Join(strings.Fields(data.Description), " "), shareDescriptionMaxChars)
+
+	var buf bytes.Buffer
+	if err := sharePageTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render share page: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// paragraphs splits text on blank lines, dropping empty paragraphs
+func paragraphs(text string) []string {
+	var result []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// truncateText cuts text to at most maxChars characters at a word boundary,
+// adding an ellipsis when anything was cut
+func truncateText(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+
+	cut := string(runes[:maxChars-1])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, unicode.IsPunct) + "…"
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<link rel="canonical" href="{{.URL}}">
+<meta property="og:type" content="{{.Type}}">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:site_name" content="Lattice">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; color: #111; line-height: 1.55; }
+  h1 { font-size: 1.7rem; line-height: 1.25; }
+  .tags { margin: 1.5rem 0; }
+  .tags span { display: inline-block; background: #eef; border-radius: 0.25rem; padding: 0.1rem 0.5rem; margin: 0 0.25rem 0.25rem 0; font-size: 0.85rem; }
+  footer { color: #555; font-size: 0.9rem; border-top: 1px solid #ddd; margin-top: 2rem; padding-top: 1rem; }
+  a { color: #2450a6; }
+</style>
+</head>
+<body>
+<article>
+  <h1>{{.Title}}</h1>
+  {{range .Paragraphs}}<p>{{.}}</p>
+  {{end}}
+  {{if .Tags}}<div class="tags">{{range .Tags}}<span>{{.}}</span>{{end}}</div>{{end}}
+</article>
+<footer>
+  {{if .SourceURL}}From <a href="{{.SourceURL}}">{{if .SourceTitle}}{{.SourceTitle}}{{else}}{{.SourceURL}}{{end}}</a> · {{end}}Shared from Lattice
+</footer>
+</body>
+</html>
+`))
@@ -2,26 +2,53 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/mostlyerror/lattice/internal/db"
 	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/podcast"
 	"github.com/mostlyerror/lattice/pkg/youtube"
 )
 
+// ErrVideoTooLong is returned when a video exceeds a duration limit
+var ErrVideoTooLong = errors.New("video is longer than the limit")
+
+// chatHistoryLimit is how many earlier chat messages are sent with each question
+const chatHistoryLimit = 20
+
 // SourceContentService orchestrates the full content processing pipeline
 type SourceContentService struct {
 	youtubeClient *youtube.Client
+	podcastClient *podcast.Client
 	claudeService *ClaudeService
+	notifier      *NotificationService
+	scrubber      *Scrubber      // Redacts transcripts before saving; nil when off
+	audioFallback *AudioFallback // Transcribes videos without captions, or all videos; nil when off
+
+	captionLanguages []string // Caption preference; youtube.DefaultLanguages when empty
+	translateTo      string   // Language other captions are translated into; empty for none
+
+	jobWake chan struct{} // Wakes an idle job worker
+	jobID   int           // Job whose source this copy processes; zero outside jobs
+	ownerID *int          // User new sources belong to; nil for shared sources
 }
 
 // ProcessResult contains the results of processing source content
 type ProcessResult struct {
-	SourceContent    *models.SourceContent      `json:"source_content"`
-	Concepts         []models.Concept           `json:"concepts"`
-	Quizzes          []models.QuizQuestion      `json:"quizzes"`
-	GeneratedContent []models.GeneratedContent  `json:"generated_content"`
+	SourceContent    *models.SourceContent     `json:"source_content"`
+	Concepts         []models.Concept          `json:"concepts"`
+	Quizzes          []models.QuizQuestion     `json:"quizzes"`
+	Glossary         []models.GlossaryTerm     `json:"glossary"`
+	ActionItems      []models.ActionItem       `json:"action_items"`
+	Mentions         []models.Mention          `json:"mentions"`
+	GeneratedContent []models.GeneratedContent `json:"generated_content"`
+	Redactions       map[string]int            `json:"redactions,omitempty"`   // Per scrub category, for new sources
+	Warnings         []models.StageWarning     `json:"warnings"`               // Failed parts of this run; empty for existing sources
+	PipelineRun      *models.PipelineRun       `json:"pipeline_run,omitempty"` // Checkpoints, for existing sources
 }
 
 // NewSourceContentService creates a new source content service
@@ -36,92 +63,313 @@ func NewSourceContentService() (*SourceContentService, error) {
 		return nil, fmt.Errorf("failed to create Claude service: %w", err)
 	}
 
+	scrubber, err := LoadScrubber()
+	if err != nil {
+		return nil, err
+	}
+
+	audioFallback, err := LoadAudioFallback()
+	if err != nil {
+		return nil, err
+	}
+
 	return &SourceContentService{
 		youtubeClient: ytClient,
+		podcastClient: podcast.NewClient(),
 		claudeService: claudeService,
+		notifier:      NewNotificationService(),
+		scrubber:      scrubber,
+		audioFallback: audioFallback,
+		jobWake:       make(chan struct{}, 1),
 	}, nil
 }
 
-// ProcessYouTubeURL runs the full workflow for a YouTube video
-func (s *SourceContentService) ProcessYouTubeURL(ctx context.Context, url string) (*ProcessResult, error) {
+// ProcessYouTubeURL runs the full workflow for the YouTube video at req.URL,
+// using req's pipeline definition, or the default pipeline when PipelineID is
+// nil, adjusted by req's processing profile if it names one. A non-nil
+// QuizQuestions overrides the pipeline's quiz questions per concept, a non-nil Scrub turns transcript scrubbing on or off for this
+// source, and Languages and Translate choose the captions used.
+func (s *SourceContentService) ProcessYouTubeURL(ctx context.Context, req models.CreateSourceContentRequest) (*ProcessResult, error) {
+	url := req.URL
 	log.Printf("Processing YouTube URL: %s", url)
 
 	// Step 1: Check for duplicates
-	existing, err := db.GetSourceContentByURL(url)
+	existing, err := db.GetSourceContentByURL(url, req.OwnerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
 	}
 
 	if existing != nil {
 		log.Printf("URL already processed, returning existing data for source content ID: %d", existing.ID)
-		return s.getExistingProcessResult(ctx, existing)
+		s.recordJobSource(existing.ID)
+		return s.existingProcessResult(ctx, existing)
 	}
 
-	// Step 2: Fetch YouTube transcript and metadata
-	log.Printf("Fetching YouTube video info...")
-	videoInfo, err := s.youtubeClient.GetVideoInfo(ctx, url)
+	// Resolve which stages to run before doing any work
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withOwner(req.OwnerID).withScrubOverride(req.Scrub).withCaptions(req.Languages, req.Translate).processNewVideo(ctx, url, spec, 0)
+}
+
+// withOwner returns a copy of the service whose new sources, and with them
+// their concepts, quizzes, and content, belong to ownerID
+func (s *SourceContentService) withOwner(ownerID *int) *SourceContentService {
+	owned := *s
+	owned.ownerID = ownerID
+	return &owned
+}
+
+// withCaptions returns a copy of the service that prefers captions in
+// languages, in order, and with translate, translates other captions into the
+// first language listed
+func (s *SourceContentService) withCaptions(languages []string, translate bool) *SourceContentService {
+	captioned := *s
+	captioned.captionLanguages = languages
+	captioned.translateTo = ""
+	if translate {
+		for _, language := range languages {
+			if language != youtube.AutoLanguage {
+				captioned.translateTo = language
+				break
+			}
+		}
+	}
+	return &captioned
+}
+
+// resolveIngestSpec resolves the pipeline a new source runs: pipelineID, or
+// the named profile's pipeline, or the default, adjusted by the profile when
+// one is named. A request's quiz questions and target grade overrides apply
+// when non-nil.
+func resolveIngestSpec(profileName string, pipelineID *int, questions *models.QuestionCount, targetGrade *float64) (models.PipelineSpec, error) {
+	var profile *models.ProcessingProfile
+	if profileName != "" {
+		var err error
+		profile, err = db.GetProcessingProfileByName(profileName)
+		if err != nil {
+			return models.PipelineSpec{}, err
+		}
+		if pipelineID == nil {
+			pipelineID = profile.PipelineID
+		}
+	}
+
+	spec, err := resolvePipelineSpec(pipelineID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch YouTube video: %w", err)
+		return spec, err
+	}
+	if profile != nil {
+		spec = applyProfile(spec, *profile)
+	}
+	for i := range spec.Stages {
+		if questions != nil && spec.Stages[i].Name == models.StageQuizzes {
+			spec.Stages[i].Questions = questions
+		}
+		if targetGrade != nil && spec.Stages[i].Name == models.StageContent {
+			spec.Stages[i].TargetGrade = targetGrade
+		}
+	}
+	return spec, nil
+}
+
+// withScrubOverride applies a request's scrub setting, when it has one
+func (s *SourceContentService) withScrubOverride(scrub *bool) *SourceContentService {
+	if scrub == nil {
+		return s
+	}
+	return s.withScrub(*scrub)
+}
+
+// withScrub returns a copy of the service with scrubbing on or off. Turning it
+// on keeps SCRUB_TRANSCRIPTS' categories, or scrubs all PII when that's unset.
+func (s *SourceContentService) withScrub(scrub bool) *SourceContentService {
+	scrubbed := *s
+	switch {
+	case !scrub:
+		scrubbed.scrubber = nil
+	case s.scrubber == nil:
+		scrubbed.scrubber, _ = NewScrubber([]string{scrubPII})
+	}
+	return &scrubbed
+}
+
+// processNewVideo fetches, saves, and runs spec over a video that hasn't been
+// processed yet. A non-zero maxDuration rejects longer videos, and those of
+// unknown length, before any Claude calls.
+func (s *SourceContentService) processNewVideo(ctx context.Context, url string, spec models.PipelineSpec, maxDuration time.Duration) (*ProcessResult, error) {
+	// Step 2: Fetch YouTube transcript and metadata, or only the metadata
+	// when the audio is transcribed instead of the captions
+	log.Printf("Fetching YouTube video info...")
+	var videoInfo *youtube.VideoInfo
+	var err error
+	transcribeAudio := s.audioFallback.SkipsCaptions()
+	if transcribeAudio {
+		videoInfo = &youtube.VideoInfo{}
+		videoInfo.Metadata, err = s.youtubeClient.GetVideoMetadata(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch YouTube video: %w", err)
+		}
+	} else {
+		videoInfo, err = s.youtubeClient.GetVideoInfo(ctx, url, s.captionLanguages...)
+		transcribeAudio = errors.Is(err, youtube.ErrNoTranscript) && s.audioFallback != nil
+		if err != nil && !transcribeAudio {
+			return nil, fmt.Errorf("failed to fetch YouTube video: %w", err)
+		}
+	}
+
+	duration := time.Duration(videoInfo.Metadata.Duration) * time.Second
+	if maxDuration > 0 && (duration == 0 || duration > maxDuration) {
+		return nil, fmt.Errorf("%w of %s", ErrVideoTooLong, maxDuration)
+	}
+
+	// Transcribe the audio of videos without captions, or of every video with STT_SKIP_CAPTIONS
+	var words models.TranscriptWords
+	var chapters models.TranscriptChapters
+	if transcribeAudio {
+		audio, err := s.audioFallback.Transcribe(ctx, s.youtubeClient, url, videoInfo.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		videoInfo.Transcript = &audio.Transcript
+		words, chapters = audio.Words, audio.Chapters
 	}
 
 	if videoInfo.Transcript == nil {
 		return nil, fmt.Errorf("no transcript available for this video")
 	}
 
-	// Step 3: Save source content
-	log.Printf("Saving source content...")
-	sourceContent, err := db.CreateSourceContent(models.CreateSourceContentRequest{
+	// The caption track's cues time the transcript when no words do
+	var segments []models.TimedSegment
+	for _, seg := range videoInfo.Transcript.Segments {
+		segments = append(segments, models.TimedSegment{Start: seg.Start, End: seg.End, Text: seg.Text})
+	}
+
+	if s.translateTo != "" && !sameLanguage(videoInfo.Transcript.Language, s.translateTo) {
+		log.Printf("Translating %s transcript into %s...", videoInfo.Transcript.Language, s.translateTo)
+		translated, err := s.claudeService.TranslateTranscript(ctx, videoInfo.Transcript.Text, s.translateTo)
+		if err != nil {
+			return nil, err
+		}
+		videoInfo.Transcript.Text = translated
+		videoInfo.Transcript.Language = s.translateTo
+		words, segments = nil, nil // Timings don't carry over to the translation
+	}
+
+	return s.processSource(ctx, models.CreateSourceContentRequest{
 		Type:       "youtube",
 		URL:        url,
 		Title:      videoInfo.Metadata.Title,
 		Transcript: videoInfo.Transcript.Text,
-	})
+		Language:   videoInfo.Transcript.Language,
+		Words:      words,
+		Chapters:   chapters,
+		Segments:   segments,
+	}, spec)
+}
+
+// processSource scrubs, saves, and runs spec over a fetched source, whichever
+// provider it came from
+func (s *SourceContentService) processSource(ctx context.Context, source models.CreateSourceContentRequest, spec models.PipelineSpec) (*ProcessResult, error) {
+	// Redact PII before anything is stored or sent to Claude
+	var redactions map[string]int
+	if s.scrubber != nil {
+		var titleRedactions map[string]int
+		source.Transcript, redactions = s.scrubber.Scrub(source.Transcript)
+		source.Title, titleRedactions = s.scrubber.Scrub(source.Title)
+		for category, n := range titleRedactions {
+			redactions[category] += n
+		}
+		for i := range source.Chapters {
+			for _, text := range []*string{&source.Chapters[i].Headline, &source.Chapters[i].Summary} {
+				var chapterRedactions map[string]int
+				*text, chapterRedactions = s.scrubber.Scrub(*text)
+				for category, n := range chapterRedactions {
+					redactions[category] += n
+				}
+			}
+		}
+		// Redacted words are no longer in the transcript, so their timings go too
+		source.Words = alignedWords(source.Transcript, source.Words)
+		for i := range source.Segments {
+			source.Segments[i].Text, _ = s.scrubber.Scrub(source.Segments[i].Text)
+		}
+		log.Printf("Scrubbed transcript: %v", redactions)
+	}
+
+	// Step 3: Save source content
+	source.Profile = spec.Profile
+	source.OwnerID = s.ownerID
+	log.Printf("Saving source content (language %s)...", source.Language)
+	sourceContent, err := db.CreateSourceContent(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save source content: %w", err)
 	}
 
 	log.Printf("Source content saved with ID: %d", sourceContent.ID)
-
-	// Step 4: Extract concepts via Claude
-	log.Printf("Extracting concepts from transcript...")
-	concepts, err := s.claudeService.ExtractConcepts(ctx, videoInfo.Transcript.Text, sourceContent.ID)
+	RecordUsage(models.UsageSourcesProcessed, 1, &sourceContent.ID)
+	startPipelineRun(sourceContent.ID, spec)
+	s.recordJobSource(sourceContent.ID)
+	s.reportProgress(models.JobEvent{Step: models.JobStepTranscript, Status: models.JobStepCompleted, Message: fmt.Sprintf("Transcript fetched (%d words)", len(strings.Fields(source.Transcript)))})
+	s.reportProgress(models.JobEvent{Step: models.StageConcepts, Message: "Extracting concepts"})
+
+	// Step 4: Extract concepts via Claude (always the first stage)
+	result := emptyProcessResult(sourceContent)
+	result.Redactions = redactions
+	claudeService := s.claudeService.withLanguage(source.Language).forStage(withPromptExperiment(spec.Stages[0], sourceContent.ID))
+	savedConcepts, err := extractConcepts(ctx, claudeService, source.Transcript, sourceContent.ID)
 	if err != nil {
 		// Log error but don't fail - we have source content saved
-		log.Printf("Warning: Failed to extract concepts: %v", err)
-		return &ProcessResult{
-			SourceContent:    sourceContent,
-			Concepts:         []models.Concept{},
-			Quizzes:          []models.QuizQuestion{},
-			GeneratedContent: []models.GeneratedContent{},
-		}, nil
+		log.Printf("Warning: %v", err)
+		result.warn(models.StageConcepts, err)
+		finishPipelineRun(sourceContent.ID, models.PipelineRunIncomplete)
+		return result, nil
 	}
+	result.Concepts = savedConcepts
+	runHooks(ctx, models.StageConcepts, result)
+	checkpointStage(sourceContent.ID, models.StageConcepts)
+	s.reportProgress(models.JobEvent{Step: models.StageConcepts, Status: models.JobStepCompleted, Message: fmt.Sprintf("Extracted %d concepts", len(savedConcepts))})
 
-	// Save concepts to database
-	log.Printf("Saving %d concepts to database...", len(concepts))
-	savedConcepts, err := db.CreateConceptsBatch(concepts)
-	if err != nil {
-		log.Printf("Warning: Failed to save concepts: %v", err)
-		return &ProcessResult{
-			SourceContent:    sourceContent,
-			Concepts:         []models.Concept{},
-			Quizzes:          []models.QuizQuestion{},
-			GeneratedContent: []models.GeneratedContent{},
-		}, nil
-	}
+	// Step 5: Run the remaining stages in order
+	s.runStages(ctx, spec.Stages[1:], result, source.Transcript)
+	finishPipelineRun(sourceContent.ID, models.PipelineRunCompleted)
 
-	log.Printf("Concepts saved successfully")
+	// Step 6: Return complete result
+	log.Printf("Processing complete for source content ID: %d", sourceContent.ID)
+
+	s.notifier.NotifyUser(ctx, s.ownerID, models.EventPipelineComplete,
+		fmt.Sprintf("Finished processing \"%s\"", sourceContent.Title),
+		fmt.Sprintf("Extracted %d concepts, %d quiz questions, and %d content drafts.", len(result.Concepts), len(result.Quizzes), len(result.GeneratedContent)),
+		models.JSONObject{"source_content_id": sourceContent.ID},
+	)
 
-	// Step 5: Generate quizzes for each concept
+	return result, nil
+}
+
+// generateQuizzes generates and saves quiz questions for each concept, skipping
+// concepts whose generation fails. report is told as each concept finishes.
+func generateQuizzes(ctx context.Context, claudeService *ClaudeService, concepts []models.Concept, report func(models.JobEvent)) ([]models.QuizQuestion, []models.StageWarning) {
 	log.Printf("Generating quizzes for concepts...")
 	var allQuizzes []models.QuizQuestion
+	var warnings []models.StageWarning
 
-	for _, concept := range savedConcepts {
-		quizzes, err := s.claudeService.GenerateQuiz(ctx, concept)
+	for i, concept := range concepts {
+		quizzes, err := claudeService.GenerateQuiz(ctx, concept)
 		if err != nil {
 			log.Printf("Warning: Failed to generate quiz for concept %d: %v", concept.ID, err)
+			conceptID := concept.ID
+			warnings = append(warnings, models.StageWarning{
+				Stage:     models.StageQuizzes,
+				ConceptID: &conceptID,
+				Message:   fmt.Sprintf("failed to generate questions for %q: %v", concept.Title, err),
+			})
+			report(models.JobEvent{Step: models.StageQuizzes, Done: i + 1, Total: len(concepts), Message: fmt.Sprintf("Failed to generate questions for %q", concept.Title)})
 			continue
 		}
-		allQuizzes = append(allQuizzes, quizzes...)
+		allQuizzes = append(allQuizzes, dedupeQuestions(quizzes, nil)...)
+		report(models.JobEvent{Step: models.StageQuizzes, Done: i + 1, Total: len(concepts), Message: fmt.Sprintf("Generated questions for %q", concept.Title)})
 	}
 
 	// Save quizzes to database
@@ -131,54 +379,80 @@ func (s *SourceContentService) ProcessYouTubeURL(ctx context.Context, url string
 		if err != nil {
 			log.Printf("Warning: Failed to save quizzes: %v", err)
 			allQuizzes = []models.QuizQuestion{}
+			warnings = append(warnings, stageWarning(models.StageQuizzes, fmt.Errorf("failed to save quizzes: %w", err)))
 		} else {
 			allQuizzes = savedQuizzes
 			log.Printf("Quizzes saved successfully")
 		}
 	}
 
-	// Step 6: Generate content for all platforms
-	log.Printf("Generating marketing content...")
-	platforms := []string{"linkedin", "twitter", "blog"}
-	var generatedContents []models.GeneratedContent
+	return allQuizzes, warnings
+}
 
-	for _, platform := range platforms {
-		content, err := s.claudeService.GenerateContent(ctx, platform, savedConcepts)
-		if err != nil {
-			log.Printf("Warning: Failed to generate %s content: %v", platform, err)
-			continue
-		}
-		generatedContents = append(generatedContents, *content)
+// extractGlossary extracts and saves a source's glossary, replacing any earlier
+// one. Failures are logged and yield an empty glossary and a warning.
+func extractGlossary(ctx context.Context, claudeService *ClaudeService, sourceContentID int, transcript string, concepts []models.Concept) ([]models.GlossaryTerm, []models.StageWarning) {
+	log.Printf("Extracting glossary from transcript...")
+	terms, err := claudeService.ExtractGlossary(ctx, transcript, sourceContentID, concepts)
+	if err != nil {
+		log.Printf("Warning: Failed to extract glossary: %v", err)
+		return []models.GlossaryTerm{}, []models.StageWarning{stageWarning(models.StageGlossary, err)}
 	}
 
-	// Save generated content to database
-	if len(generatedContents) > 0 {
-		log.Printf("Saving %d generated content pieces to database...", len(generatedContents))
-		savedContent, err := db.CreateGeneratedContentBatch(generatedContents)
-		if err != nil {
-			log.Printf("Warning: Failed to save generated content: %v", err)
-			generatedContents = []models.GeneratedContent{}
-		} else {
-			generatedContents = savedContent
-			log.Printf("Generated content saved successfully")
-		}
+	saved, err := db.ReplaceGlossaryTerms(sourceContentID, terms)
+	if err != nil {
+		log.Printf("Warning: Failed to save glossary: %v", err)
+		return []models.GlossaryTerm{}, []models.StageWarning{stageWarning(models.StageGlossary, fmt.Errorf("failed to save glossary: %w", err))}
 	}
 
-	// Step 7: Return complete result
-	log.Printf("Processing complete for source content ID: %d", sourceContent.ID)
+	log.Printf("Glossary saved with %d terms", len(saved))
+	return saved, nil
+}
 
-	return &ProcessResult{
-		SourceContent:    sourceContent,
-		Concepts:         savedConcepts,
-		Quizzes:          allQuizzes,
-		GeneratedContent: generatedContents,
-	}, nil
+// extractActionItems extracts and saves a source's action items, replacing any
+// still open. Failures are logged and yield no items and a warning.
+func extractActionItems(ctx context.Context, claudeService *ClaudeService, sourceContentID int, transcript string, concepts []models.Concept) ([]models.ActionItem, []models.StageWarning) {
+	log.Printf("Extracting action items from transcript...")
+	items, err := claudeService.ExtractActionItems(ctx, transcript, sourceContentID, concepts)
+	if err != nil {
+		log.Printf("Warning: Failed to extract action items: %v", err)
+		return []models.ActionItem{}, []models.StageWarning{stageWarning(models.StageActionItems, err)}
+	}
+
+	saved, err := db.ReplaceActionItems(sourceContentID, items)
+	if err != nil {
+		log.Printf("Warning: Failed to save action items: %v", err)
+		return []models.ActionItem{}, []models.StageWarning{stageWarning(models.StageActionItems, fmt.Errorf("failed to save action items: %w", err))}
+	}
+
+	log.Printf("Saved %d action items", len(saved))
+	return saved, nil
+}
+
+// extractMentions extracts and saves a source's mentions, replacing any earlier
+// ones. Failures are logged and yield no mentions and a warning.
+func extractMentions(ctx context.Context, claudeService *ClaudeService, sourceContentID int, transcript string) ([]models.Mention, []models.StageWarning) {
+	log.Printf("Extracting mentions from transcript...")
+	mentions, err := claudeService.ExtractMentions(ctx, transcript, sourceContentID)
+	if err != nil {
+		log.Printf("Warning: Failed to extract mentions: %v", err)
+		return []models.Mention{}, []models.StageWarning{stageWarning(models.StageMentions, err)}
+	}
+
+	saved, err := db.ReplaceMentions(sourceContentID, mentions)
+	if err != nil {
+		log.Printf("Warning: Failed to save mentions: %v", err)
+		return []models.Mention{}, []models.StageWarning{stageWarning(models.StageMentions, fmt.Errorf("failed to save mentions: %w", err))}
+	}
+
+	log.Printf("Saved %d mentions", len(saved))
+	return saved, nil
 }
 
 // getExistingProcessResult retrieves all related data for an existing source content
 func (s *SourceContentService) getExistingProcessResult(ctx context.Context, sourceContent *models.SourceContent) (*ProcessResult, error) {
 	// Get concepts
-	concepts, err := db.GetConceptsBySourceContentID(sourceContent.ID)
+	concepts, err := db.GetConceptsBySourceContentID(sourceContent.ID, true)
 	if err != nil {
 		log.Printf("Warning: Failed to get concepts: %v", err)
 		concepts = []models.Concept{}
@@ -191,6 +465,33 @@ func (s *SourceContentService) getExistingProcessResult(ctx context.Context, sou
 		quizzes = []models.QuizQuestion{}
 	}
 
+	// Get glossary
+	glossary, err := db.GetGlossaryTermsBySourceContentID(sourceContent.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to get glossary: %v", err)
+		glossary = []models.GlossaryTerm{}
+	}
+
+	// Get action items
+	actionItems, err := db.GetActionItems(&sourceContent.ID, nil, nil)
+	if err != nil {
+		log.Printf("Warning: Failed to get action items: %v", err)
+		actionItems = []models.ActionItem{}
+	}
+
+	// Get mentions
+	mentions, err := db.GetMentionsBySourceContentID(sourceContent.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to get mentions: %v", err)
+		mentions = []models.Mention{}
+	}
+
+	// Get the run's checkpoints
+	run, err := db.GetPipelineRun(sourceContent.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to get pipeline run: %v", err)
+	}
+
 	// Get generated content (by concept IDs)
 	var generatedContent []models.GeneratedContent
 	if len(concepts) > 0 {
@@ -212,7 +513,12 @@ func (s *SourceContentService) getExistingProcessResult(ctx context.Context, sou
 		SourceContent:    sourceContent,
 		Concepts:         concepts,
 		Quizzes:          quizzes,
+		Glossary:         glossary,
+		ActionItems:      actionItems,
+		Mentions:         mentions,
 		GeneratedContent: generatedContent,
+		Warnings:         []models.StageWarning{},
+		PipelineRun:      run,
 	}, nil
 }
 
@@ -225,3 +531,218 @@ func (s *SourceContentService) GetSourceContentWithRelated(ctx context.Context,
 
 	return s.getExistingProcessResult(ctx, sourceContent)
 }
+
+// CorrectTranscript applies a manual correction to a source's transcript. The
+// transcript as first fetched is kept. With req.Reextract, the source's current
+// concepts are archived and the default pipeline's stages, except marketing
+// content, are re-run against the corrected text.
+func (s *SourceContentService) CorrectTranscript(ctx context.Context, id int, req models.CorrectTranscriptRequest) (*models.TranscriptCorrectionResult, error) {
+	sourceContent, err := db.GetSourceContentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	transcript := sourceContent.Transcript
+	if req.Transcript != nil {
+		transcript = *req.Transcript
+	}
+
+	replaced := 0
+	for _, r := range req.Replacements {
+		replaced += strings.Count(transcript, r.Find)
+		transcript = strings.ReplaceAll(transcript, r.Find, r.Replace)
+	}
+
+	if strings.TrimSpace(transcript) == "" {
+		return nil, fmt.Errorf("corrected transcript is empty")
+	}
+
+	// Corrections can't reintroduce what scrubbing removed
+	transcript, _ = s.scrubber.Scrub(transcript)
+
+	result := &models.TranscriptCorrectionResult{
+		SourceContent: sourceContent,
+		Replacements:  replaced,
+	}
+
+	if transcript != sourceContent.Transcript {
+		updated, err := db.UpdateSourceContentTranscript(id, transcript)
+		if err != nil {
+			return nil, err
+		}
+		result.SourceContent = updated
+		result.Changed = true
+	}
+
+	if !req.Reextract {
+		// Offer a re-run when the text the concepts came from has changed
+		result.ReextractSuggested = result.Changed
+		return result, nil
+	}
+
+	// Re-run the source's pipeline, without regenerating or fact-checking marketing content
+	spec, err := resolveSourceSpec(sourceContent)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Re-extracting concepts from corrected transcript for source content ID: %d", id)
+	claudeService := s.claudeService.withLanguage(sourceLanguage(sourceContent)).forStage(withPromptExperiment(spec.Stages[0], id))
+	concepts, err := claudeService.ExtractConcepts(ctx, transcript, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract concepts: %w", err)
+	}
+
+	archived, err := db.ArchiveConceptsBySourceContentID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	savedConcepts, err := db.CreateConceptsBatch(concepts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save concepts: %w", err)
+	}
+	WakeEmbedder()
+
+	stages := make([]models.PipelineStage, 0, len(spec.Stages)-1)
+	for _, stage := range spec.Stages[1:] {
+		if stage.Name != models.StageContent && stage.Name != models.StageFactCheck {
+			stages = append(stages, stage)
+		}
+	}
+
+	rerun := emptyProcessResult(result.SourceContent)
+	rerun.Concepts = savedConcepts
+	runHooks(ctx, models.StageConcepts, rerun)
+	s.runStages(ctx, stages, rerun, transcript)
+
+	result.Reextracted = true
+	result.ArchivedConcepts = archived
+	result.Concepts = rerun.Concepts
+	result.Quizzes = rerun.Quizzes
+	result.Glossary = rerun.Glossary
+	result.ActionItems = rerun.ActionItems
+	result.Mentions = rerun.Mentions
+	result.Warnings = rerun.Warnings
+
+	return result, nil
+}
+
+// Discuss answers a chat message about one source, starting a new chat unless
+// req.ChatID continues an existing one
+func (s *SourceContentService) Discuss(ctx context.Context, sourceID int, req models.SourceChatRequest) (*models.SourceChatResponse, error) {
+	sourceContent, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var chatID int
+	history := []models.ChatMessage{}
+	if req.ChatID != nil {
+		chat, err := db.GetSourceChatByID(*req.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		if chat.SourceContentID != sourceID {
+			return nil, fmt.Errorf("chat not found")
+		}
+		chatID = chat.ID
+
+		history, err = db.GetChatMessages(chatID)
+		if err != nil {
+			return nil, err
+		}
+		// Keep recent turns only, starting on a user message
+		if len(history) > chatHistoryLimit {
+			history = history[len(history)-chatHistoryLimit:]
+		}
+		for len(history) > 0 && history[0].Role != models.ChatRoleUser {
+			history = history[1:]
+		}
+	}
+
+	reply, err := s.claudeService.DiscussSource(ctx, *sourceContent, concepts, history, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only create the chat once there is a reply to store in it
+	if req.ChatID == nil {
+		chat, err := db.CreateSourceChat(sourceID)
+		if err != nil {
+			return nil, err
+		}
+		chatID = chat.ID
+	}
+
+	message, replyMessage, err := db.CreateChatExchange(chatID, req.Message, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SourceChatResponse{
+		ChatID:  chatID,
+		Message: *message,
+		Reply:   *replyMessage,
+	}, nil
+}
+
+// GetChat retrieves one of a source's chats with its messages
+func (s *SourceContentService) GetChat(ctx context.Context, sourceID, chatID int) (*models.SourceChatDetail, error) {
+	chat, err := db.GetSourceChatByID(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.SourceContentID != sourceID {
+		return nil, fmt.Errorf("chat not found")
+	}
+
+	messages, err := db.GetChatMessages(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SourceChatDetail{
+		Chat:     *chat,
+		Messages: messages,
+	}, nil
+}
+
+// CompareSources compares two or more sources and stores the comparison. A
+// source req.OwnerID can't see is not found.
+func (s *SourceContentService) CompareSources(ctx context.Context, req models.CompareSourcesRequest) (*models.SourceComparison, error) {
+	hidden, err := db.CountHidden("source_contents", req.SourceContentIDs, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden > 0 {
+		return nil, fmt.Errorf("source content not found")
+	}
+
+	sources := make([]models.SourceContent, 0, len(req.SourceContentIDs))
+	concepts := make(map[int][]models.Concept, len(req.SourceContentIDs))
+	for _, id := range req.SourceContentIDs {
+		source, err := db.GetSourceContentByID(id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+
+		concepts[id], err = db.GetConceptsBySourceContentID(id, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	comparison, err := s.claudeService.CompareSources(ctx, sources, concepts)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.CreateSourceComparison(*comparison)
+}
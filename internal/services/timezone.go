@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+)
+
+// DefaultLocation returns the zone for callers without a timezone setting and
+// for background jobs like the review digest: the workspace timezone setting,
+// from TIMEZONE by default (UTC when unset)
+func DefaultLocation() *time.Location {
+	return current.Load().location
+}
+
+// ValidateTimezone reports whether name is a loadable IANA zone
+func ValidateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("timezone must be an IANA zone name, e.g. America/New_York")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("timezone must be an IANA zone name, e.g. America/New_York")
+	}
+	return nil
+}
+
+// UserLocation returns a user's zone, or DefaultLocation when they haven't set
+// one. A nil userID (API tokens, or auth disabled) also gets DefaultLocation.
+func UserLocation(userID *int) *time.Location {
+	if userID == nil {
+		return DefaultLocation()
+	}
+
+	name, err := db.GetUserTimezone(*userID)
+	if err != nil {
+		log.Printf("Warning: Failed to load timezone for user %d: %v", *userID, err)
+		return DefaultLocation()
+	}
+	if name == nil {
+		return DefaultLocation()
+	}
+
+	loc, err := time.LoadLocation(*name)
+	if err != nil {
+		return DefaultLocation()
+	}
+	return loc
+}
+
+// endOfDay returns the start of the day after now in loc. Anything due before
+// it is due today.
+func endOfDay(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// nextLocalTime returns the next time after now that the clock in loc reads
+// hour:minute. Built with time.Date so DST changes keep the wall-clock time.
+func nextLocalTime(now time.Time, hour, minute int, loc *time.Location) time.Time {
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, loc)
+	}
+	return next
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// Transcript segmentation and highlight tuning
+const (
+	segmentTargetWords    = 60  // Segments close at the first sentence end past this
+	segmentMaxWords       = 120 // Hard cap for transcripts without punctuation
+	highlightMinScore     = 0.3
+	highlightsPerConcept  = 3
+	titleTermWeight       = 2.0
+	descriptionTermWeight = 1.0
+	wordAlignWindow       = 200 // Bytes ahead a timed word is looked for, so a missing word can't skip the rest
+)
+
+// stopwords are skipped when matching concept terms against the transcript
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "can": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "his": true, "has": true, "how": true, "its": true,
+	"who": true, "did": true, "get": true, "got": true, "him": true, "she": true,
+	"too": true, "use": true, "that": true, "with": true, "this": true, "from": true,
+	"they": true, "will": true, "have": true, "what": true, "when": true, "your": true,
+	"into": true, "than": true, "then": true, "them": true, "been": true, "more": true,
+	"some": true, "such": true, "only": true, "also": true, "each": true, "which": true,
+	"their": true, "there": true, "about": true, "would": true, "these": true, "other": true,
+	"using": true, "while": true, "where": true, "being": true, "should": true, "because": true,
+}
+
+// BuildTranscriptView segments a source's transcript and marks the segments that
+// support each of its active concepts, for rendering the transcript with highlights
+func BuildTranscriptView(sourceID int) (*models.TranscriptView, error) {
+	source, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := sourceSegments(source.ID, source.Transcript)
+	timed, err := db.GetTranscriptSegments(source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Type == "youtube" {
+		for i := range segments {
+			if segments[i].StartSeconds != nil {
+				link := timestampedURL(source.URL, int(*segments[i].StartSeconds))
+				segments[i].URL = &link
+			}
+		}
+	}
+
+	return &models.TranscriptView{
+		SourceContentID: source.ID,
+		Title:           source.Title,
+		URL:             source.URL,
+		Segments:        segments,
+		Highlights:      conceptHighlights(segments, concepts),
+		Chapters:        source.Chapters,
+		TimedSegments:   timed,
+	}, nil
+}
+
+// sourceSegments segments a source's transcript, timing the segments by its
+// word timestamps when its transcription provider returned them, or else by
+// the cues of the caption track it was built from
+func sourceSegments(sourceID int, transcript string) []models.TranscriptSegment {
+	segments := segmentTranscript(transcript)
+
+	words, err := db.GetTranscriptWords(sourceID)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return segments
+	}
+
+	if len(words) == 0 {
+		timed, err := db.GetTranscriptSegments(sourceID)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return segments
+		}
+		// Cues align with the transcript like words do, only longer
+		for _, seg := range timed {
+			words = append(words, models.TranscriptWord{Text: seg.Text, Start: seg.Start, End: seg.End})
+		}
+	}
+
+	timeSegments(segments, transcript, words)
+	return segments
+}
+
+// timeSegments times each segment from the first and last of words found within it
+func timeSegments(segments []models.TranscriptSegment, transcript string, words models.TranscriptWords) {
+	s := 0
+	for i, at := range alignWords(transcript, words) {
+		if at < 0 {
+			continue
+		}
+		for s < len(segments) && at >= segments[s].EndOffset {
+			s++
+		}
+		if s == len(segments) {
+			break
+		}
+		if at < segments[s].StartOffset {
+			continue
+		}
+
+		start, end := words[i].Start, words[i].End
+		if segments[s].StartSeconds == nil {
+			segments[s].StartSeconds = &start
+		}
+		segments[s].EndSeconds = &end
+	}
+}
+
+// alignWords finds each timed word in transcript, in order, returning its
+// byte offset, or -1 for words edited or redacted out since
+func alignWords(transcript string, words models.TranscriptWords) []int {
+	offsets := make([]int, len(words))
+	cursor := 0
+	for i, w := range words {
+		offsets[i] = -1
+		if w.Text == "" {
+			continue
+		}
+
+		window := transcript[cursor:]
+		if len(window) > wordAlignWindow {
+			window = window[:wordAlignWindow]
+		}
+		if at := strings.Index(window, w.Text); at >= 0 {
+			offsets[i] = cursor + at
+			cursor += at + len(w.Text)
+		}
+	}
+	return offsets
+}
+
+// alignedWords returns the timed words still found in transcript
+func alignedWords(transcript string, words models.TranscriptWords) models.TranscriptWords {
+	var aligned models.TranscriptWords
+	for i, at := range alignWords(transcript, words) {
+		if at >= 0 {
+			aligned = append(aligned, words[i])
+		}
+	}
+	return aligned
+}
+
+// segmentTranscript splits a transcript into passages of roughly
+// segmentTargetWords words, breaking at sentence ends where there are any
+func segmentTranscript(transcript string) []models.TranscriptSegment {
+	segments := []models.TranscriptSegment{}
+
+	start, words := -1, 0
+	closeSegment := func(end int) {
+		if start < 0 {
+			return
+		}
+		segments = append(segments, models.TranscriptSegment{
+			Index:       len(segments),
+			Text:        transcript[start:end],
+			StartOffset: start,
+			EndOffset:   end,
+		})
+		start, words = -1, 0
+	}
+
+	inWord := false
+	for i, r := range transcript {
+		if unicode.IsSpace(r) {
+			if inWord {
+				inWord = false
+				prev := transcript[i-1]
+				sentenceEnd := prev == '.' || prev == '?' || prev == '!'
+				if (words >= segmentTargetWords && sentenceEnd) || words >= segmentMaxWords {
+					closeSegment(i)
+				}
+			}
+			continue
+		}
+		if !inWord {
+			inWord = true
+			words++
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	closeSegment(len(strings.TrimRightFunc(transcript, unicode.IsSpace)))
+
+	return segments
+}
+
+// conceptHighlights scores each segment by the weighted share of a concept's key
+// terms it contains and keeps the best few segments per concept
+func conceptHighlights(segments []models.TranscriptSegment, concepts []models.Concept) []models.TranscriptHighlight {
+	highlights := []models.TranscriptHighlight{}
+
+	for _, concept := range concepts {
+		weights := map[string]float64{}
+		for _, term := range keyTerms(concept.Description) {
+			weights[term] = descriptionTermWeight
+		}
+		for _, term := range keyTerms(concept.Title) {
+			weights[term] = titleTermWeight
+		}
+
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		if total == 0 {
+			continue
+		}
+
+		var candidates []models.TranscriptHighlight
+		for _, segment := range segments {
+			matches, found := matchTerms(segment.Text, weights)
+			var score float64
+			for term := range found {
+				score += weights[term]
+			}
+			score /= total
+
+			if score >= highlightMinScore {
+				candidates = append(candidates, models.TranscriptHighlight{
+					ConceptID:    concept.ID,
+					ConceptTitle: concept.Title,
+					SegmentIndex: segment.Index,
+					Score:        score,
+					Matches:      matches,
+				})
+			}
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Score > candidates[j].Score
+		})
+		if len(candidates) > highlightsPerConcept {
+			candidates = candidates[:highlightsPerConcept]
+		}
+		highlights = append(highlights, candidates...)
+	}
+
+	return highlights
+}
+
+// keyTerms extracts lowercase content words of at least three letters
+func keyTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isTermSeparator) {
+		if len(word) >= 3 && !stopwords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// matchTerms finds the words in text that are weighted terms, returning their
+// byte ranges and the set of distinct terms found
+func matchTerms(text string, weights map[string]float64) ([]models.TextRange, map[string]bool) {
+	matches := []models.TextRange{}
+	found := map[string]bool{}
+
+	start := -1
+	for i, r := range text + " " {
+		if !isTermSeparator(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			word := strings.ToLower(text[start:i])
+			if _, ok := weights[word]; ok {
+				matches = append(matches, models.TextRange{Start: start, End: i})
+				found[word] = true
+			}
+			start = -1
+		}
+	}
+
+	return matches, found
+}
+
+// isTermSeparator reports whether r separates words for term matching
+func isTermSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
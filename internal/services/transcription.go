@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+	"github.com/mostlyerror/lattice/pkg/transcription"
+)
+
+// CreatePendingTranscription registers a source that an external service
+// will transcribe. The provider is given the callback URL built from the
+// returned record's token, and the pipeline runs when it calls back.
+// AssemblyAI and Rev callbacks only name the job, so their stored credential
+// must exist up front to fetch the transcript with.
+func CreatePendingTranscription(req models.CreateTranscriptionRequest) (*models.PendingTranscription, error) {
+	spec, err := resolveIngestSpec(req.Profile, req.PipelineID, req.QuizQuestions, req.TargetGrade)
+	if err != nil {
+		return nil, err
+	}
+
+	if transcription.NeedsAPIKey(req.Provider) {
+		if _, err := CredentialSecret(req.Provider, req.Credential); err != nil {
+			return nil, err
+		}
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate callback token: %w", err)
+	}
+
+	return db.CreatePendingTranscription(models.PendingTranscription{
+		Token:      hex.EncodeToString(token),
+		Provider:   req.Provider,
+		Title:      req.Title,
+		Credential: req.Credential,
+		Spec:       spec,
+		Scrub:      req.Scrub,
+		OwnerID:    req.OwnerID,
+	})
+}
+
+// ReceiveTranscription handles a provider's callback for the pending
+// transcription with token. The transcript, fetched when the callback only
+// names the job, moves the record to processing, and the pipeline runs in the
+// background so the provider isn't kept waiting on Claude. Failed jobs fail
+// the record. Repeated callbacks return the record unchanged, and errors
+// fetching the transcript leave it pending for the provider to retry.
+func (s *SourceContentService) ReceiveTranscription(ctx context.Context, token string, body []byte) (*models.PendingTranscription, error) {
+	pending, err := db.GetPendingTranscriptionByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if pending.Status != models.TranscriptionPending {
+		return pending, nil
+	}
+
+	callback, err := transcription.ParseCallback(pending.Provider, body)
+	if errors.Is(err, transcription.ErrJobFailed) {
+		log.Printf("Transcription %d failed at %s: %v", pending.ID, pending.Provider, err)
+		return failTranscription(pending.ID, callback.JobID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := callback.Result
+	if result == nil {
+		apiKey, err := CredentialSecret(pending.Provider, pending.Credential)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = transcription.FetchTranscript(ctx, pending.Provider, apiKey, callback.JobID); err != nil {
+			if !errors.Is(err, transcription.ErrJobFailed) && !errors.Is(err, transcription.ErrNoTranscript) {
+				return nil, err
+			}
+			return failTranscription(pending.ID, callback.JobID, err)
+		}
+	}
+	if len(result.Transcript.Utterances) == 0 {
+		return failTranscription(pending.ID, callback.JobID, transcription.ErrNoTranscript)
+	}
+	if result.Transcript.Language == "" {
+		result.Transcript.Language = callback.Language
+	}
+	result.Transcript.Title = pending.Title
+
+	claimed, err := db.ClaimPendingTranscription(pending.ID, callback.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if claimed == nil {
+		return db.GetPendingTranscriptionByID(pending.ID) // A concurrent callback claimed it
+	}
+
+	log.Printf("Received %s transcript for pending transcription %d", claimed.Provider, claimed.ID)
+	go s.processTranscription(context.WithoutCancel(ctx), *claimed, result)
+
+	return claimed, nil
+}
+
+// processTranscription runs a claimed transcription's pipeline and records
+// the source it became, or why it failed
+func (s *SourceContentService) processTranscription(ctx context.Context, pending models.PendingTranscription, result *transcription.Result) {
+	var sourceID *int
+	var failure *string
+
+	jobID := "pending-" + strconv.Itoa(pending.ID)
+	if pending.JobID != nil {
+		jobID = *pending.JobID
+	}
+
+	words, chapters := transcriptTiming(result)
+	processed, err := s.withOwner(pending.OwnerID).withScrubOverride(pending.Scrub).processSource(ctx, models.CreateSourceContentRequest{
+		Type:       "recording",
+		URL:        pending.Provider + "://jobs/" + jobID,
+		Title:      result.Transcript.Title,
+		Transcript: result.Transcript.Text(),
+		Language:   result.Transcript.Language,
+		Words:      words,
+		Chapters:   chapters,
+	}, pending.Spec)
+	if err != nil {
+		log.Printf("Error processing transcription %d: %v", pending.ID, err)
+		message := err.Error()
+		failure = &message
+	} else {
+		sourceID = &processed.SourceContent.ID
+	}
+
+	if _, err := db.FinishPendingTranscription(pending.ID, sourceID, failure); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// failTranscription records that a pending transcription's job failed
+func failTranscription(id int, jobID string, cause error) (*models.PendingTranscription, error) {
+	claimed, err := db.ClaimPendingTranscription(id, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if claimed == nil {
+		return db.GetPendingTranscriptionByID(id)
+	}
+	message := cause.Error()
+	return db.FinishPendingTranscription(id, nil, &message)
+}
+
+// transcriptTiming converts a transcription's word timings and chapters for storing
+func transcriptTiming(result *transcription.Result) (models.TranscriptWords, models.TranscriptChapters) {
+	var words models.TranscriptWords
+	for _, w := range result.Words {
+		words = append(words, models.TranscriptWord{Text: w.Text, Start: w.Start, End: w.End, Speaker: w.Speaker})
+	}
+
+	var chapters models.TranscriptChapters
+	for _, c := range result.Chapters {
+		chapters = append(chapters, models.TranscriptChapter{Headline: c.Headline, Summary: c.Summary, StartSeconds: c.Start, EndSeconds: c.End})
+	}
+
+	return words, chapters
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mostlyerror/lattice/internal/auth"
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// SignIn resolves a provider identity to a user and issues a session. When
+// currentUserID is set (the caller is already signed in) the identity is
+// linked to that user. Otherwise an already linked identity signs its user
+// in, a verified email links to the user with that email (an invited user's
+// included), and anything else creates a new user if sign-up allows it.
+func SignIn(provider string, identity auth.Identity, currentUserID *int) (*models.LoginResult, error) {
+	linkedID, err := db.GetUserIDByIdentity(provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var email *string
+	if identity.EmailVerified && identity.Email != "" {
+		email = &identity.Email
+	}
+
+	var userID int
+	linked := false
+	switch {
+	case currentUserID != nil:
+		if linkedID != nil && *linkedID != *currentUserID {
+			return nil, fmt.Errorf("identity is linked to another user")
+		}
+		userID = *currentUserID
+		linked = linkedID == nil
+	case linkedID != nil:
+		userID = *linkedID
+	default:
+		var existingID *int
+		if email != nil {
+			if existingID, err = db.GetUserIDByEmail(*email); err != nil {
+				return nil, err
+			}
+		}
+		if existingID != nil {
+			userID = *existingID
+			linked = true
+		} else if userID, err = db.CreateUser(email, identity.Name, signupAllowed(email)); err != nil {
+			return nil, err
+		}
+	}
+
+	if linkedID == nil {
+		if err := db.LinkUserIdentity(userID, provider, identity.Subject, email); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.RecordUserLogin(userID); err != nil {
+		return nil, err
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionEmail := ""
+	if user.Email != nil {
+		sessionEmail = *user.Email
+	}
+	token, expiresAt, err := auth.IssueSession(user.ID, sessionEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{Token: token, ExpiresAt: expiresAt, User: *user, Linked: linked}, nil
+}
+
+// signupAllowed reports whether an account with this verified email may sign
+// up uninvited: its domain is listed in SIGNUP_EMAIL_DOMAINS (comma-separated)
+func signupAllowed(email *string) bool {
+	if email == nil {
+		return false
+	}
+	_, domain, ok := strings.Cut(*email, "@")
+	if !ok {
+		return false
+	}
+	for _, allowed := range strings.Split(os.Getenv("SIGNUP_EMAIL_DOMAINS"), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && strings.EqualFold(allowed, domain) {
+			return true
+		}
+	}
+	return false
+}
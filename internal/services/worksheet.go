@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/db"
+	"github.com/mostlyerror/lattice/internal/models"
+)
+
+// worksheetQuestion is a numbered question on a worksheet
+type worksheetQuestion struct {
+	Number   int
+	Question models.QuizQuestion
+	Answer   string // Text of the correct option
+}
+
+// worksheetSection groups a concept's questions
+type worksheetSection struct {
+	Concept   models.Concept
+	Questions []worksheetQuestion
+}
+
+// worksheetData is the template input for a printable worksheet
+type worksheetData struct {
+	Source      models.SourceContent
+	Sections    []worksheetSection
+	AnswerKey   bool
+	GeneratedAt time.Time
+}
+
+// RenderWorksheet renders a source's concepts and quizzes as a printable HTML
+// worksheet: a concept summary, the questions, and an answer key on its own page.
+// Concepts follow the source's teaching order; archived concepts are left out.
+// Printing to PDF from a browser keeps the page breaks.
+func RenderWorksheet(sourceID int, answerKey bool) ([]byte, error) {
+	source, err := db.GetSourceContentByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	concepts, err := db.GetConceptsBySourceContentID(sourceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	data := worksheetData{
+		Source:      *source,
+		AnswerKey:   answerKey,
+		GeneratedAt: time.Now(),
+	}
+
+	number := 0
+	for _, concept := range concepts {
+		quizzes, err := db.GetQuizzesByConceptID(concept.ID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		section := worksheetSection{Concept: concept}
+		for _, q := range quizzes {
+			number++
+			section.Questions = append(section.Questions, worksheetQuestion{
+				Number:   number,
+				Question: q,
+				Answer:   optionText(q, q.CorrectAnswer),
+			})
+		}
+		data.Sections = append(data.Sections, section)
+	}
+
+	var buf bytes.Buffer
+	if err := worksheetTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render worksheet: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// optionText returns the text of the option stored under a canonical letter
+func optionText(q models.QuizQuestion, letter string) string {
+	switch letter {
+	case "A":
+		return q.OptionA
+	case "B":
+		return q.OptionB
+	case "C":
+		return q.OptionC
+	case "D":
+		return q.OptionD
+	}
+	return ""
+}
+
+var worksheetTemplate = template.Must(template.New("worksheet").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Source.Title}} — Worksheet</title>
+<style>
+  body { font-family: Georgia, "Times New Roman", serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #111; line-height: 1.45; }
+  h1 { font-size: 1.6rem; margin-bottom: 0.25rem; }
+  h2 { font-size: 1.2rem; border-bottom: 1px solid #999; padding-bottom: 0.2rem; margin-top: 2rem; }
+  h3 { font-size: 1rem; margin: 1.25rem 0 0.25rem; }
+  .meta { color: #555; font-size: 0.9rem; }
+  .concept p { margin: 0.25rem 0 0.75rem; }
+  .question { break-inside: avoid; page-break-inside: avoid; margin: 1rem 0; }
+  .question ol { list-style: upper-alpha; margin: 0.4rem 0; }
+  .question li { margin: 0.2rem 0; }
+  .answer-key { break-before: page; page-break-before: always; }
+  .answer-key .question { margin: 0.6rem 0; }
+  .explanation { color: #333; font-size: 0.95rem; }
+  @media print { body { margin: 0; max-width: none; } }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Source.Title}}</h1>
+  <div class="meta">{{.Source.URL}} · Generated {{.GeneratedAt.Format "January 2, 2006"}}</div>
+</header>
+
+<section>
+  <h2>Key Concepts</h2>
+  {{range .Sections}}
+  <div class="concept">
+    <h3>{{.Concept.Title}}</h3>
+    <p>{{.Concept.Description}}</p>
+  </div>
+  {{end}}
+</section>
+
+<section>
+  <h2>Questions</h2>
+  {{range .Sections}}{{if .Questions}}
+  <h3>{{.Concept.Title}}</h3>
+  {{range .Questions}}
+  <div class="question">
+    <strong>{{.Number}}.</strong> {{.Question.Question}}
+    <ol>
+      <li>{{.Question.OptionA}}</li>
+      <li>{{.Question.OptionB}}</li>
+      <li>{{.Question.OptionC}}</li>
+      <li>{{.Question.OptionD}}</li>
+    </ol>
+  </div>
+  {{end}}{{end}}{{end}}
+</section>
+
+{{if .AnswerKey}}
+<section class="answer-key">
+  <h2>Answer Key</h2>
+  {{range .Sections}}{{range .Questions}}
+  <div class="question">
+    <strong>{{.Number}}. {{.Question.CorrectAnswer}}</strong> — {{.Answer}}
+    <div class="explanation">{{.Question.Explanation}}</div>
+  </div>
+  {{end}}{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
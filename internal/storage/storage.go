@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no object is stored under a key
+	ErrNotFound = errors.New("object not found")
+
+	// ErrInvalidKey is returned for keys that are empty, absolute, or escape the store
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Key prefixes for each kind of stored object
+const (
+	PrefixUploads     = "uploads/"
+	PrefixAudio       = "audio/"
+	PrefixKeyframes   = "keyframes/"
+	PrefixExports     = "exports/"
+	PrefixTranscripts = "transcripts/"
+	PrefixPodcast     = "podcast/" // Audio summaries and briefings, kept until replaced (unlike audio/, which retention prunes)
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Storage stores objects (uploads, audio, exports, large transcripts) by key.
+// Keys are slash-separated relative paths such as "exports/42/worksheet.html".
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing
+	// object. Remote stores need the exact size; local disk accepts -1 for unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// SignedURL returns a URL a client can download the object from until expiry
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Store is the configured object store, set by Init
+var Store Storage
+
+// Init configures Store from STORAGE_DRIVER (local, s3, or gcs; defaults to local)
+func Init() error {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+
+	var err error
+	switch driver {
+	case "local":
+		Store, err = newLocalFromEnv()
+	case "s3":
+		Store, err = NewS3Storage(S3Config{
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          os.Getenv("S3_REGION"),
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		})
+	case "gcs":
+		Store, err = NewGCSStorage(os.Getenv("GCS_BUCKET"), os.Getenv("GCS_HMAC_ACCESS_ID"), os.Getenv("GCS_HMAC_SECRET"))
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Object storage configured (%s)", driver)
+	return nil
+}
+
+// newLocalFromEnv builds local disk storage from STORAGE_LOCAL_PATH and
+// STORAGE_SIGNING_KEY, generating a signing key when none is set
+func newLocalFromEnv() (*LocalStorage, error) {
+	root := os.Getenv("STORAGE_LOCAL_PATH")
+	if root == "" {
+		root = "data/files"
+	}
+
+	signingKey := os.Getenv("STORAGE_SIGNING_KEY")
+	if signingKey == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		signingKey = hex.EncodeToString(key)
+		log.Println("Warning: STORAGE_SIGNING_KEY is not set; signed file URLs won't survive a restart")
+	}
+
+	return NewLocalStorage(root, strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/"), []byte(signingKey))
+}
+
+// validateKey rejects keys that are empty, absolute, or not in clean form,
+// which covers any ".." that would escape the store
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return ErrInvalidKey
+	}
+	return nil
+}
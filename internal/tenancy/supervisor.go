@@ -0,0 +1,305 @@
+package tenancy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mostlyerror/lattice/internal/server"
+)
+
+// restartDelay is how long a tenant's server that exited waits to be started
+// again; it doubles with each exit in a row, up to maxRestartDelay
+const restartDelay = time.Second
+
+// maxRestartDelay caps restartDelay. A server that ran longer than this
+// before exiting is restarted after restartDelay again.
+const maxRestartDelay = time.Minute
+
+// Run serves the tenants listed in the file at path. It starts a server for
+// each tenant, listening on a Unix socket in TENANT_SOCKET_DIR (a temporary
+// directory by default), then listens as server.LoadConfig says and proxies
+// each request to the server of the tenant its host names. A tenant's server
+// that exits is started again. Run returns once the listener stops and every
+// tenant's server has shut down.
+func Run(path string) error {
+	tenants, err := LoadTenants(path)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := server.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("invalid server configuration: %w", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the server executable: %w", err)
+	}
+
+	socketDir := os.Getenv("TENANT_SOCKET_DIR")
+	if socketDir == "" {
+		if socketDir, err = os.MkdirTemp("", "lattice-tenants"); err != nil {
+			return fmt.Errorf("failed to create a socket directory: %w", err)
+		}
+		defer os.RemoveAll(socketDir)
+	} else if err := os.MkdirAll(socketDir, 0700); err != nil {
+		return fmt.Errorf("failed to create TENANT_SOCKET_DIR: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	servers := make([]*tenantServer, len(tenants))
+	routes := map[string]*httputil.ReverseProxy{}
+	for i, tenant := range tenants {
+		ts := &tenantServer{
+			tenant:     tenant,
+			executable: executable,
+			socket:     filepath.Join(socketDir, tenant.ID+".sock"),
+		}
+		servers[i] = ts
+
+		proxy := ts.proxy()
+		for _, host := range tenant.Hosts {
+			routes[host] = proxy
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ts.supervise(ctx)
+		}()
+	}
+	go forwardHangups(ctx, servers)
+
+	log.Printf("Starting Lattice tenant router for %d tenants on %s...", len(tenants), cfg.Addr())
+	err = server.ListenAndServe(cfg, hostRouter(routes), nil)
+
+	// Requests in flight have been proxied; now the tenants' servers can stop
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// tenantServer is the server process of one tenant
+type tenantServer struct {
+	tenant     Tenant
+	executable string
+	socket     string
+
+	mu      sync.Mutex
+	process *os.Process // nil while it isn't running
+}
+
+// supervise runs the tenant's server until ctx is done, starting it again
+// whenever it exits
+func (ts *tenantServer) supervise(ctx context.Context) {
+	delay := restartDelay
+	for {
+		started := time.Now()
+		err := ts.run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) > maxRestartDelay {
+			delay = restartDelay
+		}
+		log.Printf("Tenant %s server exited (%v); restarting in %s", ts.tenant.ID, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
+
+// run starts the tenant's server and waits for it to exit. Once ctx is done
+// it's sent SIGTERM, and given time to finish its requests.
+func (ts *tenantServer) run(ctx context.Context) error {
+	cmd := exec.Command(ts.executable)
+	cmd.Env = ts.env()
+	cmd.Stdout = &prefixWriter{prefix: "[" + ts.tenant.ID + "] ", out: os.Stdout}
+	cmd.Stderr = &prefixWriter{prefix: "[" + ts.tenant.ID + "] ", out: os.Stderr}
+	// In its own process group, so a Ctrl-C meant for the router doesn't stop
+	// it before the router has finished proxying to it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	ts.setProcess(cmd.Process)
+	defer ts.setProcess(nil)
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return err
+	case <-ctx.Done():
+		cmd.Process.Signal(syscall.SIGTERM)
+		return <-exited
+	}
+}
+
+// env is the deployment's environment as the tenant's server sees it: the
+// tenant's env in place of the deployment's, with its schema, its own
+// BILLING_TENANT, and its socket. TLS and socket activation are the router's,
+// so they're blanked rather than unset, or .env would set them again.
+func (ts *tenantServer) env() []string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		env[key] = value
+	}
+	for key, value := range ts.tenant.Env {
+		env[key] = value
+	}
+	for _, key := range supervisedEnv {
+		env[key] = ""
+	}
+	env["BILLING_TENANT"] = ts.tenant.ID
+	env["DB_SCHEMA"] = ts.tenant.Schema
+	env["UNIX_SOCKET"] = ts.socket
+	env["UNIX_SOCKET_MODE"] = "0600"
+
+	list := make([]string, 0, len(env))
+	for key, value := range env {
+		list = append(list, key+"="+value)
+	}
+	slices.Sort(list)
+	return list
+}
+
+// setProcess records the tenant's running server process, nil once it exits
+func (ts *tenantServer) setProcess(process *os.Process) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.process = process
+}
+
+// signal sends sig to the tenant's server, if it's running
+func (ts *tenantServer) signal(sig os.Signal) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.process == nil {
+		return
+	}
+	if err := ts.process.Signal(sig); err != nil {
+		log.Printf("Warning: failed to signal tenant %s server: %v", ts.tenant.ID, err)
+	}
+}
+
+// proxy returns the reverse proxy to the tenant's server's socket
+func (ts *tenantServer) proxy() *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(&url.URL{Scheme: "http", Host: ts.tenant.ID})
+			r.Out.Host = r.In.Host // So links it builds point at the tenant's host
+			// The tenant's server takes the client's address from the last
+			// entry, and trusts the ones before it as its TRUSTED_PROXIES say
+			r.Out.Header["X-Forwarded-For"] = r.In.Header["X-Forwarded-For"]
+			r.SetXForwarded()
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", ts.socket)
+			},
+		},
+		FlushInterval: -1, // Pass streamed responses on as they're written
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Error proxying to tenant %s: %v", ts.tenant.ID, err)
+			writeError(w, http.StatusBadGateway, "Tenant unavailable", err.Error())
+		},
+	}
+}
+
+// hostRouter routes each request to the proxy of the tenant serving its host
+func hostRouter(routes map[string]*httputil.ReverseProxy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.ToLower(r.Host)
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+
+		proxy, ok := routes[host]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Unknown tenant", "no tenant is served on host "+host)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// writeError answers with an error body as the API's handlers do
+func writeError(w http.ResponseWriter, status int, message, details string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "details": details})
+}
+
+// forwardHangups passes each SIGHUP on to every tenant's server, so they
+// reload their configuration, until ctx is done
+func forwardHangups(ctx context.Context, servers []*tenantServer) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+		}
+		for _, ts := range servers {
+			ts.signal(syscall.SIGHUP)
+		}
+	}
+}
+
+// prefixWriter writes each line written to it to out with a prefix, so each
+// tenant's log lines can be told apart
+type prefixWriter struct {
+	prefix  string
+	out     io.Writer
+	partial []byte // The start of a line not yet ended
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		end := bytes.IndexByte(w.partial, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.prefix, w.partial[:end+1]); err != nil {
+			return len(p), err
+		}
+		w.partial = w.partial[end+1:]
+	}
+}
@@ -0,0 +1,111 @@
+// Package tenancy serves several customer organizations from one deployment.
+// Each tenant gets its own server process with its own Postgres schema,
+// environment (settings, credentials, budgets, storage), and usage metering,
+// and a front process routes each request to its tenant's server by host
+// name. Tenants share no rows, so nothing in the data layer is tenant-aware.
+package tenancy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// tenantID is what a tenant's ID may be; it names its schema and socket
+var tenantID = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// schemaName is what a tenant's schema may be, as db.InitDB takes DB_SCHEMA
+var schemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// supervisedEnv are the variables the supervisor sets for each tenant's
+// server itself, which a tenant's env can't override
+var supervisedEnv = []string{
+	"BILLING_TENANT",
+	"DB_SCHEMA",
+	"HTTP2_CLEARTEXT",
+	"HTTP_REDIRECT_PORT",
+	"LISTEN_FDS",
+	"LISTEN_PID",
+	"TENANTS_FILE",
+	"TLS_AUTOCERT_DOMAINS",
+	"TLS_CERT_FILE",
+	"TLS_KEY_FILE",
+	"UNIX_SOCKET",
+	"UNIX_SOCKET_MODE",
+}
+
+// Tenant is one customer organization, as listed in TENANTS_FILE
+type Tenant struct {
+	ID     string            `json:"id"`               // Its usage is metered as BILLING_TENANT
+	Hosts  []string          `json:"hosts"`            // Host names its requests arrive on
+	Schema string            `json:"schema,omitempty"` // Postgres schema for its tables; tenant_<id> by default
+	Env    map[string]string `json:"env,omitempty"`    // Variables its server sees instead of the deployment's
+}
+
+// LoadTenants reads the tenants listed in the JSON file at path, an array of
+// tenants, and checks that no two share an ID, host, or schema
+func LoadTenants(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TENANTS_FILE: %w", err)
+	}
+
+	var tenants []Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("invalid TENANTS_FILE: %w", err)
+	}
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("TENANTS_FILE %s lists no tenants", path)
+	}
+
+	ids := map[string]bool{}
+	hosts := map[string]string{}
+	schemas := map[string]string{}
+	for i := range tenants {
+		tenant := &tenants[i]
+		if !tenantID.MatchString(tenant.ID) {
+			return nil, fmt.Errorf("invalid tenant ID %q: want lowercase letters, digits, and underscores", tenant.ID)
+		}
+		if ids[tenant.ID] {
+			return nil, fmt.Errorf("tenant %s is listed twice", tenant.ID)
+		}
+		ids[tenant.ID] = true
+
+		if len(tenant.Hosts) == 0 {
+			return nil, fmt.Errorf("tenant %s has no hosts", tenant.ID)
+		}
+		for j, host := range tenant.Hosts {
+			host = strings.ToLower(strings.TrimSpace(host))
+			if host == "" {
+				return nil, fmt.Errorf("tenant %s has an empty host", tenant.ID)
+			}
+			if other, taken := hosts[host]; taken {
+				return nil, fmt.Errorf("host %s is listed for tenants %s and %s", host, other, tenant.ID)
+			}
+			hosts[host] = tenant.ID
+			tenant.Hosts[j] = host
+		}
+
+		if tenant.Schema == "" {
+			tenant.Schema = "tenant_" + tenant.ID
+		}
+		if !schemaName.MatchString(tenant.Schema) || strings.HasPrefix(tenant.Schema, "pg_") {
+			return nil, fmt.Errorf("invalid schema %q for tenant %s", tenant.Schema, tenant.ID)
+		}
+		if other, taken := schemas[tenant.Schema]; taken {
+			return nil, fmt.Errorf("schema %s is listed for tenants %s and %s", tenant.Schema, other, tenant.ID)
+		}
+		schemas[tenant.Schema] = tenant.ID
+
+		for key := range tenant.Env {
+			if slices.Contains(supervisedEnv, key) {
+				return nil, fmt.Errorf("tenant %s can't set %s; the supervisor sets it", tenant.ID, key)
+			}
+		}
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,11 @@
+package billing
+
+import "errors"
+
+var (
+	// ErrNotConfigured is returned when a sink is missing its settings
+	ErrNotConfigured = errors.New("billing sink is not configured")
+
+	// ErrSinkError is returned when a sink rejects or fails to take events
+	ErrSinkError = errors.New("billing sink error")
+)
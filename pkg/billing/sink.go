@@ -0,0 +1,52 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sinks
+const (
+	Stripe  = "stripe"
+	Webhook = "webhook"
+)
+
+// DefaultTimeout bounds one request to a sink
+const DefaultTimeout = 30 * time.Second
+
+// Event is one metered use of the workspace, such as a processed source or
+// the tokens of a Claude response
+type Event struct {
+	Identifier      string    `json:"identifier"` // Unique per event, so sinks can drop redelivered events
+	Tenant          string    `json:"tenant"`
+	Metric          string    `json:"metric"`
+	Quantity        int64     `json:"quantity"`
+	SourceContentID *int      `json:"source_content_id,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Sink takes metered usage events for billing. Report returns how many of
+// events, from the first, the sink took, so a batch that fails partway is
+// resumed after them.
+type Sink interface {
+	Name() string
+	Report(ctx context.Context, events []Event) (int, error)
+}
+
+// NewSink creates the sink BILLING_SINK names: stripe (see NewStripeSink) or
+// webhook (see NewWebhookSink). It returns nil, nil when BILLING_SINK is unset.
+func NewSink() (Sink, error) {
+	switch sink := strings.ToLower(os.Getenv("BILLING_SINK")); sink {
+	case "":
+		return nil, nil
+	case Stripe:
+		return NewStripeSink()
+	case Webhook:
+		return NewWebhookSink()
+	default:
+		return nil, fmt.Errorf("unknown billing sink %q: must be stripe or webhook", sink)
+	}
+}
@@ -0,0 +1,120 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// StripeAPIURL is the Stripe API
+	StripeAPIURL = "https://api.stripe.com/v1"
+
+	// MeterEventsEndpoint records usage against a Stripe billing meter
+	MeterEventsEndpoint = "/billing/meter_events"
+)
+
+// StripeSink records usage events as Stripe meter events, for metered
+// billing of one Stripe customer
+type StripeSink struct {
+	apiKey     string
+	customerID string
+	eventNames map[string]string // Meter event name per metric; the metric itself when absent
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewStripeSink creates a sink from STRIPE_API_KEY, STRIPE_CUSTOMER_ID (the
+// customer billed for this workspace), and optionally STRIPE_METER_EVENTS,
+// which maps metrics to the event names of the Stripe meters they report
+// to, as metric=event_name pairs separated by commas. Unmapped metrics are
+// sent under their own names.
+func NewStripeSink() (*StripeSink, error) {
+	s := &StripeSink{
+		apiKey:     os.Getenv("STRIPE_API_KEY"),
+		customerID: os.Getenv("STRIPE_CUSTOMER_ID"),
+		eventNames: map[string]string{},
+		baseURL:    StripeAPIURL,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+	if s.apiKey == "" || s.customerID == "" {
+		return nil, fmt.Errorf("%w: set STRIPE_API_KEY and STRIPE_CUSTOMER_ID", ErrNotConfigured)
+	}
+
+	if mapping := os.Getenv("STRIPE_METER_EVENTS"); mapping != "" {
+		for _, pair := range strings.Split(mapping, ",") {
+			metric, name, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || metric == "" || name == "" {
+				return nil, fmt.Errorf("invalid STRIPE_METER_EVENTS: %q is not metric=event_name", pair)
+			}
+			s.eventNames[metric] = name
+		}
+	}
+
+	return s, nil
+}
+
+// Name identifies the sink
+func (s *StripeSink) Name() string {
+	return Stripe
+}
+
+// Report sends each event as a meter event, in order, stopping at the first
+// Stripe rejects
+func (s *StripeSink) Report(ctx context.Context, events []Event) (int, error) {
+	for i, event := range events {
+		name := s.eventNames[event.Metric]
+		if name == "" {
+			name = event.Metric
+		}
+
+		form := url.Values{
+			"event_name":                  {name},
+			"identifier":                  {event.Identifier},
+			"timestamp":                   {strconv.FormatInt(event.Timestamp.Unix(), 10)},
+			"payload[stripe_customer_id]": {s.customerID},
+			"payload[value]":              {strconv.FormatInt(event.Quantity, 10)},
+		}
+		if err := s.post(ctx, form); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// post creates one meter event
+func (s *StripeSink) post(ctx context.Context, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+MeterEventsEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSinkError, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	var stripeErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &stripeErr) == nil && stripeErr.Error.Message != "" {
+		return fmt.Errorf("%w: status %d: %s", ErrSinkError, resp.StatusCode, stripeErr.Error.Message)
+	}
+	return fmt.Errorf("%w: status %d", ErrSinkError, resp.StatusCode)
+}
@@ -0,0 +1,78 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// WebhookSink POSTs batches of usage events as JSON to a URL
+type WebhookSink struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// webhookPayload is the body a WebhookSink sends
+type webhookPayload struct {
+	Events []Event `json:"events"`
+}
+
+// NewWebhookSink creates a sink from BILLING_WEBHOOK_URL and, optionally,
+// BILLING_WEBHOOK_SECRET, which signs each body in X-Lattice-Signature as
+// its hex HMAC-SHA256
+func NewWebhookSink() (*WebhookSink, error) {
+	url := os.Getenv("BILLING_WEBHOOK_URL")
+	if url == "" {
+		return nil, fmt.Errorf("%w: set BILLING_WEBHOOK_URL", ErrNotConfigured)
+	}
+
+	return &WebhookSink{
+		url:        url,
+		secret:     os.Getenv("BILLING_WEBHOOK_SECRET"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}, nil
+}
+
+// Name identifies the sink
+func (s *WebhookSink) Name() string {
+	return Webhook
+}
+
+// Report POSTs events in one batch. Any 2xx status accepts them all.
+func (s *WebhookSink) Report(ctx context.Context, events []Event) (int, error) {
+	body, err := json.Marshal(webhookPayload{Events: events})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal usage events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set("X-Lattice-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrSinkError, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return 0, fmt.Errorf("%w: status %d: %s", ErrSinkError, resp.StatusCode, string(respBody))
+	}
+	return len(events), nil
+}
@@ -1,6 +1,7 @@
 package claude
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -29,6 +31,13 @@ const (
 
 	// DefaultTimeout is the default request timeout
 	DefaultTimeout = 60 * time.Second
+
+	// MinThinkingBudget is the smallest extended thinking budget the API accepts
+	MinThinkingBudget = 1024
+
+	// ThinkingTimeout is the request timeout with extended thinking, which
+	// responds more slowly
+	ThinkingTimeout = 5 * time.Minute
 )
 
 // Client handles Claude API interactions
@@ -37,6 +46,7 @@ type Client struct {
 	model      string
 	baseURL    string
 	httpClient *http.Client
+	thinking   int // Extended thinking budget in tokens, 0 for none
 }
 
 // Message represents a single message in the conversation
@@ -52,26 +62,50 @@ type MessageRequest struct {
 	Messages    []Message `json:"messages"`
 	System      string    `json:"system,omitempty"` // Optional system prompt
 	Temperature float64   `json:"temperature,omitempty"`
+	Thinking    *Thinking `json:"thinking,omitempty"` // Optional extended thinking
+	Stream      bool      `json:"stream,omitempty"`   // Set by StreamMessage
+}
+
+// Thinking enables extended thinking: Claude reasons, spending up to
+// BudgetTokens of the response's max tokens, before it answers
+type Thinking struct {
+	Type         string `json:"type"` // "enabled"
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 // MessageResponse represents a response from the Claude API
 type MessageResponse struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Role    string `json:"role"`
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Model        string `json:"model"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence,omitempty"`
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Role         string         `json:"role"`
+	Content      []ContentBlock `json:"content"`
+	Model        string         `json:"model"`
+	StopReason   string         `json:"stop_reason"`
+	StopSequence string         `json:"stop_sequence,omitempty"`
 	Usage        struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
 }
 
+// ContentBlock is a part of a response's content
+type ContentBlock struct {
+	Type     string `json:"type"` // "text", or "thinking" with extended thinking
+	Text     string `json:"text"`
+	Thinking string `json:"thinking,omitempty"`
+}
+
+// Text returns the response's text, leaving out any thinking
+func (r *MessageResponse) Text() string {
+	var text strings.Builder
+	for _, block := range r.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
 // ErrorResponse represents an error from the Claude API
 type ErrorResponse struct {
 	Type  string `json:"type"`
@@ -103,8 +137,136 @@ func NewClient() (*Client, error) {
 	}, nil
 }
 
+// WithModel returns a copy of the client that sends requests to a different model
+func (c *Client) WithModel(model string) *Client {
+	copied := *c
+	copied.model = model
+	return &copied
+}
+
+// Model returns the model requests are sent to
+func (c *Client) Model() string {
+	return c.model
+}
+
+// WithThinking returns a copy of the client whose requests use extended
+// thinking with a budget of budgetTokens (at least MinThinkingBudget), for
+// tasks where reasoning quality matters more than latency. Responses get that
+// many extra max tokens, and more time. A budget of 0 turns thinking off.
+func (c *Client) WithThinking(budgetTokens int) *Client {
+	copied := *c
+	copied.thinking = 0
+	copied.httpClient = &http.Client{Timeout: DefaultTimeout}
+	if budgetTokens > 0 {
+		copied.thinking = max(MinThinkingBudget, budgetTokens)
+		copied.httpClient = &http.Client{Timeout: ThinkingTimeout}
+	}
+	return &copied
+}
+
 // SendMessage sends a message to Claude and returns the response
 func (c *Client) SendMessage(ctx context.Context, req MessageRequest) (*MessageResponse, error) {
+	resp, err := c.post(ctx, c.prepare(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Parse success response
+	var msgResp MessageResponse
+	if err := json.Unmarshal(body, &msgResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &msgResp, nil
+}
+
+// StreamMessage sends a message to Claude, passing the response's text to
+// onText as it's generated, and returns the whole response once it ends. An
+// error from onText stops the stream.
+func (c *Client) StreamMessage(ctx context.Context, req MessageRequest, onText func(text string) error) (*MessageResponse, error) {
+	req = c.prepare(req)
+	req.Stream = true
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	msgResp := MessageResponse{Model: req.Model, Role: "assistant"}
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		var event struct {
+			Type    string           `json:"type"`
+			Message *MessageResponse `json:"message"`
+			Delta   struct {
+				Type       string `json:"type"`
+				Text       string `json:"text"`
+				StopReason string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage *struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				msgResp.ID = event.Message.ID
+				msgResp.Model = event.Message.Model
+				msgResp.Usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" {
+				continue // Thinking
+			}
+			text.WriteString(event.Delta.Text)
+			if err := onText(event.Delta.Text); err != nil {
+				return nil, err
+			}
+		case "message_delta":
+			msgResp.StopReason = event.Delta.StopReason
+			if event.Usage != nil {
+				msgResp.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			if event.Error != nil {
+				return nil, fmt.Errorf("%w: %s", ErrAPIError, event.Error.Message)
+			}
+			return nil, ErrAPIError
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	msgResp.Content = append(msgResp.Content, ContentBlock{Type: "text", Text: text.String()})
+
+	return &msgResp, nil
+}
+
+// prepare fills in a request's defaults and the client's extended thinking
+func (c *Client) prepare(req MessageRequest) MessageRequest {
 	// Set default model if not specified
 	if req.Model == "" {
 		req.Model = c.model
@@ -115,32 +277,59 @@ func (c *Client) SendMessage(ctx context.Context, req MessageRequest) (*MessageR
 		req.MaxTokens = DefaultMaxTokens
 	}
 
+	// Think before answering, on top of the answer's max tokens. Thinking
+	// requires the default temperature.
+	if req.Thinking == nil && c.thinking > 0 {
+		req.Thinking = &Thinking{Type: "enabled", BudgetTokens: c.thinking}
+		req.MaxTokens += c.thinking
+	}
+	if req.Thinking != nil {
+		req.Temperature = 0
+	}
+
+	// Thinking must leave room for the answer; skip it when there's too little
+	if req.Thinking != nil && req.Thinking.BudgetTokens >= req.MaxTokens {
+		if req.MaxTokens/2 >= MinThinkingBudget {
+			thinking := *req.Thinking
+			thinking.BudgetTokens = req.MaxTokens / 2
+			req.Thinking = &thinking
+		} else {
+			req.Thinking = nil
+		}
+	}
+
+	return req
+}
+
+// post sends a prepared request, retrying timeouts and rate limits, and
+// returns the successful response for the caller to read and close
+func (c *Client) post(ctx context.Context, req MessageRequest) (*http.Response, error) {
 	// Marshal request to JSON
 	reqBody, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		"POST",
-		c.baseURL+MessagesEndpoint,
-		bytes.NewReader(reqBody),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-api-key", c.apiKey)
-	httpReq.Header.Set("anthropic-version", AnthropicVersion)
-
 	// Send request with retry logic for rate limits
 	var resp *http.Response
 	maxRetries := 3
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		// Create HTTP request; each attempt needs its own body reader
+		httpReq, err := http.NewRequestWithContext(
+			ctx,
+			"POST",
+			c.baseURL+MessagesEndpoint,
+			bytes.NewReader(reqBody),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("x-api-key", c.apiKey)
+		httpReq.Header.Set("anthropic-version", AnthropicVersion)
+
 		resp, err = c.httpClient.Do(httpReq)
 		if err != nil {
 			if attempt == maxRetries-1 {
@@ -165,16 +354,15 @@ func (c *Client) SendMessage(ctx context.Context, req MessageRequest) (*MessageR
 		// Success or non-retryable error
 		break
 	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
 
 	// Check for error responses
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
 		var errResp ErrorResponse
 		if err := json.Unmarshal(body, &errResp); err != nil {
 			return nil, fmt.Errorf("%w: status %d, body: %s", ErrAPIError, resp.StatusCode, string(body))
@@ -182,108 +370,5 @@ func (c *Client) SendMessage(ctx context.Context, req MessageRequest) (*MessageR
 		return nil, fmt.Errorf("%w: %s", ErrAPIError, errResp.Error.Message)
 	}
 
-	// Parse success response
-	var msgResp MessageResponse
-	if err := json.Unmarshal(body, &msgResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Check if response is empty
-	if len(msgResp.Content) == 0 || msgResp.Content[0].Text == "" {
-		return nil, ErrEmptyResponse
-	}
-
-	return &msgResp, nil
-}
-
-// SendSimpleMessage is a helper to send a simple user message and get back Claude's response text
-func (c *Client) SendSimpleMessage(ctx context.Context, userMessage string) (string, error) {
-	req := MessageRequest{
-		Model:     c.model,
-		MaxTokens: DefaultMaxTokens,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
-	}
-
-	resp, err := c.SendMessage(ctx, req)
-	if err != nil {
-		return "", err
-	}
-
-	if len(resp.Content) == 0 {
-		return "", ErrEmptyResponse
-	}
-
-	return resp.Content[0].Text, nil
-}
-
-// SendMessageWithSystem sends a message with a system prompt
-func (c *Client) SendMessageWithSystem(ctx context.Context, systemPrompt, userMessage string) (string, error) {
-	req := MessageRequest{
-		Model:     c.model,
-		MaxTokens: DefaultMaxTokens,
-		System:    systemPrompt,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
-	}
-
-	resp, err := c.SendMessage(ctx, req)
-	if err != nil {
-		return "", err
-	}
-
-	if len(resp.Content) == 0 {
-		return "", ErrEmptyResponse
-	}
-
-	return resp.Content[0].Text, nil
-}
-
-// ParseJSONResponse is a helper to parse JSON from Claude's response
-func ParseJSONResponse(responseText string, target interface{}) error {
-	// Claude might wrap JSON in markdown code blocks, so let's handle that
-	text := responseText
-
-	// Try to extract JSON from markdown code blocks if present
-	if bytes.Contains([]byte(text), []byte("```json")) {
-		// Find JSON block
-		start := bytes.Index([]byte(text), []byte("```json"))
-		if start != -1 {
-			start += len("```json")
-			end := bytes.Index([]byte(text[start:]), []byte("```"))
-			if end != -1 {
-				text = text[start : start+end]
-			}
-		}
-	} else if bytes.Contains([]byte(text), []byte("```")) {
-		// Generic code block
-		start := bytes.Index([]byte(text), []byte("```"))
-		if start != -1 {
-			start += len("```")
-			// Skip language identifier if present
-			newlineIdx := bytes.Index([]byte(text[start:]), []byte("\n"))
-			if newlineIdx != -1 {
-				start += newlineIdx + 1
-			}
-			end := bytes.Index([]byte(text[start:]), []byte("```"))
-			if end != -1 {
-				text = text[start : start+end]
-			}
-		}
-	}
-
-	// Parse JSON
-	if err := json.Unmarshal([]byte(text), target); err != nil {
-		return fmt.Errorf("failed to parse JSON response: %w", err)
-	}
-
-	return nil
+	return resp, nil
 }
@@ -17,7 +17,4 @@ var (
 
 	// ErrTimeout is returned when request times out
 	ErrTimeout = errors.New("Claude API request timeout")
-
-	// ErrEmptyResponse is returned when Claude returns empty content
-	ErrEmptyResponse = errors.New("Claude returned empty response")
 )
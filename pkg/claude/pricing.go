@@ -0,0 +1,41 @@
+package claude
+
+import "strings"
+
+// Price is a model's list price in US dollars per million tokens
+type Price struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// modelPrices are list prices by model name prefix, most specific first, so
+// dated snapshots share their family's price
+var modelPrices = []struct {
+	prefix string
+	price  Price
+}{
+	{"claude-opus-4-5", Price{5, 25}},
+	{"claude-opus-4", Price{15, 75}},
+	{"claude-sonnet-4", Price{3, 15}},
+	{"claude-haiku-4-5", Price{1, 5}},
+	{"claude-3-7-sonnet", Price{3, 15}},
+	{"claude-3-5-sonnet", Price{3, 15}},
+	{"claude-3-5-haiku", Price{0.8, 4}},
+	{"claude-3-opus", Price{15, 75}},
+	{"claude-3-haiku", Price{0.25, 1.25}},
+}
+
+// PriceOf returns the list price of model, and false for models it doesn't know
+func PriceOf(model string) (Price, bool) {
+	for _, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost returns what inputTokens and outputTokens cost at p, in US dollars
+func (p Price) Cost(inputTokens, outputTokens int64) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1e6
+}
@@ -0,0 +1,177 @@
+package decks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqliteTimeout bounds each query of an Anki collection
+const sqliteTimeout = time.Minute
+
+// ankiClozePattern matches a cloze deletion: {{c1::answer}} or {{c1::answer::hint}}
+var ankiClozePattern = regexp.MustCompile(`(?s)\{\{c(\d+)::(.*?)(?:::(.*?))?\}\}`)
+
+// ankiNoteType is a note type from a collection's models
+type ankiNoteType struct {
+	Name string `json:"name"`
+	Type int    `json:"type"` // 1 for cloze note types
+}
+
+// ankiDeck is a deck from a collection's decks
+type ankiDeck struct {
+	Name string `json:"name"` // Nested decks are separated by "::"
+}
+
+// ankiNote is a row of a collection's notes, with the deck of its first card
+type ankiNote struct {
+	ID     int64  `json:"id"`
+	TypeID int64  `json:"mid"`
+	Fields string `json:"flds"` // Separated by \x1f
+	Tags   string `json:"tags"` // Separated by spaces
+	DeckID int64  `json:"did"`
+}
+
+// ParseAnki reads the notes of an Anki package (.apkg) as cards: a basic
+// note's first field is the front and its second the back, and each cloze
+// number of a cloze note makes a card. Fields are reduced to plain text,
+// without media. The collection is read with the sqlite3 command, from
+// SQLITE3_PATH or the PATH.
+func ParseAnki(ctx context.Context, data []byte) ([]Card, error) {
+	files, err := readArchive(data, ".anki2", ".anki21", ".anki21b")
+	if err != nil {
+		return nil, err
+	}
+	collections := map[string][]byte{}
+	for _, f := range files {
+		collections[f.name] = f.data
+	}
+
+	// Packages from newer versions hold a compressed collection, with a stub
+	// collection.anki2 asking to upgrade
+	collection, ok := collections["collection.anki21"]
+	if !ok {
+		if _, newer := collections["collection.anki21b"]; newer {
+			return nil, ErrUnsupportedAnkiVersion
+		}
+		if collection, ok = collections["collection.anki2"]; !ok {
+			return nil, fmt.Errorf("%w: no Anki collection in the package", ErrInvalidExport)
+		}
+	}
+
+	sqlite := os.Getenv("SQLITE3_PATH")
+	if sqlite == "" {
+		sqlite = "sqlite3"
+	}
+	if sqlite, err = exec.LookPath(sqlite); err != nil {
+		return nil, ErrSQLiteNotFound
+	}
+
+	dir, err := os.MkdirTemp("", "lattice-anki-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "collection.sqlite")
+	if err := os.WriteFile(path, collection, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write collection: %w", err)
+	}
+
+	var col []struct {
+		Models string `json:"models"`
+		Decks  string `json:"decks"`
+	}
+	if err := querySQLite(ctx, sqlite, path, "SELECT models, decks FROM col", &col); err != nil {
+		return nil, err
+	}
+	if len(col) == 0 {
+		return nil, fmt.Errorf("%w: the collection has no configuration", ErrInvalidExport)
+	}
+	var noteTypes map[string]ankiNoteType
+	var decks map[string]ankiDeck
+	if err := json.Unmarshal([]byte(col[0].Models), &noteTypes); err != nil {
+		return nil, fmt.Errorf("%w: unreadable note types: %v", ErrInvalidExport, err)
+	}
+	if err := json.Unmarshal([]byte(col[0].Decks), &decks); err != nil {
+		return nil, fmt.Errorf("%w: unreadable decks: %v", ErrInvalidExport, err)
+	}
+
+	var notes []ankiNote
+	query := `SELECT n.id, n.mid, n.flds, n.tags,
+		COALESCE((SELECT c.did FROM cards c WHERE c.nid = n.id ORDER BY c.ord LIMIT 1), 0) AS did
+		FROM notes n ORDER BY n.id`
+	if err := querySQLite(ctx, sqlite, path, query, &notes); err != nil {
+		return nil, err
+	}
+
+	var cards []Card
+	for _, note := range notes {
+		deck := decks[strconv.FormatInt(note.DeckID, 10)].Name
+		tags := strings.Fields(note.Tags)
+		fields := strings.Split(note.Fields, "\x1f")
+
+		var noteCards []Card
+		if noteTypes[strconv.FormatInt(note.TypeID, 10)].Type == 1 {
+			noteCards = ankiClozeCards(htmlToText(fields[0]))
+		} else if len(fields) >= 2 {
+			noteCards = []Card{{Front: htmlToText(fields[0]), Back: htmlToText(fields[1])}}
+		}
+
+		for _, card := range noteCards {
+			if card.Front == "" || card.Back == "" {
+				continue
+			}
+			card.Deck, card.Tags, card.AnkiNoteID = deck, tags, note.ID
+			cards = append(cards, card)
+		}
+	}
+
+	if len(cards) == 0 {
+		return nil, ErrNoCards
+	}
+	return cards, nil
+}
+
+// ankiClozeCards makes a card for each cloze number in text
+func ankiClozeCards(text string) []Card {
+	var deletions []cloze
+	for _, m := range ankiClozePattern.FindAllStringSubmatchIndex(text, -1) {
+		d := cloze{start: m[0], end: m[1], group: text[m[2]:m[3]], answer: text[m[4]:m[5]]}
+		if m[6] >= 0 {
+			d.hint = text[m[6]:m[7]]
+		}
+		deletions = append(deletions, d)
+	}
+	return clozeCards(text, deletions)
+}
+
+// querySQLite runs a query on the database at path, decoding its rows into rows
+func querySQLite(ctx context.Context, sqlite, path, query string, rows interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, sqliteTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, sqlite, "-readonly", "-json", path, query)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: sqlite3: %v: %s", ErrInvalidExport, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// No rows print nothing
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		out = []byte("[]")
+	}
+	if err := json.Unmarshal(out, rows); err != nil {
+		return fmt.Errorf("failed to read sqlite3 output: %w", err)
+	}
+	return nil
+}
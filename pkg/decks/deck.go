@@ -0,0 +1,150 @@
+// Package decks reads flashcards from the exports of other study tools:
+// Anki packages, and Logseq and RemNote graphs.
+package decks
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Card is a front/back flashcard
+type Card struct {
+	Front string   `json:"front"`
+	Back  string   `json:"back"`
+	Deck  string   `json:"deck,omitempty"` // Deck or page the card came from
+	Tags  []string `json:"tags,omitempty"`
+
+	AnkiNoteID int64 `json:"anki_note_id,omitempty"` // The Anki note the card came from
+}
+
+// maxArchiveFile bounds each file read from an export archive, in bytes
+const maxArchiveFile = 50 << 20
+
+var (
+	breakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(div|p|li|tr|h[1-6])>`)
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	mediaPattern = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+	spacePattern = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlToText reduces an HTML field to plain text, keeping line breaks and
+// dropping media
+func htmlToText(s string) string {
+	s = mediaPattern.ReplaceAllString(s, "")
+	s = breakPattern.ReplaceAllString(s, "\n")
+	s = tagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	return cleanText(s)
+}
+
+// cleanText collapses runs of spaces and blank lines and trims each line
+func cleanText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// cloze is one deletion in a cloze text
+type cloze struct {
+	start, end int    // Byte range of the whole deletion in the text
+	group      string // Deletions sharing a group are hidden together
+	answer     string
+	hint       string
+}
+
+// clozeCards makes a card for each group of deletions in text: the text with
+// that group hidden as [...] (or [hint]) and the others shown, answered by
+// the hidden text
+func clozeCards(text string, deletions []cloze) []Card {
+	var groups []string
+	seen := map[string]bool{}
+	for _, d := range deletions {
+		if !seen[d.group] {
+			seen[d.group] = true
+			groups = append(groups, d.group)
+		}
+	}
+
+	cards := make([]Card, 0, len(groups))
+	for _, group := range groups {
+		var front strings.Builder
+		var answers []string
+		last := 0
+		for _, d := range deletions {
+			front.WriteString(text[last:d.start])
+			if d.group != group {
+				front.WriteString(d.answer)
+			} else {
+				placeholder := "..."
+				if d.hint != "" {
+					placeholder = d.hint
+				}
+				front.WriteString("[" + placeholder + "]")
+				answers = append(answers, d.answer)
+			}
+			last = d.end
+		}
+		front.WriteString(text[last:])
+		cards = append(cards, Card{Front: cleanText(front.String()), Back: strings.Join(answers, "; ")})
+	}
+	return cards
+}
+
+// archiveFile is a file read from an export archive
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// readArchive returns the files of a ZIP archive whose names end in one of
+// exts, in archive order
+func readArchive(data []byte, exts ...string) ([]archiveFile, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	var files []archiveFile
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(path.Base(f.Name), ".") || !hasExt(f.Name, exts) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(r, maxArchiveFile))
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		files = append(files, archiveFile{name: f.Name, data: content})
+	}
+	return files, nil
+}
+
+// hasExt reports whether name ends in one of exts, ignoring case
+func hasExt(name string, exts []string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// isZip reports whether data starts like a ZIP archive
+func isZip(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
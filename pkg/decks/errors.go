@@ -0,0 +1,18 @@
+package decks
+
+import "errors"
+
+var (
+	// ErrNoCards is returned when an export holds no flashcards
+	ErrNoCards = errors.New("no flashcards found in the export")
+
+	// ErrInvalidExport is returned when a file isn't an export in the expected format
+	ErrInvalidExport = errors.New("file is not a valid export")
+
+	// ErrUnsupportedAnkiVersion is returned for .apkg files only newer Anki
+	// versions can read
+	ErrUnsupportedAnkiVersion = errors.New("this .apkg uses a newer Anki format - export again with \"Support older Anki versions\" checked")
+
+	// ErrSQLiteNotFound is returned when the sqlite3 command isn't installed
+	ErrSQLiteNotFound = errors.New("reading Anki decks needs sqlite3 - install it or set SQLITE3_PATH")
+)
@@ -0,0 +1,191 @@
+package decks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// logseqCardTag matches the tag marking a block as a card
+	logseqCardTag = regexp.MustCompile(`(?i)(^|\s)#(card|\[\[card\]\])(\s|$)|\[\[card\]\]`)
+
+	// logseqClozePattern matches a cloze deletion: {{cloze answer}}
+	logseqClozePattern = regexp.MustCompile(`(?s)\{\{cloze\s+(.*?)\}\}`)
+
+	// logseqPropertyLine matches a block property such as "card-ease:: 2.5"
+	logseqPropertyLine = regexp.MustCompile(`^[A-Za-z0-9_.-]+:: ?(.*)$`)
+
+	// logseqCardMarker matches the card tag, to remove it from a card's text
+	logseqCardMarker = regexp.MustCompile(`(?i)#?\[\[card\]\]|#card\b`)
+
+	// logseqTagPattern matches #tag and #[[multi word tag]]
+	logseqTagPattern = regexp.MustCompile(`(^|\s)#(\[\[([^\]]+)\]\]|[^\s#\[\],]+)`)
+
+	// logseqTrailingTags matches the tags ending a line
+	logseqTrailingTags = regexp.MustCompile(`(?m)(\s*#(\[\[[^\]]+\]\]|[^\s#\[\],]+))+[ \t]*$`)
+
+	// logseqLinkPattern matches a [[page link]]
+	logseqLinkPattern = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+)
+
+// logseqJSONBlock is a page or block of a Logseq JSON export
+type logseqJSONBlock struct {
+	PageName string            `json:"page-name"` // Set on pages
+	Content  string            `json:"content"`   // Set on blocks
+	Children []logseqJSONBlock `json:"children"`
+}
+
+// ParseLogseq reads the cards in a Logseq graph, exported as a page's
+// Markdown file, a ZIP of the graph's pages and journals, or the graph's
+// JSON export. Blocks tagged #card are cards: the block is the front and the
+// blocks nested under it the back. A card with {{cloze ...}} deletions makes
+// a card for each deletion instead. The page is each card's deck, and other
+// tags on the block are its tags.
+func ParseLogseq(filename string, data []byte) ([]Card, error) {
+	var cards []Card
+	switch {
+	case isZip(data):
+		files, err := readArchive(data, ".md")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			cards = append(cards, logseqPageCards(logseqPageName(f.name), parseOutline(string(f.data)))...)
+		}
+	case strings.EqualFold(path.Ext(filename), ".json"):
+		var export struct {
+			Blocks []logseqJSONBlock `json:"blocks"`
+		}
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		for _, page := range export.Blocks {
+			cards = append(cards, logseqPageCards(page.PageName, logseqJSONBlocks(page.Children))...)
+		}
+	default:
+		cards = logseqPageCards(logseqPageName(filename), parseOutline(string(data)))
+	}
+
+	if len(cards) == 0 {
+		return nil, ErrNoCards
+	}
+	return cards, nil
+}
+
+// logseqJSONBlocks converts the blocks of a JSON export to an outline
+func logseqJSONBlocks(blocks []logseqJSONBlock) []*block {
+	converted := make([]*block, 0, len(blocks))
+	for _, b := range blocks {
+		converted = append(converted, &block{text: strings.TrimSpace(b.Content), children: logseqJSONBlocks(b.Children)})
+	}
+	return converted
+}
+
+// logseqPageName recovers a page's name from its file name, in which
+// namespaces are separated by "___" and other characters URL-encoded
+func logseqPageName(filename string) string {
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	name = strings.ReplaceAll(name, "___", "/")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+// logseqPageCards returns the cards among a page's blocks, at any depth. A
+// title:: property before the first block renames the page.
+func logseqPageCards(page string, blocks []*block) []Card {
+	for _, b := range blocks {
+		if strings.HasPrefix(b.text, "title::") {
+			page = strings.TrimSpace(strings.TrimPrefix(b.text, "title::"))
+			break
+		}
+	}
+
+	var cards []Card
+	var walk func(blocks []*block)
+	walk = func(blocks []*block) {
+		for _, b := range blocks {
+			if !logseqCardTag.MatchString(b.text) {
+				walk(b.children)
+				continue
+			}
+
+			text, tags := logseqText(b.text)
+			var blockCards []Card
+			if logseqClozePattern.MatchString(text) {
+				blockCards = logseqClozeCards(text)
+			} else {
+				blockCards = []Card{{Front: text, Back: logseqChildrenText(b.children)}}
+			}
+			for _, card := range blockCards {
+				if card.Front == "" || card.Back == "" {
+					continue
+				}
+				card.Deck, card.Tags = page, tags
+				cards = append(cards, card)
+			}
+		}
+	}
+	walk(blocks)
+	return cards
+}
+
+// logseqChildrenText renders the blocks nested under a card as its back
+func logseqChildrenText(children []*block) string {
+	var cleaned func(blocks []*block) []*block
+	cleaned = func(blocks []*block) []*block {
+		out := make([]*block, 0, len(blocks))
+		for _, b := range blocks {
+			text, _ := logseqText(b.text)
+			out = append(out, &block{text: text, children: cleaned(b.children)})
+		}
+		return out
+	}
+	return outlineText(cleaned(children), 0)
+}
+
+// logseqText removes a block's properties, card tag, and link brackets,
+// returning its text and tags
+func logseqText(text string) (string, []string) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !logseqPropertyLine.MatchString(strings.TrimSpace(line)) {
+			lines = append(lines, line)
+		}
+	}
+	text = strings.Join(lines, "\n")
+
+	text = logseqCardMarker.ReplaceAllString(text, "")
+
+	var tags []string
+	for _, m := range logseqTagPattern.FindAllStringSubmatch(text, -1) {
+		tag := m[2]
+		if m[3] != "" {
+			tag = m[3]
+		}
+		tags = append(tags, tag)
+	}
+
+	// Tags ending a line only tag it; others are read as words
+	text = logseqTrailingTags.ReplaceAllString(text, "")
+	text = logseqTagPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Replace(strings.Replace(strings.Replace(match, "#", "", 1), "[[", "", 1), "]]", "", 1)
+	})
+	text = logseqLinkPattern.ReplaceAllString(text, "$1")
+
+	return cleanText(text), tags
+}
+
+// logseqClozeCards makes a card for each cloze deletion in text
+func logseqClozeCards(text string) []Card {
+	var deletions []cloze
+	for i, m := range logseqClozePattern.FindAllStringSubmatchIndex(text, -1) {
+		deletions = append(deletions, cloze{start: m[0], end: m[1], group: fmt.Sprint(i), answer: strings.TrimSpace(text[m[2]:m[3]])})
+	}
+	return clozeCards(text, deletions)
+}
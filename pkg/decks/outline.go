@@ -0,0 +1,99 @@
+package decks
+
+import "strings"
+
+// block is a bullet of an outline, with the bullets nested under it
+type block struct {
+	text     string
+	children []*block
+}
+
+// outlineText renders blocks and their children as indented "- " lines, or
+// a lone block without children as its text
+func outlineText(blocks []*block, depth int) string {
+	if depth == 0 && len(blocks) == 1 && len(blocks[0].children) == 0 {
+		return blocks[0].text
+	}
+
+	var lines []string
+	for _, b := range blocks {
+		if b.text != "" {
+			lines = append(lines, strings.Repeat("  ", depth)+"- "+strings.ReplaceAll(b.text, "\n", " "))
+		}
+		if nested := outlineText(b.children, depth+1); nested != "" {
+			lines = append(lines, nested)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseOutline reads a Markdown outline of "- " bullets, nested by
+// indentation. Lines that aren't bullets continue the bullet above them;
+// before the first bullet, each is a block of its own.
+func parseOutline(text string) []*block {
+	type open struct {
+		indent int
+		block  *block
+	}
+	var roots []*block
+	var stack []open
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		indent := indentWidth(line[:len(line)-len(trimmed)])
+
+		content, isBullet := bulletContent(trimmed)
+		if !isBullet {
+			if len(stack) == 0 {
+				roots = append(roots, &block{text: strings.TrimSpace(trimmed)})
+				continue
+			}
+			top := stack[len(stack)-1].block
+			top.text = strings.TrimSpace(top.text + "\n" + strings.TrimSpace(trimmed))
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		b := &block{text: content}
+		if len(stack) == 0 {
+			roots = append(roots, b)
+		} else {
+			parent := stack[len(stack)-1].block
+			parent.children = append(parent.children, b)
+		}
+		stack = append(stack, open{indent: indent, block: b})
+	}
+
+	return roots
+}
+
+// bulletContent returns a bullet line's text without its marker
+func bulletContent(line string) (string, bool) {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(line, marker) {
+			return strings.TrimSpace(line[len(marker):]), true
+		}
+	}
+	if strings.TrimSpace(line) == "-" {
+		return "", true
+	}
+	return "", false
+}
+
+// indentWidth measures leading whitespace, a tab counting as four spaces
+func indentWidth(whitespace string) int {
+	width := 0
+	for _, r := range whitespace {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,100 @@
+package decks
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// remNoteSeparators are RemNote's flashcard markers: >> forward, << backward
+// (the back is asked), <> both ways, :: concepts, and ;; descriptors.
+// ">>>" and the others ending a rem make a multi-line card, answered by the
+// rems nested under it.
+var remNoteSeparators = []string{">>>", ">>", "<<", "<>", "::", ";;"}
+
+// remNoteClozePattern matches a cloze deletion: {{answer}}
+var remNoteClozePattern = regexp.MustCompile(`(?s)\{\{(.*?)\}\}`)
+
+// ParseRemNote reads the cards in a RemNote Markdown export, a document's
+// file or a ZIP of them. Rems with a flashcard marker are cards, each asked
+// in its forward direction only, and a rem with {{cloze}} deletions makes a
+// card for each deletion. The document is each card's deck.
+func ParseRemNote(filename string, data []byte) ([]Card, error) {
+	var cards []Card
+	if isZip(data) {
+		files, err := readArchive(data, ".md")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			cards = append(cards, remNoteCards(remNoteDocumentName(f.name), parseOutline(string(f.data)))...)
+		}
+	} else {
+		cards = remNoteCards(remNoteDocumentName(filename), parseOutline(string(data)))
+	}
+
+	if len(cards) == 0 {
+		return nil, ErrNoCards
+	}
+	return cards, nil
+}
+
+// remNoteDocumentName returns the document a file holds: its path within the
+// export, without the extension
+func remNoteDocumentName(filename string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path.Clean("/"+filename), "/"), path.Ext(filename))
+}
+
+// remNoteCards returns the cards among a document's rems, at any depth
+func remNoteCards(document string, rems []*block) []Card {
+	var cards []Card
+	var walk func(rems []*block)
+	walk = func(rems []*block) {
+		for _, rem := range rems {
+			for _, card := range remNoteRemCards(rem) {
+				if card.Front == "" || card.Back == "" {
+					continue
+				}
+				card.Deck = document
+				cards = append(cards, card)
+			}
+			walk(rem.children)
+		}
+	}
+	walk(rems)
+	return cards
+}
+
+// remNoteRemCards returns the cards a rem makes, if any
+func remNoteRemCards(rem *block) []Card {
+	text := cleanText(rem.text)
+	if remNoteClozePattern.MatchString(text) {
+		var deletions []cloze
+		for i, m := range remNoteClozePattern.FindAllStringSubmatchIndex(text, -1) {
+			deletions = append(deletions, cloze{start: m[0], end: m[1], group: fmt.Sprint(i), answer: strings.TrimSpace(text[m[2]:m[3]])})
+		}
+		return clozeCards(text, deletions)
+	}
+
+	// The first marker in the rem splits it
+	at, separator := -1, ""
+	for _, s := range remNoteSeparators {
+		if i := strings.Index(text, s); i >= 0 && (at < 0 || i < at) {
+			at, separator = i, s
+		}
+	}
+	if at < 0 {
+		return nil
+	}
+
+	front := strings.TrimSpace(text[:at])
+	back := strings.TrimSpace(text[at+len(separator):])
+	if back == "" {
+		back = outlineText(rem.children, 0)
+	}
+	if separator == "<<" {
+		front, back = back, front
+	}
+	return []Card{{Front: front, Back: back}}
+}
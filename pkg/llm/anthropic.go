@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mostlyerror/lattice/pkg/claude"
+)
+
+// anthropicProvider sends requests to Claude through pkg/claude
+type anthropicProvider struct {
+	client *claude.Client
+}
+
+// newAnthropicProvider creates a provider from CLAUDE_API_KEY
+func newAnthropicProvider() (Provider, error) {
+	client, err := claude.NewClient()
+	if errors.Is(err, claude.ErrAPIKeyMissing) {
+		return nil, fmt.Errorf("%w: CLAUDE_API_KEY", ErrAPIKeyMissing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &anthropicProvider{client: client}, nil
+}
+
+// Name implements Provider
+func (p *anthropicProvider) Name() string {
+	return Anthropic
+}
+
+// Complete implements Provider
+func (p *anthropicProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	client, msgReq := p.request(req)
+	resp, err := client.SendMessage(ctx, msgReq)
+	if err != nil {
+		return nil, err
+	}
+	return anthropicResponse(resp), nil
+}
+
+// CompleteJSON implements Provider. Claude has no JSON mode; it follows the
+// prompt's format instructions.
+func (p *anthropicProvider) CompleteJSON(ctx context.Context, req Request) (*Response, error) {
+	return p.Complete(ctx, req)
+}
+
+// Stream implements Provider
+func (p *anthropicProvider) Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error) {
+	client, msgReq := p.request(req)
+	resp, err := client.StreamMessage(ctx, msgReq, onText)
+	if err != nil {
+		return nil, err
+	}
+	return anthropicResponse(resp), nil
+}
+
+// request converts req to a Claude request, on a client with time for its
+// extended thinking
+func (p *anthropicProvider) request(req Request) (*claude.Client, claude.MessageRequest) {
+	msgReq := claude.MessageRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		System:      req.System,
+		Temperature: req.Temperature,
+		Messages:    make([]claude.Message, 0, len(req.Messages)),
+	}
+	for _, m := range req.Messages {
+		msgReq.Messages = append(msgReq.Messages, claude.Message{Role: m.Role, Content: m.Content})
+	}
+
+	if req.Thinking == 0 {
+		return p.client, msgReq
+	}
+	msgReq.Thinking = &claude.Thinking{Type: "enabled", BudgetTokens: req.Thinking}
+	return p.client.WithThinking(req.Thinking), msgReq
+}
+
+// anthropicResponse converts a Claude response
+func anthropicResponse(resp *claude.MessageResponse) *Response {
+	return &Response{
+		Text:         resp.Text(),
+		Model:        resp.Model,
+		InputTokens:  resp.Usage.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
+	}
+}
@@ -0,0 +1,37 @@
+package llm
+
+import "sync"
+
+// TokenBudget caps the tokens (input plus output) a client may use across
+// requests. It's safe for concurrent use.
+type TokenBudget struct {
+	mu    sync.Mutex
+	limit int
+	used  int
+}
+
+// NewTokenBudget creates a budget of limit tokens
+func NewTokenBudget(limit int) *TokenBudget {
+	return &TokenBudget{limit: limit}
+}
+
+// Used returns the tokens spent so far
+func (b *TokenBudget) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// Remaining returns the tokens left, never negative
+func (b *TokenBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return max(0, b.limit-b.used)
+}
+
+// spend records tokens used by a response
+func (b *TokenBudget) spend(tokens int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used += tokens
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"slices"
+)
+
+// MinThinkingBudget is the smallest extended thinking budget Claude accepts
+const MinThinkingBudget = 1024
+
+// Client sends requests to a provider on a model, drawing on optional token
+// budgets and reporting each response's usage
+type Client struct {
+	provider Provider
+	model    string
+	budgets  []*TokenBudget // Optional caps on tokens across requests
+	thinking int            // Extended thinking budget in tokens, 0 for none
+	onUsage  UsageHook      // Optional; told the tokens of each response
+}
+
+// UsageHook is called with the model and tokens of each successful response,
+// for metering
+type UsageHook func(model string, inputTokens, outputTokens int)
+
+// NewClient creates a client for the provider configured by LLM_PROVIDER,
+// sending requests to its DefaultModel
+func NewClient() (*Client, error) {
+	provider, err := NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{provider: provider, model: DefaultModel()}, nil
+}
+
+// Provider returns the name of the provider requests are sent to
+func (c *Client) Provider() string {
+	return c.provider.Name()
+}
+
+// WithModel returns a copy of the client that sends requests to a different model
+func (c *Client) WithModel(model string) *Client {
+	copied := *c
+	copied.model = model
+	return &copied
+}
+
+// Model returns the model requests are sent to
+func (c *Client) Model() string {
+	return c.model
+}
+
+// WithTokenBudget returns a copy of the client whose requests draw on budget,
+// as well as on any budget the client already has. Once one is spent,
+// requests fail with ErrTokenBudgetExceeded.
+func (c *Client) WithTokenBudget(budget *TokenBudget) *Client {
+	copied := *c
+	copied.budgets = append(slices.Clip(c.budgets), budget)
+	return &copied
+}
+
+// WithUsageHook returns a copy of the client that calls hook after each
+// successful response. Copies made from it keep the hook.
+func (c *Client) WithUsageHook(hook UsageHook) *Client {
+	copied := *c
+	copied.onUsage = hook
+	return &copied
+}
+
+// WithThinking returns a copy of the client whose requests use extended
+// thinking with a budget of budgetTokens (at least MinThinkingBudget), for
+// tasks where reasoning quality matters more than latency. Responses get that
+// many extra max tokens. Providers without extended thinking answer as usual.
+// A budget of 0 turns thinking off.
+func (c *Client) WithThinking(budgetTokens int) *Client {
+	copied := *c
+	copied.thinking = 0
+	if budgetTokens > 0 {
+		copied.thinking = max(MinThinkingBudget, budgetTokens)
+	}
+	return &copied
+}
+
+// Complete sends a request and returns the response
+func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
+	return c.send(ctx, req, c.provider.Complete)
+}
+
+// CompleteJSON sends a request whose prompt asks for JSON and returns the
+// response; parse its text with ParseJSONResponse
+func (c *Client) CompleteJSON(ctx context.Context, req Request) (*Response, error) {
+	return c.send(ctx, req, c.provider.CompleteJSON)
+}
+
+// Stream sends a request, passing the response's text to onText as it's
+// generated, and returns the whole response once it ends
+func (c *Client) Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error) {
+	return c.send(ctx, req, func(ctx context.Context, req Request) (*Response, error) {
+		return c.provider.Stream(ctx, req, onText)
+	})
+}
+
+// SendMessageWithSystem sends a message with a system prompt and returns the
+// response's text
+func (c *Client) SendMessageWithSystem(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	resp, err := c.Complete(ctx, Request{System: systemPrompt, Messages: []Message{{Role: RoleUser, Content: userMessage}}})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// SendJSONMessageWithSystem is SendMessageWithSystem for prompts asking for JSON
+func (c *Client) SendJSONMessageWithSystem(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	resp, err := c.CompleteJSON(ctx, Request{System: systemPrompt, Messages: []Message{{Role: RoleUser, Content: userMessage}}})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// SendConversation sends a multi-turn conversation with a system prompt and
+// returns the response's text. Messages must alternate roles and end with a
+// user message.
+func (c *Client) SendConversation(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	resp, err := c.Complete(ctx, Request{System: systemPrompt, Messages: messages})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// send fills in a request's defaults, keeps it within the budget, sends it
+// with complete, and records the response's usage
+func (c *Client) send(ctx context.Context, req Request, complete func(context.Context, Request) (*Response, error)) (*Response, error) {
+	if req.Model == "" {
+		req.Model = c.model
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = DefaultMaxTokens
+	}
+
+	// Think before answering, on top of the answer's max tokens
+	if req.Thinking == 0 && c.thinking > 0 {
+		req.Thinking = c.thinking
+		req.MaxTokens += c.thinking
+	}
+
+	// Keep the response within the remaining budgets
+	for _, budget := range c.budgets {
+		remaining := budget.Remaining()
+		if remaining == 0 {
+			return nil, ErrTokenBudgetExceeded
+		}
+		req.MaxTokens = min(req.MaxTokens, remaining)
+	}
+
+	// Thinking must leave room for the answer; skip it when there's too little
+	if req.Thinking >= req.MaxTokens {
+		req.Thinking = 0
+		if req.MaxTokens/2 >= MinThinkingBudget {
+			req.Thinking = req.MaxTokens / 2
+		}
+	}
+
+	resp, err := complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, budget := range c.budgets {
+		budget.spend(resp.InputTokens + resp.OutputTokens)
+	}
+	if c.onUsage != nil {
+		c.onUsage(req.Model, resp.InputTokens, resp.OutputTokens)
+	}
+
+	// Check if response is empty
+	if resp.Text == "" {
+		return nil, ErrEmptyResponse
+	}
+
+	return resp, nil
+}
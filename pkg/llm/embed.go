@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Embedding model defaults
+const (
+	openAIDefaultEmbeddingModel = "text-embedding-3-small"
+	geminiDefaultEmbeddingModel = "gemini-embedding-001"
+	ollamaDefaultEmbeddingModel = "nomic-embed-text"
+)
+
+// EmbedRequest asks for the embeddings of texts
+type EmbedRequest struct {
+	Model      string
+	Texts      []string
+	Query      bool // The texts are search queries rather than documents, for models that embed them differently
+	Dimensions int  // Vector length to ask for, for models that can shorten theirs; 0 for the model's own
+}
+
+// EmbedResponse holds one vector per requested text, in order
+type EmbedResponse struct {
+	Vectors     [][]float32
+	Model       string
+	InputTokens int // 0 for providers that don't report it
+}
+
+// Embedder turns text into vectors for similarity search
+type Embedder interface {
+	// Name returns the provider's name, one of the provider constants
+	Name() string
+
+	// Embed returns the embeddings of req.Texts
+	Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error)
+}
+
+// NewEmbedder creates the embedding provider named by EMBEDDING_PROVIDER
+// (openai, gemini, or ollama) from its environment variables, as for
+// completions. It defaults to LLM_PROVIDER, except that Anthropic has no
+// embeddings API, so it and "none" return ErrNoEmbedder.
+func NewEmbedder() (Embedder, error) {
+	switch name := embedderName(); name {
+	case OpenAI:
+		provider, err := newOpenAIProvider()
+		if err != nil {
+			return nil, err
+		}
+		return provider.(Embedder), nil
+	case Gemini:
+		provider, err := newGeminiProvider()
+		if err != nil {
+			return nil, err
+		}
+		return provider.(Embedder), nil
+	case Ollama:
+		return newOllamaProvider().(Embedder), nil
+	case Anthropic, "none":
+		return nil, ErrNoEmbedder
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", name)
+	}
+}
+
+// EmbeddingModel returns the model embeddings come from: EMBEDDING_MODEL, or
+// the default of the provider named by EMBEDDING_PROVIDER
+func EmbeddingModel() string {
+	if model := os.Getenv("EMBEDDING_MODEL"); model != "" {
+		return model
+	}
+	switch embedderName() {
+	case OpenAI:
+		return openAIDefaultEmbeddingModel
+	case Gemini:
+		return geminiDefaultEmbeddingModel
+	case Ollama:
+		return ollamaDefaultEmbeddingModel
+	}
+	return ""
+}
+
+// embedderName returns EMBEDDING_PROVIDER, LLM_PROVIDER when unset
+func embedderName() string {
+	if name := strings.ToLower(strings.TrimSpace(os.Getenv("EMBEDDING_PROVIDER"))); name != "" {
+		return name
+	}
+	return providerName()
+}
+
+// checkEmbeddings reports a response with a vector missing
+func checkEmbeddings(provider string, req EmbedRequest, vectors [][]float32) error {
+	if len(vectors) != len(req.Texts) {
+		return fmt.Errorf("%w: %s returned %d embeddings for %d texts", ErrAPIError, provider, len(vectors), len(req.Texts))
+	}
+	for _, v := range vectors {
+		if len(v) == 0 {
+			return fmt.Errorf("%w: %s returned an empty embedding", ErrAPIError, provider)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package llm
+
+import "errors"
+
+var (
+	// ErrAPIKeyMissing is returned when the selected provider's API key is not set
+	ErrAPIKeyMissing = errors.New("LLM provider API key is not set")
+
+	// ErrRateLimitExceeded is returned when rate limit is hit
+	ErrRateLimitExceeded = errors.New("LLM API rate limit exceeded")
+
+	// ErrAPIError is returned for general API errors
+	ErrAPIError = errors.New("LLM API error")
+
+	// ErrTimeout is returned when request times out
+	ErrTimeout = errors.New("LLM API request timeout")
+
+	// ErrEmptyResponse is returned when the model returns empty content
+	ErrEmptyResponse = errors.New("model returned empty response")
+
+	// ErrTokenBudgetExceeded is returned when a client's token budget is spent
+	ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+	// ErrNoEmbedder is returned when no embedding provider is configured
+	ErrNoEmbedder = errors.New("no embedding provider is configured - set EMBEDDING_PROVIDER to openai, gemini, or ollama")
+)
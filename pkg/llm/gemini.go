@@ -0,0 +1,247 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// geminiProvider sends requests to the Google Gemini API
+type geminiProvider struct {
+	apiKey  string
+	baseURL string
+}
+
+// geminiContent is a turn of a Gemini conversation, or a system instruction
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart is a piece of a turn's content
+type geminiPart struct {
+	Text    string `json:"text"`
+	Thought bool   `json:"thought,omitempty"` // A thought summary, not the answer
+}
+
+// geminiRequest is a generateContent request
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+// geminiGenerationConfig sets a request's output limits and format
+type geminiGenerationConfig struct {
+	MaxOutputTokens  int                   `json:"maxOutputTokens,omitempty"`
+	Temperature      float64               `json:"temperature,omitempty"`
+	ResponseMimeType string                `json:"responseMimeType,omitempty"`
+	ThinkingConfig   *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// geminiThinkingConfig sets the thinking budget of models that think
+type geminiThinkingConfig struct {
+	ThinkingBudget int `json:"thinkingBudget"`
+}
+
+// geminiResponse is a generateContent response, or a chunk of a streamed one
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// newGeminiProvider creates a provider from GEMINI_API_KEY
+func newGeminiProvider() (Provider, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: GEMINI_API_KEY", ErrAPIKeyMissing)
+	}
+	return &geminiProvider{apiKey: apiKey, baseURL: geminiBaseURL}, nil
+}
+
+// Name implements Provider
+func (p *geminiProvider) Name() string {
+	return Gemini
+}
+
+// Complete implements Provider
+func (p *geminiProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	return p.generate(ctx, req, p.request(req))
+}
+
+// CompleteJSON implements Provider, with Gemini's JSON output mode
+func (p *geminiProvider) CompleteJSON(ctx context.Context, req Request) (*Response, error) {
+	body := p.request(req)
+	body.GenerationConfig.ResponseMimeType = "application/json"
+	return p.generate(ctx, req, body)
+}
+
+// generate sends a generateContent request
+func (p *geminiProvider) generate(ctx context.Context, req Request, body geminiRequest) (*Response, error) {
+	resp, err := postJSON(ctx, httpClientFor(req, DefaultTimeout), Gemini, p.endpoint(req.Model, "generateContent"), p.headers(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var generated geminiResponse
+	if err := decodeJSON(resp, &generated); err != nil {
+		return nil, err
+	}
+
+	result := &Response{Model: req.Model}
+	var text strings.Builder
+	p.collect(&generated, result, &text)
+	result.Text = text.String()
+	return result, nil
+}
+
+// Stream implements Provider
+func (p *geminiProvider) Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error) {
+	resp, err := postJSON(ctx, httpClientFor(req, DefaultTimeout), Gemini, p.endpoint(req.Model, "streamGenerateContent")+"?alt=sse", p.headers(), p.request(req))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Response{Model: req.Model}
+	var text strings.Builder
+	err = eachLine(resp, func(line string) error {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			return nil
+		}
+
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		var delta strings.Builder
+		p.collect(&chunk, result, &delta)
+		if delta.Len() == 0 {
+			return nil
+		}
+		text.WriteString(delta.String())
+		return onText(delta.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Text = text.String()
+	return result, nil
+}
+
+// collect adds a response's answer text to text, leaving out thoughts, and
+// its usage and model to result. Thinking is billed as output.
+func (p *geminiProvider) collect(generated *geminiResponse, result *Response, text *strings.Builder) {
+	if len(generated.Candidates) > 0 {
+		for _, part := range generated.Candidates[0].Content.Parts {
+			if !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+	}
+	if usage := generated.UsageMetadata; usage != nil {
+		result.InputTokens = usage.PromptTokenCount
+		result.OutputTokens = usage.CandidatesTokenCount + usage.ThoughtsTokenCount
+	}
+	if generated.ModelVersion != "" {
+		result.Model = generated.ModelVersion
+	}
+}
+
+// request converts req to a generateContent request
+func (p *geminiProvider) request(req Request) geminiRequest {
+	body := geminiRequest{
+		Contents: make([]geminiContent, 0, len(req.Messages)),
+		GenerationConfig: geminiGenerationConfig{
+			MaxOutputTokens: req.MaxTokens,
+			Temperature:     req.Temperature,
+		},
+	}
+	if req.System != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+	for _, m := range req.Messages {
+		role := "user"
+		if m.Role == RoleAssistant {
+			role = "model"
+		}
+		body.Contents = append(body.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+	if req.Thinking > 0 {
+		body.GenerationConfig.ThinkingConfig = &geminiThinkingConfig{ThinkingBudget: req.Thinking}
+	}
+	return body
+}
+
+// endpoint returns the URL of a model's method
+func (p *geminiProvider) endpoint(model, method string) string {
+	return p.baseURL + "/models/" + url.PathEscape(model) + ":" + method
+}
+
+// headers returns the request headers
+func (p *geminiProvider) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": p.apiKey}
+}
+
+// Embed implements Embedder with batchEmbedContents, asking for query or
+// document embeddings
+func (p *geminiProvider) Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error) {
+	type embedRequest struct {
+		Model                string        `json:"model"`
+		Content              geminiContent `json:"content"`
+		TaskType             string        `json:"taskType"`
+		OutputDimensionality int           `json:"outputDimensionality,omitempty"`
+	}
+	taskType := "RETRIEVAL_DOCUMENT"
+	if req.Query {
+		taskType = "RETRIEVAL_QUERY"
+	}
+	var body struct {
+		Requests []embedRequest `json:"requests"`
+	}
+	for _, text := range req.Texts {
+		body.Requests = append(body.Requests, embedRequest{
+			Model:                "models/" + req.Model,
+			Content:              geminiContent{Parts: []geminiPart{{Text: text}}},
+			TaskType:             taskType,
+			OutputDimensionality: req.Dimensions,
+		})
+	}
+
+	resp, err := postJSON(ctx, &http.Client{Timeout: DefaultTimeout}, Gemini, p.endpoint(req.Model, "batchEmbedContents"), p.headers(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var embeddings struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := decodeJSON(resp, &embeddings); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, 0, len(embeddings.Embeddings))
+	for _, e := range embeddings.Embeddings {
+		vectors = append(vectors, e.Values)
+	}
+	if err := checkEmbeddings(Gemini, req, vectors); err != nil {
+		return nil, err
+	}
+
+	return &EmbedResponse{Vectors: vectors, Model: req.Model}, nil
+}
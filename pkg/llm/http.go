@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRetries is how many times a request is attempted
+const maxRetries = 3
+
+// postJSON sends body as JSON to url with headers, retrying timeouts and rate
+// limits, and returns the successful response for the caller to read and
+// close. Errors name provider.
+func postJSON(ctx context.Context, httpClient *http.Client, provider, url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var resp *http.Response
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			httpReq.Header.Set(name, value)
+		}
+
+		resp, err = httpClient.Do(httpReq)
+		if err != nil {
+			if attempt == maxRetries-1 || ctx.Err() != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrTimeout, provider, err)
+			}
+			time.Sleep(time.Duration(attempt+1) * 2 * time.Second)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			if attempt == maxRetries-1 {
+				return nil, fmt.Errorf("%w: %s", ErrRateLimitExceeded, provider)
+			}
+			time.Sleep(time.Duration(attempt+1) * 10 * time.Second)
+			continue
+		}
+
+		break
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, fmt.Errorf("%w: %s status %d: %s", ErrAPIError, provider, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return resp, nil
+}
+
+// decodeJSON reads a successful response's JSON body into target
+func decodeJSON(resp *http.Response, target interface{}) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// eachLine calls fn with each line of a streamed response body: server-sent
+// events, or newline-delimited JSON
+func eachLine(resp *http.Response, fn func(line string) error) error {
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	return nil
+}
+
+// httpClientFor returns a client with time for the request: longer with
+// extended thinking
+func httpClientFor(req Request, timeout time.Duration) *http.Client {
+	if req.Thinking > 0 {
+		timeout = max(timeout, ThinkingTimeout)
+	}
+	return &http.Client{Timeout: timeout}
+}
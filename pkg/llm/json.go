@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// codeFencePattern matches a markdown code block, with or without a language
+var codeFencePattern = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)```")
+
+// trailingCommaPattern matches a comma directly before a closing bracket
+var trailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+
+// ParseJSONResponse is a helper to parse JSON from a model's response. Models
+// sometimes wrap the JSON in a code block, add prose before or after it, or
+// leave trailing commas, so every plausible JSON value in the response is
+// tried: the whole text, then code blocks, then bracket-balanced spans from
+// longest to shortest. The first that decodes into target wins, preferring
+// candidates that decode as-is over ones that needed trailing commas removed.
+func ParseJSONResponse(responseText string, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("failed to parse JSON response: target must be a non-nil pointer")
+	}
+
+	candidates := jsonCandidates(responseText)
+
+	var firstErr error
+	for _, repair := range []bool{false, true} {
+		for _, candidate := range candidates {
+			if repair {
+				repaired := removeTrailingCommas(candidate)
+				if repaired == candidate {
+					continue
+				}
+				candidate = repaired
+			}
+
+			// Decode into a fresh value so a failed candidate leaves target untouched
+			decoded := reflect.New(rv.Elem().Type())
+			err := json.Unmarshal([]byte(candidate), decoded.Interface())
+			if err == nil {
+				rv.Elem().Set(decoded.Elem())
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = fmt.Errorf("no JSON found in response")
+	}
+	return fmt.Errorf("failed to parse JSON response: %w", firstErr)
+}
+
+// jsonCandidates returns the substrings of a response that may be its JSON,
+// most likely first and without repeats
+func jsonCandidates(text string) []string {
+	var candidates []string
+	seen := map[string]bool{}
+	add := func(candidate string) {
+		candidate = strings.TrimSpace(candidate)
+		if candidate != "" && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	add(text)
+	for _, match := range codeFencePattern.FindAllStringSubmatch(text, -1) {
+		add(match[1])
+	}
+
+	spans := balancedSpans(text)
+	sort.SliceStable(spans, func(i, j int) bool { return len(spans[i]) > len(spans[j]) })
+	for _, span := range spans {
+		add(span)
+	}
+
+	return candidates
+}
+
+// balancedSpans returns the outermost substrings that start at a "{" or "["
+// and end at its matching bracket, skipping brackets inside JSON strings.
+// Spans that never close, such as a response cut off at max tokens, are
+// left out.
+func balancedSpans(text string) []string {
+	var spans []string
+	for start := 0; start < len(text); start++ {
+		if text[start] != '{' && text[start] != '[' {
+			continue
+		}
+		if end := matchingBracket(text, start); end != -1 {
+			spans = append(spans, text[start:end+1])
+			start = end
+		}
+	}
+	return spans
+}
+
+// matchingBracket returns the index of the bracket closing the one at start,
+// or -1 when it isn't closed or is closed by the wrong kind
+func matchingBracket(text string, start int) int {
+	var stack []byte
+	inString, escaped := false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if stack[len(stack)-1] != c {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// removeTrailingCommas drops commas before closing brackets, outside strings
+func removeTrailingCommas(candidate string) string {
+	var b strings.Builder
+	inString, escaped := false, false
+	segmentStart := 0
+	for i := 0; i < len(candidate); i++ {
+		c := candidate[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				b.WriteString(candidate[segmentStart : i+1])
+				segmentStart = i + 1
+			}
+			continue
+		}
+		if c == '"' {
+			b.WriteString(trailingCommaPattern.ReplaceAllString(candidate[segmentStart:i], "$1"))
+			segmentStart = i
+			inString = true
+		}
+	}
+	if inString {
+		b.WriteString(candidate[segmentStart:])
+	} else {
+		b.WriteString(trailingCommaPattern.ReplaceAllString(candidate[segmentStart:], "$1"))
+	}
+	return b.String()
+}
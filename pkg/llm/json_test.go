@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fuzzConcepts has the shape of the concept extraction responses parsed in
+// production
+type fuzzConcepts struct {
+	Concepts []fuzzConcept `json:"concepts"`
+}
+
+// fuzzConcept is one concept in fuzzConcepts
+type fuzzConcept struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// FuzzParseJSONResponse checks that no response panics the parser, that a
+// failed parse leaves the target untouched, and that a response that's
+// already valid JSON decodes as encoding/json decodes it. The seed corpus in
+// testdata/fuzz holds malformed responses seen from models: prose around the
+// JSON, code fences, trailing commas, and output cut off at max tokens.
+func FuzzParseJSONResponse(f *testing.F) {
+	f.Add(`{"concepts": [{"title": "Spacing", "description": "Reviews spread over time", "tags": ["memory"]}]}`)
+
+	f.Fuzz(func(t *testing.T, response string) {
+		sentinel := fuzzConcepts{Concepts: []fuzzConcept{{Title: "untouched"}}}
+		target := sentinel
+		if err := ParseJSONResponse(response, &target); err != nil {
+			if !reflect.DeepEqual(target, sentinel) {
+				t.Fatalf("failed parse changed the target: %v", err)
+			}
+			return
+		}
+
+		var want fuzzConcepts
+		if json.Unmarshal([]byte(strings.TrimSpace(response)), &want) == nil && !reflect.DeepEqual(target, want) {
+			t.Fatalf("valid JSON decoded as %+v, want %+v", target, want)
+		}
+	})
+}
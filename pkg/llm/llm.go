@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/pkg/claude"
+)
+
+// Providers, selected with LLM_PROVIDER
+const (
+	Anthropic = "anthropic" // Claude, the default
+	OpenAI    = "openai"    // OpenAI, or any OpenAI-compatible chat completions API
+	Gemini    = "gemini"    // Google Gemini
+	Ollama    = "ollama"    // Local models served by Ollama
+)
+
+// Message roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+const (
+	// DefaultMaxTokens is the default max tokens for responses
+	DefaultMaxTokens = 4096
+
+	// DefaultTimeout is the default request timeout
+	DefaultTimeout = 60 * time.Second
+
+	// ThinkingTimeout is the request timeout with extended thinking, or for
+	// local models, which respond more slowly
+	ThinkingTimeout = 5 * time.Minute
+)
+
+// Provider defaults
+const (
+	openAIDefaultModel = "gpt-4.1-mini"
+	openAIBaseURL      = "https://api.openai.com/v1"
+	geminiDefaultModel = "gemini-2.5-flash"
+	geminiBaseURL      = "https://generativelanguage.googleapis.com/v1beta"
+	ollamaDefaultModel = "llama3.1"
+	ollamaBaseURL      = "http://localhost:11434"
+)
+
+// Message is a single turn of a conversation
+type Message struct {
+	Role    string `json:"role"` // RoleUser or RoleAssistant
+	Content string `json:"content"`
+}
+
+// Request is a completion request. Messages must alternate roles and end with
+// a user message.
+type Request struct {
+	Model       string
+	System      string // Optional system prompt
+	Messages    []Message
+	MaxTokens   int     // Includes any thinking budget
+	Temperature float64 // The provider's default when 0
+	Thinking    int     // Extended thinking budget in tokens, 0 for none; ignored by providers without it
+}
+
+// Response is a completed response
+type Response struct {
+	Text         string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider sends completion requests to a model API
+type Provider interface {
+	// Name returns the provider's name, one of the provider constants
+	Name() string
+
+	// Complete returns the model's answer to req
+	Complete(ctx context.Context, req Request) (*Response, error)
+
+	// CompleteJSON is Complete for prompts asking for JSON, using the
+	// provider's JSON output mode when it has one that allows any JSON value
+	CompleteJSON(ctx context.Context, req Request) (*Response, error)
+
+	// Stream is Complete, passing the answer's text to onText as it's
+	// generated. An error from onText stops the stream.
+	Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error)
+}
+
+// NewProvider creates the provider named by LLM_PROVIDER (anthropic, the
+// default, openai, gemini, or ollama) from its environment variables
+func NewProvider() (Provider, error) {
+	switch name := providerName(); name {
+	case Anthropic:
+		return newAnthropicProvider()
+	case OpenAI:
+		return newOpenAIProvider()
+	case Gemini:
+		return newGeminiProvider()
+	case Ollama:
+		return newOllamaProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", name)
+	}
+}
+
+// DefaultModel returns the model requests go to unless they name one: the
+// model variable of the provider named by LLM_PROVIDER (CLAUDE_MODEL,
+// OPENAI_MODEL, GEMINI_MODEL, or OLLAMA_MODEL), or its default
+func DefaultModel() string {
+	env, fallback := "CLAUDE_MODEL", claude.DefaultModel
+	switch providerName() {
+	case OpenAI:
+		env, fallback = "OPENAI_MODEL", openAIDefaultModel
+	case Gemini:
+		env, fallback = "GEMINI_MODEL", geminiDefaultModel
+	case Ollama:
+		env, fallback = "OLLAMA_MODEL", ollamaDefaultModel
+	}
+
+	if model := os.Getenv(env); model != "" {
+		return model
+	}
+	return fallback
+}
+
+// providerName returns LLM_PROVIDER, Anthropic when unset
+func providerName() string {
+	if name := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER"))); name != "" {
+		return name
+	}
+	return Anthropic
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ollamaProvider sends requests to local models served by Ollama
+type ollamaProvider struct {
+	baseURL string
+}
+
+// ollamaRequest is a chat request
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []Message     `json:"messages"` // Roles "system", "user", or "assistant"
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"` // "json" constrains the output to JSON
+	Options  ollamaOptions `json:"options"`
+}
+
+// ollamaOptions are a chat request's model parameters
+type ollamaOptions struct {
+	NumPredict  int     `json:"num_predict,omitempty"` // Max tokens
+	Temperature float64 `json:"temperature,omitempty"`
+}
+
+// ollamaResponse is a chat response, or a line of a streamed one
+type ollamaResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+// newOllamaProvider creates a provider from OLLAMA_URL (default
+// http://localhost:11434). Ollama needs no API key.
+func newOllamaProvider() Provider {
+	baseURL := strings.TrimSuffix(os.Getenv("OLLAMA_URL"), "/")
+	if baseURL == "" {
+		baseURL = ollamaBaseURL
+	}
+	return &ollamaProvider{baseURL: baseURL}
+}
+
+// Name implements Provider
+func (p *ollamaProvider) Name() string {
+	return Ollama
+}
+
+// Complete implements Provider
+func (p *ollamaProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	return p.chat(ctx, req, p.request(req))
+}
+
+// CompleteJSON implements Provider, with Ollama's JSON output mode
+func (p *ollamaProvider) CompleteJSON(ctx context.Context, req Request) (*Response, error) {
+	body := p.request(req)
+	body.Format = "json"
+	return p.chat(ctx, req, body)
+}
+
+// chat sends a chat request without streaming
+func (p *ollamaProvider) chat(ctx context.Context, req Request, body ollamaRequest) (*Response, error) {
+	resp, err := postJSON(ctx, httpClientFor(req, ThinkingTimeout), Ollama, p.baseURL+"/api/chat", nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var chat ollamaResponse
+	if err := decodeJSON(resp, &chat); err != nil {
+		return nil, err
+	}
+	if chat.Error != "" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrAPIError, Ollama, chat.Error)
+	}
+
+	return &Response{
+		Text:         chat.Message.Content,
+		Model:        chat.Model,
+		InputTokens:  chat.PromptEvalCount,
+		OutputTokens: chat.EvalCount,
+	}, nil
+}
+
+// Stream implements Provider
+func (p *ollamaProvider) Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error) {
+	body := p.request(req)
+	body.Stream = true
+
+	resp, err := postJSON(ctx, httpClientFor(req, ThinkingTimeout), Ollama, p.baseURL+"/api/chat", nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Response{Model: req.Model}
+	var text strings.Builder
+	err = eachLine(resp, func(line string) error {
+		if strings.TrimSpace(line) == "" {
+			return nil
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream line: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("%w: %s: %s", ErrAPIError, Ollama, chunk.Error)
+		}
+
+		if chunk.Done {
+			result.Model = chunk.Model
+			result.InputTokens, result.OutputTokens = chunk.PromptEvalCount, chunk.EvalCount
+		}
+		if chunk.Message.Content == "" {
+			return nil
+		}
+		text.WriteString(chunk.Message.Content)
+		return onText(chunk.Message.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Text = text.String()
+	return result, nil
+}
+
+// request converts req to a chat request
+func (p *ollamaProvider) request(req Request) ollamaRequest {
+	body := ollamaRequest{
+		Model:    req.Model,
+		Messages: make([]Message, 0, len(req.Messages)+1),
+		Options:  ollamaOptions{NumPredict: req.MaxTokens, Temperature: req.Temperature},
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, Message{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, req.Messages...)
+	return body
+}
+
+// Embed implements Embedder with the embed API
+func (p *ollamaProvider) Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error) {
+	body := struct {
+		Model      string   `json:"model"`
+		Input      []string `json:"input"`
+		Dimensions int      `json:"dimensions,omitempty"`
+	}{Model: req.Model, Input: req.Texts, Dimensions: req.Dimensions}
+
+	resp, err := postJSON(ctx, &http.Client{Timeout: ThinkingTimeout}, Ollama, p.baseURL+"/api/embed", nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var embeddings struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float32 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+		Error           string      `json:"error"`
+	}
+	if err := decodeJSON(resp, &embeddings); err != nil {
+		return nil, err
+	}
+	if embeddings.Error != "" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrAPIError, Ollama, embeddings.Error)
+	}
+	if err := checkEmbeddings(Ollama, req, embeddings.Embeddings); err != nil {
+		return nil, err
+	}
+
+	return &EmbedResponse{Vectors: embeddings.Embeddings, Model: embeddings.Model, InputTokens: embeddings.PromptEvalCount}, nil
+}
@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// openAIProvider sends requests to OpenAI's chat completions API, or any
+// compatible one
+type openAIProvider struct {
+	apiKey  string
+	baseURL string
+}
+
+// openAIMessage is a chat completions message
+type openAIMessage struct {
+	Role    string `json:"role"` // "system", "user", or "assistant"
+	Content string `json:"content"`
+}
+
+// openAIRequest is a chat completions request
+type openAIRequest struct {
+	Model               string               `json:"model"`
+	Messages            []openAIMessage      `json:"messages"`
+	MaxCompletionTokens int                  `json:"max_completion_tokens,omitempty"`
+	Temperature         float64              `json:"temperature,omitempty"`
+	Stream              bool                 `json:"stream,omitempty"`
+	StreamOptions       *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+// openAIStreamOptions asks a stream to end with the usage
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIUsage is a response's token usage
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// newOpenAIProvider creates a provider from OPENAI_API_KEY and OPENAI_BASE_URL
+// (default https://api.openai.com/v1). A compatible API at its own base URL
+// may need no key.
+func newOpenAIProvider() (Provider, error) {
+	p := &openAIProvider{
+		apiKey:  os.Getenv("OPENAI_API_KEY"),
+		baseURL: strings.TrimSuffix(os.Getenv("OPENAI_BASE_URL"), "/"),
+	}
+	if p.baseURL == "" {
+		if p.apiKey == "" {
+			return nil, fmt.Errorf("%w: OPENAI_API_KEY", ErrAPIKeyMissing)
+		}
+		p.baseURL = openAIBaseURL
+	}
+	return p, nil
+}
+
+// Name implements Provider
+func (p *openAIProvider) Name() string {
+	return OpenAI
+}
+
+// Complete implements Provider
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := postJSON(ctx, httpClientFor(req, DefaultTimeout), OpenAI, p.baseURL+"/chat/completions", p.headers(), p.request(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var completion struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := decodeJSON(resp, &completion); err != nil {
+		return nil, err
+	}
+
+	result := &Response{
+		Model:        completion.Model,
+		InputTokens:  completion.Usage.PromptTokens,
+		OutputTokens: completion.Usage.CompletionTokens,
+	}
+	if len(completion.Choices) > 0 {
+		result.Text = completion.Choices[0].Message.Content
+	}
+	return result, nil
+}
+
+// CompleteJSON implements Provider. OpenAI's JSON mode only allows objects,
+// and prompts may ask for arrays, so the prompt's format instructions are
+// relied on instead.
+func (p *openAIProvider) CompleteJSON(ctx context.Context, req Request) (*Response, error) {
+	return p.Complete(ctx, req)
+}
+
+// Stream implements Provider
+func (p *openAIProvider) Stream(ctx context.Context, req Request, onText func(text string) error) (*Response, error) {
+	body := p.request(req)
+	body.Stream = true
+	body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	resp, err := postJSON(ctx, httpClientFor(req, DefaultTimeout), OpenAI, p.baseURL+"/chat/completions", p.headers(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Response{Model: req.Model}
+	var text strings.Builder
+	err = eachLine(resp, func(line string) error {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			return nil
+		}
+
+		var chunk struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *openAIUsage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.InputTokens, result.OutputTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			text.WriteString(choice.Delta.Content)
+			if err := onText(choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Text = text.String()
+	return result, nil
+}
+
+// request converts req to a chat completions request
+func (p *openAIProvider) request(req Request) openAIRequest {
+	body := openAIRequest{
+		Model:               req.Model,
+		MaxCompletionTokens: req.MaxTokens,
+		Temperature:         req.Temperature,
+		Messages:            make([]openAIMessage, 0, len(req.Messages)+1),
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		body.Messages = append(body.Messages, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+	return body
+}
+
+// headers returns the request headers, with the API key when there is one
+func (p *openAIProvider) headers() map[string]string {
+	if p.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + p.apiKey}
+}
+
+// Embed implements Embedder with the embeddings API
+func (p *openAIProvider) Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error) {
+	body := struct {
+		Model      string   `json:"model"`
+		Input      []string `json:"input"`
+		Dimensions int      `json:"dimensions,omitempty"`
+	}{Model: req.Model, Input: req.Texts, Dimensions: req.Dimensions}
+
+	resp, err := postJSON(ctx, &http.Client{Timeout: DefaultTimeout}, OpenAI, p.baseURL+"/embeddings", p.headers(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var embeddings struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := decodeJSON(resp, &embeddings); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(req.Texts))
+	for _, d := range embeddings.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	if err := checkEmbeddings(OpenAI, req, vectors); err != nil {
+		return nil, err
+	}
+
+	return &EmbedResponse{Vectors: vectors, Model: embeddings.Model, InputTokens: embeddings.Usage.PromptTokens}, nil
+}
@@ -0,0 +1,17 @@
+package meeting
+
+import "errors"
+
+var (
+	// ErrNoTranscript is returned when a recording has no transcript
+	ErrNoTranscript = errors.New("no transcript available for this meeting")
+
+	// ErrNotFound is returned when the meeting or recording doesn't exist
+	ErrNotFound = errors.New("meeting recording not found")
+
+	// ErrUnauthorized is returned when the provider rejects the credential
+	ErrUnauthorized = errors.New("meeting provider rejected the credential")
+
+	// ErrInvalidCredential is returned when a stored credential isn't in the provider's format
+	ErrInvalidCredential = errors.New("invalid meeting provider credential")
+)
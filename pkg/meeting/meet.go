@@ -0,0 +1,186 @@
+package meeting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Google API endpoints
+const (
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	meetAPIURL     = "https://meet.googleapis.com/v2"
+)
+
+// MeetClient fetches Google Meet transcripts with an OAuth refresh token
+// granted the meetings.space.readonly scope
+type MeetClient struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewMeetClient creates a client from a stored credential, a JSON object with
+// client_id, client_secret, and refresh_token
+func NewMeetClient(credential string) (*MeetClient, error) {
+	var c MeetClient
+	if err := json.Unmarshal([]byte(credential), &c); err != nil || c.ClientID == "" || c.ClientSecret == "" || c.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: google needs a JSON object with client_id, client_secret, and refresh_token", ErrInvalidCredential)
+	}
+	return &c, nil
+}
+
+// meetParticipant is the part of a Meet participant that names them
+type meetParticipant struct {
+	Name         string `json:"name"`
+	SignedinUser *struct {
+		DisplayName string `json:"displayName"`
+	} `json:"signedinUser"`
+	AnonymousUser *struct {
+		DisplayName string `json:"displayName"`
+	} `json:"anonymousUser"`
+	PhoneUser *struct {
+		DisplayName string `json:"displayName"`
+	} `json:"phoneUser"`
+}
+
+// displayName returns the participant's name, whichever kind of user they are
+func (p meetParticipant) displayName() string {
+	switch {
+	case p.SignedinUser != nil:
+		return p.SignedinUser.DisplayName
+	case p.AnonymousUser != nil:
+		return p.AnonymousUser.DisplayName
+	case p.PhoneUser != nil:
+		return p.PhoneUser.DisplayName
+	}
+	return ""
+}
+
+// GetTranscript fetches the transcript of a conference, given its record
+// name ("conferenceRecords/abc-123") or ID
+func (c *MeetClient) GetTranscript(ctx context.Context, conferenceRecord string) (*Transcript, error) {
+	token, err := postToken(ctx, googleTokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {c.RefreshToken},
+	}, c.ClientID, c.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	record := conferenceRecord
+	if !strings.HasPrefix(record, "conferenceRecords/") {
+		record = "conferenceRecords/" + url.PathEscape(record)
+	}
+
+	var conference struct {
+		StartTime time.Time `json:"startTime"`
+	}
+	if err := getJSON(ctx, meetAPIURL+"/"+record, token, &conference); err != nil {
+		return nil, fmt.Errorf("failed to get conference record: %w", err)
+	}
+
+	var transcripts struct {
+		Transcripts []struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"transcripts"`
+	}
+	if err := getJSON(ctx, meetAPIURL+"/"+record+"/transcripts", token, &transcripts); err != nil {
+		return nil, fmt.Errorf("failed to list meet transcripts: %w", err)
+	}
+
+	var transcriptName string
+	for _, t := range transcripts.Transcripts {
+		if t.State == "FILE_GENERATED" || t.State == "ENDED" {
+			transcriptName = t.Name
+			break
+		}
+	}
+	if transcriptName == "" {
+		return nil, ErrNoTranscript
+	}
+
+	speakers := map[string]string{}
+	err = eachPage(ctx, meetAPIURL+"/"+record+"/participants", token, func(page []byte) (string, error) {
+		var resp struct {
+			Participants  []meetParticipant `json:"participants"`
+			NextPageToken string            `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(page, &resp); err != nil {
+			return "", err
+		}
+		for _, p := range resp.Participants {
+			speakers[p.Name] = p.displayName()
+		}
+		return resp.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meet participants: %w", err)
+	}
+
+	transcript := &Transcript{
+		Title: "Google Meet on " + conference.StartTime.Format("Jan 2, 2006 15:04 MST"),
+	}
+	err = eachPage(ctx, meetAPIURL+"/"+transcriptName+"/entries", token, func(page []byte) (string, error) {
+		var resp struct {
+			TranscriptEntries []struct {
+				Participant  string    `json:"participant"`
+				Text         string    `json:"text"`
+				LanguageCode string    `json:"languageCode"`
+				StartTime    time.Time `json:"startTime"`
+			} `json:"transcriptEntries"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(page, &resp); err != nil {
+			return "", err
+		}
+		for _, entry := range resp.TranscriptEntries {
+			if transcript.Language == "" {
+				transcript.Language = entry.LanguageCode
+			}
+			transcript.Utterances = append(transcript.Utterances, Utterance{
+				Speaker: speakers[entry.Participant],
+				Start:   entry.StartTime.Sub(conference.StartTime),
+				Text:    strings.TrimSpace(entry.Text),
+			})
+		}
+		return resp.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meet transcript entries: %w", err)
+	}
+
+	if len(transcript.Utterances) == 0 {
+		return nil, ErrNoTranscript
+	}
+	return transcript, nil
+}
+
+// eachPage GETs every page of a Google list endpoint, passing each body to
+// handle, which returns the next page token
+func eachPage(ctx context.Context, listURL, token string, handle func(page []byte) (string, error)) error {
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {"100"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		page, err := get(ctx, listURL+"?"+query.Encode(), token)
+		if err != nil {
+			return err
+		}
+
+		pageToken, err = handle(page)
+		if err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		if pageToken == "" {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package meeting
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Utterance is one speaker's turn in a meeting
+type Utterance struct {
+	Speaker string        `json:"speaker"` // Empty when the transcript doesn't say
+	Start   time.Duration `json:"start"`
+	Text    string        `json:"text"`
+}
+
+// Transcript is a speaker-attributed meeting transcript
+type Transcript struct {
+	Title      string      `json:"title"`
+	Language   string      `json:"language,omitempty"` // BCP 47, when the provider reports it
+	Utterances []Utterance `json:"utterances"`
+}
+
+// Text renders the transcript as "Speaker: text" lines, merging consecutive
+// turns by the same speaker
+func (t *Transcript) Text() string {
+	var b strings.Builder
+	previous := "\x00"
+	for _, u := range t.Utterances {
+		if u.Speaker == previous {
+			b.WriteString(" ")
+			b.WriteString(u.Text)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if u.Speaker != "" {
+			b.WriteString(u.Speaker)
+			b.WriteString(": ")
+		}
+		b.WriteString(u.Text)
+		previous = u.Speaker
+	}
+	return b.String()
+}
+
+// Speakers lists the distinct speakers in order of first appearance
+func (t *Transcript) Speakers() []string {
+	speakers := []string{}
+	for _, u := range t.Utterances {
+		if u.Speaker != "" && !slices.Contains(speakers, u.Speaker) {
+			speakers = append(speakers, u.Speaker)
+		}
+	}
+	return speakers
+}
+
+var (
+	// cueTimingPattern matches a VTT or SRT cue timing line, capturing the start
+	cueTimingPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{2}):(\d{2})[.,](\d{3})\s+-->`)
+
+	// voiceTagPattern matches a WebVTT voice span, <v Speaker>text
+	voiceTagPattern = regexp.MustCompile(`^<v(?:\.[\w.]+)?\s+([^>]+)>(.*?)(?:</v>)?$`)
+
+	// speakerPrefixPattern matches a "Speaker: text" cue, as Zoom writes them
+	speakerPrefixPattern = regexp.MustCompile(`^([^:?!]{1,60}?):\s+(.+)$`)
+
+	// namePrefixPattern is the stricter match for plain text, where prose can
+	// contain colons: one to four capitalized words before the colon
+	namePrefixPattern = regexp.MustCompile(`^(\p{Lu}[\p{L}\p{M}'’.-]*(?:\s+\p{Lu}[\p{L}\p{M}'’.-]*){0,3}):\s+(.+)$`)
+
+	// textTimestampPattern strips a leading "[00:01:02]" or "00:01:02" from text lines
+	textTimestampPattern = regexp.MustCompile(`^\[?(?:(\d+):)?(\d{2}):(\d{2})\]?\s+`)
+)
+
+// ParseVTT parses a WebVTT or SRT transcript. Speakers come from voice tags
+// or "Speaker: " prefixes on the cue text.
+func ParseVTT(data []byte) *Transcript {
+	transcript := &Transcript{}
+	var start time.Duration
+	inCue := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if m := cueTimingPattern.FindStringSubmatch(line); m != nil {
+			start = parseTimestamp(m[1], m[2], m[3])
+			inCue = true
+			continue
+		}
+		if line == "" {
+			inCue = false
+			continue
+		}
+		if !inCue {
+			continue // Header, NOTE blocks, and cue numbers
+		}
+
+		if u, ok := parseUtterance(line, speakerPrefixPattern); ok {
+			u.Start = start
+			transcript.Utterances = append(transcript.Utterances, u)
+		}
+	}
+
+	return transcript
+}
+
+// ParseText parses a plain text transcript of "Speaker: text" lines, as
+// exported from Meet transcript docs. Lines without a speaker continue the
+// previous turn.
+func ParseText(data []byte) *Transcript {
+	transcript := &Transcript{}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		var start time.Duration
+		if m := textTimestampPattern.FindStringSubmatch(line); m != nil {
+			start = parseTimestamp(m[1], m[2], m[3])
+			line = line[len(m[0]):]
+		}
+		if line == "" {
+			continue
+		}
+
+		u, _ := parseUtterance(line, namePrefixPattern)
+		n := len(transcript.Utterances)
+		if u.Speaker == "" && n > 0 {
+			transcript.Utterances[n-1].Text += " " + u.Text
+			continue
+		}
+		u.Start = start
+		transcript.Utterances = append(transcript.Utterances, u)
+	}
+
+	return transcript
+}
+
+// parseUtterance splits a line into its speaker, matched by prefix, and text.
+// ok is false for lines with no text.
+func parseUtterance(line string, prefix *regexp.Regexp) (Utterance, bool) {
+	if m := voiceTagPattern.FindStringSubmatch(line); m != nil {
+		line = strings.TrimSpace(m[1]) + ": " + m[2]
+	}
+
+	var u Utterance
+	if m := prefix.FindStringSubmatch(line); m != nil {
+		u.Speaker, u.Text = strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	} else {
+		u.Text = line
+	}
+	return u, u.Text != ""
+}
+
+// parseTimestamp converts captured hours (optional), minutes, and seconds
+func parseTimestamp(hours, minutes, seconds string) time.Duration {
+	atoi := func(s string) time.Duration {
+		n := 0
+		for _, ch := range s {
+			n = n*10 + int(ch-'0')
+		}
+		return time.Duration(n)
+	}
+	return atoi(hours)*time.Hour + atoi(minutes)*time.Minute + atoi(seconds)*time.Second
+}
@@ -0,0 +1,82 @@
+package meeting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Zoom API endpoints
+const (
+	zoomTokenURL = "https://zoom.us/oauth/token"
+	zoomAPIURL   = "https://api.zoom.us/v2"
+)
+
+// ZoomClient fetches cloud recording transcripts with a Server-to-Server OAuth app
+type ZoomClient struct {
+	AccountID    string `json:"account_id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// NewZoomClient creates a client from a stored credential, a JSON object with
+// account_id, client_id, and client_secret
+func NewZoomClient(credential string) (*ZoomClient, error) {
+	var c ZoomClient
+	if err := json.Unmarshal([]byte(credential), &c); err != nil || c.AccountID == "" || c.ClientID == "" || c.ClientSecret == "" {
+		return nil, fmt.Errorf("%w: zoom needs a JSON object with account_id, client_id, and client_secret", ErrInvalidCredential)
+	}
+	return &c, nil
+}
+
+// GetTranscript fetches the transcript of a meeting's cloud recording. The
+// meeting is a numeric meeting ID or an instance UUID.
+func (c *ZoomClient) GetTranscript(ctx context.Context, meetingID string) (*Transcript, error) {
+	token, err := postToken(ctx, zoomTokenURL, url.Values{
+		"grant_type": {"account_credentials"},
+		"account_id": {c.AccountID},
+	}, c.ClientID, c.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// UUIDs that start with or contain "/" must be encoded twice
+	id := url.PathEscape(meetingID)
+	if strings.Contains(meetingID, "/") {
+		id = url.PathEscape(id)
+	}
+
+	var recording struct {
+		Topic          string `json:"topic"`
+		RecordingFiles []struct {
+			FileType    string `json:"file_type"`
+			Status      string `json:"status"`
+			DownloadURL string `json:"download_url"`
+		} `json:"recording_files"`
+	}
+	if err := getJSON(ctx, zoomAPIURL+"/meetings/"+id+"/recordings", token, &recording); err != nil {
+		return nil, fmt.Errorf("failed to get zoom recording: %w", err)
+	}
+
+	for _, file := range recording.RecordingFiles {
+		if file.FileType != "TRANSCRIPT" || file.Status != "completed" {
+			continue
+		}
+
+		data, err := get(ctx, file.DownloadURL, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download zoom transcript: %w", err)
+		}
+
+		transcript := ParseVTT(data)
+		if len(transcript.Utterances) == 0 {
+			return nil, ErrNoTranscript
+		}
+		transcript.Title = recording.Topic
+		return transcript, nil
+	}
+
+	return nil, ErrNoTranscript
+}
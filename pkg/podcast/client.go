@@ -0,0 +1,315 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxAudioBytes bounds downloaded episodes, about ten hours of 128 kbps MP3
+	MaxAudioBytes = 500 << 20
+
+	// maxFeedBytes bounds downloaded feeds; long-running shows' feeds run to megabytes
+	maxFeedBytes = 20 << 20
+
+	// feedTimeout bounds fetching a feed, or checking whether a URL is audio
+	feedTimeout = 30 * time.Second
+
+	// downloadTimeout bounds downloading an episode's audio
+	downloadTimeout = 30 * time.Minute
+
+	// userAgent identifies requests; some podcast hosts refuse Go's default
+	userAgent = "Lattice/1.0 (podcast ingestion)"
+)
+
+// audioExtensions are the file extensions of audio podcast hosts serve
+var audioExtensions = map[string]bool{
+	".mp3": true, ".m4a": true, ".aac": true, ".ogg": true, ".oga": true,
+	".opus": true, ".wav": true, ".flac": true, ".mp4": true, ".webm": true,
+}
+
+// Feed is a podcast's episodes. A URL pointing straight at an episode's
+// audio is a feed of that one episode, without a title.
+type Feed struct {
+	Title    string
+	Language string    // As the feed declares it, such as "en-us"; empty when it doesn't
+	Episodes []Episode // Newest first
+}
+
+// Episode is one podcast episode and where its audio is
+type Episode struct {
+	Title     string
+	AudioURL  string
+	AudioType string // MIME type the feed gives; empty when unknown
+	GUID      string
+	Link      string        // Episode page, when the feed has one
+	Published time.Time     // Zero when the feed doesn't date it
+	Duration  time.Duration // Zero when the feed doesn't say
+}
+
+// Client fetches podcast feeds and episode audio over HTTP
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a podcast client
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{}}
+}
+
+// ValidateURL checks that rawURL is an absolute http or https URL
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// Fetch reads the podcast at rawURL: an RSS feed, whose audio episodes are
+// returned newest first, or an episode's audio file itself
+func (c *Client) Fetch(ctx context.Context, rawURL string) (*Feed, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, feedTimeout)
+	defer cancel()
+
+	resp, err := c.get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Audio isn't read here; it's downloaded when the episode is transcribed
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if isAudio(mediaType, resp.Request.URL.Path) {
+		audioURL := resp.Request.URL.String() // After redirects
+		name := path.Base(resp.Request.URL.Path)
+		return &Feed{Episodes: []Episode{{
+			Title:     strings.TrimSuffix(name, path.Ext(name)),
+			AudioURL:  audioURL,
+			AudioType: mediaType,
+			GUID:      audioURL,
+		}}}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	return parseFeed(body)
+}
+
+// DownloadAudio downloads an episode's audio into dir and returns the file's
+// path. Files over MaxAudioBytes return ErrAudioTooLarge.
+func (c *Client) DownloadAudio(ctx context.Context, audioURL, dir string) (string, error) {
+	if err := ValidateURL(audioURL); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	resp, err := c.get(ctx, audioURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > MaxAudioBytes {
+		return "", ErrAudioTooLarge
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	file, err := os.Create(filepath.Join(dir, "audio"+audioExtension(mediaType, resp.Request.URL.Path)))
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	defer file.Close()
+
+	written, err := io.Copy(file, io.LimitReader(resp.Body, MaxAudioBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	if written > MaxAudioBytes {
+		return "", ErrAudioTooLarge
+	}
+
+	return file.Name(), nil
+}
+
+// get requests rawURL, returning an error for statuses other than 200
+func (c *Client) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: status %d", ErrFetchFailed, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// rssFeed is the part of an RSS 2.0 podcast feed that's read. Unqualified
+// tags match any namespace, so itunes:title is matched first to keep it out
+// of the RSS title.
+type rssFeed struct {
+	Channel *struct {
+		ITunesTitle string    `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+		Title       string    `xml:"title"`
+		Language    string    `xml:"language"`
+		Items       []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	ITunesTitle string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Duration    string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Enclosure   struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+// parseFeed reads an RSS feed's audio episodes, newest first. Items without
+// an audio enclosure, such as announcements, are skipped.
+func parseFeed(body []byte) (*Feed, error) {
+	var doc rssFeed
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil // Feeds declaring other charsets are nearly always UTF-8 anyway
+	}
+	if err := decoder.Decode(&doc); err != nil || doc.Channel == nil {
+		return nil, ErrNotPodcast
+	}
+
+	feed := &Feed{
+		Title:    firstNonEmpty(doc.Channel.Title, doc.Channel.ITunesTitle),
+		Language: strings.TrimSpace(doc.Channel.Language),
+		Episodes: []Episode{},
+	}
+	for _, item := range doc.Channel.Items {
+		audioURL := strings.TrimSpace(item.Enclosure.URL)
+		enclosureURL, err := url.Parse(audioURL)
+		if err != nil || ValidateURL(audioURL) != nil || !isAudio(item.Enclosure.Type, enclosureURL.Path) {
+			continue
+		}
+
+		episode := Episode{
+			Title:     firstNonEmpty(item.Title, item.ITunesTitle),
+			AudioURL:  audioURL,
+			AudioType: item.Enclosure.Type,
+			GUID:      strings.TrimSpace(item.GUID),
+			Link:      strings.TrimSpace(item.Link),
+			Published: parsePubDate(item.PubDate),
+			Duration:  parseDuration(item.Duration),
+		}
+		if episode.GUID == "" {
+			episode.GUID = audioURL
+		}
+		feed.Episodes = append(feed.Episodes, episode)
+	}
+	if len(feed.Episodes) == 0 {
+		return nil, ErrNoEpisodes
+	}
+
+	// Feeds are usually newest first already, but not all of them
+	sort.SliceStable(feed.Episodes, func(i, j int) bool {
+		return feed.Episodes[i].Published.After(feed.Episodes[j].Published)
+	})
+	return feed, nil
+}
+
+// firstNonEmpty returns the first of values that isn't blank, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// isAudio reports whether a response or enclosure is audio, by its media
+// type or, for servers that send a generic one, its path's extension
+func isAudio(mediaType, urlPath string) bool {
+	if strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/") {
+		return true
+	}
+	return audioExtensions[strings.ToLower(path.Ext(urlPath))]
+}
+
+// audioExtension picks the extension audio is saved with, which
+// transcription backends use to detect its format
+func audioExtension(mediaType, urlPath string) string {
+	if ext := strings.ToLower(path.Ext(urlPath)); audioExtensions[ext] {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".mp3"
+}
+
+// pubDateLayouts are the date formats found in feeds' pubDate, which RSS
+// specifies as RFC 822 but feeds write loosely
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+// parsePubDate parses an item's pubDate, returning the zero time when it
+// can't be read
+func parsePubDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range pubDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseDuration parses itunes:duration, given as seconds, MM:SS, or
+// HH:MM:SS, returning zero when it can't be read
+func parseDuration(value string) time.Duration {
+	var seconds float64
+	for _, part := range strings.Split(strings.TrimSpace(value), ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
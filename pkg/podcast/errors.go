@@ -0,0 +1,20 @@
+package podcast
+
+import "errors"
+
+var (
+	// ErrInvalidURL is returned for URLs that aren't http or https
+	ErrInvalidURL = errors.New("invalid podcast URL: must be an http or https URL")
+
+	// ErrNotPodcast is returned when a URL is neither an audio file nor an RSS feed
+	ErrNotPodcast = errors.New("URL is neither a podcast episode's audio nor an RSS feed")
+
+	// ErrNoEpisodes is returned for feeds without any audio enclosures
+	ErrNoEpisodes = errors.New("podcast feed has no audio episodes")
+
+	// ErrAudioTooLarge is returned for episodes over MaxAudioBytes
+	ErrAudioTooLarge = errors.New("podcast episode audio is larger than 500 MB")
+
+	// ErrFetchFailed is returned when the feed or audio can't be downloaded
+	ErrFetchFailed = errors.New("failed to fetch podcast")
+)
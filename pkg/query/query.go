@@ -0,0 +1,339 @@
+// Package query parses a small filter language for searching a knowledge
+// base, in the style of Dataview and search engines:
+//
+//	tag:golang AND mastery<3 AND created>2024-01-01
+//	(tag:go OR tag:rust) -pinned:true "borrow checker"
+//
+// Terms are field comparisons or bare words, combined with AND, OR, NOT (or
+// a leading "-") and parentheses. Adjacent terms are ANDed, and AND binds
+// tighter than OR. Which fields exist, and what their values mean, is left
+// to the caller.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidQuery is returned for queries that don't parse, and by callers
+// for queries naming fields or values they don't support
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Limits on a query's size, keeping the SQL it compiles to reasonable
+const (
+	MaxLength = 1000
+	MaxTerms  = 50
+	maxDepth  = 20
+)
+
+// Comparison operators. Colon is the loose match: contains for text, equals
+// otherwise.
+const (
+	OpMatch        = ":"
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// Node is a parsed query: an And, Or, Not, or Term
+type Node interface {
+	node()
+}
+
+// And matches when all of its nodes do
+type And []Node
+
+// Or matches when any of its nodes does
+type Or []Node
+
+// Not matches when its node doesn't
+type Not struct {
+	Node Node
+}
+
+// Term compares a field with a value. Field is empty for bare words, which
+// callers match against their text.
+type Term struct {
+	Field string
+	Op    string
+	Value string
+}
+
+func (And) node()  {}
+func (Or) node()   {}
+func (Not) node()  {}
+func (Term) node() {}
+
+// Parse parses a query
+func Parse(input string) (Node, error) {
+	if len(input) > MaxLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidQuery, MaxLength)
+	}
+
+	p := &parser{input: input}
+	n, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		// Only a stray closing parenthesis stops parseOr early
+		return nil, p.errorf("unexpected )")
+	}
+	if n == nil {
+		return nil, fmt.Errorf("%w: the query is empty", ErrInvalidQuery)
+	}
+	return n, nil
+}
+
+// parser is a recursive descent parser over a query's input
+type parser struct {
+	input string
+	pos   int
+	terms int
+}
+
+// parseOr parses terms separated by OR
+func (p *parser) parseOr(depth int) (Node, error) {
+	var nodes Or
+	for {
+		n, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			if len(nodes) > 0 {
+				return nil, p.errorf("expected a term after OR")
+			}
+			return nil, nil
+		}
+		nodes = append(nodes, n)
+		if !p.keyword("OR") {
+			break
+		}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// parseAnd parses terms separated by AND or nothing, stopping before OR, a
+// closing parenthesis, or the end
+func (p *parser) parseAnd(depth int) (Node, error) {
+	var nodes And
+	for {
+		explicit := p.keyword("AND")
+		p.skipSpace()
+		if p.pos == len(p.input) || p.input[p.pos] == ')' || p.peekKeyword("OR") {
+			if explicit || (len(nodes) == 0 && p.peekKeyword("OR")) {
+				return nil, p.errorf("expected a term")
+			}
+			break
+		}
+		if explicit && len(nodes) == 0 {
+			return nil, p.errorf("expected a term before AND")
+		}
+		n, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// parseNot parses a term, parenthesized query, or either negated
+func (p *parser) parseNot(depth int) (Node, error) {
+	if depth > maxDepth {
+		return nil, p.errorf("nested too deeply")
+	}
+
+	p.skipSpace()
+	if p.pos == len(p.input) || p.input[p.pos] == ')' {
+		return nil, p.errorf("expected a term")
+	}
+	if p.keyword("NOT") {
+		n, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: n}, nil
+	}
+	if strings.HasPrefix(p.input[p.pos:], "-") && p.pos+1 < len(p.input) && !isSpace(p.input[p.pos+1]) {
+		p.pos++
+		n, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: n}, nil
+	}
+
+	if p.input[p.pos] == '(' {
+		p.pos++
+		n, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.input) || p.input[p.pos] != ')' {
+			return nil, p.errorf("expected )")
+		}
+		if n == nil {
+			return nil, p.errorf("empty parentheses")
+		}
+		p.pos++
+		return n, nil
+	}
+
+	return p.parseTerm()
+}
+
+// parseTerm parses a comparison such as mastery<3, or a bare word or
+// quoted phrase
+func (p *parser) parseTerm() (Node, error) {
+	p.terms++
+	if p.terms > MaxTerms {
+		return nil, p.errorf("more than %d terms", MaxTerms)
+	}
+
+	start := p.pos
+	if field := p.field(); field != "" {
+		afterField := p.pos
+		p.skipSpace()
+		if op := p.operator(); op != "" {
+			p.skipSpace()
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			return Term{Field: strings.ToLower(field), Op: op, Value: value}, nil
+		}
+		p.pos = afterField
+	}
+
+	p.pos = start
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	return Term{Op: OpMatch, Value: value}, nil
+}
+
+// field consumes a field name: letters, digits, and underscores, starting
+// with a letter
+func (p *parser) field() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		ch := p.input[p.pos]
+		if isLetter(ch) || ch == '_' || (p.pos > start && isDigit(ch)) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+// operator consumes a comparison operator, or returns "" at something else
+func (p *parser) operator() string {
+	for _, op := range []string{OpNotEqual, OpLessEqual, OpGreaterEqual, OpMatch, OpEqual, OpLess, OpGreater} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// value consumes a quoted string, with \" and \\ escapes, or a run of
+// characters up to a space or parenthesis
+func (p *parser) value() (string, error) {
+	if p.pos == len(p.input) {
+		return "", p.errorf("expected a value")
+	}
+
+	if p.input[p.pos] == '"' {
+		p.pos++
+		var b strings.Builder
+		for p.pos < len(p.input) {
+			ch := p.input[p.pos]
+			switch {
+			case ch == '"':
+				p.pos++
+				return b.String(), nil
+			case ch == '\\' && p.pos+1 < len(p.input):
+				b.WriteByte(p.input[p.pos+1])
+				p.pos += 2
+			default:
+				b.WriteByte(ch)
+				p.pos++
+			}
+		}
+		return "", p.errorf("unterminated quote")
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !isSpace(p.input[p.pos]) && p.input[p.pos] != '(' && p.input[p.pos] != ')' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a value")
+	}
+	return p.input[start:p.pos], nil
+}
+
+// keyword consumes the uppercase keyword word, when it's next as a whole word
+func (p *parser) keyword(word string) bool {
+	if !p.peekKeyword(word) {
+		return false
+	}
+	p.skipSpace()
+	p.pos += len(word)
+	return true
+}
+
+// peekKeyword reports whether the uppercase keyword word is next as a whole word
+func (p *parser) peekKeyword(word string) bool {
+	pos := p.pos
+	for pos < len(p.input) && isSpace(p.input[pos]) {
+		pos++
+	}
+	if !strings.HasPrefix(p.input[pos:], word) {
+		return false
+	}
+	end := pos + len(word)
+	return end == len(p.input) || isSpace(p.input[end]) || p.input[end] == '(' || p.input[end] == ')'
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidQuery, fmt.Sprintf(format, args...), p.pos+1)
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,178 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the OpenAI API, whose transcription endpoint Whisper
+	// servers such as faster-whisper-server and Groq also implement
+	DefaultBaseURL = "https://api.openai.com/v1"
+
+	// TranscriptionsEndpoint is the endpoint for transcribing audio
+	TranscriptionsEndpoint = "/audio/transcriptions"
+
+	// DefaultModel is the default transcription model
+	DefaultModel = "whisper-1"
+
+	// DefaultTimeout is the default request timeout; an hour of audio can take minutes
+	DefaultTimeout = 10 * time.Minute
+)
+
+// Client transcribes audio with an OpenAI-compatible speech-to-text API
+type Client struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Transcription is the text heard in an audio file
+type Transcription struct {
+	Text     string `json:"text"`
+	Language string `json:"language"` // ISO 639-1 code; empty when the backend doesn't report one it knows
+}
+
+// NewClient creates a client from STT_API_URL (defaults to OpenAI),
+// STT_API_KEY, and STT_MODEL (defaults to whisper-1). It returns
+// ErrNotConfigured when neither the URL nor the key is set.
+func NewClient() (*Client, error) {
+	apiKey := os.Getenv("STT_API_KEY")
+	baseURL := os.Getenv("STT_API_URL")
+	if apiKey == "" && baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	model := os.Getenv("STT_MODEL")
+	if model == "" {
+		model = DefaultModel
+	}
+
+	return &Client{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+	}, nil
+}
+
+// Transcribe sends an audio file to the backend. A non-empty language (ISO
+// 639-1) skips the backend's own detection.
+func (c *Client) Transcribe(ctx context.Context, filename string, audio io.Reader, language string) (*Transcription, error) {
+	// Stream the multipart body rather than buffering the whole file
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeForm(form, filename, audio, map[string]string{
+			"model":           c.model,
+			"response_format": "verbose_json",
+			"language":        language,
+		}))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+TranscriptionsEndpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAPIError, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrAPIError, resp.StatusCode, string(respBody))
+	}
+
+	var result Transcription
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	result.Text = strings.TrimSpace(result.Text)
+	if result.Text == "" {
+		return nil, ErrEmptyTranscription
+	}
+
+	// Whisper names the language it detected ("english"); callers want a code
+	if language != "" {
+		result.Language = language
+	} else {
+		result.Language = languageCode(result.Language)
+	}
+
+	return &result, nil
+}
+
+// writeForm writes the audio file and the non-empty fields as a multipart form
+func writeForm(form *multipart.Writer, filename string, audio io.Reader, fields map[string]string) error {
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
+
+// whisperLanguages maps the language names Whisper reports to ISO 639-1 codes
+var whisperLanguages = map[string]string{
+	"arabic": "ar", "bengali": "bn", "bulgarian": "bg", "catalan": "ca",
+	"chinese": "zh", "croatian": "hr", "czech": "cs", "danish": "da",
+	"dutch": "nl", "english": "en", "estonian": "et", "finnish": "fi",
+	"french": "fr", "german": "de", "greek": "el", "hebrew": "he",
+	"hindi": "hi", "hungarian": "hu", "indonesian": "id", "italian": "it",
+	"japanese": "ja", "korean": "ko", "latvian": "lv", "lithuanian": "lt",
+	"malay": "ms", "norwegian": "no", "persian": "fa", "polish": "pl",
+	"portuguese": "pt", "romanian": "ro", "russian": "ru", "serbian": "sr",
+	"slovak": "sk", "slovenian": "sl", "spanish": "es", "swahili": "sw",
+	"swedish": "sv", "tagalog": "tl", "tamil": "ta", "thai": "th",
+	"turkish": "tr", "ukrainian": "uk", "urdu": "ur", "vietnamese": "vi",
+}
+
+// languageCode converts a reported language to an ISO 639-1 code. Backends
+// that already report codes pass through; unknown names yield "".
+func languageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, ok := whisperLanguages[language]; ok {
+		return code
+	}
+	if len(language) == 2 {
+		return language
+	}
+	return ""
+}
@@ -0,0 +1,14 @@
+package speech
+
+import "errors"
+
+var (
+	// ErrNotConfigured is returned when no speech-to-text backend is configured
+	ErrNotConfigured = errors.New("speech-to-text is not configured - set STT_API_KEY or STT_API_URL")
+
+	// ErrAPIError is returned for speech-to-text API errors
+	ErrAPIError = errors.New("speech-to-text API error")
+
+	// ErrEmptyTranscription is returned when the backend hears no speech
+	ErrEmptyTranscription = errors.New("speech-to-text returned no speech")
+)
@@ -0,0 +1,131 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWhisperCPPNotConfigured is returned when WHISPER_CPP_MODEL isn't set
+	ErrWhisperCPPNotConfigured = errors.New("whisper.cpp is not configured - set WHISPER_CPP_MODEL to a ggml model file")
+
+	// ErrWhisperCPPNotFound is returned when whisper.cpp or ffmpeg isn't installed
+	ErrWhisperCPPNotFound = errors.New("whisper.cpp needs whisper-cli and ffmpeg - install them or set WHISPER_CPP_PATH and FFMPEG_PATH")
+)
+
+// whisperCPPTimeout bounds one transcription; whisper.cpp on a CPU can take
+// about as long as the audio runs
+const whisperCPPTimeout = 4 * time.Hour
+
+// detectedLanguagePattern matches the language whisper.cpp reports detecting
+var detectedLanguagePattern = regexp.MustCompile(`auto-detected language: ([a-z]{2,3})\b`)
+
+// WhisperCPP transcribes audio locally with whisper.cpp's command-line tool,
+// after converting it to the 16 kHz WAV it reads with ffmpeg
+type WhisperCPP struct {
+	binaryPath string
+	modelPath  string
+	ffmpegPath string
+}
+
+// NewWhisperCPP creates a local transcriber from WHISPER_CPP_MODEL (a ggml
+// model file, required), WHISPER_CPP_PATH (defaults to whisper-cli on the
+// PATH), and FFMPEG_PATH (defaults to ffmpeg on the PATH)
+func NewWhisperCPP() (*WhisperCPP, error) {
+	model := os.Getenv("WHISPER_CPP_MODEL")
+	if model == "" {
+		return nil, ErrWhisperCPPNotConfigured
+	}
+	if _, err := os.Stat(model); err != nil {
+		return nil, fmt.Errorf("invalid WHISPER_CPP_MODEL: %w", err)
+	}
+
+	binary, err := lookPath(os.Getenv("WHISPER_CPP_PATH"), "whisper-cli")
+	if err != nil {
+		return nil, err
+	}
+	ffmpeg, err := lookPath(os.Getenv("FFMPEG_PATH"), "ffmpeg")
+	if err != nil {
+		return nil, err
+	}
+
+	return &WhisperCPP{binaryPath: binary, modelPath: model, ffmpegPath: ffmpeg}, nil
+}
+
+// lookPath resolves a configured command, or name on the PATH when none is set
+func lookPath(configured, name string) (string, error) {
+	if configured == "" {
+		configured = name
+	}
+	path, err := exec.LookPath(configured)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrWhisperCPPNotFound, err)
+	}
+	return path, nil
+}
+
+// Transcribe transcribes the audio file at path. A non-empty language (ISO
+// 639-1) skips whisper.cpp's own detection.
+func (w *WhisperCPP) Transcribe(ctx context.Context, path, language string) (*Transcription, error) {
+	dir, err := os.MkdirTemp("", "lattice-whisper-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(ctx, whisperCPPTimeout)
+	defer cancel()
+
+	wav := filepath.Join(dir, "audio.wav")
+	if _, err := run(ctx, w.ffmpegPath, "-nostdin", "-y", "-i", path, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav); err != nil {
+		return nil, fmt.Errorf("failed to convert audio: %w", err)
+	}
+
+	if language == "" {
+		language = "auto"
+	}
+	out := filepath.Join(dir, "transcript")
+	stderr, err := run(ctx, w.binaryPath, "-m", w.modelPath, "-f", wav, "-l", language, "-otxt", "-of", out)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAPIError, err)
+	}
+
+	text, err := os.ReadFile(out + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no transcript was written", ErrAPIError)
+	}
+
+	result := &Transcription{Text: strings.Join(strings.Fields(string(text)), " ")}
+	if result.Text == "" {
+		return nil, ErrEmptyTranscription
+	}
+
+	if language != "auto" {
+		result.Language = language
+	} else if match := detectedLanguagePattern.FindStringSubmatch(stderr); match != nil {
+		result.Language = languageCode(match[1])
+	}
+
+	return result, nil
+}
+
+// run runs a command, returning its stderr, which carries the error on failure
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", filepath.Base(name), err, strings.TrimSpace(stderr.String()))
+	}
+	return stderr.String(), nil
+}
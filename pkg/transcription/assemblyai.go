@@ -0,0 +1,140 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AssemblyAI API settings
+const (
+	assemblyAIAPIURL       = "https://api.assemblyai.com/v2"
+	assemblyAIPollInterval = 3 * time.Second
+)
+
+// assemblyAITranscript is an AssemblyAI transcript, as fetched by ID
+type assemblyAITranscript struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	Error        string `json:"error"`
+	Text         string `json:"text"`
+	LanguageCode string `json:"language_code"`
+	Utterances   []struct {
+		Speaker string  `json:"speaker"`
+		Start   float64 `json:"start"` // Milliseconds, as are the other times
+		Text    string  `json:"text"`
+	} `json:"utterances"`
+	Words []struct {
+		Text    string  `json:"text"`
+		Start   float64 `json:"start"`
+		End     float64 `json:"end"`
+		Speaker *string `json:"speaker"`
+	} `json:"words"`
+	Chapters []struct {
+		Headline string  `json:"headline"`
+		Summary  string  `json:"summary"`
+		Start    float64 `json:"start"`
+		End      float64 `json:"end"`
+	} `json:"chapters"`
+}
+
+// result converts a completed transcript, attributing speakers when it was diarized
+func (t *assemblyAITranscript) result() (*Result, error) {
+	if t.Status == "error" {
+		return nil, fmt.Errorf("%w: %s", ErrJobFailed, t.Error)
+	}
+
+	result := newResult(t.LanguageCode)
+	for _, u := range t.Utterances {
+		result.addUtterance(assemblyAISpeaker(&u.Speaker), u.Start/1000, u.Text)
+	}
+	if len(t.Utterances) == 0 {
+		result.addUtterance("", 0, t.Text)
+	}
+	for _, w := range t.Words {
+		result.Words = append(result.Words, Word{Text: w.Text, Start: w.Start / 1000, End: w.End / 1000, Speaker: assemblyAISpeaker(w.Speaker)})
+	}
+	for _, c := range t.Chapters {
+		result.Chapters = append(result.Chapters, Chapter{Headline: c.Headline, Summary: c.Summary, Start: c.Start / 1000, End: c.End / 1000})
+	}
+
+	return result, nil
+}
+
+// assemblyAISpeaker labels an AssemblyAI speaker ("A") as "Speaker A"
+func assemblyAISpeaker(speaker *string) string {
+	if speaker == nil || *speaker == "" {
+		return ""
+	}
+	return "Speaker " + *speaker
+}
+
+// fetchAssemblyAI fetches an AssemblyAI transcript by ID
+func fetchAssemblyAI(ctx context.Context, apiKey, id string) (*assemblyAITranscript, error) {
+	req, err := newRequest(ctx, http.MethodGet, assemblyAIAPIURL+"/transcript/"+url.PathEscape(id), apiKey, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var transcript assemblyAITranscript
+	if err := doJSON(httpClient, req, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to get assemblyai transcript: %w", err)
+	}
+	return &transcript, nil
+}
+
+// transcribeAssemblyAI uploads audio to AssemblyAI, requests a transcript
+// with speaker labels and chapters, and polls until it's done
+func (c *Client) transcribeAssemblyAI(ctx context.Context, audio io.Reader, language string) (*Result, error) {
+	req, err := newRequest(ctx, http.MethodPost, assemblyAIAPIURL+"/upload", c.apiKey, audio, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+
+	var upload struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := doJSON(c.httpClient, req, &upload); err != nil {
+		return nil, fmt.Errorf("failed to upload audio to assemblyai: %w", err)
+	}
+
+	request := map[string]any{
+		"audio_url":      upload.UploadURL,
+		"speaker_labels": true,
+		"auto_chapters":  true,
+	}
+	if language != "" {
+		request["language_code"] = language
+	} else {
+		request["language_detection"] = true
+	}
+	if c.model != "" {
+		request["speech_model"] = c.model
+	}
+
+	var transcript assemblyAITranscript
+	if err := postJSON(ctx, c.httpClient, assemblyAIAPIURL+"/transcript", c.apiKey, request, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to request assemblyai transcript: %w", err)
+	}
+
+	ticker := time.NewTicker(assemblyAIPollInterval)
+	defer ticker.Stop()
+	for transcript.Status != "completed" && transcript.Status != "error" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		polled, err := fetchAssemblyAI(ctx, c.apiKey, transcript.ID)
+		if err != nil {
+			return nil, err
+		}
+		transcript = *polled
+	}
+
+	return transcript.result()
+}
@@ -0,0 +1,274 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mostlyerror/lattice/pkg/meeting"
+)
+
+// Providers
+const (
+	AssemblyAI = "assemblyai"
+	Deepgram   = "deepgram"
+	Rev        = "rev" // Rev AI
+)
+
+// DefaultTimeout bounds a transcription, including AssemblyAI's polling; an
+// hour of audio can take minutes
+const DefaultTimeout = 30 * time.Minute
+
+// httpClient fetches transcripts from the providers
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
+// Word is one word of a transcript, timed in seconds from the start
+type Word struct {
+	Text    string
+	Start   float64
+	End     float64
+	Speaker string // "Speaker A"; empty unless the provider labeled speakers
+}
+
+// Chapter is a section of a transcript the provider detected
+type Chapter struct {
+	Headline string
+	Summary  string
+	Start    float64 // Seconds
+	End      float64
+}
+
+// Result is a finished transcription: the transcript attributed by speaker,
+// and the word timings and chapters of providers that return them
+type Result struct {
+	Transcript *meeting.Transcript
+	Words      []Word
+	Chapters   []Chapter
+}
+
+// newResult creates an empty result in language
+func newResult(language string) *Result {
+	return &Result{Transcript: &meeting.Transcript{Language: language}}
+}
+
+// addUtterance appends a turn starting at start seconds, skipping empty ones
+func (r *Result) addUtterance(speaker string, start float64, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	r.Transcript.Utterances = append(r.Transcript.Utterances, meeting.Utterance{
+		Speaker: speaker,
+		Start:   time.Duration(start * float64(time.Second)),
+		Text:    text,
+	})
+}
+
+// Client transcribes audio files with AssemblyAI or Deepgram
+type Client struct {
+	provider   string
+	apiKey     string
+	model      string // The provider's default when empty
+	httpClient *http.Client
+}
+
+// NewClient creates a client for provider, AssemblyAI or Deepgram. model
+// picks AssemblyAI's speech model or Deepgram's model (default nova-3).
+func NewClient(provider, apiKey, model string) (*Client, error) {
+	if provider != AssemblyAI && provider != Deepgram {
+		return nil, fmt.Errorf("unknown transcription provider %q", provider)
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s needs an API key", provider)
+	}
+
+	return &Client{
+		provider:   provider,
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}, nil
+}
+
+// Transcribe sends an audio file to the provider, with speaker labels and,
+// for AssemblyAI, chapters. A non-empty language (ISO 639-1) skips the
+// provider's own detection.
+func (c *Client) Transcribe(ctx context.Context, filename string, audio io.Reader, language string) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var result *Result
+	var err error
+	switch c.provider {
+	case AssemblyAI:
+		result, err = c.transcribeAssemblyAI(ctx, audio, language)
+	default:
+		result, err = c.transcribeDeepgram(ctx, filename, audio, language)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Transcript.Utterances) == 0 {
+		return nil, ErrNoTranscript
+	}
+	return result, nil
+}
+
+// Callback is a provider's notice that a transcription job finished
+type Callback struct {
+	JobID    string
+	Language string  // BCP 47, when the provider reports it
+	Result   *Result // Set when the callback carries the transcript; otherwise fetch it
+}
+
+// NeedsAPIKey reports whether a provider's callbacks only name the job, so
+// its transcript has to be fetched with an API key
+func NeedsAPIKey(provider string) bool {
+	return provider == AssemblyAI || provider == Rev
+}
+
+// ParseCallback parses the body a provider POSTs when a job finishes. Jobs
+// the provider reports as failed return ErrJobFailed with its reason.
+func ParseCallback(provider string, body []byte) (*Callback, error) {
+	switch provider {
+	case AssemblyAI:
+		var payload struct {
+			TranscriptID string `json:"transcript_id"`
+			Status       string `json:"status"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil || payload.TranscriptID == "" {
+			return nil, fmt.Errorf("%w: assemblyai sends transcript_id and status", ErrInvalidCallback)
+		}
+		if payload.Status != "completed" {
+			return &Callback{JobID: payload.TranscriptID}, fmt.Errorf("%w: status %s", ErrJobFailed, payload.Status)
+		}
+		return &Callback{JobID: payload.TranscriptID}, nil
+
+	case Deepgram:
+		var payload deepgramResponse
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+		}
+		callback := &Callback{JobID: payload.Metadata.RequestID}
+		result, err := payload.result()
+		if err != nil {
+			return callback, err
+		}
+		callback.Language = result.Transcript.Language
+		callback.Result = result
+		return callback, nil
+
+	case Rev:
+		var payload struct {
+			Job struct {
+				ID            string `json:"id"`
+				Status        string `json:"status"`
+				FailureDetail string `json:"failure_detail"`
+				Language      string `json:"language"`
+			} `json:"job"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Job.ID == "" {
+			return nil, fmt.Errorf("%w: rev sends a job with an id and status", ErrInvalidCallback)
+		}
+		callback := &Callback{JobID: payload.Job.ID, Language: payload.Job.Language}
+		if payload.Job.Status != "transcribed" {
+			reason := payload.Job.FailureDetail
+			if reason == "" {
+				reason = "status " + payload.Job.Status
+			}
+			return callback, fmt.Errorf("%w: %s", ErrJobFailed, reason)
+		}
+		return callback, nil
+
+	default:
+		return nil, fmt.Errorf("unknown transcription provider %q", provider)
+	}
+}
+
+// FetchTranscript fetches a finished job's transcript from a provider whose
+// callbacks don't carry it
+func FetchTranscript(ctx context.Context, provider, apiKey, jobID string) (*Result, error) {
+	var result *Result
+	switch provider {
+	case AssemblyAI:
+		transcript, err := fetchAssemblyAI(ctx, apiKey, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = transcript.result(); err != nil {
+			return nil, err
+		}
+	case Rev:
+		transcript, err := fetchRev(ctx, apiKey, jobID)
+		if err != nil {
+			return nil, err
+		}
+		result = transcript.result()
+	default:
+		return nil, fmt.Errorf("%s callbacks carry their transcripts", provider)
+	}
+
+	if len(result.Transcript.Utterances) == 0 {
+		return nil, ErrNoTranscript
+	}
+	return result, nil
+}
+
+// newRequest creates a provider API request with an Authorization header
+func newRequest(ctx context.Context, method, rawURL, authorization string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// postJSON POSTs payload as JSON and decodes the response
+func postJSON(ctx context.Context, client *http.Client, rawURL, authorization string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := newRequest(ctx, http.MethodPost, rawURL, authorization, bytes.NewReader(body), "application/json")
+	if err != nil {
+		return err
+	}
+	return doJSON(client, req, out)
+}
+
+// doJSON sends a request and decodes the JSON body of a 2xx response
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w (status %d)", ErrUnauthorized, resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+)
+
+// Deepgram API settings
+const (
+	deepgramAPIURL       = "https://api.deepgram.com/v1"
+	deepgramDefaultModel = "nova-3"
+)
+
+// deepgramResponse is a Deepgram pre-recorded transcription response, which
+// is also the body of its callbacks
+type deepgramResponse struct {
+	ErrMsg   string `json:"err_msg"`
+	Metadata struct {
+		RequestID string `json:"request_id"`
+	} `json:"metadata"`
+	Results *struct {
+		Channels []struct {
+			Alternatives []struct {
+				Transcript string `json:"transcript"`
+				Words      []struct {
+					Word           string  `json:"word"`
+					PunctuatedWord string  `json:"punctuated_word"`
+					Start          float64 `json:"start"`
+					End            float64 `json:"end"`
+					Speaker        *int    `json:"speaker"`
+				} `json:"words"`
+			} `json:"alternatives"`
+			DetectedLanguage string `json:"detected_language"`
+		} `json:"channels"`
+		Utterances []struct {
+			Start      float64 `json:"start"`
+			Speaker    *int    `json:"speaker"`
+			Transcript string  `json:"transcript"`
+		} `json:"utterances"`
+	} `json:"results"`
+}
+
+// result converts a response, attributing speakers when it was diarized.
+// Deepgram doesn't detect chapters.
+func (r *deepgramResponse) result() (*Result, error) {
+	if r.ErrMsg != "" {
+		return nil, fmt.Errorf("%w: %s", ErrJobFailed, r.ErrMsg)
+	}
+	if r.Results == nil || len(r.Results.Channels) == 0 {
+		return nil, fmt.Errorf("%w: deepgram sends results.channels", ErrInvalidCallback)
+	}
+
+	channel := r.Results.Channels[0]
+	result := newResult(channel.DetectedLanguage)
+	for _, u := range r.Results.Utterances {
+		result.addUtterance(deepgramSpeaker(u.Speaker), u.Start, u.Transcript)
+	}
+	if len(channel.Alternatives) == 0 {
+		return result, nil
+	}
+
+	alternative := channel.Alternatives[0]
+	if len(r.Results.Utterances) == 0 {
+		result.addUtterance("", 0, alternative.Transcript)
+	}
+	for _, w := range alternative.Words {
+		text := w.PunctuatedWord
+		if text == "" {
+			text = w.Word
+		}
+		result.Words = append(result.Words, Word{Text: text, Start: w.Start, End: w.End, Speaker: deepgramSpeaker(w.Speaker)})
+	}
+
+	return result, nil
+}
+
+// deepgramSpeaker labels a Deepgram speaker (0) as "Speaker 0"
+func deepgramSpeaker(speaker *int) string {
+	if speaker == nil {
+		return ""
+	}
+	return "Speaker " + strconv.Itoa(*speaker)
+}
+
+// transcribeDeepgram sends audio to Deepgram with diarization and smart
+// formatting on, and waits for the response
+func (c *Client) transcribeDeepgram(ctx context.Context, filename string, audio io.Reader, language string) (*Result, error) {
+	model := c.model
+	if model == "" {
+		model = deepgramDefaultModel
+	}
+
+	query := url.Values{
+		"model":        {model},
+		"smart_format": {"true"},
+		"diarize":      {"true"},
+		"utterances":   {"true"},
+	}
+	if language != "" {
+		query.Set("language", language)
+	} else {
+		query.Set("detect_language", "true")
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	req, err := newRequest(ctx, http.MethodPost, deepgramAPIURL+"/listen?"+query.Encode(), "Token "+c.apiKey, audio, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var response deepgramResponse
+	if err := doJSON(c.httpClient, req, &response); err != nil {
+		return nil, fmt.Errorf("failed to transcribe with deepgram: %w", err)
+	}
+
+	return response.result()
+}
@@ -0,0 +1,17 @@
+package transcription
+
+import "errors"
+
+var (
+	// ErrInvalidCallback is returned for callback bodies that aren't in the provider's format
+	ErrInvalidCallback = errors.New("invalid transcription callback")
+
+	// ErrJobFailed is returned when the provider reports that the job failed
+	ErrJobFailed = errors.New("transcription job failed")
+
+	// ErrNoTranscript is returned when a completed job heard no speech
+	ErrNoTranscript = errors.New("transcription job returned no speech")
+
+	// ErrUnauthorized is returned when the provider rejects the API key
+	ErrUnauthorized = errors.New("transcription provider rejected the API key")
+)
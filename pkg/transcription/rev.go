@@ -0,0 +1,64 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// revAPIURL is the Rev AI asynchronous speech-to-text API
+const revAPIURL = "https://api.rev.ai/speechtotext/v1"
+
+// revTranscript is a Rev AI transcript in its JSON format
+type revTranscript struct {
+	Monologues []struct {
+		Speaker  int `json:"speaker"`
+		Elements []struct {
+			Type  string   `json:"type"` // text or punct
+			Value string   `json:"value"`
+			TS    *float64 `json:"ts"`
+			EndTS *float64 `json:"end_ts"`
+		} `json:"elements"`
+	} `json:"monologues"`
+}
+
+// result converts a transcript, one utterance per monologue. Rev AI doesn't
+// detect chapters.
+func (t *revTranscript) result() *Result {
+	result := newResult("")
+	for _, m := range t.Monologues {
+		speaker := "Speaker " + strconv.Itoa(m.Speaker)
+
+		var text strings.Builder
+		var start float64
+		for _, e := range m.Elements {
+			if e.Type == "text" && e.TS != nil && e.EndTS != nil {
+				if text.Len() == 0 {
+					start = *e.TS
+				}
+				result.Words = append(result.Words, Word{Text: e.Value, Start: *e.TS, End: *e.EndTS, Speaker: speaker})
+			}
+			text.WriteString(e.Value) // Punctuation elements carry the spaces between words
+		}
+		result.addUtterance(speaker, start, text.String())
+	}
+	return result
+}
+
+// fetchRev fetches a Rev AI job's transcript
+func fetchRev(ctx context.Context, apiKey, jobID string) (*revTranscript, error) {
+	req, err := newRequest(ctx, http.MethodGet, revAPIURL+"/jobs/"+url.PathEscape(jobID)+"/transcript", "Bearer "+apiKey, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.rev.transcript.v1.0+json")
+
+	var transcript revTranscript
+	if err := doJSON(httpClient, req, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to get rev transcript: %w", err)
+	}
+	return &transcript, nil
+}
@@ -0,0 +1,243 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Providers
+const (
+	OpenAI     = "openai" // Any OpenAI-compatible speech API
+	ElevenLabs = "elevenlabs"
+)
+
+const (
+	// DefaultBaseURL is the OpenAI API
+	DefaultBaseURL = "https://api.openai.com/v1"
+
+	// SpeechEndpoint is the OpenAI endpoint for generating speech
+	SpeechEndpoint = "/audio/speech"
+
+	// DefaultTimeout bounds one request; each renders at most maxChunkChars
+	DefaultTimeout = 3 * time.Minute
+
+	// ContentType is the format audio is rendered in
+	ContentType = "audio/mpeg"
+)
+
+// Provider defaults
+const (
+	openAIDefaultModel     = "tts-1"
+	openAIDefaultVoice     = "alloy"
+	elevenLabsAPIURL       = "https://api.elevenlabs.io/v1"
+	elevenLabsDefaultModel = "eleven_multilingual_v2"
+	elevenLabsDefaultVoice = "21m00Tcm4TlvDq8ikWAM" // Rachel, a premade voice
+)
+
+// maxChunkChars stays under OpenAI's 4096-character input limit, and
+// ElevenLabs' per-request limit
+const maxChunkChars = 4000
+
+// Client renders text to speech with an OpenAI-compatible API or ElevenLabs
+type Client struct {
+	provider   string
+	apiKey     string
+	model      string
+	voice      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client from TTS_PROVIDER (openai, the default, or
+// elevenlabs), TTS_API_KEY, TTS_API_URL (an OpenAI-compatible base URL),
+// TTS_MODEL, and TTS_VOICE. It returns ErrNotConfigured when neither the key
+// nor the URL is set.
+func NewClient() (*Client, error) {
+	provider := strings.ToLower(os.Getenv("TTS_PROVIDER"))
+	if provider == "" {
+		provider = OpenAI
+	}
+
+	c := &Client{
+		provider:   provider,
+		apiKey:     os.Getenv("TTS_API_KEY"),
+		model:      os.Getenv("TTS_MODEL"),
+		voice:      os.Getenv("TTS_VOICE"),
+		baseURL:    strings.TrimSuffix(os.Getenv("TTS_API_URL"), "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+
+	switch provider {
+	case OpenAI:
+		if c.apiKey == "" && c.baseURL == "" {
+			return nil, ErrNotConfigured
+		}
+		if c.baseURL == "" {
+			c.baseURL = DefaultBaseURL
+		}
+		if c.model == "" {
+			c.model = openAIDefaultModel
+		}
+		if c.voice == "" {
+			c.voice = openAIDefaultVoice
+		}
+	case ElevenLabs:
+		if c.apiKey == "" {
+			return nil, ErrNotConfigured
+		}
+		if c.baseURL == "" {
+			c.baseURL = elevenLabsAPIURL
+		}
+		if c.model == "" {
+			c.model = elevenLabsDefaultModel
+		}
+		if c.voice == "" {
+			c.voice = elevenLabsDefaultVoice
+		}
+	default:
+		return nil, fmt.Errorf("unknown text-to-speech provider %q", provider)
+	}
+
+	return c, nil
+}
+
+// Provider returns the provider the client renders with
+func (c *Client) Provider() string {
+	return c.provider
+}
+
+// Voice returns the voice the client renders with
+func (c *Client) Voice() string {
+	return c.voice
+}
+
+// Synthesize renders text as MP3. Text longer than one request allows is
+// rendered in parts split between sentences, whose MP3 frames play back
+// as one file.
+func (c *Client) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	var audio bytes.Buffer
+	for _, chunk := range splitText(text, maxChunkChars) {
+		var part []byte
+		var err error
+		if c.provider == ElevenLabs {
+			part, err = c.synthesizeElevenLabs(ctx, chunk)
+		} else {
+			part, err = c.synthesizeOpenAI(ctx, chunk)
+		}
+		if err != nil {
+			return nil, err
+		}
+		audio.Write(part)
+	}
+	return audio.Bytes(), nil
+}
+
+// synthesizeOpenAI renders one chunk with an OpenAI-compatible speech API
+func (c *Client) synthesizeOpenAI(ctx context.Context, text string) ([]byte, error) {
+	payload := map[string]string{
+		"model":           c.model,
+		"voice":           c.voice,
+		"input":           text,
+		"response_format": "mp3",
+	}
+
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return c.post(ctx, c.baseURL+SpeechEndpoint, header, payload)
+}
+
+// synthesizeElevenLabs renders one chunk with ElevenLabs
+func (c *Client) synthesizeElevenLabs(ctx context.Context, text string) ([]byte, error) {
+	payload := map[string]string{
+		"text":     text,
+		"model_id": c.model,
+	}
+
+	header := http.Header{}
+	header.Set("xi-api-key", c.apiKey)
+	endpoint := c.baseURL + "/text-to-speech/" + url.PathEscape(c.voice) + "?output_format=mp3_44100_128"
+	return c.post(ctx, endpoint, header, payload)
+}
+
+// post sends payload as JSON and returns the audio in the response
+func (c *Client) post(ctx context.Context, endpoint string, header http.Header, payload any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", ContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAPIError, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w (status %d)", ErrUnauthorized, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: status %d: %s", ErrAPIError, resp.StatusCode, string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// splitText splits text into chunks of at most limit bytes, breaking after
+// sentences where it can and between words otherwise
+func splitText(text string, limit int) []string {
+	var chunks []string
+	for text = strings.TrimSpace(text); len(text) > limit; text = strings.TrimSpace(text) {
+		cut := sentenceBreak(text[:limit])
+		if cut <= 0 {
+			cut = strings.LastIndexFunc(text[:limit], unicode.IsSpace)
+		}
+		if cut <= 0 {
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(text[:cut]))
+		text = text[cut:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// sentenceBreak returns the index just past the last sentence end in text,
+// or -1 when it has none
+func sentenceBreak(text string) int {
+	for i := len(text) - 1; i > 0; i-- {
+		if unicode.IsSpace(rune(text[i])) && strings.ContainsRune(".!?\n", rune(text[i-1])) {
+			return i
+		}
+	}
+	return -1
+}
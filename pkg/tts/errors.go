@@ -0,0 +1,14 @@
+package tts
+
+import "errors"
+
+var (
+	// ErrNotConfigured is returned when no text-to-speech provider is configured
+	ErrNotConfigured = errors.New("text-to-speech is not configured - set TTS_API_KEY or TTS_API_URL")
+
+	// ErrAPIError is returned for text-to-speech API errors
+	ErrAPIError = errors.New("text-to-speech API error")
+
+	// ErrUnauthorized is returned when the provider rejects the API key
+	ErrUnauthorized = errors.New("text-to-speech provider rejected the API key")
+)
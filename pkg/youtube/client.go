@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -72,8 +74,9 @@ func ValidateURL(url string) error {
 	return ErrInvalidURL
 }
 
-// GetTranscript fetches and parses the transcript for a YouTube video
-func (c *Client) GetTranscript(ctx context.Context, videoURL string) (*Transcript, error) {
+// GetTranscript fetches and parses the transcript for a YouTube video, in
+// the first of languages with captions (DefaultLanguages when none are given)
+func (c *Client) GetTranscript(ctx context.Context, videoURL string, languages ...string) (*Transcript, error) {
 	// Validate URL first
 	if err := ValidateURL(videoURL); err != nil {
 		return nil, err
@@ -83,11 +86,10 @@ func (c *Client) GetTranscript(ctx context.Context, videoURL string) (*Transcrip
 	cmdCtx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	// Use yt-dlp to get full video JSON with subtitle information
+	// Use yt-dlp to get full video JSON with subtitle information. It lists
+	// every caption track; the chosen one is downloaded below.
 	cmd := exec.CommandContext(cmdCtx, c.ytdlpPath,
 		"--skip-download",
-		"--write-auto-subs",
-		"--sub-lang", "en",
 		"--print-json",
 		videoURL,
 	)
@@ -114,8 +116,18 @@ func (c *Client) GetTranscript(ctx context.Context, videoURL string) (*Transcrip
 		return nil, fmt.Errorf("failed to parse video data: %w", err)
 	}
 
-	// Try to find subtitle URL (preferring JSON3 format)
-	subtitleURL, subtitleFormat := c.findBestSubtitleURL(videoData)
+	// Try to find subtitle URL in each preferred language (preferring JSON3
+	// format), then in its base language ("pt" for "pt-BR")
+	if len(languages) == 0 {
+		languages = DefaultLanguages
+	}
+	var language, subtitleURL, subtitleFormat string
+	for _, candidate := range captionCandidates(videoData, languages) {
+		if subtitleURL, subtitleFormat = c.findBestSubtitleURL(videoData, candidate); subtitleURL != "" {
+			language = candidate
+			break
+		}
+	}
 	if subtitleURL == "" {
 		return nil, ErrNoTranscript
 	}
@@ -127,29 +139,33 @@ func (c *Client) GetTranscript(ctx context.Context, videoURL string) (*Transcrip
 	}
 
 	// Parse subtitle based on format
-	var text string
+	var segments []Segment
 	switch subtitleFormat {
 	case "json3":
-		text, err = c.parser.ParseJSON3(subtitleData)
-	case "vtt":
-		text, err = c.parser.ParseVTT(subtitleData)
+		segments, err = c.parser.ParseJSON3(subtitleData)
+	case "srt":
+		segments, err = c.parser.ParseSRT(subtitleData)
 	case "srv1", "srv2", "srv3":
-		// YouTube's XML formats - try parsing as JSON3 first, fall back to VTT
-		text, err = c.parser.ParseJSON3(subtitleData)
-		if err != nil {
-			text, err = c.parser.ParseVTT(subtitleData)
-		}
+		// YouTube's XML formats
+		segments, err = c.parser.ParseSRV(subtitleData)
 	default:
 		// Default to VTT parsing
-		text, err = c.parser.ParseVTT(subtitleData)
+		segments, err = c.parser.ParseVTT(subtitleData)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse subtitle: %w", err)
 	}
 
-	// Clean up the transcript
-	text = c.parser.CleanTranscript(text)
+	// Clean up each cue and the transcript, dropping cues left empty
+	cleaned := segments[:0]
+	for _, seg := range segments {
+		if seg.Text = c.parser.CleanTranscript(seg.Text); seg.Text != "" {
+			cleaned = append(cleaned, seg)
+		}
+	}
+	segments = cleaned
+	text := c.parser.CleanTranscript(SegmentsText(segments))
 
 	if text == "" {
 		return nil, ErrNoTranscript
@@ -157,39 +173,126 @@ func (c *Client) GetTranscript(ctx context.Context, videoURL string) (*Transcrip
 
 	return &Transcript{
 		Text:     text,
-		Language: "en",
+		Language: language,
+		Segments: segments,
 	}, nil
 }
 
-// findBestSubtitleURL finds the best subtitle URL from video data
-func (c *Client) findBestSubtitleURL(videoData map[string]interface{}) (string, string) {
+// DefaultLanguage is assumed when a video's language can't be detected
+const DefaultLanguage = "en"
+
+// AutoLanguage in a preference list stands for the video's spoken language,
+// or failing that any language it has subtitles in
+const AutoLanguage = "auto"
+
+// DefaultLanguages is the caption preference when none is given: the spoken
+// language, then English
+var DefaultLanguages = []string{AutoLanguage, DefaultLanguage}
+
+// captionCandidates expands a language preference list into the caption
+// languages to try, in order, without repeats
+func captionCandidates(videoData map[string]interface{}, languages []string) []string {
+	var candidates []string
+	seen := map[string]bool{}
+	add := func(language string) {
+		base, _, _ := strings.Cut(language, "-")
+		for _, l := range []string{language, base} {
+			if l != "" && !seen[l] {
+				seen[l] = true
+				candidates = append(candidates, l)
+			}
+		}
+	}
+
+	for _, language := range languages {
+		if language != AutoLanguage {
+			add(language)
+			continue
+		}
+
+		add(detectLanguage(videoData))
+		if subs, ok := videoData["subtitles"].(map[string]interface{}); ok {
+			keys := make([]string, 0, len(subs))
+			for key := range subs {
+				if key != "live_chat" {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				add(key)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// detectLanguage returns the video's spoken language: yt-dlp's language
+// field, else the original-language automatic captions ("<lang>-orig"), else
+// the only manual subtitle language, else DefaultLanguage
+func detectLanguage(videoData map[string]interface{}) string {
+	if language, ok := videoData["language"].(string); ok && language != "" {
+		return language
+	}
+
+	if autoCaps, ok := videoData["automatic_captions"].(map[string]interface{}); ok {
+		for key := range autoCaps {
+			if language, ok := strings.CutSuffix(key, "-orig"); ok {
+				return language
+			}
+		}
+	}
+
+	if subs, ok := videoData["subtitles"].(map[string]interface{}); ok && len(subs) == 1 {
+		for key := range subs {
+			if key != "live_chat" {
+				return key
+			}
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// findBestSubtitleURL finds the best subtitle URL in a language from video data
+func (c *Client) findBestSubtitleURL(videoData map[string]interface{}, language string) (string, string) {
 	// Preference order: json3 > vtt > srv3 > srv2 > srv1
 	formatPreference := []string{"json3", "vtt", "srv3", "srv2", "srv1"}
 
-	// Check automatic_captions first (more reliable for most videos)
+	// Check automatic_captions first (more reliable for most videos). The
+	// original-language track beats the machine translation into it.
 	if autoCaps, ok := videoData["automatic_captions"].(map[string]interface{}); ok {
-		if url, format := c.extractSubtitleURL(autoCaps, formatPreference); url != "" {
-			return url, format
+		for _, key := range []string{language + "-orig", language} {
+			if url, format := c.extractSubtitleURL(autoCaps, key, formatPreference); url != "" {
+				return url, format
+			}
 		}
 	}
 
-	// Fall back to manual subtitles
+	// Fall back to manual subtitles, in the language or a regional variant of it
 	if subs, ok := videoData["subtitles"].(map[string]interface{}); ok {
-		if url, format := c.extractSubtitleURL(subs, formatPreference); url != "" {
+		if url, format := c.extractSubtitleURL(subs, language, formatPreference); url != "" {
 			return url, format
 		}
+		for key := range subs {
+			if strings.HasPrefix(key, language+"-") {
+				if url, format := c.extractSubtitleURL(subs, key, formatPreference); url != "" {
+					return url, format
+				}
+			}
+		}
 	}
 
 	return "", ""
 }
 
-// extractSubtitleURL extracts subtitle URL from subtitle data
-func (c *Client) extractSubtitleURL(subsData map[string]interface{}, formatPreference []string) (string, string) {
-	// Try to get English subtitles
-	if enSubs, ok := subsData["en"].([]interface{}); ok && len(enSubs) > 0 {
+// extractSubtitleURL extracts the URL of one language's subtitles from subtitle data
+func (c *Client) extractSubtitleURL(subsData map[string]interface{}, language string, formatPreference []string) (string, string) {
+	if tracks, ok := subsData[language].([]interface{}); ok && len(tracks) > 0 {
 		// Try each format in order of preference
 		for _, preferredFormat := range formatPreference {
-			for _, sub := range enSubs {
+			for _, sub := range tracks {
 				if subInfo, ok := sub.(map[string]interface{}); ok {
 					if ext, ok := subInfo["ext"].(string); ok && ext == preferredFormat {
 						if url, ok := subInfo["url"].(string); ok {
@@ -201,7 +304,7 @@ func (c *Client) extractSubtitleURL(subsData map[string]interface{}, formatPrefe
 		}
 
 		// If no preferred format found, use first available
-		if subInfo, ok := enSubs[0].(map[string]interface{}); ok {
+		if subInfo, ok := tracks[0].(map[string]interface{}); ok {
 			if url, ok := subInfo["url"].(string); ok {
 				format := "unknown"
 				if ext, ok := subInfo["ext"].(string); ok {
@@ -305,11 +408,51 @@ func (c *Client) GetVideoMetadata(ctx context.Context, videoURL string) (*Metada
 		metadata.Channel = uploader
 	}
 
+	if language, ok := result["language"].(string); ok {
+		metadata.Language = language
+	}
+
 	return metadata, nil
 }
 
-// GetVideoInfo fetches both transcript and metadata
-func (c *Client) GetVideoInfo(ctx context.Context, videoURL string) (*VideoInfo, error) {
+// DownloadAudio downloads a video's smallest audio-only track into dir and
+// returns the file's path. Speech is intelligible at the lowest bitrate, and
+// it keeps an hour of audio around 20 MB.
+func (c *Client) DownloadAudio(ctx context.Context, videoURL, dir string) (string, error) {
+	// Validate URL first
+	if err := ValidateURL(videoURL); err != nil {
+		return "", err
+	}
+
+	// Downloads take longer than metadata lookups
+	cmdCtx, cancel := context.WithTimeout(ctx, 5*c.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, c.ytdlpPath,
+		"--no-playlist",
+		"-f", "worstaudio[ext=m4a]/worstaudio/bestaudio",
+		"-o", filepath.Join(dir, "audio.%(ext)s"),
+		videoURL,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCommandFailed, stderr.String())
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "audio.*"))
+	if err != nil || len(matches) == 0 {
+		return "", fmt.Errorf("%w: no audio file was written", ErrCommandFailed)
+	}
+
+	return matches[0], nil
+}
+
+// GetVideoInfo fetches both transcript and metadata, preferring captions in
+// languages as GetTranscript does
+func (c *Client) GetVideoInfo(ctx context.Context, videoURL string, languages ...string) (*VideoInfo, error) {
 	// Get metadata first (it's more reliable)
 	metadata, err := c.GetVideoMetadata(ctx, videoURL)
 	if err != nil {
@@ -317,7 +460,7 @@ func (c *Client) GetVideoInfo(ctx context.Context, videoURL string) (*VideoInfo,
 	}
 
 	// Try to get transcript
-	transcript, err := c.GetTranscript(ctx, videoURL)
+	transcript, err := c.GetTranscript(ctx, videoURL, languages...)
 	if err != nil {
 		// If transcript fails, return metadata only
 		return &VideoInfo{
@@ -2,8 +2,16 @@ package youtube
 
 // Transcript represents a YouTube video transcript
 type Transcript struct {
-	Text     string `json:"text"`
-	Language string `json:"language"`
+	Text     string    `json:"text"`
+	Language string    `json:"language"`           // Language of the caption track used
+	Segments []Segment `json:"segments,omitempty"` // The caption track's timed cues, in order
+}
+
+// Segment is one timed cue of a caption track
+type Segment struct {
+	Start float64 `json:"start"` // Seconds
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
 }
 
 // Metadata represents YouTube video metadata
@@ -11,6 +19,7 @@ type Metadata struct {
 	Title    string `json:"title"`
 	Duration int    `json:"duration"` // in seconds
 	Channel  string `json:"channel"`
+	Language string `json:"language,omitempty"` // Spoken language, when the uploader set one
 }
 
 // VideoInfo contains both transcript and metadata
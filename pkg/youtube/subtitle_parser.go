@@ -1,9 +1,14 @@
 package youtube
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"html"
+	"io"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -17,31 +22,35 @@ func NewSubtitleParser() *SubtitleParser {
 
 // ParseJSON3 parses YouTube's JSON3 subtitle format
 // JSON3 format looks like:
-// {"events": [{"segs": [{"utf8": "text"}], ...}]}
-func (p *SubtitleParser) ParseJSON3(data []byte) (string, error) {
+// {"events": [{"tStartMs": 0, "dDurationMs": 2000, "segs": [{"utf8": "text"}], ...}]}
+func (p *SubtitleParser) ParseJSON3(data []byte) ([]Segment, error) {
 	var result struct {
 		Events []struct {
-			Segs []struct {
+			StartMs    float64 `json:"tStartMs"`
+			DurationMs float64 `json:"dDurationMs"`
+			Segs       []struct {
 				UTF8 string `json:"utf8"`
 			} `json:"segs"`
 		} `json:"events"`
 	}
 
 	if err := json.Unmarshal(data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse JSON3: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON3: %w", err)
 	}
 
-	var text strings.Builder
+	var segments []Segment
 	for _, event := range result.Events {
+		var text strings.Builder
 		for _, seg := range event.Segs {
 			if seg.UTF8 != "" && seg.UTF8 != "\n" {
 				text.WriteString(seg.UTF8)
 				text.WriteString(" ")
 			}
 		}
+		segments = appendSegment(segments, event.StartMs/1000, (event.StartMs+event.DurationMs)/1000, text.String())
 	}
 
-	return strings.TrimSpace(text.String()), nil
+	return segments, nil
 }
 
 // ParseSRT parses SRT (SubRip) subtitle format
@@ -53,27 +62,29 @@ func (p *SubtitleParser) ParseJSON3(data []byte) (string, error) {
 // 2
 // 00:00:02,000 --> 00:00:04,000
 // Second subtitle text
-func (p *SubtitleParser) ParseSRT(data []byte) (string, error) {
-	content := string(data)
+func (p *SubtitleParser) ParseSRT(data []byte) ([]Segment, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
 
 	// Split by double newlines (subtitle blocks)
 	blocks := strings.Split(content, "\n\n")
 
-	var text strings.Builder
+	var segments []Segment
 	for _, block := range blocks {
 		lines := strings.Split(strings.TrimSpace(block), "\n")
 
-		// Skip sequence number and timestamp lines
-		// The actual text starts from line 3 (index 2)
+		// Skip the sequence number; the timestamp line follows it and the
+		// actual text starts from line 3 (index 2)
 		if len(lines) >= 3 {
+			start, end, ok := parseCueTiming(lines[1])
+			if !ok {
+				continue
+			}
 			// Join all lines after the timestamp (in case subtitle spans multiple lines)
-			subtitleText := strings.Join(lines[2:], " ")
-			text.WriteString(subtitleText)
-			text.WriteString(" ")
+			segments = appendSegment(segments, start, end, strings.Join(lines[2:], " "))
 		}
 	}
 
-	return strings.TrimSpace(text.String()), nil
+	return segments, nil
 }
 
 // ParseVTT parses WebVTT subtitle format
@@ -85,8 +96,8 @@ func (p *SubtitleParser) ParseSRT(data []byte) (string, error) {
 //
 // 00:00:02.000 --> 00:00:04.000
 // Second subtitle text
-func (p *SubtitleParser) ParseVTT(data []byte) (string, error) {
-	content := string(data)
+func (p *SubtitleParser) ParseVTT(data []byte) ([]Segment, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
 
 	// Remove WEBVTT header
 	content = regexp.MustCompile(`(?i)^WEBVTT[^\n]*\n`).ReplaceAllString(content, "")
@@ -98,22 +109,153 @@ func (p *SubtitleParser) ParseVTT(data []byte) (string, error) {
 	// Split by double newlines
 	blocks := strings.Split(content, "\n\n")
 
-	var text strings.Builder
+	var segments []Segment
 	for _, block := range blocks {
 		lines := strings.Split(strings.TrimSpace(block), "\n")
 
-		// Skip timestamp lines (contain -->)
+		// Text follows the timestamp line (it contains -->); an optional cue
+		// identifier precedes it
+		var start, end float64
+		timed := false
+		var text strings.Builder
 		for _, line := range lines {
-			if !strings.Contains(line, "-->") && strings.TrimSpace(line) != "" {
+			if strings.Contains(line, "-->") {
+				start, end, timed = parseCueTiming(line)
+				continue
+			}
+			if timed && strings.TrimSpace(line) != "" {
 				// Remove VTT tags like <c>, <v>, etc.
-				line = regexp.MustCompile(`<[^>]+>`).ReplaceAllString(line, "")
+				line = vttTagPattern.ReplaceAllString(line, "")
 				text.WriteString(strings.TrimSpace(line))
 				text.WriteString(" ")
 			}
 		}
+		if timed {
+			segments = appendSegment(segments, start, end, text.String())
+		}
+	}
+
+	return segments, nil
+}
+
+// ParseSRV parses YouTube's XML subtitle formats
+// srv1 times cues in seconds, and srv2 and srv3 in milliseconds:
+// <transcript><text start="0.5" dur="2.1">text</text></transcript>
+// <timedtext><body><p t="500" d="2100"><s>text</s></p></body></timedtext>
+func (p *SubtitleParser) ParseSRV(data []byte) ([]Segment, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	var segments []Segment
+	var cue *Segment
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SRV: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "text" || t.Name.Local == "p" {
+				if start, end, ok := srvTiming(t.Attr); ok {
+					cue = &Segment{Start: start, End: end}
+					text.Reset()
+				}
+			}
+		case xml.CharData:
+			if cue != nil {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if cue != nil && (t.Name.Local == "text" || t.Name.Local == "p") {
+				// srv1 escapes entities twice
+				segments = appendSegment(segments, cue.Start, cue.End, html.UnescapeString(text.String()))
+				cue = nil
+			}
+		}
 	}
 
-	return strings.TrimSpace(text.String()), nil
+	return segments, nil
+}
+
+// srvTiming reads a cue's start and end seconds from its start and dur
+// (seconds) or t and d (milliseconds) attributes
+func srvTiming(attrs []xml.Attr) (float64, float64, bool) {
+	values := map[string]float64{}
+	for _, a := range attrs {
+		if n, err := strconv.ParseFloat(a.Value, 64); err == nil {
+			values[a.Name.Local] = n
+		}
+	}
+
+	if start, ok := values["start"]; ok {
+		return start, start + values["dur"], true
+	}
+	if t, ok := values["t"]; ok {
+		return t / 1000, (t + values["d"]) / 1000, true
+	}
+	return 0, 0, false
+}
+
+// SegmentsText joins the text of segments into one transcript
+func SegmentsText(segments []Segment) string {
+	texts := make([]string, len(segments))
+	for i, seg := range segments {
+		texts[i] = seg.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// vttTagPattern matches VTT tags like <c>, <v>, and inline timestamps
+var vttTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// cueTimingPattern matches an SRT or VTT timing line, such as
+// "00:00:01,500 --> 00:00:04,000" or "01:02.000 --> 01:04.500 align:start".
+// Hours are optional in VTT.
+var cueTimingPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+
+// parseCueTiming reads the start and end seconds of a cue timing line
+func parseCueTiming(line string) (float64, float64, bool) {
+	m := cueTimingPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, false
+	}
+	start, ok := parseTimestamp(m[1])
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok := parseTimestamp(m[2])
+	if !ok {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseTimestamp reads "[hh:]mm:ss.mmm" (or with a comma) as seconds
+func parseTimestamp(ts string) (float64, bool) {
+	parts := strings.Split(strings.Replace(ts, ",", ".", 1), ":")
+	seconds := 0.0
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, true
+}
+
+// appendSegment appends a cue, skipping those left without text
+func appendSegment(segments []Segment, start, end float64, text string) []Segment {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return segments
+	}
+	return append(segments, Segment{Start: start, End: end, Text: text})
 }
 
 // CleanTranscript removes duplicate words and extra whitespace